@@ -2,18 +2,24 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/minicodemonkey/chief/internal/agent"
 	"github.com/minicodemonkey/chief/internal/cmd"
 	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/control"
 	"github.com/minicodemonkey/chief/internal/git"
 	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/network"
 	"github.com/minicodemonkey/chief/internal/prd"
+	"github.com/minicodemonkey/chief/internal/pushgate"
+	"github.com/minicodemonkey/chief/internal/timefmt"
 	"github.com/minicodemonkey/chief/internal/tui"
 )
 
@@ -28,11 +34,19 @@ type TUIOptions struct {
 	Merge         bool
 	Force         bool
 	NoRetry       bool
-	Agent         string // --agent claude|codex|opencode|cursor
-	AgentPath     string // --agent-path
+	IgnoreQuiet   bool     // --ignore-quiet-hours
+	Agent         string   // --agent claude|codex|opencode|cursor
+	AgentPath     string   // --agent-path
+	Model         string   // --model, passed to the agent
+	Base          string   // --base <ref>
+	Labels        []string // --label <name[=value]>, repeatable
+	A11y          bool     // --a11y: plain text progress instead of the TUI
+	Parallel      int      // --parallel N: stories worked on side by side
 }
 
 func main() {
+	configureDisplay()
+
 	// Handle subcommands first
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
@@ -48,6 +62,108 @@ func main() {
 		case "list":
 			runList()
 			return
+		case "validate":
+			runValidate()
+			return
+		case "validate-workspace":
+			runValidateWorkspace()
+			return
+		case "bundle":
+			runBundle()
+			return
+		case "attach":
+			runAttach()
+			return
+		case "pause":
+			runPause()
+			return
+		case "resume":
+			runResume()
+			return
+		case "top":
+			runTop()
+			return
+		case "run":
+			runRun()
+			return
+		case "deps":
+			runDeps()
+			return
+		case "review":
+			runReview()
+			return
+		case "tasks":
+			runTasks()
+			return
+		case "record":
+			runRecord()
+			return
+		case "profile":
+			runProfile()
+			return
+		case "compare-runs":
+			runCompareRuns()
+			return
+		case "cache":
+			runCache()
+			return
+		case "retention":
+			runRetention()
+			return
+		case "explain":
+			runExplain()
+			return
+		case "why-failed":
+			runWhyFailed()
+			return
+		case "blame":
+			runBlame()
+			return
+		case "estimate":
+			runEstimate()
+			return
+		case "watch-pr":
+			runWatchPR()
+			return
+		case "init-conventions":
+			runInitConventions()
+			return
+		case "undo":
+			runUndo()
+			return
+		case "do":
+			runDo()
+			return
+		case "protocol":
+			runProtocol()
+			return
+		case "push-gate":
+			// Run by the git wrapper the push policy puts on the agent's PATH
+			os.Exit(pushgate.Run(os.Args[2:]))
+		case "eval":
+			runEval()
+			return
+		case "doctor":
+			runDoctor()
+			return
+		case "selftest":
+			runSelftest()
+			return
+		case "clone":
+			runClone()
+			return
+		case "backup":
+			runBackup()
+			return
+		case "similar":
+			runSimilar()
+			return
+		case "restore":
+			runRestore()
+			return
+		case "settings":
+			runSettings()
+			return
 		case "help":
 			printHelp()
 			return
@@ -77,6 +193,7 @@ func main() {
 		// Already handled (--help or --version)
 		return
 	}
+	cleanRunData()
 
 	// Run the TUI
 	runTUIWithOptions(opts)
@@ -122,10 +239,10 @@ func listAvailablePRDs() []string {
 	return names
 }
 
-// parseAgentFlags extracts --agent and --agent-path from args[startIdx:],
-// returning the agent name, agent path, remaining args (with agent flags removed),
-// and the updated index offsets. It exits on missing values.
-func parseAgentFlags(args []string, startIdx int) (agentName, agentPath string, remaining []string) {
+// parseAgentFlags extracts --agent, --agent-path and --model from args[startIdx:],
+// returning the agent name, agent path, model, and remaining args (with agent
+// flags removed). It exits on missing values.
+func parseAgentFlags(args []string, startIdx int) (agentName, agentPath, agentModel string, remaining []string) {
 	for i := startIdx; i < len(args); i++ {
 		arg := args[i]
 		switch {
@@ -149,6 +266,16 @@ func parseAgentFlags(args []string, startIdx int) (agentName, agentPath string,
 			}
 		case strings.HasPrefix(arg, "--agent-path="):
 			agentPath = strings.TrimPrefix(arg, "--agent-path=")
+		case arg == "--model":
+			if i+1 < len(args) {
+				i++
+				agentModel = args[i]
+			} else {
+				fmt.Fprintf(os.Stderr, "Error: --model requires a value\n")
+				os.Exit(1)
+			}
+		case strings.HasPrefix(arg, "--model="):
+			agentModel = strings.TrimPrefix(arg, "--model=")
 		default:
 			remaining = append(remaining, arg)
 		}
@@ -156,6 +283,25 @@ func parseAgentFlags(args []string, startIdx int) (agentName, agentPath string,
 	return
 }
 
+// parseLabel validates a --label value, exiting on a malformed one.
+func parseLabel(label string) string {
+	if err := loop.ValidateLabel(label); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return label
+}
+
+// parseParallel validates a --parallel value, exiting on a malformed one.
+func parseParallel(value string) int {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		fmt.Fprintf(os.Stderr, "Error: --parallel must be at least 1\n")
+		os.Exit(1)
+	}
+	return n
+}
+
 // parseTUIFlags parses command-line flags for TUI mode
 func parseTUIFlags() *TUIOptions {
 	opts := &TUIOptions{
@@ -168,7 +314,7 @@ func parseTUIFlags() *TUIOptions {
 	}
 
 	// Pre-extract agent flags so they don't interfere with positional arg parsing
-	opts.Agent, opts.AgentPath, _ = parseAgentFlags(os.Args, 1)
+	opts.Agent, opts.AgentPath, opts.Model, _ = parseAgentFlags(os.Args, 1)
 
 	for i := 1; i < len(os.Args); i++ {
 		arg := os.Args[i]
@@ -188,10 +334,43 @@ func parseTUIFlags() *TUIOptions {
 			opts.Force = true
 		case arg == "--no-retry":
 			opts.NoRetry = true
-		case arg == "--agent" || arg == "--agent-path":
+		case arg == "--ignore-quiet-hours":
+			opts.IgnoreQuiet = true
+		case arg == "--a11y":
+			opts.A11y = true
+		case arg == "--agent" || arg == "--agent-path" || arg == "--model":
 			i++ // skip value (already parsed by parseAgentFlags)
-		case strings.HasPrefix(arg, "--agent=") || strings.HasPrefix(arg, "--agent-path="):
+		case strings.HasPrefix(arg, "--agent=") || strings.HasPrefix(arg, "--agent-path=") || strings.HasPrefix(arg, "--model="):
 			// already parsed by parseAgentFlags
+		case arg == "--base":
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "Error: --base requires a value\n")
+				os.Exit(1)
+			}
+			i++
+			opts.Base = os.Args[i]
+		case strings.HasPrefix(arg, "--base="):
+			opts.Base = strings.TrimPrefix(arg, "--base=")
+		case arg == "--label":
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "Error: --label requires a value\n")
+				os.Exit(1)
+			}
+			i++
+			opts.Labels = append(opts.Labels, parseLabel(os.Args[i]))
+		case strings.HasPrefix(arg, "--label="):
+			opts.Labels = append(opts.Labels, parseLabel(strings.TrimPrefix(arg, "--label=")))
+		case arg == "--parallel" || strings.HasPrefix(arg, "--parallel="):
+			value := strings.TrimPrefix(arg, "--parallel=")
+			if value == arg {
+				if i+1 >= len(os.Args) {
+					fmt.Fprintf(os.Stderr, "Error: --parallel requires a value\n")
+					os.Exit(1)
+				}
+				i++
+				value = os.Args[i]
+			}
+			opts.Parallel = parseParallel(value)
 		case arg == "--max-iterations" || arg == "-n":
 			// Next argument should be the number
 			if i+1 < len(os.Args) {
@@ -257,7 +436,7 @@ func runNew() {
 	opts := cmd.NewOptions{}
 
 	// Parse arguments: chief new [name] [context...] [--agent X] [--agent-path X]
-	flagAgent, flagPath, positional := parseAgentFlags(os.Args, 2)
+	flagAgent, flagPath, flagModel, positional := parseAgentFlags(os.Args, 2)
 	// Filter out remaining flags, keep only positional args
 	var args []string
 	for _, a := range positional {
@@ -272,7 +451,7 @@ func runNew() {
 		opts.Context = strings.Join(args[1:], " ")
 	}
 
-	opts.Provider = resolveProvider(flagAgent, flagPath)
+	opts.Provider = resolveProvider(flagAgent, flagPath, flagModel)
 	if err := cmd.RunNew(opts); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -283,14 +462,14 @@ func runEdit() {
 	opts := cmd.EditOptions{}
 
 	// Parse arguments: chief edit [name] [--agent X] [--agent-path X]
-	flagAgent, flagPath, remaining := parseAgentFlags(os.Args, 2)
+	flagAgent, flagPath, flagModel, remaining := parseAgentFlags(os.Args, 2)
 	for _, arg := range remaining {
 		if opts.Name == "" && !strings.HasPrefix(arg, "-") {
 			opts.Name = arg
 		}
 	}
 
-	opts.Provider = resolveProvider(flagAgent, flagPath)
+	opts.Provider = resolveProvider(flagAgent, flagPath, flagModel)
 	if err := cmd.RunEdit(opts); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -311,6 +490,20 @@ func runStatus() {
 	}
 }
 
+func runValidate() {
+	opts := cmd.ValidateOptions{}
+
+	// Parse arguments: chief validate [name]
+	if len(os.Args) > 2 && !strings.HasPrefix(os.Args[2], "-") {
+		opts.Name = os.Args[2]
+	}
+
+	if err := cmd.RunValidate(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
 func runUpdate() {
 	if err := cmd.RunUpdate(cmd.UpdateOptions{
 		Version: Version,
@@ -323,133 +516,1469 @@ func runUpdate() {
 func runList() {
 	opts := cmd.ListOptions{}
 
+	// Parse arguments: chief list [--sort <column>] [--filter <state>] [--json]
+	for i := 2; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		switch {
+		case arg == "--sort" || arg == "--filter":
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "Error: %s requires a value\n", arg)
+				os.Exit(1)
+			}
+			i++
+			if arg == "--sort" {
+				opts.Sort = os.Args[i]
+			} else {
+				opts.Filter = os.Args[i]
+			}
+		case strings.HasPrefix(arg, "--sort="):
+			opts.Sort = strings.TrimPrefix(arg, "--sort=")
+		case strings.HasPrefix(arg, "--filter="):
+			opts.Filter = strings.TrimPrefix(arg, "--filter=")
+		case arg == "--json":
+			opts.JSON = true
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown argument: %s\n", arg)
+			os.Exit(1)
+		}
+	}
+
 	if err := cmd.RunList(opts); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-// resolveProvider loads config and resolves the agent provider, exiting on error.
-func resolveProvider(flagAgent, flagPath string) loop.Provider {
-	cwd, err := os.Getwd()
-	if err != nil {
+func runValidateWorkspace() {
+	opts := cmd.ValidateWorkspaceOptions{}
+
+	// Parse arguments: chief validate-workspace [--workspace <dir>] [dir]
+	for i := 2; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		switch {
+		case arg == "--workspace":
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "Error: --workspace requires a value\n")
+				os.Exit(1)
+			}
+			i++
+			opts.Workspace = os.Args[i]
+		case strings.HasPrefix(arg, "--workspace="):
+			opts.Workspace = strings.TrimPrefix(arg, "--workspace=")
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+			os.Exit(1)
+		default:
+			opts.Workspace = arg
+		}
+	}
+
+	if err := cmd.RunValidateWorkspace(opts); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-	cfg, err := config.Load(cwd)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to load .chief/config.yaml: %v\n", err)
-		os.Exit(1)
+}
+
+func runAttach() {
+	opts := cmd.AttachOptions{}
+
+	// Parse arguments: chief attach [name] [--read-only]
+	for _, arg := range os.Args[2:] {
+		switch {
+		case arg == "--read-only":
+			opts.ReadOnly = true
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+			os.Exit(1)
+		default:
+			opts.Name = arg
+		}
 	}
-	provider, err := agent.Resolve(flagAgent, flagPath, cfg)
-	if err != nil {
+
+	if err := cmd.RunAttach(opts); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-	if err := agent.CheckInstalled(provider); err != nil {
+}
+
+func runPause() {
+	opts := cmd.PauseOptions{}
+
+	// Parse arguments: chief pause [name] | --all [--maintenance [--reason <text>]]
+	for i := 2; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		switch {
+		case arg == "--all":
+			opts.All = true
+		case arg == "--maintenance":
+			opts.Maintenance = true
+		case arg == "--reason":
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "Error: --reason requires a value\n")
+				os.Exit(1)
+			}
+			i++
+			opts.Reason = os.Args[i]
+		case strings.HasPrefix(arg, "--reason="):
+			opts.Reason = strings.TrimPrefix(arg, "--reason=")
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+			os.Exit(1)
+		default:
+			opts.Name = arg
+		}
+	}
+	if opts.Name != "" && (opts.All || opts.Maintenance) {
+		fmt.Fprintf(os.Stderr, "Error: --all and --maintenance pause every PRD; don't name one\n")
+		os.Exit(1)
+	}
+	if opts.Reason != "" && !opts.Maintenance {
+		fmt.Fprintf(os.Stderr, "Error: --reason only applies with --maintenance\n")
+		os.Exit(1)
+	}
+
+	if err := cmd.RunPause(opts); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-	return provider
 }
 
-func runTUIWithOptions(opts *TUIOptions) {
-	provider := resolveProvider(opts.Agent, opts.AgentPath)
+func runResume() {
+	opts := cmd.ResumeOptions{}
 
-	prdPath := opts.PRDPath
+	// Parse arguments: chief resume <name> | --all
+	for _, arg := range os.Args[2:] {
+		switch {
+		case arg == "--all":
+			opts.All = true
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+			os.Exit(1)
+		default:
+			opts.Name = arg
+		}
+	}
+	if opts.Name != "" && opts.All {
+		fmt.Fprintf(os.Stderr, "Error: --all resumes every PRD; don't name one\n")
+		os.Exit(1)
+	}
 
-	// If no PRD specified, try to find one
-	if prdPath == "" {
-		// Try "main" first
-		mainPath := ".chief/prds/main/prd.md"
-		if _, err := os.Stat(mainPath); err == nil {
-			prdPath = mainPath
-		} else {
-			// Look for any available PRD
-			prdPath = findAvailablePRD()
+	if err := cmd.RunResume(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runTop() {
+	opts := cmd.TopOptions{}
+
+	// Parse arguments: chief top [--once]
+	for _, arg := range os.Args[2:] {
+		switch arg {
+		case "--once":
+			opts.Once = true
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown argument: %s\n", arg)
+			os.Exit(1)
 		}
+	}
 
-		// If still no PRD found, run first-time setup
-		if prdPath == "" {
-			cwd, _ := os.Getwd()
-			showGitignore := git.IsGitRepo(cwd) && !git.IsChiefIgnored(cwd)
+	if err := cmd.RunTop(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
 
-			// Run the first-time setup TUI
-			result, err := tui.RunFirstTimeSetup(cwd, showGitignore)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+func runRun() {
+	// Parse arguments: chief run [name] [--profile X] [--json] [--verbose] [--no-retry] [--ignore-quiet-hours]
+	//                  [--base <ref>] [--label X] [--parallel N] [-n N] [--agent X] [--agent-path X] [--model X]
+	opts := cmd.RunOptions{}
+	flagAgent, flagPath, flagModel, remaining := parseAgentFlags(os.Args, 2)
+	remaining = applyRunProfile(&opts, &flagAgent, &flagModel, remaining)
+	name := ""
+	for i := 0; i < len(remaining); i++ {
+		arg := remaining[i]
+		switch {
+		case arg == "--json":
+			opts.JSON = true
+		case arg == "--verbose":
+			opts.Verbose = true
+		case arg == "--no-retry":
+			opts.NoRetry = true
+		case arg == "--ignore-quiet-hours":
+			opts.IgnoreQuiet = true
+		case arg == "--base":
+			if i+1 >= len(remaining) {
+				fmt.Fprintf(os.Stderr, "Error: --base requires a value\n")
 				os.Exit(1)
 			}
-
-			if result.Cancelled {
-				return
+			i++
+			opts.Base = remaining[i]
+		case strings.HasPrefix(arg, "--base="):
+			opts.Base = strings.TrimPrefix(arg, "--base=")
+		case arg == "--label":
+			if i+1 >= len(remaining) {
+				fmt.Fprintf(os.Stderr, "Error: --label requires a value\n")
+				os.Exit(1)
 			}
-
-			// Save config from setup
-			cfg := config.Default()
-			cfg.OnComplete.Push = result.PushOnComplete
-			cfg.OnComplete.CreatePR = result.CreatePROnComplete
-			if err := config.Save(cwd, cfg); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to save config: %v\n", err)
+			i++
+			opts.Labels = append(opts.Labels, parseLabel(remaining[i]))
+		case strings.HasPrefix(arg, "--label="):
+			opts.Labels = append(opts.Labels, parseLabel(strings.TrimPrefix(arg, "--label=")))
+		case arg == "--parallel" || strings.HasPrefix(arg, "--parallel="):
+			value := strings.TrimPrefix(arg, "--parallel=")
+			if value == arg {
+				if i+1 >= len(remaining) {
+					fmt.Fprintf(os.Stderr, "Error: --parallel requires a value\n")
+					os.Exit(1)
+				}
+				i++
+				value = remaining[i]
 			}
-
-			// Create the PRD
-			newOpts := cmd.NewOptions{
-				Name:     result.PRDName,
-				Provider: provider,
+			opts.Parallel = parseParallel(value)
+		case arg == "--max-iterations" || arg == "-n" || strings.HasPrefix(arg, "--max-iterations="):
+			value := strings.TrimPrefix(arg, "--max-iterations=")
+			if value == arg {
+				if i+1 >= len(remaining) {
+					fmt.Fprintf(os.Stderr, "Error: %s requires a value\n", arg)
+					os.Exit(1)
+				}
+				i++
+				value = remaining[i]
 			}
-			if err := cmd.RunNew(newOpts); err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				fmt.Fprintf(os.Stderr, "Error: --max-iterations must be at least 1\n")
 				os.Exit(1)
 			}
-
-			// Restart TUI with the new PRD
-			opts.PRDPath = fmt.Sprintf(".chief/prds/%s/prd.md", result.PRDName)
-			runTUIWithOptions(opts)
-			return
+			opts.MaxIterations = n
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+			os.Exit(1)
+		case name == "":
+			name = arg
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unexpected argument: %s\n", arg)
+			os.Exit(1)
 		}
 	}
 
-	prdDir := filepath.Dir(prdPath)
-
-	// Auto-migrate: if prd.json exists alongside prd.md, migrate status
-	jsonPath := filepath.Join(prdDir, "prd.json")
-	if _, err := os.Stat(jsonPath); err == nil {
-		fmt.Println("Migrating status from prd.json to prd.md...")
-		if err := prd.MigrateFromJSON(prdDir); err != nil {
-			fmt.Printf("Warning: migration failed: %v\n", err)
-		} else {
-			fmt.Println("Migration complete (prd.json renamed to prd.json.bak).")
+	opts.PRDPath = ".chief/prds/main/prd.md"
+	if name != "" {
+		opts.PRDPath = fmt.Sprintf(".chief/prds/%s/prd.md", name)
+	} else if _, err := os.Stat(opts.PRDPath); err != nil {
+		if found := findAvailablePRD(); found != "" {
+			opts.PRDPath = found
 		}
 	}
+	if _, err := os.Stat(opts.PRDPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: PRD not found: %s\n", opts.PRDPath)
+		os.Exit(1)
+	}
+	opts.Provider = resolveProvider(flagAgent, flagPath, flagModel)
+	cleanRunData()
 
-	app, err := tui.NewAppWithOptions(prdPath, opts.MaxIterations, provider)
-	if err != nil {
-		// Check if this is a missing PRD file error
-		if os.IsNotExist(err) || strings.Contains(err.Error(), "no such file") {
-			fmt.Printf("PRD not found: %s\n", prdPath)
-			fmt.Println()
-			// Show available PRDs if any exist
-			available := listAvailablePRDs()
-			if len(available) > 0 {
-				fmt.Println("Available PRDs:")
-				for _, name := range available {
-					fmt.Printf("  chief %s\n", name)
-				}
-				fmt.Println()
-			}
-			fmt.Println("Or create a new one:")
-			fmt.Println("  chief new               # Create default PRD")
-			fmt.Println("  chief new <name>        # Create named PRD")
-		} else {
-			fmt.Printf("Error: %v\n", err)
-		}
+	if err := cmd.RunHeadless(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+}
 
-	// Set verbose mode if requested
-	if opts.Verbose {
-		app.SetVerbose(true)
+// applyRunProfile applies the profile named by --profile, if any, to a run's
+// options and agent flags, and returns the arguments without --profile. The
+// remaining flags are parsed afterwards, so they take precedence.
+func applyRunProfile(opts *cmd.RunOptions, flagAgent, flagModel *string, args []string) []string {
+	var rest []string
+	name := ""
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--profile":
+			if i+1 >= len(args) {
+				fmt.Fprintf(os.Stderr, "Error: --profile requires a value\n")
+				os.Exit(1)
+			}
+			i++
+			name = args[i]
+		case strings.HasPrefix(arg, "--profile="):
+			name = strings.TrimPrefix(arg, "--profile=")
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	if name == "" {
+		return rest
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	cfg, err := config.Load(cwd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load .chief/config.yaml: %v\n", err)
+		os.Exit(1)
+	}
+	profile, err := cfg.Profile(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *flagAgent == "" {
+		*flagAgent = profile.Agent
+	}
+	if *flagModel == "" {
+		*flagModel = profile.Model
+	}
+	if profile.Parallel > 0 {
+		opts.Parallel = profile.Parallel
+	}
+	if profile.MaxIterations > 0 {
+		opts.MaxIterations = profile.MaxIterations
+	}
+	opts.Base = profile.Base
+	for _, label := range profile.Labels {
+		opts.Labels = append(opts.Labels, parseLabel(label))
+	}
+	opts.JSON = profile.JSON
+	opts.Verbose = profile.Verbose
+	opts.NoRetry = profile.NoRetry
+	opts.IgnoreQuiet = profile.IgnoreQuietHours
+	return rest
+}
+
+func runDeps() {
+	// Parse arguments: chief deps scan [--name <prd>] [--dry-run]
+	if len(os.Args) < 3 || os.Args[2] != "scan" {
+		fmt.Fprintf(os.Stderr, "Usage: chief deps scan [--name <prd>] [--dry-run]\n")
+		os.Exit(1)
+	}
+
+	opts := cmd.DepsScanOptions{}
+	for i := 3; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		switch {
+		case arg == "--dry-run":
+			opts.DryRun = true
+		case arg == "--name":
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "Error: --name requires a value\n")
+				os.Exit(1)
+			}
+			i++
+			opts.Name = os.Args[i]
+		case strings.HasPrefix(arg, "--name="):
+			opts.Name = strings.TrimPrefix(arg, "--name=")
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown argument: %s\n", arg)
+			os.Exit(1)
+		}
+	}
+
+	if err := cmd.RunDepsScan(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runReview() {
+	// Parse arguments: chief review security [name] [--agent X] [--agent-path X]
+	if len(os.Args) < 3 || os.Args[2] != "security" {
+		fmt.Fprintf(os.Stderr, "Usage: chief review security [name] [--agent <provider>] [--agent-path <path>]\n")
+		os.Exit(1)
+	}
+
+	opts := cmd.ReviewOptions{}
+	flagAgent, flagPath, flagModel, remaining := parseAgentFlags(os.Args, 3)
+	for _, arg := range remaining {
+		if strings.HasPrefix(arg, "-") {
+			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+			os.Exit(1)
+		}
+		opts.Name = arg
+	}
+
+	opts.Provider = resolveProvider(flagAgent, flagPath, flagModel)
+	if err := cmd.RunReviewSecurity(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runExplain() {
+	// Parse arguments: chief explain <story-id> [--prd <name>] [--agent X] [--agent-path X]
+	usage := "Usage: chief explain <story-id> [--prd <name>] [--agent <provider>] [--agent-path <path>]\n"
+	opts := cmd.ExplainOptions{}
+	flagAgent, flagPath, flagModel, remaining := parseAgentFlags(os.Args, 2)
+	for i := 0; i < len(remaining); i++ {
+		arg := remaining[i]
+		switch {
+		case arg == "--prd":
+			if i+1 >= len(remaining) {
+				fmt.Fprintf(os.Stderr, "Error: --prd requires a value\n")
+				os.Exit(1)
+			}
+			i++
+			opts.Name = remaining[i]
+		case strings.HasPrefix(arg, "--prd="):
+			opts.Name = strings.TrimPrefix(arg, "--prd=")
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+			os.Exit(1)
+		case opts.StoryID == "":
+			opts.StoryID = arg
+		default:
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(1)
+		}
+	}
+	if opts.StoryID == "" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+
+	opts.Provider = resolveProvider(flagAgent, flagPath, flagModel)
+	if err := cmd.RunExplain(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runBlame() {
+	// Parse arguments: chief blame <file> [--summary]
+	opts := cmd.BlameOptions{}
+	for _, arg := range os.Args[2:] {
+		switch {
+		case arg == "--summary":
+			opts.Summary = true
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+			os.Exit(1)
+		case opts.File == "":
+			opts.File = arg
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unexpected argument: %s\n", arg)
+			os.Exit(1)
+		}
+	}
+	if opts.File == "" {
+		fmt.Fprintf(os.Stderr, "Usage: chief blame <file> [--summary]\n")
+		os.Exit(1)
+	}
+
+	if err := cmd.RunBlame(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runEstimate() {
+	opts := cmd.EstimateOptions{}
+
+	// Parse arguments: chief estimate [name] [--agent X] [--agent-path X]
+	flagAgent, flagPath, flagModel, remaining := parseAgentFlags(os.Args, 2)
+	for _, arg := range remaining {
+		if strings.HasPrefix(arg, "-") {
+			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+			os.Exit(1)
+		}
+		if opts.Name == "" {
+			opts.Name = arg
+		}
+	}
+
+	opts.Provider = resolveProvider(flagAgent, flagPath, flagModel)
+	if err := cmd.RunEstimate(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runWatchPR() {
+	// Parse arguments: chief watch-pr [name] [--interval <duration>] [--once] [--agent X] [--agent-path X]
+	opts := cmd.WatchPROptions{}
+	flagAgent, flagPath, flagModel, remaining := parseAgentFlags(os.Args, 2)
+	for i := 0; i < len(remaining); i++ {
+		arg := remaining[i]
+		switch {
+		case arg == "--once":
+			opts.Once = true
+		case arg == "--interval" || strings.HasPrefix(arg, "--interval="):
+			value, ok := strings.CutPrefix(arg, "--interval=")
+			if !ok {
+				if i+1 >= len(remaining) {
+					fmt.Fprintf(os.Stderr, "Error: --interval requires a value\n")
+					os.Exit(1)
+				}
+				i++
+				value = remaining[i]
+			}
+			interval, err := time.ParseDuration(value)
+			if err != nil || interval < time.Minute {
+				fmt.Fprintf(os.Stderr, "Error: --interval must be a duration of at least 1m, e.g. 5m\n")
+				os.Exit(1)
+			}
+			opts.Interval = interval
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+			os.Exit(1)
+		case opts.Name == "":
+			opts.Name = arg
+		}
+	}
+
+	opts.Provider = resolveProvider(flagAgent, flagPath, flagModel)
+	if err := cmd.RunWatchPR(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runWhyFailed() {
+	// Parse arguments: chief why-failed <story-id> [--prd <name>] [--agent X] [--agent-path X]
+	usage := "Usage: chief why-failed <story-id> [--prd <name>] [--agent <provider>] [--agent-path <path>]\n"
+	opts := cmd.WhyFailedOptions{}
+	flagAgent, flagPath, flagModel, remaining := parseAgentFlags(os.Args, 2)
+	for i := 0; i < len(remaining); i++ {
+		arg := remaining[i]
+		switch {
+		case arg == "--prd":
+			if i+1 >= len(remaining) {
+				fmt.Fprintf(os.Stderr, "Error: --prd requires a value\n")
+				os.Exit(1)
+			}
+			i++
+			opts.Name = remaining[i]
+		case strings.HasPrefix(arg, "--prd="):
+			opts.Name = strings.TrimPrefix(arg, "--prd=")
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+			os.Exit(1)
+		case opts.StoryID == "":
+			opts.StoryID = arg
+		default:
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(1)
+		}
+	}
+	if opts.StoryID == "" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+
+	opts.Provider = resolveProvider(flagAgent, flagPath, flagModel)
+	if err := cmd.RunWhyFailed(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runDo() {
+	// Parse arguments: chief do <task> [--name <name>] [--rm] [--label X] [-n N] [--agent X] [--agent-path X]
+	//                  echo <task> | chief do [-]
+	usage := "Usage: chief do <task> [--name <name>] [--rm] [--label <label>] [--max-iterations N]\n       echo <task> | chief do\n"
+	opts := cmd.DoOptions{}
+	flagAgent, flagPath, flagModel, remaining := parseAgentFlags(os.Args, 2)
+	var words []string
+	for i := 0; i < len(remaining); i++ {
+		arg := remaining[i]
+		value := ""
+		switch {
+		case arg == "--rm":
+			opts.Remove = true
+			continue
+		case arg == "--label":
+			if i+1 >= len(remaining) {
+				fmt.Fprintf(os.Stderr, "Error: --label requires a value\n")
+				os.Exit(1)
+			}
+			i++
+			opts.Labels = append(opts.Labels, parseLabel(remaining[i]))
+			continue
+		case strings.HasPrefix(arg, "--label="):
+			opts.Labels = append(opts.Labels, parseLabel(strings.TrimPrefix(arg, "--label=")))
+			continue
+		case arg == "--name":
+			if i+1 >= len(remaining) {
+				fmt.Fprintf(os.Stderr, "Error: --name requires a value\n")
+				os.Exit(1)
+			}
+			i++
+			opts.Name = remaining[i]
+			continue
+		case strings.HasPrefix(arg, "--name="):
+			opts.Name = strings.TrimPrefix(arg, "--name=")
+			continue
+		case arg == "--max-iterations" || arg == "-n":
+			if i+1 >= len(remaining) {
+				fmt.Fprintf(os.Stderr, "Error: --max-iterations requires a value\n")
+				os.Exit(1)
+			}
+			i++
+			value = remaining[i]
+		case strings.HasPrefix(arg, "--max-iterations="):
+			value = strings.TrimPrefix(arg, "--max-iterations=")
+		case arg != "-" && strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+			os.Exit(1)
+		default:
+			words = append(words, arg)
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			fmt.Fprintf(os.Stderr, "Error: --max-iterations must be at least 1\n")
+			os.Exit(1)
+		}
+		opts.MaxIterations = n
+	}
+
+	// Read the task from stdin when it isn't given, or given as "-"
+	opts.Task = strings.Join(words, " ")
+	if opts.Task == "" || opts.Task == "-" {
+		if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice != 0 {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(1)
+		}
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read the task from stdin: %v\n", err)
+			os.Exit(1)
+		}
+		opts.Task = string(data)
+	}
+
+	opts.Provider = resolveProvider(flagAgent, flagPath, flagModel)
+	if err := cmd.RunDo(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runProtocol() {
+	// Parse arguments: chief protocol dump
+	if len(os.Args) != 3 || os.Args[2] != "dump" {
+		fmt.Fprintf(os.Stderr, "Usage: chief protocol dump\n")
+		os.Exit(1)
+	}
+	if err := cmd.RunProtocolDump(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runEval() {
+	// Parse arguments: chief eval [name] [--min-score N] [--agent X] [--agent-path X]
+	opts := cmd.EvalOptions{}
+	flagAgent, flagPath, flagModel, remaining := parseAgentFlags(os.Args, 2)
+	for i := 0; i < len(remaining); i++ {
+		arg := remaining[i]
+		value := ""
+		switch {
+		case arg == "--min-score":
+			if i+1 >= len(remaining) {
+				fmt.Fprintf(os.Stderr, "Error: --min-score requires a value\n")
+				os.Exit(1)
+			}
+			i++
+			value = remaining[i]
+		case strings.HasPrefix(arg, "--min-score="):
+			value = strings.TrimPrefix(arg, "--min-score=")
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+			os.Exit(1)
+		default:
+			opts.Name = arg
+			continue
+		}
+		score, err := strconv.Atoi(value)
+		if err != nil || score < 1 || score > 100 {
+			fmt.Fprintf(os.Stderr, "Error: --min-score must be a percentage from 1 to 100\n")
+			os.Exit(1)
+		}
+		opts.MinScore = score
+	}
+
+	opts.Provider = resolveProvider(flagAgent, flagPath, flagModel)
+	if err := cmd.RunEval(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runTasks() {
+	// Parse arguments: chief tasks [--prd <name>]
+	//                  chief tasks pass <story-id> [--prd <name>]
+	usage := "Usage: chief tasks [--prd <name>]\n       chief tasks pass <story-id> [--prd <name>]\n"
+	opts := cmd.TasksOptions{}
+	var positional []string
+	for i := 2; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		switch {
+		case arg == "--prd":
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "Error: --prd requires a value\n")
+				os.Exit(1)
+			}
+			i++
+			opts.Name = os.Args[i]
+		case strings.HasPrefix(arg, "--prd="):
+			opts.Name = strings.TrimPrefix(arg, "--prd=")
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+			os.Exit(1)
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	var err error
+	switch {
+	case len(positional) == 0:
+		err = cmd.RunTasksList(opts)
+	case positional[0] == "pass" && len(positional) == 2:
+		opts.StoryID = positional[1]
+		err = cmd.RunTasksPass(opts)
+	case positional[0] == "pass":
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown tasks command: %s (expected pass)\n", positional[0])
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runRecord() {
+	// Parse arguments: chief record <story-id> [--prd <name>]
+	usage := "Usage: chief record <story-id> [--prd <name>]\n"
+	opts := cmd.RecordOptions{}
+	for i := 2; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		switch {
+		case arg == "--prd":
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "Error: --prd requires a value\n")
+				os.Exit(1)
+			}
+			i++
+			opts.Name = os.Args[i]
+		case strings.HasPrefix(arg, "--prd="):
+			opts.Name = strings.TrimPrefix(arg, "--prd=")
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+			os.Exit(1)
+		case opts.StoryID == "":
+			opts.StoryID = arg
+		default:
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(1)
+		}
+	}
+	if opts.StoryID == "" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+
+	if err := cmd.RunRecord(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runProfile() {
+	// Parse arguments: chief profile [name] [--all] [--label X] [--without-label X]
+	usage := "Usage: chief profile [name] [--all] [--label <label>] [--without-label <label>]\n"
+	opts := cmd.ProfileOptions{}
+	args := os.Args[2:]
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--all":
+			opts.All = true
+		case arg == "--label" || arg == "--without-label":
+			if i+1 >= len(args) {
+				fmt.Fprintf(os.Stderr, "Error: %s requires a value\n", arg)
+				os.Exit(1)
+			}
+			i++
+			if arg == "--label" {
+				opts.Labels.With = append(opts.Labels.With, parseLabel(args[i]))
+			} else {
+				opts.Labels.Without = append(opts.Labels.Without, parseLabel(args[i]))
+			}
+		case strings.HasPrefix(arg, "--label="):
+			opts.Labels.With = append(opts.Labels.With, parseLabel(strings.TrimPrefix(arg, "--label=")))
+		case strings.HasPrefix(arg, "--without-label="):
+			opts.Labels.Without = append(opts.Labels.Without, parseLabel(strings.TrimPrefix(arg, "--without-label=")))
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+			os.Exit(1)
+		case opts.Name == "":
+			opts.Name = arg
+		default:
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(1)
+		}
+	}
+
+	if err := cmd.RunProfile(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runCompareRuns() {
+	// Parse arguments: chief compare-runs [name] <runA> <runB>
+	usage := "Usage: chief compare-runs [name] <runA> <runB>\n\nA run is its number in the event log, latest, or a label picking the latest run with it.\n"
+	var positional []string
+	for _, arg := range os.Args[2:] {
+		if strings.HasPrefix(arg, "-") {
+			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+			os.Exit(1)
+		}
+		positional = append(positional, arg)
+	}
+	opts := cmd.CompareRunsOptions{}
+	switch len(positional) {
+	case 2:
+		opts.RunA, opts.RunB = positional[0], positional[1]
+	case 3:
+		opts.Name, opts.RunA, opts.RunB = positional[0], positional[1], positional[2]
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+
+	if err := cmd.RunCompareRuns(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runCache() {
+	// Parse arguments: chief cache run [--] <command...>
+	//                  chief cache clear
+	usage := "Usage: chief cache run -- <command>\n       chief cache clear\n"
+	if len(os.Args) < 3 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+
+	switch os.Args[2] {
+	case "run":
+		args := os.Args[3:]
+		if len(args) > 0 && args[0] == "--" {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(1)
+		}
+		code, err := cmd.RunCacheRun(cmd.CacheOptions{Command: strings.Join(args, " ")})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(code)
+	case "clear":
+		if err := cmd.RunCacheClear(cmd.CacheOptions{}); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown cache command: %s (expected run or clear)\n", os.Args[2])
+		os.Exit(1)
+	}
+}
+
+func runRetention() {
+	// Parse arguments: chief retention status
+	if len(os.Args) != 3 || os.Args[2] != "status" {
+		fmt.Fprintf(os.Stderr, "Usage: chief retention status\n")
+		os.Exit(1)
+	}
+	if err := cmd.RunRetentionStatus(cmd.RetentionOptions{}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// cleanRunData removes run data past the retention settings in
+// .chief/config.yaml before a session starts.
+func cleanRunData() {
+	if cwd, err := os.Getwd(); err == nil {
+		cmd.CleanRunDataOnStartup(cwd)
+	}
+}
+
+func runDoctor() {
+	// Parse arguments: chief doctor [--agent X] [--agent-path X]
+	flagAgent, flagPath, flagModel, remaining := parseAgentFlags(os.Args, 2)
+	if len(remaining) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: unexpected argument: %s\n", remaining[0])
+		os.Exit(1)
+	}
+
+	if err := cmd.RunDoctor(cmd.DoctorOptions{Agent: flagAgent, AgentPath: flagPath, Model: flagModel}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runInitConventions() {
+	// Parse arguments: chief init-conventions [--force] [--dry-run]
+	opts := cmd.InitConventionsOptions{}
+	for _, arg := range os.Args[2:] {
+		switch arg {
+		case "--force":
+			opts.Force = true
+		case "--dry-run":
+			opts.DryRun = true
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unexpected argument: %s\n", arg)
+			os.Exit(1)
+		}
+	}
+
+	if err := cmd.RunInitConventions(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runUndo() {
+	// Parse arguments: chief undo [--list]
+	opts := cmd.UndoOptions{}
+	for _, arg := range os.Args[2:] {
+		switch arg {
+		case "--list":
+			opts.List = true
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unexpected argument: %s\n", arg)
+			os.Exit(1)
+		}
+	}
+
+	if err := cmd.RunUndo(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runSelftest() {
+	// Parse arguments: chief selftest [--keep]
+	// `chief selftest agent <prd>` is the fake agent the self-test runs.
+	if len(os.Args) > 3 && os.Args[2] == "agent" {
+		if err := cmd.RunSelftestAgent(os.Stdout, os.Args[3]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	opts := cmd.SelftestOptions{}
+	for _, arg := range os.Args[2:] {
+		switch arg {
+		case "--keep":
+			opts.Keep = true
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unexpected argument: %s\n", arg)
+			os.Exit(1)
+		}
+	}
+
+	if err := cmd.RunSelftest(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runClone() {
+	// Parse arguments: chief clone <url> [dir] [--depth N] [--branch B] [--single-branch] [--sparse <path>]...
+	opts := cmd.CloneOptions{}
+	var positional []string
+	flagValue := func(i int) string {
+		if i+1 >= len(os.Args) {
+			fmt.Fprintf(os.Stderr, "Error: %s requires a value\n", os.Args[i])
+			os.Exit(1)
+		}
+		return os.Args[i+1]
+	}
+	parseDepth := func(v string) int {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			fmt.Fprintf(os.Stderr, "Error: --depth must be a positive number\n")
+			os.Exit(1)
+		}
+		return n
+	}
+	addSparse := func(v string) {
+		for _, p := range strings.Split(v, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				opts.Sparse = append(opts.Sparse, p)
+			}
+		}
+	}
+	for i := 2; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		switch {
+		case arg == "--depth":
+			opts.Depth = parseDepth(flagValue(i))
+			i++
+		case strings.HasPrefix(arg, "--depth="):
+			opts.Depth = parseDepth(strings.TrimPrefix(arg, "--depth="))
+		case arg == "--branch" || arg == "-b":
+			opts.Branch = flagValue(i)
+			i++
+		case strings.HasPrefix(arg, "--branch="):
+			opts.Branch = strings.TrimPrefix(arg, "--branch=")
+		case arg == "--single-branch":
+			opts.SingleBranch = true
+		case arg == "--sparse":
+			addSparse(flagValue(i))
+			i++
+		case strings.HasPrefix(arg, "--sparse="):
+			addSparse(strings.TrimPrefix(arg, "--sparse="))
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+			os.Exit(1)
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) == 0 || len(positional) > 2 {
+		fmt.Fprintf(os.Stderr, "Usage: chief clone <url> [dir] [--depth N] [--branch <name>] [--single-branch] [--sparse <path>]...\n")
+		os.Exit(1)
+	}
+	opts.URL = positional[0]
+	if len(positional) == 2 {
+		opts.Dir = positional[1]
+	}
+
+	if err := cmd.RunClone(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runSettings() {
+	// Parse arguments: chief settings show [key] [--project <dir>]
+	//                  chief settings set <key> <value> [--project <dir>]
+	usage := "Usage: chief settings show [key] [--project <dir>]\n       chief settings set <key> <value> [--project <dir>]\n"
+	if len(os.Args) < 3 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+
+	opts := cmd.SettingsOptions{}
+	var positional []string
+	for i := 3; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		switch {
+		case arg == "--project":
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "Error: --project requires a value\n")
+				os.Exit(1)
+			}
+			i++
+			opts.Project = os.Args[i]
+		case strings.HasPrefix(arg, "--project="):
+			opts.Project = strings.TrimPrefix(arg, "--project=")
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+			os.Exit(1)
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	var err error
+	switch os.Args[2] {
+	case "show":
+		if len(positional) > 1 {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(1)
+		}
+		if len(positional) == 1 {
+			opts.Key = positional[0]
+		}
+		err = cmd.RunSettingsShow(opts)
+	case "set":
+		if len(positional) != 2 {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(1)
+		}
+		opts.Key, opts.Value = positional[0], positional[1]
+		err = cmd.RunSettingsSet(opts)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown settings command: %s (expected show or set)\n", os.Args[2])
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runSimilar() {
+	// Parse arguments: chief similar <query...> [--limit N]
+	opts := cmd.SimilarOptions{}
+	var words []string
+	for i := 2; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		switch {
+		case arg == "--limit" || strings.HasPrefix(arg, "--limit="):
+			value := strings.TrimPrefix(arg, "--limit=")
+			if arg == "--limit" {
+				if i+1 >= len(os.Args) {
+					fmt.Fprintf(os.Stderr, "Error: --limit requires a value\n")
+					os.Exit(1)
+				}
+				i++
+				value = os.Args[i]
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				fmt.Fprintf(os.Stderr, "Error: --limit must be a positive number\n")
+				os.Exit(1)
+			}
+			opts.Limit = n
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+			os.Exit(1)
+		default:
+			words = append(words, arg)
+		}
+	}
+	if len(words) == 0 {
+		fmt.Fprintf(os.Stderr, "Usage: chief similar <text> [--limit N]\n")
+		os.Exit(1)
+	}
+	opts.Query = strings.Join(words, " ")
+
+	if err := cmd.RunSimilar(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runBackup() {
+	// Parse arguments: chief backup [--workspace <dir>] [-o <file>] [--include-logs]
+	opts := cmd.BackupOptions{}
+	for i := 2; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		switch {
+		case arg == "--workspace" || arg == "-o" || arg == "--output":
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "Error: %s requires a value\n", arg)
+				os.Exit(1)
+			}
+			i++
+			if arg == "--workspace" {
+				opts.Workspace = os.Args[i]
+			} else {
+				opts.Output = os.Args[i]
+			}
+		case strings.HasPrefix(arg, "--workspace="):
+			opts.Workspace = strings.TrimPrefix(arg, "--workspace=")
+		case strings.HasPrefix(arg, "--output="):
+			opts.Output = strings.TrimPrefix(arg, "--output=")
+		case arg == "--include-logs":
+			opts.IncludeLogs = true
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unexpected argument: %s\n", arg)
+			os.Exit(1)
+		}
+	}
+
+	if err := cmd.RunBackup(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runRestore() {
+	// Parse arguments: chief restore <file> [--workspace <dir>] [--force]
+	opts := cmd.RestoreOptions{}
+	for i := 2; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		switch {
+		case arg == "--workspace":
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "Error: --workspace requires a value\n")
+				os.Exit(1)
+			}
+			i++
+			opts.Workspace = os.Args[i]
+		case strings.HasPrefix(arg, "--workspace="):
+			opts.Workspace = strings.TrimPrefix(arg, "--workspace=")
+		case arg == "--force":
+			opts.Force = true
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+			os.Exit(1)
+		default:
+			opts.Path = arg
+		}
+	}
+	if opts.Path == "" {
+		fmt.Fprintf(os.Stderr, "Usage: chief restore <file> [--workspace <dir>] [--force]\n")
+		os.Exit(1)
+	}
+
+	if err := cmd.RunRestore(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runBundle() {
+	// Parse arguments: chief bundle export [name] [-o <file>]
+	//                  chief bundle import <file> [--name <name>] [--force]
+	if len(os.Args) < 3 {
+		fmt.Fprintf(os.Stderr, "Usage: chief bundle export [name] [-o <file>]\n       chief bundle import <file> [--name <name>] [--force]\n")
+		os.Exit(1)
+	}
+
+	// flagValue returns the value for a flag that takes an argument, exiting if it's missing.
+	flagValue := func(i int) string {
+		if i+1 >= len(os.Args) {
+			fmt.Fprintf(os.Stderr, "Error: %s requires a value\n", os.Args[i])
+			os.Exit(1)
+		}
+		return os.Args[i+1]
+	}
+
+	var err error
+	switch os.Args[2] {
+	case "export":
+		opts := cmd.BundleExportOptions{}
+		for i := 3; i < len(os.Args); i++ {
+			arg := os.Args[i]
+			switch {
+			case arg == "-o" || arg == "--output":
+				opts.Output = flagValue(i)
+				i++
+			case strings.HasPrefix(arg, "--output="):
+				opts.Output = strings.TrimPrefix(arg, "--output=")
+			case strings.HasPrefix(arg, "-"):
+				fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+				os.Exit(1)
+			default:
+				opts.Name = arg
+			}
+		}
+		err = cmd.RunBundleExport(opts)
+	case "import":
+		opts := cmd.BundleImportOptions{}
+		for i := 3; i < len(os.Args); i++ {
+			arg := os.Args[i]
+			switch {
+			case arg == "--name":
+				opts.Name = flagValue(i)
+				i++
+			case strings.HasPrefix(arg, "--name="):
+				opts.Name = strings.TrimPrefix(arg, "--name=")
+			case arg == "--force":
+				opts.Force = true
+			case strings.HasPrefix(arg, "-"):
+				fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
+				os.Exit(1)
+			default:
+				opts.Path = arg
+			}
+		}
+		err = cmd.RunBundleImport(opts)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown bundle command: %s (expected export or import)\n", os.Args[2])
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// configureDisplay shows times in the timezone and clock style from
+// .chief/config.yaml. A config that can't be loaded is reported by the
+// command that needs it; a bad display setting is only warned about.
+func configureDisplay() {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return
+	}
+	cfg, err := config.Load(cwd)
+	if err != nil {
+		return
+	}
+	if err := timefmt.Configure(cfg.Display.Timezone, cfg.Display.Clock); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// resolveProvider loads config and resolves the agent provider, exiting on error.
+func resolveProvider(flagAgent, flagPath, flagModel string) loop.Provider {
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	cfg, err := config.Load(cwd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load .chief/config.yaml: %v\n", err)
+		os.Exit(1)
+	}
+	provider, err := agent.Resolve(flagAgent, flagPath, flagModel, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := agent.CheckInstalled(provider); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	// The proxy lives as long as this process; agent commands are its children
+	proxy, err := network.Start(cfg.Network, provider.Name(), cwd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if env := network.AgentEnv(cfg.Network, cwd, proxy); env != nil {
+		return &network.Provider{Provider: provider, Env: env}
+	}
+	return provider
+}
+
+func runTUIWithOptions(opts *TUIOptions) {
+	provider := resolveProvider(opts.Agent, opts.AgentPath, opts.Model)
+
+	prdPath := opts.PRDPath
+
+	// If no PRD specified, try to find one
+	if prdPath == "" {
+		// Try "main" first
+		mainPath := ".chief/prds/main/prd.md"
+		if _, err := os.Stat(mainPath); err == nil {
+			prdPath = mainPath
+		} else {
+			// Look for any available PRD
+			prdPath = findAvailablePRD()
+		}
+
+		// If still no PRD found, run first-time setup
+		if prdPath == "" && opts.A11y {
+			fmt.Println("No PRD found. Create one with:")
+			fmt.Println("  chief new               # Create default PRD")
+			fmt.Println("  chief new <name>        # Create named PRD")
+			os.Exit(1)
+		}
+		if prdPath == "" {
+			cwd, _ := os.Getwd()
+			showGitignore := git.IsGitRepo(cwd) && !git.IsChiefIgnored(cwd)
+
+			// Run the first-time setup TUI
+			result, err := tui.RunFirstTimeSetup(cwd, showGitignore)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			if result.Cancelled {
+				return
+			}
+
+			// Save config from setup
+			cfg := config.Default()
+			cfg.OnComplete.Push = result.PushOnComplete
+			cfg.OnComplete.CreatePR = result.CreatePROnComplete
+			if err := config.Save(cwd, cfg); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to save config: %v\n", err)
+			}
+
+			// Create the PRD
+			newOpts := cmd.NewOptions{
+				Name:     result.PRDName,
+				Provider: provider,
+			}
+			if err := cmd.RunNew(newOpts); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			// Restart TUI with the new PRD
+			opts.PRDPath = fmt.Sprintf(".chief/prds/%s/prd.md", result.PRDName)
+			runTUIWithOptions(opts)
+			return
+		}
+	}
+
+	prdDir := filepath.Dir(prdPath)
+
+	// Auto-migrate: if prd.json exists alongside prd.md, migrate status
+	jsonPath := filepath.Join(prdDir, "prd.json")
+	if _, err := os.Stat(jsonPath); err == nil {
+		fmt.Println("Migrating status from prd.json to prd.md...")
+		if err := prd.MigrateFromJSON(prdDir); err != nil {
+			fmt.Printf("Warning: migration failed: %v\n", err)
+		} else {
+			fmt.Println("Migration complete (prd.json renamed to prd.json.bak).")
+		}
+	}
+
+	// Report progress as plain lines of text for screen readers
+	if opts.A11y {
+		err := cmd.RunPlain(cmd.PlainOptions{
+			PRDPath:       prdPath,
+			MaxIterations: opts.MaxIterations,
+			Verbose:       opts.Verbose,
+			NoRetry:       opts.NoRetry,
+			IgnoreQuiet:   opts.IgnoreQuiet,
+			Base:          opts.Base,
+			Labels:        opts.Labels,
+			Parallel:      opts.Parallel,
+			Provider:      provider,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	app, err := tui.NewAppWithOptions(prdPath, opts.MaxIterations, provider)
+	if err != nil {
+		// Check if this is a missing PRD file error
+		if os.IsNotExist(err) || strings.Contains(err.Error(), "no such file") {
+			fmt.Printf("PRD not found: %s\n", prdPath)
+			fmt.Println()
+			// Show available PRDs if any exist
+			available := listAvailablePRDs()
+			if len(available) > 0 {
+				fmt.Println("Available PRDs:")
+				for _, name := range available {
+					fmt.Printf("  chief %s\n", name)
+				}
+				fmt.Println()
+			}
+			fmt.Println("Or create a new one:")
+			fmt.Println("  chief new               # Create default PRD")
+			fmt.Println("  chief new <name>        # Create named PRD")
+		} else {
+			fmt.Printf("Error: %v\n", err)
+		}
+		os.Exit(1)
+	}
+
+	// Set verbose mode if requested
+	if opts.Verbose {
+		app.SetVerbose(true)
 	}
 
 	// Disable retry if requested
@@ -457,8 +1986,41 @@ func runTUIWithOptions(opts *TUIOptions) {
 		app.DisableRetry()
 	}
 
+	// Run through quiet hours if requested
+	if opts.IgnoreQuiet {
+		app.IgnoreQuietHours()
+	}
+
+	// Pin the run to a base commit if requested
+	if opts.Base != "" {
+		base, branch, err := cmd.PinBase(app.GetBaseDir(), filepath.Base(prdDir), opts.Base)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		app.SetBase(base, branch)
+	}
+
+	// Record the run's labels for filtering its history
+	if len(opts.Labels) > 0 {
+		app.SetLabels(opts.Labels)
+	}
+	if opts.Parallel > 1 {
+		app.SetParallel(opts.Parallel)
+	}
+
+	// Serve the control socket so other terminals can attach and control the
+	// loops. If another chief already serves this project, run without one.
 	p := tea.NewProgram(app, tea.WithAltScreen())
+	server, _ := control.Listen(app.GetBaseDir(), app.GetManager())
+	if server != nil {
+		server.SetHandler(tui.RemoteHandler(p))
+	}
+
 	model, err := p.Run()
+	if server != nil {
+		server.Close()
+	}
 	if err != nil {
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
@@ -508,17 +2070,79 @@ Usage:
 Commands:
   new [name] [context]      Create a new PRD interactively
   edit [name] [options]     Edit an existing PRD interactively
+  run [name] [--json]       Run a PRD without the TUI, for CI and cron; exits
+                            non-zero if stories are left undone; --profile <name>
+                            adds the flags of a profile from .chief/config.yaml
   status [name]             Show progress for a PRD (default: main)
-  list                      List all PRDs with progress
+  list [--sort <column>] [--filter <state>] [--json]
+                            List all PRDs with progress, last activity, and cost
+  validate [name]           Check a PRD for format problems before a run
+  validate-workspace [dir]  Check a workspace's projects for problems before serving it
+  bundle export [name]      Pack a PRD into a portable <name>.chief.tar.gz
+  bundle import <file>      Unpack a PRD bundle into .chief/prds/
+  attach [name] [--read-only]
+                            Watch (and control) a run from another terminal
+  pause [name]              Pause a running loop after its current iteration
+  pause --all [--maintenance [--reason <text>]]
+                            Pause every running loop; maintenance mode also
+                            refuses new runs until resume --all
+  resume <name> | --all     Resume a paused loop, or lift maintenance mode
+                            and resume every paused loop
+  top [--once]              Live CPU and memory of chief's agents and commands;
+                            kill a runaway one with x
+  protocol dump             Print the JSON Schema of the control socket protocol
+  deps scan                 Add upgrade stories for outdated or vulnerable dependencies
+  review security [name]    Run a read-only security review of a PRD's changes
+  tasks [--prd <name>]      List stories waiting on you (Owner: human)
+  tasks pass <story-id>     Mark a human task done, unblocking stories that depend on it
+  record <story-id>         Record a browser test for a story with a URL (Playwright)
+  profile [name] [--all] [--label <label>]
+                            Show where a run's time went, per story, and the slowest tool calls
+  compare-runs [name] <runA> <runB>
+                            Compare two runs of a PRD: outcomes, time and changed files
+  explain <story-id> [--prd <name>]
+                            Summarize how a story was implemented, for reviewers
+  why-failed <story-id> [--prd <name>]
+                            Analyze a failing story's attempts and suggest a rewrite
+  blame <file> [--summary]  Show the story and run behind each line (commits.trailers)
+  estimate [name]           Forecast each remaining story's time, iterations and tokens
+  watch-pr [name] [--interval <duration>] [--once]
+                            Turn review comments on a PRD's PR into stories, run them and push
+  do <task> [--rm]          Run a one-off task as a single-story PRD (task on stdin works too)
+  eval [name] [--min-score N]
+                            Score a PRD's changes against .chief/conventions.md
+  init-conventions [--force] [--dry-run]
+                            Draft .chief/conventions.md from the repo's linters, layout and tests
+  doctor                    Check that the project is ready for Chief to run
+  selftest [--keep]         Run a synthetic PRD with a fake agent to check Chief works here
+  clone <url> [dir]         Clone a repository, optionally shallow or sparse
+  similar <text>            Find existing stories similar to a description
+  backup                    Archive the .chief state of every project in a workspace
+  restore <file>            Restore .chief state from a backup archive
+  undo [--list]             Put back the files changed by the last destructive command
+  cache run -- <command>    Run a command, reusing its last passing result if no files changed
+  cache clear               Forget every cached command result
+  retention status          Show how much run data is kept and what the next cleanup removes
+  settings show [key]       Show settings from .chief/config.yaml
+  settings set <key> <value>
+                            Change a setting in .chief/config.yaml
   update                    Update Chief to the latest version
   help                      Show this help message
 
 Global Options:
   --agent <provider>        Agent CLI to use: claude (default), codex, opencode, or cursor
   --agent-path <path>       Custom path to agent CLI binary
+  --model <model>           Model the agent runs with (e.g. opus, or a proxy's model)
   --max-iterations N, -n N  Set maximum iterations (default: dynamic)
   --no-retry                Disable auto-retry on agent crashes
+  --ignore-quiet-hours      Keep working through schedule.quietHours
+  --base <ref>              Start the PRD's branch at <ref> (needs a clean tree)
+  --label <name[=value]>    Label the run for filtering its history (repeatable)
+  --parallel N              Work on up to N independent stories at once, each in
+                            its own git worktree, merging them as they pass
   --verbose                 Show raw agent output in log
+  --a11y                    Print progress as plain lines of text instead of the
+                            full-screen TUI, for screen readers
   --merge                   Auto-merge progress on conversion conflicts
   --force                   Auto-overwrite on conversion conflicts
   --help, -h                Show this help message
@@ -540,6 +2164,11 @@ Examples:
   chief --max-iterations=5 auth
                             Launch auth PRD with 5 max iterations
   chief --verbose           Launch with raw agent output visible
+  chief auth --a11y         Run auth with plain text progress for screen readers
+  chief auth --base v2.3.0  Run auth on chief/auth, starting from the v2.3.0 tag
+  chief auth --parallel 3   Run up to three of auth's stories side by side
+  chief auth --label experiment --label model=opus
+                            Label the run so its history can be kept apart
   chief --agent codex       Use Codex CLI instead of Claude
   chief --agent cursor      Use Cursor CLI as agent
   chief new                 Create PRD in .chief/prds/main/
@@ -549,9 +2178,64 @@ Examples:
   chief edit                Edit PRD in .chief/prds/main/
   chief edit auth           Edit PRD in .chief/prds/auth/
   chief edit auth --merge   Edit and auto-merge progress
+  chief run auth --json     Run auth in CI, printing progress as JSON lines
+  chief run auth --profile overnight
+                            Run auth with the flags of the overnight profile
   chief status              Show progress for default PRD
   chief status auth         Show progress for auth PRD
   chief list                List all PRDs with progress
+  chief list --filter stalled --sort activity
+                            List PRDs untouched for a week, most recent first
+  chief validate-workspace ~/code
+                            Preflight every project under ~/code
+  chief attach auth --read-only
+                            Follow the auth run started in another terminal
+  chief pause auth          Pause the auth loop from another terminal
+  chief pause --maintenance Pause everything for a host maintenance window
+  chief top                 See what chief's agents and test runs are using
+  chief deps scan           Write upgrade stories to .chief/prds/deps/
+  chief deps scan --dry-run List outdated dependencies without writing stories
+  chief review security auth
+                            Write a findings report to .chief/prds/auth/security-review.md
+  chief tasks pass US-004   Mark the human task US-004 done
+  chief record US-012       Click through US-012's page to record its browser test
+  chief profile auth        Break down the latest auth run into thinking, tools and verification
+  chief profile auth --all --without-label experiment
+                            Profile every auth run except the experiments
+  chief compare-runs auth 3 model=opus
+                            Compare run 3 of auth with the latest run labeled model=opus
+  chief explain US-012      Explain the files, commits and decisions behind US-012
+  chief why-failed US-031   Find the root cause of US-031's failed attempts
+  chief blame internal/auth/reset.go --summary
+                            Count the lines of reset.go each story wrote
+  chief estimate auth       Warn which auth stories are likely to exceed the run's limits
+  chief watch-pr auth --interval 10m
+                            Address new review comments on the auth PR every 10 minutes
+  chief do "add a /healthz endpoint" --rm
+                            Implement one small task, deleting its PRD once it passes
+  chief do < task.md        Take the task from stdin
+  chief eval auth --min-score 80
+                            Fail unless auth follows at least 80% of the conventions
+  chief init-conventions --dry-run
+                            Preview the conventions Chief would draft for this repo
+  chief clone git@github.com:acme/mono.git --depth 1 --sparse services/api
+                            Clone only the latest commit and one directory
+  chief similar "rate limiting"
+                            Check whether any PRD already covers rate limiting
+  chief backup --workspace ~/code
+                            Write chief-backup-<timestamp>.tar.gz (no logs)
+  chief restore chief-backup-20260301-120000.tar.gz --force
+                            Put every project's .chief state back
+  chief undo                Bring back the PRD the last "chief do --rm" deleted
+  chief doctor              Show the agent and verify command Chief will use
+  chief selftest            Check the loop, git, and TUI log work on this machine
+  chief settings set onComplete.push true
+                            Push the branch when a PRD completes
+  chief settings show --project ~/code/api
+                            List the settings of another project
+  chief bundle export auth  Write auth.chief.tar.gz (no logs)
+  chief bundle import auth.chief.tar.gz --name auth-v2
+                            Import a bundle under a new name
   chief --version           Show version number`)
 }
 
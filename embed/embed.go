@@ -4,6 +4,7 @@ package embed
 
 import (
 	_ "embed"
+	"strconv"
 	"strings"
 )
 
@@ -16,9 +17,54 @@ var initPromptTemplate string
 //go:embed edit_prompt.txt
 var editPromptTemplate string
 
+//go:embed wrapup_prompt.txt
+var wrapUpPromptTemplate string
+
+//go:embed security_review_prompt.txt
+var securityReviewPromptTemplate string
+
 //go:embed detect_setup_prompt.txt
 var detectSetupPromptTemplate string
 
+//go:embed explain_prompt.txt
+var explainPromptTemplate string
+
+//go:embed language_prompt.txt
+var languagePromptTemplate string
+
+//go:embed cache_prompt.txt
+var cachePromptTemplate string
+
+//go:embed scratch_prompt.txt
+var scratchPromptTemplate string
+
+//go:embed conventions_prompt.txt
+var conventionsPromptTemplate string
+
+//go:embed conventions_eval_prompt.txt
+var conventionsEvalPromptTemplate string
+
+//go:embed repomap_prompt.txt
+var repoMapPromptTemplate string
+
+//go:embed why_failed_prompt.txt
+var whyFailedPromptTemplate string
+
+//go:embed summary_prompt.txt
+var summaryPromptTemplate string
+
+//go:embed summarize_prompt.txt
+var summarizePromptTemplate string
+
+//go:embed explore_prompt.txt
+var explorePromptTemplate string
+
+//go:embed explore_findings_prompt.txt
+var exploreFindingsPromptTemplate string
+
+//go:embed estimate_prompt.txt
+var estimatePromptTemplate string
+
 // GetPrompt returns the agent prompt with the progress path and
 // current story context substituted. The storyContext is the JSON of the
 // current story to work on, inlined directly into the prompt so that the
@@ -30,6 +76,24 @@ func GetPrompt(progressPath, storyContext, storyID, storyTitle string) string {
 	return strings.ReplaceAll(result, "{{STORY_TITLE}}", storyTitle)
 }
 
+// GetWrapUpPrompt returns the prompt used after a story exceeds its time budget.
+// It asks the agent to commit or describe partial progress instead of continuing.
+func GetWrapUpPrompt(progressPath, storyID, storyTitle, timeout string) string {
+	result := strings.ReplaceAll(wrapUpPromptTemplate, "{{PROGRESS_PATH}}", progressPath)
+	result = strings.ReplaceAll(result, "{{STORY_ID}}", storyID)
+	result = strings.ReplaceAll(result, "{{STORY_TITLE}}", storyTitle)
+	return strings.ReplaceAll(result, "{{TIMEOUT}}", timeout)
+}
+
+// GetSecurityReviewPrompt returns the read-only security review prompt for the
+// changes between base and HEAD, with the report written to reportPath.
+func GetSecurityReviewPrompt(prdName, prdPath, base, reportPath string) string {
+	result := strings.ReplaceAll(securityReviewPromptTemplate, "{{PRD_NAME}}", prdName)
+	result = strings.ReplaceAll(result, "{{PRD_PATH}}", prdPath)
+	result = strings.ReplaceAll(result, "{{BASE}}", base)
+	return strings.ReplaceAll(result, "{{REPORT_PATH}}", reportPath)
+}
+
 // GetInitPrompt returns the PRD generator prompt with the PRD directory and optional context substituted.
 func GetInitPrompt(prdDir, context string) string {
 	if context == "" {
@@ -44,7 +108,156 @@ func GetEditPrompt(prdDir string) string {
 	return strings.ReplaceAll(editPromptTemplate, "{{PRD_DIR}}", prdDir)
 }
 
+// GetExplainPrompt returns the prompt for explaining how a story was
+// implemented. story, commits, and progress are pre-formatted markdown.
+func GetExplainPrompt(prdPath, storyID, storyTitle, story, commits, progress, explanationPath string) string {
+	result := strings.ReplaceAll(explainPromptTemplate, "{{PRD_PATH}}", prdPath)
+	result = strings.ReplaceAll(result, "{{STORY_ID}}", storyID)
+	result = strings.ReplaceAll(result, "{{STORY_TITLE}}", storyTitle)
+	result = strings.ReplaceAll(result, "{{STORY}}", story)
+	result = strings.ReplaceAll(result, "{{COMMITS}}", commits)
+	result = strings.ReplaceAll(result, "{{PROGRESS}}", progress)
+	return strings.ReplaceAll(result, "{{EXPLANATION_PATH}}", explanationPath)
+}
+
+// GetWhyFailedPrompt returns the prompt for analyzing why a story failed.
+// story, attempts, commits, progress, and evidence are pre-formatted markdown.
+func GetWhyFailedPrompt(prdPath, storyID, storyTitle, story, attempts, commits, progress, evidence, analysisPath string) string {
+	result := strings.ReplaceAll(whyFailedPromptTemplate, "{{PRD_PATH}}", prdPath)
+	result = strings.ReplaceAll(result, "{{STORY_ID}}", storyID)
+	result = strings.ReplaceAll(result, "{{STORY_TITLE}}", storyTitle)
+	result = strings.ReplaceAll(result, "{{STORY}}", story)
+	result = strings.ReplaceAll(result, "{{ATTEMPTS}}", attempts)
+	result = strings.ReplaceAll(result, "{{COMMITS}}", commits)
+	result = strings.ReplaceAll(result, "{{PROGRESS}}", progress)
+	result = strings.ReplaceAll(result, "{{EVIDENCE}}", evidence)
+	return strings.ReplaceAll(result, "{{ANALYSIS_PATH}}", analysisPath)
+}
+
+// GetSummaryPrompt returns the prompt section holding the summary of the
+// count stories completed so far. It returns "" when there is no summary.
+func GetSummaryPrompt(summary string, count int, progressPath string) string {
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return ""
+	}
+	result := strings.ReplaceAll(summaryPromptTemplate, "{{SUMMARY}}", summary)
+	result = strings.ReplaceAll(result, "{{COUNT}}", strconv.Itoa(count))
+	return strings.TrimRight(strings.ReplaceAll(result, "{{PROGRESS_PATH}}", progressPath), "\n")
+}
+
+// GetSummarizePrompt returns the prompt for summarizing the completed stories
+// of a PRD. stories is a pre-formatted markdown list; previousPath is the
+// summary being replaced ("" = none).
+func GetSummarizePrompt(prdPath, progressPath, stories, previousPath, summaryPath string) string {
+	previous := "There is no earlier summary"
+	if previousPath != "" {
+		previous = "`" + previousPath + "`: the earlier summary, to update rather than rewrite from scratch"
+	}
+	result := strings.ReplaceAll(summarizePromptTemplate, "{{PRD_PATH}}", prdPath)
+	result = strings.ReplaceAll(result, "{{PROGRESS_PATH}}", progressPath)
+	result = strings.ReplaceAll(result, "{{STORIES}}", stories)
+	result = strings.ReplaceAll(result, "{{PREVIOUS}}", previous)
+	return strings.ReplaceAll(result, "{{SUMMARY_PATH}}", summaryPath)
+}
+
+// GetExplorePrompt returns the prompt for a read-only exploration pass of
+// at most turns tool calls before a story is implemented.
+func GetExplorePrompt(prdPath, progressPath, story string, turns int, findingsPath string) string {
+	result := strings.ReplaceAll(explorePromptTemplate, "{{PRD_PATH}}", prdPath)
+	result = strings.ReplaceAll(result, "{{PROGRESS_PATH}}", progressPath)
+	result = strings.ReplaceAll(result, "{{STORY}}", story)
+	result = strings.ReplaceAll(result, "{{TURNS}}", strconv.Itoa(turns))
+	return strings.ReplaceAll(result, "{{FINDINGS_PATH}}", findingsPath)
+}
+
+// GetExploreFindingsPrompt returns the prompt section holding a story's
+// exploration findings. It returns "" when there are none.
+func GetExploreFindingsPrompt(storyID, findings, findingsPath string) string {
+	findings = strings.TrimSpace(findings)
+	if findings == "" {
+		return ""
+	}
+	result := strings.ReplaceAll(exploreFindingsPromptTemplate, "{{FINDINGS}}", findings)
+	result = strings.ReplaceAll(result, "{{STORY_ID}}", storyID)
+	return strings.TrimRight(strings.ReplaceAll(result, "{{FINDINGS_PATH}}", findingsPath), "\n")
+}
+
+// GetLanguagePrompt returns instructions to write prose in the given language
+// while keeping the structured parts of prd.md in English. It returns "" when
+// no language is set.
+func GetLanguagePrompt(language string) string {
+	language = strings.TrimSpace(language)
+	if language == "" {
+		return ""
+	}
+	return strings.ReplaceAll(languagePromptTemplate, "{{LANGUAGE}}", language)
+}
+
+// GetScratchPrompt returns instructions to keep temporary files in the
+// story's scratch directory. It returns "" when there is none.
+func GetScratchPrompt(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	return strings.ReplaceAll(scratchPromptTemplate, "{{DIR}}", dir)
+}
+
+// GetCachePrompt returns instructions to run the given command patterns
+// through `chief cache run`, where chief is the path of the chief binary. It
+// returns "" when there are no patterns.
+func GetCachePrompt(chief string, patterns []string) string {
+	if len(patterns) == 0 {
+		return ""
+	}
+	var list strings.Builder
+	for _, p := range patterns {
+		list.WriteString("- `" + p + "`\n")
+	}
+	result := strings.ReplaceAll(cachePromptTemplate, "{{COMMANDS}}", strings.TrimRight(list.String(), "\n"))
+	result = strings.ReplaceAll(result, "{{CHIEF}}", chief)
+	return strings.ReplaceAll(result, "{{EXAMPLE}}", strings.ReplaceAll(patterns[0], "*", ""))
+}
+
+// GetConventionsPrompt returns the project's conventions as a prompt section.
+// It returns "" when conventions is empty.
+func GetConventionsPrompt(conventions string) string {
+	conventions = strings.TrimSpace(conventions)
+	if conventions == "" {
+		return ""
+	}
+	return strings.ReplaceAll(conventionsPromptTemplate, "{{CONVENTIONS}}", conventions)
+}
+
+// GetConventionsEvalPrompt returns the read-only prompt for scoring the
+// changes between base and HEAD against checklist, with the scorecard written
+// to scorecardPath.
+func GetConventionsEvalPrompt(prdName, prdPath, base, checklist, scorecardPath string) string {
+	result := strings.ReplaceAll(conventionsEvalPromptTemplate, "{{PRD_NAME}}", prdName)
+	result = strings.ReplaceAll(result, "{{PRD_PATH}}", prdPath)
+	result = strings.ReplaceAll(result, "{{BASE}}", base)
+	result = strings.ReplaceAll(result, "{{CHECKLIST}}", checklist)
+	return strings.ReplaceAll(result, "{{SCORECARD_PATH}}", scorecardPath)
+}
+
+// GetRepoMapPrompt returns a prompt section listing the repository's files.
+// It returns "" when there are no files.
+func GetRepoMapPrompt(files []string) string {
+	if len(files) == 0 {
+		return ""
+	}
+	return strings.ReplaceAll(repoMapPromptTemplate, "{{FILES}}", strings.Join(files, "\n"))
+}
+
 // GetDetectSetupPrompt returns the prompt for detecting project setup commands.
 func GetDetectSetupPrompt() string {
 	return detectSetupPromptTemplate
 }
+
+// GetEstimatePrompt returns the prompt for estimating the remaining stories
+// of a PRD. stories is pre-formatted markdown.
+func GetEstimatePrompt(prdPath, stories, estimatePath string) string {
+	result := strings.ReplaceAll(estimatePromptTemplate, "{{PRD_PATH}}", prdPath)
+	result = strings.ReplaceAll(result, "{{STORIES}}", stories)
+	return strings.ReplaceAll(result, "{{ESTIMATE_PATH}}", estimatePath)
+}
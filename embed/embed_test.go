@@ -106,3 +106,70 @@ func TestGetEditPrompt(t *testing.T) {
 		t.Error("Expected prompt to contain the PRD directory path")
 	}
 }
+
+func TestGetSecurityReviewPrompt(t *testing.T) {
+	prompt := GetSecurityReviewPrompt("auth", "/test/prd.md", "abc123", "/test/security-review.md")
+	for _, want := range []string{"auth", "/test/prd.md", "git diff abc123..HEAD", "/test/security-review.md", "READ-ONLY"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected security review prompt to contain %q", want)
+		}
+	}
+	if strings.Contains(prompt, "{{") {
+		t.Error("Expected all placeholders to be substituted")
+	}
+}
+
+func TestGetEstimatePrompt(t *testing.T) {
+	prompt := GetEstimatePrompt("/test/prd.md", "### US-001: Login", "/test/estimate.md")
+	for _, want := range []string{"/test/prd.md", "### US-001: Login", "/test/estimate.md", "READ-ONLY"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected estimate prompt to contain %q", want)
+		}
+	}
+	if strings.Contains(prompt, "{{") {
+		t.Error("Expected all placeholders to be substituted")
+	}
+}
+
+func TestGetWrapUpPrompt(t *testing.T) {
+	prompt := GetWrapUpPrompt("/test/progress.md", "US-042", "Add login", "30m0s")
+	for _, want := range []string{"/test/progress.md", "US-042", "Add login", "30m0s"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected wrap-up prompt to contain %q", want)
+		}
+	}
+	if strings.Contains(prompt, "{{") {
+		t.Error("Expected all placeholders to be substituted")
+	}
+}
+
+func TestGetLanguagePrompt(t *testing.T) {
+	if got := GetLanguagePrompt(" "); got != "" {
+		t.Errorf("expected no instructions without a language, got %q", got)
+	}
+
+	prompt := GetLanguagePrompt("Japanese")
+	if strings.Contains(prompt, "{{LANGUAGE}}") {
+		t.Error("Expected {{LANGUAGE}} to be substituted")
+	}
+	for _, want := range []string{"in Japanese", "US-001", "**Status:**"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected language prompt to contain %q", want)
+		}
+	}
+}
+
+func TestGetScratchPrompt(t *testing.T) {
+	if got := GetScratchPrompt(""); got != "" {
+		t.Errorf("expected no instructions without a scratch directory, got %q", got)
+	}
+	prompt := GetScratchPrompt("/tmp/chief-scratch-US-001-123")
+	if strings.Contains(prompt, "{{DIR}}") {
+		t.Error("Expected {{DIR}} to be substituted")
+	}
+	for _, want := range []string{"/tmp/chief-scratch-US-001-123", "$CHIEF_SCRATCH"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected scratch prompt to contain %q", want)
+		}
+	}
+}
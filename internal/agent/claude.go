@@ -3,6 +3,8 @@ package agent
 import (
 	"context"
 	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"github.com/minicodemonkey/chief/internal/loop"
 )
@@ -10,6 +12,7 @@ import (
 // ClaudeProvider implements loop.Provider for the Claude Code CLI.
 type ClaudeProvider struct {
 	cliPath string
+	model   string // Passed as --model when set
 }
 
 // NewClaudeProvider returns a Provider for the Claude CLI.
@@ -21,6 +24,25 @@ func NewClaudeProvider(cliPath string) *ClaudeProvider {
 	return &ClaudeProvider{cliPath: cliPath}
 }
 
+// WithModel sets the model every invocation asks for with --model, e.g. a
+// local model served through LM Studio or OpenRouter. An empty model leaves
+// the choice to Claude.
+func (p *ClaudeProvider) WithModel(model string) *ClaudeProvider {
+	p.model = model
+	return p
+}
+
+// Model returns the model passed with --model, or "" for Claude's default.
+func (p *ClaudeProvider) Model() string { return p.model }
+
+// modelArgs returns the --model flag when a model is set.
+func (p *ClaudeProvider) modelArgs() []string {
+	if p.model == "" {
+		return nil
+	}
+	return []string{"--model", p.model}
+}
+
 // Name implements loop.Provider.
 func (p *ClaudeProvider) Name() string { return "Claude" }
 
@@ -29,19 +51,51 @@ func (p *ClaudeProvider) CLIPath() string { return p.cliPath }
 
 // LoopCommand implements loop.Provider.
 func (p *ClaudeProvider) LoopCommand(ctx context.Context, prompt, workDir string) *exec.Cmd {
-	cmd := exec.CommandContext(ctx, p.cliPath,
+	args := append([]string{
 		"--dangerously-skip-permissions",
 		"-p", prompt,
 		"--output-format", "stream-json",
 		"--verbose",
-	)
+	}, p.modelArgs()...)
+	cmd := exec.CommandContext(ctx, p.cliPath, args...)
 	cmd.Dir = workDir
 	return cmd
 }
 
+// readOnlyTools are the Claude tools a read-only pass may use: reading and
+// searching files, and the git commands that only show history.
+var readOnlyTools = []string{
+	"Read", "Grep", "Glob", "LS",
+	"Bash(git diff:*)", "Bash(git log:*)", "Bash(git show:*)", "Bash(git status:*)", "Bash(git blame:*)",
+}
+
+// ReadOnlyCommand implements loop.Provider. Permissions aren't skipped: only
+// readOnlyTools and writing outputs are allowed, and print mode denies every
+// other tool, including the rest of Bash and web access.
+func (p *ClaudeProvider) ReadOnlyCommand(ctx context.Context, prompt, workDir string, outputs ...string) (*exec.Cmd, error) {
+	tools := append([]string(nil), readOnlyTools...)
+	for _, out := range outputs {
+		if abs, err := filepath.Abs(out); err == nil {
+			out = abs
+		}
+		// A rule path starting with "//" is absolute
+		tools = append(tools, "Write(/"+out+")", "Edit(/"+out+")")
+	}
+	args := append([]string{
+		"--permission-mode", "default",
+		"--allowedTools", strings.Join(tools, ","),
+		"-p", prompt,
+		"--output-format", "stream-json",
+		"--verbose",
+	}, p.modelArgs()...)
+	cmd := exec.CommandContext(ctx, p.cliPath, args...)
+	cmd.Dir = workDir
+	return cmd, nil
+}
+
 // InteractiveCommand implements loop.Provider.
 func (p *ClaudeProvider) InteractiveCommand(workDir, prompt string) *exec.Cmd {
-	cmd := exec.Command(p.cliPath, prompt)
+	cmd := exec.Command(p.cliPath, append(p.modelArgs(), prompt)...)
 	cmd.Dir = workDir
 	return cmd
 }
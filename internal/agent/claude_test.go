@@ -2,6 +2,8 @@ package agent
 
 import (
 	"context"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/minicodemonkey/chief/internal/loop"
@@ -25,6 +27,26 @@ func TestClaudeProvider_CLIPath(t *testing.T) {
 	}
 }
 
+func TestClaudeProvider_Model(t *testing.T) {
+	p := NewClaudeProvider("/bin/claude").WithModel("qwen/qwen3-coder")
+
+	loopArgs := p.LoopCommand(context.Background(), "hello", "/work").Args
+	if n := len(loopArgs); n < 2 || loopArgs[n-2] != "--model" || loopArgs[n-1] != "qwen/qwen3-coder" {
+		t.Errorf("LoopCommand Args = %v, want --model qwen/qwen3-coder", loopArgs)
+	}
+	interactiveArgs := p.InteractiveCommand("/work", "hello").Args
+	want := []string{"/bin/claude", "--model", "qwen/qwen3-coder", "hello"}
+	if len(interactiveArgs) != len(want) {
+		t.Fatalf("InteractiveCommand Args = %v, want %v", interactiveArgs, want)
+	}
+	for i := range want {
+		if interactiveArgs[i] != want[i] {
+			t.Errorf("InteractiveCommand Args = %v, want %v", interactiveArgs, want)
+			break
+		}
+	}
+}
+
 func TestClaudeProvider_LogFileName(t *testing.T) {
 	p := NewClaudeProvider("")
 	if p.LogFileName() != "claude.log" {
@@ -54,6 +76,36 @@ func TestClaudeProvider_LoopCommand(t *testing.T) {
 	}
 }
 
+func TestClaudeProvider_ReadOnlyCommand(t *testing.T) {
+	p := NewClaudeProvider("/bin/claude")
+	cmd, err := p.ReadOnlyCommand(context.Background(), "hello world", "/work/dir", "/work/dir/.chief/report.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args := strings.Join(cmd.Args, " ")
+	if strings.Contains(args, "--dangerously-skip-permissions") {
+		t.Errorf("ReadOnlyCommand Args = %v, must not skip permissions", cmd.Args)
+	}
+	var tools string
+	for i, arg := range cmd.Args {
+		if arg == "--allowedTools" && i+1 < len(cmd.Args) {
+			tools = cmd.Args[i+1]
+		}
+	}
+	for _, want := range []string{"Read", "Bash(git diff:*)", "Write(//work/dir/.chief/report.md)"} {
+		if !slices.Contains(strings.Split(tools, ","), want) {
+			t.Errorf("allowed tools %q, want %s among them", tools, want)
+		}
+	}
+	if slices.Contains(strings.Split(tools, ","), "Bash") || slices.Contains(strings.Split(tools, ","), "Write") {
+		t.Errorf("allowed tools %q give unrestricted Bash or Write", tools)
+	}
+	if cmd.Dir != "/work/dir" {
+		t.Errorf("ReadOnlyCommand Dir = %q, want /work/dir", cmd.Dir)
+	}
+}
+
 func TestClaudeProvider_InteractiveCommand(t *testing.T) {
 	p := NewClaudeProvider("/bin/claude")
 	cmd := p.InteractiveCommand("/work", "my prompt")
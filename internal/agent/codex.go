@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/minicodemonkey/chief/internal/loop"
@@ -11,6 +12,7 @@ import (
 // CodexProvider implements loop.Provider for the Codex CLI.
 type CodexProvider struct {
 	cliPath string
+	model   string // Passed as --model when set
 }
 
 // NewCodexProvider returns a Provider for the Codex CLI.
@@ -22,6 +24,22 @@ func NewCodexProvider(cliPath string) *CodexProvider {
 	return &CodexProvider{cliPath: cliPath}
 }
 
+// WithModel sets the model every invocation asks for with --model, e.g. one
+// served by an OpenAI-compatible endpoint set up as a Codex model provider.
+// An empty model leaves the choice to Codex.
+func (p *CodexProvider) WithModel(model string) *CodexProvider {
+	p.model = model
+	return p
+}
+
+// modelArgs returns the --model flag when a model is set.
+func (p *CodexProvider) modelArgs() []string {
+	if p.model == "" {
+		return nil
+	}
+	return []string{"--model", p.model}
+}
+
 // Name implements loop.Provider.
 func (p *CodexProvider) Name() string { return "Codex" }
 
@@ -30,15 +48,32 @@ func (p *CodexProvider) CLIPath() string { return p.cliPath }
 
 // LoopCommand implements loop.Provider.
 func (p *CodexProvider) LoopCommand(ctx context.Context, prompt, workDir string) *exec.Cmd {
-	cmd := exec.CommandContext(ctx, p.cliPath, "exec", "--json", "--yolo", "--skip-git-repo-check", "-C", workDir, "-")
+	args := append([]string{"exec", "--json", "--yolo", "--skip-git-repo-check", "-C", workDir}, p.modelArgs()...)
+	cmd := exec.CommandContext(ctx, p.cliPath, append(args, "-")...)
 	cmd.Dir = workDir
 	cmd.Stdin = strings.NewReader(prompt)
 	return cmd
 }
 
+// ReadOnlyCommand implements loop.Provider. Codex can't limit writes to single
+// files, so instead of --yolo the pass runs in its workspace-write sandbox:
+// no network access, and no writes outside workDir and the outputs'
+// directories.
+func (p *CodexProvider) ReadOnlyCommand(ctx context.Context, prompt, workDir string, outputs ...string) (*exec.Cmd, error) {
+	args := []string{"exec", "--json", "--sandbox", "workspace-write", "--skip-git-repo-check", "-C", workDir}
+	for _, out := range outputs {
+		args = append(args, "--add-dir", filepath.Dir(out))
+	}
+	args = append(args, p.modelArgs()...)
+	cmd := exec.CommandContext(ctx, p.cliPath, append(args, "-")...)
+	cmd.Dir = workDir
+	cmd.Stdin = strings.NewReader(prompt)
+	return cmd, nil
+}
+
 // InteractiveCommand implements loop.Provider.
 func (p *CodexProvider) InteractiveCommand(workDir, prompt string) *exec.Cmd {
-	cmd := exec.Command(p.cliPath, prompt)
+	cmd := exec.Command(p.cliPath, append(p.modelArgs(), prompt)...)
 	cmd.Dir = workDir
 	return cmd
 }
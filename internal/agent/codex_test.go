@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"slices"
 	"testing"
 
 	"github.com/minicodemonkey/chief/internal/loop"
@@ -25,6 +26,17 @@ func TestCodexProvider_CLIPath(t *testing.T) {
 	}
 }
 
+func TestCodexProvider_Model(t *testing.T) {
+	p := NewCodexProvider("/bin/codex").WithModel("gpt-oss:20b")
+
+	if got, want := p.LoopCommand(context.Background(), "hello", "/work").Args, []string{"/bin/codex", "exec", "--json", "--yolo", "--skip-git-repo-check", "-C", "/work", "--model", "gpt-oss:20b", "-"}; !slices.Equal(got, want) {
+		t.Errorf("LoopCommand Args = %v, want %v", got, want)
+	}
+	if got, want := p.InteractiveCommand("/work", "hello").Args, []string{"/bin/codex", "--model", "gpt-oss:20b", "hello"}; !slices.Equal(got, want) {
+		t.Errorf("InteractiveCommand Args = %v, want %v", got, want)
+	}
+}
+
 func TestCodexProvider_LogFileName(t *testing.T) {
 	p := NewCodexProvider("")
 	if p.LogFileName() != "codex.log" {
@@ -59,6 +71,19 @@ func TestCodexProvider_LoopCommand(t *testing.T) {
 	// We can't easily read cmd.Stdin without running; just check it's non-nil (done above)
 }
 
+func TestCodexProvider_ReadOnlyCommand(t *testing.T) {
+	p := NewCodexProvider("/bin/codex")
+	cmd, err := p.ReadOnlyCommand(context.Background(), "hello world", "/work/dir", "/work/dir/.chief/report.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantArgs := []string{"/bin/codex", "exec", "--json", "--sandbox", "workspace-write", "--skip-git-repo-check", "-C", "/work/dir", "--add-dir", "/work/dir/.chief", "-"}
+	if !slices.Equal(cmd.Args, wantArgs) {
+		t.Errorf("ReadOnlyCommand Args = %v, want %v", cmd.Args, wantArgs)
+	}
+}
+
 func TestCodexProvider_InteractiveCommand(t *testing.T) {
 	p := NewCodexProvider("codex")
 	cmd := p.InteractiveCommand("/work", "my prompt")
@@ -44,6 +44,12 @@ func (p *CursorProvider) LoopCommand(ctx context.Context, prompt, workDir string
 	return cmd
 }
 
+// ReadOnlyCommand implements loop.Provider. Cursor CLI has no mode that keeps
+// it from writing, so read-only passes are refused.
+func (p *CursorProvider) ReadOnlyCommand(context.Context, string, string, ...string) (*exec.Cmd, error) {
+	return nil, errReadOnly(p)
+}
+
 // InteractiveCommand implements loop.Provider.
 func (p *CursorProvider) InteractiveCommand(workDir, prompt string) *exec.Cmd {
 	cmd := exec.Command(p.cliPath, prompt)
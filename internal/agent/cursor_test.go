@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/minicodemonkey/chief/internal/loop"
@@ -57,6 +58,17 @@ func TestCursorProvider_LoopCommand(t *testing.T) {
 	}
 }
 
+func TestCursorProvider_ReadOnlyCommand(t *testing.T) {
+	p := NewCursorProvider("agent")
+	cmd, err := p.ReadOnlyCommand(context.Background(), "hello", "/work", "/work/report.md")
+	if cmd != nil || !errors.Is(err, loop.ErrReadOnlyUnsupported) {
+		t.Fatalf("ReadOnlyCommand() = %v, %v; want ErrReadOnlyUnsupported", cmd, err)
+	}
+	if got := err.Error(); got != "Cursor can't be limited to reading the project; use Claude or Codex for read-only passes" {
+		t.Errorf("error = %q", got)
+	}
+}
+
 func TestCursorProvider_InteractiveCommand(t *testing.T) {
 	p := NewCursorProvider("/bin/agent")
 	cmd := p.InteractiveCommand("/work", "my prompt")
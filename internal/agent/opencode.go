@@ -11,6 +11,7 @@ import (
 
 type OpenCodeProvider struct {
 	cliPath string
+	model   string // Passed as --model when set
 }
 
 func NewOpenCodeProvider(cliPath string) *OpenCodeProvider {
@@ -20,18 +21,41 @@ func NewOpenCodeProvider(cliPath string) *OpenCodeProvider {
 	return &OpenCodeProvider{cliPath: cliPath}
 }
 
+// WithModel sets the model every invocation asks for with --model, in
+// OpenCode's provider/model form, e.g. one served by an OpenAI-compatible
+// provider in opencode.json. An empty model leaves the choice to OpenCode.
+func (p *OpenCodeProvider) WithModel(model string) *OpenCodeProvider {
+	p.model = model
+	return p
+}
+
+// modelArgs returns the --model flag when a model is set.
+func (p *OpenCodeProvider) modelArgs() []string {
+	if p.model == "" {
+		return nil
+	}
+	return []string{"--model", p.model}
+}
+
 func (p *OpenCodeProvider) Name() string { return "OpenCode" }
 
 func (p *OpenCodeProvider) CLIPath() string { return p.cliPath }
 
 func (p *OpenCodeProvider) LoopCommand(ctx context.Context, prompt, workDir string) *exec.Cmd {
-	cmd := exec.CommandContext(ctx, p.cliPath, "run", "--format", "json", prompt)
+	args := append([]string{"run", "--format", "json"}, p.modelArgs()...)
+	cmd := exec.CommandContext(ctx, p.cliPath, append(args, prompt)...)
 	cmd.Dir = workDir
 	return cmd
 }
 
+// ReadOnlyCommand implements loop.Provider. OpenCode has no mode that keeps
+// it from writing, so read-only passes are refused.
+func (p *OpenCodeProvider) ReadOnlyCommand(context.Context, string, string, ...string) (*exec.Cmd, error) {
+	return nil, errReadOnly(p)
+}
+
 func (p *OpenCodeProvider) InteractiveCommand(workDir, prompt string) *exec.Cmd {
-	cmd := exec.Command(p.cliPath, "--prompt", prompt)
+	cmd := exec.Command(p.cliPath, append(p.modelArgs(), "--prompt", prompt)...)
 	cmd.Dir = workDir
 	return cmd
 }
@@ -2,6 +2,9 @@ package agent
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/minicodemonkey/chief/internal/loop"
@@ -25,6 +28,17 @@ func TestOpenCodeProvider_CLIPath(t *testing.T) {
 	}
 }
 
+func TestOpenCodeProvider_Model(t *testing.T) {
+	p := NewOpenCodeProvider("/bin/opencode").WithModel("gpt-oss:20b")
+
+	if got, want := p.LoopCommand(context.Background(), "hello", "/work").Args, []string{"/bin/opencode", "run", "--format", "json", "--model", "gpt-oss:20b", "hello"}; !slices.Equal(got, want) {
+		t.Errorf("LoopCommand Args = %v, want %v", got, want)
+	}
+	if got, want := p.InteractiveCommand("/work", "hello").Args, []string{"/bin/opencode", "--model", "gpt-oss:20b", "--prompt", "hello"}; !slices.Equal(got, want) {
+		t.Errorf("InteractiveCommand Args = %v, want %v", got, want)
+	}
+}
+
 func TestOpenCodeProvider_LogFileName(t *testing.T) {
 	p := NewOpenCodeProvider("")
 	if p.LogFileName() != "opencode.log" {
@@ -95,6 +109,17 @@ func TestOpenCodeProvider_CleanOutput_NoTextEvent(t *testing.T) {
 	}
 }
 
+func TestOpenCodeProvider_ReadOnlyCommand(t *testing.T) {
+	p := NewOpenCodeProvider("opencode")
+	cmd, err := p.ReadOnlyCommand(context.Background(), "hello", "/work", "/work/report.md")
+	if cmd != nil || !errors.Is(err, loop.ErrReadOnlyUnsupported) {
+		t.Fatalf("ReadOnlyCommand() = %v, %v; want ErrReadOnlyUnsupported", cmd, err)
+	}
+	if !strings.Contains(err.Error(), "OpenCode") {
+		t.Errorf("error %q doesn't name the provider", err)
+	}
+}
+
 func TestOpenCodeProvider_InteractiveCommand(t *testing.T) {
 	p := NewOpenCodeProvider("opencode")
 	cmd := p.InteractiveCommand("/work", "my prompt")
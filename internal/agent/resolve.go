@@ -12,8 +12,10 @@ import (
 
 // Resolve returns the agent Provider using priority: flagAgent > CHIEF_AGENT env > config > "claude".
 // flagPath overrides the CLI path when non-empty (flag > CHIEF_AGENT_PATH > config agent.cliPath).
+// flagModel picks the model the same way (flag > CHIEF_MODEL > config agent.model); Claude,
+// Codex and OpenCode are run with it, Cursor ignores it.
 // Returns an error if the resolved provider name is not recognised.
-func Resolve(flagAgent, flagPath string, cfg *config.Config) (loop.Provider, error) {
+func Resolve(flagAgent, flagPath, flagModel string, cfg *config.Config) (loop.Provider, error) {
 	providerName := "claude"
 	if flagAgent != "" {
 		providerName = strings.ToLower(strings.TrimSpace(flagAgent))
@@ -32,13 +34,22 @@ func Resolve(flagAgent, flagPath string, cfg *config.Config) (loop.Provider, err
 		cliPath = strings.TrimSpace(cfg.Agent.CLIPath)
 	}
 
+	model := ""
+	if flagModel != "" {
+		model = strings.TrimSpace(flagModel)
+	} else if v := os.Getenv("CHIEF_MODEL"); v != "" {
+		model = strings.TrimSpace(v)
+	} else if cfg != nil && cfg.Agent.Model != "" {
+		model = strings.TrimSpace(cfg.Agent.Model)
+	}
+
 	switch providerName {
 	case "claude":
-		return NewClaudeProvider(cliPath), nil
+		return NewClaudeProvider(cliPath).WithModel(model), nil
 	case "codex":
-		return NewCodexProvider(cliPath), nil
+		return NewCodexProvider(cliPath).WithModel(model), nil
 	case "opencode":
-		return NewOpenCodeProvider(cliPath), nil
+		return NewOpenCodeProvider(cliPath).WithModel(model), nil
 	case "cursor":
 		return NewCursorProvider(cliPath), nil
 	default:
@@ -54,3 +65,9 @@ func CheckInstalled(p loop.Provider) error {
 	}
 	return nil
 }
+
+// errReadOnly is the error ReadOnlyCommand returns for providers that can't be
+// kept from changing the project.
+func errReadOnly(p loop.Provider) error {
+	return fmt.Errorf("%s %w; use Claude or Codex for read-only passes", p.Name(), loop.ErrReadOnlyUnsupported)
+}
@@ -13,7 +13,7 @@ import (
 
 func mustResolve(t *testing.T, flagAgent, flagPath string, cfg *config.Config) loop.Provider {
 	t.Helper()
-	p, err := Resolve(flagAgent, flagPath, cfg)
+	p, err := Resolve(flagAgent, flagPath, "", cfg)
 	if err != nil {
 		t.Fatalf("Resolve(%q, %q, cfg) unexpected error: %v", flagAgent, flagPath, err)
 	}
@@ -150,8 +150,33 @@ func TestResolve_cursor(t *testing.T) {
 	}
 }
 
+func TestResolve_model(t *testing.T) {
+	t.Setenv("CHIEF_MODEL", "")
+	cfg := &config.Config{}
+	cfg.Agent.Model = "qwen/qwen3-coder"
+
+	model := func(flagModel string) string {
+		t.Helper()
+		p, err := Resolve("claude", "", flagModel, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p.(*ClaudeProvider).Model()
+	}
+	if got := model(""); got != "qwen/qwen3-coder" {
+		t.Errorf("config model = %q, want qwen/qwen3-coder", got)
+	}
+	t.Setenv("CHIEF_MODEL", "sonnet")
+	if got := model(""); got != "sonnet" {
+		t.Errorf("CHIEF_MODEL model = %q, want sonnet", got)
+	}
+	if got := model("opus"); got != "opus" {
+		t.Errorf("--model model = %q, want opus", got)
+	}
+}
+
 func TestResolve_unknownProvider(t *testing.T) {
-	_, err := Resolve("typo", "", nil)
+	_, err := Resolve("typo", "", "", nil)
 	if err == nil {
 		t.Fatal("Resolve(typo) expected error, got nil")
 	}
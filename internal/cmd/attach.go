@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/minicodemonkey/chief/internal/control"
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/tui"
+)
+
+// AttachOptions contains configuration for the attach command.
+type AttachOptions struct {
+	Name     string // PRD name to watch (default: the running PRD, or "main")
+	ReadOnly bool   // Watch without start/pause/stop controls
+	BaseDir  string // Project root of the running chief (default: current directory)
+}
+
+// RunAttach connects to a chief instance running in another terminal and
+// shows a live view of one of its PRDs.
+func RunAttach(opts AttachOptions) error {
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+
+	dial := control.Dial
+	if opts.ReadOnly {
+		dial = control.DialWatch
+	}
+	client, err := dial(opts.BaseDir)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if opts.Name == "" {
+		statuses, err := client.Status()
+		if err != nil {
+			return fmt.Errorf("failed to query running chief: %w", err)
+		}
+		opts.Name = pickAttachPRD(statuses)
+	}
+
+	if err := client.SubscribeWithHistory(opts.Name); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", opts.Name, err)
+	}
+
+	observer := tui.NewObserver(opts.BaseDir, opts.Name, client, opts.ReadOnly)
+	if _, err := tea.NewProgram(observer, tea.WithAltScreen()).Run(); err != nil {
+		return fmt.Errorf("error running program: %w", err)
+	}
+	return nil
+}
+
+// pickAttachPRD chooses which PRD to watch when none is named: the first
+// running one, otherwise the first known one, otherwise "main".
+func pickAttachPRD(statuses []control.PRDStatus) string {
+	for _, s := range statuses {
+		if s.State == loop.LoopStateRunning.String() {
+			return s.Name
+		}
+	}
+	if len(statuses) > 0 {
+		return statuses[0].Name
+	}
+	return "main"
+}
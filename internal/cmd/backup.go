@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/undo"
+	"github.com/minicodemonkey/chief/internal/workspace"
+)
+
+// maxBackupFileSize caps the size of a single file extracted from a backup.
+const maxBackupFileSize = 200 << 20
+
+// BackupOptions contains configuration for the backup command.
+type BackupOptions struct {
+	Workspace   string // Workspace or project directory (default: current directory)
+	Output      string // Archive path (default: chief-backup-<timestamp>.tar.gz in the current directory)
+	IncludeLogs bool   // Include agent logs
+}
+
+// RestoreOptions contains configuration for the restore command.
+type RestoreOptions struct {
+	Path      string // Archive created by RunBackup
+	Workspace string // Workspace or project directory (default: current directory)
+	Force     bool   // Overwrite files that already exist
+}
+
+// RunBackup archives the .chief directory of every project in a workspace.
+// Worktrees are left out since they are git checkouts that can be recreated,
+// and so are agent logs unless IncludeLogs is set.
+func RunBackup(opts BackupOptions) error {
+	if opts.Workspace == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.Workspace = cwd
+	}
+	if opts.Output == "" {
+		opts.Output = "chief-backup-" + time.Now().Format("20060102-150405") + ".tar.gz"
+	}
+
+	projects, err := workspace.Scan(opts.Workspace)
+	if err != nil {
+		return fmt.Errorf("failed to scan workspace %s: %w", opts.Workspace, err)
+	}
+	var withChief []workspace.Project
+	for _, p := range projects {
+		if p.HasChief {
+			withChief = append(withChief, p)
+		}
+	}
+	if len(withChief) == 0 {
+		return fmt.Errorf("no .chief directories found in %s", opts.Workspace)
+	}
+
+	f, err := os.Create(opts.Output)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	total := 0
+	for _, p := range withChief {
+		count, err := addChiefDir(tw, p, opts.IncludeLogs)
+		if err != nil {
+			os.Remove(opts.Output)
+			return fmt.Errorf("failed to back up %s: %w", p.Name, err)
+		}
+		fmt.Printf("  %s: %d file(s)\n", p.Name, count)
+		total += count
+	}
+	if err := tw.Close(); err != nil {
+		os.Remove(opts.Output)
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		os.Remove(opts.Output)
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	fmt.Printf("Backed up %d project(s), %d file(s) to %s\n", len(withChief), total, opts.Output)
+	return nil
+}
+
+// addChiefDir writes a project's .chief directory to tw under <project>/.chief/.
+func addChiefDir(tw *tar.Writer, p workspace.Project, includeLogs bool) (int, error) {
+	chiefDir := filepath.Join(p.Path, ".chief")
+	count := 0
+	err := filepath.WalkDir(chiefDir, func(file string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if file == filepath.Join(chiefDir, "worktrees") || file == filepath.Join(chiefDir, "cache") || file == undo.Dir(p.Path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || (!includeLogs && isLogFile(d.Name())) || d.Name() == loop.RunLockFile {
+			return nil
+		}
+
+		rel, err := filepath.Rel(p.Path, file)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hdr := &tar.Header{
+			Name:    path.Join(p.Name, filepath.ToSlash(rel)),
+			Mode:    int64(info.Mode().Perm()),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		src, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		if _, err := io.Copy(tw, src); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// RunRestore extracts a backup into the matching projects of a workspace.
+// Files that already exist are kept unless Force is set. Projects that aren't
+// in the workspace are skipped.
+func RunRestore(opts RestoreOptions) error {
+	if opts.Path == "" {
+		return fmt.Errorf("backup path is required")
+	}
+	if opts.Workspace == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.Workspace = cwd
+	}
+
+	f, err := os.Open(opts.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("invalid backup: %w", err)
+	}
+	defer gz.Close()
+
+	// Every file written is journaled so `chief undo` can put things back
+	journal, err := undo.Begin(opts.Workspace, "restore", fmt.Sprintf("Restored %s", filepath.Base(opts.Path)))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := journal.Commit(); err != nil {
+			fmt.Printf("  ! %v\n", err)
+		}
+	}()
+
+	restored, kept := 0, 0
+	var missing []string
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid backup: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return fmt.Errorf("invalid backup: unsupported entry %q", hdr.Name)
+		}
+		if hdr.Size > maxBackupFileSize {
+			return fmt.Errorf("invalid backup: %s is too large", hdr.Name)
+		}
+
+		project, rel, err := splitBackupPath(hdr.Name)
+		if err != nil {
+			return fmt.Errorf("invalid backup: %w", err)
+		}
+		projectDir := restoreTarget(opts.Workspace, project)
+		if projectDir == "" {
+			if !slices.Contains(missing, project) {
+				missing = append(missing, project)
+			}
+			continue
+		}
+
+		target := filepath.Join(projectDir, filepath.FromSlash(rel))
+		if _, err := os.Stat(target); err == nil && !opts.Force {
+			kept++
+			continue
+		}
+		saved, err := filepath.Rel(opts.Workspace, target)
+		if err != nil {
+			return err
+		}
+		if err := journal.Save(saved); err != nil {
+			return err
+		}
+		if err := writeRestoredFile(target, tr); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", hdr.Name, err)
+		}
+		restored++
+	}
+
+	for _, project := range missing {
+		fmt.Printf("  ! skipped %s: no such project in %s\n", project, opts.Workspace)
+	}
+	fmt.Printf("Restored %d file(s)", restored)
+	if kept > 0 {
+		fmt.Printf(", kept %d existing file(s) (use --force to overwrite)", kept)
+	}
+	fmt.Println()
+	return nil
+}
+
+// splitBackupPath splits an archive entry "<project>/.chief/<rest>" into the
+// project name and the path relative to the project. Anything outside a
+// project's .chief directory is rejected.
+func splitBackupPath(name string) (project, rel string, err error) {
+	clean := path.Clean(name)
+	project, rel, ok := strings.Cut(clean, "/")
+	if !ok || path.IsAbs(clean) || project == ".." || project == "." ||
+		!strings.HasPrefix(rel, ".chief/") {
+		return "", "", fmt.Errorf("unsafe path %q", name)
+	}
+	return project, rel, nil
+}
+
+// restoreTarget returns the directory of the named project in the workspace,
+// which may be the workspace directory itself, or "" if it doesn't exist.
+func restoreTarget(root, project string) string {
+	if info, err := os.Stat(filepath.Join(root, project)); err == nil && info.IsDir() {
+		return filepath.Join(root, project)
+	}
+	if abs, err := filepath.Abs(root); err == nil && filepath.Base(abs) == project {
+		return root
+	}
+	return ""
+}
+
+// writeRestoredFile writes r to target, creating parent directories.
+func writeRestoredFile(target string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, io.LimitReader(r, maxBackupFileSize))
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
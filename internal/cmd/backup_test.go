@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBackupRestore(t *testing.T) {
+	ws := t.TempDir()
+	writeFiles(t, ws, map[string]string{
+		"api/.chief/config.yaml":              "verify:\n  command: go test ./...\n",
+		"api/.chief/prds/main/prd.md":         "# API\n",
+		"api/.chief/prds/main/claude.log":     "log",
+		"api/.chief/worktrees/main/README.md": "checkout",
+		"web/.chief/prds/ui/prd.md":           "# UI\n",
+		"docs/.git/HEAD":                      "ref: refs/heads/main\n",
+	})
+
+	archive := filepath.Join(t.TempDir(), "backup.tar.gz")
+	if err := RunBackup(BackupOptions{Workspace: ws, Output: archive}); err != nil {
+		t.Fatalf("RunBackup() error = %v", err)
+	}
+
+	// Botch the api state, then restore it
+	if err := os.WriteFile(filepath.Join(ws, "api/.chief/prds/main/prd.md"), []byte("conflict"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(ws, "web/.chief")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RunRestore(RestoreOptions{Path: archive, Workspace: ws}); err != nil {
+		t.Fatalf("RunRestore() error = %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(ws, "web/.chief/prds/ui/prd.md")); string(got) != "# UI\n" {
+		t.Errorf("expected missing files to be restored, got %q", got)
+	}
+	if got, _ := os.ReadFile(filepath.Join(ws, "api/.chief/prds/main/prd.md")); string(got) != "conflict" {
+		t.Errorf("expected existing files to be kept without --force, got %q", got)
+	}
+
+	if err := RunRestore(RestoreOptions{Path: archive, Workspace: ws, Force: true}); err != nil {
+		t.Fatalf("RunRestore(--force) error = %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(ws, "api/.chief/prds/main/prd.md")); string(got) != "# API\n" {
+		t.Errorf("expected --force to overwrite, got %q", got)
+	}
+
+	// Logs and worktrees stay out of the backup
+	dest := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dest, "api"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunRestore(RestoreOptions{Path: archive, Workspace: dest}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"api/.chief/prds/main/claude.log", "api/.chief/worktrees"} {
+		if _, err := os.Stat(filepath.Join(dest, name)); err == nil {
+			t.Errorf("expected %s to be excluded from the backup", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dest, "web")); err == nil {
+		t.Error("expected projects missing from the workspace to be skipped")
+	}
+}
+
+func TestBackup_IncludeLogs(t *testing.T) {
+	ws := t.TempDir()
+	writeFiles(t, ws, map[string]string{"api/.chief/prds/main/claude.log": "log"})
+
+	archive := filepath.Join(t.TempDir(), "backup.tar.gz")
+	if err := RunBackup(BackupOptions{Workspace: ws, Output: archive, IncludeLogs: true}); err != nil {
+		t.Fatal(err)
+	}
+	dest := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dest, "api"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunRestore(RestoreOptions{Path: archive, Workspace: dest}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "api/.chief/prds/main/claude.log")); err != nil {
+		t.Errorf("expected logs to be included: %v", err)
+	}
+}
+
+func TestRestore_RejectsPathsOutsideChief(t *testing.T) {
+	for _, name := range []string{"api/src/main.go", "../etc/passwd", "api/.chief/../../x"} {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: 1, Typeflag: tar.TypeReg})
+		tw.Write([]byte("x"))
+		tw.Close()
+		gz.Close()
+
+		ws := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(ws, "api"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		archive := filepath.Join(t.TempDir(), "evil.tar.gz")
+		if err := os.WriteFile(archive, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := RunRestore(RestoreOptions{Path: archive, Workspace: ws}); err == nil {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/minicodemonkey/chief/internal/git"
+	"github.com/minicodemonkey/chief/internal/loop"
+)
+
+// maxDirtyFiles caps the uncommitted changes listed when refusing to pin a run.
+const maxDirtyFiles = 10
+
+// PinBase prepares a run of prdName pinned to ref, so it can be reproduced
+// from the same starting point: it checks out the PRD's branch (chief/<name>)
+// at the commit ref points to and returns that base and branch. It refuses to
+// start when the working tree has uncommitted changes, since they wouldn't be
+// part of the recorded base.
+func PinBase(baseDir, prdName, ref string) (loop.RunBase, string, error) {
+	if !git.IsGitRepo(baseDir) {
+		return loop.RunBase{}, "", fmt.Errorf("--base requires a git repository")
+	}
+
+	commit, err := git.ResolveCommit(baseDir, ref)
+	if err != nil {
+		return loop.RunBase{}, "", err
+	}
+
+	dirty, err := git.DirtyFiles(baseDir)
+	if err != nil {
+		return loop.RunBase{}, "", err
+	}
+	if len(dirty) > 0 {
+		shown := dirty
+		if len(shown) > maxDirtyFiles {
+			shown = append(shown[:maxDirtyFiles:maxDirtyFiles], fmt.Sprintf("... and %d more", len(dirty)-maxDirtyFiles))
+		}
+		return loop.RunBase{}, "", fmt.Errorf("the working tree has uncommitted changes; commit or stash them before pinning a run to %s:\n  %s", ref, strings.Join(shown, "\n  "))
+	}
+
+	branch := fmt.Sprintf("chief/%s", prdName)
+	if err := git.CheckoutBase(baseDir, branch, commit); err != nil {
+		return loop.RunBase{}, "", err
+	}
+	return loop.RunBase{Ref: ref, Commit: commit}, branch, nil
+}
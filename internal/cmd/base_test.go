@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPinBase(t *testing.T) {
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-b", "main"},
+		{"config", "user.email", "test@test.com"},
+		{"config", "user.name", "Test"},
+		{"commit", "--allow-empty", "-m", "initial"},
+		{"tag", "v2.3.0"},
+		{"commit", "--allow-empty", "-m", "later"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %s", args, out)
+		}
+	}
+
+	os.WriteFile(filepath.Join(dir, "wip.go"), []byte("package wip\n"), 0644)
+	if _, _, err := PinBase(dir, "auth", "v2.3.0"); err == nil || !strings.Contains(err.Error(), "wip.go") {
+		t.Fatalf("expected a dirty tree to be refused, got %v", err)
+	}
+	os.Remove(filepath.Join(dir, "wip.go"))
+
+	if _, _, err := PinBase(dir, "auth", "v9.9.9"); err == nil {
+		t.Error("expected an unknown ref to be refused")
+	}
+
+	base, branch, err := PinBase(dir, "auth", "v2.3.0")
+	if err != nil {
+		t.Fatalf("PinBase() error = %v", err)
+	}
+	tagged, _ := exec.Command("git", "-C", dir, "rev-parse", "v2.3.0").Output()
+	if base.Ref != "v2.3.0" || base.Commit != strings.TrimSpace(string(tagged)) {
+		t.Errorf("unexpected base %+v", base)
+	}
+	head, _ := exec.Command("git", "-C", dir, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if branch != "chief/auth" || strings.TrimSpace(string(head)) != branch {
+		t.Errorf("expected chief/auth to be checked out, got %s (HEAD %s)", branch, head)
+	}
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/minicodemonkey/chief/internal/git"
+)
+
+// BlameOptions contains configuration for the blame command.
+type BlameOptions struct {
+	File    string // File to blame, relative to BaseDir
+	BaseDir string // Repository directory (default: current directory)
+	Summary bool   // Count lines per story instead of listing them
+}
+
+// RunBlame shows the story and run behind each line of a file, read from
+// the Chief-Story and Chief-Run trailers of the commit that last changed it.
+func RunBlame(opts BlameOptions) error {
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+
+	lines, err := git.Blame(opts.BaseDir, opts.File)
+	if err != nil {
+		return err
+	}
+	if opts.Summary {
+		printBlameSummary(os.Stdout, lines)
+	} else {
+		printBlame(os.Stdout, lines)
+	}
+	return nil
+}
+
+// printBlame prints each line with its commit, story, and run.
+func printBlame(w io.Writer, lines []git.BlameLine) {
+	storyWidth, runWidth := 1, 1
+	for _, l := range lines {
+		storyWidth = max(storyWidth, len(l.Story))
+		runWidth = max(runWidth, len(l.Run))
+	}
+	numWidth := len(fmt.Sprint(len(lines)))
+	for _, l := range lines {
+		commit := "0000000"
+		if len(l.Commit) >= 7 {
+			commit = l.Commit[:7]
+		}
+		fmt.Fprintf(w, "%s  %-*s  %-*s  %*d) %s\n", commit, storyWidth, orDash(l.Story), runWidth, orDash(l.Run), numWidth, l.Number, l.Text)
+	}
+}
+
+// printBlameSummary prints how many lines of the file each story wrote,
+// most first, and how many came from commits without a story.
+func printBlameSummary(w io.Writer, lines []git.BlameLine) {
+	counts := make(map[string]int)
+	for _, l := range lines {
+		counts[l.Story]++
+	}
+	stories := make([]string, 0, len(counts))
+	for story := range counts {
+		if story != "" {
+			stories = append(stories, story)
+		}
+	}
+	sort.Slice(stories, func(i, j int) bool {
+		if counts[stories[i]] != counts[stories[j]] {
+			return counts[stories[i]] > counts[stories[j]]
+		}
+		return stories[i] < stories[j]
+	})
+	for _, story := range stories {
+		fmt.Fprintf(w, "%-12s %5d lines\n", story, counts[story])
+	}
+	if n := counts[""]; n > 0 {
+		fmt.Fprintf(w, "%-12s %5d lines\n", "(no story)", n)
+	}
+}
+
+// orDash returns s, or "-" when it is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
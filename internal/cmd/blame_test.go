@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/git"
+)
+
+func TestPrintBlame(t *testing.T) {
+	lines := []git.BlameLine{
+		{Number: 1, Commit: "a1b2c3d4e5", Text: "package auth", Provenance: git.Provenance{Story: "US-012", Run: "auth-20261016-091500"}},
+		{Number: 2, Commit: "f6e5d4c3b2", Text: ""},
+		{Number: 3, Text: "// TODO"},
+	}
+
+	var buf bytes.Buffer
+	printBlame(&buf, lines)
+	got := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	want := []string{
+		"a1b2c3d  US-012  auth-20261016-091500  1) package auth",
+		"f6e5d4c  -       -                     2) ",
+		"0000000  -       -                     3) // TODO",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("printBlame() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	buf.Reset()
+	printBlameSummary(&buf, lines)
+	if want := "US-012           1 lines\n(no story)       2 lines\n"; buf.String() != want {
+		t.Errorf("printBlameSummary() = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/prd"
+	"github.com/minicodemonkey/chief/internal/undo"
+)
+
+// BundleExtension is the file extension of PRD bundles.
+const BundleExtension = ".chief.tar.gz"
+
+// maxBundleFileSize caps the size of a single file extracted from a bundle.
+const maxBundleFileSize = 50 << 20
+
+// BundleExportOptions contains configuration for the bundle export command.
+type BundleExportOptions struct {
+	Name    string // PRD name to export (default: "main")
+	Output  string // Output file (default: <name>.chief.tar.gz in the current directory)
+	BaseDir string // Base directory for .chief/prds/ (default: current directory)
+}
+
+// BundleImportOptions contains configuration for the bundle import command.
+type BundleImportOptions struct {
+	Path    string // Bundle file to import
+	Name    string // PRD name to import as (default: the name stored in the bundle)
+	Force   bool   // Overwrite an existing PRD with the same name
+	BaseDir string // Base directory for .chief/prds/ (default: current directory)
+}
+
+// RunBundleExport packs a PRD directory into a portable tarball. Everything in
+// the PRD directory is included (prd.md, progress notes, attachments) except
+// agent logs and hidden files, which are machine-specific.
+func RunBundleExport(opts BundleExportOptions) error {
+	if opts.Name == "" {
+		opts.Name = "main"
+	}
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+	if opts.Output == "" {
+		opts.Output = opts.Name + BundleExtension
+	}
+
+	prdDir := filepath.Join(opts.BaseDir, ".chief", "prds", opts.Name)
+	if _, err := prd.LoadPRD(filepath.Join(prdDir, "prd.md")); err != nil {
+		return fmt.Errorf("failed to load PRD %q: %w", opts.Name, err)
+	}
+
+	f, err := os.Create(opts.Output)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer f.Close()
+
+	count, err := writeBundle(f, prdDir, opts.Name)
+	if err != nil {
+		os.Remove(opts.Output)
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	fmt.Printf("Exported PRD %q (%d file(s)) to %s\n", opts.Name, count, opts.Output)
+	return nil
+}
+
+// writeBundle writes the files of prdDir to w as a gzipped tarball rooted at name/.
+func writeBundle(w io.Writer, prdDir, name string) (int, error) {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	count := 0
+	err := filepath.WalkDir(prdDir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == prdDir {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() || isLogFile(d.Name()) || d.Name() == loop.RunLockFile || d.Name() == loop.RunStateFile {
+			return nil
+		}
+
+		rel, err := filepath.Rel(prdDir, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hdr := &tar.Header{
+			Name:    path.Join(name, filepath.ToSlash(rel)),
+			Mode:    0644,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		src, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		if _, err := io.Copy(tw, src); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return 0, err
+	}
+	return count, gz.Close()
+}
+
+// isLogFile reports whether a file is an agent or event log, or the events
+// feed, that should stay local.
+func isLogFile(name string) bool {
+	return strings.HasSuffix(name, ".log") || name == loop.EventLogFile || strings.HasPrefix(name, filepath.Base(loop.FeedFile))
+}
+
+// RunBundleImport unpacks a bundle created by RunBundleExport into .chief/prds/.
+func RunBundleImport(opts BundleImportOptions) error {
+	if opts.Path == "" {
+		return fmt.Errorf("bundle path is required")
+	}
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+
+	f, err := os.Open(opts.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer f.Close()
+
+	prdsDir := filepath.Join(opts.BaseDir, ".chief", "prds")
+	if err := os.MkdirAll(prdsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create .chief/prds: %w", err)
+	}
+
+	// Extract into a staging directory first so a bad bundle leaves nothing behind
+	staging, err := os.MkdirTemp(prdsDir, ".import-")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	bundleName, err := extractBundle(f, staging)
+	if err != nil {
+		return fmt.Errorf("invalid bundle: %w", err)
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = bundleName
+	}
+	if !isValidPRDName(name) {
+		return fmt.Errorf("invalid PRD name %q: must contain only letters, numbers, hyphens, and underscores", name)
+	}
+
+	src := filepath.Join(staging, bundleName)
+	if _, err := prd.LoadPRD(filepath.Join(src, "prd.md")); err != nil {
+		return fmt.Errorf("bundle does not contain a valid prd.md: %w", err)
+	}
+
+	dest := filepath.Join(prdsDir, name)
+	if _, err := os.Stat(dest); err == nil {
+		if !opts.Force {
+			return fmt.Errorf("PRD %q already exists (use --force to overwrite)", name)
+		}
+		if err := undo.Snapshot(opts.BaseDir, "bundle import --force", fmt.Sprintf("Replaced PRD %s with %s", name, filepath.Base(opts.Path)), filepath.Join(".chief", "prds", name)); err != nil {
+			return fmt.Errorf("failed to save the existing PRD for undo: %w", err)
+		}
+		if err := os.RemoveAll(dest); err != nil {
+			return fmt.Errorf("failed to remove existing PRD: %w", err)
+		}
+	}
+	if err := os.Rename(src, dest); err != nil {
+		return fmt.Errorf("failed to install PRD: %w", err)
+	}
+
+	fmt.Printf("Imported PRD %q into %s\n", name, dest)
+	fmt.Printf("Run it with: chief %s\n", name)
+	return nil
+}
+
+// extractBundle extracts a gzipped tarball into dir and returns the name of its
+// single top-level directory. Entries outside that directory, links, and
+// absolute or parent-relative paths are rejected.
+func extractBundle(r io.Reader, dir string) (string, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return "", err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	var root string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		clean := path.Clean(hdr.Name)
+		if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+			return "", fmt.Errorf("unsafe path %q", hdr.Name)
+		}
+		top, _, _ := strings.Cut(clean, "/")
+		if root == "" {
+			root = top
+		} else if top != root {
+			return "", fmt.Errorf("bundle contains more than one PRD (%q and %q)", root, top)
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(clean))
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return "", err
+			}
+		case tar.TypeReg:
+			if hdr.Size > maxBundleFileSize {
+				return "", fmt.Errorf("%s is too large", hdr.Name)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return "", err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				return "", err
+			}
+			_, err = io.Copy(out, io.LimitReader(tr, maxBundleFileSize))
+			out.Close()
+			if err != nil {
+				return "", err
+			}
+		default:
+			return "", fmt.Errorf("unsupported entry %q", hdr.Name)
+		}
+	}
+
+	if root == "" || root == "." {
+		return "", fmt.Errorf("bundle is empty")
+	}
+	return root, nil
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBundleExportImport(t *testing.T) {
+	src := t.TempDir()
+	prdDir := filepath.Join(src, ".chief", "prds", "auth")
+	if err := os.MkdirAll(filepath.Join(prdDir, "notes"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"prd.md":          "# Auth\n\n### US-001: Login\n",
+		"progress.md":     "## Progress\n",
+		"notes/design.md": "design",
+		"claude.log":      "secret log",
+		".DS_Store":       "junk",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(prdDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bundle := filepath.Join(t.TempDir(), "auth"+BundleExtension)
+	if err := RunBundleExport(BundleExportOptions{Name: "auth", Output: bundle, BaseDir: src}); err != nil {
+		t.Fatalf("RunBundleExport() error = %v", err)
+	}
+
+	dest := t.TempDir()
+	if err := RunBundleImport(BundleImportOptions{Path: bundle, BaseDir: dest}); err != nil {
+		t.Fatalf("RunBundleImport() error = %v", err)
+	}
+
+	imported := filepath.Join(dest, ".chief", "prds", "auth")
+	for _, name := range []string{"prd.md", "progress.md", "notes/design.md"} {
+		got, err := os.ReadFile(filepath.Join(imported, name))
+		if err != nil || string(got) != files[name] {
+			t.Errorf("expected %s to be imported, got %q (%v)", name, got, err)
+		}
+	}
+	for _, name := range []string{"claude.log", ".DS_Store"} {
+		if _, err := os.Stat(filepath.Join(imported, name)); err == nil {
+			t.Errorf("expected %s to be excluded from the bundle", name)
+		}
+	}
+
+	// Importing again requires --force, and a new name avoids the conflict
+	if err := RunBundleImport(BundleImportOptions{Path: bundle, BaseDir: dest}); err == nil {
+		t.Error("expected error when the PRD already exists")
+	}
+	if err := RunBundleImport(BundleImportOptions{Path: bundle, BaseDir: dest, Force: true}); err != nil {
+		t.Errorf("expected --force to overwrite, got %v", err)
+	}
+	if err := RunBundleImport(BundleImportOptions{Path: bundle, BaseDir: dest, Name: "auth-copy"}); err != nil {
+		t.Errorf("expected import under a new name to succeed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, ".chief", "prds", "auth-copy", "prd.md")); err != nil {
+		t.Error("expected PRD imported as auth-copy")
+	}
+}
+
+func TestBundleExport_MissingPRD(t *testing.T) {
+	err := RunBundleExport(BundleExportOptions{Name: "missing", Output: filepath.Join(t.TempDir(), "x.tar.gz"), BaseDir: t.TempDir()})
+	if err == nil {
+		t.Error("expected error for missing PRD")
+	}
+}
+
+func TestBundleImport_RejectsUnsafePaths(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	content := "# Evil\n"
+	if err := tw.WriteHeader(&tar.Header{Name: "../evil/prd.md", Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Write([]byte(content))
+	tw.Close()
+	gz.Close()
+
+	path := filepath.Join(t.TempDir(), "evil"+BundleExtension)
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := t.TempDir()
+	err := RunBundleImport(BundleImportOptions{Path: path, BaseDir: dest})
+	if err == nil || !strings.Contains(err.Error(), "unsafe path") {
+		t.Errorf("expected unsafe path error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, ".chief", "evil")); err == nil {
+		t.Error("expected nothing to be written outside .chief/prds")
+	}
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/toolcache"
+)
+
+// CacheOptions contains configuration for the cache commands.
+type CacheOptions struct {
+	Command string // Command to run (cache run only)
+	BaseDir string // Project root (default: $CHIEF_CACHE_DIR's project, or the nearest directory with .chief/)
+}
+
+// RunCacheRun runs a command through the project's tool cache and returns its
+// exit code. A command matching cache.commands whose last passing run was on
+// the same files isn't run again; its earlier output is printed after a
+// [chief cache] line instead. Other commands just run.
+func RunCacheRun(opts CacheOptions) (int, error) {
+	if opts.Command == "" {
+		return 0, fmt.Errorf("missing command")
+	}
+	cache, err := projectCache(opts.BaseDir)
+	if err != nil {
+		return 0, err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	var tree string
+	if cache.Matches(opts.Command) {
+		entry, t, err := cache.Lookup(cwd, opts.Command)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s Not caching: %v\n", toolcache.Marker, err)
+		}
+		if entry != nil {
+			fmt.Println(entry.Banner())
+			fmt.Print(entry.Output)
+			return 0, nil
+		}
+		tree = t
+	}
+
+	var out bytes.Buffer
+	c := exec.Command("sh", "-c", opts.Command)
+	c.Stdin = os.Stdin
+	c.Stdout = io.MultiWriter(os.Stdout, &out)
+	c.Stderr = io.MultiWriter(os.Stderr, &out)
+	err = c.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to run %q: %w", opts.Command, err)
+	}
+	if tree != "" {
+		if err := cache.Store(cwd, opts.Command, tree, out.String()); err != nil {
+			fmt.Fprintf(os.Stderr, "%s Failed to save the result: %v\n", toolcache.Marker, err)
+		}
+	}
+	return 0, nil
+}
+
+// RunCacheClear removes every cached result of the project.
+func RunCacheClear(opts CacheOptions) error {
+	cache, err := projectCache(opts.BaseDir)
+	if err != nil {
+		return err
+	}
+	if err := cache.Clear(); err != nil {
+		return fmt.Errorf("failed to clear %s: %w", cache.Dir, err)
+	}
+	fmt.Printf("Cleared %s\n", cache.Dir)
+	return nil
+}
+
+// projectCache returns the tool cache of the project, configured from its
+// config.yaml. The agent is started with the cache directory in
+// $CHIEF_CACHE_DIR, since it may be working in a worktree.
+func projectCache(baseDir string) (*toolcache.Cache, error) {
+	if baseDir == "" {
+		if dir := os.Getenv(toolcache.DirEnv); dir != "" {
+			baseDir = filepath.Dir(filepath.Dir(dir))
+		}
+	}
+	if baseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current directory: %w", err)
+		}
+		baseDir = findChiefRoot(cwd)
+	}
+
+	cfg, err := config.Load(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return &toolcache.Cache{
+		Dir:      toolcache.Dir(baseDir),
+		Patterns: cfg.Cache.Commands,
+		MaxAge:   time.Duration(cfg.Cache.MaxAgeMinutes) * time.Minute,
+	}, nil
+}
+
+// findChiefRoot returns the nearest directory at or above dir that holds a
+// .chief directory, or dir itself when there is none.
+func findChiefRoot(dir string) string {
+	for d := dir; ; {
+		if info, err := os.Stat(filepath.Join(d, ".chief")); err == nil && info.IsDir() {
+			return d
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return dir
+		}
+		d = parent
+	}
+}
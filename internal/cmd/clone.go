@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/minicodemonkey/chief/internal/git"
+)
+
+// CloneOptions contains configuration for the clone command.
+type CloneOptions struct {
+	URL          string
+	Dir          string   // Destination directory (default: derived from URL)
+	Depth        int      // History depth (0 = full)
+	Branch       string   // Branch to check out
+	SingleBranch bool     // Fetch only one branch
+	Sparse       []string // Directories to check out (empty = all)
+}
+
+// RunClone clones a repository to work on with Chief, optionally keeping the
+// checkout small with a shallow history and sparse paths.
+func RunClone(opts CloneOptions) error {
+	dir := opts.Dir
+	if dir == "" {
+		dir = git.CloneDir(opts.URL)
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("%s already exists", dir)
+	}
+
+	if err := git.Clone(git.CloneOptions{
+		URL:          opts.URL,
+		Dir:          dir,
+		Depth:        opts.Depth,
+		Branch:       opts.Branch,
+		SingleBranch: opts.SingleBranch,
+		SparsePaths:  opts.Sparse,
+		Output:       os.Stderr,
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("Cloned %s into %s\n", opts.URL, dir)
+	if len(opts.Sparse) > 0 {
+		fmt.Printf("Checked out only: %s (add more with `git sparse-checkout add <dir>`)\n", strings.Join(opts.Sparse, ", "))
+	}
+	if opts.Depth > 0 {
+		fmt.Printf("History limited to %d commit(s) (fetch more with `git fetch --deepen=<n>`)\n", opts.Depth)
+	}
+	fmt.Printf("\nNext: cd %s && chief new\n", dir)
+	return nil
+}
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/timefmt"
+)
+
+// compareFilesListed is how many files only one run changed are listed.
+const compareFilesListed = 10
+
+// CompareRunsOptions contains configuration for the compare-runs command.
+type CompareRunsOptions struct {
+	Name    string    // PRD name (default: "main")
+	BaseDir string    // Base directory for .chief/prds/ (default: current directory)
+	RunA    string    // Run number, "latest", or a label picking its latest run
+	RunB    string    // Same as RunA
+	Output  io.Writer // Where the report goes (default: stdout)
+}
+
+// RunCompareRuns compares two recorded runs of a PRD: which stories newly
+// pass or fail, how the time, iterations and tokens changed, and how much the
+// files they changed overlap.
+func RunCompareRuns(opts CompareRunsOptions) error {
+	if opts.Name == "" {
+		opts.Name = "main"
+	}
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+	if opts.Output == nil {
+		opts.Output = os.Stdout
+	}
+
+	path := filepath.Join(opts.BaseDir, ".chief", "prds", opts.Name, loop.EventLogFile)
+	records, err := loop.ReadEventLog(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no runs recorded for %s yet; the event log is written while a run is active", opts.Name)
+	}
+	if err != nil {
+		return err
+	}
+	runs := loop.SplitRuns(records)
+	a, err := resolveRun(runs, opts.RunA)
+	if err != nil {
+		return err
+	}
+	b, err := resolveRun(runs, opts.RunB)
+	if err != nil {
+		return err
+	}
+	if a.Number == b.Number {
+		return fmt.Errorf("%s and %s are both run #%d", opts.RunA, opts.RunB, a.Number)
+	}
+	printRunComparison(opts.Output, opts.Name, a, b)
+	return nil
+}
+
+// resolveRun picks a run by reference: its number in the event log, "latest",
+// or a label, which picks the latest run with that label.
+func resolveRun(runs [][]loop.EventRecord, ref string) (*loop.RunSummary, error) {
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("the event log has no runs")
+	}
+	if ref == "latest" {
+		return loop.SummarizeRun(len(runs), runs[len(runs)-1]), nil
+	}
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(runs) {
+			return nil, fmt.Errorf("no run #%d; the event log has %d runs", n, len(runs))
+		}
+		return loop.SummarizeRun(n, runs[n-1]), nil
+	}
+	if err := loop.ValidateLabel(ref); err != nil {
+		return nil, fmt.Errorf("invalid run %q: use a run number, latest, or a label", ref)
+	}
+	filter := loop.LabelFilter{With: []string{ref}}
+	for i := len(runs) - 1; i >= 0; i-- {
+		if filter.Match(runs[i][0].Labels) {
+			return loop.SummarizeRun(i+1, runs[i]), nil
+		}
+	}
+	return nil, fmt.Errorf("no run is labelled %s", ref)
+}
+
+// printRunComparison prints the comparison of run a with run b.
+func printRunComparison(w io.Writer, name string, a, b *loop.RunSummary) {
+	fmt.Fprintf(w, "Comparing runs of %s\n", name)
+	fmt.Fprintf(w, "  A: %s\n", describeRun(a))
+	fmt.Fprintf(w, "  B: %s\n\n", describeRun(b))
+
+	// Stories in the order A worked on them, then those only B reached
+	ids := make([]string, 0, len(a.Stories)+len(b.Stories))
+	for _, s := range a.Stories {
+		ids = append(ids, s.StoryID)
+	}
+	for _, s := range b.Stories {
+		if a.Story(s.StoryID) == nil {
+			ids = append(ids, s.StoryID)
+		}
+	}
+	fmt.Fprintf(w, "  %-10s %-22s %-22s %s\n", "Story", "A", "B", "Change")
+	var newlyPassing, newlyFailing []string
+	for _, id := range ids {
+		sa, sb := a.Story(id), b.Story(id)
+		change := ""
+		switch {
+		case storyPassed(sb) && !storyPassed(sa):
+			change = "newly passing"
+			newlyPassing = append(newlyPassing, id)
+		case storyPassed(sa) && !storyPassed(sb):
+			change = "newly failing"
+			newlyFailing = append(newlyFailing, id)
+		case sa != nil && sb != nil:
+			change = formatDurationDelta(sb.Duration - sa.Duration)
+		}
+		fmt.Fprintf(w, "  %-10s %-22s %-22s %s\n", id, describeOutcome(sa), describeOutcome(sb), change)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "  Passed:     %d -> %d (%+d)\n", a.Passed(), b.Passed(), b.Passed()-a.Passed())
+	fmt.Fprintf(w, "  Iterations: %d -> %d (%+d)\n", a.Iterations(), b.Iterations(), b.Iterations()-a.Iterations())
+	fmt.Fprintf(w, "  Duration:   %s -> %s (%s)\n", timefmt.Duration(a.Duration), timefmt.Duration(b.Duration), formatDurationDelta(b.Duration-a.Duration))
+	if a.Tokens > 0 || b.Tokens > 0 {
+		fmt.Fprintf(w, "  Tokens:     %s -> %s (%s)\n", formatTokens(a.Tokens), formatTokens(b.Tokens), formatTokenDelta(b.Tokens-a.Tokens))
+	}
+	if len(newlyPassing) > 0 {
+		fmt.Fprintf(w, "  Newly passing: %s\n", strings.Join(newlyPassing, ", "))
+	}
+	if len(newlyFailing) > 0 {
+		fmt.Fprintf(w, "  Newly failing: %s\n", strings.Join(newlyFailing, ", "))
+	}
+
+	both, onlyA, onlyB := splitFiles(a.Files, b.Files)
+	fmt.Fprintln(w)
+	if len(a.Files)+len(b.Files) == 0 {
+		fmt.Fprintln(w, "  Neither run recorded changed files.")
+		return
+	}
+	overlap := float64(len(both)) / float64(len(both)+len(onlyA)+len(onlyB)) * 100
+	fmt.Fprintf(w, "  Changed files: %d in both, %d only in A, %d only in B (%.0f%% overlap)\n", len(both), len(onlyA), len(onlyB), overlap)
+	printFileList(w, "Only in A", onlyA)
+	printFileList(w, "Only in B", onlyB)
+}
+
+// describeRun describes a run for the comparison header, e.g.
+// "run #3, started Mar 1 12:00, 14m2s [model=opus]".
+func describeRun(r *loop.RunSummary) string {
+	s := fmt.Sprintf("run #%d, started %s, %s", r.Number, timefmt.Stamp(r.Start), timefmt.Duration(r.Duration))
+	if len(r.Labels) > 0 {
+		s += " [" + strings.Join(r.Labels, ", ") + "]"
+	}
+	if base := r.Base; base != "" {
+		if len(base) > 12 {
+			base = base[:12]
+		}
+		s += " from " + base
+	}
+	return s
+}
+
+// describeOutcome describes how a story fared, e.g. "passed, 2 iter, 4m10s".
+func describeOutcome(s *loop.StoryOutcome) string {
+	if s == nil {
+		return "not reached"
+	}
+	status := "failed"
+	if s.Passed {
+		status = "passed"
+	}
+	return fmt.Sprintf("%s, %d iter, %s", status, s.Iterations, timefmt.Duration(s.Duration))
+}
+
+// storyPassed returns true if a story was reached and passed.
+func storyPassed(s *loop.StoryOutcome) bool {
+	return s != nil && s.Passed
+}
+
+// formatDurationDelta formats the change in a duration with its sign, e.g. "+1m5s".
+func formatDurationDelta(d time.Duration) string {
+	if d < 0 {
+		return "-" + timefmt.Duration(-d)
+	}
+	return "+" + timefmt.Duration(d)
+}
+
+// formatTokenDelta formats the change in tokens with its sign, e.g. "-120k".
+func formatTokenDelta(n int) string {
+	if n < 0 {
+		return "-" + formatTokens(-n)
+	}
+	return "+" + formatTokens(n)
+}
+
+// splitFiles splits two sorted file lists into the files in both and the
+// files in only one of them.
+func splitFiles(a, b []string) (both, onlyA, onlyB []string) {
+	inB := make(map[string]bool, len(b))
+	for _, f := range b {
+		inB[f] = true
+	}
+	inA := make(map[string]bool, len(a))
+	for _, f := range a {
+		inA[f] = true
+		if inB[f] {
+			both = append(both, f)
+		} else {
+			onlyA = append(onlyA, f)
+		}
+	}
+	for _, f := range b {
+		if !inA[f] {
+			onlyB = append(onlyB, f)
+		}
+	}
+	return both, onlyA, onlyB
+}
+
+// printFileList prints up to compareFilesListed files under a heading.
+func printFileList(w io.Writer, heading string, files []string) {
+	if len(files) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "  %s:\n", heading)
+	for i, f := range files {
+		if i == compareFilesListed {
+			fmt.Fprintf(w, "    ... and %d more\n", len(files)-compareFilesListed)
+			break
+		}
+		fmt.Fprintf(w, "    %s\n", f)
+	}
+}
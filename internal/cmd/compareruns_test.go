@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunCompareRuns(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdDir := filepath.Join(tmpDir, ".chief", "prds", "auth")
+	if err := os.MkdirAll(prdDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RunCompareRuns(CompareRunsOptions{Name: "auth", BaseDir: tmpDir, RunA: "1", RunB: "2"}); err == nil || !strings.Contains(err.Error(), "no runs recorded") {
+		t.Errorf("expected an error before any run, got %v", err)
+	}
+
+	log := `{"time":"2026-03-01T12:00:00Z","type":"RunStart"}
+{"time":"2026-03-01T12:00:01Z","type":"IterationStart","iteration":1,"storyId":"US-001"}
+{"time":"2026-03-01T12:00:05Z","type":"ToolStart","iteration":1,"tool":"Write","detail":"src/auth.go"}
+{"time":"2026-03-01T12:00:06Z","type":"ToolResult","iteration":1}
+{"time":"2026-03-01T12:01:00Z","type":"StoryDone","iteration":1}
+{"time":"2026-03-01T12:01:01Z","type":"IterationStart","iteration":2,"storyId":"US-002"}
+{"time":"2026-03-01T12:03:00Z","type":"Error","iteration":2}
+{"time":"2026-03-01T13:00:00Z","type":"RunStart","labels":["model=opus"]}
+{"time":"2026-03-01T13:00:01Z","type":"IterationStart","iteration":1,"storyId":"US-001"}
+{"time":"2026-03-01T13:00:05Z","type":"ToolStart","iteration":1,"tool":"Edit","detail":"src/auth.go","messageId":"msg_1","inputTokens":120000}
+{"time":"2026-03-01T13:00:06Z","type":"ToolResult","iteration":1}
+{"time":"2026-03-01T13:00:30Z","type":"StoryDone","iteration":1}
+{"time":"2026-03-01T13:00:31Z","type":"IterationStart","iteration":2,"storyId":"US-002"}
+{"time":"2026-03-01T13:00:35Z","type":"ToolStart","iteration":2,"tool":"Write","detail":"src/session.go"}
+{"time":"2026-03-01T13:00:36Z","type":"ToolResult","iteration":2}
+{"time":"2026-03-01T13:01:00Z","type":"StoryDone","iteration":2}
+`
+	if err := os.WriteFile(filepath.Join(prdDir, "events.jsonl"), []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := RunCompareRuns(CompareRunsOptions{Name: "auth", BaseDir: tmpDir, RunA: "1", RunB: "model=opus", Output: &out}); err != nil {
+		t.Fatalf("RunCompareRuns() error = %v", err)
+	}
+	for _, want := range []string{
+		"A: run #1",
+		"B: run #2",
+		"[model=opus]",
+		"US-002     failed, 1 iter, 2m00s",
+		"newly passing",
+		"Passed:     1 -> 2 (+1)",
+		"Duration:   3m00s -> 1m00s (-2m00s)",
+		"Tokens:     0 -> 120k (+120k)",
+		"1 in both, 0 only in A, 1 only in B (50% overlap)",
+		"src/session.go",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestResolveRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdDir := filepath.Join(tmpDir, ".chief", "prds", "auth")
+	if err := os.MkdirAll(prdDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	log := `{"time":"2026-03-01T12:00:00Z","type":"RunStart"}
+{"time":"2026-03-01T13:00:00Z","type":"RunStart"}
+`
+	if err := os.WriteFile(filepath.Join(prdDir, "events.jsonl"), []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"1", "3", "no run #3"},
+		{"latest", "2", "both run #2"},
+		{"1", "model=opus", "no run is labelled model=opus"},
+		{"1", "two words", "invalid run"},
+	}
+	for _, tt := range tests {
+		err := RunCompareRuns(CompareRunsOptions{Name: "auth", BaseDir: tmpDir, RunA: tt.a, RunB: tt.b})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("RunCompareRuns(%s, %s) error = %v, want %q", tt.a, tt.b, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/minicodemonkey/chief/internal/deps"
+)
+
+// DepsScanOptions contains configuration for the deps scan command.
+type DepsScanOptions struct {
+	Name    string // PRD to add upgrade stories to (default: "deps")
+	DryRun  bool   // Print findings without writing stories
+	BaseDir string // Project root to scan (default: current directory)
+}
+
+// RunDepsScan checks the project's dependencies and adds an upgrade story for
+// each outdated or vulnerable one to a PRD, ready to run like any other.
+func RunDepsScan(opts DepsScanOptions) error {
+	if opts.Name == "" {
+		opts.Name = "deps"
+	}
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+	if !isValidPRDName(opts.Name) {
+		return fmt.Errorf("invalid PRD name %q: must contain only letters, numbers, hyphens, and underscores", opts.Name)
+	}
+
+	updates, notes, err := deps.Scan(opts.BaseDir)
+	if err != nil {
+		return err
+	}
+	for _, note := range notes {
+		fmt.Printf("Note: %s\n", note)
+	}
+	if len(updates) == 0 {
+		fmt.Println("All dependencies are up to date")
+		return nil
+	}
+
+	for _, u := range updates {
+		marker := " "
+		if u.Vulnerable() {
+			marker = "!"
+		}
+		latest := u.Latest
+		if latest == "" {
+			latest = "(fix needed)"
+		}
+		fmt.Printf("%s %-40s %-12s → %s\n", marker, u.Name, u.Current, latest)
+	}
+	if opts.DryRun {
+		return nil
+	}
+
+	prdPath := filepath.Join(opts.BaseDir, ".chief", "prds", opts.Name, "prd.md")
+	added, err := deps.AddStories(prdPath, updates)
+	if err != nil {
+		return err
+	}
+	if len(added) == 0 {
+		fmt.Printf("\nEvery dependency above already has an open story in %s\n", opts.Name)
+		return nil
+	}
+	fmt.Printf("\nAdded %d upgrade story(s) to %s\n", len(added), prdPath)
+	fmt.Printf("Run them with: chief %s\n", opts.Name)
+	return nil
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/prd"
+	"github.com/minicodemonkey/chief/internal/undo"
+)
+
+// DoOptions contains configuration for the do command.
+type DoOptions struct {
+	Task          string        // What to do, in plain words
+	Name          string        // PRD name (default: "do-" plus the task's first words)
+	BaseDir       string        // Base directory for .chief/prds/ (default: current directory)
+	MaxIterations int           // Iterations before giving up (default: 5)
+	Remove        bool          // Delete the PRD once the task passes
+	Labels        []string      // Labels recorded with the run
+	Provider      loop.Provider // Agent CLI provider
+}
+
+// doStoryID is the ID of the one story in a task PRD.
+const doStoryID = "US-001"
+
+// maxTaskTitle caps the length of a task PRD's story title.
+const maxTaskTitle = 72
+
+// RunDo runs a one-off task with the same loop as a PRD: it writes a PRD
+// with a single story for the task, runs it until the story passes or the
+// iterations run out, and prints progress as it goes. It returns an error
+// when the task doesn't pass, leaving the PRD in place so it can be resumed
+// or inspected.
+func RunDo(opts DoOptions) error {
+	opts.Task = strings.TrimSpace(opts.Task)
+	if opts.Task == "" {
+		return fmt.Errorf("no task given; pass it as an argument or on stdin")
+	}
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+	if opts.MaxIterations <= 0 {
+		opts.MaxIterations = 5
+	}
+	if opts.Provider == nil {
+		return fmt.Errorf("do command requires Provider to be set")
+	}
+
+	prdsDir := filepath.Join(opts.BaseDir, ".chief", "prds")
+	if opts.Name == "" {
+		opts.Name = taskPRDName(prdsDir, opts.Task)
+	}
+	if !isValidPRDName(opts.Name) {
+		return fmt.Errorf("invalid PRD name %q: must contain only letters, numbers, hyphens, and underscores", opts.Name)
+	}
+	prdDir := filepath.Join(prdsDir, opts.Name)
+	prdPath := filepath.Join(prdDir, "prd.md")
+	if _, err := os.Stat(prdPath); err == nil {
+		return fmt.Errorf("PRD %q already exists; pick another --name", opts.Name)
+	}
+	if err := writeTaskPRD(prdPath, opts.Task); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(opts.BaseDir)
+	if err != nil {
+		cfg = config.Default()
+	}
+	manager := loop.NewManager(opts.MaxIterations, opts.Provider)
+	manager.SetBaseDir(opts.BaseDir)
+	manager.SetConfig(cfg)
+	if err := manager.Register(opts.Name, prdPath); err != nil {
+		return err
+	}
+	manager.SetLabels(opts.Name, opts.Labels)
+
+	fmt.Printf("Running %s with %s (PRD %s)...\n", taskTitle(opts.Task), opts.Provider.Name(), opts.Name)
+	if err := manager.Start(opts.Name); err != nil {
+		// Nothing ran, so the PRD is of no use to anyone
+		os.RemoveAll(prdDir)
+		return err
+	}
+
+	watchRun(manager, opts.Name, func(event loop.Event) {
+		if line := plainEvent(event, true); line != "" {
+			fmt.Println(line)
+		}
+	})
+
+	instance := manager.GetInstance(opts.Name)
+	switch {
+	case instance.State == loop.LoopStateComplete:
+	case instance.Error != nil:
+		return fmt.Errorf("%w\nThe PRD is kept at %s", instance.Error, prdDir)
+	case instance.State == loop.LoopStateStopped:
+		return fmt.Errorf("stopped; resume with `chief %s`", opts.Name)
+	default:
+		return fmt.Errorf("the task did not pass in %d iteration(s); see `chief why-failed %s --prd %s`", opts.MaxIterations, doStoryID, opts.Name)
+	}
+
+	fmt.Println("\nDone.")
+	if opts.Remove {
+		if err := undo.Snapshot(opts.BaseDir, "do --rm", fmt.Sprintf("Removed PRD %s", opts.Name), filepath.Join(".chief", "prds", opts.Name)); err != nil {
+			return fmt.Errorf("failed to save %s for undo; kept it: %w", prdDir, err)
+		}
+		if err := os.RemoveAll(prdDir); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", prdDir, err)
+		}
+		fmt.Printf("Removed PRD %s (restore it with `chief undo`)\n", opts.Name)
+	}
+	return nil
+}
+
+// writeTaskPRD writes a PRD with a single story for task.
+func writeTaskPRD(prdPath, task string) error {
+	if err := os.MkdirAll(filepath.Dir(prdPath), 0755); err != nil {
+		return fmt.Errorf("failed to create PRD directory: %w", err)
+	}
+	title := taskTitle(task)
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	b.WriteString("A one-off task run with `chief do`.\n\n")
+	fmt.Fprintf(&b, "### %s: %s\n\n", doStoryID, title)
+	fmt.Fprintf(&b, "**Description:** %s\n\n", strings.Join(strings.Fields(task), " "))
+	b.WriteString("- [ ] The task described above is done\n")
+	b.WriteString("- [ ] The project's existing tests and checks still pass\n")
+	if err := os.WriteFile(prdPath, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write PRD: %w", err)
+	}
+	if _, err := prd.ParseMarkdownPRD(prdPath); err != nil {
+		return fmt.Errorf("failed to write PRD: %w", err)
+	}
+	return nil
+}
+
+// taskTitle returns the task's first line, shortened to fit a story heading.
+func taskTitle(task string) string {
+	title, _, _ := strings.Cut(strings.TrimSpace(task), "\n")
+	title = strings.TrimSpace(title)
+	if runes := []rune(title); len(runes) > maxTaskTitle {
+		title = strings.TrimSpace(string(runes[:maxTaskTitle-3])) + "..."
+	}
+	return title
+}
+
+// taskPRDName derives a PRD name from the first words of task that isn't
+// taken in prdsDir, e.g. "do-add-a-healthz-endpoint".
+func taskPRDName(prdsDir, task string) string {
+	words := strings.FieldsFunc(strings.ToLower(task), func(r rune) bool {
+		return !(r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)))
+	})
+	if len(words) > 5 {
+		words = words[:5]
+	}
+	base := strings.Join(append([]string{"do"}, words...), "-")
+	name := base
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(prdsDir, name)); os.IsNotExist(err) {
+			return name
+		}
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+func TestWriteTaskPRD(t *testing.T) {
+	prdPath := filepath.Join(t.TempDir(), "do-add", "prd.md")
+	if err := writeTaskPRD(prdPath, "add a /healthz endpoint\nthat returns 200 when the DB is up"); err != nil {
+		t.Fatalf("writeTaskPRD: %v", err)
+	}
+
+	p, err := prd.ParseMarkdownPRD(prdPath)
+	if err != nil {
+		t.Fatalf("ParseMarkdownPRD: %v", err)
+	}
+	if len(p.UserStories) != 1 {
+		t.Fatalf("expected one story, got %d", len(p.UserStories))
+	}
+	story := p.UserStories[0]
+	if story.ID != doStoryID || story.Title != "add a /healthz endpoint" || story.Passes {
+		t.Errorf("unexpected story: %+v", story)
+	}
+	if story.Description != "add a /healthz endpoint that returns 200 when the DB is up" {
+		t.Errorf("unexpected description: %q", story.Description)
+	}
+	if len(story.AcceptanceCriteria) == 0 {
+		t.Error("expected acceptance criteria")
+	}
+}
+
+func TestTaskTitle(t *testing.T) {
+	long := strings.Repeat("word ", 30)
+	if title := taskTitle(long); len([]rune(title)) > maxTaskTitle || !strings.HasSuffix(title, "...") {
+		t.Errorf("expected a shortened title, got %q", title)
+	}
+	if title := taskTitle("  fix the login redirect  \nmore detail"); title != "fix the login redirect" {
+		t.Errorf("got %q", title)
+	}
+}
+
+func TestTaskPRDName(t *testing.T) {
+	prdsDir := t.TempDir()
+	name := taskPRDName(prdsDir, "Add a /healthz endpoint, then deploy it everywhere")
+	if name != "do-add-a-healthz-endpoint-then" {
+		t.Errorf("got %q", name)
+	}
+	if !isValidPRDName(name) {
+		t.Errorf("%q is not a valid PRD name", name)
+	}
+
+	os.MkdirAll(filepath.Join(prdsDir, name), 0755)
+	if next := taskPRDName(prdsDir, "Add a /healthz endpoint, then deploy it everywhere"); next != name+"-2" {
+		t.Errorf("expected a free name, got %q", next)
+	}
+}
+
+func TestRunDo_RequiresTask(t *testing.T) {
+	err := RunDo(DoOptions{Task: "  ", BaseDir: t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "no task") {
+		t.Errorf("expected a missing task error, got %v", err)
+	}
+}
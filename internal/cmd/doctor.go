@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/minicodemonkey/chief/internal/agent"
+	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/git"
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/network"
+	"github.com/minicodemonkey/chief/internal/verify"
+)
+
+// DoctorOptions contains configuration for the doctor command.
+type DoctorOptions struct {
+	BaseDir   string // Project directory (default: current directory)
+	Agent     string // Agent provider from --agent
+	AgentPath string // Agent CLI path from --agent-path
+	Model     string // Claude model from --model
+}
+
+// doctorReport collects check results and prints them as it goes.
+type doctorReport struct {
+	errors, warnings int
+}
+
+func (r *doctorReport) ok(format string, args ...any) {
+	fmt.Printf("  ✓ "+format+"\n", args...)
+}
+
+func (r *doctorReport) warn(format string, args ...any) {
+	r.warnings++
+	fmt.Printf("  ! "+format+"\n", args...)
+}
+
+func (r *doctorReport) fail(format string, args ...any) {
+	r.errors++
+	fmt.Printf("  ✗ "+format+"\n", args...)
+}
+
+// RunDoctor checks that a project is ready for Chief to run: it is a git
+// repository, its config parses, the agent CLI is installed and can reach its
+// model under the network policy, and stories can be verified. Returns an
+// error when any check fails.
+func RunDoctor(opts DoctorOptions) error {
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+
+	r := &doctorReport{}
+	fmt.Printf("Project: %s\n", opts.BaseDir)
+
+	if git.IsGitRepo(opts.BaseDir) {
+		r.ok("Git repository")
+	} else {
+		r.fail("Not a git repository; run `git init` first")
+	}
+
+	cfg, err := config.Load(opts.BaseDir)
+	switch {
+	case err != nil:
+		r.fail("Config: .chief/config.yaml is invalid: %v", err)
+		cfg = config.Default()
+	case config.Exists(opts.BaseDir):
+		r.ok("Config: .chief/config.yaml")
+	default:
+		r.ok("Config: defaults (no .chief/config.yaml)")
+	}
+
+	provider, err := agent.Resolve(opts.Agent, opts.AgentPath, opts.Model, cfg)
+	if err != nil {
+		r.fail("Agent: %v", err)
+	} else if err := agent.CheckInstalled(provider); err != nil {
+		r.fail("Agent: %v", err)
+	} else {
+		r.ok("Agent: %s (%s)", provider.Name(), provider.CLIPath())
+	}
+	checkNetwork(r, cfg.Network, opts.BaseDir, provider)
+
+	command, source := verify.Resolve("", cfg.Verify.Command, opts.BaseDir)
+	switch {
+	case command != "":
+		r.ok("Verify: %s (%s)", command, source)
+	case cfg.Verify.Command == verify.Disabled:
+		r.ok("Verify: off (%s)", source)
+	default:
+		r.warn("Verify: off (%s); set verify.command in .chief/config.yaml so stories are checked before they're marked done", source)
+	}
+	checkPRDVerify(r, opts.BaseDir, cfg)
+
+	fmt.Println()
+	if r.errors == 0 && r.warnings == 0 {
+		fmt.Println("No problems found.")
+		return nil
+	}
+	fmt.Printf("%d error(s), %d warning(s)\n", r.errors, r.warnings)
+	if r.errors > 0 {
+		return fmt.Errorf("%d check(s) failed", r.errors)
+	}
+	return nil
+}
+
+// checkNetwork reports the network policy, whether the agent can still reach
+// its model under it, and whether the CA bundle and client certificate load. provider may be nil when it couldn't be resolved.
+func checkNetwork(r *doctorReport, cfg config.NetworkConfig, baseDir string, provider loop.Provider) {
+	if err := network.Validate(cfg); err != nil {
+		r.fail("Network: %v", err)
+		return
+	}
+	if cfg.CABundle != "" {
+		if _, err := network.CertPool(cfg, baseDir); err != nil {
+			r.fail("Network: %v", err)
+		} else {
+			r.ok("Network: trusting the CAs in %s", cfg.CABundle)
+		}
+	}
+	if cfg.ClientCert != "" {
+		if _, err := network.ClientCertificate(cfg, baseDir); err != nil {
+			r.fail("Network: %v", err)
+		} else {
+			r.ok("Network: presenting the client certificate in %s", cfg.ClientCert)
+		}
+	}
+	policy := network.Policy(cfg)
+	if policy == network.PolicyOpen {
+		r.ok("Network: open")
+		return
+	}
+	if provider == nil {
+		r.ok("Network: %s", policy)
+		return
+	}
+
+	// The policy is only a proxy the agent's commands are pointed at
+	const proxied = "through the policy proxy; programs that ignore HTTP_PROXY aren't stopped"
+	hosts, known := network.AgentHosts(provider.Name())
+	switch {
+	case policy == network.PolicyOffline && known:
+		r.warn("Network: offline, but %s needs %s; use an agent that runs a local model, or the restricted policy", provider.Name(), strings.Join(hosts, ", "))
+	case policy == network.PolicyOffline:
+		r.ok("Network: offline (%s); %s must use a local model", proxied, provider.Name())
+	case !known && len(cfg.Allow) == 0:
+		r.warn("Network: restricted, but no hosts are allowed; add %s's model provider to network.allow", provider.Name())
+	default:
+		r.ok("Network: restricted to %s (%s)", strings.Join(network.AllowedHosts(cfg, provider.Name()), ", "), proxied)
+	}
+}
+
+// checkPRDVerify reports the verification command of each PRD that overrides it.
+func checkPRDVerify(r *doctorReport, baseDir string, cfg *config.Config) {
+	prdsDir := filepath.Join(baseDir, ".chief", "prds")
+	entries, err := os.ReadDir(prdsDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		prdCfg, err := config.LoadPRD(filepath.Join(prdsDir, entry.Name()))
+		if err != nil {
+			r.fail("Verify (%s): .chief/prds/%s/config.yaml is invalid: %v", entry.Name(), entry.Name(), err)
+			continue
+		}
+		if prdCfg.Verify.Command == "" {
+			continue
+		}
+		command, source := verify.Resolve(prdCfg.Verify.Command, cfg.Verify.Command, baseDir)
+		if command == "" {
+			command = "off"
+		}
+		r.ok("Verify (%s): %s (%s)", entry.Name(), command, source)
+	}
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/agent"
+	"github.com/minicodemonkey/chief/internal/config"
+)
+
+func TestRunDoctor_NotGitRepo(t *testing.T) {
+	err := RunDoctor(DoctorOptions{BaseDir: t.TempDir(), Agent: "claude", AgentPath: "true"})
+	if err == nil {
+		t.Error("expected doctor to fail outside a git repository")
+	}
+}
+
+func TestRunDoctor_InvalidPRDConfig(t *testing.T) {
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "-C", dir, "init").CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %s", out)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DoctorOptions{BaseDir: dir, Agent: "claude", AgentPath: "true"}
+	if err := RunDoctor(opts); err != nil {
+		t.Fatalf("expected a healthy project to pass, got %v", err)
+	}
+
+	prdDir := filepath.Join(dir, ".chief", "prds", "web")
+	if err := os.MkdirAll(prdDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(prdDir, "config.yaml"), []byte("verify: [\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunDoctor(opts); err == nil {
+		t.Error("expected doctor to fail on an unparsable PRD config")
+	}
+}
+
+func TestCheckNetwork(t *testing.T) {
+	claude, err := agent.Resolve("claude", "true", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		cfg            config.NetworkConfig
+		errs, warnings int
+	}{
+		{config.NetworkConfig{}, 0, 0},
+		{config.NetworkConfig{Policy: "restricted"}, 0, 0},
+		{config.NetworkConfig{Policy: "offline"}, 0, 1},
+		{config.NetworkConfig{Policy: "sealed"}, 1, 0},
+		{config.NetworkConfig{CABundle: "missing.pem"}, 1, 0},
+	}
+	for _, tc := range tests {
+		r := &doctorReport{}
+		checkNetwork(r, tc.cfg, t.TempDir(), claude)
+		if r.errors != tc.errs || r.warnings != tc.warnings {
+			t.Errorf("policy %q: got %d error(s), %d warning(s), want %d, %d", tc.cfg.Policy, r.errors, r.warnings, tc.errs, tc.warnings)
+		}
+	}
+}
@@ -45,8 +45,19 @@ func RunEdit(opts EditOptions) error {
 		return fmt.Errorf("PRD not found at %s. Use 'chief new %s' to create it first", prdMdPath, opts.Name)
 	}
 
+	// Remember the existing stories so only new ones are checked for duplicates
+	before := make(map[string]bool)
+	if p, err := prd.ParseMarkdownPRD(prdMdPath); err == nil {
+		for _, s := range p.UserStories {
+			before[s.ID] = true
+		}
+	}
+
 	// Get the edit prompt with the PRD directory path
 	prompt := embed.GetEditPrompt(prdDir)
+	if note := embed.GetLanguagePrompt(projectLanguage(opts.BaseDir)); note != "" {
+		prompt += "\n\n" + note
+	}
 	if opts.Provider == nil {
 		return fmt.Errorf("edit command requires Provider to be set")
 	}
@@ -63,8 +74,11 @@ func RunEdit(opts EditOptions) error {
 	fmt.Println("\nPRD editing complete!")
 
 	// Validate the edited prd.md can be parsed
-	if _, err := prd.ParseMarkdownPRD(prdMdPath); err != nil {
+	if p, err := prd.ParseMarkdownPRD(prdMdPath); err != nil {
 		fmt.Printf("Warning: prd.md could not be parsed: %v\n", err)
+	} else {
+		warnInvalid(p)
+		warnDuplicates(opts.BaseDir, opts.Name, p, before)
 	}
 
 	fmt.Printf("\nYour PRD is updated! Run 'chief' or 'chief %s' to continue working on it.\n", opts.Name)
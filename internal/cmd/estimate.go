@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/git"
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/prd"
+	"github.com/minicodemonkey/chief/internal/review"
+)
+
+// EstimateOptions contains configuration for the estimate command.
+type EstimateOptions struct {
+	Name     string        // PRD name (default: "main")
+	BaseDir  string        // Base directory for .chief/prds/ (default: current directory)
+	Provider loop.Provider // Agent CLI provider
+}
+
+// RunEstimate asks the agent, read-only, to forecast the complexity, time,
+// iterations, and tokens of each remaining story, prints the forecast, and
+// warns about stories likely to exceed the run's limits.
+func RunEstimate(opts EstimateOptions) error {
+	if opts.Name == "" {
+		opts.Name = "main"
+	}
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+	if opts.Provider == nil {
+		return fmt.Errorf("estimate command requires Provider to be set")
+	}
+
+	prdPath := filepath.Join(opts.BaseDir, ".chief", "prds", opts.Name, "prd.md")
+	p, err := prd.LoadPRD(prdPath)
+	if err != nil {
+		return fmt.Errorf("PRD %q not found", opts.Name)
+	}
+	var stories []prd.UserStory
+	for _, s := range p.UserStories {
+		if !s.Passes {
+			stories = append(stories, s)
+		}
+	}
+	if len(stories) == 0 {
+		fmt.Printf("All stories in %s are done; nothing to estimate.\n", opts.Name)
+		return nil
+	}
+
+	cfg, err := config.Load(opts.BaseDir)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Read the PRD's worktree when it already has one
+	workDir := opts.BaseDir
+	if wt := git.WorktreePathForPRD(opts.BaseDir, opts.Name); git.IsWorktree(wt) {
+		workDir = wt
+	}
+
+	fmt.Printf("Estimating %d stories of %s with %s...\n", len(stories), opts.Name, opts.Provider.Name())
+	estimates, err := review.Estimate(context.Background(), review.EstimateOptions{
+		Provider: opts.Provider,
+		PRDPath:  prdPath,
+		Stories:  stories,
+		WorkDir:  workDir,
+		Output:   os.Stdout,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	printEstimates(estimates)
+	warnings := estimateWarnings(estimates, cfg.Loop.StoryTimeoutMinutes, len(stories)+5)
+	if len(warnings) > 0 {
+		fmt.Println()
+		for _, w := range warnings {
+			fmt.Printf("  ! %s\n", w)
+		}
+	}
+	fmt.Printf("\nSaved to %s\n", filepath.Join(filepath.Dir(prdPath), review.EstimateFile))
+	return nil
+}
+
+// printEstimates prints the estimates as a table with a total row.
+func printEstimates(estimates []review.StoryEstimate) {
+	fmt.Printf("  %-10s %-10s %8s %10s %10s\n", "STORY", "COMPLEXITY", "MINUTES", "ITERATIONS", "TOKENS")
+	var minutes, iterations, tokens int
+	for _, e := range estimates {
+		fmt.Printf("  %-10s %-10s %8d %10d %10s\n", e.StoryID, e.Complexity, e.Minutes, e.Iterations, formatTokens(e.Tokens))
+		minutes += e.Minutes
+		iterations += e.Iterations
+		tokens += e.Tokens
+	}
+	fmt.Printf("  %-10s %-10s %8d %10d %10s\n", "Total", "", minutes, iterations, formatTokens(tokens))
+}
+
+// estimateWarnings returns a warning for each limit the estimates are likely
+// to exceed: the per-story time budget (0 = unlimited), the run's default
+// iteration limit, and stories rated HIGH that are better split before the
+// run.
+func estimateWarnings(estimates []review.StoryEstimate, storyTimeoutMinutes, maxIterations int) []string {
+	var warnings, high []string
+	iterations := 0
+	for _, e := range estimates {
+		iterations += e.Iterations
+		if storyTimeoutMinutes > 0 && e.Minutes > storyTimeoutMinutes {
+			warnings = append(warnings, fmt.Sprintf("%s: ~%d minutes exceeds loop.storyTimeoutMinutes (%d); the agent will be asked to wrap up early", e.StoryID, e.Minutes, storyTimeoutMinutes))
+		}
+		if e.Complexity == "HIGH" {
+			high = append(high, e.StoryID)
+		}
+	}
+	if iterations > maxIterations {
+		warnings = append(warnings, fmt.Sprintf("%d iterations expected but the run pauses after %d; raise it with --max-iterations", iterations, maxIterations))
+	}
+	if len(high) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%s rated HIGH; consider splitting before the run", strings.Join(high, ", ")))
+	}
+	return warnings
+}
+
+// formatTokens formats a token count compactly, e.g. "420k" or "1.2M".
+func formatTokens(n int) string {
+	switch {
+	case n >= 1000000:
+		return fmt.Sprintf("%.1fM", float64(n)/1000000)
+	case n >= 1000:
+		return fmt.Sprintf("%dk", n/1000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/review"
+)
+
+func TestEstimateWarnings(t *testing.T) {
+	estimates := []review.StoryEstimate{
+		{StoryID: "US-001", Complexity: "LOW", Minutes: 10, Iterations: 1},
+		{StoryID: "US-002", Complexity: "HIGH", Minutes: 60, Iterations: 6},
+	}
+
+	warnings := estimateWarnings(estimates, 30, 6)
+	if len(warnings) != 3 {
+		t.Fatalf("expected 3 warnings, got %q", warnings)
+	}
+	if !strings.HasPrefix(warnings[0], "US-002: ~60 minutes") {
+		t.Errorf("expected a story timeout warning, got %q", warnings[0])
+	}
+	if !strings.Contains(warnings[1], "7 iterations") {
+		t.Errorf("expected an iteration warning, got %q", warnings[1])
+	}
+	if !strings.HasPrefix(warnings[2], "US-002 rated HIGH") {
+		t.Errorf("expected a split warning, got %q", warnings[2])
+	}
+
+	if warnings := estimateWarnings(estimates[:1], 0, 6); len(warnings) != 0 {
+		t.Errorf("expected no warnings, got %q", warnings)
+	}
+}
+
+func TestFormatTokens(t *testing.T) {
+	for n, want := range map[int]string{900: "900", 420000: "420k", 1500000: "1.5M"} {
+		if got := formatTokens(n); got != want {
+			t.Errorf("formatTokens(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/minicodemonkey/chief/internal/git"
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/review"
+)
+
+// EvalOptions contains configuration for the eval command.
+type EvalOptions struct {
+	Name     string        // PRD name (default: "main")
+	BaseDir  string        // Base directory for .chief/prds/ (default: current directory)
+	MinScore int           // Lowest passing score, in percent (default: 100)
+	Provider loop.Provider // Agent CLI provider
+}
+
+// RunEval scores the changes a PRD run produced against the project's
+// conventions and prints the scorecard. It returns an error when the score is
+// below opts.MinScore, so CI can hold back the PR.
+func RunEval(opts EvalOptions) error {
+	if opts.Name == "" {
+		opts.Name = "main"
+	}
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+	if opts.MinScore == 0 {
+		opts.MinScore = 100
+	}
+	if opts.Provider == nil {
+		return fmt.Errorf("eval command requires Provider to be set")
+	}
+
+	prdPath := filepath.Join(opts.BaseDir, ".chief", "prds", opts.Name, "prd.md")
+	if _, err := os.Stat(prdPath); err != nil {
+		return fmt.Errorf("PRD %q not found", opts.Name)
+	}
+
+	// Check the PRD's worktree when it ran in one
+	workDir := opts.BaseDir
+	if wt := git.WorktreePathForPRD(opts.BaseDir, opts.Name); git.IsWorktree(wt) {
+		workDir = wt
+	}
+
+	fmt.Printf("Checking %s against the project's conventions with %s...\n", opts.Name, opts.Provider.Name())
+	scorecard, err := review.RunConventions(context.Background(), review.ConventionsOptions{
+		Provider: opts.Provider,
+		BaseDir:  opts.BaseDir,
+		PRDName:  opts.Name,
+		PRDPath:  prdPath,
+		WorkDir:  workDir,
+		Output:   os.Stdout,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	for _, c := range scorecard.Checks {
+		fmt.Printf("  [%s] %s\n", c.Verdict, c.Convention)
+		for _, note := range c.Notes {
+			fmt.Printf("         %s\n", note)
+		}
+	}
+	fmt.Printf("\nScore: %d%%\n", scorecard.Score)
+	fmt.Printf("Scorecard: %s\n", scorecard.Path)
+
+	if scorecard.Score < opts.MinScore {
+		return fmt.Errorf("score %d%% is below the minimum of %d%% (%d convention(s) broken)", scorecard.Score, opts.MinScore, len(scorecard.Failed()))
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/minicodemonkey/chief/internal/git"
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/prd"
+	"github.com/minicodemonkey/chief/internal/review"
+)
+
+// ExplainOptions contains configuration for the explain command.
+type ExplainOptions struct {
+	StoryID  string        // Story to explain, e.g. "US-012"
+	Name     string        // PRD name (default: the PRD containing the story)
+	BaseDir  string        // Base directory for .chief/prds/ (default: current directory)
+	Provider loop.Provider // Agent CLI provider
+}
+
+// RunExplain asks the agent, read-only, how a story was implemented and prints
+// its summary of the files, commits, and key decisions.
+func RunExplain(opts ExplainOptions) error {
+	if opts.StoryID == "" {
+		return fmt.Errorf("missing story ID")
+	}
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+	if opts.Provider == nil {
+		return fmt.Errorf("explain command requires Provider to be set")
+	}
+
+	name, story, err := findStory(opts.BaseDir, opts.Name, opts.StoryID)
+	if err != nil {
+		return err
+	}
+	prdPath := filepath.Join(opts.BaseDir, ".chief", "prds", name, "prd.md")
+
+	// Read the PRD's worktree when it ran in one
+	workDir := opts.BaseDir
+	if wt := git.WorktreePathForPRD(opts.BaseDir, name); git.IsWorktree(wt) {
+		workDir = wt
+	}
+
+	fmt.Printf("Explaining %s from %s with %s...\n", story.ID, name, opts.Provider.Name())
+	explanation, err := review.Explain(context.Background(), review.ExplainOptions{
+		Provider: opts.Provider,
+		PRDPath:  prdPath,
+		Story:    *story,
+		WorkDir:  workDir,
+		Output:   os.Stdout,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%s\n", strings.TrimSpace(explanation))
+	fmt.Printf("\nSaved to %s\n", review.ExplanationPath(prdPath, story.ID))
+	return nil
+}
+
+// findStory finds a story by ID, case-insensitively. Without a PRD name it
+// searches every PRD and fails when the ID is ambiguous.
+func findStory(baseDir, name, storyID string) (string, *prd.UserStory, error) {
+	prdsDir := filepath.Join(baseDir, ".chief", "prds")
+	names := []string{name}
+	if name == "" {
+		entries, err := os.ReadDir(prdsDir)
+		if err != nil {
+			return "", nil, fmt.Errorf("no PRDs found in %s", prdsDir)
+		}
+		names = nil
+		for _, entry := range entries {
+			if entry.IsDir() {
+				names = append(names, entry.Name())
+			}
+		}
+	}
+
+	var foundIn []string
+	var found *prd.UserStory
+	for _, n := range names {
+		p, err := prd.LoadPRD(filepath.Join(prdsDir, n, "prd.md"))
+		if err != nil {
+			if name != "" {
+				return "", nil, fmt.Errorf("PRD %q not found", name)
+			}
+			continue
+		}
+		for i := range p.UserStories {
+			if strings.EqualFold(p.UserStories[i].ID, storyID) {
+				foundIn = append(foundIn, n)
+				found = &p.UserStories[i]
+			}
+		}
+	}
+
+	switch len(foundIn) {
+	case 0:
+		if name != "" {
+			return "", nil, fmt.Errorf("story %s not found in PRD %q", storyID, name)
+		}
+		return "", nil, fmt.Errorf("story %s not found in any PRD", storyID)
+	case 1:
+		return foundIn[0], found, nil
+	default:
+		sort.Strings(foundIn)
+		return "", nil, fmt.Errorf("story %s is in several PRDs (%s); name one with --prd", storyID, strings.Join(foundIn, ", "))
+	}
+}
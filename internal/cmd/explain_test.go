@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindStory(t *testing.T) {
+	tmpDir := t.TempDir()
+	for name, stories := range map[string]string{
+		"auth":    "### US-001: Login\n- [ ] Works\n\n### US-002: Logout\n- [ ] Works\n",
+		"billing": "### US-001: Invoices\n- [ ] Works\n",
+	} {
+		prdDir := filepath.Join(tmpDir, ".chief", "prds", name)
+		if err := os.MkdirAll(prdDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(prdDir, "prd.md"), []byte("# "+name+"\n\n"+stories), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	name, story, err := findStory(tmpDir, "", "us-002")
+	if err != nil || name != "auth" || story.Title != "Logout" {
+		t.Errorf("findStory(us-002) = %q, %+v, %v", name, story, err)
+	}
+
+	if _, _, err := findStory(tmpDir, "", "US-001"); err == nil || !strings.Contains(err.Error(), "auth, billing") {
+		t.Errorf("expected an ambiguity error naming both PRDs, got %v", err)
+	}
+	name, story, err = findStory(tmpDir, "billing", "US-001")
+	if err != nil || name != "billing" || story.Title != "Invoices" {
+		t.Errorf("findStory(billing, US-001) = %q, %+v, %v", name, story, err)
+	}
+
+	if _, _, err := findStory(tmpDir, "", "US-009"); err == nil || !strings.Contains(err.Error(), "not found in any PRD") {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	if _, _, err := findStory(tmpDir, "missing", "US-001"); err == nil || !strings.Contains(err.Error(), `PRD "missing" not found`) {
+		t.Errorf("expected a missing PRD error, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/conventions"
+	"github.com/minicodemonkey/chief/internal/prompt"
+	"github.com/minicodemonkey/chief/internal/undo"
+)
+
+// InitConventionsOptions contains configuration for the init-conventions command.
+type InitConventionsOptions struct {
+	BaseDir string // Project root to scan (default: current directory)
+	Force   bool   // Overwrite an existing conventions file
+	DryRun  bool   // Print the draft without writing it
+}
+
+// RunInitConventions drafts .chief/conventions.md from the project's
+// manifests, linter configs, layout and tests, and turns on the conventions
+// prompt middleware so the agent follows it.
+func RunInitConventions(opts InitConventionsOptions) error {
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+
+	path := filepath.Join(opts.BaseDir, prompt.ConventionsFile)
+	if _, err := os.Stat(path); err == nil && !opts.Force && !opts.DryRun {
+		return fmt.Errorf("%s already exists; pass --force to replace it", prompt.ConventionsFile)
+	}
+
+	sections, err := conventions.Scan(opts.BaseDir)
+	if err != nil {
+		return err
+	}
+	if len(sections) == 0 {
+		return fmt.Errorf("found no manifests, linter configs or tests to draft conventions from; write %s by hand", prompt.ConventionsFile)
+	}
+	content := conventions.Render(sections)
+	if opts.DryRun {
+		fmt.Print(content)
+		return nil
+	}
+
+	if err := undo.Snapshot(opts.BaseDir, "init-conventions", "Drafted "+prompt.ConventionsFile, prompt.ConventionsFile, filepath.Join(".chief", "config.yaml")); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create .chief directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", prompt.ConventionsFile, err)
+	}
+	count := 0
+	for _, s := range sections {
+		count += len(s.Rules)
+	}
+	fmt.Printf("Wrote %d conventions to %s\n", count, path)
+
+	enabled, err := enableConventionsMiddleware(opts.BaseDir)
+	if err != nil {
+		return err
+	}
+	if enabled {
+		fmt.Println("Turned on the conventions prompt middleware in .chief/config.yaml")
+	}
+	fmt.Println("Review the draft and edit it to match how the team works; the agent follows it from the next iteration.")
+	return nil
+}
+
+// enableConventionsMiddleware adds the conventions middleware to the
+// project's prompt chain, keeping the default chain in front of it. It
+// returns false when the middleware was already on.
+func enableConventionsMiddleware(baseDir string) (bool, error) {
+	cfg, err := config.Load(baseDir)
+	if err != nil {
+		return false, fmt.Errorf("failed to load config: %w", err)
+	}
+	middlewares := cfg.Prompt.Middlewares
+	if len(middlewares) == 0 {
+		middlewares = prompt.DefaultMiddlewares
+	}
+	if slices.Contains(middlewares, "conventions") {
+		return false, nil
+	}
+	cfg.Prompt.Middlewares = append(slices.Clone(middlewares), "conventions")
+	if err := config.Save(baseDir, cfg); err != nil {
+		return false, fmt.Errorf("failed to save config: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/prompt"
+)
+
+func TestRunInitConventions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.22\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RunInitConventions(InitConventionsOptions{BaseDir: dir}); err != nil {
+		t.Fatalf("RunInitConventions() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, prompt.ConventionsFile))
+	if err != nil || !strings.Contains(string(data), "example.com/app") {
+		t.Fatalf("expected a drafted conventions file, got %q, %v", data, err)
+	}
+	cfg, err := config.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(cfg.Prompt.Middlewares, []string{"language", "conventions"}) {
+		t.Errorf("expected the conventions middleware after the defaults, got %v", cfg.Prompt.Middlewares)
+	}
+
+	if err := RunInitConventions(InitConventionsOptions{BaseDir: dir}); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("expected an existing file to be kept without --force, got %v", err)
+	}
+	if err := RunInitConventions(InitConventionsOptions{BaseDir: dir, Force: true}); err != nil {
+		t.Errorf("expected --force to replace the file, got %v", err)
+	}
+	if cfg, _ := config.Load(dir); len(cfg.Prompt.Middlewares) != 2 {
+		t.Errorf("expected the middleware to be added once, got %v", cfg.Prompt.Middlewares)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 
 	"github.com/minicodemonkey/chief/embed"
+	"github.com/minicodemonkey/chief/internal/config"
 	"github.com/minicodemonkey/chief/internal/loop"
 	"github.com/minicodemonkey/chief/internal/prd"
 )
@@ -54,6 +55,9 @@ func RunNew(opts NewOptions) error {
 
 	// Get the init prompt with the PRD directory path
 	prompt := embed.GetInitPrompt(prdDir, opts.Context)
+	if note := embed.GetLanguagePrompt(projectLanguage(opts.BaseDir)); note != "" {
+		prompt += "\n\n" + note
+	}
 	if opts.Provider == nil {
 		return fmt.Errorf("new command requires Provider to be set")
 	}
@@ -76,11 +80,13 @@ func RunNew(opts NewOptions) error {
 	}
 
 	// Validate the created prd.md can be parsed
-	if _, err := prd.ParseMarkdownPRD(prdMdPath); err != nil {
+	if p, err := prd.ParseMarkdownPRD(prdMdPath); err != nil {
 		fmt.Printf("\nWarning: prd.md was created but could not be parsed: %v\n", err)
 		fmt.Println("You may need to edit it to match the expected format.")
 	} else {
 		fmt.Println("\nPRD created successfully!")
+		warnInvalid(p)
+		warnDuplicates(opts.BaseDir, opts.Name, p, nil)
 	}
 
 	fmt.Printf("\nYour PRD is ready! Run 'chief' or 'chief %s' to start working on it.\n", opts.Name)
@@ -99,6 +105,16 @@ func runInteractiveAgent(provider loop.Provider, workDir, prompt string) error {
 	return cmd.Run()
 }
 
+// projectLanguage returns the language setting from the project's config, or ""
+// when it isn't set or the config can't be read.
+func projectLanguage(baseDir string) string {
+	cfg, err := config.Load(baseDir)
+	if err != nil {
+		return ""
+	}
+	return cfg.Language
+}
+
 // isValidPRDName checks if the name contains only valid characters.
 func isValidPRDName(name string) bool {
 	if name == "" {
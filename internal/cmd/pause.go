@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/minicodemonkey/chief/internal/control"
+	"github.com/minicodemonkey/chief/internal/loop"
+)
+
+// PauseOptions contains configuration for the pause command.
+type PauseOptions struct {
+	Name        string // PRD name to pause (default: the only running PRD)
+	BaseDir     string // Project root of the running chief (default: current directory)
+	All         bool   // Pause every running PRD
+	Maintenance bool   // Also enter maintenance mode (implies All)
+	Reason      string // Why maintenance mode was entered
+}
+
+// RunPause asks the chief instance running in another terminal to pause a
+// PRD loop after its current iteration. With All it pauses every running PRD;
+// with Maintenance it also refuses new runs until chief resume --all, which
+// holds across restarts and is recorded even when no chief is running.
+func RunPause(opts PauseOptions) error {
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+
+	client, err := control.Dial(opts.BaseDir)
+	if err != nil {
+		if !opts.Maintenance {
+			return err
+		}
+		if err := loop.EnterMaintenance(opts.BaseDir, opts.Reason); err != nil {
+			return fmt.Errorf("failed to enter maintenance mode: %w", err)
+		}
+		fmt.Println("Maintenance mode on. No chief is running; new runs are refused until chief resume --all")
+		return nil
+	}
+	defer client.Close()
+
+	if opts.Maintenance || opts.All {
+		var text string
+		if opts.Maintenance {
+			text, err = client.Maintenance(true, opts.Reason)
+		} else {
+			text, err = client.Command(control.CmdPauseAll, "")
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println(text)
+		return nil
+	}
+
+	if opts.Name == "" {
+		statuses, err := client.Status()
+		if err != nil {
+			return fmt.Errorf("failed to query running chief: %w", err)
+		}
+		name, err := pickRunningPRD(statuses)
+		if err != nil {
+			return err
+		}
+		opts.Name = name
+	}
+
+	text, err := client.Command(control.CmdPause, opts.Name)
+	if err != nil {
+		return fmt.Errorf("failed to pause %s: %w", opts.Name, err)
+	}
+	fmt.Println(text)
+	return nil
+}
+
+// pickRunningPRD returns the single running PRD, or an error asking for a
+// name when none or several are running.
+func pickRunningPRD(statuses []control.PRDStatus) (string, error) {
+	var running []string
+	for _, s := range statuses {
+		if s.State == loop.LoopStateRunning.String() {
+			running = append(running, s.Name)
+		}
+	}
+	switch len(running) {
+	case 0:
+		return "", fmt.Errorf("no PRD is running")
+	case 1:
+		return running[0], nil
+	default:
+		return "", fmt.Errorf("several PRDs are running (%v); name the one to pause", running)
+	}
+}
+
+// ResumeOptions contains configuration for the resume command.
+type ResumeOptions struct {
+	Name    string // PRD name to resume
+	BaseDir string // Project root of the running chief (default: current directory)
+	All     bool   // Lift maintenance mode and resume every paused PRD
+}
+
+// RunResume asks the chief instance running in another terminal to resume a
+// paused PRD loop. With All it lifts maintenance mode, if on, and resumes
+// every paused PRD; with no chief running it only lifts maintenance mode.
+func RunResume(opts ResumeOptions) error {
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+	if !opts.All && opts.Name == "" {
+		return fmt.Errorf("name the PRD to resume, or use --all")
+	}
+
+	client, err := control.Dial(opts.BaseDir)
+	if err != nil {
+		if !opts.All || loop.ReadMaintenance(opts.BaseDir) == nil {
+			return err
+		}
+		if err := loop.LeaveMaintenance(opts.BaseDir); err != nil {
+			return fmt.Errorf("failed to lift maintenance mode: %w", err)
+		}
+		fmt.Println("Maintenance mode lifted")
+		return nil
+	}
+	defer client.Close()
+
+	if !opts.All {
+		text, err := client.Command(control.CmdStart, opts.Name)
+		if err != nil {
+			return fmt.Errorf("failed to resume %s: %w", opts.Name, err)
+		}
+		fmt.Println(text)
+		return nil
+	}
+
+	if loop.ReadMaintenance(opts.BaseDir) != nil {
+		text, err := client.Maintenance(false, "")
+		if err != nil {
+			return err
+		}
+		fmt.Println(text)
+	}
+	text, err := client.Command(control.CmdResumeAll, "")
+	if err != nil {
+		return err
+	}
+	fmt.Println(text)
+	return nil
+}
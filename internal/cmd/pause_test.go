@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/control"
+	"github.com/minicodemonkey/chief/internal/loop"
+)
+
+func TestPickRunningPRD(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []control.PRDStatus
+		want     string
+		wantErr  bool
+	}{
+		{"none", nil, "", true},
+		{"idle only", []control.PRDStatus{{Name: "auth", State: "Paused"}}, "", true},
+		{"one running", []control.PRDStatus{{Name: "auth", State: "Paused"}, {Name: "billing", State: "Running"}}, "billing", false},
+		{"several running", []control.PRDStatus{{Name: "auth", State: "Running"}, {Name: "billing", State: "Running"}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pickRunningPRD(tt.statuses)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("pickRunningPRD() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("pickRunningPRD() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunPause_NoRunningChief(t *testing.T) {
+	dir, err := os.MkdirTemp("", "cp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := RunPause(PauseOptions{Name: "auth", BaseDir: dir}); err == nil {
+		t.Error("expected an error when no chief is running")
+	}
+}
+
+func TestRunPause_MaintenanceWithoutChief(t *testing.T) {
+	dir, err := os.MkdirTemp("", "cp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := RunPause(PauseOptions{BaseDir: dir, All: true}); err == nil {
+		t.Error("expected pause --all to need a running chief")
+	}
+	if err := RunPause(PauseOptions{BaseDir: dir, Maintenance: true, Reason: "host reboot"}); err != nil {
+		t.Fatalf("RunPause(--maintenance) error = %v", err)
+	}
+	if m := loop.ReadMaintenance(dir); m == nil || m.Reason != "host reboot" {
+		t.Fatalf("expected maintenance mode to be recorded, got %+v", m)
+	}
+	if err := RunResume(ResumeOptions{BaseDir: dir, All: true}); err != nil {
+		t.Fatalf("RunResume(--all) error = %v", err)
+	}
+	if m := loop.ReadMaintenance(dir); m != nil {
+		t.Errorf("expected maintenance mode to be lifted, got %+v", m)
+	}
+}
@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/control"
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// PlainOptions contains configuration for running a PRD without the TUI.
+type PlainOptions struct {
+	PRDPath       string        // Path to prd.md
+	MaxIterations int           // Iterations before pausing (default: remaining stories + 5)
+	Verbose       bool          // Also print a line per tool call
+	NoRetry       bool          // Disable auto-retry on agent crashes
+	IgnoreQuiet   bool          // Keep working through quiet hours
+	Base          string        // Pin the run's branch to this ref
+	Labels        []string      // Labels recorded with the run
+	Parallel      int           // Stories worked on side by side in worktrees of their own (<= 1 = one at a time)
+	Provider      loop.Provider // Agent CLI provider
+}
+
+// RunPlain runs a PRD's loop and reports its progress as plain sequential
+// lines of text instead of the full-screen TUI, for screen readers and
+// simple terminals. It serves the control socket like the TUI, so `chief
+// pause` and `chief attach` work from another terminal. Ctrl+C stops the
+// loop.
+func RunPlain(opts PlainOptions) error {
+	if opts.Provider == nil {
+		return fmt.Errorf("plain output requires Provider to be set")
+	}
+	p, err := prd.LoadPRD(opts.PRDPath)
+	if err != nil {
+		return err
+	}
+	done, total := storyCounts(p)
+	if done == total {
+		fmt.Printf("All %d stories are already done.\n", total)
+		return nil
+	}
+	if opts.MaxIterations <= 0 {
+		opts.MaxIterations = max(total-done+5, 5)
+	}
+
+	manager, name, closeServer, err := newRunManager(opts)
+	if err != nil {
+		return err
+	}
+	defer closeServer()
+
+	fmt.Printf("Running %s with %s: %d of %d stories done, up to %d iterations.\n", name, opts.Provider.Name(), done, total, opts.MaxIterations)
+	fmt.Println("Press Ctrl+C to stop.")
+	if err := manager.Start(name); err != nil {
+		return err
+	}
+
+	watchRun(manager, name, func(event loop.Event) {
+		if event.Type == loop.EventIterationStart && event.StoryID != "" {
+			fmt.Println(iterationLine(event, opts.PRDPath, opts.MaxIterations))
+			return
+		}
+		if line := plainEvent(event, opts.Verbose); line != "" {
+			fmt.Println(line)
+		}
+	})
+
+	instance := manager.GetInstance(name)
+	if p, err := prd.LoadPRD(opts.PRDPath); err == nil {
+		done, total = storyCounts(p)
+	}
+	switch instance.State {
+	case loop.LoopStateComplete:
+		fmt.Printf("Finished: all %d stories are done.\n", total)
+	case loop.LoopStateError:
+		return fmt.Errorf("%w (%d of %d stories done)", instance.Error, done, total)
+	case loop.LoopStateStopped:
+		fmt.Printf("Stopped with %d of %d stories done. Run `chief %s --a11y` to resume.\n", done, total, name)
+	default:
+		fmt.Printf("Paused with %d of %d stories done. Run `chief %s --a11y` to resume.\n", done, total, name)
+	}
+	return nil
+}
+
+// newRunManager sets up a manager for the PRD at opts.PRDPath, registered
+// under the PRD's name, and serves the control socket for it unless another
+// chief already does. The returned func closes the socket.
+func newRunManager(opts PlainOptions) (*loop.Manager, string, func(), error) {
+	var err error
+
+	// Same project root as the TUI: up from .chief/prds/<name>/prd.md
+	baseDir := filepath.Dir(filepath.Dir(filepath.Dir(filepath.Dir(opts.PRDPath))))
+	if !strings.Contains(opts.PRDPath, ".chief/prds/") {
+		if baseDir, err = os.Getwd(); err != nil {
+			return nil, "", nil, fmt.Errorf("failed to get current directory: %w", err)
+		}
+	}
+	name := filepath.Base(filepath.Dir(opts.PRDPath))
+
+	cfg, err := config.Load(baseDir)
+	if err != nil {
+		cfg = config.Default()
+	}
+	manager := loop.NewManager(opts.MaxIterations, opts.Provider)
+	manager.SetBaseDir(baseDir)
+	manager.SetConfig(cfg)
+	if err := manager.Register(name, opts.PRDPath); err != nil {
+		return nil, "", nil, err
+	}
+	if opts.NoRetry {
+		manager.DisableRetry()
+	}
+	if opts.IgnoreQuiet {
+		manager.IgnoreQuietHours()
+	}
+	if opts.Base != "" {
+		base, branch, err := PinBase(baseDir, name, opts.Base)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		manager.UpdateWorktreeInfo(name, "", branch)
+		manager.SetBase(name, base)
+	}
+	manager.SetLabels(name, opts.Labels)
+	if opts.Parallel > 1 {
+		manager.SetParallel(name, opts.Parallel)
+	}
+
+	// If another chief already serves this project, run without a socket
+	if server, _ := control.Listen(baseDir, manager); server != nil {
+		return manager, name, func() { server.Close() }, nil
+	}
+	return manager, name, func() {}, nil
+}
+
+// watchRun passes each event of the manager's loops to handle until they
+// have all finished. Ctrl+C, or SIGTERM from a CI runner cancelling the
+// job, stops the loop named name.
+func watchRun(manager *loop.Manager, name string, handle func(loop.Event)) {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	finished := make(chan struct{})
+	go func() {
+		manager.Wait()
+		close(finished)
+	}()
+	for running := true; running; {
+		select {
+		case event := <-manager.Events():
+			handle(event.Event)
+		case <-interrupt:
+			manager.Stop(name)
+		case <-finished:
+			running = false
+		}
+	}
+	for len(manager.Events()) > 0 {
+		handle((<-manager.Events()).Event)
+	}
+}
+
+// plainEvent returns a line of text describing a loop event, or "" for
+// events not worth a line. Tool calls get a line only when tools is set.
+func plainEvent(event loop.Event, tools bool) string {
+	switch event.Type {
+	case loop.EventIterationStart:
+		if event.StoryID == "" {
+			return "" // The agent starting up, not a new iteration
+		}
+		return fmt.Sprintf("Iteration %d: %s", event.Iteration, event.StoryID)
+	case loop.EventToolStart:
+		if !tools {
+			return ""
+		}
+		return "Tool: " + event.Tool
+	case loop.EventAssistantText, loop.EventToolResult, loop.EventUnknown:
+		return ""
+	case loop.EventStoryDone:
+		return "The agent reports the story done."
+	case loop.EventComplete:
+		return "All stories are complete."
+	case loop.EventMaxIterationsReached:
+		return "Reached the maximum number of iterations."
+	case loop.EventError:
+		text := event.Text
+		if text == "" && event.Err != nil {
+			text = event.Err.Error()
+		}
+		return "Error: " + text
+	default:
+		return event.Text
+	}
+}
+
+// iterationLine announces an iteration with the story it works on and the
+// PRD's progress so far.
+func iterationLine(event loop.Event, prdPath string, maxIter int) string {
+	line := fmt.Sprintf("Iteration %d of %d", event.Iteration, maxIter)
+	p, err := prd.LoadPRD(prdPath)
+	if err != nil {
+		return line + "."
+	}
+	for _, s := range p.UserStories {
+		if s.ID == event.StoryID {
+			line += fmt.Sprintf(", story %s: %s", s.ID, s.Title)
+		}
+	}
+	done, total := storyCounts(p)
+	return fmt.Sprintf("%s. %d of %d stories done.", line, done, total)
+}
+
+// storyCounts returns the number of done stories in p and the total.
+func storyCounts(p *prd.PRD) (int, int) {
+	done := 0
+	for _, s := range p.UserStories {
+		if s.Passes {
+			done++
+		}
+	}
+	return done, len(p.UserStories)
+}
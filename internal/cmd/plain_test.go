@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/loop"
+)
+
+func TestPlainEvent(t *testing.T) {
+	tests := []struct {
+		name  string
+		event loop.Event
+		tools bool
+		want  string
+	}{
+		{"iteration", loop.Event{Type: loop.EventIterationStart, Iteration: 2, StoryID: "US-002"}, false, "Iteration 2: US-002"},
+		{"agent init", loop.Event{Type: loop.EventIterationStart, Iteration: 2}, false, ""},
+		{"tool hidden", loop.Event{Type: loop.EventToolStart, Tool: "Read"}, false, ""},
+		{"tool shown", loop.Event{Type: loop.EventToolStart, Tool: "Read"}, true, "Tool: Read"},
+		{"assistant text", loop.Event{Type: loop.EventAssistantText, Text: "thinking"}, true, ""},
+		{"story done", loop.Event{Type: loop.EventStoryDone, Text: "<chief-done/>"}, false, "The agent reports the story done."},
+		{"error", loop.Event{Type: loop.EventError, Err: errors.New("boom")}, false, "Error: boom"},
+		{"other", loop.Event{Type: loop.EventVerify, Text: "Checks passed"}, false, "Checks passed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := plainEvent(tt.event, tt.tools); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIterationLine(t *testing.T) {
+	prdPath := filepath.Join(t.TempDir(), "prd.md")
+	content := `# Test
+
+### US-001: First story
+**Status:** done
+- [x] Done
+
+### US-002: Second story
+- [ ] Not yet
+`
+	if err := os.WriteFile(prdPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := iterationLine(loop.Event{Type: loop.EventIterationStart, Iteration: 3, StoryID: "US-002"}, prdPath, 7)
+	want := "Iteration 3 of 7, story US-002: Second story. 1 of 2 stories done."
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
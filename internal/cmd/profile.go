@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/timefmt"
+)
+
+// profileTopTools is how many of the slowest tool calls are listed.
+const profileTopTools = 10
+
+// ProfileOptions contains configuration for the profile command.
+type ProfileOptions struct {
+	Name    string           // PRD name (default: "main")
+	BaseDir string           // Base directory for .chief/prds/ (default: current directory)
+	All     bool             // Profile every recorded run instead of the latest
+	Labels  loop.LabelFilter // Only count runs with (or without) these labels
+	Output  io.Writer        // Where the report goes (default: stdout)
+}
+
+// RunProfile prints where the time in a PRD's latest run went: agent
+// thinking, tool calls, and verification, per story, and the slowest tool
+// calls.
+func RunProfile(opts ProfileOptions) error {
+	if opts.Name == "" {
+		opts.Name = "main"
+	}
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+	if opts.Output == nil {
+		opts.Output = os.Stdout
+	}
+	w := opts.Output
+
+	path := filepath.Join(opts.BaseDir, ".chief", "prds", opts.Name, loop.EventLogFile)
+	records, err := loop.ReadEventLog(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no runs recorded for %s yet; the event log is written while a run is active", opts.Name)
+	}
+	if err != nil {
+		return err
+	}
+	records = loop.FilterRuns(records, opts.Labels)
+	if len(records) == 0 {
+		return fmt.Errorf("no runs of %s match %s", opts.Name, describeLabelFilter(opts.Labels))
+	}
+	if !opts.All {
+		records = loop.LastRun(records)
+	}
+	p := loop.BuildProfile(records)
+	total := p.Total()
+	if total == 0 {
+		return fmt.Errorf("the recorded run of %s has no timed events", opts.Name)
+	}
+
+	if opts.All {
+		fmt.Fprintf(w, "Profile of %s: %d runs since %s, %s\n\n", opts.Name, p.Runs, timefmt.Stamp(p.Start), timefmt.Duration(total))
+	} else {
+		fmt.Fprintf(w, "Profile of %s: run started %s, %s\n\n", opts.Name, timefmt.Stamp(p.Start), timefmt.Duration(total))
+	}
+	if !opts.Labels.IsZero() {
+		fmt.Fprintf(w, "Runs matching %s\n\n", describeLabelFilter(opts.Labels))
+	}
+
+	fmt.Fprintf(w, "  %-10s %6s %9s", "Story", "Iter", "Total")
+	for _, phase := range loop.Phases {
+		fmt.Fprintf(w, " %13s", phase)
+	}
+	fmt.Fprintln(w)
+	for _, s := range p.Stories {
+		fmt.Fprintf(w, "  %-10s %6d %9s", s.StoryID, s.Iterations, timefmt.Duration(s.Total()))
+		for _, phase := range loop.Phases {
+			fmt.Fprintf(w, " %13s", timefmt.Duration(s.Phases[phase]))
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "  %-10s %6s %9s", "All", "", timefmt.Duration(total))
+	for _, phase := range loop.Phases {
+		share := float64(p.Phases[phase]) / float64(total) * 100
+		fmt.Fprintf(w, " %13s", fmt.Sprintf("%s %2.0f%%", timefmt.Duration(p.Phases[phase]), share))
+	}
+	fmt.Fprintln(w)
+	printCategories(w, p)
+
+	if len(p.ToolCalls) == 0 {
+		return nil
+	}
+	fmt.Fprintf(w, "\nSlowest tool calls:\n")
+	for i, call := range p.ToolCalls {
+		if i == profileTopTools {
+			break
+		}
+		fmt.Fprintf(w, "  %2d. %8s  %-8s %-8s %s\n", i+1, timefmt.Duration(call.Duration), call.StoryID, call.Tool, call.Detail)
+	}
+	return nil
+}
+
+// printCategories prints the time, and the tokens when the agent reported
+// them, spent per tool category for each story and overall.
+func printCategories(w io.Writer, p *loop.Profile) {
+	if len(p.ToolCalls) == 0 && p.Tokens == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nBy tool category")
+	if p.Tokens > 0 {
+		fmt.Fprintf(w, " (time / tokens)")
+	}
+	fmt.Fprintf(w, ":\n  %-10s", "Story")
+	for _, c := range loop.Categories {
+		fmt.Fprintf(w, " %15s", c)
+	}
+	fmt.Fprintln(w)
+	row := func(name string, usage map[loop.Category]*loop.CategoryUsage) {
+		fmt.Fprintf(w, "  %-10s", name)
+		for _, c := range loop.Categories {
+			fmt.Fprintf(w, " %15s", formatCategoryUsage(usage[c], p.Tokens > 0))
+		}
+		fmt.Fprintln(w)
+	}
+	for _, s := range p.Stories {
+		row(s.StoryID, s.Categories)
+	}
+	row("All", p.Categories)
+}
+
+// formatCategoryUsage formats the time, and optionally the tokens, spent on
+// a category, e.g. "1m5s / 42k".
+func formatCategoryUsage(u *loop.CategoryUsage, tokens bool) string {
+	if u == nil {
+		u = &loop.CategoryUsage{}
+	}
+	if !tokens {
+		return timefmt.Duration(u.Time)
+	}
+	return timefmt.Duration(u.Time) + " / " + formatTokens(u.Tokens)
+}
+
+// describeLabelFilter describes a label filter for messages, e.g.
+// "--label experiment --without-label model=opus".
+func describeLabelFilter(f loop.LabelFilter) string {
+	var parts []string
+	for _, label := range f.With {
+		parts = append(parts, "--label "+label)
+	}
+	for _, label := range f.Without {
+		parts = append(parts, "--without-label "+label)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/loop"
+)
+
+func TestRunProfile(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdDir := filepath.Join(tmpDir, ".chief", "prds", "auth")
+	if err := os.MkdirAll(prdDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RunProfile(ProfileOptions{Name: "auth", BaseDir: tmpDir}); err == nil || !strings.Contains(err.Error(), "no runs recorded") {
+		t.Errorf("expected an error before any run, got %v", err)
+	}
+
+	log := `{"time":"2026-03-01T12:00:00Z","type":"RunStart"}
+{"time":"2026-03-01T12:00:01Z","type":"IterationStart","iteration":1,"storyId":"US-001"}
+{"time":"2026-03-01T12:00:05Z","type":"ToolStart","iteration":1,"tool":"Bash","detail":"npm test","messageId":"msg_1","inputTokens":42000,"outputTokens":300}
+{"time":"2026-03-01T12:01:05Z","type":"ToolResult","iteration":1}
+{"time":"2026-03-01T12:01:20Z","type":"Verify","iteration":1,"storyId":"US-001","detail":"Verification passed for US-001"}
+`
+	if err := os.WriteFile(filepath.Join(prdDir, "events.jsonl"), []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := RunProfile(ProfileOptions{Name: "auth", BaseDir: tmpDir, Output: &out}); err != nil {
+		t.Fatalf("RunProfile() error = %v", err)
+	}
+	for _, want := range []string{"Profile of auth", "US-001", "1m20s", "1m00s 75%", "Slowest tool calls", "npm test", "By tool category (time / tokens)", "1m00s / 42k"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunProfile_Labels(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdDir := filepath.Join(tmpDir, ".chief", "prds", "auth")
+	if err := os.MkdirAll(prdDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	log := `{"time":"2026-03-01T12:00:00Z","type":"RunStart","labels":["experiment","model=opus"]}
+{"time":"2026-03-01T12:00:01Z","type":"IterationStart","iteration":1,"storyId":"US-001"}
+{"time":"2026-03-01T12:00:30Z","type":"StoryDone","iteration":1,"storyId":"US-001"}
+{"time":"2026-03-01T13:00:00Z","type":"RunStart"}
+{"time":"2026-03-01T13:00:01Z","type":"IterationStart","iteration":1,"storyId":"US-002"}
+{"time":"2026-03-01T13:00:09Z","type":"StoryDone","iteration":1,"storyId":"US-002"}
+`
+	if err := os.WriteFile(filepath.Join(prdDir, "events.jsonl"), []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	filter := loop.LabelFilter{With: []string{"model"}}
+	if err := RunProfile(ProfileOptions{Name: "auth", BaseDir: tmpDir, Labels: filter, Output: &out}); err != nil {
+		t.Fatalf("RunProfile() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "US-001") || strings.Contains(out.String(), "US-002") {
+		t.Errorf("expected only the labeled run:\n%s", out.String())
+	}
+
+	out.Reset()
+	filter = loop.LabelFilter{Without: []string{"experiment"}}
+	if err := RunProfile(ProfileOptions{Name: "auth", BaseDir: tmpDir, All: true, Labels: filter, Output: &out}); err != nil {
+		t.Fatalf("RunProfile() error = %v", err)
+	}
+	if strings.Contains(out.String(), "US-001") || !strings.Contains(out.String(), "US-002") {
+		t.Errorf("expected the experiment run to be left out:\n%s", out.String())
+	}
+
+	filter = loop.LabelFilter{With: []string{"nightly"}}
+	if err := RunProfile(ProfileOptions{Name: "auth", BaseDir: tmpDir, Labels: filter}); err == nil || !strings.Contains(err.Error(), "--label nightly") {
+		t.Errorf("expected no matching runs, got %v", err)
+	}
+}
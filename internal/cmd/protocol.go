@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/minicodemonkey/chief/internal/control"
+)
+
+// RunProtocolDump writes the JSON Schema of the control protocol to w
+// (default: stdout), for clients that talk to a running Chief.
+func RunProtocolDump(w io.Writer) error {
+	if w == nil {
+		w = os.Stdout
+	}
+	schema, err := control.Schema()
+	if err != nil {
+		return fmt.Errorf("failed to build the protocol schema: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "%s\n", schema)
+	return err
+}
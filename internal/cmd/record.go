@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/minicodemonkey/chief/internal/git"
+	"github.com/minicodemonkey/chief/internal/verify"
+)
+
+// RecordOptions contains configuration for the record command.
+type RecordOptions struct {
+	StoryID string // Story to record a browser test for
+	Name    string // PRD name (default: the PRD containing the story)
+	BaseDir string // Base directory for .chief/prds/ (default: current directory)
+}
+
+// RunRecord opens Playwright's recorder on a story's URL and saves what you
+// click through as the story's browser test, which verification then runs.
+func RunRecord(opts RecordOptions) error {
+	if opts.StoryID == "" {
+		return fmt.Errorf("missing story ID")
+	}
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+
+	name, story, err := findStory(opts.BaseDir, opts.Name, opts.StoryID)
+	if err != nil {
+		return err
+	}
+	if story.URL == "" {
+		return fmt.Errorf("%s has no URL; add a **URL:** line to the story in .chief/prds/%s/prd.md", story.ID, name)
+	}
+
+	// Record into the PRD's worktree when it runs in one, so the agent sees the test
+	workDir := opts.BaseDir
+	if wt := git.WorktreePathForPRD(opts.BaseDir, name); git.IsWorktree(wt) {
+		workDir = wt
+	}
+	rel := verify.BrowserTestPath(workDir, story.ID)
+	path := filepath.Join(workDir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(rel), err)
+	}
+
+	fmt.Printf("Recording %s at %s. Close the browser when you're done.\n", story.ID, story.URL)
+	c := exec.Command("npx", "playwright", "codegen", "--target=playwright-test", "--output", path, story.URL)
+	c.Dir = workDir
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("playwright codegen failed: %w", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("nothing was recorded")
+	}
+
+	fmt.Printf("Saved %s. Chief runs it before marking %s done; commit it with your code.\n", rel, story.ID)
+	return nil
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/retention"
+	"github.com/minicodemonkey/chief/internal/timefmt"
+)
+
+// RetentionOptions contains configuration for the retention commands.
+type RetentionOptions struct {
+	BaseDir string // Project root (default: current directory)
+}
+
+// RunRetentionStatus prints how much of each kind of run data the project
+// keeps, how long it is kept, and how much of it the next cleanup removes.
+func RunRetentionStatus(opts RetentionOptions) error {
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+	cfg, err := config.Load(opts.BaseDir)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	printRetention(os.Stdout, retention.Check(opts.BaseDir, cfg.Retention, time.Now()))
+	return nil
+}
+
+// printRetention prints a line per kind of run data.
+func printRetention(w io.Writer, statuses []retention.Status) {
+	fmt.Fprintf(w, "%-12s %-9s %6s %7s  %-17s %s\n", "DATA", "KEPT", "ITEMS", "SIZE", "OLDEST", "EXPIRED")
+	for _, s := range statuses {
+		kept := "forever"
+		if s.Days > 0 {
+			kept = fmt.Sprintf("%d days", s.Days)
+		}
+		oldest := "-"
+		if !s.Oldest.IsZero() {
+			oldest = timefmt.DateTime(s.Oldest)
+		}
+		fmt.Fprintf(w, "%-12s %-9s %6d %7s  %-17s %d\n", s.Kind, kept, s.Items, formatSize(s.Bytes), oldest, s.Expired)
+	}
+}
+
+// formatSize shows a byte count in the largest unit that keeps it above 1.
+func formatSize(bytes int64) string {
+	switch {
+	case bytes >= 1<<30:
+		return fmt.Sprintf("%.1fG", float64(bytes)/(1<<30))
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.1fM", float64(bytes)/(1<<20))
+	case bytes >= 1<<10:
+		return fmt.Sprintf("%dK", bytes>>10)
+	default:
+		return fmt.Sprintf("%dB", bytes)
+	}
+}
+
+// CleanRunDataOnStartup removes the project's run data that is older than
+// its retention settings. It is best effort: a failure is only warned about.
+func CleanRunDataOnStartup(baseDir string) {
+	cfg, err := config.Load(baseDir)
+	if err != nil {
+		return
+	}
+	if _, err := retention.Clean(baseDir, cfg.Retention, time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to remove expired run data: %v\n", err)
+	}
+}
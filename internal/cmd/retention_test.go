@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/retention"
+)
+
+func TestPrintRetention(t *testing.T) {
+	var b strings.Builder
+	printRetention(&b, []retention.Status{
+		{Kind: retention.KindLogs, Days: 30, Items: 3, Bytes: 5 << 20, Oldest: time.Now().AddDate(0, 0, -40), Expired: 1},
+		{Kind: retention.KindHistory},
+	})
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and 2 lines, got %q", b.String())
+	}
+	if f := strings.Fields(lines[1]); f[0] != "logs" || f[1] != "30" || f[4] != "5.0M" || f[len(f)-1] != "1" {
+		t.Errorf("unexpected logs line %q", lines[1])
+	}
+	if f := strings.Fields(lines[2]); f[1] != "forever" || f[3] != "0B" || f[4] != "-" {
+		t.Errorf("unexpected history line %q", lines[2])
+	}
+}
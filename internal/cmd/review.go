@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/minicodemonkey/chief/internal/git"
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/review"
+)
+
+// ReviewOptions contains configuration for the review command.
+type ReviewOptions struct {
+	Name     string        // PRD name (default: "main")
+	BaseDir  string        // Base directory for .chief/prds/ (default: current directory)
+	Provider loop.Provider // Agent CLI provider
+}
+
+// RunReviewSecurity runs a read-only security review over the changes a PRD
+// run produced and prints its findings. It returns an error when the review
+// reports high or critical findings, so scripts can stop before opening a PR.
+func RunReviewSecurity(opts ReviewOptions) error {
+	if opts.Name == "" {
+		opts.Name = "main"
+	}
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+	if opts.Provider == nil {
+		return fmt.Errorf("review command requires Provider to be set")
+	}
+
+	prdPath := filepath.Join(opts.BaseDir, ".chief", "prds", opts.Name, "prd.md")
+	if _, err := os.Stat(prdPath); err != nil {
+		return fmt.Errorf("PRD %q not found", opts.Name)
+	}
+
+	// Review the PRD's worktree when it ran in one
+	workDir := opts.BaseDir
+	if wt := git.WorktreePathForPRD(opts.BaseDir, opts.Name); git.IsWorktree(wt) {
+		workDir = wt
+	}
+
+	fmt.Printf("Running security review of %s with %s...\n", opts.Name, opts.Provider.Name())
+	report, err := review.RunSecurity(context.Background(), review.SecurityOptions{
+		Provider: opts.Provider,
+		PRDName:  opts.Name,
+		PRDPath:  prdPath,
+		WorkDir:  workDir,
+		Output:   os.Stdout,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	if len(report.Findings) == 0 {
+		fmt.Println("No findings")
+	}
+	for _, f := range report.Findings {
+		fmt.Printf("  [%s] %s\n", f.Severity, f.Title)
+	}
+	fmt.Printf("\nReport: %s\n", report.Path)
+
+	if blocking := report.Blocking(); len(blocking) > 0 {
+		return fmt.Errorf("%d high-severity finding(s)", len(blocking))
+	}
+	return nil
+}
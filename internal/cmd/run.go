@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// RunOptions contains configuration for the run command.
+type RunOptions struct {
+	PlainOptions
+	JSON bool // Print progress as JSON lines instead of text
+}
+
+// RunLine is a line of `chief run --json` output.
+type RunLine struct {
+	Time      time.Time `json:"time"`
+	Type      string    `json:"type"` // Event type, or "started" and "finished" around the run
+	PRD       string    `json:"prd"`
+	StoryID   string    `json:"storyId,omitempty"`
+	Iteration int       `json:"iteration,omitempty"`
+	Text      string    `json:"text,omitempty"`
+	Tool      string    `json:"tool,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	State     string    `json:"state,omitempty"` // How the run ended (finished only)
+	Done      int       `json:"done"`
+	Total     int       `json:"total"`
+}
+
+// RunHeadless runs a PRD's loop without a terminal, for CI pipelines and
+// cron jobs. Progress goes to stdout as text lines, or JSON lines with
+// opts.JSON. It returns an error when the run ends with stories not done,
+// so the process exits non-zero.
+func RunHeadless(opts RunOptions) error {
+	if opts.Provider == nil {
+		return fmt.Errorf("run requires Provider to be set")
+	}
+	p, err := prd.LoadPRD(opts.PRDPath)
+	if err != nil {
+		return err
+	}
+	done, total := storyCounts(p)
+	if opts.MaxIterations <= 0 {
+		opts.MaxIterations = max(total-done+5, 5)
+	}
+	out := &runOutput{w: os.Stdout, json: opts.JSON, name: filepath.Base(filepath.Dir(opts.PRDPath)), prdPath: opts.PRDPath, done: done, total: total}
+
+	if done == total {
+		out.finish(loop.LoopStateComplete)
+		return nil
+	}
+
+	manager, name, closeServer, err := newRunManager(opts.PlainOptions)
+	if err != nil {
+		return err
+	}
+	defer closeServer()
+
+	out.start(opts.Provider.Name(), opts.MaxIterations)
+	if err := manager.Start(name); err != nil {
+		return err
+	}
+	watchRun(manager, name, func(event loop.Event) {
+		out.event(event, opts.Verbose, opts.MaxIterations)
+	})
+
+	instance := manager.GetInstance(name)
+	out.finish(instance.State)
+	switch {
+	case instance.State == loop.LoopStateError:
+		return fmt.Errorf("%w (%d of %d stories done)", instance.Error, out.done, out.total)
+	case out.done < out.total:
+		return fmt.Errorf("%d of %d stories not done", out.total-out.done, out.total)
+	}
+	return nil
+}
+
+// runOutput writes the progress of a headless run as text or JSON lines.
+type runOutput struct {
+	w       io.Writer
+	json    bool
+	name    string
+	prdPath string
+	done    int
+	total   int
+}
+
+// write prints rec, or line when printing text. Records always carry the
+// PRD's latest story counts.
+func (o *runOutput) write(rec RunLine, line string) {
+	if !o.json {
+		if line != "" {
+			fmt.Fprintln(o.w, line)
+		}
+		return
+	}
+	rec.Time = time.Now().UTC()
+	rec.PRD = o.name
+	rec.Done, rec.Total = o.done, o.total
+	data, _ := json.Marshal(rec)
+	fmt.Fprintln(o.w, string(data))
+}
+
+// refresh reloads the story counts from the PRD.
+func (o *runOutput) refresh() {
+	if p, err := prd.LoadPRD(o.prdPath); err == nil {
+		o.done, o.total = storyCounts(p)
+	}
+}
+
+// start reports the run starting.
+func (o *runOutput) start(agent string, maxIter int) {
+	o.write(RunLine{Type: "started", Text: agent},
+		fmt.Sprintf("Running %s with %s: %d of %d stories done, up to %d iterations.", o.name, agent, o.done, o.total, maxIter))
+}
+
+// event reports a loop event. Tool calls are reported only when tools is set.
+func (o *runOutput) event(event loop.Event, tools bool, maxIter int) {
+	if event.Type == loop.EventStoryPassed || event.Type == loop.EventIterationStart {
+		o.refresh()
+	}
+	line := plainEvent(event, tools)
+	if event.Type == loop.EventIterationStart && event.StoryID != "" {
+		line = iterationLine(event, o.prdPath, maxIter)
+	}
+	if line == "" {
+		return
+	}
+	rec := RunLine{
+		Type:      event.Type.String(),
+		StoryID:   event.StoryID,
+		Iteration: event.Iteration,
+		Text:      event.Text,
+		Tool:      event.Tool,
+	}
+	if event.Err != nil {
+		rec.Error = event.Err.Error()
+	}
+	o.write(rec, line)
+}
+
+// finish reports how the run ended.
+func (o *runOutput) finish(state loop.LoopState) {
+	o.refresh()
+	var line string
+	switch {
+	case o.done == o.total:
+		state = loop.LoopStateComplete
+		line = fmt.Sprintf("Finished: all %d stories are done.", o.total)
+	case state == loop.LoopStateError:
+		line = "" // Reported as the command's error
+	default:
+		line = fmt.Sprintf("%s with %d of %d stories done.", state, o.done, o.total)
+	}
+	o.write(RunLine{Type: "finished", State: strings.ToLower(state.String())}, line)
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/loop"
+)
+
+func writeRunPRD(t *testing.T) string {
+	t.Helper()
+	prdPath := filepath.Join(t.TempDir(), "auth", "prd.md")
+	content := `# Test
+
+### US-001: First story
+**Status:** done
+- [x] Done
+
+### US-002: Second story
+- [ ] Not yet
+`
+	if err := os.MkdirAll(filepath.Dir(prdPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(prdPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return prdPath
+}
+
+func TestRunOutput_Text(t *testing.T) {
+	var buf bytes.Buffer
+	out := &runOutput{w: &buf, name: "auth", prdPath: writeRunPRD(t), done: 1, total: 2}
+
+	out.start("Claude", 6)
+	out.event(loop.Event{Type: loop.EventIterationStart, Iteration: 1, StoryID: "US-002"}, false, 6)
+	out.event(loop.Event{Type: loop.EventToolStart, Tool: "Read"}, false, 6)
+	out.finish(loop.LoopStatePaused)
+
+	want := "Running auth with Claude: 1 of 2 stories done, up to 6 iterations.\n" +
+		"Iteration 1 of 6, story US-002: Second story. 1 of 2 stories done.\n" +
+		"Paused with 1 of 2 stories done.\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRunOutput_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	out := &runOutput{w: &buf, json: true, name: "auth", prdPath: writeRunPRD(t), done: 1, total: 2}
+
+	out.start("Claude", 6)
+	out.event(loop.Event{Type: loop.EventIterationStart, Iteration: 1, StoryID: "US-002"}, false, 6)
+	out.event(loop.Event{Type: loop.EventAssistantText, Text: "thinking"}, false, 6)
+	out.event(loop.Event{Type: loop.EventError, StoryID: "US-002", Err: errors.New("boom")}, false, 6)
+	out.finish(loop.LoopStateStopped)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %d:\n%s", len(lines), buf.String())
+	}
+	var recs []RunLine
+	for _, line := range lines {
+		var rec RunLine
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		if rec.PRD != "auth" || rec.Done != 1 || rec.Total != 2 || rec.Time.IsZero() {
+			t.Errorf("line missing run fields: %s", line)
+		}
+		recs = append(recs, rec)
+	}
+	if recs[0].Type != "started" || recs[0].Text != "Claude" {
+		t.Errorf("first line = %+v, want started with agent", recs[0])
+	}
+	if recs[1].Type != "IterationStart" || recs[1].StoryID != "US-002" || recs[1].Iteration != 1 {
+		t.Errorf("second line = %+v, want iteration start of US-002", recs[1])
+	}
+	if recs[2].Type != "Error" || recs[2].Error != "boom" {
+		t.Errorf("third line = %+v, want error boom", recs[2])
+	}
+	if recs[3].Type != "finished" || recs[3].State != "stopped" {
+		t.Errorf("last line = %+v, want finished stopped", recs[3])
+	}
+}
+
+func TestRunHeadless_AllDone(t *testing.T) {
+	prdPath := filepath.Join(t.TempDir(), "prd.md")
+	content := "# Test\n\n### US-001: Only story\n**Status:** done\n- [x] Done\n"
+	if err := os.WriteFile(prdPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunHeadless(RunOptions{PlainOptions: PlainOptions{PRDPath: prdPath, Provider: &selftestProvider{command: []string{"true"}}}}); err != nil {
+		t.Errorf("expected success when every story is done, got %v", err)
+	}
+}
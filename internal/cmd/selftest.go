@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/git"
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/prd"
+	"github.com/minicodemonkey/chief/internal/tui"
+	"github.com/minicodemonkey/chief/internal/verify"
+)
+
+// SelftestOptions contains configuration for the selftest command.
+type SelftestOptions struct {
+	Keep    bool          // Keep the scratch project instead of deleting it
+	Timeout time.Duration // Time the run may take (default: 1 minute)
+}
+
+// selftestPRD is the synthetic PRD the self-test runs. Each story is finished
+// by the fake agent in one iteration.
+const selftestPRD = `# Self-test
+
+A synthetic PRD run by ` + "`chief selftest`" + `.
+
+### US-001: Write the first file
+- [ ] first.txt exists
+
+### US-002: Write the second file
+- [ ] second.txt exists
+`
+
+// selftestFiles are the files the fake agent writes for each story.
+var selftestFiles = map[string]string{"US-001": "first.txt", "US-002": "second.txt"}
+
+// selftestAgentCommand returns the command that runs the fake agent
+// (replaceable in tests).
+var selftestAgentCommand = func() ([]string, error) {
+	chief, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	return []string{chief, "selftest", "agent"}, nil
+}
+
+// RunSelftest checks that Chief works end to end on this machine: it creates
+// a scratch git repository with a synthetic PRD, runs the loop on it with a
+// fake agent that needs no model or network, and checks that the PRD was
+// parsed, every story was finished and committed, and the run's events
+// render in the TUI's log. Returns an error when any check fails.
+func RunSelftest(opts SelftestOptions) error {
+	if opts.Timeout <= 0 {
+		opts.Timeout = time.Minute
+	}
+	dir, err := os.MkdirTemp("", "chief-selftest-")
+	if err != nil {
+		return fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+	if opts.Keep {
+		defer fmt.Printf("\nScratch project kept at %s\n", dir)
+	} else {
+		defer os.RemoveAll(dir)
+	}
+
+	r := &doctorReport{}
+	fmt.Printf("Self-test in %s\n", dir)
+
+	// Git: a repository with chief's files ignored, like a real project
+	if err := selftestRepo(dir); err != nil {
+		r.fail("Git: %v", err)
+		return fmt.Errorf("self-test failed: git is not usable")
+	}
+	r.ok("Git: created a scratch repository")
+
+	// PRD: the synthetic PRD parses into its stories
+	prdPath := filepath.Join(dir, ".chief", "prds", "selftest", "prd.md")
+	if err := os.MkdirAll(filepath.Dir(prdPath), 0755); err != nil {
+		return fmt.Errorf("failed to create PRD directory: %w", err)
+	}
+	if err := os.WriteFile(prdPath, []byte(selftestPRD), 0644); err != nil {
+		return fmt.Errorf("failed to write PRD: %w", err)
+	}
+	if p, err := prd.LoadPRD(prdPath); err != nil {
+		r.fail("PRD: %v", err)
+	} else if len(p.UserStories) != len(selftestFiles) {
+		r.fail("PRD: parsed %d stories, expected %d", len(p.UserStories), len(selftestFiles))
+	} else {
+		r.ok("PRD: parsed %d stories", len(p.UserStories))
+	}
+	if r.errors > 0 {
+		return fmt.Errorf("self-test failed: the PRD could not be parsed")
+	}
+
+	// Loop: run the PRD with the fake agent, rendering each event in the log
+	agentCmd, err := selftestAgentCommand()
+	if err != nil {
+		return fmt.Errorf("failed to find the fake agent: %w", err)
+	}
+	events, err := selftestRun(dir, prdPath, agentCmd, opts.Timeout)
+	if err != nil {
+		r.fail("Loop: %v", err)
+	}
+	if p, err := prd.LoadPRD(prdPath); err != nil {
+		r.fail("Loop: %v", err)
+	} else if done, total := storyCounts(p); done != total {
+		r.fail("Loop: %d of %d stories done", done, total)
+	} else {
+		r.ok("Loop: finished %d stories", total)
+	}
+
+	// Git: every story was committed
+	var missing []string
+	for id := range selftestFiles {
+		if commits, err := git.StoryCommits(dir, id); err != nil || len(commits) == 0 {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		r.fail("Commits: no commit for %s", strings.Join(missing, ", "))
+	} else {
+		r.ok("Commits: one commit per story")
+	}
+
+	// TUI: the run's events render in the log viewer
+	log := tui.NewLogViewer()
+	log.SetSize(100, 40)
+	for _, event := range events {
+		log.AddEvent(event)
+	}
+	if rendered := log.Render(); len(events) == 0 || strings.Contains(rendered, "No log entries yet") {
+		r.fail("TUI: the log rendered no entries from %d events", len(events))
+	} else {
+		r.ok("TUI: rendered %d events", len(events))
+	}
+
+	if r.errors > 0 {
+		fmt.Println()
+		return fmt.Errorf("%d check(s) failed", r.errors)
+	}
+	fmt.Println()
+	fmt.Println("Chief works on this machine.")
+	return nil
+}
+
+// selftestRepo creates a git repository in dir with a first commit that
+// ignores .chief/.
+func selftestRepo(dir string) error {
+	if err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte(".chief/\n"), 0644); err != nil {
+		return err
+	}
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.email", "selftest@chief.invalid"},
+		{"config", "user.name", "Chief self-test"},
+		{"add", ".gitignore"},
+		{"commit", "-m", "Initial commit"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
+
+// selftestRun runs the PRD with the fake agent and returns the events of the
+// run.
+func selftestRun(dir, prdPath string, agentCmd []string, timeout time.Duration) ([]loop.Event, error) {
+	cfg := config.Default()
+	cfg.Verify.Command = verify.Disabled
+	manager := loop.NewManager(len(selftestFiles)+2, &selftestProvider{command: agentCmd, prdPath: prdPath})
+	manager.SetBaseDir(dir)
+	manager.SetConfig(cfg)
+	manager.DisableRetry()
+	if err := manager.Register("selftest", prdPath); err != nil {
+		return nil, err
+	}
+	if err := manager.Start("selftest"); err != nil {
+		return nil, err
+	}
+	timer := time.AfterFunc(timeout, func() { manager.Stop("selftest") })
+	defer timer.Stop()
+
+	var events []loop.Event
+	watchRun(manager, "selftest", func(event loop.Event) {
+		events = append(events, event)
+	})
+
+	instance := manager.GetInstance("selftest")
+	switch instance.State {
+	case loop.LoopStateComplete:
+		return events, nil
+	case loop.LoopStateError:
+		return events, instance.Error
+	case loop.LoopStateStopped:
+		return events, fmt.Errorf("the run didn't finish within %s", timeout)
+	default:
+		return events, fmt.Errorf("the run paused before finishing")
+	}
+}
+
+// selftestProvider runs the fake agent in place of an agent CLI. Its output
+// is in Claude's stream-json format.
+type selftestProvider struct {
+	command []string
+	prdPath string
+}
+
+func (p *selftestProvider) Name() string                      { return "Fake agent" }
+func (p *selftestProvider) CLIPath() string                   { return p.command[0] }
+func (p *selftestProvider) ParseLine(line string) *loop.Event { return loop.ParseLine(line) }
+func (p *selftestProvider) CleanOutput(output string) string  { return output }
+func (p *selftestProvider) LogFileName() string               { return "selftest.log" }
+
+func (p *selftestProvider) LoopCommand(ctx context.Context, _, workDir string) *exec.Cmd {
+	args := append(append([]string{}, p.command[1:]...), p.prdPath)
+	cmd := exec.CommandContext(ctx, p.command[0], args...)
+	cmd.Dir = workDir
+	return cmd
+}
+
+func (p *selftestProvider) ReadOnlyCommand(ctx context.Context, prompt, workDir string, _ ...string) (*exec.Cmd, error) {
+	return p.LoopCommand(ctx, prompt, workDir), nil
+}
+
+func (p *selftestProvider) InteractiveCommand(workDir, _ string) *exec.Cmd {
+	return p.LoopCommand(context.Background(), "", workDir)
+}
+
+// RunSelftestAgent is the fake agent of `chief selftest`: it finishes the next
+// story of the PRD at prdPath by writing its file and committing it in the
+// current directory, and reports what it did in Claude's stream-json format.
+func RunSelftestAgent(w io.Writer, prdPath string) error {
+	p, err := prd.LoadPRD(prdPath)
+	if err != nil {
+		return err
+	}
+	story := p.NextStory()
+	if story == nil {
+		return fmt.Errorf("no story left to work on")
+	}
+	file, ok := selftestFiles[story.ID]
+	if !ok {
+		return fmt.Errorf("unknown story %s", story.ID)
+	}
+
+	emit := func(v any) {
+		data, _ := json.Marshal(v)
+		fmt.Fprintln(w, string(data))
+	}
+	assistant := func(content map[string]any) {
+		emit(map[string]any{"type": "assistant", "message": map[string]any{"content": []any{content}}})
+	}
+	emit(map[string]any{"type": "system", "subtype": "init"})
+	assistant(map[string]any{"type": "tool_use", "id": "1", "name": "Write", "input": map[string]any{"file_path": file}})
+	if err := os.WriteFile(file, []byte(story.Title+"\n"), 0644); err != nil {
+		return err
+	}
+	assistant(map[string]any{"type": "tool_use", "id": "2", "name": "Bash", "input": map[string]any{"command": "git commit"}})
+	for _, args := range [][]string{{"add", file}, {"commit", "-m", fmt.Sprintf("feat: %s - %s", story.ID, story.Title)}} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(out)))
+		}
+	}
+	assistant(map[string]any{"type": "text", "text": fmt.Sprintf("Wrote %s for %s.\n<chief-done/>", file, story.ID)})
+	emit(map[string]any{"type": "result", "subtype": "success"})
+	return nil
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// TestSelftestAgentHelper is the fake agent when run by TestRunSelftest; it
+// does nothing as a test of its own.
+func TestSelftestAgentHelper(t *testing.T) {
+	if os.Getenv("CHIEF_SELFTEST_HELPER") != "1" {
+		return
+	}
+	if err := RunSelftestAgent(os.Stdout, os.Args[len(os.Args)-1]); err != nil {
+		os.Stderr.WriteString(err.Error() + "\n")
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestRunSelftest(t *testing.T) {
+	t.Setenv("CHIEF_SELFTEST_HELPER", "1")
+	orig := selftestAgentCommand
+	selftestAgentCommand = func() ([]string, error) {
+		return []string{os.Args[0], "-test.run=^TestSelftestAgentHelper$", "--"}, nil
+	}
+	defer func() { selftestAgentCommand = orig }()
+
+	if err := RunSelftest(SelftestOptions{Timeout: 30 * time.Second}); err != nil {
+		t.Fatalf("RunSelftest: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/minicodemonkey/chief/internal/config"
+)
+
+// SettingsOptions contains configuration for the settings command.
+type SettingsOptions struct {
+	Key     string // Setting to show or set ("" = show all)
+	Value   string // New value (set only)
+	Project string // Project directory (default: current directory)
+}
+
+// RunSettingsShow prints the project's settings from .chief/config.yaml, or a
+// single setting when Key is given.
+func RunSettingsShow(opts SettingsOptions) error {
+	cfg, err := loadSettings(&opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.Key != "" {
+		value, err := config.Get(cfg, opts.Key)
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	}
+
+	settings := config.Settings(cfg)
+	width := 0
+	for _, s := range settings {
+		width = max(width, len(s.Key))
+	}
+	for _, s := range settings {
+		fmt.Printf("%-*s  %s\n", width, s.Key, s.Value)
+	}
+	return nil
+}
+
+// RunSettingsSet changes one setting in the project's .chief/config.yaml.
+func RunSettingsSet(opts SettingsOptions) error {
+	if opts.Key == "" {
+		return fmt.Errorf("missing setting key")
+	}
+	cfg, err := loadSettings(&opts)
+	if err != nil {
+		return err
+	}
+	if err := config.Set(cfg, opts.Key, opts.Value); err != nil {
+		return err
+	}
+	if err := config.Save(opts.Project, cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	value, _ := config.Get(cfg, opts.Key)
+	fmt.Printf("Set %s = %s\n", opts.Key, value)
+	return nil
+}
+
+// loadSettings fills in the default project directory and loads its config.
+func loadSettings(opts *SettingsOptions) (*config.Config, error) {
+	if opts.Project == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.Project = cwd
+	}
+	if info, err := os.Stat(opts.Project); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("project directory %s not found", opts.Project)
+	}
+
+	cfg, err := config.Load(opts.Project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load .chief/config.yaml: %w", err)
+	}
+	return cfg, nil
+}
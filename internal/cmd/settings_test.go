@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/config"
+)
+
+func TestRunSettingsSet(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := RunSettingsSet(SettingsOptions{Key: "onComplete.push", Value: "true", Project: dir}); err != nil {
+		t.Fatalf("RunSettingsSet() error = %v", err)
+	}
+	if err := RunSettingsSet(SettingsOptions{Key: "loop.storyTimeoutMinutes", Value: "45", Project: dir}); err != nil {
+		t.Fatalf("RunSettingsSet() error = %v", err)
+	}
+
+	cfg, err := config.Load(dir)
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	if !cfg.OnComplete.Push || cfg.Loop.StoryTimeoutMinutes != 45 {
+		t.Errorf("settings not saved: push=%v storyTimeoutMinutes=%d", cfg.OnComplete.Push, cfg.Loop.StoryTimeoutMinutes)
+	}
+
+	if err := RunSettingsSet(SettingsOptions{Key: "loop.unknown", Value: "1", Project: dir}); err == nil {
+		t.Error("expected error for unknown setting")
+	}
+	if err := RunSettingsSet(SettingsOptions{Key: "onComplete.push", Value: "sometimes", Project: dir}); err == nil {
+		t.Error("expected error for invalid value")
+	}
+	if err := RunSettingsShow(SettingsOptions{Key: "onComplete.push", Project: dir}); err != nil {
+		t.Errorf("RunSettingsShow() error = %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/prd"
+	"github.com/minicodemonkey/chief/internal/similar"
+)
+
+// SimilarOptions contains configuration for the similar command.
+type SimilarOptions struct {
+	Query   string
+	Limit   int    // Maximum number of results (default: 5)
+	BaseDir string // Base directory for .chief/prds/ (default: current directory)
+}
+
+// RunSimilar lists the stories across all PRDs that most resemble a query.
+func RunSimilar(opts SimilarOptions) error {
+	if opts.Query == "" {
+		return fmt.Errorf("query is required")
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 5
+	}
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+
+	cfg, err := config.Load(opts.BaseDir)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.Similar.Provider == "off" {
+		cfg.Similar.Provider = "local"
+	}
+	embedder, err := similar.New(cfg.Similar, opts.BaseDir)
+	if err != nil {
+		return err
+	}
+
+	stories, err := similar.LoadStories(opts.BaseDir)
+	if err != nil {
+		return err
+	}
+	matches, err := similar.Search(context.Background(), embedder, opts.Query, stories, opts.Limit)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, m := range matches {
+		if m.Score <= 0 {
+			continue
+		}
+		if !found {
+			fmt.Printf("Stories similar to %q:\n", opts.Query)
+			found = true
+		}
+		fmt.Printf("  %.2f  %s/%s  %s (%s)\n", m.Score, m.PRD, m.ID, m.Title, similar.FormatStatus(m.Story))
+	}
+	if !found {
+		fmt.Printf("No stories similar to %q.\n", opts.Query)
+	}
+	return nil
+}
+
+// warnDuplicates prints a warning for each story of the named PRD that closely
+// matches a story in another PRD. Stories whose ID is in skip are not checked.
+// Problems are reported as warnings too, since the PRD was already written.
+func warnDuplicates(baseDir, name string, p *prd.PRD, skip map[string]bool) {
+	cfg, err := config.Load(baseDir)
+	if err != nil {
+		return
+	}
+	embedder, err := similar.New(cfg.Similar, baseDir)
+	if err != nil {
+		fmt.Printf("Warning: could not check for duplicate stories: %v\n", err)
+		return
+	}
+	if embedder == nil {
+		return
+	}
+
+	var incoming []similar.Story
+	for _, s := range similar.FromPRD(name, p) {
+		if !skip[s.ID] {
+			incoming = append(incoming, s)
+		}
+	}
+	existing, err := similar.LoadStories(baseDir)
+	if err != nil {
+		return
+	}
+	dups, err := similar.FindDuplicates(context.Background(), embedder, incoming, existing, similar.Threshold(cfg.Similar))
+	if err != nil {
+		fmt.Printf("Warning: could not check for duplicate stories: %v\n", err)
+		return
+	}
+	if len(dups) == 0 {
+		return
+	}
+
+	fmt.Println("\nThese stories look like ones in other PRDs:")
+	for _, d := range dups {
+		fmt.Printf("  %s %s\n    ~ %s/%s %s (%s, %.2f)\n", d.Story.ID, d.Story.Title, d.Existing.PRD, d.Existing.ID, d.Existing.Title, similar.FormatStatus(d.Existing), d.Score)
+	}
+}
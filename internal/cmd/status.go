@@ -1,11 +1,18 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/minicodemonkey/chief/internal/loop"
 	"github.com/minicodemonkey/chief/internal/prd"
+	"github.com/minicodemonkey/chief/internal/timefmt"
 )
 
 // StatusOptions contains configuration for the status command.
@@ -68,9 +75,14 @@ func RunStatus(opts StatusOptions) error {
 			status := ""
 			if story.InProgress {
 				status = " (in progress)"
+			} else if blocking := p.BlockedBy(&story); len(blocking) > 0 {
+				status = " (blocked by " + strings.Join(blocking, ", ") + ")"
 			}
 			fmt.Printf("  %s: %s%s\n", story.ID, story.Title, status)
 		}
+		if blockers := p.ExternalBlockers(); len(blockers) > 0 {
+			fmt.Printf("\nWaiting on other PRDs: %s\n", strings.Join(blockers, ", "))
+		}
 	} else {
 		fmt.Println("\nAll stories complete!")
 	}
@@ -78,24 +90,63 @@ func RunStatus(opts StatusOptions) error {
 	return nil
 }
 
+// List sort orders. All but SortName put the largest value first.
+const (
+	SortName     = "name"
+	SortProgress = "progress"
+	SortActivity = "activity"
+	SortStories  = "stories"
+	SortCost     = "cost"
+)
+
+// PRD states the list command can filter on.
+const (
+	StateActive   = "active"   // Stories left, touched in the last week
+	StateStalled  = "stalled"  // Stories left, untouched for a week
+	StateComplete = "complete" // Every story done
+)
+
+// stalledAfter is how long a PRD with stories left can go untouched before it
+// counts as stalled.
+const stalledAfter = 7 * 24 * time.Hour
+
 // ListOptions contains configuration for the list command.
 type ListOptions struct {
 	BaseDir string // Base directory for .chief/prds/ (default: current directory)
+	Sort    string // Sort order, one of the Sort constants (default: SortName)
+	Filter  string // Only list PRDs in this state ("" = all)
+	JSON    bool   // Print a JSON array instead of a table
 }
 
 // PRDInfo holds summary info about a PRD for the list command.
 type PRDInfo struct {
-	Name       string
-	Title      string
-	Completed  int
-	Total      int
-	Percentage int
+	Name         string    `json:"name"`
+	Title        string    `json:"title"`
+	Completed    int       `json:"completed"`
+	Total        int       `json:"total"`
+	Percentage   int       `json:"percentage"`
+	State        string    `json:"state"`
+	LastActivity time.Time `json:"lastActivity"`      // Last change to a file in the PRD directory
+	CostUSD      float64   `json:"costUsd,omitempty"` // Cost to date from usage.json
 }
 
 // RunList prints all PRDs with their progress.
 // Returns nil on success, error otherwise. Exit code should be 0 on success.
 func RunList(opts ListOptions) error {
 	// Set defaults
+	if opts.Sort == "" {
+		opts.Sort = SortName
+	}
+	switch opts.Sort {
+	case SortName, SortProgress, SortActivity, SortStories, SortCost:
+	default:
+		return fmt.Errorf("unknown sort %q: expected %s, %s, %s, %s, or %s", opts.Sort, SortName, SortProgress, SortActivity, SortStories, SortCost)
+	}
+	switch opts.Filter {
+	case "", StateActive, StateStalled, StateComplete:
+	default:
+		return fmt.Errorf("unknown filter %q: expected %s, %s, or %s", opts.Filter, StateActive, StateComplete, StateStalled)
+	}
 	if opts.BaseDir == "" {
 		cwd, err := os.Getwd()
 		if err != nil {
@@ -107,63 +158,120 @@ func RunList(opts ListOptions) error {
 	// Find all PRDs in .chief/prds/
 	prdsDir := filepath.Join(opts.BaseDir, ".chief", "prds")
 	entries, err := os.ReadDir(prdsDir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			fmt.Println("No PRDs found. Run 'chief new' to create one.")
-			return nil
-		}
+	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to read PRDs directory: %w", err)
 	}
 
 	// Collect PRD info
+	now := time.Now()
 	var prds []PRDInfo
 	for _, entry := range entries {
 		if !entry.IsDir() {
 			continue
 		}
+		info, ok := listPRD(filepath.Join(prdsDir, entry.Name()), now)
+		if ok && (opts.Filter == "" || info.State == opts.Filter) {
+			prds = append(prds, info)
+		}
+	}
+	sortPRDs(prds, opts.Sort)
 
-		name := entry.Name()
-		prdPath := filepath.Join(prdsDir, name, "prd.md")
-
-		// Try to load the PRD
-		p, err := prd.LoadPRD(prdPath)
+	if opts.JSON {
+		if prds == nil {
+			prds = []PRDInfo{}
+		}
+		data, err := json.MarshalIndent(prds, "", "  ")
 		if err != nil {
-			// Skip PRDs that can't be loaded (might be partially created)
-			continue
+			return err
 		}
+		fmt.Println(string(data))
+		return nil
+	}
 
-		// Count completed stories
-		total := len(p.UserStories)
-		completed := 0
-		for _, story := range p.UserStories {
-			if story.Passes {
-				completed++
-			}
+	if len(prds) == 0 {
+		if opts.Filter != "" {
+			fmt.Printf("No %s PRDs.\n", opts.Filter)
+			return nil
 		}
+		fmt.Println("No PRDs found. Run 'chief new' to create one.")
+		return nil
+	}
+	printPRDs(os.Stdout, prds)
+	return nil
+}
+
+// listPRD summarizes the PRD in prdDir. It returns false for a PRD that can't
+// be loaded, which might be partially created.
+func listPRD(prdDir string, now time.Time) (PRDInfo, bool) {
+	p, err := prd.LoadPRD(filepath.Join(prdDir, "prd.md"))
+	if err != nil {
+		return PRDInfo{}, false
+	}
+
+	info := PRDInfo{Name: filepath.Base(prdDir), Title: p.Project, Total: len(p.UserStories)}
+	for _, story := range p.UserStories {
+		if story.Passes {
+			info.Completed++
+		}
+	}
+	if info.Total > 0 {
+		info.Percentage = (info.Completed * 100) / info.Total
+	}
 
-		percentage := 0
-		if total > 0 {
-			percentage = (completed * 100) / total
+	if files, err := os.ReadDir(prdDir); err == nil {
+		for _, f := range files {
+			if fi, err := f.Info(); err == nil && fi.ModTime().After(info.LastActivity) {
+				info.LastActivity = fi.ModTime()
+			}
 		}
+	}
+	if usage, err := loop.ReadUsage(filepath.Join(prdDir, loop.UsageFile)); err == nil {
+		info.CostUSD = usage.CostUSD
+	}
 
-		prds = append(prds, PRDInfo{
-			Name:       name,
-			Title:      p.Project,
-			Completed:  completed,
-			Total:      total,
-			Percentage: percentage,
-		})
+	switch {
+	case info.Total > 0 && info.Completed == info.Total:
+		info.State = StateComplete
+	case now.Sub(info.LastActivity) > stalledAfter:
+		info.State = StateStalled
+	default:
+		info.State = StateActive
 	}
+	return info, true
+}
 
-	if len(prds) == 0 {
-		fmt.Println("No PRDs found. Run 'chief new' to create one.")
-		return nil
+// sortPRDs sorts the PRDs by the given column, then by name.
+func sortPRDs(prds []PRDInfo, by string) {
+	key := func(info PRDInfo) float64 {
+		switch by {
+		case SortProgress:
+			return float64(info.Percentage)
+		case SortActivity:
+			return float64(info.LastActivity.UnixNano())
+		case SortStories:
+			return float64(info.Total)
+		case SortCost:
+			return info.CostUSD
+		}
+		return 0
 	}
+	sort.SliceStable(prds, func(i, j int) bool {
+		if a, b := key(prds[i]), key(prds[j]); a != b {
+			return a > b
+		}
+		return prds[i].Name < prds[j].Name
+	})
+}
 
-	// Print PRDs
+// printPRDs prints a line per PRD.
+func printPRDs(w io.Writer, prds []PRDInfo) {
+	fmt.Fprintf(w, "%-20s %8s %7s  %-8s  %-17s %8s  %s\n", "NAME", "PROGRESS", "STORIES", "STATE", "LAST ACTIVITY", "COST", "TITLE")
 	for _, info := range prds {
-		fmt.Printf("%s: %s (%d/%d, %d%%)\n", info.Name, info.Title, info.Completed, info.Total, info.Percentage)
+		cost := "-"
+		if info.CostUSD > 0 {
+			cost = fmt.Sprintf("$%.2f", info.CostUSD)
+		}
+		stories := fmt.Sprintf("%d/%d", info.Completed, info.Total)
+		fmt.Fprintf(w, "%-20s %7d%% %7s  %-8s  %-17s %8s  %s\n", info.Name, info.Percentage, stories, info.State, timefmt.DateTime(info.LastActivity), cost, info.Title)
 	}
-
-	return nil
 }
@@ -3,7 +3,9 @@ package cmd
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestRunStatusWithValidPRD(t *testing.T) {
@@ -212,3 +214,82 @@ func TestRunStatusEmptyPRD(t *testing.T) {
 		t.Errorf("RunStatus() returned error: %v", err)
 	}
 }
+
+func TestListPRDsSortAndFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdsDir := filepath.Join(tmpDir, ".chief", "prds")
+	now := time.Now()
+	prds := []struct {
+		name  string
+		md    string
+		touch time.Time
+		usage string
+	}{
+		{"auth", "# Auth\n\n### US-001: Login\n**Status:** done\n- [x] Works\n\n### US-002: Logout\n- [ ] Works\n", now.Add(-time.Hour), `{"costUsd": 12.4}`},
+		{"api", "# API\n\n### US-001: Endpoints\n- [ ] Works\n", now.Add(-30 * 24 * time.Hour), ""},
+		{"site", "# Site\n\n### US-001: Page\n**Status:** done\n- [x] Works\n", now.Add(-48 * time.Hour), `{"costUsd": 3}`},
+	}
+	for _, p := range prds {
+		dir := filepath.Join(prdsDir, p.name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		files := map[string]string{"prd.md": p.md}
+		if p.usage != "" {
+			files["usage.json"] = p.usage
+		}
+		for name, content := range files {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(path, p.touch, p.touch); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	var infos []PRDInfo
+	for _, p := range prds {
+		info, ok := listPRD(filepath.Join(prdsDir, p.name), now)
+		if !ok {
+			t.Fatalf("listPRD(%s) failed", p.name)
+		}
+		infos = append(infos, info)
+	}
+	states := map[string]string{}
+	for _, info := range infos {
+		states[info.Name] = info.State
+	}
+	want := map[string]string{"auth": StateActive, "api": StateStalled, "site": StateComplete}
+	if !reflect.DeepEqual(states, want) {
+		t.Errorf("states = %v, want %v", states, want)
+	}
+
+	order := func(by string) []string {
+		sortPRDs(infos, by)
+		var names []string
+		for _, info := range infos {
+			names = append(names, info.Name)
+		}
+		return names
+	}
+	for by, want := range map[string][]string{
+		SortName:     {"api", "auth", "site"},
+		SortProgress: {"site", "auth", "api"},
+		SortActivity: {"auth", "site", "api"},
+		SortStories:  {"auth", "api", "site"},
+		SortCost:     {"auth", "site", "api"},
+	} {
+		if got := order(by); !reflect.DeepEqual(got, want) {
+			t.Errorf("sorted by %s = %v, want %v", by, got, want)
+		}
+	}
+
+	if err := RunList(ListOptions{BaseDir: tmpDir, Filter: StateStalled, JSON: true}); err != nil {
+		t.Errorf("RunList() returned error: %v", err)
+	}
+	if err := RunList(ListOptions{BaseDir: tmpDir, Sort: "size"}); err == nil {
+		t.Error("expected an error for an unknown sort")
+	}
+}
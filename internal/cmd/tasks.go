@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// TasksOptions contains configuration for the tasks commands.
+type TasksOptions struct {
+	StoryID string // Human task to mark done (tasks pass only)
+	Name    string // PRD name (default: every PRD)
+	BaseDir string // Base directory for .chief/prds/ (default: current directory)
+}
+
+// RunTasksList prints the human tasks that aren't done yet.
+func RunTasksList(opts TasksOptions) error {
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+
+	prdsDir := filepath.Join(opts.BaseDir, ".chief", "prds")
+	names := []string{opts.Name}
+	if opts.Name == "" {
+		entries, err := os.ReadDir(prdsDir)
+		if err != nil {
+			return fmt.Errorf("no PRDs found in %s", prdsDir)
+		}
+		names = nil
+		for _, entry := range entries {
+			if entry.IsDir() {
+				names = append(names, entry.Name())
+			}
+		}
+		sort.Strings(names)
+	}
+
+	found := 0
+	for _, name := range names {
+		p, err := prd.LoadPRD(filepath.Join(prdsDir, name, "prd.md"))
+		if err != nil {
+			if opts.Name != "" {
+				return fmt.Errorf("PRD %q not found", opts.Name)
+			}
+			continue
+		}
+		waiting := p.WaitingOnHumans()
+		if len(waiting) == 0 {
+			continue
+		}
+		if found > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s\n", name)
+		for _, s := range waiting {
+			fmt.Printf("  %s  %s\n", s.ID, s.Title)
+			if blocked := dependentsOf(p, s.ID); len(blocked) > 0 {
+				fmt.Printf("          blocks %s\n", strings.Join(blocked, ", "))
+			}
+		}
+		found += len(waiting)
+	}
+
+	if found == 0 {
+		fmt.Println("No tasks are waiting for you.")
+		return nil
+	}
+	fmt.Println("\nRun `chief tasks pass <id>` when a task is done.")
+	return nil
+}
+
+// RunTasksPass marks a human task as done and reports the agent stories it
+// unblocks.
+func RunTasksPass(opts TasksOptions) error {
+	if opts.StoryID == "" {
+		return fmt.Errorf("missing story ID")
+	}
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+
+	name, story, err := findStory(opts.BaseDir, opts.Name, opts.StoryID)
+	if err != nil {
+		return err
+	}
+	if !story.IsHuman() {
+		return fmt.Errorf("%s is an agent story; only stories with **Owner:** human can be passed by hand", story.ID)
+	}
+	if story.Passes {
+		fmt.Printf("%s is already done.\n", story.ID)
+		return nil
+	}
+
+	prdPath := filepath.Join(opts.BaseDir, ".chief", "prds", name, "prd.md")
+	before, err := prd.LoadPRD(prdPath)
+	if err != nil {
+		return err
+	}
+	if err := prd.SetStoryStatus(prdPath, story.ID, "done"); err != nil {
+		return fmt.Errorf("failed to mark %s done: %w", story.ID, err)
+	}
+	after, err := prd.LoadPRD(prdPath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Marked %s done: %s\n", story.ID, story.Title)
+	var unblocked []string
+	for i := range after.UserStories {
+		s := &after.UserStories[i]
+		if s.Passes || s.IsHuman() || len(after.BlockedBy(s)) > 0 {
+			continue
+		}
+		if len(before.BlockedBy(&before.UserStories[i])) > 0 {
+			unblocked = append(unblocked, s.ID)
+		}
+	}
+	if len(unblocked) > 0 {
+		fmt.Printf("Unblocked %s. Resume %s to let the agent continue.\n", strings.Join(unblocked, ", "), name)
+	}
+	return nil
+}
+
+// dependentsOf returns the IDs of stories that depend on the given story.
+func dependentsOf(p *prd.PRD, id string) []string {
+	var ids []string
+	for _, s := range p.UserStories {
+		for _, dep := range s.DependsOn {
+			if strings.EqualFold(dep, id) {
+				ids = append(ids, s.ID)
+				break
+			}
+		}
+	}
+	return ids
+}
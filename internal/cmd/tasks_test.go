@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+func TestRunTasksPass(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdDir := filepath.Join(tmpDir, ".chief", "prds", "billing")
+	if err := os.MkdirAll(prdDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	md := "# Billing\n\n### US-001: Create Stripe account\n**Owner:** human\n- [ ] Keys in .env\n\n### US-002: Charge cards\n**Depends on:** US-001\n- [ ] It works\n"
+	prdPath := filepath.Join(prdDir, "prd.md")
+	if err := os.WriteFile(prdPath, []byte(md), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RunTasksPass(TasksOptions{StoryID: "US-002", BaseDir: tmpDir}); err == nil || !strings.Contains(err.Error(), "agent story") {
+		t.Errorf("expected passing an agent story to fail, got %v", err)
+	}
+	if err := RunTasksPass(TasksOptions{StoryID: "us-001", BaseDir: tmpDir}); err != nil {
+		t.Fatalf("RunTasksPass() error = %v", err)
+	}
+
+	p, err := prd.LoadPRD(prdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.UserStories[0].Passes {
+		t.Error("expected US-001 to be done")
+	}
+	if next := p.NextStory(); next == nil || next.ID != "US-002" {
+		t.Errorf("expected US-002 to be unblocked, got %+v", next)
+	}
+}
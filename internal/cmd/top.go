@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/minicodemonkey/chief/internal/control"
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/proctree"
+	"github.com/minicodemonkey/chief/internal/tui"
+)
+
+// topKillGrace is how long a killed process gets to exit after SIGTERM
+// before it is sent SIGKILL.
+const topKillGrace = 5 * time.Second
+
+// TopOptions contains configuration for the top command.
+type TopOptions struct {
+	BaseDir string // Project root (default: current directory)
+	Once    bool   // Print the processes once instead of the live view
+}
+
+// RunTop shows the processes chief runs for the project on this machine, with
+// their CPU and memory use and the PRD each works for.
+func RunTop(opts TopOptions) error {
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+
+	load := func() ([]tui.TopRow, error) { return topRows(opts.BaseDir) }
+	if !opts.Once {
+		kill := func(pid int) error { return killChiefChild(opts.BaseDir, pid) }
+		if _, err := tea.NewProgram(tui.NewTop(load, kill), tea.WithAltScreen()).Run(); err != nil {
+			return fmt.Errorf("error running program: %w", err)
+		}
+		return nil
+	}
+
+	rows, err := load()
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		fmt.Println("No chief runs in this project on this machine.")
+		return nil
+	}
+	fmt.Printf("%7s  %-14s %5s %7s %11s  %s\n", "PID", "PRD", "CPU%", "MEM", "TIME", "COMMAND")
+	for _, row := range rows {
+		fmt.Printf("%7d  %-14s %5.1f %6dM %11s  %s%s\n", row.PID, row.PRD, row.CPU, row.RSS>>20, row.Elapsed, strings.Repeat("  ", row.Depth), row.Command)
+	}
+	return nil
+}
+
+// chiefProcesses returns the chief processes running PRDs of the project on
+// this machine, with the PRDs each runs, from the PRDs' run locks.
+func chiefProcesses(baseDir string) map[int][]string {
+	host, _ := os.Hostname()
+	chiefs := make(map[int][]string)
+	prdsDir := filepath.Join(baseDir, ".chief", "prds")
+	entries, _ := os.ReadDir(prdsDir)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if lock := loop.ReadRunLock(filepath.Join(prdsDir, entry.Name())); lock != nil && lock.Host == host {
+			chiefs[lock.PID] = append(chiefs[lock.PID], entry.Name())
+		}
+	}
+	return chiefs
+}
+
+// topRows lists each chief process with everything it started, depth first.
+// Agents are attributed to their PRD through the control socket; the rest
+// of a chief's children belong to its PRD when it runs only one.
+func topRows(baseDir string) ([]tui.TopRow, error) {
+	chiefs := chiefProcesses(baseDir)
+	if len(chiefs) == 0 {
+		return nil, nil
+	}
+	agents := make(map[int]string)
+	if client, err := control.Dial(baseDir); err == nil {
+		if statuses, err := client.Status(); err == nil {
+			for _, s := range statuses {
+				if s.AgentPID != 0 {
+					agents[s.AgentPID] = s.Name
+				}
+			}
+		}
+		client.Close()
+	}
+	procs, err := proctree.List()
+	if err != nil {
+		return nil, err
+	}
+
+	pids := make([]int, 0, len(chiefs))
+	for pid := range chiefs {
+		pids = append(pids, pid)
+	}
+	sort.Ints(pids)
+
+	var rows []tui.TopRow
+	for _, pid := range pids {
+		var owners []string // PRD at each depth of the walk
+		for _, node := range proctree.Tree(procs, pid) {
+			owner := ""
+			switch {
+			case node.Depth == 0:
+				owner = strings.Join(chiefs[pid], ",")
+			case agents[node.PID] != "":
+				owner = agents[node.PID]
+			case node.Depth > 1 || len(chiefs[pid]) == 1:
+				owner = owners[node.Depth-1]
+			}
+			owners = append(owners[:node.Depth], owner)
+			rows = append(rows, tui.TopRow{
+				PID:     node.PID,
+				Depth:   node.Depth,
+				PRD:     owner,
+				CPU:     node.CPU,
+				RSS:     node.RSS,
+				Elapsed: node.Elapsed,
+				Command: node.Command,
+			})
+		}
+	}
+	return rows, nil
+}
+
+// killChiefChild stops pid, asking it to exit before killing it, as long as
+// one of the project's chief processes started it. The loop treats a killed
+// agent like a crashed one and retries the iteration.
+func killChiefChild(baseDir string, pid int) error {
+	procs, err := proctree.List()
+	if err != nil {
+		return err
+	}
+	for chief := range chiefProcesses(baseDir) {
+		if proctree.Descends(procs, pid, chief) {
+			return proctree.Terminate(pid, topKillGrace)
+		}
+	}
+	return fmt.Errorf("process %d wasn't started by chief for this project", pid)
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/loop"
+)
+
+func TestTopRows(t *testing.T) {
+	dir := t.TempDir()
+	if rows, err := topRows(dir); err != nil || rows != nil {
+		t.Fatalf("expected no rows without a run, got %v (%v)", rows, err)
+	}
+
+	// A live process on this host holding the run lock of one PRD
+	sleeper := exec.Command("sleep", "30")
+	if err := sleeper.Start(); err != nil {
+		t.Skip("sleep not available")
+	}
+	defer func() {
+		sleeper.Process.Kill()
+		sleeper.Wait()
+	}()
+	host, _ := os.Hostname()
+	lock, _ := json.Marshal(loop.RunLock{Host: host, PID: sleeper.Process.Pid, Started: time.Now(), Heartbeat: time.Now()})
+	prdDir := filepath.Join(dir, ".chief", "prds", "auth")
+	if err := os.MkdirAll(prdDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(prdDir, loop.RunLockFile), lock, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := topRows(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].PID != sleeper.Process.Pid || rows[0].PRD != "auth" || rows[0].Depth != 0 {
+		t.Errorf("expected the chief process for auth, got %+v", rows)
+	}
+
+	if err := killChiefChild(dir, os.Getpid()); err == nil {
+		t.Error("expected a process chief didn't start to be refused")
+	}
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/minicodemonkey/chief/internal/timefmt"
+	"github.com/minicodemonkey/chief/internal/undo"
+)
+
+// UndoOptions contains configuration for the undo command.
+type UndoOptions struct {
+	BaseDir string // Project root holding .chief/undo/ (default: current directory)
+	List    bool   // List what can be undone instead of undoing it
+}
+
+// RunUndo puts back the files changed by the most recent destructive
+// command, from the snapshot taken before it ran.
+func RunUndo(opts UndoOptions) error {
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+
+	if opts.List {
+		entries, err := undo.List(opts.BaseDir)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			fmt.Println("Nothing to undo")
+			return nil
+		}
+		for i, e := range entries {
+			marker := " "
+			if i == 0 {
+				marker = "→"
+			}
+			fmt.Printf("%s %s  %-22s %s\n", marker, timefmt.DateTime(e.Time), e.Action, e.Description)
+		}
+		return nil
+	}
+
+	e, err := undo.Undo(opts.BaseDir)
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		fmt.Println("Nothing to undo")
+		return nil
+	}
+	fmt.Printf("Undid %s from %s: %s\n", e.Action, timefmt.DateTime(e.Time), e.Description)
+	for _, p := range e.Paths {
+		if p.Existed {
+			fmt.Printf("  restored %s\n", p.Path)
+		} else {
+			fmt.Printf("  removed  %s\n", p.Path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/prompt"
+)
+
+func TestRunUndo_InitConventions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conventionsPath := filepath.Join(dir, prompt.ConventionsFile)
+	if err := os.MkdirAll(filepath.Dir(conventionsPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(conventionsPath, []byte("- Hand-written rule\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RunInitConventions(InitConventionsOptions{BaseDir: dir, Force: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunUndo(UndoOptions{BaseDir: dir}); err != nil {
+		t.Fatalf("RunUndo() error = %v", err)
+	}
+
+	data, err := os.ReadFile(conventionsPath)
+	if err != nil || string(data) != "- Hand-written rule\n" {
+		t.Errorf("expected the hand-written conventions back, got %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".chief", "config.yaml")); !os.IsNotExist(err) {
+		t.Error("expected the config written by init-conventions to be removed")
+	}
+	if err := RunUndo(UndoOptions{BaseDir: dir}); err != nil {
+		t.Errorf("expected an empty journal to be fine, got %v", err)
+	}
+}
@@ -3,7 +3,11 @@ package cmd
 import (
 	"fmt"
 	"log"
+	"net/http"
+	"os"
 
+	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/network"
 	"github.com/minicodemonkey/chief/internal/update"
 )
 
@@ -17,9 +21,15 @@ type UpdateOptions struct {
 func RunUpdate(opts UpdateOptions) error {
 	fmt.Println("Checking for updates...")
 
+	client, err := projectHTTPClient()
+	if err != nil {
+		return err
+	}
+
 	// First check if an update is available
 	result, err := update.CheckForUpdate(opts.Version, update.Options{
 		ReleasesURL: opts.ReleasesURL,
+		Client:      client,
 	})
 	if err != nil {
 		return fmt.Errorf("checking for updates: %w", err)
@@ -35,6 +45,7 @@ func RunUpdate(opts UpdateOptions) error {
 	// Perform the update
 	if _, err := update.PerformUpdate(opts.Version, update.Options{
 		ReleasesURL: opts.ReleasesURL,
+		Client:      client,
 	}); err != nil {
 		return err
 	}
@@ -47,7 +58,9 @@ func RunUpdate(opts UpdateOptions) error {
 // This is called on startup for interactive CLI commands.
 func CheckVersionOnStartup(version string) {
 	go func() {
-		result, err := update.CheckForUpdate(version, update.Options{})
+		// A broken network config is reported by the commands that need it
+		client, _ := projectHTTPClient()
+		result, err := update.CheckForUpdate(version, update.Options{Client: client})
 		if err != nil {
 			// Silently fail — version check is best-effort
 			return
@@ -61,8 +74,14 @@ func CheckVersionOnStartup(version string) {
 
 // CheckVersionForServe performs a version check and returns the result for use by the serve command.
 func CheckVersionForServe(version, releasesURL string) *update.CheckResult {
+	client, err := projectHTTPClient()
+	if err != nil {
+		log.Printf("Version check failed: %v", err)
+		return nil
+	}
 	result, err := update.CheckForUpdate(version, update.Options{
 		ReleasesURL: releasesURL,
+		Client:      client,
 	})
 	if err != nil {
 		log.Printf("Version check failed: %v", err)
@@ -70,3 +89,17 @@ func CheckVersionForServe(version, releasesURL string) *update.CheckResult {
 	}
 	return result
 }
+
+// projectHTTPClient returns an HTTP client that uses the network settings of
+// the project in the current directory: its proxy and CA bundle.
+func projectHTTPClient() (*http.Client, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+	cfg, err := config.Load(cwd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load .chief/config.yaml: %w", err)
+	}
+	return network.HTTPClient(cfg.Network, cwd)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// ValidateOptions contains configuration for the validate command.
+type ValidateOptions struct {
+	Name    string // PRD name (default: "main")
+	BaseDir string // Base directory for .chief/prds/ (default: current directory)
+}
+
+// RunValidate checks a PRD against the format Chief expects and prints the
+// problems found. Returns an error when there are any, so a script can check
+// a PRD before starting a run.
+func RunValidate(opts ValidateOptions) error {
+	if opts.Name == "" {
+		opts.Name = "main"
+	}
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+
+	prdPath := filepath.Join(opts.BaseDir, ".chief", "prds", opts.Name, "prd.md")
+	p, err := prd.LoadPRD(prdPath)
+	if err != nil {
+		return fmt.Errorf("failed to load PRD %q: %w", opts.Name, err)
+	}
+
+	err = p.Validate()
+	if err == nil {
+		fmt.Printf("%s: %d stories, no problems found\n", opts.Name, len(p.UserStories))
+		return nil
+	}
+	fmt.Printf("%s:\n", opts.Name)
+	printProblems(os.Stdout, err)
+	var invalid *prd.ValidationError
+	errors.As(err, &invalid)
+	return fmt.Errorf("PRD %q has %d problem(s)", opts.Name, len(invalid.Problems))
+}
+
+// printProblems prints the problems of a PRD that failed validation.
+func printProblems(w io.Writer, err error) {
+	var invalid *prd.ValidationError
+	if !errors.As(err, &invalid) {
+		fmt.Fprintf(w, "  ✗ %v\n", err)
+		return
+	}
+	for _, problem := range invalid.Problems {
+		fmt.Fprintf(w, "  ✗ %s\n", problem)
+	}
+}
+
+// warnInvalid prints the problems of a PRD an agent just wrote, if any.
+func warnInvalid(p *prd.PRD) {
+	if err := p.Validate(); err != nil {
+		fmt.Println("\nWarning: prd.md doesn't match the expected format:")
+		printProblems(os.Stdout, err)
+		fmt.Println("Run 'chief validate' after fixing it; runs won't start until it passes.")
+	}
+}
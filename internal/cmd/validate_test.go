@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunValidate(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdDir := filepath.Join(tmpDir, ".chief", "prds", "test")
+	if err := os.MkdirAll(prdDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	prdPath := filepath.Join(prdDir, "prd.md")
+
+	valid := "# Test Project\n\n### US-001: Story 1\n- [ ] Works\n"
+	if err := os.WriteFile(prdPath, []byte(valid), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunValidate(ValidateOptions{Name: "test", BaseDir: tmpDir}); err != nil {
+		t.Errorf("RunValidate() on a valid PRD = %v", err)
+	}
+
+	invalid := valid + "\n### US-002: Story 2\n**Depends on:** US-009\n"
+	if err := os.WriteFile(prdPath, []byte(invalid), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunValidate(ValidateOptions{Name: "test", BaseDir: tmpDir}); err == nil {
+		t.Error("RunValidate() on an invalid PRD = nil, want an error")
+	}
+}
+
+func TestRunValidate_Missing(t *testing.T) {
+	if err := RunValidate(ValidateOptions{Name: "nope", BaseDir: t.TempDir()}); err == nil {
+		t.Error("RunValidate() on a missing PRD = nil, want an error")
+	}
+}
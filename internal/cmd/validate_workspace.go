@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/workspace"
+)
+
+// ValidateWorkspaceOptions contains configuration for the validate-workspace command.
+type ValidateWorkspaceOptions struct {
+	Workspace   string // Workspace directory to scan (default: current directory)
+	MaxRepoSize int64  // Repository size warning threshold in bytes (default: 1 GiB)
+}
+
+// RunValidateWorkspace scans a workspace the same way a device exposes it to the
+// web app and reports the projects, PRDs, and problems found.
+// Returns an error when any project has error-level issues.
+func RunValidateWorkspace(opts ValidateWorkspaceOptions) error {
+	if opts.Workspace == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.Workspace = cwd
+	}
+
+	projects, err := workspace.Scan(opts.Workspace)
+	if err != nil {
+		return fmt.Errorf("failed to scan workspace %s: %w", opts.Workspace, err)
+	}
+
+	// Pin and hide rules come from the workspace root's .chief/config.yaml
+	cfg, err := config.Load(opts.Workspace)
+	if err != nil {
+		return fmt.Errorf("failed to load workspace config: %w", err)
+	}
+	scanned := len(projects)
+	projects = workspace.Arrange(projects, cfg.Workspace.Pin, cfg.Workspace.Hide)
+
+	fmt.Printf("Workspace: %s\n", opts.Workspace)
+	if hidden := scanned - len(projects); hidden > 0 {
+		fmt.Printf("%d project(s) hidden by workspace.hide\n", hidden)
+	}
+	if len(projects) == 0 {
+		fmt.Println("No projects found. Projects are git repositories or directories containing .chief/.")
+		return nil
+	}
+	fmt.Printf("Found %d project(s)\n", len(projects))
+
+	allIssues := workspace.PreflightAll(projects, workspace.PreflightOptions{MaxRepoSize: opts.MaxRepoSize})
+
+	errorCount, warningCount := 0, 0
+	for i, p := range projects {
+		pinned := ""
+		if p.Pinned {
+			pinned = " [pinned]"
+		}
+		fmt.Printf("\n%s%s (%s)\n", p.Name, pinned, p.Path)
+
+		if len(p.PRDs) == 0 {
+			fmt.Println("  PRDs: none")
+		} else {
+			fmt.Println("  PRDs:")
+			for _, summary := range p.PRDs {
+				if summary.Err != nil {
+					fmt.Printf("    %s (unreadable)\n", summary.Name)
+					continue
+				}
+				fmt.Printf("    %s (%d/%d stories complete)\n", summary.Name, summary.Completed, summary.Total)
+			}
+		}
+
+		for _, issue := range allIssues[i] {
+			if issue.Severity == workspace.SeverityError {
+				errorCount++
+				fmt.Printf("  ✗ %s\n", issue.Message)
+			} else {
+				warningCount++
+				fmt.Printf("  ! %s\n", issue.Message)
+			}
+		}
+	}
+
+	fmt.Println()
+	if errorCount == 0 && warningCount == 0 {
+		fmt.Println("No problems found.")
+		return nil
+	}
+	fmt.Printf("%d error(s), %d warning(s)\n", errorCount, warningCount)
+	if errorCount > 0 {
+		return fmt.Errorf("workspace has %d error(s)", errorCount)
+	}
+	return nil
+}
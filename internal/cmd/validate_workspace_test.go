@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunValidateWorkspace(t *testing.T) {
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	root := t.TempDir()
+	projectDir := filepath.Join(root, "api")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("git", "init")
+	cmd.Dir = projectDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %s", out)
+	}
+
+	// Missing git identity is an error
+	if err := RunValidateWorkspace(ValidateWorkspaceOptions{Workspace: root}); err == nil {
+		t.Error("expected error for project without git identity")
+	}
+
+	for _, args := range [][]string{
+		{"git", "config", "user.name", "Test"},
+		{"git", "config", "user.email", "test@test.com"},
+	} {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = projectDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v failed: %s", args, out)
+		}
+	}
+
+	if err := RunValidateWorkspace(ValidateWorkspaceOptions{Workspace: root}); err != nil {
+		t.Errorf("expected no error once identity is configured, got %v", err)
+	}
+}
+
+func TestRunValidateWorkspace_MissingWorkspace(t *testing.T) {
+	err := RunValidateWorkspace(ValidateWorkspaceOptions{Workspace: filepath.Join(t.TempDir(), "missing")})
+	if err == nil {
+		t.Error("expected error for missing workspace")
+	}
+}
+
+func TestRunValidateWorkspace_PinAndHide(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"api", "legacy"} {
+		if err := os.MkdirAll(filepath.Join(root, name, ".chief"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := "workspace:\n  pin: [legacy]\n  hide: [\"leg*\", api]\n"
+	if err := os.MkdirAll(filepath.Join(root, ".chief"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".chief", "config.yaml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RunValidateWorkspace(ValidateWorkspaceOptions{Workspace: root}); err != nil {
+		t.Errorf("RunValidateWorkspace() error = %v", err)
+	}
+}
@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/forge"
+	"github.com/minicodemonkey/chief/internal/git"
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/prd"
+	"github.com/minicodemonkey/chief/internal/timefmt"
+)
+
+// WatchPROptions contains configuration for the watch-pr command.
+type WatchPROptions struct {
+	Name     string        // PRD name (default: "main")
+	BaseDir  string        // Base directory for .chief/prds/ (default: current directory)
+	Interval time.Duration // Time between checks for new comments (default: 5 minutes)
+	Once     bool          // Check once and return instead of polling
+	Provider loop.Provider // Agent CLI provider
+}
+
+// SeenCommentsFile records the review comments watch-pr has handled, inside
+// the PRD directory.
+const SeenCommentsFile = "pr-comments.json"
+
+// maxCommentLength caps how much of a comment is copied into a story.
+const maxCommentLength = 600
+
+// Review operations (replaceable in tests).
+var (
+	findPullRequest = forge.FindPullRequest
+	reviewComments  = forge.ReviewComments
+	pushBranch      = git.PushBranch
+)
+
+// acknowledgementRegex matches comments that need no change, e.g. "LGTM" or
+// "Thanks!".
+var acknowledgementRegex = regexp.MustCompile(`(?i)^\W*(lgtm|looks good( to me)?|thanks?( you)?|thx|nice|great( work| job)?|approved?|ship it|\+1|👍|🎉|🚀)\W*$`)
+
+// storyNumberRegex extracts the number from a story ID like "US-007".
+var storyNumberRegex = regexp.MustCompile(`-(\d+)$`)
+
+// RunWatchPR follows the pull request of a PRD's branch. Each actionable
+// review comment becomes a follow-up story on the PRD, the loop runs those
+// stories, and the fixes are pushed to the PR. It polls until the PR is
+// closed or merged, or until interrupted.
+func RunWatchPR(opts WatchPROptions) error {
+	if opts.Name == "" {
+		opts.Name = "main"
+	}
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 5 * time.Minute
+	}
+	if opts.Provider == nil {
+		return fmt.Errorf("watch-pr command requires Provider to be set")
+	}
+
+	prdPath := filepath.Join(opts.BaseDir, ".chief", "prds", opts.Name, "prd.md")
+	if _, err := prd.LoadPRD(prdPath); err != nil {
+		return fmt.Errorf("PRD %q not found", opts.Name)
+	}
+	workDir := opts.BaseDir
+	if wt := git.WorktreePathForPRD(opts.BaseDir, opts.Name); git.IsWorktree(wt) {
+		workDir = wt
+	}
+	branch, err := git.GetCurrentBranch(workDir)
+	if err != nil {
+		return fmt.Errorf("failed to find the PRD's branch: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	seenPath := filepath.Join(filepath.Dir(prdPath), SeenCommentsFile)
+	for {
+		pr, err := findPullRequest(workDir, branch)
+		if err != nil {
+			return err
+		}
+		if pr == nil {
+			return fmt.Errorf("no pull request found for %s; open one first", branch)
+		}
+		if !pr.IsOpen() {
+			fmt.Printf("PR #%d is %s; stopped watching.\n", pr.Number, strings.ToLower(pr.State))
+			return nil
+		}
+
+		comments, err := reviewComments(workDir, pr.Number)
+		if err != nil {
+			return err
+		}
+		seen := loadSeenComments(seenPath)
+		var actionable []forge.ReviewComment
+		for _, c := range comments {
+			if seen[c.Key()] {
+				continue
+			}
+			seen[c.Key()] = true
+			if actionableComment(c) {
+				actionable = append(actionable, c)
+			}
+		}
+
+		if len(actionable) > 0 {
+			added, err := addReviewStories(prdPath, actionable)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Added %d follow-up story(s) from review comments on PR #%d: %s\n", len(added), pr.Number, strings.Join(added, ", "))
+			if err := saveSeenComments(seenPath, seen); err != nil {
+				return err
+			}
+			if err := runReviewStories(opts, prdPath, workDir, branch); err != nil {
+				return err
+			}
+			if err := pushBranch(workDir, branch); err != nil {
+				return err
+			}
+			fmt.Printf("Pushed the fixes to %s (PR #%d: %s)\n", branch, pr.Number, pr.URL)
+		} else if err := saveSeenComments(seenPath, seen); err != nil {
+			return err
+		}
+
+		if opts.Once {
+			return nil
+		}
+		if len(actionable) == 0 {
+			fmt.Printf("%s No new review comments on PR #%d; checking again in %s\n", timefmt.Clock(time.Now()), pr.Number, opts.Interval)
+		}
+		select {
+		case <-ctx.Done():
+			fmt.Println("Stopped watching.")
+			return nil
+		case <-time.After(opts.Interval):
+		}
+	}
+}
+
+// runReviewStories runs the loop on the PRD until its follow-up stories pass.
+func runReviewStories(opts WatchPROptions, prdPath, workDir, branch string) error {
+	p, err := prd.LoadPRD(prdPath)
+	if err != nil {
+		return err
+	}
+	remaining := 0
+	for _, s := range p.UserStories {
+		if !s.Passes {
+			remaining++
+		}
+	}
+
+	cfg, err := config.Load(opts.BaseDir)
+	if err != nil {
+		cfg = config.Default()
+	}
+	manager := loop.NewManager(remaining+5, opts.Provider)
+	manager.SetBaseDir(opts.BaseDir)
+	manager.SetConfig(cfg)
+	if workDir != opts.BaseDir {
+		err = manager.RegisterWithWorktree(opts.Name, prdPath, workDir, branch)
+	} else {
+		err = manager.Register(opts.Name, prdPath)
+	}
+	if err != nil {
+		return err
+	}
+	if err := manager.Start(opts.Name); err != nil {
+		return err
+	}
+	watchRun(manager, opts.Name, func(event loop.Event) {
+		if line := plainEvent(event, true); line != "" {
+			fmt.Println(line)
+		}
+	})
+
+	instance := manager.GetInstance(opts.Name)
+	switch {
+	case instance.State == loop.LoopStateComplete:
+		return nil
+	case instance.Error != nil:
+		return instance.Error
+	case instance.State == loop.LoopStateStopped:
+		return fmt.Errorf("stopped; the fixes were not pushed")
+	default:
+		return fmt.Errorf("the follow-up stories did not pass; resume with `chief %s`, then push", opts.Name)
+	}
+}
+
+// actionableComment returns true if a review comment asks for a change:
+// replies within a thread and plain acknowledgements are left out, as are
+// approving reviews.
+func actionableComment(c forge.ReviewComment) bool {
+	if c.InReplyTo != 0 || c.State == forge.ReviewApproved {
+		return false
+	}
+	return !acknowledgementRegex.MatchString(strings.TrimSpace(c.Body))
+}
+
+// addReviewStories appends a follow-up story for each comment to the PRD and
+// returns the IDs of the stories added.
+func addReviewStories(prdPath string, comments []forge.ReviewComment) ([]string, error) {
+	data, err := os.ReadFile(prdPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PRD: %w", err)
+	}
+	p, err := prd.ParseMarkdownPRDFromString(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", prdPath, err)
+	}
+	prefix, nextID, priority := p.ExtractIDPrefix(), 1, 0
+	for _, story := range p.UserStories {
+		if m := storyNumberRegex.FindStringSubmatch(story.ID); m != nil {
+			if n, _ := strconv.Atoi(m[1]); n >= nextID {
+				nextID = n + 1
+			}
+		}
+		if int(story.Priority) > priority {
+			priority = int(story.Priority)
+		}
+	}
+
+	var ids []string
+	var b strings.Builder
+	for _, c := range comments {
+		id := fmt.Sprintf("%s-%03d", prefix, nextID)
+		nextID++
+		priority++
+		b.WriteString("\n")
+		b.WriteString(renderReviewStory(id, priority, c))
+		ids = append(ids, id)
+	}
+	content := strings.TrimRight(string(data), "\n") + "\n" + b.String()
+	if err := os.WriteFile(prdPath, []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write PRD: %w", err)
+	}
+	return ids, nil
+}
+
+// renderReviewStory renders the markdown for the story addressing a comment.
+func renderReviewStory(id string, priority int, c forge.ReviewComment) string {
+	body := strings.Join(strings.Fields(c.Body), " ")
+	if runes := []rune(body); len(runes) > maxCommentLength {
+		body = string(runes[:maxCommentLength-3]) + "..."
+	}
+	where := "the pull request"
+	if c.Path != "" {
+		where = c.Path
+		if c.Line > 0 {
+			where = fmt.Sprintf("%s:%d", c.Path, c.Line)
+		}
+	}
+	author := "A reviewer"
+	if c.Author != "" {
+		author = "@" + c.Author
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "### %s: Address review comment on %s\n", id, where)
+	fmt.Fprintf(&b, "**Priority:** %d\n", priority)
+	fmt.Fprintf(&b, "**Description:** %s commented on %s: \"%s\"", author, where, body)
+	if c.URL != "" {
+		fmt.Fprintf(&b, " (%s)", c.URL)
+	}
+	b.WriteString("\n\n**Acceptance Criteria:**\n")
+	b.WriteString("- [ ] The change the comment asks for is made, or the code explains why not\n")
+	b.WriteString("- [ ] Tests pass\n")
+	return b.String()
+}
+
+// loadSeenComments reads the keys of the comments already handled.
+func loadSeenComments(path string) map[string]bool {
+	seen := make(map[string]bool)
+	var keys []string
+	if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &keys) == nil {
+		for _, k := range keys {
+			seen[k] = true
+		}
+	}
+	return seen
+}
+
+// saveSeenComments writes the keys of the comments already handled.
+func saveSeenComments(path string, seen map[string]bool) error {
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
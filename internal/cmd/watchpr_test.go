@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/forge"
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+func TestActionableComment(t *testing.T) {
+	tests := []struct {
+		comment forge.ReviewComment
+		want    bool
+	}{
+		{forge.ReviewComment{Body: "This leaks the file handle; close it in a defer."}, true},
+		{forge.ReviewComment{Body: "nit: rename to userID"}, true},
+		{forge.ReviewComment{Body: "LGTM!"}, false},
+		{forge.ReviewComment{Body: "Thanks 👍"}, false},
+		{forge.ReviewComment{Body: "👍"}, false},
+		{forge.ReviewComment{Body: "Fixed", InReplyTo: 11}, false},
+		{forge.ReviewComment{Body: "Nice work", State: forge.ReviewApproved}, false},
+		{forge.ReviewComment{Body: "Please add tests", State: forge.ReviewChangesRequested}, true},
+	}
+	for _, tt := range tests {
+		if got := actionableComment(tt.comment); got != tt.want {
+			t.Errorf("actionableComment(%q) = %v, want %v", tt.comment.Body, got, tt.want)
+		}
+	}
+}
+
+func TestAddReviewStories(t *testing.T) {
+	prdPath := filepath.Join(t.TempDir(), "prd.md")
+	content := "# Auth\n\n### AUTH-001: Login\n**Status:** done\n- [x] Works\n\n### AUTH-002: Logout\n**Priority:** 2\n- [ ] Works\n"
+	if err := os.WriteFile(prdPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := addReviewStories(prdPath, []forge.ReviewComment{
+		{Author: "ana", Body: "Handle the\nnil case", Path: "auth.go", Line: 42, URL: "https://github.com/o/r/pull/7#discussion_r11"},
+		{Kind: forge.KindReview, Body: "Please add tests"},
+	})
+	if err != nil {
+		t.Fatalf("addReviewStories() error = %v", err)
+	}
+	if strings.Join(ids, ",") != "AUTH-003,AUTH-004" {
+		t.Errorf("expected AUTH-003 and AUTH-004, got %v", ids)
+	}
+
+	p, err := prd.LoadPRD(prdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.UserStories) != 4 {
+		t.Fatalf("expected 4 stories, got %d", len(p.UserStories))
+	}
+	story := p.UserStories[2]
+	if story.Title != "Address review comment on auth.go:42" || story.Passes || story.Priority != 3 {
+		t.Errorf("unexpected story: %+v", story)
+	}
+	if !strings.Contains(story.Description, `@ana commented on auth.go:42: "Handle the nil case"`) {
+		t.Errorf("expected the comment in the description, got %q", story.Description)
+	}
+	if p.UserStories[3].Title != "Address review comment on the pull request" {
+		t.Errorf("unexpected review story title %q", p.UserStories[3].Title)
+	}
+}
+
+func TestRunWatchPR_RecordsSeenComments(t *testing.T) {
+	dir := t.TempDir()
+	for _, args := range [][]string{{"init", "-b", "chief/auth"}, {"commit", "--allow-empty", "-m", "init"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	prdDir := filepath.Join(dir, ".chief", "prds", "auth")
+	if err := os.MkdirAll(prdDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(prdDir, "prd.md"), []byte("# Auth\n\n### US-001: Login\n**Status:** done\n- [x] Works\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	origFind, origComments := findPullRequest, reviewComments
+	t.Cleanup(func() { findPullRequest, reviewComments = origFind, origComments })
+	findPullRequest = func(dir, head string) (*forge.PullRequest, error) {
+		if head != "chief/auth" {
+			t.Errorf("expected the PRD branch, got %q", head)
+		}
+		return &forge.PullRequest{Number: 7, State: "OPEN"}, nil
+	}
+	reviewComments = func(dir string, number int) ([]forge.ReviewComment, error) {
+		return []forge.ReviewComment{{ID: 11, Kind: forge.KindComment, Body: "LGTM"}}, nil
+	}
+
+	err := RunWatchPR(WatchPROptions{Name: "auth", BaseDir: dir, Once: true, Provider: &selftestProvider{command: []string{"true"}}})
+	if err != nil {
+		t.Fatalf("RunWatchPR() error = %v", err)
+	}
+	if seen := loadSeenComments(filepath.Join(prdDir, SeenCommentsFile)); !seen["comment-11"] {
+		t.Errorf("expected comment-11 to be recorded, got %v", seen)
+	}
+	if p, _ := prd.LoadPRD(filepath.Join(prdDir, "prd.md")); len(p.UserStories) != 1 {
+		t.Errorf("expected no stories for an acknowledgement, got %d", len(p.UserStories))
+	}
+}
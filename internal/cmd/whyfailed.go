@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/minicodemonkey/chief/internal/git"
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/review"
+)
+
+// WhyFailedOptions contains configuration for the why-failed command.
+type WhyFailedOptions struct {
+	StoryID  string        // Story to analyze, e.g. "US-031"
+	Name     string        // PRD name (default: the PRD containing the story)
+	BaseDir  string        // Base directory for .chief/prds/ (default: current directory)
+	Provider loop.Provider // Agent CLI provider
+}
+
+// RunWhyFailed asks the agent, read-only, why a story keeps failing and
+// prints its root-cause analysis and suggested rewrite of the story.
+func RunWhyFailed(opts WhyFailedOptions) error {
+	if opts.StoryID == "" {
+		return fmt.Errorf("missing story ID")
+	}
+	if opts.BaseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		opts.BaseDir = cwd
+	}
+	if opts.Provider == nil {
+		return fmt.Errorf("why-failed command requires Provider to be set")
+	}
+
+	name, story, err := findStory(opts.BaseDir, opts.Name, opts.StoryID)
+	if err != nil {
+		return err
+	}
+	prdPath := filepath.Join(opts.BaseDir, ".chief", "prds", name, "prd.md")
+
+	// Read the PRD's worktree when it ran in one
+	workDir := opts.BaseDir
+	if wt := git.WorktreePathForPRD(opts.BaseDir, name); git.IsWorktree(wt) {
+		workDir = wt
+	}
+
+	fmt.Printf("Analyzing why %s from %s failed with %s...\n", story.ID, name, opts.Provider.Name())
+	analysis, err := review.WhyFailed(context.Background(), review.WhyFailedOptions{
+		Provider: opts.Provider,
+		PRDPath:  prdPath,
+		Story:    *story,
+		WorkDir:  workDir,
+		Output:   os.Stdout,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%s\n", strings.TrimSpace(analysis))
+	fmt.Printf("\nSaved to %s\n", review.AnalysisPath(prdPath, story.ID))
+	return nil
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -11,15 +15,197 @@ const configFile = ".chief/config.yaml"
 
 // Config holds project-level settings for Chief.
 type Config struct {
-	Worktree   WorktreeConfig   `yaml:"worktree"`
-	OnComplete OnCompleteConfig `yaml:"onComplete"`
-	Agent      AgentConfig      `yaml:"agent"`
+	ConfigFrom    string           `yaml:"configFrom,omitempty"`    // https:// URL or git+<repository>#<path> of organization settings merged below this file
+	ConfigFromKey string           `yaml:"configFromKey,omitempty"` // Base64 ed25519 public key the organization settings must be signed with ("" = not verified)
+	Language      string           `yaml:"language,omitempty"`      // Language for PRD prose, progress notes and commit messages ("" = English)
+	Worktree      WorktreeConfig   `yaml:"worktree"`
+	OnComplete    OnCompleteConfig `yaml:"onComplete"`
+	Agent         AgentConfig      `yaml:"agent"`
+	Workspace     WorkspaceConfig  `yaml:"workspace,omitempty"`
+	Loop          LoopConfig       `yaml:"loop,omitempty"`
+	Commits       CommitsConfig    `yaml:"commits,omitempty"`
+	Review        ReviewConfig     `yaml:"review,omitempty"`
+	Coverage      CoverageConfig   `yaml:"coverage,omitempty"`
+	Verify        VerifyConfig     `yaml:"verify,omitempty"`
+	Done          []DoneCriterion  `yaml:"done,omitempty"` // Definition of done added to every story's acceptance criteria
+	Similar       SimilarConfig    `yaml:"similar,omitempty"`
+	Forge         ForgeConfig      `yaml:"forge,omitempty"`
+	Schedule      ScheduleConfig   `yaml:"schedule,omitempty"`
+	Resources     map[string]int   `yaml:"resources,omitempty"` // Verification runs that may hold each resource tag at once on this machine (default 1)
+	Cache         CacheConfig      `yaml:"cache,omitempty"`
+	Prompt        PromptConfig     `yaml:"prompt,omitempty"`
+	Network       NetworkConfig    `yaml:"network,omitempty"`
+	Push          PushConfig       `yaml:"push,omitempty"`
+	Display       DisplayConfig    `yaml:"display,omitempty"`
+	Retention     RetentionConfig  `yaml:"retention,omitempty"`
+	Profiles      RunProfiles      `yaml:"profiles,omitempty"` // Named sets of chief run flags, picked with --profile
+
+	org *Config // Organization settings merged below this file, kept out of it on Save
+}
+
+// RunProfiles maps profile names to their flags.
+type RunProfiles map[string]RunProfile
+
+// RunProfile is a named set of `chief run` flags. Flags given on the command
+// line take precedence; labels are added to the profile's.
+type RunProfile struct {
+	Agent            string   `yaml:"agent,omitempty"`            // --agent
+	Model            string   `yaml:"model,omitempty"`            // --model
+	Parallel         int      `yaml:"parallel,omitempty"`         // --parallel
+	MaxIterations    int      `yaml:"maxIterations,omitempty"`    // --max-iterations
+	Base             string   `yaml:"base,omitempty"`             // --base
+	Labels           []string `yaml:"labels,omitempty"`           // --label, once per label
+	JSON             bool     `yaml:"json,omitempty"`             // --json
+	Verbose          bool     `yaml:"verbose,omitempty"`          // --verbose
+	NoRetry          bool     `yaml:"noRetry,omitempty"`          // --no-retry
+	IgnoreQuietHours bool     `yaml:"ignoreQuietHours,omitempty"` // --ignore-quiet-hours
+}
+
+// Profile returns the run profile called name.
+func (c *Config) Profile(name string) (RunProfile, error) {
+	if profile, ok := c.Profiles[name]; ok {
+		return profile, nil
+	}
+	if len(c.Profiles) == 0 {
+		return RunProfile{}, fmt.Errorf("unknown profile %q: no profiles are configured", name)
+	}
+	names := make([]string, 0, len(c.Profiles))
+	for n := range c.Profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return RunProfile{}, fmt.Errorf("unknown profile %q: expected one of %s", name, strings.Join(names, ", "))
+}
+
+// RetentionConfig holds how long run data is kept. Older data is removed
+// when Chief starts; zero keeps it forever.
+type RetentionConfig struct {
+	LogDays        int `yaml:"logDays,omitempty"`        // Agent logs, crash reports and verification output not written to for this many days
+	HistoryDays    int `yaml:"historyDays,omitempty"`    // Undo snapshots taken before destructive commands
+	TranscriptDays int `yaml:"transcriptDays,omitempty"` // Runs in each PRD's events.jsonl
+	UsageDays      int `yaml:"usageDays,omitempty"`      // Runs in each PRD's usage.json
+}
+
+// DisplayConfig holds how times are shown in the TUI, commands and reports.
+type DisplayConfig struct {
+	Timezone string `yaml:"timezone,omitempty"` // IANA timezone, e.g. Europe/Berlin ("" = TZ or the system's)
+	Clock    string `yaml:"clock,omitempty"`    // "24h" (default) | "12h"
+}
+
+// PushConfig limits how often the agent's pushes reach the remote. Either
+// limit also refuses the agent's force pushes.
+type PushConfig struct {
+	EveryMinutes int  `yaml:"everyMinutes,omitempty"` // Minimum time between pushes (0 = no limit)
+	PerStory     bool `yaml:"perStory,omitempty"`     // Hold pushes until the story is done, then push once
+}
+
+// NetworkConfig holds the network access allowed to the agent.
+type NetworkConfig struct {
+	Policy     string   `yaml:"policy,omitempty"`     // "open" (default) | "restricted" | "offline"
+	Allow      []string `yaml:"allow,omitempty"`      // Extra hosts the restricted policy allows, e.g. registry.npmjs.org or *.internal.example.com
+	CABundle   string   `yaml:"caBundle,omitempty"`   // PEM file of extra CA certificates to trust, e.g. a corporate proxy's
+	ClientCert string   `yaml:"clientCert,omitempty"` // PEM client certificate for servers and gateways that require mutual TLS
+	ClientKey  string   `yaml:"clientKey,omitempty"`  // PEM private key of clientCert
+}
+
+// PromptConfig holds settings for assembling the agent prompt.
+type PromptConfig struct {
+	Middlewares []string `yaml:"middlewares,omitempty"` // Middlewares the prompt passes through, in order (empty = [language])
+}
+
+// CacheConfig holds settings for reusing the results of expensive commands.
+type CacheConfig struct {
+	Commands      []string `yaml:"commands,omitempty"`      // Command patterns whose passing result is reused while no files change ("*" matches anything)
+	MaxAgeMinutes int      `yaml:"maxAgeMinutes,omitempty"` // Age after which a cached result is run again (0 = no limit)
+}
+
+// ScheduleConfig holds settings for when runs may work.
+type ScheduleConfig struct {
+	QuietHours []string `yaml:"quietHours,omitempty"` // Times no new iteration starts, e.g. "09:00-18:00 weekdays"
+}
+
+// ForgeConfig holds settings for reporting to the code host (GitHub via gh).
+type ForgeConfig struct {
+	CommitStatus bool `yaml:"commitStatus,omitempty"` // Post run progress as a commit status on the pushed PRD branch
+	StackedPRs   bool `yaml:"stackedPRs,omitempty"`   // Open a PR per finished story, each stacked on the previous one
+	FileIssues   bool `yaml:"fileIssues,omitempty"`   // Open an issue with the failure dossier when repeated crashes pause a run
+}
+
+// SimilarConfig holds settings for finding similar stories across PRDs.
+type SimilarConfig struct {
+	Provider  string  `yaml:"provider,omitempty"`  // "local" (default) | "command" | "off"
+	Command   string  `yaml:"command,omitempty"`   // Embedding command for the "command" provider
+	Threshold float64 `yaml:"threshold,omitempty"` // Similarity that counts as a likely duplicate (0 = default)
+}
+
+// VerifyConfig holds the check a story must pass before it is marked done.
+type VerifyConfig struct {
+	Command   string        `yaml:"command,omitempty"`   // Shell command that must exit 0 ("" = detect from project files, "none" = off)
+	GateFlaky bool          `yaml:"gateFlaky,omitempty"` // Keep failing stories on tests known to be flaky
+	Browser   BrowserConfig `yaml:"browser,omitempty"`
+	Resources []string      `yaml:"resources,omitempty"` // Resource tags verification uses, e.g. gpu, integration-db
+	Ignore    []string      `yaml:"ignore,omitempty"`    // Paths whose changes never need verifying, e.g. docs/, *.md
+}
+
+// DoneCriterion is one item of the project's definition of done.
+type DoneCriterion struct {
+	Text    string `yaml:"text"`              // Criterion as the agent sees it, e.g. "Lint passes"
+	Command string `yaml:"command,omitempty"` // Shell command that must exit 0 before a story is marked done ("" = left to the agent)
+}
+
+// BrowserConfig holds the browser tests run for stories that have a URL.
+type BrowserConfig struct {
+	Command string `yaml:"command,omitempty"` // Playwright command ("" = detect from playwright.config.*, "none" = off)
+}
+
+// CoverageConfig holds settings for tracking test coverage per story.
+type CoverageConfig struct {
+	Command string  `yaml:"command,omitempty"` // Shell command that prints a coverage percentage ("" = off)
+	MaxDrop float64 `yaml:"maxDrop,omitempty"` // Fail stories that lower coverage by more than this many points (0 = never)
+}
+
+// ReviewConfig holds settings for agent review passes.
+type ReviewConfig struct {
+	BlockPR bool `yaml:"blockPR,omitempty"` // Run a security review before creating a PR and skip the PR on high-severity findings
+}
+
+// CommitsConfig holds the rules agent commit messages are checked against.
+type CommitsConfig struct {
+	Validate         bool     `yaml:"validate,omitempty"`         // Check agent commits after each iteration
+	MaxSubjectLength int      `yaml:"maxSubjectLength,omitempty"` // Subject line limit (default 72)
+	Prefixes         []string `yaml:"prefixes,omitempty"`         // Allowed prefixes, e.g. feat, fix (empty = any)
+	RequireStoryID   bool     `yaml:"requireStoryID,omitempty"`   // Subject must mention the current story ID
+	Squash           bool     `yaml:"squash,omitempty"`           // Squash each finished story into a single commit
+	SquashMessage    string   `yaml:"squashMessage,omitempty"`    // Subject template for squashed stories
+	Trailers         bool     `yaml:"trailers,omitempty"`         // Add Chief-Story and Chief-Run trailers to agent commits
+}
+
+// LoopConfig holds agent loop tuning.
+type LoopConfig struct {
+	StallMinutes        int    `yaml:"stallMinutes,omitempty"`        // Silence before a run is reported as stalled (0 = default, -1 = off)
+	WatchdogMinutes     int    `yaml:"watchdogMinutes,omitempty"`     // Silence before a hung agent is killed and retried (0 = default, -1 = never kill)
+	StoryTimeoutMinutes int    `yaml:"storyTimeoutMinutes,omitempty"` // Time budget per story before the agent is asked to wrap up (0 = unlimited)
+	StopGraceSeconds    int    `yaml:"stopGraceSeconds,omitempty"`    // Time a stopped agent gets to exit before it is killed (0 = default, -1 = kill immediately)
+	CrashLimit          int    `yaml:"crashLimit,omitempty"`          // Agent crashes within 30 minutes that pause the run (0 = default, -1 = never pause)
+	StoryRetries        int    `yaml:"storyRetries,omitempty"`        // Failed iterations before a story is marked needs-review and skipped (0 = default, -1 = never skip)
+	DirtyWorktree       string `yaml:"dirtyWorktree,omitempty"`       // Uncommitted changes at run start: "abort" (default) | "stash" (restored when the run ends) | "include"
+	SummaryEvery        int    `yaml:"summaryEvery,omitempty"`        // Completed stories between updates of the summary that replaces progress.md history in prompts (0 = off)
+	ExploreTurns        int    `yaml:"exploreTurns,omitempty"`        // Tool calls for a read-only exploration pass before each story, kept for retries (0 = off)
+	RemoteSync          string `yaml:"remoteSync,omitempty"`          // Branch behind its remote at run start: "off" (default) | "pause" | "rebase"
+}
+
+// WorkspaceConfig holds settings read from the workspace root's config.
+// Entries are glob patterns matched against project directory names.
+type WorkspaceConfig struct {
+	Pin  []string `yaml:"pin,omitempty"`  // Projects listed first, in pattern order
+	Hide []string `yaml:"hide,omitempty"` // Projects excluded from the project list
 }
 
 // AgentConfig holds agent CLI settings (Claude, Codex, OpenCode, or Cursor).
 type AgentConfig struct {
-	Provider string `yaml:"provider"` // "claude" (default) | "codex" | "opencode" | "cursor"
-	CLIPath  string `yaml:"cliPath"`  // optional custom path to CLI binary
+	Provider string `yaml:"provider"`        // "claude" (default) | "codex" | "opencode" | "cursor"
+	CLIPath  string `yaml:"cliPath"`         // optional custom path to CLI binary
+	Model    string `yaml:"model,omitempty"` // Model the agent is run with (--model), e.g. for local models ("" = the agent's default)
 }
 
 // WorktreeConfig holds worktree-related settings.
@@ -29,8 +215,9 @@ type WorktreeConfig struct {
 
 // OnCompleteConfig holds post-completion automation settings.
 type OnCompleteConfig struct {
-	Push     bool `yaml:"push"`
-	CreatePR bool `yaml:"createPR"`
+	Push       bool   `yaml:"push"`
+	CreatePR   bool   `yaml:"createPR"`
+	PRTemplate string `yaml:"prTemplate,omitempty"` // Path to a PR body template ("" = built-in)
 }
 
 // Default returns a Config with zero-value defaults.
@@ -49,7 +236,8 @@ func Exists(baseDir string) bool {
 	return err == nil
 }
 
-// Load reads the config from .chief/config.yaml.
+// Load reads the config from .chief/config.yaml, merged over the
+// organization settings its configFrom points to, if any.
 // Returns Default() when the file doesn't exist (no error).
 func Load(baseDir string) (*Config, error) {
 	path := configPath(baseDir)
@@ -66,8 +254,29 @@ func Load(baseDir string) (*Config, error) {
 	if err := yaml.Unmarshal(data, cfg); err != nil {
 		return nil, err
 	}
+	if cfg.ConfigFrom == "" {
+		return cfg, nil
+	}
 
-	return cfg, nil
+	// Decode the organization settings, then this file's over them: keys set
+	// here win, maps are merged, and everything else is inherited.
+	orgData, err := loadOrgConfig(cfg.ConfigFrom, cfg.ConfigFromKey, cfg.Network, baseDir)
+	if err != nil {
+		return nil, err
+	}
+	org := Default()
+	if err := yaml.Unmarshal(orgData, org); err != nil {
+		return nil, fmt.Errorf("invalid organization settings from %s: %w", cfg.ConfigFrom, err)
+	}
+	org.ConfigFrom, org.ConfigFromKey = "", ""
+	merged := Default()
+	yaml.Unmarshal(orgData, merged)
+	merged.ConfigFrom, merged.ConfigFromKey = "", ""
+	if err := yaml.Unmarshal(data, merged); err != nil {
+		return nil, err
+	}
+	merged.org = org
+	return merged, nil
 }
 
 // Save writes the config to .chief/config.yaml.
@@ -79,10 +288,119 @@ func Save(baseDir string, cfg *Config) error {
 		return err
 	}
 
-	data, err := yaml.Marshal(cfg)
+	data, err := marshalLocal(cfg)
 	if err != nil {
 		return err
 	}
 
 	return os.WriteFile(path, data, 0o644)
 }
+
+// marshalLocal marshals the settings of cfg that aren't inherited from its
+// organization settings. A setting turned off here but on in the
+// organization's is written out explicitly, so it stays off.
+func marshalLocal(cfg *Config) ([]byte, error) {
+	if cfg.org == nil {
+		return yaml.Marshal(cfg)
+	}
+	inherited := make(map[string]reflect.Value)
+	walkSettings(reflect.ValueOf(cfg.org).Elem(), "", func(key string, v reflect.Value) {
+		inherited[key] = v
+	})
+
+	local := *cfg
+	local.org = nil
+	var inheritedKeys, offKeys []string
+	walkSettings(reflect.ValueOf(&local).Elem(), "", func(key string, v reflect.Value) {
+		org, ok := inherited[key]
+		switch {
+		case !ok || org.IsZero():
+		case reflect.DeepEqual(v.Interface(), org.Interface()):
+			inheritedKeys = append(inheritedKeys, key)
+		case v.IsZero():
+			offKeys = append(offKeys, key)
+		}
+	})
+
+	var doc yaml.Node
+	if err := doc.Encode(&local); err != nil {
+		return nil, err
+	}
+	for _, key := range inheritedKeys {
+		deleteNodeKey(&doc, strings.Split(key, "."))
+	}
+	for _, key := range offKeys {
+		v, _ := lookupSetting(&local, key)
+		var value yaml.Node
+		if err := value.Encode(v.Interface()); err != nil {
+			return nil, err
+		}
+		setNodeKey(&doc, strings.Split(key, "."), &value)
+	}
+	return yaml.Marshal(&doc)
+}
+
+// deleteNodeKey removes the key at a key path from a YAML mapping, along with
+// mappings left empty.
+func deleteNodeKey(m *yaml.Node, path []string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value != path[0] {
+			continue
+		}
+		if len(path) > 1 {
+			deleteNodeKey(m.Content[i+1], path[1:])
+			if len(m.Content[i+1].Content) > 0 {
+				return
+			}
+		}
+		m.Content = append(m.Content[:i], m.Content[i+2:]...)
+		return
+	}
+}
+
+// setNodeKey sets the value at a key path in a YAML mapping, adding the
+// mappings along the way.
+func setNodeKey(m *yaml.Node, path []string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			m.Content[i+1] = value
+		} else {
+			setNodeKey(m.Content[i+1], path[1:], value)
+		}
+		return
+	}
+	next := value
+	if len(path) > 1 {
+		next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		setNodeKey(next, path[1:], value)
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: path[0]}, next)
+}
+
+// prdConfigFile holds per-PRD overrides, relative to the PRD directory.
+const prdConfigFile = "config.yaml"
+
+// PRDConfig holds settings that a single PRD can override.
+type PRDConfig struct {
+	Verify VerifyConfig `yaml:"verify,omitempty"`
+}
+
+// LoadPRD reads the overrides in a PRD directory's config.yaml.
+// Returns an empty PRDConfig when the file doesn't exist (no error).
+func LoadPRD(prdDir string) (*PRDConfig, error) {
+	cfg := &PRDConfig{}
+	data, err := os.ReadFile(filepath.Join(prdDir, prdConfigFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
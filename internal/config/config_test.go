@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -82,3 +83,53 @@ func TestExists(t *testing.T) {
 		t.Error("expected Exists to return true for existing config")
 	}
 }
+
+func TestLoadPRD(t *testing.T) {
+	prdDir := t.TempDir()
+
+	cfg, err := LoadPRD(prdDir)
+	if err != nil {
+		t.Fatalf("LoadPRD() without a config.yaml: %v", err)
+	}
+	if cfg.Verify.Command != "" {
+		t.Errorf("expected empty override, got %q", cfg.Verify.Command)
+	}
+
+	if err := os.WriteFile(filepath.Join(prdDir, "config.yaml"), []byte("verify:\n  command: npm test\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = LoadPRD(prdDir)
+	if err != nil {
+		t.Fatalf("LoadPRD() failed: %v", err)
+	}
+	if cfg.Verify.Command != "npm test" {
+		t.Errorf("expected verify.command npm test, got %q", cfg.Verify.Command)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".chief"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	yaml := "profiles:\n  overnight:\n    parallel: 3\n    model: opus\n    labels: [overnight]\n  ci:\n    json: true\n"
+	if err := os.WriteFile(filepath.Join(dir, ".chief", "config.yaml"), []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := cfg.Profile("overnight")
+	if err != nil {
+		t.Fatalf("Profile() error = %v", err)
+	}
+	if profile.Parallel != 3 || profile.Model != "opus" || len(profile.Labels) != 1 {
+		t.Errorf("Profile() = %+v", profile)
+	}
+
+	if _, err := cfg.Profile("weekend"); err == nil || !strings.Contains(err.Error(), "ci, overnight") {
+		t.Errorf("Profile() of an unknown name = %v, want the configured names listed", err)
+	}
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// resolvePath resolves a configured file against the project root.
+func resolvePath(path, baseDir string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// CABundlePath returns the configured CA bundle's path, resolved against the
+// project root. It returns "" when none is set.
+func (n NetworkConfig) CABundlePath(baseDir string) string {
+	return resolvePath(n.CABundle, baseDir)
+}
+
+// CertPool returns the system's trusted roots plus the certificates in the
+// configured CA bundle, or nil when no bundle is set.
+func (n NetworkConfig) CertPool(baseDir string) (*x509.CertPool, error) {
+	path := n.CABundlePath(baseDir)
+	if path == "" {
+		return nil, nil
+	}
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("network.caBundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("network.caBundle: %s has no PEM certificates", path)
+	}
+	return pool, nil
+}
+
+// ClientCertificate returns the configured client certificate for mutual
+// TLS, or nil when none is set.
+func (n NetworkConfig) ClientCertificate(baseDir string) (*tls.Certificate, error) {
+	if n.ClientCert == "" && n.ClientKey == "" {
+		return nil, nil
+	}
+	if n.ClientCert == "" || n.ClientKey == "" {
+		return nil, fmt.Errorf("network.clientCert and network.clientKey must be set together")
+	}
+	cert, err := tls.LoadX509KeyPair(resolvePath(n.ClientCert, baseDir), resolvePath(n.ClientKey, baseDir))
+	if err != nil {
+		return nil, fmt.Errorf("network.clientCert: %w", err)
+	}
+	return &cert, nil
+}
+
+// TLSConfig returns the TLS settings for Chief's own connections: the
+// configured CA bundle and client certificate. It returns nil when neither
+// is set, meaning the defaults.
+func (n NetworkConfig) TLSConfig(baseDir string) (*tls.Config, error) {
+	pool, err := n.CertPool(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := n.ClientCertificate(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	if pool == nil && cert == nil {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{RootCAs: pool}
+	if cert != nil {
+		tlsConfig.Certificates = []tls.Certificate{*cert}
+	}
+	return tlsConfig, nil
+}
+
+// HTTPClient returns a client for Chief's own requests. It goes through the
+// proxy named by HTTPS_PROXY or HTTP_PROXY, except for hosts in NO_PROXY,
+// trusts the configured CA bundle, and presents the configured client
+// certificate to servers that ask for one.
+func (n NetworkConfig) HTTPClient(baseDir string) (*http.Client, error) {
+	tlsConfig, err := n.TLSConfig(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	return &http.Client{Transport: transport}, nil
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// OrgCacheTTL is how long fetched organization settings are used before
+// they are fetched again.
+const OrgCacheTTL = time.Hour
+
+// orgFetchTimeout bounds fetching organization settings over HTTPS.
+const orgFetchTimeout = 30 * time.Second
+
+// Organization settings operations (replaceable in tests).
+var (
+	fetchOrgConfig = fetchOrgSource
+	orgCacheDir    = defaultOrgCacheDir
+)
+
+// defaultOrgCacheDir returns where fetched organization settings are cached,
+// shared by every project on the machine.
+func defaultOrgCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "chief", "org-config"), nil
+}
+
+// loadOrgConfig returns the organization settings configFrom points to. They
+// are fetched at most once per OrgCacheTTL; when fetching fails, the last
+// copy fetched is used. With a key, the settings must carry a valid
+// signature, checked every time they are read.
+func loadOrgConfig(source, key string, network NetworkConfig, baseDir string) ([]byte, error) {
+	if err := validateOrgSource(source); err != nil {
+		return nil, err
+	}
+	var pub ed25519.PublicKey
+	if key != "" {
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("configFromKey must be a base64 ed25519 public key")
+		}
+		pub = raw
+	}
+
+	cached, sum := "", sha256.Sum256([]byte(source))
+	if dir, err := orgCacheDir(); err == nil {
+		cached = filepath.Join(dir, hex.EncodeToString(sum[:8])+".yaml")
+	}
+	if cached != "" {
+		if info, err := os.Stat(cached); err == nil && time.Since(info.ModTime()) < OrgCacheTTL {
+			if data, sig, err := readOrgCache(cached); err == nil {
+				return data, verifyOrgConfig(source, data, sig, pub)
+			}
+		}
+	}
+
+	data, sig, err := fetchOrgConfig(source, network, baseDir)
+	if err != nil {
+		if cached != "" {
+			if data, sig, cacheErr := readOrgCache(cached); cacheErr == nil {
+				return data, verifyOrgConfig(source, data, sig, pub)
+			}
+		}
+		return nil, fmt.Errorf("failed to fetch organization settings from %s: %w", source, err)
+	}
+	if err := verifyOrgConfig(source, data, sig, pub); err != nil {
+		return nil, err
+	}
+	if cached != "" {
+		writeOrgCache(cached, data, sig)
+	}
+	return data, nil
+}
+
+// verifyOrgConfig checks the signature of organization settings against the
+// configured key. Without a key the settings are used as fetched.
+func verifyOrgConfig(source string, data, sig []byte, pub ed25519.PublicKey) error {
+	if pub == nil {
+		return nil
+	}
+	if len(sig) == 0 {
+		return fmt.Errorf("organization settings from %s are not signed; configFromKey requires a signature next to them (.sig)", source)
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil || !ed25519.Verify(pub, data, raw) {
+		return fmt.Errorf("organization settings from %s do not match their signature; refusing to use them", source)
+	}
+	return nil
+}
+
+// readOrgCache reads cached settings and their signature, if any.
+func readOrgCache(path string) ([]byte, []byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	sig, _ := os.ReadFile(path + ".sig")
+	return data, sig, nil
+}
+
+// writeOrgCache caches fetched settings. Failing to cache only means they are
+// fetched again next time.
+func writeOrgCache(path string, data, sig []byte) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return
+	}
+	if len(sig) > 0 {
+		os.WriteFile(path+".sig", sig, 0o644)
+	} else {
+		os.Remove(path + ".sig")
+	}
+}
+
+// fetchOrgSource fetches organization settings and their signature (the same
+// path with .sig appended, if present) from an https:// URL or from a git
+// repository given as git+<repository>#<path>. HTTPS requests use the client
+// of Chief's own requests under network, so they honor its proxy, CA bundle,
+// and client certificate settings.
+func fetchOrgSource(source string, network NetworkConfig, baseDir string) ([]byte, []byte, error) {
+	if err := validateOrgSource(source); err != nil {
+		return nil, nil, err
+	}
+	if repo, ok := strings.CutPrefix(source, "git+"); ok {
+		repo, path, _ := strings.Cut(repo, "#")
+		return fetchOrgGit(repo, path)
+	}
+
+	client, err := network.HTTPClient(baseDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	client.Timeout = orgFetchTimeout
+	data, err := httpGetOrg(client, source)
+	if err != nil {
+		return nil, nil, err
+	}
+	if data == nil {
+		return nil, nil, fmt.Errorf("not found")
+	}
+	sig, err := httpGetOrg(client, source+".sig")
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, sig, nil
+}
+
+// httpGetOrg downloads url, returning nil when it doesn't exist.
+func httpGetOrg(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// validateOrgSource checks that configFrom is an https:// URL or a git
+// repository with a path. A repository starting with "-" is refused, since git
+// would read it as an option.
+func validateOrgSource(source string) error {
+	if repo, ok := strings.CutPrefix(source, "git+"); ok {
+		repo, path, found := strings.Cut(repo, "#")
+		if !found || path == "" {
+			return fmt.Errorf("a git configFrom needs the file's path, e.g. git+https://github.com/acme/platform.git#chief/chief-org.yaml")
+		}
+		if repo == "" || strings.HasPrefix(repo, "-") {
+			return fmt.Errorf("configFrom repository %q is not a git repository", repo)
+		}
+		return nil
+	}
+	if !strings.HasPrefix(source, "https://") {
+		return fmt.Errorf("configFrom must be an https:// URL or a git repository (git+<repository>#<path>)")
+	}
+	return nil
+}
+
+// fetchOrgGit reads a file and its signature from a shallow clone of repo.
+func fetchOrgGit(repo, path string) ([]byte, []byte, error) {
+	dir, err := os.MkdirTemp("", "chief-org-config-")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	cmd := exec.Command("git", "clone", "--depth", "1", "--quiet", "--", repo, dir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, nil, fmt.Errorf("git clone failed: %s", strings.TrimSpace(string(out)))
+	}
+	file := filepath.Join(dir, filepath.FromSlash(path))
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s not found in %s", path, repo)
+	}
+	sig, _ := os.ReadFile(file + ".sig")
+	return data, sig, nil
+}
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testOrgSettings = `onComplete:
+  push: true
+  createPR: true
+commits:
+  validate: true
+  prefixes: [feat, fix]
+resources:
+  gpu: 1
+network:
+  policy: restricted
+`
+
+// fakeOrgSource serves organization settings from memory, counting fetches.
+func fakeOrgSource(t *testing.T, data, sig string) *int {
+	t.Helper()
+	fetches := 0
+	cacheDir := t.TempDir()
+	origFetch, origCache := fetchOrgConfig, orgCacheDir
+	fetchOrgConfig = func(string, NetworkConfig, string) ([]byte, []byte, error) {
+		fetches++
+		if data == "" {
+			return nil, nil, fmt.Errorf("connection refused")
+		}
+		return []byte(data), []byte(sig), nil
+	}
+	orgCacheDir = func() (string, error) { return cacheDir, nil }
+	t.Cleanup(func() { fetchOrgConfig, orgCacheDir = origFetch, origCache })
+	return &fetches
+}
+
+func writeLocalConfig(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".chief"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".chief", "config.yaml"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestLoad_OrgConfig(t *testing.T) {
+	fetches := fakeOrgSource(t, testOrgSettings, "")
+	dir := writeLocalConfig(t, `configFrom: https://example.com/chief-org.yaml
+onComplete:
+  createPR: false
+resources:
+  integration-db: 2
+network:
+  policy: open
+`)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.OnComplete.Push || !cfg.Commits.Validate || len(cfg.Commits.Prefixes) != 2 {
+		t.Errorf("expected the organization settings to be inherited, got %+v", cfg)
+	}
+	if cfg.OnComplete.CreatePR || cfg.Network.Policy != "open" {
+		t.Errorf("expected local settings to win, got createPR=%v policy=%q", cfg.OnComplete.CreatePR, cfg.Network.Policy)
+	}
+	if cfg.Resources["gpu"] != 1 || cfg.Resources["integration-db"] != 2 {
+		t.Errorf("expected resources to be merged, got %v", cfg.Resources)
+	}
+
+	// Cached for an hour
+	if _, err := Load(dir); err != nil {
+		t.Fatal(err)
+	}
+	if *fetches != 1 {
+		t.Errorf("expected 1 fetch, got %d", *fetches)
+	}
+}
+
+func TestSave_OrgConfig(t *testing.T) {
+	fakeOrgSource(t, testOrgSettings, "")
+	dir := writeLocalConfig(t, "configFrom: https://example.com/chief-org.yaml\n")
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Worktree.Setup = "npm ci"
+	cfg.Commits.Validate = false
+	if err := Save(dir, cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ".chief", "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := string(data)
+	for _, inherited := range []string{"prefixes", "restricted", "gpu", "createPR: true"} {
+		if strings.Contains(saved, inherited) {
+			t.Errorf("expected %q to stay in the organization settings, got:\n%s", inherited, saved)
+		}
+	}
+	for _, want := range []string{"configFrom: https://example.com/chief-org.yaml", "setup: npm ci", "validate: false"} {
+		if !strings.Contains(saved, want) {
+			t.Errorf("expected %q in the saved config, got:\n%s", want, saved)
+		}
+	}
+
+	reloaded, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Commits.Validate || !reloaded.OnComplete.CreatePR || reloaded.Worktree.Setup != "npm ci" {
+		t.Errorf("unexpected config after saving: %+v", reloaded)
+	}
+}
+
+func TestLoad_OrgConfigSignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := base64.StdEncoding.EncodeToString(pub)
+	sign := func(data string) string {
+		return base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(data)))
+	}
+	local := fmt.Sprintf("configFrom: https://example.com/chief-org.yaml\nconfigFromKey: %s\n", key)
+
+	tests := []struct {
+		name    string
+		sig     string
+		wantErr string
+	}{
+		{"valid", sign(testOrgSettings), ""},
+		{"unsigned", "", "not signed"},
+		{"tampered", sign("onComplete:\n  push: false\n"), "do not match"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeOrgSource(t, testOrgSettings, tt.sig)
+			cfg, err := Load(writeLocalConfig(t, local))
+			if tt.wantErr == "" {
+				if err != nil || !cfg.OnComplete.Push {
+					t.Errorf("Load() = %+v, %v; want the signed settings", cfg, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_OrgConfigUnreachable(t *testing.T) {
+	fakeOrgSource(t, "", "")
+	dir := writeLocalConfig(t, "configFrom: https://example.com/chief-org.yaml\n")
+	if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "failed to fetch organization settings") {
+		t.Errorf("expected a fetch error without a cached copy, got %v", err)
+	}
+
+	// A stale cached copy is used when fetching fails
+	cacheDir, _ := orgCacheDir()
+	entries, _ := os.ReadDir(cacheDir)
+	if len(entries) != 0 {
+		t.Fatalf("expected nothing cached, got %d files", len(entries))
+	}
+	fakeOrgSource(t, testOrgSettings, "")
+	if _, err := Load(dir); err != nil {
+		t.Fatal(err)
+	}
+	cacheDir, _ = orgCacheDir()
+	entries, _ = os.ReadDir(cacheDir)
+	if len(entries) != 1 {
+		t.Fatalf("expected the settings to be cached, got %d files", len(entries))
+	}
+	stale, staleTime := filepath.Join(cacheDir, entries[0].Name()), time.Now().Add(-2*OrgCacheTTL)
+	if err := os.Chtimes(stale, staleTime, staleTime); err != nil {
+		t.Fatal(err)
+	}
+	fetchOrgConfig = func(string, NetworkConfig, string) ([]byte, []byte, error) {
+		return nil, nil, fmt.Errorf("connection refused")
+	}
+	cfg, err := Load(dir)
+	if err != nil || !cfg.OnComplete.Push {
+		t.Errorf("expected the stale copy to be used, got %+v, %v", cfg, err)
+	}
+}
+
+func TestFetchOrgSource_Invalid(t *testing.T) {
+	for _, source := range []string{
+		"http://example.com/chief-org.yaml",
+		"git+https://github.com/acme/platform.git",
+		"git+--upload-pack=touch pwned#chief-org.yaml",
+		"git+#chief-org.yaml",
+	} {
+		if _, _, err := fetchOrgSource(source, NetworkConfig{}, ""); err == nil {
+			t.Errorf("fetchOrgSource(%q) expected an error", source)
+		}
+		if _, err := loadOrgConfig(source, "", NetworkConfig{}, ""); err == nil {
+			t.Errorf("loadOrgConfig(%q) expected an error", source)
+		}
+	}
+}
+
+func TestFetchOrgSource_UsesNetworkSettings(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chief-org.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.WriteString(w, testOrgSettings)
+	}))
+	defer server.Close()
+
+	baseDir := t.TempDir()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(filepath.Join(baseDir, "ca.pem"), ca, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := fetchOrgSource(server.URL+"/chief-org.yaml", NetworkConfig{}, baseDir); err == nil {
+		t.Fatal("expected the server's certificate to be untrusted without network.caBundle")
+	}
+	data, sig, err := fetchOrgSource(server.URL+"/chief-org.yaml", NetworkConfig{CABundle: "ca.pem"}, baseDir)
+	if err != nil {
+		t.Fatalf("fetchOrgSource() with network.caBundle error = %v", err)
+	}
+	if string(data) != testOrgSettings || sig != nil {
+		t.Errorf("fetchOrgSource() = %q, %q", data, sig)
+	}
+}
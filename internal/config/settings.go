@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Setting is a single config key and its current value, formatted as text.
+type Setting struct {
+	Key   string // Dotted YAML path, e.g. "onComplete.push"
+	Value string
+}
+
+// Settings lists every config key with its value, in the order the keys
+// appear in Config.
+func Settings(cfg *Config) []Setting {
+	var settings []Setting
+	walkSettings(reflect.ValueOf(cfg).Elem(), "", func(key string, v reflect.Value) {
+		settings = append(settings, Setting{Key: key, Value: formatSetting(v)})
+	})
+	return settings
+}
+
+// Get returns the value of a config key.
+func Get(cfg *Config, key string) (string, error) {
+	v, err := lookupSetting(cfg, key)
+	if err != nil {
+		return "", err
+	}
+	return formatSetting(v), nil
+}
+
+// Set parses value for the type of key and stores it in cfg. Lists are given
+// comma-separated; an empty value clears a key.
+func Set(cfg *Config, key, value string) error {
+	v, err := lookupSetting(cfg, key)
+	if err != nil {
+		return err
+	}
+	value = strings.TrimSpace(value)
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		if value == "" {
+			v.SetBool(false)
+			return nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false, got %q", key, value)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		if value == "" {
+			v.SetInt(0)
+			return nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s must be a whole number, got %q", key, value)
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		if value == "" {
+			v.SetFloat(0)
+			return nil
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s must be a number, got %q", key, value)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("%s can't be set from the command line", key)
+	}
+	return nil
+}
+
+// lookupSetting finds the field for a dotted key.
+func lookupSetting(cfg *Config, key string) (reflect.Value, error) {
+	var found reflect.Value
+	walkSettings(reflect.ValueOf(cfg).Elem(), "", func(k string, v reflect.Value) {
+		if k == key {
+			found = v
+		}
+	})
+	if !found.IsValid() {
+		return reflect.Value{}, fmt.Errorf("unknown setting %q (run 'chief settings show' to list settings)", key)
+	}
+	return found, nil
+}
+
+// walkSettings calls fn for every leaf field of a config struct, keyed by its
+// dotted YAML path.
+func walkSettings(v reflect.Value, prefix string, fn func(key string, v reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			walkSettings(field, key, fn)
+			continue
+		}
+		fn(key, field)
+	}
+}
+
+// formatSetting formats a field's value the way Set accepts it.
+func formatSetting(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return strings.Join(items, ",")
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSettings_ListsEveryKey(t *testing.T) {
+	cfg := Default()
+	cfg.OnComplete.Push = true
+	cfg.Commits.Prefixes = []string{"feat", "fix"}
+
+	got := make(map[string]string)
+	for _, s := range Settings(cfg) {
+		got[s.Key] = s.Value
+	}
+	for key, want := range map[string]string{
+		"worktree.setup":        "",
+		"onComplete.push":       "true",
+		"commits.prefixes":      "feat,fix",
+		"loop.stallMinutes":     "0",
+		"similar.threshold":     "0",
+		"forge.stackedPRs":      "false",
+		"workspace.pin":         "",
+		"agent.provider":        "",
+		"coverage.maxDrop":      "0",
+		"verify.gateFlaky":      "false",
+		"onComplete.createPR":   "false",
+		"commits.squashMessage": "",
+	} {
+		value, ok := got[key]
+		if !ok {
+			t.Errorf("missing setting %q", key)
+		} else if value != want {
+			t.Errorf("%s = %q, want %q", key, value, want)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		check   func(*Config) any
+		want    any
+		wantErr bool
+	}{
+		{key: "onComplete.push", value: "true", check: func(c *Config) any { return c.OnComplete.Push }, want: true},
+		{key: "loop.storyTimeoutMinutes", value: "45", check: func(c *Config) any { return c.Loop.StoryTimeoutMinutes }, want: 45},
+		{key: "coverage.maxDrop", value: "1.5", check: func(c *Config) any { return c.Coverage.MaxDrop }, want: 1.5},
+		{key: "verify.command", value: "go test ./...", check: func(c *Config) any { return c.Verify.Command }, want: "go test ./..."},
+		{key: "commits.prefixes", value: "feat, fix,,docs", check: func(c *Config) any { return c.Commits.Prefixes }, want: []string{"feat", "fix", "docs"}},
+		{key: "commits.prefixes", value: "", check: func(c *Config) any { return c.Commits.Prefixes }, want: []string(nil)},
+		{key: "onComplete.push", value: "maybe", wantErr: true},
+		{key: "loop.stallMinutes", value: "three", wantErr: true},
+		{key: "loop.nope", value: "1", wantErr: true},
+		{key: "loop", value: "1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			cfg := Default()
+			err := Set(cfg, tt.key, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := tt.check(cfg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s = %#v, want %#v", tt.key, got, tt.want)
+			}
+			value, err := Get(cfg, tt.key)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if again := Default(); Set(again, tt.key, value) != nil || !reflect.DeepEqual(tt.check(again), tt.want) {
+				t.Errorf("Get() value %q doesn't round-trip through Set()", value)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+)
+
+// Client is a connection to a running Chief instance's control socket.
+type Client struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+}
+
+// Dial connects to the control socket of the project at baseDir.
+func Dial(baseDir string) (*Client, error) {
+	return dial(SocketPath(baseDir))
+}
+
+// DialWatch connects to the read-only socket of the project at baseDir. The
+// server refuses start, pause, stop, and other commands that change a run on
+// this connection.
+func DialWatch(baseDir string) (*Client, error) {
+	return dial(WatchSocketPath(baseDir))
+}
+
+// dial connects to the socket at path.
+func dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("no running chief found for this project (start chief first): %w", err)
+	}
+	scanner := bufio.NewScanner(conn)
+	// Tool inputs can be large (file contents)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	return &Client{conn: conn, scanner: scanner}, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Send writes a request to the server.
+func (c *Client) Send(req Request) error {
+	return json.NewEncoder(c.conn).Encode(req)
+}
+
+// Receive reads the next message from the server.
+func (c *Client) Receive() (Message, error) {
+	if !c.scanner.Scan() {
+		if err := c.scanner.Err(); err != nil {
+			return Message{}, err
+		}
+		return Message{}, fmt.Errorf("connection closed")
+	}
+	var msg Message
+	if err := json.Unmarshal(c.scanner.Bytes(), &msg); err != nil {
+		return Message{}, fmt.Errorf("invalid message: %w", err)
+	}
+	return msg, nil
+}
+
+// Status returns the state of every PRD in the running instance.
+func (c *Client) Status() ([]PRDStatus, error) {
+	if err := c.Send(Request{Cmd: CmdStatus}); err != nil {
+		return nil, err
+	}
+	msg, err := c.Receive()
+	if err != nil {
+		return nil, err
+	}
+	if msg.Type == MsgError {
+		return nil, fmt.Errorf("%s", msg.Error)
+	}
+	return msg.PRDs, nil
+}
+
+// Subscribe starts streaming messages for prd (empty = all PRDs). The first
+// message is a state snapshot; events follow until the connection closes.
+// Use Receive to read them.
+func (c *Client) Subscribe(prd string) error {
+	return c.Send(Request{Cmd: CmdSubscribe, PRD: prd})
+}
+
+// SubscribeWithHistory is Subscribe for one PRD, with the recent events of
+// its current run sent as a history message right after the state snapshot,
+// so a client connecting mid-run can show what happened so far.
+func (c *Client) SubscribeWithHistory(prd string) error {
+	return c.Send(Request{Cmd: CmdSubscribe, PRD: prd, History: true})
+}
+
+// History returns the recent events of prd's current run, oldest first.
+func (c *Client) History(prd string) ([]*Event, error) {
+	if err := c.Send(Request{Cmd: CmdHistory, PRD: prd}); err != nil {
+		return nil, err
+	}
+	msg, err := c.Receive()
+	if err != nil {
+		return nil, err
+	}
+	if msg.Type == MsgError {
+		return nil, fmt.Errorf("%s", msg.Error)
+	}
+	return msg.Events, nil
+}
+
+// Command asks the running instance to start, pause, or stop prd, or to
+// pause or resume every PRD (CmdPauseAll, CmdResumeAll, with no prd), and
+// returns its description of the outcome.
+func (c *Client) Command(cmd, prd string) (string, error) {
+	if err := c.Send(Request{Cmd: cmd, PRD: prd}); err != nil {
+		return "", err
+	}
+	msg, err := c.Receive()
+	if err != nil {
+		return "", err
+	}
+	if msg.Type == MsgError {
+		return "", fmt.Errorf("%s", msg.Error)
+	}
+	return msg.Text, nil
+}
+
+// Maintenance enters (on) or lifts maintenance mode in the running instance
+// and returns its description of the outcome.
+func (c *Client) Maintenance(on bool, reason string) (string, error) {
+	if err := c.Send(Request{Cmd: CmdMaintenance, On: on, Reason: reason}); err != nil {
+		return "", err
+	}
+	msg, err := c.Receive()
+	if err != nil {
+		return "", err
+	}
+	if msg.Type == MsgError {
+		return "", fmt.Errorf("%s", msg.Error)
+	}
+	return msg.Text, nil
+}
@@ -0,0 +1,404 @@
+package control
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/loop"
+)
+
+// shortTempDir returns a temp directory with a path short enough for a unix socket.
+func shortTempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "cc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestServer_StatusAndSubscribe(t *testing.T) {
+	baseDir := shortTempDir(t)
+	manager := loop.NewManager(5, nil)
+	if err := manager.Register("auth", filepath.Join(baseDir, ".chief", "prds", "auth", "prd.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := Listen(baseDir, manager)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer server.Close()
+
+	client, err := Dial(baseDir)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer client.Close()
+
+	statuses, err := client.Status()
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].Name != "auth" || statuses[0].State != "Ready" {
+		t.Fatalf("unexpected status: %+v", statuses)
+	}
+
+	if err := client.Subscribe("auth"); err != nil {
+		t.Fatal(err)
+	}
+	msg, err := client.Receive()
+	if err != nil || msg.Type != MsgState {
+		t.Fatalf("expected initial state, got %+v (%v)", msg, err)
+	}
+
+	// Events for other PRDs are filtered out
+	server.Publish(loop.ManagerEvent{PRDName: "other", Event: loop.Event{Type: loop.EventAssistantText, Text: "nope"}})
+	server.Publish(loop.ManagerEvent{PRDName: "auth", Event: loop.Event{Type: loop.EventError, Err: errors.New("boom"), StoryID: "US-001", Stderr: "ECONNRESET", Cause: "network"}})
+
+	msg, err = client.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Type != MsgEvent || msg.PRD != "auth" || msg.Event == nil {
+		t.Fatalf("expected auth event, got %+v", msg)
+	}
+	event := msg.Event.LoopEvent()
+	if event.Type != loop.EventError || event.Err == nil || event.Err.Error() != "boom" || event.StoryID != "US-001" ||
+		event.Stderr != "ECONNRESET" || event.Cause != "network" {
+		t.Errorf("event did not round-trip: %+v", event)
+	}
+}
+
+func TestServer_Commands(t *testing.T) {
+	baseDir := shortTempDir(t)
+	manager := loop.NewManager(5, nil)
+	if err := manager.Register("auth", filepath.Join(baseDir, "prd.md")); err != nil {
+		t.Fatal(err)
+	}
+	server, err := Listen(baseDir, manager)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	client, err := Dial(baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// Without a handler, commands go straight to the manager
+	if _, err := client.Command(CmdPause, "auth"); err == nil || !strings.Contains(err.Error(), "not running") {
+		t.Errorf("expected pause of an idle PRD to fail, got %v", err)
+	}
+	if _, err := client.Command(CmdPause, ""); err == nil {
+		t.Error("expected an error without a PRD name")
+	}
+
+	// A handler takes over command execution
+	var got Request
+	server.SetHandler(func(req Request) (string, error) {
+		got = req
+		return "Pausing auth after current iteration", nil
+	})
+	text, err := client.Command(CmdPause, "auth")
+	if err != nil {
+		t.Fatalf("Command() error = %v", err)
+	}
+	if text != "Pausing auth after current iteration" || got.Cmd != CmdPause || got.PRD != "auth" {
+		t.Errorf("unexpected reply %q for request %+v", text, got)
+	}
+
+	server.SetHandler(func(req Request) (string, error) {
+		return "", errors.New("chief is waiting for a branch confirmation")
+	})
+	if _, err := client.Command(CmdStart, "auth"); err == nil || !strings.Contains(err.Error(), "branch confirmation") {
+		t.Errorf("expected handler error to be returned, got %v", err)
+	}
+}
+
+func TestServer_History(t *testing.T) {
+	baseDir := shortTempDir(t)
+	manager := loop.NewManager(5, nil)
+	if err := manager.Register("auth", filepath.Join(baseDir, "prd.md")); err != nil {
+		t.Fatal(err)
+	}
+	server, err := Listen(baseDir, manager)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	publish := func(event loop.Event) {
+		server.Publish(loop.ManagerEvent{PRDName: "auth", Event: event})
+	}
+	publish(loop.Event{Type: loop.EventIterationStart, Iteration: 1, StoryID: "US-001"})
+	publish(loop.Event{Type: loop.EventAssistantText, Iteration: 1, Text: "old run"})
+	// A new run starts over at iteration 1
+	publish(loop.Event{Type: loop.EventIterationStart, Iteration: 1, StoryID: "US-002"})
+	publish(loop.Event{Type: loop.EventToolStart, Iteration: 1, Tool: "Bash"})
+	server.Publish(loop.ManagerEvent{PRDName: "other", Event: loop.Event{Type: loop.EventAssistantText, Text: "nope"}})
+
+	client, err := Dial(baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	events, err := client.History("auth")
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(events) != 2 || events[0].StoryID != "US-002" || events[1].Tool != "Bash" {
+		t.Fatalf("expected the current run's two events, got %+v", events)
+	}
+
+	// Subscribing with history sends it after the state, then live events
+	if err := client.SubscribeWithHistory("auth"); err != nil {
+		t.Fatal(err)
+	}
+	if msg, err := client.Receive(); err != nil || msg.Type != MsgState {
+		t.Fatalf("expected initial state, got %+v (%v)", msg, err)
+	}
+	msg, err := client.Receive()
+	if err != nil || msg.Type != MsgHistory || len(msg.Events) != 2 {
+		t.Fatalf("expected history, got %+v (%v)", msg, err)
+	}
+	publish(loop.Event{Type: loop.EventToolResult, Iteration: 1})
+	if msg, err := client.Receive(); err != nil || msg.Type != MsgEvent || msg.Event.Type != "ToolResult" {
+		t.Fatalf("expected a live event, got %+v (%v)", msg, err)
+	}
+}
+
+func TestRunHistory_Bounded(t *testing.T) {
+	h := &runHistory{}
+	for i := 0; i < historySize+10; i++ {
+		h.add(&Event{Type: "AssistantText", Iteration: 1, Text: string(rune('a' + i%26))})
+	}
+	if len(h.events) != historySize {
+		t.Fatalf("expected %d events, got %d", historySize, len(h.events))
+	}
+	if h.events[0].Text != string(rune('a'+10%26)) {
+		t.Errorf("expected the oldest events to be dropped, first is %q", h.events[0].Text)
+	}
+}
+
+func TestListen_AlreadyRunning(t *testing.T) {
+	baseDir := shortTempDir(t)
+	server, err := Listen(baseDir, loop.NewManager(5, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	if _, err := Listen(baseDir, loop.NewManager(5, nil)); !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("expected ErrAlreadyRunning, got %v", err)
+	}
+}
+
+func TestListen_SocketPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix permissions")
+	}
+	baseDir := shortTempDir(t)
+	server, err := Listen(baseDir, loop.NewManager(5, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	for path, want := range map[string]os.FileMode{server.Path(): 0600, server.WatchPath(): watchSocketMode} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != want {
+			t.Errorf("%s permissions = %o, want %o", filepath.Base(path), perm, want)
+		}
+	}
+	// The directories the sockets were bound in are gone
+	entries, err := os.ReadDir(filepath.Dir(server.Path()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected only the two sockets in .chief, got %d entries", len(entries))
+	}
+}
+
+func TestServer_WatchIsReadOnly(t *testing.T) {
+	baseDir := shortTempDir(t)
+	manager := loop.NewManager(5, nil)
+	if err := manager.Register("auth", filepath.Join(baseDir, "prd.md")); err != nil {
+		t.Fatal(err)
+	}
+	server, err := Listen(baseDir, manager)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	var ran []string
+	server.SetHandler(func(req Request) (string, error) {
+		ran = append(ran, req.Cmd)
+		return "ok", nil
+	})
+
+	client, err := DialWatch(baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	for _, req := range []Request{
+		{Cmd: CmdStart, PRD: "auth"},
+		{Cmd: CmdPause, PRD: "auth"},
+		{Cmd: CmdStop, PRD: "auth"},
+		{Cmd: CmdPauseAll},
+		{Cmd: CmdResumeAll},
+		{Cmd: CmdMaintenance, On: true},
+	} {
+		if err := client.Send(req); err != nil {
+			t.Fatal(err)
+		}
+		msg, err := client.Receive()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.Type != MsgError || !strings.Contains(msg.Error, "read-only") {
+			t.Errorf("%s on the watch socket: got %+v, want a read-only error", req.Cmd, msg)
+		}
+	}
+	if len(ran) != 0 {
+		t.Errorf("commands ran on the watch socket: %v", ran)
+	}
+	if manager.Maintenance() != nil {
+		t.Error("maintenance mode was turned on from the watch socket")
+	}
+
+	// Observing still works
+	statuses, err := client.Status()
+	if err != nil || len(statuses) != 1 {
+		t.Fatalf("Status() = %+v, %v", statuses, err)
+	}
+	if err := client.SubscribeWithHistory("auth"); err != nil {
+		t.Fatal(err)
+	}
+	if msg, err := client.Receive(); err != nil || msg.Type != MsgState {
+		t.Errorf("expected a state message after subscribing, got %+v (%v)", msg, err)
+	}
+}
+
+func TestListen_ReplacesStaleSocket(t *testing.T) {
+	baseDir := shortTempDir(t)
+	path := SocketPath(baseDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// A socket file nobody listens on, as left behind by a crash
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+
+	server, err := Listen(baseDir, loop.NewManager(5, nil))
+	if err != nil {
+		t.Fatalf("expected stale socket to be replaced, got %v", err)
+	}
+	server.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected socket to be removed on Close")
+	}
+}
+
+func TestDial_NoServer(t *testing.T) {
+	if _, err := Dial(shortTempDir(t)); err == nil {
+		t.Error("expected error when nothing is listening")
+	}
+}
+
+func TestSocketPath_LongPathFallback(t *testing.T) {
+	short := SocketPath("/tmp/project")
+	if short != "/tmp/project/.chief/control.sock" {
+		t.Errorf("SocketPath() = %q", short)
+	}
+
+	long := SocketPath("/" + strings.Repeat("a", 120))
+	if len(long) > maxSocketPathLen || !strings.HasPrefix(filepath.Base(long), "chief-") {
+		t.Errorf("expected a short fallback path, got %q", long)
+	}
+	if long != SocketPath("/"+strings.Repeat("a", 120)) {
+		t.Error("expected fallback path to be stable")
+	}
+}
+
+func TestServer_PauseAllAndMaintenance(t *testing.T) {
+	baseDir := shortTempDir(t)
+	manager := loop.NewManager(5, nil)
+	manager.SetBaseDir(baseDir)
+	if err := manager.Register("auth", filepath.Join(baseDir, "prd.md")); err != nil {
+		t.Fatal(err)
+	}
+	server, err := Listen(baseDir, manager)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	client, err := Dial(baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	text, err := client.Command(CmdPauseAll, "")
+	if err != nil || text != "No PRD is running" {
+		t.Errorf("pause_all with nothing running = %q, %v", text, err)
+	}
+
+	if _, err := client.Maintenance(true, "kernel upgrade"); err != nil {
+		t.Fatalf("Maintenance(true) error = %v", err)
+	}
+	if m := loop.ReadMaintenance(baseDir); m == nil || m.Reason != "kernel upgrade" {
+		t.Fatalf("expected maintenance mode to be recorded, got %+v", m)
+	}
+	if err := client.Send(Request{Cmd: CmdStatus}); err != nil {
+		t.Fatal(err)
+	}
+	if msg, err := client.Receive(); err != nil || msg.Maintenance == nil {
+		t.Errorf("expected the state to report maintenance mode, got %+v (%v)", msg, err)
+	}
+	if _, err := client.Command(CmdResumeAll, ""); err == nil || !strings.Contains(err.Error(), "maintenance mode") {
+		t.Errorf("expected resume_all to be refused during maintenance, got %v", err)
+	}
+
+	if _, err := client.Maintenance(false, ""); err != nil {
+		t.Fatalf("Maintenance(false) error = %v", err)
+	}
+	if m := loop.ReadMaintenance(baseDir); m != nil {
+		t.Errorf("expected maintenance mode to be lifted, got %+v", m)
+	}
+}
+
+func TestBlockedStories(t *testing.T) {
+	prdPath := filepath.Join(t.TempDir(), "prd.md")
+	md := "# P\n\n### US-001: Schema\n**Status:** done\n\n### US-002: API\n\n### US-003: UI\n**Depends on:** US-001, US-002\n"
+	if err := os.WriteFile(prdPath, []byte(md), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	blocked := blockedStories(prdPath)
+	if len(blocked) != 1 || strings.Join(blocked["US-003"], ",") != "US-002" {
+		t.Errorf("blockedStories() = %v, want US-003 waiting on US-002", blocked)
+	}
+	if blocked := blockedStories(filepath.Join(t.TempDir(), "missing.md")); blocked != nil {
+		t.Errorf("expected nil for an unreadable PRD, got %v", blocked)
+	}
+}
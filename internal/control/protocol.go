@@ -0,0 +1,168 @@
+// Package control implements Chief's local control socket. A running TUI
+// listens on a per-repository unix socket, and everything that talks to a
+// running Chief from outside (chief attach, chief pause, editor plugins) uses
+// it rather than inventing its own channel. Messages are newline-delimited
+// JSON in both directions.
+package control
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+
+	"github.com/minicodemonkey/chief/internal/loop"
+)
+
+// socketFile is the socket location relative to the project root.
+const socketFile = ".chief/control.sock"
+
+// watchSocketFile is the read-only socket location relative to the project
+// root.
+const watchSocketFile = ".chief/watch.sock"
+
+// maxSocketPathLen is the longest unix socket path that works on every
+// supported platform (macOS limits sun_path to 104 bytes).
+const maxSocketPathLen = 100
+
+// Request types sent by clients.
+const (
+	CmdSubscribe = "subscribe" // Stream state and events (optionally for one PRD)
+	CmdStatus    = "status"    // Return the current state of every PRD
+	CmdStart     = "start"     // Start (or resume) a PRD loop
+	CmdPause     = "pause"     // Pause a PRD loop after its current iteration
+	CmdStop      = "stop"      // Stop a PRD loop, letting the agent wrap up first
+	CmdHistory   = "history"   // Return the recent events of a PRD's current run
+
+	CmdPauseAll    = "pause_all"        // Pause every running PRD loop after its current iteration
+	CmdResumeAll   = "resume_all"       // Resume every paused PRD loop
+	CmdMaintenance = "maintenance_mode" // Enter (On) or lift maintenance mode
+)
+
+// Message types sent by the server.
+const (
+	MsgState   = "state"   // Snapshot of every PRD's state
+	MsgEvent   = "event"   // A loop event
+	MsgHistory = "history" // Recent events of a PRD's current run, oldest first
+	MsgOK      = "ok"      // A command succeeded
+	MsgError   = "error"   // The request failed
+)
+
+// Request is a command sent to the control socket.
+type Request struct {
+	Cmd     string `json:"cmd"`
+	PRD     string `json:"prd,omitempty"`
+	History bool   `json:"history,omitempty"` // Subscribe: send the run's recent events after the state snapshot
+	On      bool   `json:"on,omitempty"`      // Maintenance: true enters maintenance mode, false lifts it
+	Reason  string `json:"reason,omitempty"`  // Maintenance: why, shown when a run is refused
+}
+
+// Message is a response or notification sent by the control socket.
+type Message struct {
+	Type   string      `json:"type"`
+	PRD    string      `json:"prd,omitempty"`
+	PRDs   []PRDStatus `json:"prds,omitempty"`
+	Event  *Event      `json:"event,omitempty"`
+	Events []*Event    `json:"events,omitempty"` // History
+	Text   string      `json:"text,omitempty"`   // Outcome of a command
+	Error  string      `json:"error,omitempty"`
+
+	Maintenance *loop.Maintenance `json:"maintenance,omitempty"` // State: the maintenance window in effect
+}
+
+// Handler carries out a start, pause, or stop command and returns a short
+// description of what happened.
+type Handler func(req Request) (string, error)
+
+// PRDStatus describes a PRD loop known to the running Chief instance.
+type PRDStatus struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	State     string `json:"state"`
+	Iteration int    `json:"iteration"`
+	Error     string `json:"error,omitempty"`
+	AgentPID  int    `json:"agentPid,omitempty"` // Process ID of the running agent
+
+	Blocked map[string][]string `json:"blocked,omitempty"` // Unfinished story ID -> the dependencies it waits on
+}
+
+// Event is the wire form of a loop.Event.
+type Event struct {
+	Type      string                 `json:"type"`
+	Iteration int                    `json:"iteration,omitempty"`
+	Text      string                 `json:"text,omitempty"`
+	Tool      string                 `json:"tool,omitempty"`
+	ToolInput map[string]interface{} `json:"toolInput,omitempty"`
+	StoryID   string                 `json:"storyId,omitempty"`
+	Err       string                 `json:"error,omitempty"`
+	Stderr    string                 `json:"stderr,omitempty"`
+	Cause     string                 `json:"cause,omitempty"`
+}
+
+// NewEvent converts a loop event to its wire form.
+func NewEvent(e loop.Event) *Event {
+	wire := &Event{
+		Type:      e.Type.String(),
+		Iteration: e.Iteration,
+		Text:      e.Text,
+		Tool:      e.Tool,
+		ToolInput: e.ToolInput,
+		StoryID:   e.StoryID,
+		Stderr:    e.Stderr,
+		Cause:     e.Cause,
+	}
+	if e.Err != nil {
+		wire.Err = e.Err.Error()
+	}
+	return wire
+}
+
+// LoopEvent converts a wire event back to a loop event.
+func (e *Event) LoopEvent() loop.Event {
+	ev := loop.Event{
+		Type:      loop.ParseEventType(e.Type),
+		Iteration: e.Iteration,
+		Text:      e.Text,
+		Tool:      e.Tool,
+		ToolInput: e.ToolInput,
+		StoryID:   e.StoryID,
+		Stderr:    e.Stderr,
+		Cause:     e.Cause,
+	}
+	if e.Err != "" {
+		ev.Err = errorString(e.Err)
+	}
+	return ev
+}
+
+// errorString is an error reconstructed from its message.
+type errorString string
+
+func (e errorString) Error() string { return string(e) }
+
+// SocketPath returns the control socket path for the project at baseDir.
+// When the natural location (.chief/control.sock) is too long for a unix
+// socket, a path in the temp directory derived from baseDir is used instead.
+func SocketPath(baseDir string) string {
+	return socketPath(baseDir, socketFile, "")
+}
+
+// WatchSocketPath returns the read-only socket path for the project at
+// baseDir. Connections on it can follow a run but not change it.
+func WatchSocketPath(baseDir string) string {
+	return socketPath(baseDir, watchSocketFile, "-watch")
+}
+
+// socketPath returns file under baseDir, or a temp directory path derived
+// from baseDir and ending in suffix when that is too long for a unix socket.
+func socketPath(baseDir, file, suffix string) string {
+	if abs, err := filepath.Abs(baseDir); err == nil {
+		baseDir = abs
+	}
+	path := filepath.Join(baseDir, file)
+	if len(path) <= maxSocketPathLen {
+		return path
+	}
+	sum := sha256.Sum256([]byte(baseDir))
+	return filepath.Join(os.TempDir(), "chief-"+hex.EncodeToString(sum[:8])+suffix+".sock")
+}
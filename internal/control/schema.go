@@ -0,0 +1,114 @@
+package control
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+//go:generate sh -c "go run ../../cmd/chief protocol dump > ../../docs/public/control-protocol.schema.json"
+
+// SchemaID identifies the published schema of the control protocol.
+const SchemaID = "https://chiefloop.com/control-protocol.schema.json"
+
+// Commands lists the request types, for the protocol schema.
+var Commands = []string{CmdSubscribe, CmdStatus, CmdStart, CmdPause, CmdStop, CmdHistory, CmdPauseAll, CmdResumeAll, CmdMaintenance}
+
+// MessageTypes lists the message types, for the protocol schema.
+var MessageTypes = []string{MsgState, MsgEvent, MsgHistory, MsgOK, MsgError}
+
+// enums are the fields whose values are limited to a known set.
+var enums = map[string][]string{
+	"Request.cmd":  Commands,
+	"Message.type": MessageTypes,
+}
+
+// Schema returns a JSON Schema of the control protocol's requests and
+// messages, generated from the Go types so clients written in other
+// languages can stay in sync with it.
+func Schema() ([]byte, error) {
+	defs := make(map[string]any)
+	schema := map[string]any{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"$id":         SchemaID,
+		"title":       "Chief control protocol",
+		"description": "Newline-delimited JSON over the control socket. Clients send a Request per line; the server replies with Message lines.",
+		"oneOf": []any{
+			map[string]any{"$ref": "#/$defs/Request"},
+			map[string]any{"$ref": "#/$defs/Message"},
+		},
+		"$defs": defs,
+	}
+	for _, v := range []any{Request{}, Message{}} {
+		schemaOf(reflect.TypeOf(v), defs)
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// schemaOf returns the schema of t, adding named structs to defs and
+// referring to them.
+func schemaOf(t reflect.Type, defs map[string]any) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaOf(t.Elem(), defs)
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem(), defs)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem(), defs)}
+	case reflect.Struct:
+		if t == reflect.TypeOf(time.Time{}) {
+			return map[string]any{"type": "string", "format": "date-time"}
+		}
+		if _, ok := defs[t.Name()]; !ok {
+			defs[t.Name()] = nil // Guards against recursion
+			defs[t.Name()] = structSchema(t, defs)
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	default:
+		return map[string]any{} // Any value
+	}
+}
+
+// structSchema returns the object schema of a struct from its json tags.
+// Fields without omitempty are required.
+func structSchema(t reflect.Type, defs map[string]any) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		prop := schemaOf(field.Type, defs)
+		if values, ok := enums[t.Name()+"."+name]; ok {
+			prop = map[string]any{"type": "string", "enum": values}
+		}
+		properties[name] = prop
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	s := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
@@ -0,0 +1,57 @@
+package control
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	data, err := Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		Defs map[string]struct {
+			Properties map[string]map[string]any `json:"properties"`
+			Required   []string                  `json:"required"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	for _, name := range []string{"Request", "Message", "PRDStatus", "Event"} {
+		if _, ok := schema.Defs[name]; !ok {
+			t.Errorf("expected a definition for %s", name)
+		}
+	}
+
+	request := schema.Defs["Request"]
+	if !slices.Equal(request.Required, []string{"cmd"}) {
+		t.Errorf("expected only cmd to be required in a request, got %v", request.Required)
+	}
+	if enum, _ := request.Properties["cmd"]["enum"].([]any); len(enum) != len(Commands) {
+		t.Errorf("expected cmd to list every command, got %v", request.Properties["cmd"])
+	}
+	if ref := schema.Defs["Message"].Properties["event"]["$ref"]; ref != "#/$defs/Event" {
+		t.Errorf("expected the message's event to refer to Event, got %v", ref)
+	}
+}
+
+// The published schema is generated with `go generate ./internal/control`.
+func TestSchema_Published(t *testing.T) {
+	published, err := os.ReadFile(filepath.Join("..", "..", "docs", "public", "control-protocol.schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(bytes.TrimSpace(published), data) {
+		t.Error("docs/public/control-protocol.schema.json is out of date; run `go generate ./internal/control`")
+	}
+}
@@ -0,0 +1,564 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// ErrAlreadyRunning is returned by Listen when another Chief instance already
+// serves the control socket for the project.
+var ErrAlreadyRunning = errors.New("another chief instance is serving this project")
+
+// subscriberBuffer is how many messages may queue for a slow subscriber before
+// further events are dropped for it.
+const subscriberBuffer = 256
+
+// stateInterval is how often subscribers are sent a new state snapshot when
+// a PRD's state has changed.
+const stateInterval = time.Second
+
+// historySize is how many recent events of each PRD's run are kept for
+// clients that connect mid-run.
+const historySize = 500
+
+// Server serves the control socket for a Manager, and a read-only watch
+// socket for observers that may follow runs but not change them.
+type Server struct {
+	path          string
+	listener      net.Listener
+	watchPath     string
+	watchListener net.Listener
+	manager       *loop.Manager
+
+	mu          sync.Mutex
+	handler     Handler // Runs commands; nil = call the manager directly
+	subscribers map[*subscriber]struct{}
+	history     map[string]*runHistory // Recent events by PRD
+	conns       map[net.Conn]struct{}
+	closed      bool
+	wg          sync.WaitGroup
+}
+
+// runHistory holds the most recent events of a PRD's current run.
+type runHistory struct {
+	events    []*Event
+	iteration int // Iteration of the latest event, to notice a new run
+}
+
+// add appends an event, dropping the oldest beyond historySize. An iteration
+// starting no later than the last one seen begins a new run, and the previous
+// run's events are dropped.
+func (h *runHistory) add(event *Event) {
+	if event.Type == loop.EventIterationStart.String() && event.Iteration <= h.iteration {
+		h.events = nil
+	}
+	if event.Iteration > 0 {
+		h.iteration = event.Iteration
+	}
+	if len(h.events) == historySize {
+		h.events = append(h.events[:0:0], h.events[1:]...)
+	}
+	h.events = append(h.events, event)
+}
+
+// subscriber is a connection following events.
+type subscriber struct {
+	prd string // Only events for this PRD (empty = all)
+	ch  chan Message
+}
+
+// watchSocketMode lets the owner's group connect to the watch socket, so a
+// teammate on another account can follow a run.
+const watchSocketMode = 0660
+
+// Listen starts serving the control and watch sockets for the project at
+// baseDir and registers the server as the manager's event callback. A stale
+// socket left by a crashed instance is replaced; a live one yields
+// ErrAlreadyRunning.
+func Listen(baseDir string, manager *loop.Manager) (*Server, error) {
+	path := SocketPath(baseDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, ErrAlreadyRunning
+		}
+		os.Remove(path)
+	}
+
+	// Only the owner may control the loops
+	listener, err := listenWithMode(path, 0600)
+	if err != nil {
+		return nil, err
+	}
+	watchPath := WatchSocketPath(baseDir)
+	watchListener, err := listenWithMode(watchPath, watchSocketMode)
+	if err != nil {
+		listener.Close()
+		os.Remove(path)
+		return nil, err
+	}
+
+	s := &Server{
+		path:          path,
+		listener:      listener,
+		watchPath:     watchPath,
+		watchListener: watchListener,
+		manager:       manager,
+		subscribers:   make(map[*subscriber]struct{}),
+		history:       make(map[string]*runHistory),
+		conns:         make(map[net.Conn]struct{}),
+	}
+	manager.SetEventCallback(s.Publish)
+
+	s.wg.Add(2)
+	go s.acceptLoop(listener, false)
+	go s.acceptLoop(watchListener, true)
+	return s, nil
+}
+
+// listenWithMode listens on a unix socket at path with the given permissions.
+// The socket is bound inside a new directory only the owner can enter and
+// moved into place once chmodded, so no one can connect to it early.
+func listenWithMode(path string, mode os.FileMode) (net.Listener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".sock")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "s")
+	listener, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	// The socket file moves; Close shouldn't unlink whatever is left at tmp
+	if ul, ok := listener.(*net.UnixListener); ok {
+		ul.SetUnlinkOnClose(false)
+	}
+	if err := os.Chmod(tmp, mode); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set permissions of %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	return listener, nil
+}
+
+// SetHandler routes start, pause, and stop commands through h, so they take
+// the same path as the equivalent action in the TUI. Without a handler the
+// server calls the manager directly.
+func (s *Server) SetHandler(h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handler = h
+}
+
+// Path returns the socket path.
+func (s *Server) Path() string {
+	return s.path
+}
+
+// WatchPath returns the read-only socket path.
+func (s *Server) WatchPath() string {
+	return s.watchPath
+}
+
+// Close stops the server, disconnects clients, and removes the socket.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	for sub := range s.subscribers {
+		close(sub.ch)
+		delete(s.subscribers, sub)
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.manager.SetEventCallback(nil)
+	err := s.listener.Close()
+	s.watchListener.Close()
+	s.wg.Wait()
+	os.Remove(s.path)
+	os.Remove(s.watchPath)
+	return err
+}
+
+// Publish sends a manager event to every matching subscriber and adds it to
+// the PRD's history. It never blocks: subscribers that fall behind miss
+// events rather than stalling the loop.
+func (s *Server) Publish(event loop.ManagerEvent) {
+	msg := Message{Type: MsgEvent, PRD: event.PRDName, Event: NewEvent(event.Event)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, ok := s.history[event.PRDName]
+	if !ok {
+		h = &runHistory{}
+		s.history[event.PRDName] = h
+	}
+	h.add(msg.Event)
+	for sub := range s.subscribers {
+		if sub.prd != "" && sub.prd != event.PRDName {
+			continue
+		}
+		select {
+		case sub.ch <- msg:
+		default:
+		}
+	}
+}
+
+// acceptLoop accepts connections until the listener is closed. Connections
+// from a read-only listener may not change any run.
+func (s *Server) acceptLoop(listener net.Listener, readOnly bool) {
+	defer s.wg.Done()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn, readOnly)
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// mutating lists the commands that change a run, which read-only connections
+// may not send.
+var mutating = map[string]bool{
+	CmdStart:       true,
+	CmdPause:       true,
+	CmdStop:        true,
+	CmdPauseAll:    true,
+	CmdResumeAll:   true,
+	CmdMaintenance: true,
+}
+
+// handle serves requests from a single connection.
+func (s *Server) handle(conn net.Conn, readOnly bool) {
+	defer conn.Close()
+	enc := json.NewEncoder(conn)
+	scanner := bufio.NewScanner(conn)
+
+	for scanner.Scan() {
+		var req Request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			enc.Encode(Message{Type: MsgError, Error: "invalid request: " + err.Error()})
+			continue
+		}
+		if readOnly && mutating[req.Cmd] {
+			if err := enc.Encode(Message{Type: MsgError, Error: fmt.Sprintf("%s is not allowed on a read-only connection", req.Cmd)}); err != nil {
+				return
+			}
+			continue
+		}
+
+		switch req.Cmd {
+		case CmdStatus:
+			if err := enc.Encode(s.state()); err != nil {
+				return
+			}
+		case CmdSubscribe:
+			s.stream(conn, enc, req)
+			return
+		case CmdHistory:
+			if err := enc.Encode(s.historyMessage(req.PRD)); err != nil {
+				return
+			}
+		case CmdStart, CmdPause, CmdStop:
+			if err := enc.Encode(s.command(req)); err != nil {
+				return
+			}
+		case CmdPauseAll, CmdResumeAll:
+			if err := enc.Encode(s.commandAll(req.Cmd)); err != nil {
+				return
+			}
+		case CmdMaintenance:
+			if err := enc.Encode(s.maintenance(req)); err != nil {
+				return
+			}
+		default:
+			if err := enc.Encode(Message{Type: MsgError, Error: fmt.Sprintf("unknown command %q", req.Cmd)}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// command runs a start, pause, or stop command and returns the reply.
+func (s *Server) command(req Request) Message {
+	if req.PRD == "" {
+		return Message{Type: MsgError, Error: "a PRD name is required"}
+	}
+
+	s.mu.Lock()
+	handler := s.handler
+	s.mu.Unlock()
+	if handler == nil {
+		handler = s.runOnManager
+	}
+
+	text, err := handler(req)
+	if err != nil {
+		return Message{Type: MsgError, PRD: req.PRD, Error: err.Error()}
+	}
+	return Message{Type: MsgOK, PRD: req.PRD, Text: text}
+}
+
+// commandAll pauses every running PRD or resumes every paused one, each the
+// same way as a single pause or start command.
+func (s *Server) commandAll(cmd string) Message {
+	single, from, verb := CmdPause, loop.LoopStateRunning.String(), "Pausing"
+	if cmd == CmdResumeAll {
+		single, from, verb = CmdStart, loop.LoopStatePaused.String(), "Resuming"
+		if m := s.manager.Maintenance(); m != nil {
+			return Message{Type: MsgError, Error: fmt.Sprintf("chief is in maintenance mode (%s); lift it first", m)}
+		}
+	}
+
+	var done, failed []string
+	for _, prd := range s.state().PRDs {
+		if prd.State != from {
+			continue
+		}
+		reply := s.command(Request{Cmd: single, PRD: prd.Name})
+		if reply.Type == MsgError {
+			failed = append(failed, fmt.Sprintf("%s: %s", prd.Name, reply.Error))
+			continue
+		}
+		done = append(done, prd.Name)
+	}
+	if len(failed) > 0 {
+		return Message{Type: MsgError, Error: strings.Join(failed, "; ")}
+	}
+	if len(done) == 0 {
+		return Message{Type: MsgOK, Text: "No PRD is " + strings.ToLower(from)}
+	}
+	return Message{Type: MsgOK, Text: verb + " " + strings.Join(done, ", ")}
+}
+
+// maintenance enters or lifts maintenance mode. Entering it also pauses every
+// running PRD; lifting it leaves paused PRDs paused until they are resumed.
+func (s *Server) maintenance(req Request) Message {
+	if !req.On {
+		if err := s.manager.LeaveMaintenance(); err != nil {
+			return Message{Type: MsgError, Error: err.Error()}
+		}
+		return Message{Type: MsgOK, Text: "Maintenance mode lifted"}
+	}
+	if err := s.manager.EnterMaintenance(req.Reason); err != nil {
+		return Message{Type: MsgError, Error: err.Error()}
+	}
+	reply := s.commandAll(CmdPauseAll)
+	if reply.Type == MsgError {
+		reply.Error = "maintenance mode on, but not every PRD paused: " + reply.Error
+		return reply
+	}
+	reply.Text = "Maintenance mode on. " + reply.Text
+	return reply
+}
+
+// runOnManager is the default Handler: it calls the manager directly.
+func (s *Server) runOnManager(req Request) (string, error) {
+	switch req.Cmd {
+	case CmdStart:
+		if err := s.manager.Start(req.PRD); err != nil {
+			return "", err
+		}
+		return "Started " + req.PRD, nil
+	case CmdPause:
+		if err := s.manager.Pause(req.PRD); err != nil {
+			return "", err
+		}
+		return "Pausing " + req.PRD + " after current iteration", nil
+	case CmdStop:
+		if err := s.manager.Stop(req.PRD); err != nil {
+			return "", err
+		}
+		return "Stopping " + req.PRD, nil
+	}
+	return "", fmt.Errorf("unknown command %q", req.Cmd)
+}
+
+// historyMessage returns the recent events of a PRD's run.
+func (s *Server) historyMessage(prd string) Message {
+	if prd == "" {
+		return Message{Type: MsgError, Error: "a PRD name is required"}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.historyLocked(prd)
+}
+
+// historyLocked returns the history message for prd. s.mu must be held.
+func (s *Server) historyLocked(prd string) Message {
+	msg := Message{Type: MsgHistory, PRD: prd}
+	if h, ok := s.history[prd]; ok {
+		msg.Events = append([]*Event(nil), h.events...)
+	}
+	return msg
+}
+
+// stream sends a state snapshot followed by events until the client
+// disconnects or the server closes. With req.History set, the PRD's recent
+// events are sent after the snapshot; they are taken together with
+// subscribing, so none are missed or repeated.
+func (s *Server) stream(conn net.Conn, enc *json.Encoder, req Request) {
+	prd := req.PRD
+	sub := &subscriber{prd: prd, ch: make(chan Message, subscriberBuffer)}
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.subscribers[sub] = struct{}{}
+	var history *Message
+	if req.History && prd != "" {
+		msg := s.historyLocked(prd)
+		history = &msg
+	}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		if _, ok := s.subscribers[sub]; ok {
+			delete(s.subscribers, sub)
+			close(sub.ch)
+		}
+		s.mu.Unlock()
+	}()
+
+	last := s.state()
+	if err := enc.Encode(last); err != nil {
+		return
+	}
+	if history != nil {
+		if err := enc.Encode(history); err != nil {
+			return
+		}
+	}
+
+	// Detect client disconnects while waiting for events
+	gone := make(chan struct{})
+	go func() {
+		buf := make([]byte, 1)
+		for {
+			if _, err := conn.Read(buf); err != nil {
+				close(gone)
+				return
+			}
+		}
+	}()
+
+	// State changes such as pausing don't produce loop events, so poll for them
+	ticker := time.NewTicker(stateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg, ok := <-sub.ch:
+			if !ok {
+				return
+			}
+			if err := enc.Encode(msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			current := s.state()
+			if reflect.DeepEqual(current, last) {
+				continue
+			}
+			last = current
+			if err := enc.Encode(current); err != nil {
+				return
+			}
+		case <-gone:
+			return
+		}
+	}
+}
+
+// state returns a snapshot of every PRD registered with the manager.
+func (s *Server) state() Message {
+	instances := s.manager.GetAllInstances()
+	prds := make([]PRDStatus, 0, len(instances))
+	for _, inst := range instances {
+		status := PRDStatus{
+			Name:      inst.Name,
+			Path:      inst.PRDPath,
+			State:     inst.State.String(),
+			Iteration: inst.Iteration,
+			AgentPID:  inst.AgentPID,
+		}
+		if inst.Error != nil {
+			status.Error = inst.Error.Error()
+		}
+		status.Blocked = blockedStories(inst.PRDPath)
+		prds = append(prds, status)
+	}
+	sort.Slice(prds, func(i, j int) bool { return prds[i].Name < prds[j].Name })
+	return Message{Type: MsgState, PRDs: prds, Maintenance: s.manager.Maintenance()}
+}
+
+// blockedStories maps each unfinished story of the PRD at prdPath to the
+// dependencies it is waiting on, or returns nil when none waits or the PRD
+// can't be read.
+func blockedStories(prdPath string) map[string][]string {
+	p, err := prd.LoadPRD(prdPath)
+	if err != nil {
+		return nil
+	}
+	var blocked map[string][]string
+	for i := range p.UserStories {
+		story := &p.UserStories[i]
+		if story.Passes {
+			continue
+		}
+		if deps := p.BlockedBy(story); len(deps) > 0 {
+			if blocked == nil {
+				blocked = make(map[string][]string)
+			}
+			blocked[story.ID] = deps
+		}
+	}
+	return blocked
+}
@@ -0,0 +1,422 @@
+// Package conventions drafts a project's conventions file from what the
+// repository already shows: the languages and frameworks it uses, the linters
+// and formatters it is configured for, its folder layout, and where its tests
+// live. The draft is a starting point for .chief/conventions.md, which the
+// conventions prompt middleware feeds to the agent.
+package conventions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxScannedFiles caps the files walked when looking for tests, so a large
+// repository is scanned quickly.
+const maxScannedFiles = 20000
+
+// Section is a group of conventions under one heading.
+type Section struct {
+	Title string
+	Rules []string
+}
+
+// skippedDirs are never walked or listed as part of the layout.
+var skippedDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "dist": true, "build": true, "target": true,
+	"__pycache__": true, "venv": true, "coverage": true,
+}
+
+// layoutRules describe well-known top-level directories.
+var layoutRules = map[string]string{
+	"cmd":      "Entry points live in `cmd/<name>/`; keep them thin and put the logic in packages.",
+	"internal": "Packages that aren't part of the public API go under `internal/`.",
+	"pkg":      "Packages meant to be imported by other projects go under `pkg/`.",
+	"src":      "Application source lives in `src/`.",
+	"lib":      "Shared library code lives in `lib/`.",
+	"app":      "Application code lives in `app/`.",
+	"docs":     "Update the docs in `docs/` when behavior changes.",
+	"scripts":  "Helper scripts go in `scripts/`, not the repository root.",
+}
+
+// goVersionRegex matches the go directive of go.mod.
+var goVersionRegex = regexp.MustCompile(`(?m)^go\s+(\S+)`)
+
+// Scan looks at the repository in dir and returns the conventions it
+// suggests. Sections with nothing found are left out.
+func Scan(dir string) ([]Section, error) {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	p := &project{dir: dir, npmDeps: make(map[string]bool)}
+	p.readManifests()
+
+	sections := []Section{
+		{Title: "Stack", Rules: p.stackRules()},
+		{Title: "Linting and Formatting", Rules: p.lintRules()},
+		{Title: "Layout", Rules: p.layoutRules()},
+		{Title: "Tests", Rules: p.testRules()},
+	}
+	var found []Section
+	for _, s := range sections {
+		if len(s.Rules) > 0 {
+			found = append(found, s)
+		}
+	}
+	return found, nil
+}
+
+// Render formats sections as a conventions file, one convention per list
+// item.
+func Render(sections []Section) string {
+	var b strings.Builder
+	b.WriteString("# Conventions\n\n")
+	b.WriteString("Drafted by `chief init-conventions` from the repository's files. Edit freely: every list item is a rule the agent follows and `chief eval` checks.\n")
+	for _, s := range sections {
+		fmt.Fprintf(&b, "\n## %s\n\n", s.Title)
+		for _, r := range s.Rules {
+			fmt.Fprintf(&b, "- %s\n", r)
+		}
+	}
+	return b.String()
+}
+
+// project holds what was read from a repository's manifests.
+type project struct {
+	dir       string
+	goModule  string
+	goVersion string
+	npm       bool
+	npmDeps   map[string]bool // npm dependencies and devDependencies
+	scripts   map[string]string
+	pyproject string // Contents of pyproject.toml
+	python    bool
+	rust      bool
+	ruby      bool
+}
+
+// exists reports whether any of the names exists in the project root.
+func (p *project) exists(names ...string) bool {
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(p.dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// glob reports whether any file in the project root matches pattern.
+func (p *project) glob(pattern string) bool {
+	matches, _ := filepath.Glob(filepath.Join(p.dir, pattern))
+	return len(matches) > 0
+}
+
+// read returns the contents of a file in the project root, or "".
+func (p *project) read(name string) string {
+	data, _ := os.ReadFile(filepath.Join(p.dir, name))
+	return string(data)
+}
+
+func (p *project) readManifests() {
+	if mod := p.read("go.mod"); mod != "" {
+		for _, line := range strings.Split(mod, "\n") {
+			if name, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+				p.goModule = strings.TrimSpace(name)
+				break
+			}
+		}
+		if m := goVersionRegex.FindStringSubmatch(mod); m != nil {
+			p.goVersion = m[1]
+		}
+	}
+	if data := p.read("package.json"); data != "" {
+		p.npm = true
+		var pkg struct {
+			Dependencies    map[string]string `json:"dependencies"`
+			DevDependencies map[string]string `json:"devDependencies"`
+			Scripts         map[string]string `json:"scripts"`
+		}
+		if json.Unmarshal([]byte(data), &pkg) == nil {
+			for name := range pkg.Dependencies {
+				p.npmDeps[name] = true
+			}
+			for name := range pkg.DevDependencies {
+				p.npmDeps[name] = true
+			}
+			p.scripts = pkg.Scripts
+		}
+	}
+	p.pyproject = p.read("pyproject.toml")
+	p.python = p.pyproject != "" || p.exists("requirements.txt", "setup.py")
+	p.rust = p.exists("Cargo.toml")
+	p.ruby = p.exists("Gemfile")
+}
+
+// pythonUses reports whether the Python project mentions a package, as a
+// dependency or a [tool.<name>] section.
+func (p *project) pythonUses(name string) bool {
+	text := strings.ToLower(p.pyproject + "\n" + p.read("requirements.txt") + "\n" + p.read("requirements-dev.txt"))
+	return regexp.MustCompile(`(?m)(^|["'\s\[.])` + regexp.QuoteMeta(name) + `\b`).MatchString(text)
+}
+
+func (p *project) stackRules() []string {
+	var rules []string
+	if p.goModule != "" {
+		rule := fmt.Sprintf("This is the Go module `%s`", p.goModule)
+		if p.goVersion != "" {
+			rule += fmt.Sprintf("; keep code compatible with Go %s", p.goVersion)
+		}
+		rules = append(rules, rule+". Keep `go.mod` tidy with `go mod tidy`.")
+	}
+	if p.npm {
+		if p.npmDeps["typescript"] || p.exists("tsconfig.json") {
+			rules = append(rules, "Write new code in TypeScript, not JavaScript, and keep it passing `tsc` with the repository's `tsconfig.json`.")
+		}
+		var frameworks []string
+		for _, f := range []struct{ pkg, name string }{
+			{"next", "Next.js"}, {"react", "React"}, {"vue", "Vue"}, {"svelte", "Svelte"},
+			{"@angular/core", "Angular"}, {"@nestjs/core", "NestJS"}, {"express", "Express"}, {"fastify", "Fastify"},
+		} {
+			if p.npmDeps[f.pkg] {
+				frameworks = append(frameworks, f.name)
+			}
+		}
+		if len(frameworks) > 0 {
+			rules = append(rules, fmt.Sprintf("Follow the idioms of %s already used in the codebase rather than adding another framework.", joinList(frameworks)))
+		}
+		switch {
+		case p.exists("pnpm-lock.yaml"):
+			rules = append(rules, "Manage packages with pnpm; don't add a package-lock.json or yarn.lock.")
+		case p.exists("yarn.lock"):
+			rules = append(rules, "Manage packages with yarn; don't add a package-lock.json or pnpm-lock.yaml.")
+		case p.exists("bun.lockb", "bun.lock"):
+			rules = append(rules, "Manage packages with bun; don't add another package manager's lockfile.")
+		case p.exists("package-lock.json"):
+			rules = append(rules, "Manage packages with npm; don't add a yarn.lock or pnpm-lock.yaml.")
+		}
+	}
+	if p.python {
+		var frameworks []string
+		for _, f := range []struct{ pkg, name string }{{"django", "Django"}, {"flask", "Flask"}, {"fastapi", "FastAPI"}} {
+			if p.pythonUses(f.pkg) {
+				frameworks = append(frameworks, f.name)
+			}
+		}
+		rule := "This is a Python project"
+		if len(frameworks) > 0 {
+			rule += " built on " + joinList(frameworks)
+		}
+		switch {
+		case p.exists("uv.lock"):
+			rule += "; manage dependencies with uv"
+		case p.exists("poetry.lock"):
+			rule += "; manage dependencies with Poetry"
+		}
+		rules = append(rules, rule+".")
+	}
+	if p.rust {
+		rules = append(rules, "This is a Rust crate; run `cargo fmt` and keep `cargo clippy` free of warnings.")
+	}
+	if p.ruby {
+		if strings.Contains(p.read("Gemfile"), "rails") {
+			rules = append(rules, "This is a Rails app; follow Rails conventions for models, controllers and migrations.")
+		} else {
+			rules = append(rules, "Manage Ruby dependencies in the Gemfile with Bundler.")
+		}
+	}
+	return rules
+}
+
+func (p *project) lintRules() []string {
+	var rules []string
+	if p.goModule != "" {
+		rules = append(rules, "Format Go code with `gofmt` and keep `go vet ./...` clean.")
+	}
+	if p.glob(".golangci.*") {
+		rules = append(rules, "Code must pass golangci-lint with the repository's config; don't add `//nolint` without a reason.")
+	}
+	if p.glob(".eslintrc*") || p.glob("eslint.config.*") || p.npmDeps["eslint"] {
+		rules = append(rules, "Code must pass ESLint with the repository's config; don't disable rules inline without a comment saying why.")
+	}
+	if p.glob(".prettierrc*") || p.glob("prettier.config.*") || p.npmDeps["prettier"] {
+		rules = append(rules, "Format code with Prettier using the repository's config.")
+	}
+	if p.exists("biome.json", "biome.jsonc") {
+		rules = append(rules, "Code must pass Biome's linter and formatter with the repository's `biome.json`.")
+	}
+	if p.exists("ruff.toml", ".ruff.toml") || strings.Contains(p.pyproject, "[tool.ruff") {
+		rules = append(rules, "Python code must pass `ruff check` and be formatted with the repository's ruff settings.")
+	}
+	if strings.Contains(p.pyproject, "[tool.black") {
+		rules = append(rules, "Format Python code with Black.")
+	}
+	if p.exists("mypy.ini") || strings.Contains(p.pyproject, "[tool.mypy") {
+		rules = append(rules, "Add type hints to new Python code; it must pass mypy.")
+	}
+	if p.exists(".rubocop.yml") {
+		rules = append(rules, "Ruby code must pass RuboCop with the repository's `.rubocop.yml`.")
+	}
+	if p.exists(".editorconfig") {
+		rules = append(rules, "Follow `.editorconfig` for indentation, line endings and final newlines.")
+	}
+	if p.scripts["lint"] != "" {
+		rules = append(rules, "Run the `lint` script from package.json before committing.")
+	} else if regexp.MustCompile(`(?m)^lint:`).MatchString(p.read("Makefile")) {
+		rules = append(rules, "Run `make lint` before committing.")
+	}
+	return rules
+}
+
+func (p *project) layoutRules() []string {
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		return nil
+	}
+	var dirs, rules []string
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() || strings.HasPrefix(name, ".") || skippedDirs[name] {
+			continue
+		}
+		dirs = append(dirs, name)
+		if rule, ok := layoutRules[name]; ok {
+			rules = append(rules, rule)
+		}
+	}
+	if len(dirs) == 0 {
+		return nil
+	}
+	listed := make([]string, len(dirs))
+	for i, d := range dirs {
+		listed[i] = "`" + d + "/`"
+	}
+	return append([]string{fmt.Sprintf("Keep the existing top-level layout (%s); don't add top-level directories without a clear reason.", strings.Join(listed, ", "))}, rules...)
+}
+
+// testLayout counts the test files found in a repository, by style.
+type testLayout struct {
+	goTests      int // *_test.go
+	jsColocated  int // *.test.* and *.spec.* outside __tests__
+	jsTestsDir   int // Files under __tests__/
+	pyTests      int // test_*.py and *_test.py
+	pyTestsRoot  int // Python tests under a top-level tests/ directory
+	rubySpecs    int // *_spec.rb
+	rustTestsDir bool
+	e2e          []string // End-to-end test tools configured
+}
+
+func (p *project) scanTests() testLayout {
+	var t testLayout
+	files := 0
+	_ = filepath.WalkDir(p.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != p.dir && (strings.HasPrefix(name, ".") || skippedDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if files++; files > maxScannedFiles {
+			return filepath.SkipAll
+		}
+		rel, _ := filepath.Rel(p.dir, path)
+		rel = filepath.ToSlash(rel)
+		switch {
+		case strings.HasSuffix(name, "_test.go"):
+			t.goTests++
+		case isJSFile(name) && strings.Contains("/"+rel, "/__tests__/"):
+			t.jsTestsDir++
+		case isJSFile(name) && (strings.Contains(name, ".test.") || strings.Contains(name, ".spec.")):
+			t.jsColocated++
+		case strings.HasSuffix(name, ".py") && (strings.HasPrefix(name, "test_") || strings.HasSuffix(name, "_test.py")):
+			t.pyTests++
+			if strings.HasPrefix(rel, "tests/") {
+				t.pyTestsRoot++
+			}
+		case strings.HasSuffix(name, "_spec.rb"):
+			t.rubySpecs++
+		case strings.HasSuffix(name, ".rs") && strings.HasPrefix(rel, "tests/"):
+			t.rustTestsDir = true
+		}
+		return nil
+	})
+	if p.npmDeps["@playwright/test"] || p.glob("playwright.config.*") {
+		t.e2e = append(t.e2e, "Playwright")
+	}
+	if p.npmDeps["cypress"] || p.glob("cypress.config.*") {
+		t.e2e = append(t.e2e, "Cypress")
+	}
+	return t
+}
+
+func (p *project) testRules() []string {
+	t := p.scanTests()
+	var rules []string
+	if t.goTests > 0 {
+		rules = append(rules, "Go tests live next to the code they test, in `_test.go` files of the same package; prefer table-driven tests.")
+	}
+	if t.jsColocated+t.jsTestsDir > 0 {
+		runner := ""
+		for _, r := range []struct{ pkg, name string }{{"vitest", "Vitest"}, {"jest", "Jest"}, {"mocha", "Mocha"}} {
+			if p.npmDeps[r.pkg] {
+				runner = " with " + r.name
+				break
+			}
+		}
+		if t.jsTestsDir > t.jsColocated {
+			rules = append(rules, fmt.Sprintf("JavaScript tests go in `__tests__/` directories%s.", runner))
+		} else {
+			rules = append(rules, fmt.Sprintf("JavaScript tests sit next to the code as `*.test.*` files%s.", runner))
+		}
+	}
+	if t.pyTests > 0 {
+		where := "next to the code"
+		if t.pyTestsRoot*2 > t.pyTests {
+			where = "under `tests/`"
+		}
+		runner := ""
+		if p.pythonUses("pytest") {
+			runner = ", using pytest"
+		}
+		rules = append(rules, fmt.Sprintf("Python tests live %s as `test_*.py` files%s.", where, runner))
+	}
+	if t.rubySpecs > 0 {
+		rules = append(rules, "Ruby specs go in `spec/` as `*_spec.rb` files.")
+	}
+	if t.rustTestsDir {
+		rules = append(rules, "Rust unit tests go in a `#[cfg(test)]` module of the file; integration tests go in `tests/`.")
+	}
+	if len(t.e2e) > 0 {
+		sort.Strings(t.e2e)
+		rules = append(rules, fmt.Sprintf("User-facing changes get an end-to-end test with %s.", joinList(t.e2e)))
+	}
+	if len(rules) > 0 {
+		rules = append(rules, "Every change comes with new or updated tests, and the existing tests keep passing.")
+	}
+	return rules
+}
+
+// isJSFile reports whether name is a JavaScript or TypeScript source file.
+func isJSFile(name string) bool {
+	switch filepath.Ext(name) {
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs", ".vue", ".svelte":
+		return true
+	}
+	return false
+}
+
+// joinList joins items as "a", "a and b", or "a, b and c".
+func joinList(items []string) string {
+	if len(items) <= 1 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
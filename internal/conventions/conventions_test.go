@@ -0,0 +1,119 @@
+package conventions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files under dir, with their parent directories.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// rules returns every rule of the sections, joined by newlines.
+func rules(sections []Section) string {
+	var all []string
+	for _, s := range sections {
+		all = append(all, s.Rules...)
+	}
+	return strings.Join(all, "\n")
+}
+
+func TestScan_Go(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":                       "module example.com/app\n\ngo 1.22\n",
+		".golangci.yml":                "linters: {}\n",
+		"cmd/app/main.go":              "package main\n",
+		"internal/store/store.go":      "package store\n",
+		"internal/store/store_test.go": "package store\n",
+		"vendor/x/x_test.go":           "package x\n",
+	})
+
+	sections, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := rules(sections)
+	for _, want := range []string{"`example.com/app`", "Go 1.22", "golangci-lint", "`cmd/<name>/`", "`internal/`", "`_test.go` files", "(`cmd/`, `internal/`)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected a rule mentioning %s, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "vendor") {
+		t.Errorf("expected vendor/ to be skipped, got:\n%s", got)
+	}
+}
+
+func TestScan_TypeScript(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"package.json":                  `{"dependencies":{"react":"^18"},"devDependencies":{"typescript":"^5","vitest":"^1","eslint":"^9"},"scripts":{"lint":"eslint ."}}`,
+		"pnpm-lock.yaml":                "",
+		"src/App.tsx":                   "",
+		"src/App.test.tsx":              "",
+		"node_modules/a/a.test.js":      "",
+		"node_modules/b/__tests__/b.js": "",
+	})
+
+	sections, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := rules(sections)
+	for _, want := range []string{"TypeScript", "React", "pnpm", "ESLint", "`lint` script", "`*.test.*` files with Vitest"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected a rule mentioning %s, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestScan_Python(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"pyproject.toml":      "[project]\ndependencies = [\"fastapi\"]\n\n[tool.ruff]\nline-length = 100\n\n[tool.pytest.ini_options]\n",
+		"uv.lock":             "",
+		"app/main.py":         "",
+		"tests/test_main.py":  "",
+		"tests/test_users.py": "",
+	})
+
+	sections, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := rules(sections)
+	for _, want := range []string{"built on FastAPI", "uv", "ruff", "under `tests/`", "pytest"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected a rule mentioning %s, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	out := Render([]Section{{Title: "Tests", Rules: []string{"Add tests", "Keep them fast"}}})
+	if !strings.HasPrefix(out, "# Conventions\n") || !strings.Contains(out, "## Tests\n\n- Add tests\n- Keep them fast\n") {
+		t.Errorf("unexpected render:\n%s", out)
+	}
+}
+
+func TestScan_Empty(t *testing.T) {
+	sections, err := Scan(t.TempDir())
+	if err != nil || len(sections) != 0 {
+		t.Errorf("expected no sections for an empty directory, got %+v, %v", sections, err)
+	}
+	if _, err := Scan(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
@@ -0,0 +1,151 @@
+// Package deps finds outdated and vulnerable dependencies in a project and
+// turns them into upgrade stories, so routine maintenance runs through the
+// same loop as feature work. Each ecosystem is handled by a scanner that
+// shells out to the ecosystem's own tooling (go list, govulncheck, npm).
+package deps
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Update is a dependency that should be upgraded.
+type Update struct {
+	Ecosystem  string   // "go" or "npm"
+	Manifest   string   // Manifest file declaring the dependency (e.g. go.mod)
+	Name       string   // Module or package name
+	Current    string   // Version in use
+	Latest     string   // Version to upgrade to (empty if only a fix is known to be needed)
+	Advisories []string // Security advisories affecting Current
+}
+
+// Vulnerable reports whether the dependency has known advisories.
+func (u Update) Vulnerable() bool {
+	return len(u.Advisories) > 0
+}
+
+// scanner checks one ecosystem. It returns nil updates when the project
+// doesn't use the ecosystem, and notes for checks it had to skip.
+type scanner func(dir string) (updates []Update, notes []string, err error)
+
+// scanners are run in order by Scan.
+var scanners = []scanner{scanGo, scanNPM}
+
+// runCommand runs a tool in dir and returns its stdout. Replaced in tests.
+var runCommand = func(dir, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("%s: %w: %s", name, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	// Tools like npm outdated exit non-zero when they find something, so
+	// output is returned whenever there is any.
+	return out, nil
+}
+
+// lookPath reports whether a tool is installed. Replaced in tests.
+var lookPath = func(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// Scan checks every supported manifest in dir and returns the dependencies to
+// upgrade, vulnerable ones first. Notes describe checks that were skipped, for
+// example because a tool isn't installed.
+func Scan(dir string) ([]Update, []string, error) {
+	var all []Update
+	var notes []string
+	for _, scan := range scanners {
+		updates, scanNotes, err := scan(dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		all = append(all, updates...)
+		notes = append(notes, scanNotes...)
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].Vulnerable() != all[j].Vulnerable() {
+			return all[i].Vulnerable()
+		}
+		if all[i].Ecosystem != all[j].Ecosystem {
+			return all[i].Ecosystem < all[j].Ecosystem
+		}
+		return all[i].Name < all[j].Name
+	})
+	return all, notes, nil
+}
+
+// fileExists reports whether name exists in dir.
+func fileExists(dir, name string) bool {
+	_, err := os.Stat(filepath.Join(dir, name))
+	return err == nil
+}
+
+// merge combines outdated and vulnerable findings for the same dependency.
+func merge(outdated []Update, vulnerable []Update) []Update {
+	index := make(map[string]int, len(outdated))
+	result := append([]Update(nil), outdated...)
+	for i, u := range result {
+		index[u.Name] = i
+	}
+	for _, v := range vulnerable {
+		i, ok := index[v.Name]
+		if !ok {
+			index[v.Name] = len(result)
+			result = append(result, v)
+			continue
+		}
+		result[i].Advisories = append(result[i].Advisories, v.Advisories...)
+		if result[i].Latest == "" {
+			result[i].Latest = v.Latest
+		}
+	}
+	return result
+}
+
+// versionLess reports whether version a is older than b. Versions are compared
+// numerically part by part ("v1.9.0" < "v1.10.0"); pre-release suffixes are
+// ignored. An empty version is older than any other.
+func versionLess(a, b string) bool {
+	if a == "" || b == "" {
+		return a == "" && b != ""
+	}
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			return x < y
+		}
+	}
+	return false
+}
+
+// versionParts splits "v1.2.3-rc.1" into [1 2 3].
+func versionParts(v string) []int {
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	var parts []int
+	for _, p := range strings.Split(v, ".") {
+		n, _ := strconv.Atoi(p)
+		parts = append(parts, n)
+	}
+	return parts
+}
@@ -0,0 +1,206 @@
+package deps
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+const goListOutput = `{
+	"Path": "example.com/app",
+	"Main": true
+}
+{
+	"Path": "github.com/fsnotify/fsnotify",
+	"Version": "v1.7.0",
+	"Update": {"Path": "github.com/fsnotify/fsnotify", "Version": "v1.8.0"}
+}
+{
+	"Path": "golang.org/x/sys",
+	"Version": "v0.10.0",
+	"Indirect": true,
+	"Update": {"Path": "golang.org/x/sys", "Version": "v0.20.0"}
+}
+{
+	"Path": "gopkg.in/yaml.v3",
+	"Version": "v3.0.1"
+}
+`
+
+const govulncheckOutput = `{"config": {"scanner_name": "govulncheck"}}
+{"osv": {"id": "GO-2024-0001"}}
+{"finding": {"osv": "GO-2024-0001", "fixed_version": "v0.9.0", "trace": [{"module": "golang.org/x/net", "version": "v0.1.0"}]}}
+{"finding": {"osv": "GO-2024-0001", "fixed_version": "v0.9.0", "trace": [{"module": "golang.org/x/net", "version": "v0.1.0", "function": "Get"}]}}
+{"finding": {"osv": "GO-2024-0002", "fixed_version": "v0.10.0", "trace": [{"module": "golang.org/x/net", "version": "v0.1.0"}]}}
+{"finding": {"osv": "GO-2024-0003", "fixed_version": "v1.22.1", "trace": [{"module": "stdlib", "version": "v1.22.0"}]}}
+`
+
+func TestParseGoList(t *testing.T) {
+	updates, err := parseGoList([]byte(goListOutput))
+	if err != nil {
+		t.Fatalf("parseGoList() error = %v", err)
+	}
+	if len(updates) != 1 {
+		t.Fatalf("expected only the direct outdated dependency, got %+v", updates)
+	}
+	u := updates[0]
+	if u.Name != "github.com/fsnotify/fsnotify" || u.Current != "v1.7.0" || u.Latest != "v1.8.0" || u.Manifest != "go.mod" {
+		t.Errorf("unexpected update: %+v", u)
+	}
+}
+
+func TestParseGovulncheck(t *testing.T) {
+	updates, err := parseGovulncheck([]byte(govulncheckOutput))
+	if err != nil {
+		t.Fatalf("parseGovulncheck() error = %v", err)
+	}
+	if len(updates) != 1 {
+		t.Fatalf("expected one vulnerable module (stdlib skipped), got %+v", updates)
+	}
+	u := updates[0]
+	if u.Name != "golang.org/x/net" || u.Latest != "v0.10.0" {
+		t.Errorf("expected x/net with the highest fixed version, got %+v", u)
+	}
+	if strings.Join(u.Advisories, ",") != "GO-2024-0001,GO-2024-0002" {
+		t.Errorf("expected deduplicated advisories, got %v", u.Advisories)
+	}
+}
+
+func TestParseNPM(t *testing.T) {
+	outdated, err := parseNPMOutdated([]byte(`{
+		"lodash": {"current": "4.17.20", "wanted": "4.17.21", "latest": "4.17.21"},
+		"left-pad": {"wanted": "1.3.0", "latest": "1.3.0"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outdated) != 1 || outdated[0].Name != "lodash" || outdated[0].Latest != "4.17.21" {
+		t.Errorf("unexpected outdated packages: %+v", outdated)
+	}
+
+	vulnerable, err := parseNPMAudit([]byte(`{"vulnerabilities": {
+		"lodash": {"severity": "high", "via": [{"title": "Prototype Pollution", "url": "https://example.com/1"}]},
+		"express": {"severity": "moderate", "via": ["qs"]}
+	}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vulnerable) != 1 || vulnerable[0].Advisories[0] != "high: Prototype Pollution (https://example.com/1)" {
+		t.Errorf("expected only lodash's own advisory, got %+v", vulnerable)
+	}
+
+	merged := merge(outdated, vulnerable)
+	if len(merged) != 1 || !merged[0].Vulnerable() || merged[0].Latest != "4.17.21" {
+		t.Errorf("expected findings to merge into one update, got %+v", merged)
+	}
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	origRun, origLook := runCommand, lookPath
+	defer func() { runCommand, lookPath = origRun, origLook }()
+	lookPath = func(name string) bool { return name == "govulncheck" }
+	runCommand = func(dir, name string, args ...string) ([]byte, error) {
+		switch name {
+		case "go":
+			return []byte(goListOutput), nil
+		case "govulncheck":
+			return []byte(govulncheckOutput), nil
+		}
+		return nil, fmt.Errorf("unexpected command %s", name)
+	}
+
+	updates, notes, err := Scan(dir)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(notes) != 0 {
+		t.Errorf("unexpected notes: %v", notes)
+	}
+	if len(updates) != 2 || updates[0].Name != "golang.org/x/net" || updates[1].Name != "github.com/fsnotify/fsnotify" {
+		t.Errorf("expected vulnerable module first, got %+v", updates)
+	}
+
+	lookPath = func(string) bool { return false }
+	_, notes, err = Scan(dir)
+	if err != nil || len(notes) != 1 {
+		t.Errorf("expected a note about the missing govulncheck, got %v (%v)", notes, err)
+	}
+}
+
+func TestAddStories(t *testing.T) {
+	prdPath := filepath.Join(t.TempDir(), "deps", "prd.md")
+	updates := []Update{
+		{Ecosystem: "go", Manifest: "go.mod", Name: "golang.org/x/net", Current: "v0.1.0", Latest: "v0.10.0", Advisories: []string{"GO-2024-0001"}},
+		{Ecosystem: "npm", Manifest: "package.json", Name: "lodash", Current: "4.17.20", Latest: "4.17.21"},
+	}
+
+	added, err := AddStories(prdPath, updates)
+	if err != nil {
+		t.Fatalf("AddStories() error = %v", err)
+	}
+	if len(added) != 2 {
+		t.Fatalf("expected 2 stories, got %v", added)
+	}
+
+	p, err := prd.LoadPRD(prdPath)
+	if err != nil {
+		t.Fatalf("generated PRD does not parse: %v", err)
+	}
+	if p.Project != "Dependency Updates" || len(p.UserStories) != 2 {
+		t.Fatalf("unexpected PRD: %+v", p)
+	}
+	first := p.UserStories[0]
+	if first.ID != "DEP-001" || first.Title != "Upgrade golang.org/x/net to v0.10.0" || first.Priority != 1 {
+		t.Errorf("unexpected first story: %+v", first)
+	}
+	criteria := strings.Join(first.AcceptanceCriteria, "\n")
+	for _, want := range []string{"Tests pass", "Changelog", "govulncheck"} {
+		if !strings.Contains(criteria, want) {
+			t.Errorf("expected acceptance criteria to mention %q, got:\n%s", want, criteria)
+		}
+	}
+
+	// A second scan only adds dependencies without an open story
+	if err := prd.SetStoryStatus(prdPath, "DEP-002", "done"); err != nil {
+		t.Fatal(err)
+	}
+	added, err = AddStories(prdPath, append(updates, Update{Ecosystem: "go", Manifest: "go.mod", Name: "gopkg.in/yaml.v3", Current: "v3.0.0", Latest: "v3.0.1"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(added, ",") != "Upgrade lodash to 4.17.21,Upgrade gopkg.in/yaml.v3 to v3.0.1" {
+		t.Errorf("unexpected stories added on rescan: %v", added)
+	}
+	p, _ = prd.LoadPRD(prdPath)
+	if len(p.UserStories) != 4 || p.UserStories[3].ID != "DEP-004" || p.UserStories[3].Priority != 4 {
+		t.Errorf("expected IDs and priorities to continue, got %+v", p.UserStories)
+	}
+}
+
+func TestVersionLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"v1.9.0", "v1.10.0", true},
+		{"v1.10.0", "v1.9.0", false},
+		{"v1.2.3", "v1.2.3", false},
+		{"", "v0.0.1", true},
+		{"v0.0.1", "", false},
+		{"v2.0.0-rc.1", "v2.0.1", true},
+	}
+	for _, tt := range tests {
+		if got := versionLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("versionLess(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package deps
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// goModule is an entry from `go list -m -u -json all`.
+type goModule struct {
+	Path     string
+	Version  string
+	Main     bool
+	Indirect bool
+	Update   *struct {
+		Version string
+	}
+}
+
+// govulnMessage is an entry from `govulncheck -json`. Only findings matter.
+type govulnMessage struct {
+	Finding *struct {
+		OSV          string `json:"osv"`
+		FixedVersion string `json:"fixed_version"`
+		Trace        []struct {
+			Module  string `json:"module"`
+			Version string `json:"version"`
+		} `json:"trace"`
+	} `json:"finding"`
+}
+
+// scanGo checks go.mod for direct dependencies with newer versions and, when
+// govulncheck is installed, for modules with known vulnerabilities.
+func scanGo(dir string) ([]Update, []string, error) {
+	if !fileExists(dir, "go.mod") {
+		return nil, nil, nil
+	}
+
+	out, err := runCommand(dir, "go", "list", "-m", "-u", "-json", "all")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list Go modules: %w", err)
+	}
+	outdated, err := parseGoList(out)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !lookPath("govulncheck") {
+		return outdated, []string{"govulncheck not installed; skipped the Go vulnerability check"}, nil
+	}
+	out, err = runCommand(dir, "govulncheck", "-json", "./...")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to run govulncheck: %w", err)
+	}
+	vulnerable, err := parseGovulncheck(out)
+	if err != nil {
+		return nil, nil, err
+	}
+	return merge(outdated, vulnerable), nil, nil
+}
+
+// parseGoList returns the direct dependencies that have an update available.
+func parseGoList(data []byte) ([]Update, error) {
+	var updates []Update
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var m goModule
+		if err := dec.Decode(&m); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse go list output: %w", err)
+		}
+		if m.Main || m.Indirect || m.Update == nil {
+			continue
+		}
+		updates = append(updates, Update{
+			Ecosystem: "go",
+			Manifest:  "go.mod",
+			Name:      m.Path,
+			Current:   m.Version,
+			Latest:    m.Update.Version,
+		})
+	}
+	return updates, nil
+}
+
+// parseGovulncheck returns one Update per vulnerable module. The standard
+// library is skipped since upgrading it means upgrading Go itself.
+func parseGovulncheck(data []byte) ([]Update, error) {
+	var updates []Update
+	index := make(map[string]int)
+	seen := make(map[string]bool)
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var msg govulnMessage
+		if err := dec.Decode(&msg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse govulncheck output: %w", err)
+		}
+		f := msg.Finding
+		if f == nil || len(f.Trace) == 0 || f.Trace[0].Module == "stdlib" {
+			continue
+		}
+
+		module := f.Trace[0].Module
+		if seen[module+" "+f.OSV] {
+			continue
+		}
+		seen[module+" "+f.OSV] = true
+
+		i, ok := index[module]
+		if !ok {
+			i = len(updates)
+			index[module] = i
+			updates = append(updates, Update{
+				Ecosystem: "go",
+				Manifest:  "go.mod",
+				Name:      module,
+				Current:   f.Trace[0].Version,
+			})
+		}
+		updates[i].Advisories = append(updates[i].Advisories, f.OSV)
+		if versionLess(updates[i].Latest, f.FixedVersion) {
+			updates[i].Latest = f.FixedVersion
+		}
+	}
+	return updates, nil
+}
@@ -0,0 +1,124 @@
+package deps
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// npmOutdated is a value from `npm outdated --json`, keyed by package name.
+type npmOutdated struct {
+	Current string `json:"current"`
+	Latest  string `json:"latest"`
+}
+
+// npmAudit is the output of `npm audit --json` (npm 7 and later).
+type npmAudit struct {
+	Vulnerabilities map[string]struct {
+		Severity string            `json:"severity"`
+		Via      []json.RawMessage `json:"via"`
+	} `json:"vulnerabilities"`
+}
+
+// npmAdvisory is an entry in a vulnerability's "via" list. Entries that are
+// plain strings name another vulnerable package and are skipped.
+type npmAdvisory struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// scanNPM checks package.json for outdated and vulnerable packages.
+func scanNPM(dir string) ([]Update, []string, error) {
+	if !fileExists(dir, "package.json") {
+		return nil, nil, nil
+	}
+	if !lookPath("npm") {
+		return nil, []string{"npm not installed; skipped package.json"}, nil
+	}
+
+	out, err := runCommand(dir, "npm", "outdated", "--json")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to run npm outdated: %w", err)
+	}
+	outdated, err := parseNPMOutdated(out)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out, err = runCommand(dir, "npm", "audit", "--json")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to run npm audit: %w", err)
+	}
+	vulnerable, err := parseNPMAudit(out)
+	if err != nil {
+		return nil, nil, err
+	}
+	return merge(outdated, vulnerable), nil, nil
+}
+
+// parseNPMOutdated returns installed packages with a newer release.
+func parseNPMOutdated(data []byte) ([]Update, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var pkgs map[string]npmOutdated
+	if err := json.Unmarshal(data, &pkgs); err != nil {
+		return nil, fmt.Errorf("failed to parse npm outdated output: %w", err)
+	}
+
+	var updates []Update
+	for name, pkg := range pkgs {
+		// Packages that aren't installed have no current version to upgrade from
+		if pkg.Current == "" || pkg.Current == pkg.Latest {
+			continue
+		}
+		updates = append(updates, Update{
+			Ecosystem: "npm",
+			Manifest:  "package.json",
+			Name:      name,
+			Current:   pkg.Current,
+			Latest:    pkg.Latest,
+		})
+	}
+	sort.Slice(updates, func(i, j int) bool { return updates[i].Name < updates[j].Name })
+	return updates, nil
+}
+
+// parseNPMAudit returns packages with their own advisories. Packages that are
+// only vulnerable through a dependency are left to that dependency's story.
+func parseNPMAudit(data []byte) ([]Update, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var audit npmAudit
+	if err := json.Unmarshal(data, &audit); err != nil {
+		return nil, fmt.Errorf("failed to parse npm audit output: %w", err)
+	}
+
+	var updates []Update
+	for name, vuln := range audit.Vulnerabilities {
+		var advisories []string
+		for _, raw := range vuln.Via {
+			var adv npmAdvisory
+			if json.Unmarshal(raw, &adv) != nil || adv.Title == "" {
+				continue
+			}
+			text := fmt.Sprintf("%s: %s", vuln.Severity, adv.Title)
+			if adv.URL != "" {
+				text += " (" + adv.URL + ")"
+			}
+			advisories = append(advisories, text)
+		}
+		if len(advisories) == 0 {
+			continue
+		}
+		updates = append(updates, Update{
+			Ecosystem:  "npm",
+			Manifest:   "package.json",
+			Name:       name,
+			Advisories: advisories,
+		})
+	}
+	sort.Slice(updates, func(i, j int) bool { return updates[i].Name < updates[j].Name })
+	return updates, nil
+}
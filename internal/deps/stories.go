@@ -0,0 +1,152 @@
+package deps
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// storyIDPrefix is used for stories in a PRD created by AddStories.
+const storyIDPrefix = "DEP"
+
+// prdHeader starts a new dependency PRD.
+const prdHeader = `# PRD: Dependency Updates
+
+## Introduction
+
+Routine dependency maintenance found by ` + "`chief deps scan`" + `. Each story upgrades one dependency. Vulnerable dependencies come first.
+
+## User Stories
+`
+
+// storyIDNumberRegex extracts the number from a story ID like "DEP-007".
+var storyIDNumberRegex = regexp.MustCompile(`-(\d+)$`)
+
+// StoryTitle returns the title of the upgrade story for u. It doubles as the
+// key used to avoid adding the same story twice.
+func StoryTitle(u Update) string {
+	if u.Latest == "" {
+		return fmt.Sprintf("Upgrade %s to a fixed version", u.Name)
+	}
+	return fmt.Sprintf("Upgrade %s to %s", u.Name, u.Latest)
+}
+
+// AddStories appends an upgrade story for each update to the PRD at prdPath,
+// creating the PRD if it doesn't exist. Dependencies that already have an
+// unfinished upgrade story are skipped. It returns the titles of the stories
+// that were added.
+func AddStories(prdPath string, updates []Update) ([]string, error) {
+	content := prdHeader
+	var existing *prd.PRD
+	if data, err := os.ReadFile(prdPath); err == nil {
+		content = string(data)
+		existing, err = prd.ParseMarkdownPRDFromString(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", prdPath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read PRD: %w", err)
+	}
+
+	prefix, nextID, priority := storyIDPrefix, 1, 0
+	pending := make(map[string]bool)
+	if existing != nil {
+		if len(existing.UserStories) > 0 {
+			prefix = existing.ExtractIDPrefix()
+		}
+		for _, story := range existing.UserStories {
+			if m := storyIDNumberRegex.FindStringSubmatch(story.ID); m != nil {
+				if n, _ := strconv.Atoi(m[1]); n >= nextID {
+					nextID = n + 1
+				}
+			}
+			if int(story.Priority) > priority {
+				priority = int(story.Priority)
+			}
+			if !story.Passes {
+				pending[dependencyFromTitle(story.Title)] = true
+			}
+		}
+	}
+
+	var added []string
+	var b strings.Builder
+	for _, u := range updates {
+		if pending[u.Name] {
+			continue
+		}
+		pending[u.Name] = true
+		priority++
+		b.WriteString("\n")
+		b.WriteString(renderStory(fmt.Sprintf("%s-%03d", prefix, nextID), priority, u))
+		added = append(added, StoryTitle(u))
+		nextID++
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(prdPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create PRD directory: %w", err)
+	}
+	content = strings.TrimRight(content, "\n") + "\n" + b.String()
+	if err := os.WriteFile(prdPath, []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write PRD: %w", err)
+	}
+	return added, nil
+}
+
+// dependencyFromTitle returns the dependency name from an upgrade story title,
+// or "" if the title isn't one.
+func dependencyFromTitle(title string) string {
+	rest, ok := strings.CutPrefix(title, "Upgrade ")
+	if !ok {
+		return ""
+	}
+	name, _, _ := strings.Cut(rest, " to ")
+	return name
+}
+
+// renderStory renders the markdown for one upgrade story.
+func renderStory(id string, priority int, u Update) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### %s: %s\n", id, StoryTitle(u))
+	fmt.Fprintf(&b, "**Priority:** %d\n", priority)
+
+	current := u.Current
+	if current == "" {
+		current = "the installed version"
+	}
+	target := u.Latest
+	if target == "" {
+		target = "a fixed release"
+	}
+	desc := fmt.Sprintf("As a maintainer, I want %s (%s) upgraded from %s to %s so the project stays current.", u.Name, u.Manifest, current, target)
+	if u.Vulnerable() {
+		desc = fmt.Sprintf("As a maintainer, I want %s (%s) upgraded from %s to %s to fix: %s.", u.Name, u.Manifest, current, target, strings.Join(u.Advisories, "; "))
+	}
+	fmt.Fprintf(&b, "**Description:** %s\n\n", desc)
+
+	b.WriteString("**Acceptance Criteria:**\n")
+	if u.Latest != "" {
+		fmt.Fprintf(&b, "- [ ] %s is at %s in %s (and its lockfile, if any)\n", u.Name, u.Latest, u.Manifest)
+	} else {
+		fmt.Fprintf(&b, "- [ ] %s is upgraded to a release that fixes the advisories\n", u.Name)
+	}
+	fmt.Fprintf(&b, "- [ ] Changelog between %s and the new version reviewed; breaking changes handled\n", current)
+	if u.Vulnerable() {
+		switch u.Ecosystem {
+		case "go":
+			b.WriteString("- [ ] govulncheck no longer reports these advisories\n")
+		case "npm":
+			b.WriteString("- [ ] npm audit no longer reports these advisories\n")
+		}
+	}
+	b.WriteString("- [ ] Tests pass\n")
+	return b.String()
+}
@@ -0,0 +1,87 @@
+package forge
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Review comment kinds.
+const (
+	KindComment = "comment" // An inline comment on a line of the diff
+	KindReview  = "review"  // The summary of a submitted review
+)
+
+// ReviewComment is feedback left on a pull request.
+type ReviewComment struct {
+	ID        int64
+	Kind      string // One of the Kind constants
+	Author    string
+	Body      string
+	Path      string // File commented on (inline comments only)
+	Line      int    // Line commented on (0 = outdated or not inline)
+	URL       string
+	InReplyTo int64  // Comment this one replies to (0 = starts a thread)
+	State     string // Review state, e.g. CHANGES_REQUESTED (reviews only)
+}
+
+// Key identifies the comment across polls, e.g. "comment-1234".
+func (c ReviewComment) Key() string {
+	return fmt.Sprintf("%s-%d", c.Kind, c.ID)
+}
+
+// ghComment is a pull request comment or review as returned by the GitHub API.
+type ghComment struct {
+	ID        int64  `json:"id"`
+	Body      string `json:"body"`
+	Path      string `json:"path"`
+	Line      int    `json:"line"`
+	URL       string `json:"html_url"`
+	InReplyTo int64  `json:"in_reply_to_id"`
+	State     string `json:"state"`
+	User      struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+// ReviewComments returns the inline comments and the review summaries left on
+// pull request number, oldest first within each kind. Reviews without a
+// summary are left out.
+func ReviewComments(dir string, number int) ([]ReviewComment, error) {
+	var comments []ReviewComment
+	for _, kind := range []string{KindComment, KindReview} {
+		endpoint := fmt.Sprintf("repos/{owner}/{repo}/pulls/%d/comments", number)
+		if kind == KindReview {
+			endpoint = fmt.Sprintf("repos/{owner}/{repo}/pulls/%d/reviews", number)
+		}
+		out, err := runGH(dir, "api", "--paginate", "--jq", ".[]", endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list review comments: %s", strings.TrimSpace(string(out)))
+		}
+		dec := json.NewDecoder(strings.NewReader(string(out)))
+		for {
+			var c ghComment
+			if err := dec.Decode(&c); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to parse review comments: %w", err)
+			}
+			if strings.TrimSpace(c.Body) == "" {
+				continue
+			}
+			comments = append(comments, ReviewComment{
+				ID:        c.ID,
+				Kind:      kind,
+				Author:    c.User.Login,
+				Body:      c.Body,
+				Path:      c.Path,
+				Line:      c.Line,
+				URL:       c.URL,
+				InReplyTo: c.InReplyTo,
+				State:     c.State,
+			})
+		}
+	}
+	return comments, nil
+}
@@ -0,0 +1,50 @@
+package forge
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReviewComments(t *testing.T) {
+	orig := runGH
+	t.Cleanup(func() { runGH = orig })
+	runGH = func(dir string, args ...string) ([]byte, error) {
+		switch endpoint := args[len(args)-1]; {
+		case strings.HasSuffix(endpoint, "/pulls/7/comments"):
+			return []byte(`{"id":11,"body":"Handle the nil case","path":"auth.go","line":42,"html_url":"https://github.com/o/r/pull/7#discussion_r11","user":{"login":"ana"}}
+{"id":12,"body":"Done","in_reply_to_id":11,"user":{"login":"bot"}}
+`), nil
+		case strings.HasSuffix(endpoint, "/pulls/7/reviews"):
+			return []byte(`{"id":21,"body":"","state":"APPROVED","user":{"login":"ana"}}
+{"id":22,"body":"Please add tests","state":"CHANGES_REQUESTED","user":{"login":"ana"}}
+`), nil
+		}
+		return nil, errors.New("unexpected endpoint")
+	}
+
+	comments, err := ReviewComments(".", 7)
+	if err != nil {
+		t.Fatalf("ReviewComments() error = %v", err)
+	}
+	if len(comments) != 3 {
+		t.Fatalf("expected 3 comments, got %+v", comments)
+	}
+	first := comments[0]
+	if first.Key() != "comment-11" || first.Author != "ana" || first.Path != "auth.go" || first.Line != 42 {
+		t.Errorf("unexpected first comment: %+v", first)
+	}
+	if comments[1].InReplyTo != 11 {
+		t.Errorf("expected the reply to point at 11, got %+v", comments[1])
+	}
+	if review := comments[2]; review.Key() != "review-22" || review.State != "CHANGES_REQUESTED" {
+		t.Errorf("unexpected review: %+v", review)
+	}
+
+	runGH = func(dir string, args ...string) ([]byte, error) {
+		return []byte("HTTP 404: Not Found"), errors.New("exit status 1")
+	}
+	if _, err := ReviewComments(".", 7); err == nil || !strings.Contains(err.Error(), "Not Found") {
+		t.Errorf("expected gh output in the error, got %v", err)
+	}
+}
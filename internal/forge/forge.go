@@ -0,0 +1,70 @@
+// Package forge talks to the code host a project's branches are pushed to.
+// GitHub is supported through the gh CLI, which handles authentication and
+// resolves the repository from the working directory's remote.
+package forge
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Commit status states.
+const (
+	StatePending = "pending"
+	StateSuccess = "success"
+	StateFailure = "failure"
+	StateError   = "error"
+)
+
+// maxDescriptionLength is GitHub's limit for a commit status description.
+const maxDescriptionLength = 140
+
+// Status is a commit status to post.
+type Status struct {
+	SHA         string
+	State       string // One of the State constants
+	Context     string // Identifies the status, e.g. "chief/auth"
+	Description string
+	TargetURL   string // Optional link shown with the status
+}
+
+// runGH runs gh in dir (replaceable in tests).
+var runGH = func(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("gh", args...)
+	cmd.Dir = dir
+	return cmd.CombinedOutput()
+}
+
+// SetCommitStatus posts a commit status for the repository in dir.
+func SetCommitStatus(dir string, s Status) error {
+	if s.SHA == "" {
+		return fmt.Errorf("commit status requires a SHA")
+	}
+	description := s.Description
+	if runes := []rune(description); len(runes) > maxDescriptionLength {
+		description = string(runes[:maxDescriptionLength-1]) + "…"
+	}
+	args := []string{"api", "--method", "POST", "repos/{owner}/{repo}/statuses/" + s.SHA,
+		"-f", "state=" + s.State,
+		"-f", "context=" + s.Context,
+		"-f", "description=" + description,
+	}
+	if s.TargetURL != "" {
+		args = append(args, "-f", "target_url="+s.TargetURL)
+	}
+	if out, err := runGH(dir, args...); err != nil {
+		return fmt.Errorf("failed to set commit status: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// PushedHead returns the commit the current branch's upstream points to, or
+// "" if the branch hasn't been pushed.
+func PushedHead(dir string) string {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--verify", "-q", "@{upstream}").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,49 @@
+package forge
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSetCommitStatus(t *testing.T) {
+	var got []string
+	orig := runGH
+	t.Cleanup(func() { runGH = orig })
+	runGH = func(dir string, args ...string) ([]byte, error) {
+		got = args
+		return nil, nil
+	}
+
+	err := SetCommitStatus(".", Status{SHA: "abc123", State: StatePending, Context: "chief/auth", Description: strings.Repeat("x", 200)})
+	if err != nil {
+		t.Fatalf("SetCommitStatus() error = %v", err)
+	}
+	joined := strings.Join(got, " ")
+	for _, want := range []string{"repos/{owner}/{repo}/statuses/abc123", "state=pending", "context=chief/auth"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected %q in gh args %q", want, joined)
+		}
+	}
+	for _, arg := range got {
+		if desc, ok := strings.CutPrefix(arg, "description="); ok && len([]rune(desc)) != maxDescriptionLength {
+			t.Errorf("expected description truncated to %d runes, got %d", maxDescriptionLength, len([]rune(desc)))
+		}
+	}
+
+	runGH = func(dir string, args ...string) ([]byte, error) {
+		return []byte("HTTP 422: No commit found for SHA"), errors.New("exit status 1")
+	}
+	if err := SetCommitStatus(".", Status{SHA: "abc123", State: StateSuccess}); err == nil || !strings.Contains(err.Error(), "No commit found") {
+		t.Errorf("expected gh output in the error, got %v", err)
+	}
+	if err := SetCommitStatus(".", Status{}); err == nil {
+		t.Error("expected error without a SHA")
+	}
+}
+
+func TestPushedHead_NoUpstream(t *testing.T) {
+	if sha := PushedHead(t.TempDir()); sha != "" {
+		t.Errorf("expected no pushed head outside a repository, got %q", sha)
+	}
+}
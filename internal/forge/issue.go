@@ -0,0 +1,23 @@
+package forge
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxIssueBodyLength keeps issue bodies under GitHub's 65536 character limit.
+const maxIssueBodyLength = 60000
+
+// CreateIssue opens an issue in the repository of dir and returns its URL.
+// Bodies over GitHub's limit are cut short.
+func CreateIssue(dir, title, body string) (string, error) {
+	if len(body) > maxIssueBodyLength {
+		body = body[:maxIssueBodyLength] + "\n\n…(truncated)"
+	}
+	out, err := runGH(dir, "issue", "create", "--title", title, "--body", body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create issue: %s", strings.TrimSpace(string(out)))
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1]), nil
+}
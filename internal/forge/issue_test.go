@@ -0,0 +1,38 @@
+package forge
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCreateIssue(t *testing.T) {
+	var got []string
+	orig := runGH
+	t.Cleanup(func() { runGH = orig })
+	runGH = func(dir string, args ...string) ([]byte, error) {
+		got = args
+		return []byte("Creating issue in o/r\n\nhttps://github.com/o/r/issues/12\n"), nil
+	}
+
+	url, err := CreateIssue(".", "chief: auth paused", strings.Repeat("x", maxIssueBodyLength+10))
+	if err != nil {
+		t.Fatalf("CreateIssue() error = %v", err)
+	}
+	if url != "https://github.com/o/r/issues/12" {
+		t.Errorf("CreateIssue() = %q, want the issue URL", url)
+	}
+	if got[0] != "issue" || got[1] != "create" || got[3] != "chief: auth paused" {
+		t.Errorf("unexpected gh args %q", got[:4])
+	}
+	if body := got[len(got)-1]; !strings.HasSuffix(body, "(truncated)") {
+		t.Error("expected the long body to be truncated")
+	}
+
+	runGH = func(dir string, args ...string) ([]byte, error) {
+		return []byte("gh: To use GitHub CLI, please authenticate"), errors.New("exit status 4")
+	}
+	if _, err := CreateIssue(".", "t", "b"); err == nil || !strings.Contains(err.Error(), "authenticate") {
+		t.Errorf("expected gh output in the error, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package forge
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Pull request review decisions, as reported by GitHub.
+const (
+	ReviewApproved         = "APPROVED"
+	ReviewChangesRequested = "CHANGES_REQUESTED"
+	ReviewRequired         = "REVIEW_REQUIRED"
+)
+
+// PullRequest is the state of an existing pull request.
+type PullRequest struct {
+	Number         int    `json:"number"`
+	URL            string `json:"url"`
+	State          string `json:"state"`          // OPEN, CLOSED or MERGED
+	ReviewDecision string `json:"reviewDecision"` // One of the Review constants, or "" when no review is required
+}
+
+// IsOpen reports whether the pull request is still open.
+func (pr *PullRequest) IsOpen() bool {
+	return pr.State == "OPEN"
+}
+
+// CreatePullRequest opens a pull request from head into base and returns its URL.
+func CreatePullRequest(dir, head, base, title, body string) (string, error) {
+	out, err := runGH(dir, "pr", "create",
+		"--head", head,
+		"--base", base,
+		"--title", title,
+		"--body", body,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create PR: %s", strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// FindPullRequest returns the most recent pull request opened from branch
+// head, or nil if there is none.
+func FindPullRequest(dir, head string) (*PullRequest, error) {
+	out, err := runGH(dir, "pr", "view", head, "--json", "number,url,state,reviewDecision")
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if strings.Contains(msg, "no pull requests found") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to look up PR for %s: %s", head, msg)
+	}
+	var pr PullRequest
+	if err := json.Unmarshal(out, &pr); err != nil {
+		return nil, fmt.Errorf("failed to parse PR for %s: %w", head, err)
+	}
+	return &pr, nil
+}
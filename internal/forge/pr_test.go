@@ -0,0 +1,85 @@
+package forge
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCreatePullRequest(t *testing.T) {
+	var got []string
+	orig := runGH
+	t.Cleanup(func() { runGH = orig })
+	runGH = func(dir string, args ...string) ([]byte, error) {
+		got = args
+		return []byte("https://github.com/o/r/pull/7\n"), nil
+	}
+
+	url, err := CreatePullRequest(".", "chief/auth-us-002", "chief/auth-us-001", "feat(auth): US-002", "body")
+	if err != nil {
+		t.Fatalf("CreatePullRequest() error = %v", err)
+	}
+	if url != "https://github.com/o/r/pull/7" {
+		t.Errorf("url = %q", url)
+	}
+	joined := strings.Join(got, " ")
+	for _, want := range []string{"pr create", "--head chief/auth-us-002", "--base chief/auth-us-001"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected %q in gh args %q", want, joined)
+		}
+	}
+}
+
+func TestFindPullRequest(t *testing.T) {
+	orig := runGH
+	t.Cleanup(func() { runGH = orig })
+
+	tests := []struct {
+		name    string
+		out     string
+		err     error
+		want    *PullRequest
+		wantErr bool
+	}{
+		{
+			name: "open with changes requested",
+			out:  `{"number":7,"url":"https://github.com/o/r/pull/7","state":"OPEN","reviewDecision":"CHANGES_REQUESTED"}`,
+			want: &PullRequest{Number: 7, URL: "https://github.com/o/r/pull/7", State: "OPEN", ReviewDecision: ReviewChangesRequested},
+		},
+		{
+			name: "no pull request",
+			out:  `no pull requests found for branch "chief/auth-us-001"`,
+			err:  errors.New("exit status 1"),
+		},
+		{
+			name:    "gh failure",
+			out:     "gh: To use GitHub CLI, please authenticate",
+			err:     errors.New("exit status 4"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runGH = func(dir string, args ...string) ([]byte, error) {
+				return []byte(tt.out), tt.err
+			}
+			got, err := FindPullRequest(".", "chief/auth-us-001")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindPullRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("FindPullRequest() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || *got != *tt.want {
+				t.Errorf("FindPullRequest() = %+v, want %+v", got, tt.want)
+			}
+			if !got.IsOpen() {
+				t.Error("expected pull request to be open")
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ResolveCommit returns the full hash of the commit ref points to. Tags are
+// peeled, so an annotated tag resolves to the commit it marks.
+func ResolveCommit(dir, ref string) (string, error) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("unknown ref %q", ref)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// DirtyFiles returns the working tree's uncommitted changes, one `git status
+// --porcelain` line each, ignoring .chief/. An empty list means the tree is clean.
+func DirtyFiles(dir string) ([]string, error) {
+	out, err := exec.Command("git", "-C", dir, "status", "--porcelain", "--", ":/", ":(top,exclude).chief").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git status: %w", err)
+	}
+	var files []string
+	for _, line := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// IsAncestor returns true if commit is reachable from ref.
+func IsAncestor(dir, commit, ref string) bool {
+	return exec.Command("git", "-C", dir, "merge-base", "--is-ancestor", commit, ref).Run() == nil
+}
+
+// CheckoutBase switches dir to branch, starting it at base. A branch that
+// already exists is reused only if base is in its history, so resuming a
+// pinned run keeps its commits while a branch built on another base is
+// refused.
+func CheckoutBase(dir, branch, base string) error {
+	exists, err := BranchExists(dir, "refs/heads/"+branch)
+	if err != nil {
+		return err
+	}
+	args := []string{"-C", dir, "checkout", "-b", branch, base}
+	if exists {
+		if !IsAncestor(dir, base, branch) {
+			return fmt.Errorf("branch %s exists and is not based on %s; delete it or pick another base", branch, shortHash(base))
+		}
+		args = []string{"-C", dir, "checkout", branch}
+	}
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to check out %s: %s", branch, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// shortHash abbreviates a commit hash for messages.
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
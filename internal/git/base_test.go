@@ -0,0 +1,86 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveCommit(t *testing.T) {
+	dir := initTestRepo(t)
+	head, err := ResolveCommit(dir, "HEAD")
+	if err != nil {
+		t.Fatalf("ResolveCommit(HEAD) error = %v", err)
+	}
+	if out, err := exec.Command("git", "-C", dir, "tag", "-a", "v1.0.0", "-m", "release").CombinedOutput(); err != nil {
+		t.Fatalf("git tag failed: %s", out)
+	}
+	if got, err := ResolveCommit(dir, "v1.0.0"); err != nil || got != head {
+		t.Errorf("ResolveCommit(v1.0.0) = %q, %v; want the tagged commit %q", got, err, head)
+	}
+	if _, err := ResolveCommit(dir, "v9.9.9"); err == nil {
+		t.Error("expected an error for an unknown ref")
+	}
+}
+
+func TestDirtyFiles(t *testing.T) {
+	dir := initTestRepo(t)
+	if files, err := DirtyFiles(dir); err != nil || len(files) != 0 {
+		t.Fatalf("DirtyFiles() = %v, %v; want a clean tree", files, err)
+	}
+
+	os.MkdirAll(filepath.Join(dir, ".chief"), 0755)
+	os.WriteFile(filepath.Join(dir, ".chief", "config.yaml"), []byte("{}\n"), 0644)
+	if files, _ := DirtyFiles(dir); len(files) != 0 {
+		t.Errorf("expected .chief/ to be ignored, got %v", files)
+	}
+
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Changed\n"), 0644)
+	if files, _ := DirtyFiles(dir); len(files) != 1 {
+		t.Errorf("expected the changed README, got %v", files)
+	}
+}
+
+func TestCheckoutBase(t *testing.T) {
+	dir := initTestRepo(t)
+	base, _ := ResolveCommit(dir, "HEAD")
+
+	commit := func(name string) {
+		t.Helper()
+		os.WriteFile(filepath.Join(dir, name), []byte(name), 0644)
+		for _, args := range [][]string{{"add", name}, {"commit", "-m", name}} {
+			if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+				t.Fatalf("git %v failed: %s", args, out)
+			}
+		}
+	}
+	commit("later.txt")
+
+	if err := CheckoutBase(dir, "chief/auth", base); err != nil {
+		t.Fatalf("CheckoutBase() error = %v", err)
+	}
+	if branch, _ := GetCurrentBranch(dir); branch != "chief/auth" {
+		t.Errorf("expected chief/auth to be checked out, got %s", branch)
+	}
+	if head, _ := ResolveCommit(dir, "HEAD"); head != base {
+		t.Errorf("expected HEAD at the base %s, got %s", base, head)
+	}
+
+	// Resuming keeps the branch's own commits
+	commit("story.txt")
+	exec.Command("git", "-C", dir, "checkout", "main").Run()
+	if err := CheckoutBase(dir, "chief/auth", base); err != nil {
+		t.Fatalf("CheckoutBase() on an existing branch error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "story.txt")); err != nil {
+		t.Error("expected the existing branch to be reused")
+	}
+
+	// A branch that doesn't contain the base is refused
+	later, _ := ResolveCommit(dir, "main")
+	exec.Command("git", "-C", dir, "checkout", "main").Run()
+	if err := CheckoutBase(dir, "chief/auth", later); err == nil {
+		t.Error("expected an error for a branch built on another base")
+	}
+}
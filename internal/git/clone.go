@@ -0,0 +1,76 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// CloneOptions controls how much of a repository Clone fetches.
+type CloneOptions struct {
+	URL          string
+	Dir          string   // Destination (default: derived from URL, like git clone)
+	Depth        int      // Fetch only the last Depth commits (0 = full history)
+	Branch       string   // Branch to check out (default: the remote's HEAD)
+	SingleBranch bool     // Fetch only Branch, or the remote's HEAD
+	SparsePaths  []string // Check out only these directories (empty = everything)
+	Output       io.Writer
+}
+
+// Clone clones a repository. Depth and SingleBranch keep history small;
+// SparsePaths fetches file contents lazily and checks out only the listed
+// directories, which makes large monorepos practical to work in.
+func Clone(opts CloneOptions) error {
+	if opts.URL == "" {
+		return fmt.Errorf("repository URL is required")
+	}
+	dir := opts.Dir
+	if dir == "" {
+		dir = CloneDir(opts.URL)
+	}
+
+	cmd := exec.Command("git", cloneArgs(opts, dir)...)
+	cmd.Stdout = opts.Output
+	cmd.Stderr = opts.Output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git clone failed: %w", err)
+	}
+
+	if len(opts.SparsePaths) > 0 {
+		args := append([]string{"-C", dir, "sparse-checkout", "set", "--"}, opts.SparsePaths...)
+		out, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to set sparse-checkout paths: %s", strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
+
+// cloneArgs builds the git clone arguments for opts.
+func cloneArgs(opts CloneOptions, dir string) []string {
+	args := []string{"clone", "--progress"}
+	if opts.Depth > 0 {
+		args = append(args, "--depth", fmt.Sprint(opts.Depth))
+	}
+	if opts.Branch != "" {
+		args = append(args, "--branch", opts.Branch)
+	}
+	if opts.SingleBranch {
+		args = append(args, "--single-branch")
+	}
+	if len(opts.SparsePaths) > 0 {
+		args = append(args, "--filter=blob:none", "--sparse")
+	}
+	return append(args, "--", opts.URL, dir)
+}
+
+// CloneDir returns the directory git clone would create for url.
+func CloneDir(url string) string {
+	url = strings.TrimRight(url, "/")
+	if i := strings.LastIndex(url, ":"); i > strings.LastIndex(url, "/") {
+		url = url[i+1:] // git@host:repo.git
+	}
+	return strings.TrimSuffix(path.Base(url), ".git")
+}
@@ -0,0 +1,82 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCloneDir(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://github.com/acme/mono.git", "mono"},
+		{"https://github.com/acme/mono/", "mono"},
+		{"git@github.com:acme/mono.git", "mono"},
+		{"git@host:mono.git", "mono"},
+		{"/srv/repos/mono", "mono"},
+	}
+	for _, tt := range tests {
+		if got := CloneDir(tt.url); got != tt.want {
+			t.Errorf("CloneDir(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestCloneArgs(t *testing.T) {
+	got := cloneArgs(CloneOptions{
+		URL:          "https://example.com/mono.git",
+		Depth:        1,
+		Branch:       "develop",
+		SingleBranch: true,
+		SparsePaths:  []string{"services/api"},
+	}, "mono")
+	want := []string{"clone", "--progress", "--depth", "1", "--branch", "develop", "--single-branch",
+		"--filter=blob:none", "--sparse", "--", "https://example.com/mono.git", "mono"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cloneArgs() = %q, want %q", got, want)
+	}
+}
+
+func TestClone_ShallowSparse(t *testing.T) {
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	src := initTestRepo(t)
+	for _, dir := range []string{"api", "web"} {
+		if err := os.MkdirAll(filepath.Join(src, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(src, dir, "main.txt"), []byte(dir), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{{"add", "."}, {"commit", "-m", "add dirs"}} {
+		if out, err := exec.Command("git", append([]string{"-C", src}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %s", args, out)
+		}
+	}
+
+	dest := filepath.Join(t.TempDir(), "clone")
+	err := Clone(CloneOptions{URL: "file://" + src, Dir: dest, Depth: 1, SparsePaths: []string{"api"}})
+	if err != nil {
+		t.Fatalf("Clone() failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dest, "api", "main.txt")); err != nil {
+		t.Errorf("expected api/ to be checked out: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "web")); !os.IsNotExist(err) {
+		t.Errorf("expected web/ to be left out of the sparse checkout, got %v", err)
+	}
+	out, err := exec.Command("git", "-C", dest, "rev-list", "--count", "HEAD").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := strings.TrimSpace(string(out)); count != "1" {
+		t.Errorf("expected a single commit of history, got %s", count)
+	}
+}
@@ -0,0 +1,123 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// DefaultMaxSubjectLength is the subject line length limit used when none is configured.
+const DefaultMaxSubjectLength = 72
+
+// CommitRules describes what an acceptable commit message looks like.
+type CommitRules struct {
+	MaxSubjectLength int      // Maximum subject line length (0 = DefaultMaxSubjectLength)
+	Prefixes         []string // Allowed conventional prefixes, e.g. "feat" (empty = any)
+	RequireStoryID   bool     // Subject must mention the story being worked on
+}
+
+// Commit is a commit hash with its message.
+type Commit struct {
+	Hash    string
+	Subject string
+}
+
+// HeadCommit returns the commit hash HEAD points to, or an empty string when
+// the repository has no commits yet.
+func HeadCommit(dir string) string {
+	cmd := exec.Command("git", "rev-parse", "--verify", "-q", "HEAD")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// CommitsSince returns the commits reachable from HEAD but not from base,
+// oldest first. An empty base returns every commit on HEAD.
+func CommitsSince(dir, base string) ([]Commit, error) {
+	revRange := "HEAD"
+	if base != "" {
+		revRange = base + "..HEAD"
+	}
+	cmd := exec.Command("git", "log", "--reverse", "--format=%H%x00%s", revRange)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCommits(string(output)), nil
+}
+
+// StoryCommits returns the commits on HEAD whose subject mentions storyID,
+// oldest first. "US-1" does not match a subject that only mentions "US-12".
+func StoryCommits(dir, storyID string) ([]Commit, error) {
+	cmd := exec.Command("git", "log", "--reverse", "--fixed-strings", "--regexp-ignore-case", "--grep="+storyID, "--format=%H%x00%s", "HEAD")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	mentions := regexp.MustCompile(`(?i)(^|[^A-Za-z0-9])` + regexp.QuoteMeta(storyID) + `($|[^0-9])`)
+	var commits []Commit
+	for _, c := range parseCommits(string(output)) {
+		if mentions.MatchString(c.Subject) {
+			commits = append(commits, c)
+		}
+	}
+	return commits, nil
+}
+
+// parseCommits parses `git log --format=%H%x00%s` output.
+func parseCommits(output string) []Commit {
+	var commits []Commit
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		hash, subject, ok := strings.Cut(line, "\x00")
+		if !ok {
+			continue
+		}
+		commits = append(commits, Commit{Hash: hash, Subject: subject})
+	}
+	return commits
+}
+
+// CheckCommitSubject validates a commit subject line against rules and
+// returns a description of each problem found (nil when the subject is fine).
+func CheckCommitSubject(subject, storyID string, rules CommitRules) []string {
+	maxLen := rules.MaxSubjectLength
+	if maxLen <= 0 {
+		maxLen = DefaultMaxSubjectLength
+	}
+
+	var problems []string
+	if n := len([]rune(subject)); n > maxLen {
+		problems = append(problems, fmt.Sprintf("subject is %d characters (max %d)", n, maxLen))
+	}
+
+	if len(rules.Prefixes) > 0 && !hasConventionalPrefix(subject, rules.Prefixes) {
+		problems = append(problems, fmt.Sprintf("subject must start with one of: %s", strings.Join(rules.Prefixes, ", ")))
+	}
+
+	if rules.RequireStoryID && storyID != "" && !strings.Contains(subject, storyID) {
+		problems = append(problems, fmt.Sprintf("subject must mention %s", storyID))
+	}
+
+	return problems
+}
+
+// hasConventionalPrefix reports whether subject starts with "<prefix>:" or
+// "<prefix>(scope):" for one of the allowed prefixes.
+func hasConventionalPrefix(subject string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSuffix(prefix, ":")
+		rest, ok := strings.CutPrefix(subject, prefix)
+		if ok && (strings.HasPrefix(rest, ":") || strings.HasPrefix(rest, "(") || strings.HasPrefix(rest, "!:")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package git
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCheckCommitSubject(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+		rules   CommitRules
+		want    []string // substrings expected in the problems, in order
+	}{
+		{"valid", "feat: US-001 - Add login", CommitRules{Prefixes: []string{"feat", "fix"}, RequireStoryID: true}, nil},
+		{"scoped prefix", "fix(auth): US-001 handle expiry", CommitRules{Prefixes: []string{"fix"}}, nil},
+		{"too long", strings.Repeat("x", 80), CommitRules{}, []string{"80 characters (max 72)"}},
+		{"custom length", "feat: short enough?", CommitRules{MaxSubjectLength: 10}, []string{"max 10"}},
+		{"bad prefix", "wip", CommitRules{Prefixes: []string{"feat:"}}, []string{"must start with one of: feat:"}},
+		{"prefix without separator", "feature: x", CommitRules{Prefixes: []string{"feat"}}, []string{"must start with"}},
+		{"missing story", "feat: add login", CommitRules{RequireStoryID: true}, []string{"must mention US-001"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckCommitSubject(tt.subject, "US-001", tt.rules)
+			if len(got) != len(tt.want) {
+				t.Fatalf("CheckCommitSubject(%q) = %v, want %d problem(s)", tt.subject, got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(got[i], want) {
+					t.Errorf("problem %d = %q, want it to contain %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestCommitsSince(t *testing.T) {
+	dir := initTestRepo(t)
+	base := HeadCommit(dir)
+	if base == "" {
+		t.Fatal("expected HEAD commit")
+	}
+
+	for _, msg := range []string{"first", "second"} {
+		cmd := exec.Command("git", "commit", "--allow-empty", "-m", msg)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git commit failed: %s", out)
+		}
+	}
+
+	commits, err := CommitsSince(dir, base)
+	if err != nil {
+		t.Fatalf("CommitsSince() error = %v", err)
+	}
+	if len(commits) != 2 || commits[0].Subject != "first" || commits[1].Subject != "second" {
+		t.Errorf("expected [first second], got %+v", commits)
+	}
+
+	all, err := CommitsSince(dir, "")
+	if err != nil {
+		t.Fatalf("CommitsSince() error = %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("expected 3 commits from an empty base, got %d", len(all))
+	}
+}
+
+func TestStoryCommits(t *testing.T) {
+	dir := initTestRepo(t)
+	for _, msg := range []string{"feat: US-1 - Login", "feat: US-12 - Logout", "fix(us-1): handle expiry", "chore: bump deps"} {
+		cmd := exec.Command("git", "commit", "--allow-empty", "-m", msg)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git commit failed: %s", out)
+		}
+	}
+
+	commits, err := StoryCommits(dir, "US-1")
+	if err != nil {
+		t.Fatalf("StoryCommits() error = %v", err)
+	}
+	if len(commits) != 2 || commits[0].Subject != "feat: US-1 - Login" || commits[1].Subject != "fix(us-1): handle expiry" {
+		t.Errorf("expected the two US-1 commits oldest first, got %+v", commits)
+	}
+}
+
+func TestHeadCommit_EmptyRepo(t *testing.T) {
+	dir := t.TempDir()
+	cmd := exec.Command("git", "init")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %s", out)
+	}
+	if got := HeadCommit(dir); got != "" {
+		t.Errorf("expected empty HEAD for a repo without commits, got %q", got)
+	}
+}
@@ -2,6 +2,7 @@
 package git
 
 import (
+	"fmt"
 	"os/exec"
 	"strconv"
 	"strings"
@@ -73,56 +74,49 @@ func CommitCount(repoDir, branch string) int {
 // It shows the diff between the current branch and its merge base with the default branch.
 // If on main/master or if merge-base fails, it shows the last few commits' diff.
 func GetDiff(dir string) (string, error) {
-	branch, err := GetCurrentBranch(dir)
+	base, err := DiffBase(dir)
 	if err != nil {
 		return "", err
 	}
-
-	// If on a feature branch, diff against merge-base with main/master
-	if !IsProtectedBranch(branch) {
-		baseBranch, err := GetDefaultBranch(dir)
-		if err == nil && baseBranch != "" {
-			mergeBase, err := getMergeBase(dir, baseBranch, "HEAD")
-			if err == nil && mergeBase != "" {
-				return getDiffOutput(dir, mergeBase, "HEAD")
-			}
-		}
-	}
-
-	// Fallback: show diff of recent commits (last 10)
-	return getDiffOutput(dir, "HEAD~10", "HEAD")
+	return getDiffOutput(dir, base, "HEAD")
 }
 
 // GetDiffStats returns a short diffstat summary.
 func GetDiffStats(dir string) (string, error) {
+	base, err := DiffBase(dir)
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.Command("git", "diff", "--stat", base, "HEAD")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// DiffBase returns the ref GetDiff compares HEAD against: the merge base with
+// the default branch when on a feature branch, otherwise HEAD~10.
+func DiffBase(dir string) (string, error) {
 	branch, err := GetCurrentBranch(dir)
 	if err != nil {
 		return "", err
 	}
 
+	// If on a feature branch, diff against merge-base with main/master
 	if !IsProtectedBranch(branch) {
 		baseBranch, err := GetDefaultBranch(dir)
 		if err == nil && baseBranch != "" {
 			mergeBase, err := getMergeBase(dir, baseBranch, "HEAD")
 			if err == nil && mergeBase != "" {
-				cmd := exec.Command("git", "diff", "--stat", mergeBase, "HEAD")
-				cmd.Dir = dir
-				output, err := cmd.Output()
-				if err != nil {
-					return "", err
-				}
-				return strings.TrimSpace(string(output)), nil
+				return mergeBase, nil
 			}
 		}
 	}
 
-	cmd := exec.Command("git", "diff", "--stat", "HEAD~10", "HEAD")
-	cmd.Dir = dir
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
-	return strings.TrimSpace(string(output)), nil
+	// Fallback: the last 10 commits
+	return "HEAD~10", nil
 }
 
 // GetDiffForCommit returns the diff for a single commit using git show.
@@ -184,3 +178,84 @@ func getDiffOutput(dir, from, to string) (string, error) {
 	}
 	return string(output), nil
 }
+
+// GetUserIdentity returns the git user.name and user.email that commits made
+// in dir would use. Values that are not configured are returned as empty strings.
+func GetUserIdentity(dir string) (name, email string) {
+	return getConfigValue(dir, "user.name"), getConfigValue(dir, "user.email")
+}
+
+// RepoSize returns the size in bytes of the repository's object database
+// (loose and packed objects), as reported by `git count-objects -v`.
+func RepoSize(dir string) (int64, error) {
+	cmd := exec.Command("git", "count-objects", "-v")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, err
+	}
+
+	var totalKiB int64
+	for _, line := range strings.Split(string(output), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok || (key != "size" && key != "size-pack") {
+			continue
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			continue
+		}
+		totalKiB += n
+	}
+	return totalKiB * 1024, nil
+}
+
+// TrackedFiles returns the paths of the files git tracks in dir, relative to
+// dir.
+func TrackedFiles(dir string) ([]string, error) {
+	cmd := exec.Command("git", "ls-files")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// ChangedFiles returns the paths, relative to the repository root, of the
+// files that differ from base: committed since, uncommitted, or untracked.
+// .chief/ is left out.
+func ChangedFiles(dir, base string) ([]string, error) {
+	diff, err := exec.Command("git", "-C", dir, "diff", "--name-only", base, "--", ":/", ":(top,exclude).chief").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff against %s: %w", base, err)
+	}
+	untracked, err := exec.Command("git", "-C", dir, "ls-files", "--others", "--exclude-standard", "--full-name", "--", ":/", ":(top,exclude).chief").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+	var files []string
+	for _, line := range strings.Split(string(diff)+string(untracked), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// getConfigValue returns the value of a git config key for a directory, or an empty string.
+func getConfigValue(dir, key string) string {
+	cmd := exec.Command("git", "config", "--get", key)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
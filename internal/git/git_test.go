@@ -150,3 +150,31 @@ func TestIsProtectedBranch(t *testing.T) {
 		})
 	}
 }
+
+func TestGetUserIdentity(t *testing.T) {
+	dir := initTestRepo(t)
+
+	name, email := GetUserIdentity(dir)
+	if name != "Test" {
+		t.Errorf("expected name %q, got %q", "Test", name)
+	}
+	if email != "test@test.com" {
+		t.Errorf("expected email %q, got %q", "test@test.com", email)
+	}
+}
+
+func TestRepoSize(t *testing.T) {
+	dir := initTestRepo(t)
+
+	size, err := RepoSize(dir)
+	if err != nil {
+		t.Fatalf("RepoSize() error = %v", err)
+	}
+	if size <= 0 {
+		t.Errorf("expected positive repo size, got %d", size)
+	}
+
+	if _, err := RepoSize(t.TempDir()); err == nil {
+		t.Error("expected error for non-git directory")
+	}
+}
@@ -0,0 +1,164 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+	"github.com/minicodemonkey/chief/internal/timefmt"
+)
+
+// DefaultPRTemplate is the PR body template used when onComplete.prTemplate
+// isn't set. Custom templates use the same fields as PRBodyData.
+const DefaultPRTemplate = `## Summary
+
+{{.Description}}
+
+## Stories
+
+{{range .Stories}}- [{{if .Passes}}x{{else}} {{end}}] {{.ID}}: {{.Title}}
+{{end}}
+{{- if .Decisions}}
+## Notable Decisions
+
+{{.Decisions}}
+{{end}}
+## Stats
+
+- Stories: {{.Completed}}/{{.Total}} complete
+{{- if .Iterations}}
+- Iterations: {{.Iterations}}{{end}}
+{{- if .Elapsed}}
+- Time: {{.Elapsed}}{{end}}
+{{- if .Logs}}
+
+## Run Logs
+
+{{range .Logs}}- ` + "`{{.}}`" + `
+{{end}}
+{{- end}}
+`
+
+// PRBodyData holds everything a PR body template can reference.
+type PRBodyData struct {
+	PRDName     string
+	Project     string
+	Description string
+	Stories     []prd.UserStory
+	Completed   int           // Stories that pass
+	Total       int           // All stories
+	Decisions   string        // Notes from notes.md, or the progress.md Codebase Patterns section
+	Iterations  int           // Loop iterations (0 = unknown)
+	Duration    time.Duration // Time spent running (0 = unknown)
+	Elapsed     string        // Duration formatted for display ("" when unknown)
+	Logs        []string      // Run log paths relative to the project root
+}
+
+// NewPRBodyData collects PR body data for the PRD in prdDir. Iterations and
+// Duration are left for the caller, which knows how the run went.
+func NewPRBodyData(baseDir, prdName, prdDir string, p *prd.PRD) PRBodyData {
+	data := PRBodyData{
+		PRDName:     prdName,
+		Project:     p.Project,
+		Description: p.Description,
+		Stories:     p.UserStories,
+		Total:       len(p.UserStories),
+		Decisions:   loadDecisions(prdDir),
+	}
+	for _, story := range p.UserStories {
+		if story.Passes {
+			data.Completed++
+		}
+	}
+
+	logs, _ := filepath.Glob(filepath.Join(prdDir, "*.log"))
+	sort.Strings(logs)
+	for _, path := range logs {
+		if rel, err := filepath.Rel(baseDir, path); err == nil {
+			path = rel
+		}
+		data.Logs = append(data.Logs, filepath.ToSlash(path))
+	}
+	return data
+}
+
+// SetRunStats records how long the run took and how many iterations it used.
+func (d *PRBodyData) SetRunStats(iterations int, duration time.Duration) {
+	d.Iterations = iterations
+	d.Duration = duration
+	d.Elapsed = ""
+	if duration > 0 {
+		d.Elapsed = timefmt.Duration(duration)
+	}
+}
+
+// loadDecisions returns the notable decisions recorded for a PRD: the contents
+// of notes.md when present, otherwise the Codebase Patterns section of
+// progress.md.
+func loadDecisions(prdDir string) string {
+	if data, err := os.ReadFile(filepath.Join(prdDir, "notes.md")); err == nil {
+		return strings.TrimSpace(string(data))
+	}
+	data, err := os.ReadFile(filepath.Join(prdDir, "progress.md"))
+	if err != nil {
+		return ""
+	}
+	return codebasePatterns(string(data))
+}
+
+// codebasePatterns extracts the body of the "## Codebase Patterns" section.
+func codebasePatterns(progress string) string {
+	var lines []string
+	inSection := false
+	for _, line := range strings.Split(progress, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "## ") {
+			if inSection {
+				break
+			}
+			inSection = strings.EqualFold(strings.TrimPrefix(trimmed, "## "), "Codebase Patterns")
+			continue
+		}
+		if inSection {
+			if trimmed == "---" {
+				break
+			}
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// LoadPRTemplate reads a PR body template. An empty path returns
+// DefaultPRTemplate; a relative path is resolved against baseDir.
+func LoadPRTemplate(baseDir, path string) (string, error) {
+	if path == "" {
+		return DefaultPRTemplate, nil
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read PR template: %w", err)
+	}
+	return string(data), nil
+}
+
+// RenderPRBody renders a PR body template with data.
+func RenderPRBody(tmpl string, data PRBodyData) (string, error) {
+	t, err := template.New("pr").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid PR template: %w", err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render PR template: %w", err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,147 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+func testPRBodyPRD() *prd.PRD {
+	return &prd.PRD{
+		Project:     "Test Project",
+		Description: "This is a test project description.",
+		UserStories: []prd.UserStory{
+			{ID: "US-001", Title: "Config System", Passes: true},
+			{ID: "US-002", Title: "Git Worktree Primitives", Passes: true},
+			{ID: "US-003", Title: "Incomplete Story", Passes: false},
+		},
+	}
+}
+
+func TestRenderPRBody_Default(t *testing.T) {
+	baseDir := t.TempDir()
+	prdDir := filepath.Join(baseDir, ".chief", "prds", "auth")
+	if err := os.MkdirAll(prdDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(prdDir, "claude.log"), []byte("log"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	data := NewPRBodyData(baseDir, "auth", prdDir, testPRBodyPRD())
+	data.SetRunStats(7, 95*time.Second)
+	body, err := RenderPRBody(DefaultPRTemplate, data)
+	if err != nil {
+		t.Fatalf("RenderPRBody() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"## Summary",
+		"This is a test project description.",
+		"- [x] US-001: Config System",
+		"- [x] US-002: Git Worktree Primitives",
+		"- [ ] US-003: Incomplete Story",
+		"- Stories: 2/3 complete",
+		"- Iterations: 7",
+		"- Time: 1m35s",
+		"`.chief/prds/auth/claude.log`",
+	} {
+		if !contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+	if contains(body, "## Notable Decisions") {
+		t.Errorf("body should omit decisions when none are recorded:\n%s", body)
+	}
+}
+
+func TestRenderPRBody_OmitsUnknownStats(t *testing.T) {
+	data := NewPRBodyData(t.TempDir(), "auth", t.TempDir(), &prd.PRD{Description: "No stories yet."})
+	body, err := RenderPRBody(DefaultPRTemplate, data)
+	if err != nil {
+		t.Fatalf("RenderPRBody() error = %v", err)
+	}
+	if !contains(body, "- Stories: 0/0 complete") {
+		t.Errorf("body missing story count:\n%s", body)
+	}
+	for _, unwanted := range []string{"Iterations:", "Time:", "## Run Logs"} {
+		if contains(body, unwanted) {
+			t.Errorf("body should not include %q:\n%s", unwanted, body)
+		}
+	}
+}
+
+func TestNewPRBodyData_Decisions(t *testing.T) {
+	progress := "## Codebase Patterns\n- Use table tests\n- Errors wrap with %w\n\n## 2024-01-01 - US-001\n- Did things\n---\n"
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name:  "notes.md wins",
+			files: map[string]string{"notes.md": "- Chose SQLite over Postgres\n", "progress.md": progress},
+			want:  "- Chose SQLite over Postgres",
+		},
+		{
+			name:  "falls back to codebase patterns",
+			files: map[string]string{"progress.md": progress},
+			want:  "- Use table tests\n- Errors wrap with %w",
+		},
+		{
+			name: "nothing recorded",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			data := NewPRBodyData(dir, "main", dir, testPRBodyPRD())
+			if data.Decisions != tt.want {
+				t.Errorf("Decisions = %q, want %q", data.Decisions, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPRTemplate(t *testing.T) {
+	dir := t.TempDir()
+
+	got, err := LoadPRTemplate(dir, "")
+	if err != nil || got != DefaultPRTemplate {
+		t.Errorf("LoadPRTemplate(\"\") = %q, %v; want default template", got, err)
+	}
+
+	custom := "{{.Project}}: {{.Completed}} of {{.Total}} done"
+	if err := os.WriteFile(filepath.Join(dir, "pr.md"), []byte(custom), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := LoadPRTemplate(dir, "pr.md")
+	if err != nil {
+		t.Fatalf("LoadPRTemplate() error = %v", err)
+	}
+	body, err := RenderPRBody(tmpl, NewPRBodyData(dir, "main", dir, testPRBodyPRD()))
+	if err != nil {
+		t.Fatalf("RenderPRBody() error = %v", err)
+	}
+	if body != "Test Project: 2 of 3 done" {
+		t.Errorf("body = %q", body)
+	}
+
+	if _, err := LoadPRTemplate(dir, "missing.md"); err == nil {
+		t.Error("expected error for missing template")
+	}
+	if _, err := RenderPRBody("{{.Nope", PRBodyData{}); err == nil {
+		t.Error("expected error for invalid template")
+	}
+}
@@ -56,24 +56,6 @@ func PRTitleFromPRD(prdName string, p *prd.PRD) string {
 	return fmt.Sprintf("feat(%s): %s", prdName, p.Project)
 }
 
-// PRBodyFromPRD generates a PR body with a summary and list of completed stories.
-func PRBodyFromPRD(p *prd.PRD) string {
-	var b strings.Builder
-
-	b.WriteString("## Summary\n\n")
-	b.WriteString(p.Description)
-	b.WriteString("\n\n")
-
-	b.WriteString("## Changes\n\n")
-	for _, story := range p.UserStories {
-		if story.Passes {
-			b.WriteString(fmt.Sprintf("- %s: %s\n", story.ID, story.Title))
-		}
-	}
-
-	return b.String()
-}
-
 // DeleteBranch deletes a local branch.
 func DeleteBranch(repoDir, branch string) error {
 	cmd := exec.Command("git", "branch", "-D", branch)
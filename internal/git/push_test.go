@@ -84,65 +84,6 @@ func TestPRTitleFromPRD(t *testing.T) {
 	}
 }
 
-func TestPRBodyFromPRD(t *testing.T) {
-	t.Run("includes summary and completed stories", func(t *testing.T) {
-		p := &prd.PRD{
-			Project:     "Test Project",
-			Description: "This is a test project description.",
-			UserStories: []prd.UserStory{
-				{ID: "US-001", Title: "Config System", Passes: true},
-				{ID: "US-002", Title: "Git Worktree Primitives", Passes: true},
-				{ID: "US-003", Title: "Incomplete Story", Passes: false},
-			},
-		}
-
-		body := PRBodyFromPRD(p)
-
-		// Check summary section
-		if got := body; got == "" {
-			t.Fatal("PRBodyFromPRD() returned empty string")
-		}
-		if !contains(body, "## Summary") {
-			t.Error("body missing ## Summary header")
-		}
-		if !contains(body, "This is a test project description.") {
-			t.Error("body missing project description")
-		}
-
-		// Check changes section
-		if !contains(body, "## Changes") {
-			t.Error("body missing ## Changes header")
-		}
-		if !contains(body, "US-001: Config System") {
-			t.Error("body missing completed story US-001")
-		}
-		if !contains(body, "US-002: Git Worktree Primitives") {
-			t.Error("body missing completed story US-002")
-		}
-
-		// Incomplete stories should not be listed
-		if contains(body, "Incomplete Story") {
-			t.Error("body should not include incomplete stories")
-		}
-	})
-
-	t.Run("empty stories produces changes header only", func(t *testing.T) {
-		p := &prd.PRD{
-			Project:     "Empty Project",
-			Description: "No stories yet.",
-			UserStories: []prd.UserStory{},
-		}
-
-		body := PRBodyFromPRD(p)
-		if !contains(body, "## Summary") {
-			t.Error("body missing ## Summary header")
-		}
-		if !contains(body, "## Changes") {
-			t.Error("body missing ## Changes header")
-		}
-	})
-}
-
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && searchString(s, substr)
 }
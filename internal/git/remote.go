@@ -0,0 +1,69 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// RemoteBranch returns the remote and branch the current branch in dir is
+// compared with: its upstream, or the branch of the same name on origin when
+// it has none. It returns "" for both on a detached HEAD or without a remote.
+func RemoteBranch(dir string) (remote, branch string) {
+	current, err := GetCurrentBranch(dir)
+	if err != nil || current == "HEAD" {
+		return "", ""
+	}
+	remote = getConfigValue(dir, "branch."+current+".remote")
+	merge := getConfigValue(dir, "branch."+current+".merge")
+	if remote != "" && remote != "." && strings.HasPrefix(merge, "refs/heads/") {
+		return remote, strings.TrimPrefix(merge, "refs/heads/")
+	}
+	if getConfigValue(dir, "remote.origin.url") == "" {
+		return "", ""
+	}
+	return "origin", current
+}
+
+// FetchBranch fetches branch from remote, updating its remote-tracking
+// branch. It returns false when the remote has no such branch, e.g. one
+// that was never pushed.
+func FetchBranch(dir, remote, branch string) (bool, error) {
+	cmd := exec.Command("git", "fetch", "--quiet", remote, branch)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(out), "couldn't find remote ref") {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to fetch %s from %s: %s", branch, remote, strings.TrimSpace(string(out)))
+	}
+	return true, nil
+}
+
+// CommitsBehind returns how many commits ref has that HEAD doesn't.
+func CommitsBehind(dir, ref string) (int, error) {
+	cmd := exec.Command("git", "rev-list", "--count", "HEAD.."+ref)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to compare with %s: %w", ref, err)
+	}
+	return strconv.Atoi(strings.TrimSpace(string(out)))
+}
+
+// Rebase rebases the current branch onto ref, carrying uncommitted changes
+// along. When it can't be done cleanly it is aborted, leaving the branch as
+// it was.
+func Rebase(dir, ref string) error {
+	cmd := exec.Command("git", "rebase", "--autostash", ref)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		abort := exec.Command("git", "rebase", "--abort")
+		abort.Dir = dir
+		abort.Run()
+		return fmt.Errorf("failed to rebase onto %s: %s", ref, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// cloneWithUpstream pushes repo to a bare origin and returns a second clone of
+// it, so repo can be moved ahead of the clone.
+func cloneWithUpstream(t *testing.T, repo string) string {
+	t.Helper()
+	bare := filepath.Join(t.TempDir(), "origin.git")
+	clone := filepath.Join(t.TempDir(), "clone")
+	steps := []struct {
+		dir  string
+		args []string
+	}{
+		{repo, []string{"init", "--bare", bare}},
+		{repo, []string{"remote", "add", "origin", bare}},
+		{repo, []string{"push", "-u", "origin", "main"}},
+		{repo, []string{"clone", "--branch", "main", bare, clone}},
+		{clone, []string{"config", "user.email", "test@test.com"}},
+		{clone, []string{"config", "user.name", "Test"}},
+	}
+	for _, s := range steps {
+		cmd := exec.Command("git", s.args...)
+		cmd.Dir = s.dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %s", s.args, out)
+		}
+	}
+	return clone
+}
+
+// commitFile commits a file in dir.
+func commitFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"add", name}, {"commit", "-m", "add " + name}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %s", args, out)
+		}
+	}
+}
+
+func TestRemoteBranch(t *testing.T) {
+	repo := initTestRepo(t)
+	if remote, branch := RemoteBranch(repo); remote != "" || branch != "" {
+		t.Errorf("RemoteBranch() without a remote = %q, %q, want none", remote, branch)
+	}
+	clone := cloneWithUpstream(t, repo)
+	if remote, branch := RemoteBranch(clone); remote != "origin" || branch != "main" {
+		t.Errorf("RemoteBranch() = %q, %q, want origin, main", remote, branch)
+	}
+}
+
+func TestFetchBranch_Missing(t *testing.T) {
+	repo := initTestRepo(t)
+	clone := cloneWithUpstream(t, repo)
+	found, err := FetchBranch(clone, "origin", "never-pushed")
+	if err != nil {
+		t.Fatalf("FetchBranch() error = %v", err)
+	}
+	if found {
+		t.Error("FetchBranch() found a branch that was never pushed")
+	}
+}
+
+func TestCommitsBehindAndRebase(t *testing.T) {
+	repo := initTestRepo(t)
+	clone := cloneWithUpstream(t, repo)
+
+	commitFile(t, repo, "upstream.txt", "upstream\n")
+	cmd := exec.Command("git", "push", "origin", "main")
+	cmd.Dir = repo
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git push failed: %s", out)
+	}
+	commitFile(t, clone, "local.txt", "local\n")
+
+	if found, err := FetchBranch(clone, "origin", "main"); err != nil || !found {
+		t.Fatalf("FetchBranch() = %v, %v", found, err)
+	}
+	behind, err := CommitsBehind(clone, "origin/main")
+	if err != nil {
+		t.Fatalf("CommitsBehind() error = %v", err)
+	}
+	if behind != 1 {
+		t.Errorf("CommitsBehind() = %d, want 1", behind)
+	}
+
+	if err := Rebase(clone, "origin/main"); err != nil {
+		t.Fatalf("Rebase() error = %v", err)
+	}
+	if behind, _ := CommitsBehind(clone, "origin/main"); behind != 0 {
+		t.Errorf("CommitsBehind() after rebase = %d, want 0", behind)
+	}
+	if _, err := os.Stat(filepath.Join(clone, "local.txt")); err != nil {
+		t.Errorf("local commit lost in rebase: %v", err)
+	}
+}
+
+func TestRebase_ConflictAborts(t *testing.T) {
+	repo := initTestRepo(t)
+	clone := cloneWithUpstream(t, repo)
+
+	commitFile(t, repo, "README.md", "upstream\n")
+	cmd := exec.Command("git", "push", "origin", "main")
+	cmd.Dir = repo
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git push failed: %s", out)
+	}
+	commitFile(t, clone, "README.md", "local\n")
+	FetchBranch(clone, "origin", "main")
+
+	if err := Rebase(clone, "origin/main"); err == nil {
+		t.Fatal("Rebase() expected a conflict error")
+	}
+	data, _ := os.ReadFile(filepath.Join(clone, "README.md"))
+	if string(data) != "local\n" {
+		t.Errorf("README.md after aborted rebase = %q, want the local change", data)
+	}
+	if _, err := os.Stat(filepath.Join(clone, ".git", "rebase-merge")); !os.IsNotExist(err) {
+		t.Error("rebase was left in progress")
+	}
+}
@@ -0,0 +1,100 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// SquashCommits replaces the commits between base and HEAD with a single commit
+// that has the same tree and the given message. Before rewriting, the current
+// HEAD is saved under backupRef (e.g. refs/chief/squash/<prd>/<story>) so the
+// original commits stay reachable for debugging. The index and working tree are
+// not touched. An empty base squashes the whole history into a root commit.
+// Returns the hash of the new commit.
+func SquashCommits(dir, base, message, backupRef string) (string, error) {
+	head := HeadCommit(dir)
+	if head == "" {
+		return "", fmt.Errorf("repository has no commits")
+	}
+	if base != "" {
+		cmd := exec.Command("git", "merge-base", "--is-ancestor", base, head)
+		cmd.Dir = dir
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("%s is not an ancestor of HEAD", base)
+		}
+	}
+
+	if backupRef != "" {
+		if err := runGit(dir, "update-ref", backupRef, head); err != nil {
+			return "", fmt.Errorf("failed to save %s: %w", backupRef, err)
+		}
+	}
+
+	args := []string{"commit-tree", head + "^{tree}", "-m", message}
+	if base != "" {
+		args = append(args, "-p", base)
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to create squashed commit: %w", err)
+	}
+	squashed := strings.TrimSpace(string(output))
+
+	// Move the current branch only if nothing else moved it in the meantime
+	if err := runGit(dir, "update-ref", "-m", "chief: squash", "HEAD", squashed, head); err != nil {
+		return "", fmt.Errorf("failed to move HEAD to squashed commit: %w", err)
+	}
+	return squashed, nil
+}
+
+// PushedSince reports whether any commit between base and HEAD is on the
+// remote branch the current branch is compared with (see RemoteBranch), as of
+// its remote-tracking branch. Rewriting such a commit makes the branch diverge
+// from the remote, so it can't be pushed again without forcing.
+func PushedSince(dir, base string) (bool, error) {
+	remote, branch := RemoteBranch(dir)
+	if remote == "" {
+		return false, nil
+	}
+	tracking := "refs/remotes/" + remote + "/" + branch
+	if exec.Command("git", "-C", dir, "rev-parse", "--verify", "--quiet", tracking).Run() != nil {
+		return false, nil // Never pushed
+	}
+
+	revRange := "HEAD"
+	if base != "" {
+		revRange = base + "..HEAD"
+	}
+	count := func(args ...string) (int, error) {
+		cmd := exec.Command("git", append([]string{"rev-list", "--count", revRange}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			return 0, fmt.Errorf("failed to compare with %s: %w", tracking, err)
+		}
+		return strconv.Atoi(strings.TrimSpace(string(out)))
+	}
+	all, err := count()
+	if err != nil {
+		return false, err
+	}
+	unpushed, err := count("--not", tracking)
+	if err != nil {
+		return false, err
+	}
+	return unpushed < all, nil
+}
+
+// runGit runs a git command in dir and includes its output in the error.
+func runGit(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSquashCommits(t *testing.T) {
+	dir := initTestRepo(t)
+	base := HeadCommit(dir)
+
+	for i, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		for _, args := range [][]string{{"git", "add", name}, {"git", "commit", "-m", "step " + string(rune('1'+i))}} {
+			cmd := exec.Command(args[0], args[1:]...)
+			cmd.Dir = dir
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("%v failed: %s", args, out)
+			}
+		}
+	}
+	original := HeadCommit(dir)
+
+	// Uncommitted work must survive the squash
+	if err := os.WriteFile(filepath.Join(dir, "dirty.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	squashed, err := SquashCommits(dir, base, "feat: US-001 - Story", "refs/chief/squash/test/US-001")
+	if err != nil {
+		t.Fatalf("SquashCommits() error = %v", err)
+	}
+	if HeadCommit(dir) != squashed {
+		t.Errorf("expected HEAD to be the squashed commit")
+	}
+
+	commits, err := CommitsSince(dir, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 1 || commits[0].Subject != "feat: US-001 - Story" {
+		t.Errorf("expected a single squashed commit, got %+v", commits)
+	}
+
+	cmd := exec.Command("git", "rev-parse", "refs/chief/squash/test/US-001")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil || strings.TrimSpace(string(out)) != original {
+		t.Errorf("expected backup ref to point at %s, got %q (%v)", original, out, err)
+	}
+
+	// Same tree, so nothing shows up as changed except the untracked file
+	cmd = exec.Command("git", "status", "--porcelain")
+	cmd.Dir = dir
+	out, err = cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(out)) != "?? dirty.txt" {
+		t.Errorf("expected only the untracked file in status, got %q", out)
+	}
+}
+
+func TestSquashCommits_BaseNotAncestor(t *testing.T) {
+	dir := initTestRepo(t)
+	if _, err := SquashCommits(dir, "0000000000000000000000000000000000000000", "msg", ""); err == nil {
+		t.Error("expected error when base is not an ancestor of HEAD")
+	}
+}
+
+func TestPushedSince(t *testing.T) {
+	clone := cloneWithUpstream(t, initTestRepo(t))
+	base := HeadCommit(clone)
+
+	commitFile(t, clone, "a.txt", "a")
+	if pushed, err := PushedSince(clone, base); err != nil || pushed {
+		t.Errorf("PushedSince() before pushing = %v, %v; want false", pushed, err)
+	}
+
+	cmd := exec.Command("git", "push", "origin", "main")
+	cmd.Dir = clone
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git push failed: %s", out)
+	}
+	pushedHead := HeadCommit(clone)
+	commitFile(t, clone, "b.txt", "b")
+	if pushed, err := PushedSince(clone, base); err != nil || !pushed {
+		t.Errorf("PushedSince() with a pushed commit = %v, %v; want true", pushed, err)
+	}
+	if pushed, err := PushedSince(clone, pushedHead); err != nil || pushed {
+		t.Errorf("PushedSince() after the pushed commits = %v, %v; want false", pushed, err)
+	}
+}
@@ -0,0 +1,32 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// StackBranch returns the branch a story's stacked PR is opened from, e.g.
+// "chief/auth-us-003". It sits next to the PRD's own "chief/<prd>" branch
+// rather than under it, since git can't have both "chief/auth" and
+// "chief/auth/..." as branches.
+func StackBranch(prdName, storyID string) string {
+	return fmt.Sprintf("chief/%s-%s", prdName, strings.ToLower(storyID))
+}
+
+// PushStackBranch points branch at the current HEAD of dir and pushes it to
+// origin, without switching branches. An existing branch is moved, so a
+// story redone after review gets its branch updated.
+func PushStackBranch(dir, branch string) error {
+	cmd := exec.Command("git", "branch", "-f", branch, "HEAD")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create branch %s: %s", branch, strings.TrimSpace(string(out)))
+	}
+	cmd = exec.Command("git", "push", "--force-with-lease", "origin", branch)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to push branch %s: %s", branch, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Stash stashes the working tree's uncommitted changes, untracked files
+// included and .chief/ left alone, and returns the stash commit's hash. It
+// returns "" when there was nothing to stash.
+func Stash(dir, message string) (string, error) {
+	before := stashTop(dir)
+	cmd := exec.Command("git", "-C", dir, "stash", "push", "--include-untracked", "-m", message, "--", ":/", ":(top,exclude).chief")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to stash changes: %s", strings.TrimSpace(string(out)))
+	}
+	after := stashTop(dir)
+	if after == before {
+		return "", nil
+	}
+	return after, nil
+}
+
+// RestoreStash applies the stash with the given hash and drops it. On a
+// conflict the stash is kept so nothing is lost.
+func RestoreStash(dir, hash string) error {
+	if out, err := exec.Command("git", "-C", dir, "stash", "apply", hash).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to restore stash %s (it is kept; run `git stash list`): %s", shortHash(hash), strings.TrimSpace(string(out)))
+	}
+	out, err := exec.Command("git", "-C", dir, "stash", "list", "--format=%H").Output()
+	if err != nil {
+		return fmt.Errorf("failed to list stashes: %w", err)
+	}
+	for i, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line == hash {
+			ref := fmt.Sprintf("stash@{%d}", i)
+			if out, err := exec.Command("git", "-C", dir, "stash", "drop", ref).CombinedOutput(); err != nil {
+				return fmt.Errorf("failed to drop %s: %s", ref, strings.TrimSpace(string(out)))
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// stashTop returns the hash of the most recent stash, or "" if there is none.
+func stashTop(dir string) string {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--quiet", "--verify", "refs/stash").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
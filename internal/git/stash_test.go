@@ -0,0 +1,41 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStashAndRestore(t *testing.T) {
+	dir := initTestRepo(t)
+
+	if hash, err := Stash(dir, "nothing"); err != nil || hash != "" {
+		t.Fatalf("Stash() on a clean tree = %q, %v; want nothing stashed", hash, err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Changed\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("wip\n"), 0644)
+	os.MkdirAll(filepath.Join(dir, ".chief"), 0755)
+	os.WriteFile(filepath.Join(dir, ".chief", "config.yaml"), []byte("{}\n"), 0644)
+
+	hash, err := Stash(dir, "chief test")
+	if err != nil || hash == "" {
+		t.Fatalf("Stash() = %q, %v; want a stash", hash, err)
+	}
+	if files, _ := DirtyFiles(dir); len(files) != 0 {
+		t.Errorf("expected a clean tree after stashing, got %v", files)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".chief", "config.yaml")); err != nil {
+		t.Error("expected .chief/ to be left alone")
+	}
+
+	if err := RestoreStash(dir, hash); err != nil {
+		t.Fatalf("RestoreStash() error = %v", err)
+	}
+	if files, _ := DirtyFiles(dir); len(files) != 2 {
+		t.Errorf("expected both changes back, got %v", files)
+	}
+	if top := stashTop(dir); top != "" {
+		t.Errorf("expected the stash to be dropped, still have %s", top)
+	}
+}
@@ -0,0 +1,220 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Trailers Chief adds to agent commits to record what they were made for.
+const (
+	StoryTrailer = "Chief-Story"
+	RunTrailer   = "Chief-Run"
+)
+
+// Provenance is the story and run a commit was made for.
+type Provenance struct {
+	Story string
+	Run   string
+}
+
+// trailerArgs returns the interpret-trailers arguments adding p.
+func (p Provenance) trailerArgs() []string {
+	args := []string{"--trailer", StoryTrailer + ": " + p.Story}
+	if p.Run != "" {
+		args = append(args, "--trailer", RunTrailer+": "+p.Run)
+	}
+	return args
+}
+
+// WithTrailers returns message with p's trailers added to it.
+func WithTrailers(dir, message string, p Provenance) (string, error) {
+	cmd := exec.Command("git", append([]string{"interpret-trailers"}, p.trailerArgs()...)...)
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(message)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to add trailers: %w", err)
+	}
+	return string(output), nil
+}
+
+// AddTrailers adds p's trailers to the commits between base and HEAD that
+// don't have a Chief-Story trailer yet, rewriting them with the same trees
+// and authors. Like SquashCommits, the index and working tree are not
+// touched and HEAD only moves if nothing else moved it in the meantime.
+// A merge commit among them is an error, and nothing is rewritten. An empty
+// base covers the whole history. Returns how many commits were tagged.
+func AddTrailers(dir, base string, p Provenance) (int, error) {
+	head := HeadCommit(dir)
+	if head == "" {
+		return 0, nil
+	}
+	revRange := "HEAD"
+	if base != "" {
+		revRange = base + "..HEAD"
+	}
+	cmd := exec.Command("git", "log", "--reverse", "--date=raw",
+		"--format=%H%x00%P%x00%an%x00%ae%x00%ad%x00%(trailers:key="+StoryTrailer+",valueonly)%x00%B%x1e", revRange)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, err
+	}
+
+	type commit struct {
+		hash, parents, name, email, date, story, message string
+	}
+	var commits []commit
+	for _, rec := range strings.Split(string(output), "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(rec, "\n"), "\x00", 7)
+		if len(fields) < 7 {
+			continue
+		}
+		c := commit{fields[0], fields[1], fields[2], fields[3], fields[4], strings.TrimSpace(fields[5]), fields[6]}
+		if strings.Contains(c.parents, " ") {
+			return 0, fmt.Errorf("%s is a merge commit", c.hash[:7])
+		}
+		commits = append(commits, c)
+	}
+
+	tagged := 0
+	parent := ""
+	rewriting := false
+	for _, c := range commits {
+		if c.story != "" && !rewriting {
+			continue // Nothing before it changed, so it can stay as it is
+		}
+		if !rewriting {
+			parent = c.parents
+		}
+		message := c.message
+		if c.story == "" {
+			if message, err = WithTrailers(dir, message, p); err != nil {
+				return 0, err
+			}
+			tagged++
+		}
+		args := []string{"commit-tree", c.hash + "^{tree}"}
+		if parent != "" {
+			args = append(args, "-p", parent)
+		}
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Stdin = strings.NewReader(message)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME="+c.name, "GIT_AUTHOR_EMAIL="+c.email, "GIT_AUTHOR_DATE="+c.date)
+		out, err := cmd.Output()
+		if err != nil {
+			return 0, fmt.Errorf("failed to rewrite %s: %w", c.hash[:7], err)
+		}
+		parent = strings.TrimSpace(string(out))
+		rewriting = true
+	}
+	if !rewriting {
+		return 0, nil
+	}
+
+	if err := runGit(dir, "update-ref", "-m", "chief: add trailers", "HEAD", parent, head); err != nil {
+		return 0, fmt.Errorf("failed to move HEAD to tagged commits: %w", err)
+	}
+	return tagged, nil
+}
+
+// BlameLine is a line of a file with the commit that last changed it and
+// that commit's provenance, if it has any.
+type BlameLine struct {
+	Number int
+	Commit string // "" for a line that isn't committed yet
+	Text   string
+	Provenance
+}
+
+// Blame returns the lines of file as of HEAD plus uncommitted changes, each
+// with the commit that last changed it and the Chief-Story and Chief-Run
+// trailers of that commit.
+func Blame(dir, file string) ([]BlameLine, error) {
+	cmd := exec.Command("git", "blame", "--line-porcelain", "--", file)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git blame failed: %s", strings.TrimSpace(string(output)))
+	}
+
+	var lines []BlameLine
+	var current BlameLine
+	header := true
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if text, ok := strings.CutPrefix(line, "\t"); ok {
+			current.Text = text
+			lines = append(lines, current)
+			header = true
+			continue
+		}
+		if !header {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		current = BlameLine{Commit: fields[0]}
+		current.Number, _ = strconv.Atoi(fields[2])
+		if strings.Trim(current.Commit, "0") == "" {
+			current.Commit = ""
+		}
+		header = false
+	}
+
+	provenance, err := commitProvenance(dir, lines)
+	if err != nil {
+		return nil, err
+	}
+	for i := range lines {
+		lines[i].Provenance = provenance[lines[i].Commit]
+	}
+	return lines, nil
+}
+
+// commitProvenance returns the provenance of each commit lines came from.
+func commitProvenance(dir string, lines []BlameLine) (map[string]Provenance, error) {
+	seen := make(map[string]bool)
+	var hashes []string
+	for _, l := range lines {
+		if l.Commit != "" && !seen[l.Commit] {
+			seen[l.Commit] = true
+			hashes = append(hashes, l.Commit)
+		}
+	}
+	provenance := make(map[string]Provenance, len(hashes))
+	if len(hashes) == 0 {
+		return provenance, nil
+	}
+
+	args := []string{"show", "-s", "--format=%H%x00%(trailers:key=" + StoryTrailer + ",valueonly)%x00%(trailers:key=" + RunTrailer + ",valueonly)%x1e"}
+	cmd := exec.Command("git", append(args, hashes...)...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit trailers: %w", err)
+	}
+	for _, rec := range strings.Split(string(output), "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(rec, "\n"), "\x00", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		provenance[fields[0]] = Provenance{Story: firstLine(fields[1]), Run: firstLine(fields[2])}
+	}
+	return provenance, nil
+}
+
+// firstLine returns the first non-empty line of s, trimmed.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(line)
+}
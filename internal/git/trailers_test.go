@@ -0,0 +1,69 @@
+package git
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestAddTrailers(t *testing.T) {
+	repo := initTestRepo(t)
+	base := HeadCommit(repo)
+	commitFile(t, repo, "a.txt", "one\n")
+	commitFile(t, repo, "b.txt", "two\n")
+
+	n, err := AddTrailers(repo, base, Provenance{Story: "US-012", Run: "main-20260101-120000"})
+	if err != nil {
+		t.Fatalf("AddTrailers() error = %v", err)
+	}
+	if n != 2 {
+		t.Errorf("AddTrailers() tagged %d commits, want 2", n)
+	}
+	commits, _ := CommitsSince(repo, base)
+	if len(commits) != 2 || commits[0].Subject != "add a.txt" {
+		t.Fatalf("commits after AddTrailers() = %v", commits)
+	}
+	cmd := exec.Command("git", "log", "-1", "--format=%B", "HEAD")
+	cmd.Dir = repo
+	out, _ := cmd.Output()
+	if !strings.Contains(string(out), "Chief-Story: US-012\nChief-Run: main-20260101-120000") {
+		t.Errorf("HEAD message = %q, want the trailers", out)
+	}
+
+	// Commits already tagged are left as they are
+	head := HeadCommit(repo)
+	if n, err := AddTrailers(repo, base, Provenance{Story: "US-013"}); err != nil || n != 0 {
+		t.Errorf("AddTrailers() again = %d, %v, want 0, nil", n, err)
+	}
+	if HeadCommit(repo) != head {
+		t.Error("AddTrailers() rewrote commits that were already tagged")
+	}
+}
+
+func TestBlame(t *testing.T) {
+	repo := initTestRepo(t)
+	base := HeadCommit(repo)
+	commitFile(t, repo, "main.go", "package main\n")
+	if _, err := AddTrailers(repo, base, Provenance{Story: "US-001", Run: "r1"}); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, repo, "main.go", "package main\n\nfunc main() {}\n")
+
+	lines, err := Blame(repo, "main.go")
+	if err != nil {
+		t.Fatalf("Blame() error = %v", err)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("Blame() returned %d lines, want 3", len(lines))
+	}
+	if lines[0].Story != "US-001" || lines[0].Run != "r1" || lines[0].Text != "package main" {
+		t.Errorf("line 1 = %+v, want US-001 from run r1", lines[0])
+	}
+	if lines[2].Number != 3 || lines[2].Story != "" || lines[2].Commit == "" {
+		t.Errorf("line 3 = %+v, want an untagged commit", lines[2])
+	}
+
+	if _, err := Blame(repo, "missing.go"); err == nil {
+		t.Error("Blame() expected an error for a missing file")
+	}
+}
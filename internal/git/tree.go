@@ -0,0 +1,56 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// WorkTreeHash returns the hash of a tree holding every file in the
+// repository at dir that git would see, committed or not, leaving out .chief. Two calls return the same
+// hash only when no tracked or untracked, non-ignored file changed in between.
+// The repository's index and HEAD are not touched.
+func WorkTreeHash(dir string) (string, error) {
+	indexPath, err := exec.Command("git", "-C", dir, "rev-parse", "--path-format=absolute", "--git-path", "index").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the git index: %w", err)
+	}
+
+	// Work on a copy of the index, which keeps git's stat cache so unchanged
+	// files aren't hashed again
+	tmp, err := os.CreateTemp("", "chief-index-*")
+	if err != nil {
+		return "", err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+	src, err := os.Open(strings.TrimSpace(string(indexPath)))
+	if err == nil {
+		_, err = io.Copy(tmp, src)
+		src.Close()
+	} else {
+		// No index yet; let git start an empty one
+		os.Remove(tmpPath)
+		err = nil
+	}
+	tmp.Close()
+	if err != nil {
+		return "", err
+	}
+
+	env := append(os.Environ(), "GIT_INDEX_FILE="+tmpPath)
+	add := exec.Command("git", "-C", dir, "add", "-A", "--", ":/", ":(top,exclude).chief")
+	add.Env = env
+	if out, err := add.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to snapshot the files in %s: %s", dir, strings.TrimSpace(string(out)))
+	}
+	write := exec.Command("git", "-C", dir, "write-tree")
+	write.Env = env
+	out, err := write.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to write the snapshot tree: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
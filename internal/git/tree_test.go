@@ -0,0 +1,54 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestWorkTreeHash(t *testing.T) {
+	dir := initTestRepo(t)
+	hash := func() string {
+		t.Helper()
+		h, err := WorkTreeHash(dir)
+		if err != nil {
+			t.Fatalf("WorkTreeHash() error = %v", err)
+		}
+		return h
+	}
+
+	clean := hash()
+	if err := os.WriteFile(filepath.Join(dir, "new.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	untracked := hash()
+	if untracked == clean {
+		t.Error("expected an untracked file to change the hash")
+	}
+
+	// .chief and committing don't count
+	if err := os.MkdirAll(filepath.Join(dir, ".chief"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".chief", "notes.md"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := hash(); got != untracked {
+		t.Error("expected files under .chief to be ignored")
+	}
+	for _, args := range [][]string{{"add", "new.go"}, {"commit", "-m", "add"}} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+	if got := hash(); got != untracked {
+		t.Error("expected committing to leave the hash unchanged")
+	}
+
+	// The real index is left alone
+	out, _ := exec.Command("git", "-C", dir, "status", "--porcelain").Output()
+	if string(out) != "?? .chief/\n" {
+		t.Errorf("expected only .chief to be untracked, got %q", out)
+	}
+}
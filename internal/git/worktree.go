@@ -96,6 +96,50 @@ func CreateWorktree(repoDir, worktreePath, branch string) error {
 	return nil
 }
 
+// AddWorktreeAt adds a worktree at worktreePath on branch, which is created
+// or reset to start. A worktree already at worktreePath on branch is reused
+// as it is, so work left in it can be picked up again.
+func AddWorktreeAt(repoDir, worktreePath, branch, start string) error {
+	absWorktreePath, err := filepath.Abs(worktreePath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve worktree path: %w", err)
+	}
+	if IsWorktree(absWorktreePath) {
+		if current, err := GetCurrentBranch(absWorktreePath); err == nil && current == branch {
+			return nil
+		}
+		if err := DiscardWorktree(repoDir, absWorktreePath, ""); err != nil {
+			return fmt.Errorf("failed to remove stale worktree: %w", err)
+		}
+	}
+
+	cmd := exec.Command("git", "worktree", "add", "-B", branch, absWorktreePath, start)
+	cmd.Dir = repoDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to add worktree: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// DiscardWorktree removes the worktree at worktreePath along with any
+// changes in it, then deletes branch unless it is empty.
+func DiscardWorktree(repoDir, worktreePath, branch string) error {
+	cmd := exec.Command("git", "worktree", "remove", "--force", worktreePath)
+	cmd.Dir = repoDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to remove worktree: %s", strings.TrimSpace(string(out)))
+	}
+	if branch == "" {
+		return nil
+	}
+	cmd = exec.Command("git", "branch", "-D", branch)
+	cmd.Dir = repoDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to delete branch %s: %s", branch, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
 // RemoveWorktree removes a git worktree at the given path.
 func RemoveWorktree(repoDir, worktreePath string) error {
 	cmd := exec.Command("git", "worktree", "remove", worktreePath)
@@ -218,7 +262,7 @@ func DetectOrphanedWorktrees(baseDir string) map[string]string {
 
 // MergeBranch merges a branch into the current branch, returning conflicting file list on failure.
 func MergeBranch(repoDir, branch string) ([]string, error) {
-	cmd := exec.Command("git", "merge", branch)
+	cmd := exec.Command("git", "merge", "--no-edit", branch)
 	cmd.Dir = repoDir
 	out, err := cmd.CombinedOutput()
 	if err != nil {
@@ -168,6 +168,44 @@ func TestCreateWorktree(t *testing.T) {
 	})
 }
 
+func TestAddWorktreeAt(t *testing.T) {
+	dir := initTestRepo(t)
+	start := HeadCommit(dir)
+	wtPath := filepath.Join(dir, "worktrees", "auth-us-001")
+
+	if err := AddWorktreeAt(dir, wtPath, "chief/auth-us-001", start); err != nil {
+		t.Fatalf("AddWorktreeAt() error = %v", err)
+	}
+	if branch, _ := GetCurrentBranch(wtPath); branch != "chief/auth-us-001" {
+		t.Errorf("branch = %q, want %q", branch, "chief/auth-us-001")
+	}
+	if head := HeadCommit(wtPath); head != start {
+		t.Errorf("HEAD = %s, want %s", head, start)
+	}
+
+	// Work left in the worktree is kept when it is added again
+	marker := filepath.Join(wtPath, "marker.txt")
+	if err := os.WriteFile(marker, []byte("marker"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddWorktreeAt(dir, wtPath, "chief/auth-us-001", start); err != nil {
+		t.Fatalf("second AddWorktreeAt() error = %v", err)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Error("expected the existing worktree to be reused")
+	}
+
+	if err := DiscardWorktree(dir, wtPath, "chief/auth-us-001"); err != nil {
+		t.Fatalf("DiscardWorktree() error = %v", err)
+	}
+	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
+		t.Error("expected the worktree to be removed")
+	}
+	if exists, _ := BranchExists(dir, "chief/auth-us-001"); exists {
+		t.Error("expected the branch to be deleted")
+	}
+}
+
 func TestRemoveWorktree(t *testing.T) {
 	t.Run("removes existing worktree", func(t *testing.T) {
 		dir := initTestRepo(t)
@@ -0,0 +1,99 @@
+package loop
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+	"github.com/minicodemonkey/chief/internal/verify"
+)
+
+// ArtifactsDir holds files kept from verification, such as failure
+// screenshots, inside the PRD directory.
+const ArtifactsDir = "artifacts"
+
+// SetBrowserVerify enables browser tests for stories that have a URL. After
+// the story passes verification, command is run with `sh -c` on the test
+// files named after the story, and the story is only marked done when they
+// pass. Screenshots from a failed run are kept in the PRD's artifacts
+// directory and shown to the agent.
+func (l *Loop) SetBrowserVerify(command string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.verify.browser = command
+}
+
+// checkBrowser runs the recorded browser tests of storyID and reports whether
+// the story may be marked done. Stories without a URL or recorded tests pass.
+func (l *Loop) checkBrowser(ctx context.Context, storyID string) bool {
+	l.mu.Lock()
+	command := l.verify.browser
+	l.mu.Unlock()
+	if command == "" {
+		return true
+	}
+
+	p, err := prd.LoadPRD(l.prdPath)
+	if err != nil {
+		return true
+	}
+	var url string
+	for _, s := range p.UserStories {
+		if s.ID == storyID {
+			url = s.URL
+		}
+	}
+	if url == "" {
+		return true
+	}
+
+	dir := l.effectiveWorkDir()
+	tests := verify.BrowserTests(dir, storyID)
+	if len(tests) == 0 {
+		l.emitVerify(storyID, fmt.Sprintf("No browser tests recorded for %s; record one with `chief record %s`", storyID, storyID), nil)
+		return true
+	}
+
+	release, err := l.holdResources(ctx, storyID)
+	if err != nil {
+		return false
+	}
+	start := time.Now()
+	result := verify.RunBrowser(ctx, dir, command, tests, storyID, url)
+	release()
+	if result.Passed {
+		l.emitVerify(storyID, fmt.Sprintf("Browser tests passed for %s", storyID), nil)
+		return true
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+
+	artifacts := filepath.Join(filepath.Dir(l.prdPath), ArtifactsDir, storyID)
+	screenshots := verify.CollectScreenshots(dir, start, artifacts)
+	summary := "browser tests failed"
+	if len(result.Failed) > 0 {
+		summary = fmt.Sprintf("browser tests failed: %s", strings.Join(result.Failed, ", "))
+	}
+	if len(screenshots) > 0 {
+		summary += fmt.Sprintf(" (%d screenshot(s) in %s)", len(screenshots), artifacts)
+	}
+	l.emitVerify(storyID, "", fmt.Errorf("%s for %s, asking %s to fix it", summary, storyID, l.provider.Name()))
+
+	note := fmt.Sprintf("## Fix Failing Browser Tests\n\n"+
+		"You marked %s as done, but its browser tests (%s) failed against %s. The end of the output:\n\n```\n%s\n```\n\n",
+		storyID, strings.Join(tests, ", "), url, verify.Tail(result.Output, verifyOutputLines))
+	if len(screenshots) > 0 {
+		note += "Screenshots of the page when the tests failed:\n\n"
+		for _, path := range screenshots {
+			note += fmt.Sprintf("- %s\n", path)
+		}
+		note += "\n"
+	}
+	note += "The story is not done yet. Fix the page (not the recorded tests, unless they are wrong), commit, and then output <chief-done/> again."
+	l.addFeedback(note)
+	return false
+}
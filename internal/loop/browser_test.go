@@ -0,0 +1,47 @@
+package loop
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckBrowser(t *testing.T) {
+	l, project := newVerifyTestLoop(t)
+	md := "# Test\n\n### US-001: Checkout\n**URL:** http://localhost:3000/checkout\n- [ ] Pays\n"
+	if err := os.WriteFile(l.prdPath, []byte(md), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Writes a screenshot the way Playwright does, then fails
+	l.SetBrowserVerify(`mkdir -p test-results/checkout && touch test-results/checkout/test-failed-1.png && exit 1; :`)
+
+	if !l.checkBrowser(context.Background(), "US-001") {
+		t.Fatal("expected a story without recorded tests to pass")
+	}
+
+	test := filepath.Join(project, "e2e", "us-001.spec.ts")
+	if err := os.MkdirAll(filepath.Dir(test), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(test, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	drainEvents(l)
+	if l.checkBrowser(context.Background(), "US-001") {
+		t.Fatal("expected failing browser tests to keep the story open")
+	}
+
+	screenshot := filepath.Join(filepath.Dir(l.prdPath), ArtifactsDir, "US-001", "checkout--test-failed-1.png")
+	if _, err := os.Stat(screenshot); err != nil {
+		t.Errorf("expected the screenshot to be kept: %v", err)
+	}
+	if !strings.Contains(l.feedback, screenshot) || !strings.Contains(l.feedback, "http://localhost:3000/checkout") {
+		t.Errorf("expected the feedback to show the URL and screenshot, got:\n%s", l.feedback)
+	}
+	events := drainEvents(l)
+	if len(events) != 1 || events[0].Err == nil || !strings.Contains(events[0].Text, "browser tests failed") {
+		t.Errorf("expected one failed verify event, got %+v", events)
+	}
+}
@@ -0,0 +1,176 @@
+package loop
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// coverageTimeout bounds a single run of the coverage command.
+const coverageTimeout = 15 * time.Minute
+
+// coveragePercentRegex matches a percentage such as "78.3%".
+var coveragePercentRegex = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*%`)
+
+// coverageState tracks coverage measurements for the running PRD.
+type coverageState struct {
+	command  string  // Shell command printing a coverage percentage ("" = off)
+	maxDrop  float64 // Largest allowed drop in percentage points per story (0 = never fail)
+	before   float64 // Coverage when work on the current story began
+	haveBase bool    // Whether before holds a measurement
+	runStart float64 // First measurement of this run
+	runEnd   float64 // Latest measurement after a finished story
+	measured bool    // Whether runStart/runEnd hold measurements
+}
+
+// SetCoverage enables coverage tracking. command is run with `sh -c` in the
+// working directory before and after each story, and the last percentage it
+// prints is taken as the coverage. When maxDrop is positive, a story that
+// lowers coverage by more than maxDrop percentage points is not marked done;
+// the agent is asked to add tests instead.
+func (l *Loop) SetCoverage(command string, maxDrop float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.coverage.command = command
+	l.coverage.maxDrop = maxDrop
+}
+
+// measureCoverage runs the coverage command and parses its output.
+func (l *Loop) measureCoverage(ctx context.Context, command string) (float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, coverageTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = l.effectiveWorkDir()
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("coverage command failed: %w", err)
+	}
+	return parseCoverage(string(out))
+}
+
+// parseCoverage returns the last percentage in the coverage command's output.
+func parseCoverage(output string) (float64, error) {
+	matches := coveragePercentRegex.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return 0, fmt.Errorf("coverage command printed no percentage")
+	}
+	return strconv.ParseFloat(matches[len(matches)-1][1], 64)
+}
+
+// recordCoverageBaseline measures coverage before work on a new story begins.
+func (l *Loop) recordCoverageBaseline(ctx context.Context) {
+	l.mu.Lock()
+	command := l.coverage.command
+	l.coverage.haveBase = false
+	l.mu.Unlock()
+	if command == "" {
+		return
+	}
+
+	before, err := l.measureCoverage(ctx, command)
+	if err != nil {
+		l.emitCoverage("", 0, fmt.Errorf("could not measure baseline coverage: %w", err))
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.coverage.before = before
+	l.coverage.haveBase = true
+	if !l.coverage.measured {
+		l.coverage.runStart = before
+		l.coverage.runEnd = before
+		l.coverage.measured = true
+	}
+}
+
+// checkCoverage measures coverage after the agent finished storyID, records the
+// change in progress.md, and reports whether the story may be marked done.
+func (l *Loop) checkCoverage(ctx context.Context, storyID string) bool {
+	l.mu.Lock()
+	state := l.coverage
+	l.mu.Unlock()
+	if state.command == "" || !state.haveBase {
+		return true
+	}
+
+	after, err := l.measureCoverage(ctx, state.command)
+	if err != nil {
+		l.emitCoverage(storyID, 0, fmt.Errorf("could not measure coverage after %s: %w", storyID, err))
+		return true
+	}
+	delta := after - state.before
+	summary := fmt.Sprintf("Coverage %s → %s (%s)", formatPercent(state.before), formatPercent(after), formatDelta(delta))
+
+	if state.maxDrop > 0 && -delta > state.maxDrop {
+		l.emitCoverage(storyID, delta, fmt.Errorf("%s for %s exceeds the allowed drop of %.1f points, asking %s to add tests", summary, storyID, state.maxDrop, l.provider.Name()))
+		l.addFeedback(fmt.Sprintf("## Restore Test Coverage First\n\n"+
+			"You marked %s as done, but test coverage fell from %s to %s, more than the allowed %.1f points. "+
+			"The story is not done yet. Add tests for the code you changed until coverage is back within the limit, "+
+			"commit them, and then output <chief-done/> again.",
+			storyID, formatPercent(state.before), formatPercent(after), state.maxDrop))
+		return false
+	}
+
+	l.mu.Lock()
+	l.coverage.runEnd = after
+	l.mu.Unlock()
+	l.emitCoverage(storyID, delta, nil)
+	l.appendCoverageProgress(storyID, summary)
+	return true
+}
+
+// emitCoverage sends an EventCoverage. A non-nil err marks a failed check.
+func (l *Loop) emitCoverage(storyID string, delta float64, err error) {
+	l.mu.Lock()
+	iter := l.iteration
+	state := l.coverage
+	l.mu.Unlock()
+
+	event := Event{Type: EventCoverage, Iteration: iter, StoryID: storyID, Err: err}
+	if err != nil {
+		event.Text = err.Error()
+	} else {
+		event.Text = fmt.Sprintf("Coverage %s → %s (%s) for %s", formatPercent(state.before), formatPercent(state.before+delta), formatDelta(delta), storyID)
+	}
+	l.events <- event
+}
+
+// appendCoverageProgress attaches the coverage change to the story's notes in progress.md.
+func (l *Loop) appendCoverageProgress(storyID, summary string) {
+	f, err := os.OpenFile(prd.ProgressPath(l.prdPath), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "\n## %s - %s\n- %s\n---\n", time.Now().Format("2006-01-02"), storyID, summary)
+}
+
+// coverageSummary describes the coverage change over the whole run, or "" when
+// coverage isn't tracked.
+func (l *Loop) coverageSummary() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.coverage.measured {
+		return ""
+	}
+	start, end := l.coverage.runStart, l.coverage.runEnd
+	return fmt.Sprintf("Coverage %s → %s (%s)", formatPercent(start), formatPercent(end), formatDelta(end-start))
+}
+
+// formatPercent formats a coverage percentage.
+func formatPercent(p float64) string {
+	return strconv.FormatFloat(p, 'f', 1, 64) + "%"
+}
+
+// formatDelta formats a coverage change in percentage points with its sign.
+func formatDelta(d float64) string {
+	return fmt.Sprintf("%+.1f", d)
+}
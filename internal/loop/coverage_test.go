@@ -0,0 +1,96 @@
+package loop
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseCoverage(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    float64
+		wantErr bool
+	}{
+		{"go tool cover", "total:\t(statements)\t78.3%\n", 78.3, false},
+		{"last percentage wins", "pkg/a 50%\npkg/b 90%\nAll files | 71.25 %\n", 71.25, false},
+		{"integer", "Coverage: 80%", 80, false},
+		{"no percentage", "ok\n", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCoverage(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCoverage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseCoverage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckCoverage(t *testing.T) {
+	dir := t.TempDir()
+	prdPath := createTestPRD(t, dir, false)
+	setCoverage := func(value string) {
+		if err := os.WriteFile(filepath.Join(dir, "cov.txt"), []byte(value), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l := NewLoopWithWorkDir(prdPath, dir, "test", 5, testProvider)
+	l.SetCoverage("cat cov.txt", 2)
+	ctx := context.Background()
+
+	setCoverage("80.0%")
+	l.recordCoverageBaseline(ctx)
+
+	// A small drop is allowed and recorded in progress.md
+	setCoverage("79.0%")
+	if !l.checkCoverage(ctx, "US-001") {
+		t.Fatal("expected a 1 point drop to be allowed")
+	}
+	if e := <-l.events; e.Type != EventCoverage || e.Err != nil {
+		t.Errorf("expected a successful Coverage event, got %v (%v)", e.Type, e.Err)
+	}
+	progress, err := os.ReadFile(filepath.Join(dir, "progress.md"))
+	if err != nil {
+		t.Fatalf("expected progress.md to be written: %v", err)
+	}
+	if !strings.Contains(string(progress), "- US-001") || !strings.Contains(string(progress), "Coverage 80.0% → 79.0% (-1.0)") {
+		t.Errorf("unexpected progress entry: %q", progress)
+	}
+
+	// A large drop keeps the story open and asks for tests
+	l.recordCoverageBaseline(ctx)
+	setCoverage("70.0%")
+	if l.checkCoverage(ctx, "US-002") {
+		t.Fatal("expected a 9 point drop to block the story")
+	}
+	if e := <-l.events; e.Type != EventCoverage || e.Err == nil {
+		t.Errorf("expected a failed Coverage event, got %v (%v)", e.Type, e.Err)
+	}
+	if !strings.Contains(l.feedback, "Restore Test Coverage") || !strings.Contains(l.feedback, "US-002") {
+		t.Errorf("expected coverage feedback, got %q", l.feedback)
+	}
+
+	if got, want := l.coverageSummary(), "Coverage 80.0% → 79.0% (-1.0)"; got != want {
+		t.Errorf("coverageSummary() = %q, want %q", got, want)
+	}
+}
+
+func TestCheckCoverage_Disabled(t *testing.T) {
+	dir := t.TempDir()
+	l := NewLoopWithWorkDir(createTestPRD(t, dir, false), dir, "test", 5, testProvider)
+	l.recordCoverageBaseline(context.Background())
+	if !l.checkCoverage(context.Background(), "US-001") {
+		t.Error("expected checkCoverage to pass when coverage isn't configured")
+	}
+	if got := l.coverageSummary(); got != "" {
+		t.Errorf("expected empty summary, got %q", got)
+	}
+}
@@ -0,0 +1,265 @@
+package loop
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/timefmt"
+)
+
+// DefaultCrashLimit is how many agent crashes within CrashWindow open the
+// circuit breaker and pause the run.
+const DefaultCrashLimit = 5
+
+// CrashWindow is the period crashes are counted over for the circuit breaker.
+const CrashWindow = 30 * time.Minute
+
+// maxRetryDelay caps the exponential backoff between retries.
+const maxRetryDelay = time.Minute
+
+// stderrTailLines is how many lines of the agent's stderr are kept per attempt.
+const stderrTailLines = 20
+
+// FailureFile is the failure dossier written to the PRD directory when the
+// circuit breaker opens.
+const FailureFile = "failure.md"
+
+// errCircuitOpen is returned by runIterationWithRetry when the agent crashed
+// too often and the run should pause.
+var errCircuitOpen = errors.New("circuit breaker open")
+
+// crashVolatileRegex matches the parts of an error that differ between
+// otherwise identical crashes: hex ids, numbers, and paths under temp dirs.
+var crashVolatileRegex = regexp.MustCompile(`0x[0-9a-fA-F]+|[0-9a-fA-F]{8,}|\d+|/tmp/\S+`)
+
+// crash records one failed agent attempt.
+type crash struct {
+	at          time.Time
+	storyID     string
+	iteration   int
+	err         string
+	stderr      string // Last lines of stderr
+	cause       string // Classified cause, see classifyFailure
+	fingerprint string
+}
+
+// Failure causes reported on crash events.
+const (
+	CauseAuth      = "auth"
+	CauseNetwork   = "network"
+	CauseOOM       = "oom"
+	CauseRateLimit = "rate-limit"
+)
+
+// failureCauses maps a cause to patterns seen in the agent's error or stderr.
+// Checked in order, so more specific causes come first.
+var failureCauses = []struct {
+	cause   string
+	pattern *regexp.Regexp
+}{
+	{CauseOOM, regexp.MustCompile(`(?i)out of memory|heap out of memory|oom-?kill|exit status 137|signal: killed|cannot allocate memory`)},
+	{CauseRateLimit, regexp.MustCompile(`(?i)rate.?limit|too many requests|\b429\b|overloaded|quota exceeded`)},
+	{CauseAuth, regexp.MustCompile(`(?i)unauthori[sz]ed|authentication|invalid api key|not logged in|please log ?in|\b401\b|\b403\b|forbidden|credentials`)},
+	{CauseNetwork, regexp.MustCompile(`(?i)connection (reset|refused|closed)|network|timed? ?out|dns|no such host|econn\w+|etimedout|enotfound|socket hang up|fetch failed|tls handshake`)},
+}
+
+// Delay returns how long to wait before retry attempt n (1-based). Attempts
+// past the end of RetryDelays double the last delay, up to a minute.
+func (c RetryConfig) Delay(attempt int) time.Duration {
+	if len(c.RetryDelays) == 0 || attempt < 1 {
+		return 0
+	}
+	if attempt <= len(c.RetryDelays) {
+		return c.RetryDelays[attempt-1]
+	}
+	delay := c.RetryDelays[len(c.RetryDelays)-1]
+	for i := len(c.RetryDelays); i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// SetCrashLimit sets how many crashes within CrashWindow pause the run.
+// Setting it to 0 disables the circuit breaker.
+func (l *Loop) SetCrashLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.crashLimit = limit
+}
+
+// captureStderr logs the agent's stderr and keeps its last lines for crash reports.
+func (l *Loop) captureStderr(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		l.logLine("[stderr] " + line)
+		l.mu.Lock()
+		l.stderrTail = append(l.stderrTail, line)
+		if len(l.stderrTail) > stderrTailLines {
+			l.stderrTail = l.stderrTail[len(l.stderrTail)-stderrTailLines:]
+		}
+		l.mu.Unlock()
+	}
+}
+
+// recordCrash notes a failed attempt. It returns the number of crashes within
+// CrashWindow and whether that is enough to open the circuit breaker.
+func (l *Loop) recordCrash(err error) (int, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	c := crash{
+		at:        time.Now(),
+		storyID:   l.currentStoryID,
+		iteration: l.iteration,
+		err:       err.Error(),
+		stderr:    strings.Join(l.stderrTail, "\n"),
+	}
+	c.cause = classifyFailure(c.err, c.stderr)
+	c.fingerprint = crashFingerprint(c.err, l.stderrTail)
+	l.logLine(fmt.Sprintf("[chief] %s crashed (%s, fingerprint %s): %s", l.provider.Name(), causeLabel(c.cause), c.fingerprint, c.err))
+	l.lastCrash = c
+
+	cutoff := c.at.Add(-CrashWindow)
+	recent := l.crashes[:0]
+	for _, prev := range l.crashes {
+		if prev.at.After(cutoff) {
+			recent = append(recent, prev)
+		}
+	}
+	l.crashes = append(recent, c)
+	return len(l.crashes), l.crashLimit > 0 && len(l.crashes) >= l.crashLimit
+}
+
+// classifyFailure guesses why the agent failed from its error and stderr.
+// It returns one of the Cause constants, or "" when the cause is unknown.
+func classifyFailure(err, stderr string) string {
+	text := err + "\n" + stderr
+	for _, fc := range failureCauses {
+		if fc.pattern.MatchString(text) {
+			return fc.cause
+		}
+	}
+	return ""
+}
+
+// causeLabel describes a failure cause for messages.
+func causeLabel(cause string) string {
+	switch cause {
+	case CauseAuth:
+		return "authentication error"
+	case CauseNetwork:
+		return "network error"
+	case CauseOOM:
+		return "out of memory"
+	case CauseRateLimit:
+		return "rate limited"
+	}
+	return "unknown cause"
+}
+
+// lastCrashEvent fills in the stderr excerpt and cause of the most recent
+// crash on a failure event.
+func (l *Loop) lastCrashEvent(e Event) Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e.Stderr = l.lastCrash.stderr
+	e.Cause = l.lastCrash.cause
+	return e
+}
+
+// crashFingerprint identifies a kind of crash: the error and the last stderr
+// line with volatile details removed, hashed to a short id.
+func crashFingerprint(err string, stderr []string) string {
+	last := ""
+	for i := len(stderr) - 1; i >= 0; i-- {
+		if strings.TrimSpace(stderr[i]) != "" {
+			last = stderr[i]
+			break
+		}
+	}
+	normalized := crashVolatileRegex.ReplaceAllString(strings.ToLower(err+"\n"+last), "#")
+	sum := sha1.Sum([]byte(strings.Join(strings.Fields(normalized), " ")))
+	return hex.EncodeToString(sum[:4])
+}
+
+// openCircuit pauses the run after repeated crashes, writes the failure
+// dossier, files it as an issue when enabled, and emits EventCircuitOpen.
+func (l *Loop) openCircuit(reason string) {
+	l.mu.Lock()
+	l.paused = true
+	iter := l.iteration
+	storyID := l.currentStoryID
+	crashes := append([]crash(nil), l.crashes...)
+	l.crashes = nil
+	fileIssues := l.fileIssues
+	l.mu.Unlock()
+
+	path := filepath.Join(filepath.Dir(l.prdPath), FailureFile)
+	dossier := failureDossier(reason, crashes)
+	text := fmt.Sprintf("Paused: %s, see %s", reason, path)
+	if fileIssues {
+		if url, err := l.fileFailureIssue(storyID, reason, dossier); err != nil {
+			text += fmt.Sprintf(" (could not file an issue: %v)", err)
+		} else {
+			dossier += fmt.Sprintf("**Issue:** %s\n", url)
+			text += ", filed " + url
+		}
+	}
+	if err := os.WriteFile(path, []byte(dossier), 0644); err != nil {
+		text = fmt.Sprintf("Paused: %s (could not write %s: %v)", reason, FailureFile, err)
+	}
+	l.events <- l.lastCrashEvent(Event{Type: EventCircuitOpen, Iteration: iter, StoryID: storyID, Text: text})
+}
+
+// failureDossier describes why a run was paused, grouping crashes by fingerprint.
+func failureDossier(reason string, crashes []crash) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Run Paused After Repeated Crashes\n\n")
+	fmt.Fprintf(&b, "%s: %s.\n\n", timefmt.DateTime(time.Now()), reason)
+	fmt.Fprintf(&b, "Resume the PRD once the cause is fixed. Crashes with the same fingerprint failed the same way.\n\n")
+
+	byFingerprint := make(map[string][]crash)
+	var order []string
+	for _, c := range crashes {
+		if _, ok := byFingerprint[c.fingerprint]; !ok {
+			order = append(order, c.fingerprint)
+		}
+		byFingerprint[c.fingerprint] = append(byFingerprint[c.fingerprint], c)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return len(byFingerprint[order[i]]) > len(byFingerprint[order[j]])
+	})
+
+	for _, fp := range order {
+		group := byFingerprint[fp]
+		last := group[len(group)-1]
+		fmt.Fprintf(&b, "## Fingerprint %s (%d crash(es))\n\n", fp, len(group))
+		if last.cause != "" {
+			fmt.Fprintf(&b, "Likely cause: %s.\n\n", causeLabel(last.cause))
+		}
+		for _, c := range group {
+			story := c.storyID
+			if story == "" {
+				story = "no story"
+			}
+			fmt.Fprintf(&b, "- %s: iteration %d, %s\n", timefmt.ClockSeconds(c.at), c.iteration, story)
+		}
+		fmt.Fprintf(&b, "\n**Error:** %s\n", last.err)
+		if last.stderr != "" {
+			fmt.Fprintf(&b, "\n**Last stderr:**\n\n```\n%s\n```\n", last.stderr)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
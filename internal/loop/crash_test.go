@@ -0,0 +1,142 @@
+package loop
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryConfig_Delay(t *testing.T) {
+	config := RetryConfig{RetryDelays: []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second}}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 0},
+		{1, 5 * time.Second},
+		{3, 20 * time.Second},
+		{4, 40 * time.Second},
+		{5, time.Minute},
+		{9, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := config.Delay(tt.attempt); got != tt.want {
+			t.Errorf("Delay(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+	if got := (RetryConfig{}).Delay(2); got != 0 {
+		t.Errorf("Delay() without delays = %s, want 0", got)
+	}
+}
+
+func TestCrashFingerprint(t *testing.T) {
+	a := crashFingerprint("Test exited with error: exit status 1", []string{"fetch failed after 3012ms", ""})
+	b := crashFingerprint("Test exited with error: exit status 1", []string{"fetch failed after 95ms"})
+	c := crashFingerprint("Test exited with error: exit status 137", []string{"Killed"})
+	if a != b {
+		t.Errorf("expected crashes differing only in numbers to share a fingerprint, got %s and %s", a, b)
+	}
+	if a == c {
+		t.Errorf("expected different crashes to have different fingerprints, both %s", a)
+	}
+	if len(a) != 8 {
+		t.Errorf("expected an 8 character fingerprint, got %q", a)
+	}
+}
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		err    string
+		stderr string
+		want   string
+	}{
+		{"exit status 1", "Error: Invalid API key · Please run /login", CauseAuth},
+		{"exit status 1", "API Error: 401 Unauthorized", CauseAuth},
+		{"exit status 1", "fetch failed: getaddrinfo ENOTFOUND api.anthropic.com", CauseNetwork},
+		{"exit status 1", "read: connection reset by peer", CauseNetwork},
+		{"exit status 137", "", CauseOOM},
+		{"exit status 1", "FATAL ERROR: Reached heap limit Allocation failed - JavaScript heap out of memory", CauseOOM},
+		{"exit status 1", "API Error: 429 Too Many Requests", CauseRateLimit},
+		{"exit status 1", "panic: nil map", ""},
+	}
+	for _, tt := range tests {
+		if got := classifyFailure(tt.err, tt.stderr); got != tt.want {
+			t.Errorf("classifyFailure(%q, %q) = %q, want %q", tt.err, tt.stderr, got, tt.want)
+		}
+	}
+}
+
+// runCrashingAgent runs a loop whose agent always fails after writing to stderr.
+func runCrashingAgent(t *testing.T, retries, crashLimit int) (*Loop, []Event, string) {
+	t.Helper()
+	dir := t.TempDir()
+	prdPath := createTestPRD(t, dir, false)
+	agent := filepath.Join(dir, "agent")
+	script := "#!/bin/bash\necho \"request $RANDOM failed: connection reset by peer\" >&2\nexit 1\n"
+	if err := os.WriteFile(agent, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	l := NewLoopWithEmbeddedPrompt(prdPath, 10, &mockProvider{cliPath: agent})
+	l.SetRetryConfig(RetryConfig{MaxRetries: retries, RetryDelays: []time.Duration{0}, Enabled: true})
+	l.SetCrashLimit(crashLimit)
+
+	done := make(chan error, 1)
+	go func() { done <- l.Run(t.Context()) }()
+	var events []Event
+	for event := range l.Events() {
+		events = append(events, event)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Run() error = %v, want the run paused", err)
+	}
+	return l, events, filepath.Join(dir, FailureFile)
+}
+
+func TestLoop_CircuitBreakerPausesRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		retries    int
+		crashLimit int
+		wantReason string
+		wantCount  string
+	}{
+		{name: "crash limit", retries: 10, crashLimit: 3, wantReason: "crashed 3 times within", wantCount: "(3 crash(es))"},
+		{name: "retries exhausted", retries: 1, crashLimit: 10, wantReason: "crashed 2 times in a row", wantCount: "(2 crash(es))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, events, dossierPath := runCrashingAgent(t, tt.retries, tt.crashLimit)
+			if !l.IsPaused() {
+				t.Error("expected the run to be paused")
+			}
+
+			last := events[len(events)-1]
+			if last.Type != EventCircuitOpen || !strings.Contains(last.Text, tt.wantReason) {
+				t.Errorf("expected EventCircuitOpen mentioning %q last, got %v %q", tt.wantReason, last.Type, last.Text)
+			}
+
+			if last.Cause != CauseNetwork || !strings.Contains(last.Stderr, "connection reset by peer") {
+				t.Errorf("expected the network cause and stderr on the event, got %q %q", last.Cause, last.Stderr)
+			}
+			for _, e := range events {
+				if e.Type == EventRetrying && !strings.Contains(e.Text, "(network error)") {
+					t.Errorf("expected the retry to name the cause, got %q", e.Text)
+				}
+			}
+
+			dossier, err := os.ReadFile(dossierPath)
+			if err != nil {
+				t.Fatalf("expected a failure dossier: %v", err)
+			}
+			for _, want := range []string{"## Fingerprint", tt.wantCount, "Likely cause: network error", "connection reset by peer", "US-001"} {
+				if !strings.Contains(string(dossier), want) {
+					t.Errorf("dossier missing %q:\n%s", want, dossier)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package loop
+
+import (
+	"fmt"
+
+	"github.com/minicodemonkey/chief/internal/git"
+)
+
+// Policies for uncommitted changes found in the working tree when a run starts.
+const (
+	DirtyAbort   = "abort"   // Refuse to start (default)
+	DirtyStash   = "stash"   // Stash them for the run and restore them when it ends
+	DirtyInclude = "include" // Leave them in place; the agent may commit them
+)
+
+// ValidateDirtyPolicy reports an unknown loop.dirtyWorktree value. "" is the default, abort.
+func ValidateDirtyPolicy(policy string) error {
+	switch policy {
+	case "", DirtyAbort, DirtyStash, DirtyInclude:
+		return nil
+	}
+	return fmt.Errorf("unknown policy %q: expected %q, %q, or %q", policy, DirtyAbort, DirtyStash, DirtyInclude)
+}
+
+// dirtyFiles returns the uncommitted changes in workDir, ignoring .chief/.
+// Outside a git repository there are none.
+func dirtyFiles(workDir string) []string {
+	if workDir == "" || !git.IsGitRepo(workDir) {
+		return nil
+	}
+	files, err := git.DirtyFiles(workDir)
+	if err != nil {
+		return nil
+	}
+	return files
+}
+
+// SetDirtyWorktree sets what the run does with the uncommitted changes it
+// starts with: DirtyStash or DirtyInclude.
+func (l *Loop) SetDirtyWorktree(policy string, files []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.dirtyPolicy = policy
+	l.dirtyFiles = files
+}
+
+// prepareDirtyWorktree applies the dirty worktree policy at the start of a
+// run, logging and emitting the action taken. It returns a function that
+// puts stashed changes back when the run ends.
+func (l *Loop) prepareDirtyWorktree() (func(), error) {
+	l.mu.Lock()
+	policy, files := l.dirtyPolicy, l.dirtyFiles
+	l.mu.Unlock()
+	if len(files) == 0 {
+		return func() {}, nil
+	}
+
+	var text string
+	restore := func() {}
+	switch policy {
+	case DirtyStash:
+		hash, err := git.Stash(l.workDir, fmt.Sprintf("chief: uncommitted changes before running %s", l.prdPath))
+		if err != nil {
+			return nil, err
+		}
+		text = fmt.Sprintf("Stashed %d uncommitted change(s); they are restored when the run ends", len(files))
+		if hash != "" {
+			restore = func() { l.restoreStash(hash, len(files)) }
+		}
+	case DirtyInclude:
+		text = fmt.Sprintf("Running with %d uncommitted change(s) in the working tree", len(files))
+	default:
+		return func() {}, nil
+	}
+	l.logLine("[chief] " + text)
+	l.events <- Event{Type: EventDirtyWorktree, Text: text}
+	return restore, nil
+}
+
+// restoreStash puts back the changes stashed at the start of the run.
+func (l *Loop) restoreStash(hash string, count int) {
+	l.mu.Lock()
+	iter := l.iteration
+	l.mu.Unlock()
+
+	event := Event{Type: EventDirtyWorktree, Iteration: iter, Text: fmt.Sprintf("Restored %d stashed change(s)", count)}
+	if err := git.RestoreStash(l.workDir, hash); err != nil {
+		event.Text = err.Error()
+		event.Err = err
+	}
+	l.logLine("[chief] " + event.Text)
+	l.events <- event
+}
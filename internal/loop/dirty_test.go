@@ -0,0 +1,105 @@
+package loop
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/git"
+)
+
+// initDirtyRepo creates a repository with one commit and one uncommitted change.
+func initDirtyRepo(t *testing.T) string {
+	t.Helper()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"git", "init"},
+		{"git", "config", "user.name", "Test"},
+		{"git", "config", "user.email", "test@test.com"},
+		{"git", "commit", "--allow-empty", "-m", "initial"},
+	} {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v failed: %s", args, out)
+		}
+	}
+	os.WriteFile(filepath.Join(dir, "wip.txt"), []byte("wip\n"), 0644)
+	return dir
+}
+
+func TestValidateDirtyPolicy(t *testing.T) {
+	for _, policy := range []string{"", DirtyAbort, DirtyStash, DirtyInclude} {
+		if err := ValidateDirtyPolicy(policy); err != nil {
+			t.Errorf("ValidateDirtyPolicy(%q) = %v", policy, err)
+		}
+	}
+	if err := ValidateDirtyPolicy("commit"); err == nil {
+		t.Error("expected an unknown policy to be rejected")
+	}
+}
+
+func TestManagerStart_DirtyWorktreeAborts(t *testing.T) {
+	dir := initDirtyRepo(t)
+	prdPath := createTestPRDWithName(t, dir, "auth")
+
+	m := NewManager(5, testProvider)
+	m.SetBaseDir(dir)
+	m.SetConfig(&config.Config{})
+	m.Register("auth", prdPath)
+	err := m.Start("auth")
+	if err == nil || !strings.Contains(err.Error(), "uncommitted") {
+		t.Fatalf("expected the run to be refused, got %v", err)
+	}
+
+	m.SetConfig(&config.Config{Loop: config.LoopConfig{DirtyWorktree: "later"}})
+	if err := m.Start("auth"); err == nil || !strings.Contains(err.Error(), "loop.dirtyWorktree") {
+		t.Errorf("expected an unknown policy to be reported, got %v", err)
+	}
+}
+
+func TestLoop_DirtyWorktreeStash(t *testing.T) {
+	dir := initDirtyRepo(t)
+	l := NewLoopWithWorkDir(filepath.Join(dir, "prd.md"), dir, "test", 5, testProvider)
+	l.SetDirtyWorktree(DirtyStash, dirtyFiles(dir))
+
+	restore, err := l.prepareDirtyWorktree()
+	if err != nil {
+		t.Fatalf("prepareDirtyWorktree() error = %v", err)
+	}
+	if e := <-l.events; e.Type != EventDirtyWorktree || !strings.Contains(e.Text, "Stashed 1") {
+		t.Errorf("expected a stash event, got %+v", e)
+	}
+	if files, _ := git.DirtyFiles(dir); len(files) != 0 {
+		t.Errorf("expected the run to start from a clean tree, got %v", files)
+	}
+
+	restore()
+	if e := <-l.events; e.Type != EventDirtyWorktree || e.Err != nil {
+		t.Errorf("expected a restore event, got %+v", e)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "wip.txt")); err != nil {
+		t.Error("expected the stashed file to be restored")
+	}
+}
+
+func TestLoop_DirtyWorktreeInclude(t *testing.T) {
+	dir := initDirtyRepo(t)
+	l := NewLoopWithWorkDir(filepath.Join(dir, "prd.md"), dir, "test", 5, testProvider)
+	l.SetDirtyWorktree(DirtyInclude, dirtyFiles(dir))
+
+	if _, err := l.prepareDirtyWorktree(); err != nil {
+		t.Fatalf("prepareDirtyWorktree() error = %v", err)
+	}
+	if e := <-l.events; e.Type != EventDirtyWorktree || !strings.Contains(e.Text, "Running with 1") {
+		t.Errorf("expected an include event, got %+v", e)
+	}
+	if files, _ := git.DirtyFiles(dir); len(files) != 1 {
+		t.Errorf("expected the change to stay in place, got %v", files)
+	}
+}
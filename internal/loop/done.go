@@ -0,0 +1,74 @@
+package loop
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/prd"
+	"github.com/minicodemonkey/chief/internal/verify"
+)
+
+// SetDoneDefinition sets the project's definition of done. Each criterion is
+// added to the acceptance criteria of every story the agent is given, and
+// the ones with a command are checked with `sh -c` in the working directory
+// before a story is marked done.
+func (l *Loop) SetDoneDefinition(criteria []config.DoneCriterion) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.verify.done = criteria
+}
+
+// withDone returns a copy of story with the definition of done added to its
+// acceptance criteria.
+func (l *Loop) withDone(story *prd.UserStory) *prd.UserStory {
+	l.mu.Lock()
+	done := l.verify.done
+	l.mu.Unlock()
+	if len(done) == 0 {
+		return story
+	}
+	s := *story
+	s.AcceptanceCriteria = slices.Clone(story.AcceptanceCriteria)
+	for _, c := range done {
+		s.AcceptanceCriteria = append(s.AcceptanceCriteria, c.Text)
+	}
+	return &s
+}
+
+// checkDone runs the commands of the definition of done after the agent
+// finished storyID and reports whether the story may be marked done. It
+// stops at the first check that fails and asks the agent to fix it.
+func (l *Loop) checkDone(ctx context.Context, storyID string) bool {
+	l.mu.Lock()
+	done := l.verify.done
+	l.mu.Unlock()
+
+	dir := l.effectiveWorkDir()
+	checked := 0
+	for _, c := range done {
+		if c.Command == "" {
+			continue
+		}
+		checked++
+		result := verify.Run(ctx, dir, c.Command)
+		if result.Passed {
+			continue
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		l.saveVerifyLog(storyID, result.Output)
+		l.emitVerify(storyID, "", fmt.Errorf("done check %q failed for %s, asking %s to fix it", c.Text, storyID, l.provider.Name()))
+		l.addFeedback(fmt.Sprintf("## Meet the Definition of Done\n\n"+
+			"You marked %s as done, but it doesn't meet the project's definition of done: %s. The check `%s` failed. The end of its output:\n\n```\n%s\n```\n\n"+
+			"The story is not done yet. Fix this, commit, and then output <chief-done/> again.",
+			storyID, c.Text, c.Command, verify.Tail(result.Output, verifyOutputLines)))
+		return false
+	}
+	if checked > 0 {
+		l.emitVerify(storyID, fmt.Sprintf("Definition of done met for %s", storyID), nil)
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package loop
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/config"
+)
+
+func TestPromptIncludesDoneDefinition(t *testing.T) {
+	l, _ := newVerifyTestLoop(t)
+	l.SetDoneDefinition([]config.DoneCriterion{{Text: "Docs are updated"}})
+
+	prompt, storyID, err := l.promptBuilderForPRD()()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if storyID != "US-001" {
+		t.Fatalf("storyID = %q, want US-001", storyID)
+	}
+	if !strings.Contains(prompt, "It works") || !strings.Contains(prompt, "Docs are updated") {
+		t.Errorf("prompt is missing the story's criteria or the definition of done:\n%s", prompt)
+	}
+}
+
+func TestCheckDone(t *testing.T) {
+	l, _ := newVerifyTestLoop(t)
+	l.SetDoneDefinition([]config.DoneCriterion{
+		{Text: "Docs are updated"},
+		{Text: "Lint passes", Command: "true"},
+	})
+	if !l.checkDone(context.Background(), "US-001") {
+		t.Fatal("checkDone() = false with passing checks")
+	}
+
+	l.SetDoneDefinition([]config.DoneCriterion{{Text: "Lint passes", Command: "echo 'unused variable'; exit 1"}})
+	if l.checkDone(context.Background(), "US-001") {
+		t.Fatal("checkDone() = true with a failing check")
+	}
+	if !strings.Contains(l.feedback, "Lint passes") || !strings.Contains(l.feedback, "unused variable") {
+		t.Errorf("feedback = %q, want the failed criterion and its output", l.feedback)
+	}
+	var failed bool
+	for _, e := range drainEvents(l) {
+		if e.Type == EventVerify && e.Err != nil {
+			failed = true
+		}
+	}
+	if !failed {
+		t.Error("expected a failed verify event")
+	}
+}
@@ -0,0 +1,138 @@
+package loop
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EventLogFile records a PRD's loop events with timestamps, one JSON object
+// per line, for `chief profile`. It lives in the PRD directory and is
+// appended to by every run.
+const EventLogFile = "events.jsonl"
+
+// RunStartRecord is the record type written when a run starts, separating
+// runs in the event log.
+const RunStartRecord = "RunStart"
+
+// eventDetailLen bounds the detail kept for each record.
+const eventDetailLen = 120
+
+// RunBase is the commit a run was pinned to with --base.
+type RunBase struct {
+	Ref    string // As given, e.g. "v2.3.0"
+	Commit string // Full hash Ref resolved to
+}
+
+// EventRecord is one line of the event log.
+type EventRecord struct {
+	Time      time.Time `json:"time"`
+	Type      string    `json:"type"`
+	Iteration int       `json:"iteration,omitempty"`
+	StoryID   string    `json:"storyId,omitempty"`
+	Tool      string    `json:"tool,omitempty"`
+	Detail    string    `json:"detail,omitempty"`       // What a tool call was for, or a step's outcome
+	Base      string    `json:"base,omitempty"`         // Commit a pinned run started from (RunStart only)
+	Labels    []string  `json:"labels,omitempty"`       // Labels the run was started with (RunStart only)
+	MessageID string    `json:"messageId,omitempty"`    // Assistant message the event came from
+	Input     int       `json:"inputTokens,omitempty"`  // Input tokens of that message, cached ones included
+	Output    int       `json:"outputTokens,omitempty"` // Output tokens of that message
+}
+
+// eventLog appends records to a PRD's event log.
+type eventLog struct {
+	f   *os.File
+	enc *json.Encoder
+}
+
+// openEventLog opens the event log in prdDir and marks the start of a run,
+// noting the base it was pinned to and its labels, if any. It returns nil
+// when the log can't be written; runs go on without it.
+func openEventLog(prdDir string, base RunBase, labels []string) *eventLog {
+	f, err := os.OpenFile(filepath.Join(prdDir, EventLogFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil
+	}
+	log := &eventLog{f: f, enc: json.NewEncoder(f)}
+	log.enc.Encode(EventRecord{Time: time.Now(), Type: RunStartRecord, Detail: base.Ref, Base: base.Commit, Labels: labels})
+	return log
+}
+
+// record appends an event. Assistant text isn't kept, only when it arrived.
+func (e *eventLog) record(event Event) {
+	if e == nil {
+		return
+	}
+	rec := EventRecord{
+		Time:      time.Now(),
+		Type:      event.Type.String(),
+		Iteration: event.Iteration,
+		StoryID:   event.StoryID,
+		Tool:      event.Tool,
+		MessageID: event.MessageID,
+	}
+	// A usage event repeats the tokens of the iteration's messages
+	if event.Type != EventUsage {
+		rec.Input, rec.Output = event.Usage.Input, event.Usage.Output
+	}
+	switch event.Type {
+	case EventAssistantText, EventToolResult:
+	case EventToolStart:
+		rec.Detail = toolDetail(event.ToolInput)
+	default:
+		rec.Detail = event.Text
+	}
+	if i := strings.IndexByte(rec.Detail, '\n'); i >= 0 {
+		rec.Detail = rec.Detail[:i]
+	}
+	if len(rec.Detail) > eventDetailLen {
+		rec.Detail = rec.Detail[:eventDetailLen-3] + "..."
+	}
+	e.enc.Encode(rec)
+}
+
+// close closes the event log.
+func (e *eventLog) close() {
+	if e != nil {
+		e.f.Close()
+	}
+}
+
+// toolDetail picks the input that says what a tool call did: the command,
+// file, or pattern.
+func toolDetail(input map[string]interface{}) string {
+	for _, key := range []string{"command", "file_path", "path", "pattern", "url", "query", "description"} {
+		if v, ok := input[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// ReadEventLog reads the records in an event log.
+func ReadEventLog(path string) ([]EventRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []EventRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var rec EventRecord
+		if json.Unmarshal(scanner.Bytes(), &rec) != nil {
+			continue // Skip a line cut short by a crash
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return records, nil
+}
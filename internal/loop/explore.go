@@ -0,0 +1,171 @@
+package loop
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/minicodemonkey/chief/embed"
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// ExploreDir holds the findings of each story's exploration pass, inside the
+// PRD directory.
+const ExploreDir = "explore"
+
+// writeTools are the agent tools that change files. The exploration pass may
+// only use them to write its findings.
+var writeTools = map[string]bool{
+	"Edit":         true,
+	"MultiEdit":    true,
+	"Write":        true,
+	"NotebookEdit": true,
+	"edit":         true,
+	"write":        true,
+	"patch":        true,
+}
+
+// ExplorePath returns where the exploration findings of a story are kept.
+func ExplorePath(prdPath, storyID string) string {
+	return filepath.Join(filepath.Dir(prdPath), ExploreDir, storyID+".md")
+}
+
+// SetExploreTurns splits each story into a read-only exploration pass of at
+// most n tool calls, whose findings are kept and given to every
+// implementation attempt, and the implementation itself. 0 turns it off.
+func (l *Loop) SetExploreTurns(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.exploreTurns = n
+}
+
+// exploreNote returns the prompt section with the current story's
+// exploration findings. Callers must hold l.mu.
+func (l *Loop) exploreNote() string {
+	if l.exploreTurns <= 0 || l.currentStoryID == "" {
+		return ""
+	}
+	path := ExplorePath(l.prdPath, l.currentStoryID)
+	findings, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return embed.GetExploreFindingsPrompt(l.currentStoryID, string(findings), path)
+}
+
+// exploreStory runs the exploration pass for storyID unless its findings are
+// already kept from an earlier attempt. A pass that fails leaves no findings;
+// the implementation then explores on its own.
+func (l *Loop) exploreStory(ctx context.Context, storyID string) {
+	l.mu.Lock()
+	turns := l.exploreTurns
+	iter := l.iteration
+	l.mu.Unlock()
+	if turns <= 0 || storyID == "" {
+		return
+	}
+	path := ExplorePath(l.prdPath, storyID)
+	if _, err := os.Stat(path); err == nil {
+		return
+	}
+
+	calls, err := l.runExplore(ctx, storyID, turns, path)
+	if ctx.Err() != nil || l.IsStopped() {
+		return
+	}
+	if err != nil {
+		os.Remove(path)
+		l.events <- Event{Type: EventExplore, Iteration: iter, StoryID: storyID, Text: err.Error(), Err: err}
+		return
+	}
+	l.events <- Event{Type: EventExplore, Iteration: iter, StoryID: storyID, Text: fmt.Sprintf("Explored %s in %d tool call(s); findings saved for every attempt", storyID, calls)}
+}
+
+// runExplore has the agent explore the codebase for storyID and write its
+// findings to path. It stops the agent once it has used its turns or tries
+// to change a file, and returns the number of tool calls made.
+func (l *Loop) runExplore(ctx context.Context, storyID string, turns int, path string) (int, error) {
+	p, err := prd.LoadPRD(l.prdPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to explore %s: %w", storyID, err)
+	}
+	i := slices.IndexFunc(p.UserStories, func(s prd.UserStory) bool { return s.ID == storyID })
+	if i < 0 {
+		return 0, fmt.Errorf("failed to explore %s: story not found", storyID)
+	}
+	story, _ := json.MarshalIndent(p.UserStories[i], "", "  ")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create %s: %w", ExploreDir, err)
+	}
+
+	workDir := l.effectiveWorkDir()
+	before := dirtyFiles(workDir)
+	prompt := embed.GetExplorePrompt(l.prdPath, prd.ProgressPath(l.prdPath), string(story), turns, path)
+
+	l.logLine(fmt.Sprintf("[chief] Exploring %s (up to %d tool calls)", storyID, turns))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cmd, err := l.provider.ReadOnlyCommand(ctx, prompt, workDir, path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to explore %s: %w", storyID, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return 0, fmt.Errorf("failed to explore %s: %w", storyID, err)
+	}
+	if l.logFile != nil {
+		cmd.Stderr = l.logFile
+	}
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start %s to explore %s: %w", l.provider.Name(), storyID, err)
+	}
+
+	calls := 0
+	var stopped, wrote error
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		l.logLine(line)
+		event := l.provider.ParseLine(line)
+		if event == nil || event.Type != EventToolStart || stopped != nil || wrote != nil {
+			continue
+		}
+		if writeTools[event.Tool] {
+			if target, _ := event.ToolInput["file_path"].(string); target == path {
+				continue // Writing the findings doesn't count against the budget
+			}
+			wrote = fmt.Errorf("stopped exploring %s: the agent tried to change files in a read-only pass", storyID)
+			cancel()
+			continue
+		}
+		calls++
+		if calls > turns {
+			stopped = fmt.Errorf("stopped exploring %s after %d tool calls without findings", storyID, turns)
+			cancel()
+		}
+	}
+	waitErr := cmd.Wait()
+
+	if after := dirtyFiles(workDir); !slices.Equal(before, after) {
+		return calls, fmt.Errorf("exploring %s changed the working tree; inspect `git status`", storyID)
+	}
+	if wrote != nil {
+		return calls, wrote
+	}
+	if data, err := os.ReadFile(path); err == nil && strings.TrimSpace(string(data)) != "" {
+		return calls, nil
+	}
+	if stopped != nil {
+		return calls, stopped
+	}
+	if waitErr != nil {
+		return calls, fmt.Errorf("exploring %s failed: %w", storyID, waitErr)
+	}
+	return calls, fmt.Errorf("%s did not write findings for %s", l.provider.Name(), storyID)
+}
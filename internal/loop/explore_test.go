@@ -0,0 +1,79 @@
+package loop
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const readToolLine = `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"1","name":"Read","input":{"file_path":"main.go"}}]}}`
+
+func TestExploreStory(t *testing.T) {
+	dir := t.TempDir()
+	prdPath := createTestPRD(t, dir, false)
+	findings := ExplorePath(prdPath, "US-001")
+	script := filepath.Join(dir, "mock-claude")
+	os.WriteFile(script, []byte("#!/bin/bash\necho '"+readToolLine+"'\necho '## Files' > "+findings+"\n"), 0755)
+
+	l := NewLoop(prdPath, "prompt", 1, &mockProvider{cliPath: script})
+	l.SetExploreTurns(5)
+	l.exploreStory(context.Background(), "US-001")
+
+	event := <-l.events
+	if event.Type != EventExplore || event.Err != nil || !strings.Contains(event.Text, "1 tool call") {
+		t.Fatalf("expected a successful exploration, got %+v", event)
+	}
+
+	l.currentStoryID = "US-001"
+	if note := l.exploreNote(); !strings.Contains(note, "## Files") {
+		t.Errorf("expected the findings in the prompt, got %q", note)
+	}
+
+	// Retries reuse the findings instead of exploring again
+	os.WriteFile(script, []byte("#!/bin/bash\nexit 1\n"), 0755)
+	l.exploreStory(context.Background(), "US-001")
+	select {
+	case event := <-l.events:
+		t.Errorf("expected no second exploration, got %+v", event)
+	default:
+	}
+}
+
+func TestExploreStory_Budget(t *testing.T) {
+	dir := t.TempDir()
+	prdPath := createTestPRD(t, dir, false)
+	script := filepath.Join(dir, "mock-claude")
+	lines := strings.Repeat("echo '"+readToolLine+"'\n", 3)
+	os.WriteFile(script, []byte("#!/bin/bash\n"+lines+"exec sleep 30\n"), 0755)
+
+	l := NewLoop(prdPath, "prompt", 1, &mockProvider{cliPath: script})
+	l.SetExploreTurns(2)
+	l.exploreStory(context.Background(), "US-001")
+
+	event := <-l.events
+	if event.Type != EventExplore || event.Err == nil || !strings.Contains(event.Text, "after 2 tool calls") {
+		t.Fatalf("expected the pass to be stopped at its budget, got %+v", event)
+	}
+	if _, err := os.Stat(ExplorePath(prdPath, "US-001")); !os.IsNotExist(err) {
+		t.Error("expected no findings to be kept")
+	}
+}
+
+func TestExploreStory_ReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	prdPath := createTestPRD(t, dir, false)
+	script := filepath.Join(dir, "mock-claude")
+	edit := `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"1","name":"Edit","input":{"file_path":"main.go"}}]}}`
+	os.WriteFile(script, []byte("#!/bin/bash\necho '"+edit+"'\nexec sleep 30\n"), 0755)
+
+	l := NewLoop(prdPath, "prompt", 1, &mockProvider{cliPath: script})
+	l.SetExploreTurns(5)
+	l.exploreStory(context.Background(), "US-001")
+
+	event := <-l.events
+	if event.Err == nil || !strings.Contains(event.Text, "read-only") {
+		t.Fatalf("expected the pass to be stopped for editing, got %+v", event)
+	}
+}
@@ -0,0 +1,70 @@
+package loop
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FeedFile is the project's lifecycle feed, relative to the project root: one
+// JSON object per line for every PRD's runs, so local automations (a file
+// watcher and a shell script) can react to Chief without the control socket.
+const FeedFile = ".chief/events.ndjson"
+
+// feedMaxSize is the size at which the feed is rotated to FeedFile + ".1",
+// replacing the previous rotation.
+const feedMaxSize = 1 << 20
+
+// Feed record types.
+const (
+	FeedRunStarted  = "run_started"
+	FeedStoryPassed = "story_passed"
+	FeedRunComplete = "run_complete" // Every story is done
+	FeedRunEnded    = "run_ended"    // The run paused, stopped, or failed; see State
+)
+
+// FeedRecord is one line of the feed.
+type FeedRecord struct {
+	Time    time.Time `json:"time"`
+	Event   string    `json:"event"`
+	PRD     string    `json:"prd"`
+	StoryID string    `json:"storyId,omitempty"`
+	State   string    `json:"state,omitempty"` // How the run ended (run_ended only)
+	Error   string    `json:"error,omitempty"`
+	Cost    float64   `json:"cost,omitempty"` // Dollars the agent reported the run cost (run_complete only)
+}
+
+// feedMu serializes writes to the feed from the loops of different PRDs.
+var feedMu sync.Mutex
+
+// appendFeed appends rec to the feed of the project at baseDir, rotating it
+// first when it has grown past feedMaxSize. The feed is best effort: runs go
+// on when it can't be written.
+func appendFeed(baseDir string, rec FeedRecord) {
+	if baseDir == "" {
+		return
+	}
+	rec.Time = time.Now()
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+
+	feedMu.Lock()
+	defer feedMu.Unlock()
+	path := filepath.Join(baseDir, FeedFile)
+	if info, err := os.Stat(path); err == nil && info.Size() >= feedMaxSize {
+		os.Rename(path, path+".1")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.Write(append(data, '\n'))
+}
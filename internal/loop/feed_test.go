@@ -0,0 +1,63 @@
+package loop
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readFeed(t *testing.T, path string) []FeedRecord {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var records []FeedRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec FeedRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("bad feed line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestAppendFeed(t *testing.T) {
+	dir := t.TempDir()
+	appendFeed(dir, FeedRecord{Event: FeedRunStarted, PRD: "auth"})
+	appendFeed(dir, FeedRecord{Event: FeedStoryPassed, PRD: "auth", StoryID: "US-001"})
+
+	records := readFeed(t, filepath.Join(dir, FeedFile))
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[1].Event != FeedStoryPassed || records[1].StoryID != "US-001" || records[1].Time.IsZero() {
+		t.Errorf("unexpected record: %+v", records[1])
+	}
+}
+
+func TestAppendFeed_Rotates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FeedFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Repeat("x", feedMaxSize)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	appendFeed(dir, FeedRecord{Event: FeedRunComplete, PRD: "auth"})
+
+	if info, err := os.Stat(path + ".1"); err != nil || info.Size() != feedMaxSize {
+		t.Fatalf("expected the full feed to be rotated, got %v", err)
+	}
+	if records := readFeed(t, path); len(records) != 1 || records[0].Event != FeedRunComplete {
+		t.Errorf("expected a fresh feed with one record, got %+v", records)
+	}
+}
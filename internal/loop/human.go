@@ -0,0 +1,57 @@
+package loop
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// waitingOnHumansError is returned by the prompt builder when the only stories
+// left are human tasks, stories that need review, skipped stories, stories in
+// a dependency cycle, or agent stories that depend on them or on unfinished
+// stories of other PRDs.
+type waitingOnHumansError struct {
+	stories []prd.UserStory // Human tasks
+	review  []prd.UserStory // Stories the agent gave up on
+	skipped []prd.UserStory // Stories a person set aside
+	cycle   []string        // Story IDs that depend on each other, first repeated last
+	prds    []string        // Stories of other PRDs, e.g. "auth/US-004"
+}
+
+func (e *waitingOnHumansError) Error() string {
+	var parts []string
+	if len(e.stories) > 0 {
+		parts = append(parts, fmt.Sprintf("Waiting for you: %s. Run `chief tasks pass <id>` when done, then resume.", describeStories(e.stories)))
+	}
+	if len(e.review) > 0 {
+		parts = append(parts, fmt.Sprintf("Needs review: %s. Fix them, or set their status back to todo in prd.md for the agent to retry, then resume.", describeStories(e.review)))
+	}
+	if len(e.skipped) > 0 {
+		parts = append(parts, fmt.Sprintf("Skipped: %s. Set their status back to todo in prd.md, or unskip them in the story order screen (o), then resume.", describeStories(e.skipped)))
+	}
+	if len(e.cycle) > 0 {
+		parts = append(parts, fmt.Sprintf("Dependency cycle: %s. Fix **Depends on:** in prd.md, then resume.", strings.Join(e.cycle, " -> ")))
+	}
+	if len(e.prds) > 0 {
+		parts = append(parts, fmt.Sprintf("Waiting on other PRDs: %s. Chief resumes this PRD when they pass in this session; otherwise resume it once they're done.", strings.Join(e.prds, ", ")))
+	}
+	return strings.Join(parts, " ")
+}
+
+// WaitingOn returns the stories of other PRDs ("auth/US-004") the loop paused
+// to wait for, or nil when it didn't.
+func (l *Loop) WaitingOn() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.waitingOn
+}
+
+// describeStories lists stories as "ID (Title)".
+func describeStories(stories []prd.UserStory) string {
+	tasks := make([]string, len(stories))
+	for i, s := range stories {
+		tasks[i] = fmt.Sprintf("%s (%s)", s.ID, s.Title)
+	}
+	return strings.Join(tasks, ", ")
+}
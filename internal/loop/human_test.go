@@ -0,0 +1,89 @@
+package loop
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoop_PausesWhenWaitingOnHumans(t *testing.T) {
+	dir := t.TempDir()
+	md := "# Test\n\n### US-001: Add API keys\n**Owner:** human\n- [ ] Keys in .env\n\n### US-002: Call the API\n**Depends on:** US-001\n- [ ] It works\n"
+	prdPath := filepath.Join(dir, "prd.md")
+	if err := os.WriteFile(prdPath, []byte(md), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l := NewLoopWithEmbeddedPrompt(prdPath, 5, testProvider)
+	done := make(chan error, 1)
+	go func() { done <- l.Run(t.Context()) }()
+	var events []Event
+	for event := range l.Events() {
+		events = append(events, event)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Run() error = %v, want the run paused", err)
+	}
+
+	if !l.IsPaused() {
+		t.Error("expected the run to be paused")
+	}
+	if l.Iteration() != 0 {
+		t.Errorf("expected no iteration to be spent, got %d", l.Iteration())
+	}
+	last := events[len(events)-1]
+	if last.Type != EventWaitingOnHuman || !strings.Contains(last.Text, "US-001 (Add API keys)") {
+		t.Errorf("expected EventWaitingOnHuman naming US-001 last, got %v %q", last.Type, last.Text)
+	}
+}
+
+func TestPromptBuilder_DependencyCycle(t *testing.T) {
+	dir := t.TempDir()
+	md := "# Test\n\n### US-001: Schema\n**Depends on:** US-002\n\n### US-002: Migration\n**Depends on:** US-001\n"
+	prdPath := filepath.Join(dir, "prd.md")
+	if err := os.WriteFile(prdPath, []byte(md), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := NewLoop(prdPath, "", 1, testProvider).promptBuilderForPRD()()
+	if _, ok := err.(*waitingOnHumansError); !ok {
+		t.Fatalf("expected the run to wait on a person, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "US-001 -> US-002 -> US-001") {
+		t.Errorf("expected the cycle to be named, got %q", err.Error())
+	}
+}
+
+func TestLoop_PausesWhenWaitingOnAnotherPRD(t *testing.T) {
+	prds := filepath.Join(t.TempDir(), ".chief", "prds")
+	for name, md := range map[string]string{
+		"auth":    "# Auth\n\n### US-004: Sessions\n- [ ] Sessions work\n",
+		"billing": "# Billing\n\n### US-010: Invoices\n**Depends on:** auth/US-004\n- [ ] Invoices work\n",
+	} {
+		if err := os.MkdirAll(filepath.Join(prds, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(prds, name, "prd.md"), []byte(md), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l := NewLoopWithEmbeddedPrompt(filepath.Join(prds, "billing", "prd.md"), 5, testProvider)
+	done := make(chan error, 1)
+	go func() { done <- l.Run(t.Context()) }()
+	var last Event
+	for event := range l.Events() {
+		last = event
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Run() error = %v, want the run paused", err)
+	}
+
+	if !l.IsPaused() || last.Type != EventWaitingOnHuman || !strings.Contains(last.Text, "auth/US-004") {
+		t.Errorf("expected a pause waiting on auth/US-004, got %v %q", last.Type, last.Text)
+	}
+	if got := l.WaitingOn(); len(got) != 1 || got[0] != "auth/US-004" {
+		t.Errorf("WaitingOn() = %v, want [auth/US-004]", got)
+	}
+}
@@ -0,0 +1,60 @@
+package loop
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/minicodemonkey/chief/internal/forge"
+)
+
+// createIssue opens an issue on the forge (replaceable in tests).
+var createIssue = forge.CreateIssue
+
+// IssuesFile records the issues filed for a PRD's stories, inside the PRD
+// directory, so a story that keeps failing is filed once.
+const IssuesFile = "issues.json"
+
+// SetFileIssues enables opening an issue with the failure dossier when
+// repeated crashes pause the run, so stuck work reaches the team's triage.
+func (l *Loop) SetFileIssues(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fileIssues = enabled
+}
+
+// fileFailureIssue opens an issue for a story whose crashes paused the run
+// and returns its URL. A story that already has an issue gets the same URL
+// back rather than a second issue.
+func (l *Loop) fileFailureIssue(storyID, reason, dossier string) (string, error) {
+	key := storyID
+	if key == "" {
+		key = "run"
+	}
+	path := filepath.Join(filepath.Dir(l.prdPath), IssuesFile)
+	issues := make(map[string]string)
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &issues)
+	}
+	if url := issues[key]; url != "" {
+		return url, nil
+	}
+
+	title := fmt.Sprintf("chief: %s paused after repeated crashes", l.prdName())
+	if storyID != "" {
+		title = fmt.Sprintf("chief: %s paused on %s after repeated crashes", l.prdName(), storyID)
+	}
+	body := fmt.Sprintf("Chief paused the %s run: %s.\n\n%s", l.prdName(), reason, dossier)
+	url, err := createIssue(l.effectiveWorkDir(), title, body)
+	if err != nil {
+		return "", err
+	}
+
+	issues[key] = url
+	data, err := json.MarshalIndent(issues, "", "  ")
+	if err != nil {
+		return url, err
+	}
+	return url, os.WriteFile(path, data, 0644)
+}
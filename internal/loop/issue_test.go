@@ -0,0 +1,62 @@
+package loop
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenCircuit_FilesIssue(t *testing.T) {
+	var titles []string
+	orig := createIssue
+	t.Cleanup(func() { createIssue = orig })
+	createIssue = func(dir, title, body string) (string, error) {
+		titles = append(titles, title)
+		if !strings.Contains(body, "## Fingerprint") {
+			t.Errorf("expected the dossier in the issue body, got %q", body)
+		}
+		return "https://github.com/o/r/issues/7", nil
+	}
+
+	dir := t.TempDir()
+	l := NewLoopWithEmbeddedPrompt(createTestPRD(t, dir, false), 10, &mockProvider{})
+	l.SetFileIssues(true)
+	l.currentStoryID = "US-001"
+	for i := 0; i < 2; i++ {
+		l.crashes = []crash{{storyID: "US-001", iteration: 1, err: "exit status 1", fingerprint: "abcd1234"}}
+		l.openCircuit("the agent crashed 3 times")
+		event := <-l.Events()
+		if !strings.Contains(event.Text, "filed https://github.com/o/r/issues/7") {
+			t.Errorf("expected the issue URL in the event, got %q", event.Text)
+		}
+	}
+	if len(titles) != 1 || !strings.Contains(titles[0], "on US-001") {
+		t.Errorf("expected one issue for US-001, got %q", titles)
+	}
+
+	dossier, _ := os.ReadFile(filepath.Join(dir, FailureFile))
+	if !strings.Contains(string(dossier), "**Issue:** https://github.com/o/r/issues/7") {
+		t.Errorf("expected the issue linked in the dossier:\n%s", dossier)
+	}
+}
+
+func TestOpenCircuit_IssueFailure(t *testing.T) {
+	orig := createIssue
+	t.Cleanup(func() { createIssue = orig })
+	createIssue = func(dir, title, body string) (string, error) {
+		return "", errors.New("failed to create issue: not logged in")
+	}
+
+	dir := t.TempDir()
+	l := NewLoopWithEmbeddedPrompt(createTestPRD(t, dir, false), 10, &mockProvider{})
+	l.SetFileIssues(true)
+	l.openCircuit("the agent crashed 3 times")
+	if event := <-l.Events(); !strings.Contains(event.Text, "could not file an issue: failed to create issue: not logged in") {
+		t.Errorf("expected the issue error in the event, got %q", event.Text)
+	}
+	if _, err := os.Stat(filepath.Join(dir, IssuesFile)); !os.IsNotExist(err) {
+		t.Error("expected no issue to be recorded")
+	}
+}
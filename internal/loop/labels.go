@@ -0,0 +1,80 @@
+package loop
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// labelRegex matches a run label: a name, optionally with a value, e.g.
+// "experiment" or "model=opus".
+var labelRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]+(=[A-Za-z0-9_.:/-]+)?$`)
+
+// ValidateLabel checks that a run label is a name or name=value without
+// spaces.
+func ValidateLabel(label string) error {
+	if !labelRegex.MatchString(label) {
+		return fmt.Errorf("invalid label %q: use a name or name=value of letters, numbers, and . _ -", label)
+	}
+	return nil
+}
+
+// LabelFilter picks runs by their labels.
+type LabelFilter struct {
+	With    []string // Runs must have every one of these labels
+	Without []string // Runs must have none of these labels
+}
+
+// IsZero returns true if the filter keeps every run.
+func (f LabelFilter) IsZero() bool {
+	return len(f.With) == 0 && len(f.Without) == 0
+}
+
+// Match returns true if a run with labels passes the filter. A filter label
+// without a value matches the name of any label, so "model" matches
+// "model=opus".
+func (f LabelFilter) Match(labels []string) bool {
+	for _, want := range f.With {
+		if !hasLabel(labels, want) {
+			return false
+		}
+	}
+	for _, unwanted := range f.Without {
+		if hasLabel(labels, unwanted) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasLabel returns true if labels contain want, or a name=value label named
+// want.
+func hasLabel(labels []string, want string) bool {
+	for _, label := range labels {
+		name, _, _ := strings.Cut(label, "=")
+		if label == want || (!strings.Contains(want, "=") && name == want) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterRuns returns the records of the runs in an event log that pass the
+// filter. Records written before the first run start belong to no run and are
+// kept only by a filter that keeps every run.
+func FilterRuns(records []EventRecord, filter LabelFilter) []EventRecord {
+	if filter.IsZero() {
+		return records
+	}
+	var kept []EventRecord
+	keep := false
+	for _, rec := range records {
+		if rec.Type == RunStartRecord {
+			keep = filter.Match(rec.Labels)
+		}
+		if keep {
+			kept = append(kept, rec)
+		}
+	}
+	return kept
+}
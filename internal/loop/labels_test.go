@@ -0,0 +1,63 @@
+package loop
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateLabel(t *testing.T) {
+	for _, label := range []string{"experiment", "model=opus", "prompt=v2.1", "ticket=ENG-12"} {
+		if err := ValidateLabel(label); err != nil {
+			t.Errorf("ValidateLabel(%q) = %v", label, err)
+		}
+	}
+	for _, label := range []string{"", "two words", "=opus", "model=", "a,b"} {
+		if err := ValidateLabel(label); err == nil {
+			t.Errorf("expected %q to be rejected", label)
+		}
+	}
+}
+
+func TestLabelFilterMatch(t *testing.T) {
+	labels := []string{"experiment", "model=opus"}
+	tests := []struct {
+		filter LabelFilter
+		want   bool
+	}{
+		{LabelFilter{}, true},
+		{LabelFilter{With: []string{"experiment"}}, true},
+		{LabelFilter{With: []string{"model"}}, true},
+		{LabelFilter{With: []string{"model=opus"}}, true},
+		{LabelFilter{With: []string{"model=sonnet"}}, false},
+		{LabelFilter{With: []string{"experiment", "nightly"}}, false},
+		{LabelFilter{Without: []string{"experiment"}}, false},
+		{LabelFilter{Without: []string{"model=sonnet"}}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Match(labels); got != tt.want {
+			t.Errorf("%+v.Match(%v) = %v, want %v", tt.filter, labels, got, tt.want)
+		}
+	}
+}
+
+func TestFilterRuns(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	records := []EventRecord{
+		{Time: start, Type: RunStartRecord},
+		{Time: start.Add(time.Second), Type: "IterationStart", StoryID: "US-001"},
+		{Time: start.Add(time.Minute), Type: RunStartRecord, Labels: []string{"experiment"}},
+		{Time: start.Add(time.Minute + time.Second), Type: "IterationStart", StoryID: "US-002"},
+	}
+
+	if got := FilterRuns(records, LabelFilter{}); len(got) != 4 {
+		t.Errorf("expected every record without a filter, got %d", len(got))
+	}
+	got := FilterRuns(records, LabelFilter{With: []string{"experiment"}})
+	if len(got) != 2 || got[1].StoryID != "US-002" {
+		t.Errorf("expected only the experiment run, got %+v", got)
+	}
+	got = FilterRuns(records, LabelFilter{Without: []string{"experiment"}})
+	if len(got) != 2 || got[1].StoryID != "US-001" {
+		t.Errorf("expected only the unlabeled run, got %+v", got)
+	}
+}
@@ -7,34 +7,48 @@ package loop
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/minicodemonkey/chief/embed"
+	"github.com/minicodemonkey/chief/internal/git"
 	"github.com/minicodemonkey/chief/internal/prd"
+	"github.com/minicodemonkey/chief/internal/prompt"
+	"github.com/minicodemonkey/chief/internal/pushgate"
+	"github.com/minicodemonkey/chief/internal/schedule"
+	"github.com/minicodemonkey/chief/internal/toolcache"
 )
 
 // RetryConfig configures automatic retry behavior on Claude crashes.
 type RetryConfig struct {
 	MaxRetries  int             // Maximum number of retry attempts (default: 3)
-	RetryDelays []time.Duration // Delays between retries (default: 0s, 5s, 15s)
+	RetryDelays []time.Duration // Delays between retries; later retries double the last delay (default: 5s, 10s, 20s)
 	Enabled     bool            // Whether retry is enabled (default: true)
 }
 
 // DefaultWatchdogTimeout is the default duration of silence before the watchdog kills a hung process.
 const DefaultWatchdogTimeout = 5 * time.Minute
 
+// DefaultStallTimeout is the default duration of silence before a run is reported as stalled.
+// It is shorter than the watchdog timeout so a hang is visible before the process is killed.
+const DefaultStallTimeout = 2 * time.Minute
+
+// errStoryTimeout is returned by runIteration when the story's time budget ran out.
+var errStoryTimeout = errors.New("story timeout")
+
 // DefaultRetryConfig returns the default retry configuration.
 func DefaultRetryConfig() RetryConfig {
 	return RetryConfig{
 		MaxRetries:  3,
-		RetryDelays: []time.Duration{0, 5 * time.Second, 15 * time.Second},
+		RetryDelays: []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second},
 		Enabled:     true,
 	}
 }
@@ -53,10 +67,55 @@ type Loop struct {
 	logFile         *os.File
 	mu              sync.Mutex
 	stopped         bool
+	stopGrace       time.Duration       // Time the agent gets to exit after an interrupt before it is killed
+	cancelRun       context.CancelFunc  // Cancels the running Run's context
+	crashLimit      int                 // Crashes within CrashWindow that pause the run (0 = never)
+	storyRetries    int                 // Failed iterations before a story is marked as needing review (0 = never)
+	storyFailures   map[string]int      // Failed iterations by story in this run
+	persistState    bool                // Save the run's state to RunStateFile so a killed run can resume
+	runStart        time.Time           // When the run started, moved back by the time of earlier starts when resumed
+	testFailures    map[string][]string // Stories whose verification failed on each test in this run
+	crashes         []crash             // Recent crashes, for the circuit breaker
+	stderrTail      []string            // Last lines of the running attempt's stderr
+	lastCrash       crash               // Most recent crash, attached to failure events
 	paused          bool
 	retryConfig     RetryConfig
 	lastOutputTime  time.Time
 	watchdogTimeout time.Duration
+	stallTimeout    time.Duration
+	storyTimeout    time.Duration // 0 = unlimited
+	storyStart      time.Time     // When work on storyStartID began
+	storyStartID    string
+	storyDeadline   time.Time           // Deadline for the running iteration (zero = none)
+	commitRules     *git.CommitRules    // nil = commit messages are not checked
+	trailers        bool                // Tag the agent's commits with Chief-Story and Chief-Run trailers
+	runID           string              // Identifies this run in Chief-Run trailers; kept across resumes
+	feedback        string              // Instructions for the next iteration after a failed commit, verification, or coverage check
+	promptNote      string              // Appended to the prompt for the running iteration
+	language        string              // Language the agent writes prose in ("" = English)
+	quietHours      schedule.QuietHours // Times no new iteration starts
+	squashTemplate  string              // Subject template for squashing a finished story ("" = don't squash)
+	storyBase       string              // HEAD when work on storyStartID began (only tracked when squashing or ignoring paths for verification)
+	coverage        coverageState
+	verify          verifyState
+	toolCache       *toolcache.Cache // nil = results of expensive commands are not reused
+	middlewares     prompt.Chain     // nil = only the language note is added to the prompt
+	stackedPRs      bool             // Open a stacked PR for each finished story
+	fileIssues      bool             // Open an issue with the failure dossier when crashes pause the run
+	dirtyPolicy     string           // What to do with uncommitted changes at the start of the run
+	remoteSync      string           // What to do when the branch is behind its remote at the start of the run ("" = don't check)
+	summaryEvery    int              // Completed stories between summary updates (0 = no summary)
+	exploreTurns    int              // Tool calls for a story's read-only exploration pass (0 = no pass)
+	pushPolicy      *pushgate.Policy // nil = the agent's pushes are not limited
+	scratchDir      string           // Scratch directory of scratchStory, removed when the story is done ("" = none yet)
+	scratchStory    string           // Story the scratch directory belongs to
+	dirtyFiles      []string         // Uncommitted changes found when the run was started
+	waitingOn       []string         // Stories of other PRDs the run paused to wait for
+	parallel        int              // Stories worked on side by side, each in its own worktree (<= 1 = one at a time)
+	laneDir         string           // Directory of the worktrees of a parallel run
+	laneSetup       string           // Command run in each new worktree of a parallel run ("" = none)
+	lanes           []*Loop          // Loops working on the stories of the running batch
+	runAlone        map[string]bool  // Stories whose merge conflicted, run on their own from now on
 	sawStoryDone    bool
 	currentStoryID  string
 }
@@ -71,6 +130,10 @@ func NewLoop(prdPath, prompt string, maxIter int, provider Provider) *Loop {
 		events:          make(chan Event, 100),
 		retryConfig:     DefaultRetryConfig(),
 		watchdogTimeout: DefaultWatchdogTimeout,
+		stallTimeout:    DefaultStallTimeout,
+		stopGrace:       DefaultStopGracePeriod,
+		crashLimit:      DefaultCrashLimit,
+		storyRetries:    DefaultStoryRetries,
 	}
 }
 
@@ -86,6 +149,10 @@ func NewLoopWithWorkDir(prdPath, workDir string, prompt string, maxIter int, pro
 		events:          make(chan Event, 100),
 		retryConfig:     DefaultRetryConfig(),
 		watchdogTimeout: DefaultWatchdogTimeout,
+		stallTimeout:    DefaultStallTimeout,
+		stopGrace:       DefaultStopGracePeriod,
+		crashLimit:      DefaultCrashLimit,
+		storyRetries:    DefaultStoryRetries,
 	}
 }
 
@@ -93,14 +160,16 @@ func NewLoopWithWorkDir(prdPath, workDir string, prompt string, maxIter int, pro
 // The prompt is rebuilt on each iteration to inline the current story context.
 func NewLoopWithEmbeddedPrompt(prdPath string, maxIter int, provider Provider) *Loop {
 	l := NewLoop(prdPath, "", maxIter, provider)
-	l.buildPrompt = promptBuilderForPRD(prdPath)
+	l.buildPrompt = l.promptBuilderForPRD()
+	l.persistState = true
 	return l
 }
 
 // promptBuilderForPRD returns a function that loads the PRD and builds a prompt
 // with the next story inlined. This is called before each iteration so that
 // newly completed stories are skipped. The returned storyID is stored on the Loop.
-func promptBuilderForPRD(prdPath string) func() (string, string, error) {
+func (l *Loop) promptBuilderForPRD() func() (string, string, error) {
+	prdPath := l.prdPath
 	return func() (string, string, error) {
 		p, err := prd.LoadPRD(prdPath)
 		if err != nil {
@@ -109,15 +178,17 @@ func promptBuilderForPRD(prdPath string) func() (string, string, error) {
 
 		story := p.NextStory()
 		if story == nil {
+			waiting, review, skipped, cycle, prds := p.WaitingOnHumans(), p.NeedingReview(), p.SkippedStories(), p.DependencyCycle(), p.ExternalBlockers()
+			if len(waiting) > 0 || len(review) > 0 || len(skipped) > 0 || len(cycle) > 0 || len(prds) > 0 {
+				return "", "", &waitingOnHumansError{stories: waiting, review: review, skipped: skipped, cycle: cycle, prds: prds}
+			}
 			return "", "", fmt.Errorf("all stories are complete")
 		}
 
 		// Mark the story as in-progress in the markdown file
 		_ = prd.SetStoryStatus(prdPath, story.ID, "in-progress")
 
-		storyCtx := p.NextStoryContext()
-
-		prompt := embed.GetPrompt(prd.ProgressPath(prdPath), *storyCtx, story.ID, story.Title)
+		prompt := embed.GetPrompt(prd.ProgressPath(prdPath), prd.StoryContext(l.withDone(story)), story.ID, story.Title)
 		return prompt, story.ID, nil
 	}
 }
@@ -136,6 +207,7 @@ func (l *Loop) Iteration() int {
 
 // Run executes the agent loop until completion or max iterations.
 func (l *Loop) Run(ctx context.Context) error {
+	defer close(l.events)
 	if l.provider == nil {
 		return fmt.Errorf("loop provider is not configured")
 	}
@@ -149,24 +221,83 @@ func (l *Loop) Run(ctx context.Context) error {
 		return fmt.Errorf("failed to open log file: %w", err)
 	}
 	defer l.logFile.Close()
-	defer close(l.events)
+
+	// Stop cancels this context once the grace period is over, so commands
+	// still running at that point (verification, coverage) are stopped too.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	l.mu.Lock()
+	l.cancelRun = cancel
+	l.waitingOn = nil
+	l.mu.Unlock()
+
+	restore, err := l.prepareDirtyWorktree()
+	if err != nil {
+		return err
+	}
+	defer restore()
+	defer l.removeScratch()
+
+	if !l.syncRemote() {
+		l.Pause()
+		return nil
+	}
+
+	if p, err := prd.LoadPRD(l.prdPath); err == nil {
+		if err := p.Validate(); err != nil {
+			l.events <- Event{Type: EventError, Err: err}
+			return err
+		}
+	}
+	l.resumeRunState()
+
+	l.mu.Lock()
+	parallel := l.parallel > 1
+	l.mu.Unlock()
+	if parallel {
+		return l.runParallel(ctx)
+	}
 
 	for {
 		l.mu.Lock()
 		if l.stopped {
+			storyID := l.currentStoryID
 			l.mu.Unlock()
+			l.finishStop(storyID)
 			return nil
 		}
 		if l.paused {
 			l.mu.Unlock()
 			return nil
 		}
+		stacked := l.stackedPRs
+		l.mu.Unlock()
+
+		// Stop before starting more work while a stacked PR has changes requested
+		if stacked {
+			if blocked := l.stackBlocked(); blocked != "" {
+				l.emitWithStory(EventStackedPR, "", "", errors.New(blocked))
+				l.Pause()
+				return nil
+			}
+		}
+
+		// Wait out quiet hours, then check again whether to continue
+		if until, quiet := l.quietUntil(); quiet {
+			if err := l.waitQuietHours(ctx, until); err != nil && !l.IsStopped() {
+				return err
+			}
+			continue
+		}
+
+		l.mu.Lock()
 		l.iteration++
 		currentIter := l.iteration
 		l.mu.Unlock()
 
 		// Check if max iterations reached
 		if currentIter > l.maxIter {
+			l.clearRunState()
 			l.events <- Event{
 				Type:      EventMaxIterationsReached,
 				Iteration: currentIter - 1,
@@ -177,10 +308,27 @@ func (l *Loop) Run(ctx context.Context) error {
 		// Rebuild prompt if builder is set (inlines the current story each iteration)
 		if l.buildPrompt != nil {
 			prompt, storyID, err := l.buildPrompt()
+			var waiting *waitingOnHumansError
+			if errors.As(err, &waiting) {
+				l.events <- Event{
+					Type:      EventWaitingOnHuman,
+					Iteration: currentIter - 1,
+					Text:      waiting.Error(),
+				}
+				l.mu.Lock()
+				l.iteration--
+				l.paused = true
+				l.waitingOn = waiting.prds
+				l.mu.Unlock()
+				return nil
+			}
 			if err != nil {
+				l.flushPushes("", false, true)
+				l.clearRunState()
 				l.events <- Event{
 					Type:      EventComplete,
 					Iteration: currentIter,
+					Text:      l.coverageSummary(),
 				}
 				return nil
 			}
@@ -191,22 +339,76 @@ func (l *Loop) Run(ctx context.Context) error {
 			l.mu.Unlock()
 		}
 
-		// Send iteration start event with current story ID
+		// Send iteration start event with current story ID. The story timeout
+		// covers every iteration spent on the same story, not just this one.
 		l.mu.Lock()
 		iterStoryID := l.currentStoryID
+		newStory := l.storyStart.IsZero() || l.storyStartID != iterStoryID
+		if newStory {
+			l.storyStart = time.Now()
+			l.storyStartID = iterStoryID
+		}
+		squashing := l.squashTemplate != ""
+		trackBase := squashing || len(l.verify.ignore) > 0
+		l.storyDeadline = time.Time{}
+		if l.storyTimeout > 0 {
+			l.storyDeadline = l.storyStart.Add(l.storyTimeout)
+		}
+		l.promptNote = l.feedback
+		l.feedback = ""
+		rules := l.commitRules
+		trailers := l.trailers
 		l.mu.Unlock()
+
+		// A resumed run restores the base, unless its state predates storing it
+		if trackBase && (newStory || l.storyBase == "") {
+			base := git.HeadCommit(l.effectiveWorkDir())
+			l.mu.Lock()
+			l.storyBase = base
+			l.mu.Unlock()
+		}
+		if newStory {
+			l.recordCoverageBaseline(ctx)
+		}
+		l.saveRunState()
+
+		// Remember HEAD so commits made during this iteration can be checked
+		var baseCommit string
+		if rules != nil || trailers {
+			baseCommit = git.HeadCommit(l.effectiveWorkDir())
+		}
 		l.events <- Event{
 			Type:      EventIterationStart,
 			Iteration: currentIter,
 			StoryID:   iterStoryID,
 		}
 
+		// Explore the story once, before its first attempt
+		l.exploreStory(ctx, iterStoryID)
+
 		// Run a single iteration with retry logic
-		if err := l.runIterationWithRetry(ctx); err != nil {
-			l.events <- Event{
+		err := l.runIterationWithRetry(ctx)
+		if l.IsStopped() {
+			// Whatever the agent finished is left for the next run to verify
+			l.finishStop(iterStoryID)
+			return nil
+		}
+		if errors.Is(err, errCircuitOpen) {
+			return nil // Paused; the failure dossier explains why
+		}
+		if errors.Is(err, errStoryTimeout) {
+			// Ask the agent to save its progress, then stop so a human can decide
+			// how to continue instead of looping on the same story.
+			err = l.wrapUp(ctx, iterStoryID)
+			if err == nil {
+				return nil
+			}
+		}
+		if err != nil {
+			l.events <- l.lastCrashEvent(Event{
 				Type: EventError,
 				Err:  err,
-			}
+			})
 			return err
 		}
 
@@ -217,15 +419,36 @@ func (l *Loop) Run(ctx context.Context) error {
 		default:
 		}
 
+		if trailers {
+			l.tagCommits(baseCommit, iterStoryID)
+		}
+		if rules != nil {
+			l.checkCommits(baseCommit, iterStoryID, *rules)
+		}
+
 		// If the agent emitted <chief-done/>, mark the story as done in prd.md
 		l.mu.Lock()
 		saw := l.sawStoryDone
 		storyID := l.currentStoryID
 		l.sawStoryDone = false
 		l.mu.Unlock()
-		if saw && storyID != "" {
+		storyDone := saw && storyID != "" && l.checkVerification(ctx, storyID) && l.checkDone(ctx, storyID) && l.checkBrowser(ctx, storyID) && l.checkCoverage(ctx, storyID)
+		if storyDone {
 			_ = prd.SetStoryStatus(l.prdPath, storyID, "done")
+			l.emitWithStory(EventStoryPassed, storyID, "", nil)
+			l.removeScratch()
+			if squashing {
+				l.squashStory(storyID)
+			}
+			if stacked {
+				l.openStackedPR(storyID)
+			}
+			l.updateSummary(ctx)
+		} else if !l.pauseFailureCluster(storyID) {
+			l.recordStoryFailure(storyID)
 		}
+		l.flushPushes(storyID, storyDone, false)
+		l.saveRunState()
 		// buildPrompt on the next iteration will return error if all stories are complete,
 		// which causes EventComplete to be emitted above.
 
@@ -243,6 +466,7 @@ func (l *Loop) Run(ctx context.Context) error {
 func (l *Loop) runIterationWithRetry(ctx context.Context) error {
 	l.mu.Lock()
 	config := l.retryConfig
+	l.lastCrash = crash{}
 	l.mu.Unlock()
 
 	var lastErr error
@@ -253,24 +477,24 @@ func (l *Loop) runIterationWithRetry(ctx context.Context) error {
 				return lastErr
 			}
 
-			// Get delay for this retry
-			delayIdx := attempt - 1
-			if delayIdx >= len(config.RetryDelays) {
-				delayIdx = len(config.RetryDelays) - 1
-			}
-			delay := config.RetryDelays[delayIdx]
+			// Back off exponentially between retries
+			delay := config.Delay(attempt)
 
 			// Emit retry event
 			l.mu.Lock()
 			iter := l.iteration
 			l.mu.Unlock()
-			l.events <- Event{
+			retry := l.lastCrashEvent(Event{
 				Type:       EventRetrying,
 				Iteration:  iter,
 				RetryCount: attempt,
 				RetryMax:   config.MaxRetries,
-				Text:       fmt.Sprintf("%s crashed, retrying (%d/%d)...", l.provider.Name(), attempt, config.MaxRetries),
+			})
+			retry.Text = fmt.Sprintf("%s crashed, retrying (%d/%d)...", l.provider.Name(), attempt, config.MaxRetries)
+			if retry.Cause != "" {
+				retry.Text = fmt.Sprintf("%s crashed (%s), retrying (%d/%d)...", l.provider.Name(), causeLabel(retry.Cause), attempt, config.MaxRetries)
 			}
+			l.events <- retry
 
 			// Wait before retry
 			if delay > 0 {
@@ -295,6 +519,12 @@ func (l *Loop) runIterationWithRetry(ctx context.Context) error {
 		if err == nil {
 			return nil // Success
 		}
+		if errors.Is(err, errStoryTimeout) {
+			return err // Retrying would only exceed the budget further
+		}
+		if errors.Is(err, errPromptMiddleware) {
+			return err // Retrying would fail the same way
+		}
 
 		// Check if this is a context cancellation (don't retry)
 		if ctx.Err() != nil {
@@ -310,43 +540,123 @@ func (l *Loop) runIterationWithRetry(ctx context.Context) error {
 		}
 
 		lastErr = err
+		if crashes, open := l.recordCrash(err); open {
+			l.openCircuit(fmt.Sprintf("%s crashed %d times within %s", l.provider.Name(), crashes, CrashWindow))
+			return errCircuitOpen
+		}
 	}
 
+	l.mu.Lock()
+	breaker := l.crashLimit > 0
+	l.mu.Unlock()
+	if breaker && config.Enabled {
+		l.openCircuit(fmt.Sprintf("%s crashed %d times in a row", l.provider.Name(), config.MaxRetries+1))
+		return errCircuitOpen
+	}
 	return fmt.Errorf("max retries (%d) exceeded: %w", config.MaxRetries, lastErr)
 }
 
 // runIteration spawns the agent and processes its output.
 func (l *Loop) runIteration(ctx context.Context) error {
 	workDir := l.effectiveWorkDir()
-	cmd := l.provider.LoopCommand(ctx, l.prompt, workDir)
 	l.mu.Lock()
-	l.agentCmd = cmd
+	storyID := l.currentStoryID
+	l.mu.Unlock()
+	scratch := l.scratchFor(storyID)
+
+	l.mu.Lock()
+	prompt := l.prompt
+	if note := embed.GetLanguagePrompt(l.language); note != "" && l.middlewares == nil {
+		prompt += "\n\n" + note
+	}
+	if note := l.cacheNote(); note != "" {
+		prompt += "\n\n" + note
+	}
+	if note := l.summaryNote(); note != "" {
+		prompt += "\n\n" + note
+	}
+	if note := l.exploreNote(); note != "" {
+		prompt += "\n\n" + note
+	}
+	if note := embed.GetScratchPrompt(scratch); note != "" {
+		prompt += "\n\n" + note
+	}
+	if l.promptNote != "" {
+		prompt += "\n\n" + l.promptNote
+	}
+	cache := l.toolCache
+	middlewares := l.middlewares
+	pushPolicy := l.pushPolicy
+	l.mu.Unlock()
+	prompt, err := l.applyMiddlewares(ctx, middlewares, prompt)
+	if err != nil {
+		return err
+	}
+	cmd := l.provider.LoopCommand(ctx, prompt, workDir)
+	if cache != nil {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, toolcache.DirEnv+"="+cache.Dir)
+	}
+	if scratch != "" {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, ScratchEnv+"="+scratch)
+	}
+	if pushPolicy != nil {
+		if err := pushPolicy.Apply(cmd); err != nil {
+			return fmt.Errorf("failed to set up the push policy: %w", err)
+		}
+	}
+	l.mu.Lock()
+	l.stderrTail = nil
 	// Initialize watchdog state
 	l.lastOutputTime = time.Now()
 	watchdogTimeout := l.watchdogTimeout
+	stallTimeout := l.stallTimeout
+	deadline := l.storyDeadline
 	l.mu.Unlock()
 
 	// Create pipes for stdout and stderr
-	stdout, err := l.agentCmd.StdoutPipe()
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
 
-	stderr, err := l.agentCmd.StderrPipe()
+	stderr, err := cmd.StderrPipe()
 	if err != nil {
 		return fmt.Errorf("failed to create stderr pipe: %w", err)
 	}
 
-	// Start the command
-	if err := l.agentCmd.Start(); err != nil {
+	// Start the command, then publish it so Stop and the timers can kill it
+	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("failed to start %s: %w", l.provider.Name(), err)
 	}
+	l.mu.Lock()
+	l.agentCmd = cmd
+	l.mu.Unlock()
 
 	// Start watchdog goroutine to detect hung processes
 	watchdogDone := make(chan struct{})
 	var watchdogFired atomic.Bool
-	if watchdogTimeout > 0 {
-		go l.runWatchdog(watchdogTimeout, watchdogDone, &watchdogFired)
+	if watchdogTimeout > 0 || stallTimeout > 0 {
+		go l.runWatchdog(watchdogTimeout, stallTimeout, watchdogDone, &watchdogFired)
+	}
+
+	// Kill the agent when the story's time budget runs out
+	var storyTimedOut atomic.Bool
+	if !deadline.IsZero() {
+		timer := time.AfterFunc(time.Until(deadline), func() {
+			storyTimedOut.Store(true)
+			l.mu.Lock()
+			if l.agentCmd != nil && l.agentCmd.Process != nil {
+				l.agentCmd.Process.Kill()
+			}
+			l.mu.Unlock()
+		})
+		defer timer.Stop()
 	}
 
 	// Process stdout in a separate goroutine
@@ -358,10 +668,10 @@ func (l *Loop) runIteration(ctx context.Context) error {
 		l.processOutput(stdout)
 	}()
 
-	// Log stderr to the log file
+	// Log stderr to the log file, keeping its tail for crash reports
 	go func() {
 		defer wg.Done()
-		l.logStream(stderr, "[stderr] ")
+		l.captureStderr(stderr)
 	}()
 
 	// Wait for output processing to complete
@@ -371,7 +681,7 @@ func (l *Loop) runIteration(ctx context.Context) error {
 	close(watchdogDone)
 
 	// Wait for the command to finish
-	if err := l.agentCmd.Wait(); err != nil {
+	if err := cmd.Wait(); err != nil {
 		// If the context was cancelled, don't treat it as an error
 		if ctx.Err() != nil {
 			return ctx.Err()
@@ -383,6 +693,9 @@ func (l *Loop) runIteration(ctx context.Context) error {
 		if stopped {
 			return nil
 		}
+		if storyTimedOut.Load() {
+			return errStoryTimeout
+		}
 		// Check if the watchdog killed the process
 		if watchdogFired.Load() {
 			return fmt.Errorf("watchdog timeout: no output for %s", watchdogTimeout)
@@ -397,11 +710,202 @@ func (l *Loop) runIteration(ctx context.Context) error {
 	return nil
 }
 
-// runWatchdog monitors lastOutputTime and kills the process if no output is received
-// within the timeout duration. It stops when watchdogDone is closed.
-func (l *Loop) runWatchdog(timeout time.Duration, done <-chan struct{}, fired *atomic.Bool) {
-	// Check interval scales with timeout: 1/5 of timeout, clamped to [10ms, 10s]
-	checkInterval := timeout / 5
+// checkCommits validates the subjects of commits made since baseCommit. When
+// any fail, it emits EventCommitRejected and queues instructions for the next
+// iteration to reword them, so bad messages are fixed before they pile up.
+func (l *Loop) checkCommits(baseCommit, storyID string, rules git.CommitRules) {
+	commits, err := git.CommitsSince(l.effectiveWorkDir(), baseCommit)
+	if err != nil {
+		return
+	}
+
+	var failures []string
+	for _, c := range commits {
+		problems := git.CheckCommitSubject(c.Subject, storyID, rules)
+		if len(problems) == 0 {
+			continue
+		}
+		short := c.Hash
+		if len(short) > 7 {
+			short = short[:7]
+		}
+		failures = append(failures, fmt.Sprintf("- %s %q: %s", short, c.Subject, strings.Join(problems, "; ")))
+	}
+	if len(failures) == 0 {
+		return
+	}
+
+	l.mu.Lock()
+	iter := l.iteration
+	l.mu.Unlock()
+	l.events <- Event{
+		Type:      EventCommitRejected,
+		Iteration: iter,
+		StoryID:   storyID,
+		Text:      fmt.Sprintf("%d commit message(s) need rewording, asking %s to amend", len(failures), l.provider.Name()),
+	}
+
+	var b strings.Builder
+	b.WriteString("## Fix Commit Messages First\n\n")
+	b.WriteString("These commits from the previous iteration do not follow the project's commit message rules:\n\n")
+	b.WriteString(strings.Join(failures, "\n"))
+	b.WriteString("\n\nBefore anything else, reword them without changing their contents: use `git commit --amend` for the latest commit, or a non-interactive `git rebase` with `reword` for older ones.")
+	if storyID != "" {
+		fmt.Fprintf(&b, " Use the format `%s`.", commitFormat(rules, storyID))
+	}
+
+	l.addFeedback(b.String())
+}
+
+// commitFormat returns the subject format checkCommits asks for: the story ID
+// and title, after the first allowed prefix when prefixes are required.
+func commitFormat(rules git.CommitRules, storyID string) string {
+	subject := storyID + " - <story title>"
+	if len(rules.Prefixes) > 0 {
+		subject = strings.TrimSuffix(rules.Prefixes[0], ":") + ": " + subject
+	}
+	return subject
+}
+
+// addFeedback queues instructions to append to the next iteration's prompt.
+func (l *Loop) addFeedback(note string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.feedback != "" {
+		l.feedback += "\n\n"
+	}
+	l.feedback += note
+}
+
+// squashStory squashes the commits made for a finished story into one commit
+// whose subject comes from squashTemplate. The original commits are kept under
+// refs/chief/squash/<prd>/<story>. Stories with fewer than two commits are left alone.
+func (l *Loop) squashStory(storyID string) {
+	l.mu.Lock()
+	base := l.storyBase
+	template := l.squashTemplate
+	iter := l.iteration
+	trailers, runID := l.trailers, l.runID
+	l.mu.Unlock()
+
+	// Without the commit the story started from, there is no telling which
+	// commits are the story's, and squashing since the root would rewrite
+	// the whole history
+	if base == "" {
+		l.events <- Event{Type: EventStorySquashed, Iteration: iter, StoryID: storyID,
+			Err:  fmt.Errorf("the commit %s started from is unknown", storyID),
+			Text: fmt.Sprintf("Not squashing %s: the commit it started from is unknown", storyID)}
+		return
+	}
+
+	workDir := l.effectiveWorkDir()
+	commits, err := git.CommitsSince(workDir, base)
+	if err != nil || len(commits) < 2 {
+		return
+	}
+	// Rewriting pushed commits would leave a branch that can only be pushed
+	// with force, which the push policy doesn't allow
+	if pushed, err := git.PushedSince(workDir, base); err != nil || pushed {
+		if err == nil {
+			err = fmt.Errorf("some of its commits are already pushed")
+		}
+		l.events <- Event{Type: EventStorySquashed, Iteration: iter, StoryID: storyID, Err: err,
+			Text: fmt.Sprintf("Not squashing %s: %v", storyID, err)}
+		return
+	}
+
+	title := storyID
+	if p, err := prd.LoadPRD(l.prdPath); err == nil {
+		for _, story := range p.UserStories {
+			if story.ID == storyID {
+				title = story.Title
+				break
+			}
+		}
+	}
+
+	backupRef := fmt.Sprintf("refs/chief/squash/%s/%s", filepath.Base(filepath.Dir(l.prdPath)), storyID)
+	event := Event{Type: EventStorySquashed, Iteration: iter, StoryID: storyID}
+	message := squashMessage(template, storyID, title, commits)
+	if trailers {
+		// Keep the provenance of the commits being replaced
+		if tagged, err := git.WithTrailers(workDir, message, git.Provenance{Story: storyID, Run: runID}); err == nil {
+			message = tagged
+		}
+	}
+	if _, err := git.SquashCommits(workDir, base, message, backupRef); err != nil {
+		event.Err = err
+		event.Text = fmt.Sprintf("Could not squash %s: %v", storyID, err)
+	} else {
+		event.Text = fmt.Sprintf("Squashed %d commits for %s (originals at %s)", len(commits), storyID, backupRef)
+	}
+	l.events <- event
+}
+
+// squashMessage builds the commit message for a squashed story: the templated
+// subject followed by the subjects of the original commits.
+func squashMessage(template, storyID, title string, commits []git.Commit) string {
+	subject := strings.ReplaceAll(template, "{{STORY_ID}}", storyID)
+	subject = strings.ReplaceAll(subject, "{{STORY_TITLE}}", title)
+
+	var b strings.Builder
+	b.WriteString(subject)
+	b.WriteString("\n\nSquashed commits:\n")
+	for _, c := range commits {
+		fmt.Fprintf(&b, "- %s\n", c.Subject)
+	}
+	return b.String()
+}
+
+// wrapUp runs a single iteration that asks the agent to commit or describe the
+// partial progress on a story that exceeded its time budget.
+func (l *Loop) wrapUp(ctx context.Context, storyID string) error {
+	l.mu.Lock()
+	iter := l.iteration
+	timeout := l.storyTimeout
+	l.mu.Unlock()
+
+	title := storyID
+	if p, err := prd.LoadPRD(l.prdPath); err == nil {
+		for _, story := range p.UserStories {
+			if story.ID == storyID {
+				title = story.Title
+				break
+			}
+		}
+	}
+
+	l.events <- Event{
+		Type:      EventStoryTimeout,
+		Iteration: iter,
+		StoryID:   storyID,
+		Text:      fmt.Sprintf("%s exceeded the %s story timeout, asking %s to wrap up", storyID, timeout, l.provider.Name()),
+	}
+
+	l.mu.Lock()
+	l.prompt = embed.GetWrapUpPrompt(prd.ProgressPath(l.prdPath), storyID, title, timeout.String())
+	l.storyDeadline = time.Time{}
+	l.storyStart = time.Time{}
+	l.promptNote = ""
+	l.mu.Unlock()
+
+	if err := l.runIteration(ctx); err != nil {
+		return fmt.Errorf("wrap-up after story timeout failed: %w", err)
+	}
+	return nil
+}
+
+// runWatchdog monitors lastOutputTime. After stallTimeout of silence it emits a
+// single EventStalled (re-armed once output resumes); after watchdogTimeout it
+// kills the process so the iteration can be retried. Either timeout may be 0 to
+// disable that stage. It stops when watchdogDone is closed.
+func (l *Loop) runWatchdog(timeout, stallTimeout time.Duration, done <-chan struct{}, fired *atomic.Bool) {
+	// Check interval scales with the shortest timeout: 1/5 of it, clamped to [10ms, 10s]
+	shortest := timeout
+	if shortest <= 0 || (stallTimeout > 0 && stallTimeout < shortest) {
+		shortest = stallTimeout
+	}
+	checkInterval := shortest / 5
 	if checkInterval < 10*time.Millisecond {
 		checkInterval = 10 * time.Millisecond
 	}
@@ -411,28 +915,31 @@ func (l *Loop) runWatchdog(timeout time.Duration, done <-chan struct{}, fired *a
 	ticker := time.NewTicker(checkInterval)
 	defer ticker.Stop()
 
+	var stalledSince time.Time // lastOutputTime when the stall was reported
 	for {
 		select {
 		case <-ticker.C:
 			l.mu.Lock()
 			lastOutput := l.lastOutputTime
 			stopped := l.stopped
+			iter := l.iteration
+			storyID := l.currentStoryID
 			l.mu.Unlock()
 
 			if stopped {
 				return
 			}
 
-			if time.Since(lastOutput) > timeout {
+			silence := time.Since(lastOutput)
+
+			if timeout > 0 && silence > timeout {
 				fired.Store(true)
 
 				// Emit watchdog timeout event
-				l.mu.Lock()
-				iter := l.iteration
-				l.mu.Unlock()
 				l.events <- Event{
 					Type:      EventWatchdogTimeout,
 					Iteration: iter,
+					StoryID:   storyID,
 					Text:      fmt.Sprintf("No output for %s, killing hung process", timeout),
 				}
 
@@ -444,6 +951,20 @@ func (l *Loop) runWatchdog(timeout time.Duration, done <-chan struct{}, fired *a
 				l.mu.Unlock()
 				return
 			}
+
+			if stallTimeout > 0 && silence > stallTimeout && !stalledSince.Equal(lastOutput) {
+				stalledSince = lastOutput
+				text := fmt.Sprintf("No output for %s, %s may be stalled", stallTimeout, l.provider.Name())
+				if timeout > 0 {
+					text += fmt.Sprintf(" (killing after %s)", timeout)
+				}
+				l.events <- Event{
+					Type:      EventStalled,
+					Iteration: iter,
+					StoryID:   storyID,
+					Text:      text,
+				}
+			}
 		case <-done:
 			return
 		}
@@ -475,20 +996,15 @@ func (l *Loop) processOutput(r io.Reader) {
 			if event.Type == EventStoryDone {
 				l.sawStoryDone = true
 			}
+			if event.Type == EventUsage {
+				event.StoryID = l.currentStoryID
+			}
 			l.mu.Unlock()
 			l.events <- *event
 		}
 	}
 }
 
-// logStream logs a stream with a prefix.
-func (l *Loop) logStream(r io.Reader, prefix string) {
-	scanner := bufio.NewScanner(r)
-	for scanner.Scan() {
-		l.logLine(prefix + scanner.Text())
-	}
-}
-
 // logLine writes a line to the log file.
 func (l *Loop) logLine(line string) {
 	if l.logFile != nil {
@@ -496,16 +1012,33 @@ func (l *Loop) logLine(line string) {
 	}
 }
 
-// Stop terminates the current agent process and stops the loop.
+// Stop stops the loop. The agent is interrupted so it can wrap up, and is
+// killed if it is still running after the stop grace period. Calling Stop
+// again kills it right away.
 func (l *Loop) Stop() {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
+	force := l.stopped || l.stopGrace <= 0
 	l.stopped = true
+	for _, lane := range l.lanes {
+		lane.Stop()
+	}
 
+	if force {
+		l.killLocked()
+		return
+	}
 	if l.agentCmd != nil && l.agentCmd.Process != nil {
-		l.agentCmd.Process.Kill()
+		if err := l.agentCmd.Process.Signal(os.Interrupt); err != nil {
+			l.agentCmd.Process.Kill()
+		}
 	}
+	time.AfterFunc(l.stopGrace, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.killLocked()
+	})
 }
 
 // Pause sets the pause flag. The loop will stop after the current iteration completes.
@@ -513,6 +1046,9 @@ func (l *Loop) Pause() {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 	l.paused = true
+	for _, lane := range l.lanes {
+		lane.Pause()
+	}
 }
 
 // Resume clears the pause flag.
@@ -552,6 +1088,22 @@ func (l *Loop) IsRunning() bool {
 	return l.agentCmd != nil && l.agentCmd.Process != nil
 }
 
+// AgentPID returns the process ID of the running agent, or 0 when none runs.
+// A parallel run reports the agent of its first lane that has one running.
+func (l *Loop) AgentPID() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.agentCmd == nil || l.agentCmd.Process == nil {
+		for _, lane := range l.lanes {
+			if pid := lane.AgentPID(); pid != 0 {
+				return pid
+			}
+		}
+		return 0
+	}
+	return l.agentCmd.Process.Pid
+}
+
 // SetMaxIterations updates the maximum iterations limit.
 func (l *Loop) SetMaxIterations(maxIter int) {
 	l.mu.Lock()
@@ -588,6 +1140,84 @@ func (l *Loop) SetWatchdogTimeout(timeout time.Duration) {
 	l.watchdogTimeout = timeout
 }
 
+// SetStallTimeout sets how long the agent may be silent before EventStalled is emitted.
+// Setting timeout to 0 disables stall reporting.
+func (l *Loop) SetStallTimeout(timeout time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stallTimeout = timeout
+}
+
+// SetCommitRules enables checking agent commit messages against rules after
+// each iteration. Passing nil disables the check.
+func (l *Loop) SetCommitRules(rules *git.CommitRules) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.commitRules = rules
+}
+
+// SetCommitTrailers enables adding Chief-Story and Chief-Run trailers to the
+// commits the agent makes, so `chief blame` can map lines back to stories.
+func (l *Loop) SetCommitTrailers(on bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.trailers = on
+}
+
+// tagCommits adds the story's and run's trailers to the commits made since
+// baseCommit.
+func (l *Loop) tagCommits(baseCommit, storyID string) {
+	l.mu.Lock()
+	runID := l.runID
+	l.mu.Unlock()
+	if _, err := git.AddTrailers(l.effectiveWorkDir(), baseCommit, git.Provenance{Story: storyID, Run: runID}); err != nil {
+		l.logLine(fmt.Sprintf("[chief] Could not add trailers to the commits for %s: %v", storyID, err))
+	}
+}
+
+// DefaultSquashTemplate is the subject used for squashed stories. It matches the
+// format the agent prompt asks for, so story commits can still be found by subject.
+const DefaultSquashTemplate = "feat: {{STORY_ID}} - {{STORY_TITLE}}"
+
+// SetSquashTemplate enables squashing each finished story's commits into one
+// commit whose subject is rendered from template ({{STORY_ID}}, {{STORY_TITLE}}).
+// An empty template disables squashing.
+func (l *Loop) SetSquashTemplate(template string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.squashTemplate = template
+}
+
+// SetLanguage sets the language the agent writes story prose, progress notes
+// and commit messages in. An empty language leaves the prompt unchanged.
+func (l *Loop) SetLanguage(language string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.language = language
+}
+
+// SetStoryTimeout sets the total time the agent may spend on a single story
+// before it is asked to wrap up. Setting timeout to 0 disables the limit.
+func (l *Loop) SetStoryTimeout(timeout time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.storyTimeout = timeout
+}
+
+// StoryTimeout returns the current story timeout duration.
+func (l *Loop) StoryTimeout() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.storyTimeout
+}
+
+// StallTimeout returns the current stall timeout duration.
+func (l *Loop) StallTimeout() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stallTimeout
+}
+
 // WatchdogTimeout returns the current watchdog timeout duration.
 func (l *Loop) WatchdogTimeout() time.Duration {
 	l.mu.Lock()
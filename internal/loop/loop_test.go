@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/minicodemonkey/chief/internal/git"
 	"github.com/minicodemonkey/chief/internal/prd"
 )
 
@@ -39,6 +40,10 @@ func (m *mockProvider) LoopCommand(ctx context.Context, _, workDir string) *exec
 	return cmd
 }
 
+func (m *mockProvider) ReadOnlyCommand(ctx context.Context, prompt, workDir string, _ ...string) (*exec.Cmd, error) {
+	return m.LoopCommand(ctx, prompt, workDir), nil
+}
+
 func (m *mockProvider) CleanOutput(output string) string { return output }
 
 // testProvider is used by loop tests so they don't need to run a real CLI.
@@ -490,7 +495,11 @@ func TestLoop_WatchdogKillsHungProcess(t *testing.T) {
 	// Start watchdog with a short check interval
 	watchdogDone := make(chan struct{})
 	var fired atomic.Bool
-	go l.runWatchdog(timeout, watchdogDone, &fired)
+	watchdogExited := make(chan struct{})
+	go func() {
+		l.runWatchdog(timeout, 0, watchdogDone, &fired)
+		close(watchdogExited)
+	}()
 
 	// processOutput will block until pipe is closed (by watchdog killing would close it,
 	// but in this test we close it manually after watchdog fires)
@@ -502,6 +511,7 @@ func TestLoop_WatchdogKillsHungProcess(t *testing.T) {
 
 	l.processOutput(r)
 	close(watchdogDone)
+	<-watchdogExited
 	close(l.events)
 	<-done
 
@@ -524,6 +534,223 @@ func TestLoop_WatchdogKillsHungProcess(t *testing.T) {
 	}
 }
 
+// TestLoop_StallReportedBeforeWatchdog tests that a silent process is reported as
+// stalled once, without being killed, when only the stall timeout is exceeded.
+func TestLoop_StallReportedBeforeWatchdog(t *testing.T) {
+	l := NewLoop("/test/prd.json", "test", 5, testProvider)
+	l.iteration = 1
+	l.currentStoryID = "US-001"
+
+	var events []Event
+	done := make(chan bool)
+	go func() {
+		for event := range l.Events() {
+			events = append(events, event)
+		}
+		done <- true
+	}()
+
+	l.mu.Lock()
+	l.lastOutputTime = time.Now()
+	l.mu.Unlock()
+
+	watchdogDone := make(chan struct{})
+	var fired atomic.Bool
+	watchdogExited := make(chan struct{})
+	go func() {
+		l.runWatchdog(time.Hour, 50*time.Millisecond, watchdogDone, &fired)
+		close(watchdogExited)
+	}()
+
+	time.Sleep(400 * time.Millisecond)
+	close(watchdogDone)
+	<-watchdogExited
+	close(l.events)
+	<-done
+
+	if fired.Load() {
+		t.Error("Expected watchdog not to fire before its timeout")
+	}
+
+	stalls := 0
+	for _, e := range events {
+		if e.Type == EventStalled {
+			stalls++
+			if e.StoryID != "US-001" {
+				t.Errorf("Expected stall event for US-001, got %q", e.StoryID)
+			}
+		}
+	}
+	if stalls != 1 {
+		t.Errorf("Expected exactly 1 Stalled event, got %d", stalls)
+	}
+}
+
+// TestLoop_SetStallTimeout tests setting the stall timeout.
+func TestLoop_SetStallTimeout(t *testing.T) {
+	l := NewLoop("/test/prd.json", "test", 5, testProvider)
+	if l.StallTimeout() != DefaultStallTimeout {
+		t.Errorf("Expected default stall timeout %v, got %v", DefaultStallTimeout, l.StallTimeout())
+	}
+
+	l.SetStallTimeout(0)
+	if l.StallTimeout() != 0 {
+		t.Errorf("Expected stall timeout 0 (disabled), got %v", l.StallTimeout())
+	}
+}
+
+// TestLoop_StoryTimeoutRunsWrapUp tests that a story exceeding its time budget
+// is interrupted, followed by a single wrap-up iteration, and the loop stops.
+func TestLoop_StoryTimeoutRunsWrapUp(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdPath := createTestPRD(t, tmpDir, false)
+
+	// First invocation hangs; the wrap-up invocation records that it ran.
+	marker := filepath.Join(tmpDir, "started")
+	wrapped := filepath.Join(tmpDir, "wrapped")
+	script := filepath.Join(tmpDir, "mock-claude")
+	content := "#!/bin/bash\n" +
+		"if [ ! -f " + marker + " ]; then touch " + marker + "; exec sleep 5; fi\n" +
+		"touch " + wrapped + "\n" +
+		"echo '{\"type\":\"assistant\",\"message\":{\"content\":[{\"type\":\"text\",\"text\":\"<chief-done/>\"}]}}'\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	l := NewLoopWithEmbeddedPrompt(prdPath, 5, &mockProvider{cliPath: script})
+	l.SetStoryTimeout(200 * time.Millisecond)
+
+	var events []Event
+	done := make(chan bool)
+	go func() {
+		for event := range l.Events() {
+			events = append(events, event)
+		}
+		done <- true
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := l.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	<-done
+
+	if _, err := os.Stat(wrapped); err != nil {
+		t.Error("Expected wrap-up iteration to run")
+	}
+	if l.Iteration() != 1 {
+		t.Errorf("Expected loop to stop after the timed-out iteration, got %d iterations", l.Iteration())
+	}
+
+	hasTimeout := false
+	for _, e := range events {
+		if e.Type == EventStoryTimeout {
+			hasTimeout = true
+			if e.StoryID != "US-001" {
+				t.Errorf("Expected timeout for US-001, got %q", e.StoryID)
+			}
+		}
+	}
+	if !hasTimeout {
+		t.Error("Expected StoryTimeout event")
+	}
+
+	// A <chief-done/> from the wrap-up must not mark the story done
+	p, err := prd.LoadPRD(prdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.UserStories[0].Passes {
+		t.Error("Expected timed-out story to remain incomplete")
+	}
+}
+
+// TestLoop_CheckCommitsQueuesAmendInstruction tests that a badly worded agent
+// commit produces an event and an amend instruction for the next prompt.
+func TestLoop_CheckCommitsQueuesAmendInstruction(t *testing.T) {
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"git", "init"},
+		{"git", "config", "user.name", "Test"},
+		{"git", "config", "user.email", "test@test.com"},
+		{"git", "commit", "--allow-empty", "-m", "initial"},
+	} {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v failed: %s", args, out)
+		}
+	}
+
+	l := NewLoopWithWorkDir(filepath.Join(dir, "prd.md"), dir, "test", 5, testProvider)
+	l.iteration = 1
+	rules := git.CommitRules{Prefixes: []string{"feat"}, RequireStoryID: true}
+
+	// A good commit queues nothing
+	base := git.HeadCommit(dir)
+	commit := func(msg string) {
+		cmd := exec.Command("git", "commit", "--allow-empty", "-m", msg)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git commit failed: %s", out)
+		}
+	}
+	commit("feat: US-001 - Login")
+	l.checkCommits(base, "US-001", rules)
+	if l.feedback != "" {
+		t.Errorf("expected no feedback for a valid commit, got %q", l.feedback)
+	}
+
+	base = git.HeadCommit(dir)
+	commit("wip")
+	l.checkCommits(base, "US-001", rules)
+
+	select {
+	case e := <-l.events:
+		if e.Type != EventCommitRejected {
+			t.Errorf("expected CommitRejected event, got %v", e.Type)
+		}
+	default:
+		t.Error("expected CommitRejected event")
+	}
+	if !strings.Contains(l.feedback, `"wip"`) || !strings.Contains(l.feedback, "git commit --amend") {
+		t.Errorf("expected amend instruction mentioning the bad commit, got %q", l.feedback)
+	}
+	if !strings.Contains(l.feedback, "`feat: US-001 - <story title>`") {
+		t.Errorf("expected the format to use the allowed prefix, got %q", l.feedback)
+	}
+
+	// The format follows the configured prefixes rather than assuming feat
+	l.feedback = ""
+	base = git.HeadCommit(dir)
+	commit("update login")
+	l.checkCommits(base, "US-001", git.CommitRules{Prefixes: []string{"fix", "chore"}})
+	if !strings.Contains(l.feedback, "`fix: US-001 - <story title>`") || strings.Contains(l.feedback, "feat:") {
+		t.Errorf("expected the format to use fix, got %q", l.feedback)
+	}
+	format := commitFormat(git.CommitRules{Prefixes: []string{"fix", "chore"}}, "US-001")
+	if problems := git.CheckCommitSubject(strings.Replace(format, "<story title>", "Login", 1), "US-001", git.CommitRules{Prefixes: []string{"fix", "chore"}}); len(problems) > 0 {
+		t.Errorf("the suggested format %q is rejected: %v", format, problems)
+	}
+	if got := commitFormat(git.CommitRules{}, "US-001"); got != "US-001 - <story title>" {
+		t.Errorf("commitFormat() without prefixes = %q, want no prefix", got)
+	}
+}
+
+func TestSquashMessage(t *testing.T) {
+	msg := squashMessage(DefaultSquashTemplate, "US-001", "Add login", []git.Commit{
+		{Subject: "wip"},
+		{Subject: "fix tests"},
+	})
+	want := "feat: US-001 - Add login\n\nSquashed commits:\n- wip\n- fix tests\n"
+	if msg != want {
+		t.Errorf("squashMessage() = %q, want %q", msg, want)
+	}
+}
+
 // TestLoop_WatchdogDoesNotFireForActiveProcess tests that an active process doesn't trigger the watchdog.
 func TestLoop_WatchdogDoesNotFireForActiveProcess(t *testing.T) {
 	l := NewLoop("/test/prd.json", "test", 5, testProvider)
@@ -551,7 +778,11 @@ func TestLoop_WatchdogDoesNotFireForActiveProcess(t *testing.T) {
 
 	watchdogDone := make(chan struct{})
 	var fired atomic.Bool
-	go l.runWatchdog(timeout, watchdogDone, &fired)
+	watchdogExited := make(chan struct{})
+	go func() {
+		l.runWatchdog(timeout, 0, watchdogDone, &fired)
+		close(watchdogExited)
+	}()
 
 	// Send output regularly, then close
 	go func() {
@@ -564,6 +795,7 @@ func TestLoop_WatchdogDoesNotFireForActiveProcess(t *testing.T) {
 
 	l.processOutput(r)
 	close(watchdogDone)
+	<-watchdogExited
 	close(l.events)
 	<-done
 
@@ -666,3 +898,31 @@ func TestLoop_WatchdogWithWorkDir(t *testing.T) {
 		t.Errorf("Expected default watchdog timeout for NewLoopWithWorkDir, got %v", l.WatchdogTimeout())
 	}
 }
+
+func TestLoop_RunRefusesInvalidPRD(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdPath := filepath.Join(tmpDir, "prd.md")
+	md := "# Test Project\n\n### US-001: Test Story\n**Depends on:** US-009\n- [ ] It works\n"
+	if err := os.WriteFile(prdPath, []byte(md), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l := NewLoopWithWorkDir(prdPath, tmpDir, "test prompt", 1, testProvider)
+	var events []Event
+	done := make(chan bool)
+	go func() {
+		for event := range l.Events() {
+			events = append(events, event)
+		}
+		done <- true
+	}()
+
+	err := l.Run(context.Background())
+	<-done
+	if err == nil || !strings.Contains(err.Error(), "US-009") {
+		t.Fatalf("Run() = %v, want the unknown dependency reported", err)
+	}
+	if len(events) == 0 || events[len(events)-1].Type != EventError {
+		t.Errorf("events = %v, want an error event", events)
+	}
+}
@@ -0,0 +1,78 @@
+package loop
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/timefmt"
+)
+
+// MaintenanceFile marks the project as in maintenance mode, relative to the
+// project root. It outlives the chief process, so a restart during a host
+// maintenance window doesn't start new runs.
+const MaintenanceFile = ".chief/maintenance.json"
+
+// Maintenance describes a maintenance window in effect.
+type Maintenance struct {
+	Since  time.Time `json:"since"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// String describes the maintenance window, for error messages.
+func (m *Maintenance) String() string {
+	s := "since " + timefmt.Stamp(m.Since)
+	if m.Reason != "" {
+		s += ": " + m.Reason
+	}
+	return s
+}
+
+// ReadMaintenance returns the maintenance window in effect for the project at
+// baseDir, or nil when runs may start.
+func ReadMaintenance(baseDir string) *Maintenance {
+	data, err := os.ReadFile(filepath.Join(baseDir, MaintenanceFile))
+	if err != nil {
+		return nil
+	}
+	var m Maintenance
+	if json.Unmarshal(data, &m) != nil {
+		// Still in maintenance; the file only exists while it's in effect
+		return &Maintenance{}
+	}
+	return &m
+}
+
+// EnterMaintenance puts the project at baseDir in maintenance mode. Entering
+// it again keeps the original start time and replaces the reason.
+func EnterMaintenance(baseDir, reason string) error {
+	m := Maintenance{Since: time.Now(), Reason: reason}
+	if current := ReadMaintenance(baseDir); current != nil && !current.Since.IsZero() {
+		m.Since = current.Since
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(baseDir, MaintenanceFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LeaveMaintenance lifts maintenance mode for the project at baseDir.
+func LeaveMaintenance(baseDir string) error {
+	err := os.Remove(filepath.Join(baseDir, MaintenanceFile))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// errMaintenance is returned when a run is refused during maintenance.
+func errMaintenance(m *Maintenance) error {
+	return fmt.Errorf("chief is in maintenance mode (%s); lift it with chief resume --all", m)
+}
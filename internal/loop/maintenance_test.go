@@ -0,0 +1,61 @@
+package loop
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMaintenance(t *testing.T) {
+	dir := t.TempDir()
+	if m := ReadMaintenance(dir); m != nil {
+		t.Fatalf("expected no maintenance mode, got %+v", m)
+	}
+
+	if err := EnterMaintenance(dir, "host reboot"); err != nil {
+		t.Fatal(err)
+	}
+	first := ReadMaintenance(dir)
+	if first == nil || first.Reason != "host reboot" || first.Since.IsZero() {
+		t.Fatalf("unexpected maintenance window: %+v", first)
+	}
+
+	// Entering again keeps the start time
+	if err := EnterMaintenance(dir, "still rebooting"); err != nil {
+		t.Fatal(err)
+	}
+	if m := ReadMaintenance(dir); !m.Since.Equal(first.Since) || m.Reason != "still rebooting" {
+		t.Errorf("expected the start time to be kept, got %+v", m)
+	}
+
+	if err := LeaveMaintenance(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := LeaveMaintenance(dir); err != nil {
+		t.Errorf("lifting maintenance mode twice should succeed, got %v", err)
+	}
+	if m := ReadMaintenance(dir); m != nil {
+		t.Errorf("expected maintenance mode to be lifted, got %+v", m)
+	}
+}
+
+func TestManagerStartDuringMaintenance(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager(5, testProvider)
+	m.SetBaseDir(dir)
+	if err := m.Register("auth", createTestPRDWithName(t, dir, "auth")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.EnterMaintenance("host reboot"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A manager created later, as after a restart, is refused too
+	restarted := NewManager(5, testProvider)
+	restarted.SetBaseDir(dir)
+	restarted.Register("auth", createTestPRDWithName(t, dir, "auth"))
+	for _, manager := range []*Manager{m, restarted} {
+		if err := manager.Start("auth"); err == nil || !strings.Contains(err.Error(), "maintenance mode") {
+			t.Errorf("expected Start to be refused during maintenance, got %v", err)
+		}
+	}
+}
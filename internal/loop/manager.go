@@ -3,11 +3,19 @@ package loop
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/git"
 	"github.com/minicodemonkey/chief/internal/prd"
+	"github.com/minicodemonkey/chief/internal/prompt"
+	"github.com/minicodemonkey/chief/internal/pushgate"
+	"github.com/minicodemonkey/chief/internal/schedule"
+	"github.com/minicodemonkey/chief/internal/toolcache"
+	"github.com/minicodemonkey/chief/internal/verify"
 )
 
 // LoopState represents the state of a loop instance.
@@ -45,13 +53,20 @@ func (s LoopState) String() string {
 type LoopInstance struct {
 	Name        string
 	PRDPath     string
-	WorktreeDir string // Working directory for this PRD (empty = project root)
-	Branch      string // Git branch for this PRD (empty = current branch)
+	WorktreeDir string   // Working directory for this PRD (empty = project root)
+	Branch      string   // Git branch for this PRD (empty = current branch)
+	Base        RunBase  // Commit the run is pinned to (zero = not pinned)
+	Labels      []string // Labels recorded with each run, e.g. "experiment" or "model=opus"
+	Parallel    int      // Stories worked on side by side in worktrees of their own (<= 1 = one at a time)
 	Loop        *Loop
 	State       LoopState
 	Iteration   int
 	StartTime   time.Time
 	Error       error
+	Usage       UsageTotals     // Tokens and cost of the current or last run
+	AgentPID    int             // Running agent process in GetAllInstances snapshots (0 = none)
+	status      *statusReporter // Posts commit statuses (nil = off)
+	runLock     *runLockHandle  // Held while the loop runs
 	ctx         context.Context
 	cancel      context.CancelFunc
 	mu          sync.Mutex
@@ -70,6 +85,7 @@ type Manager struct {
 	events         chan ManagerEvent
 	maxIter        int
 	retryConfig    RetryConfig
+	ignoreQuiet    bool // Run through the configured quiet hours
 	provider       Provider
 	baseDir        string         // Project root directory (for CLAUDE.md etc.)
 	config         *config.Config // Project config for post-completion actions
@@ -77,6 +93,7 @@ type Manager struct {
 	wg             sync.WaitGroup
 	onComplete     func(prdName string)                  // Callback when a PRD completes
 	onPostComplete func(prdName, branch, workDir string) // Callback for post-completion actions (push, PR)
+	onEvent        func(event ManagerEvent)              // Callback for every forwarded event (e.g. control socket)
 }
 
 // NewManager creates a new loop manager.
@@ -104,6 +121,13 @@ func (m *Manager) DisableRetry() {
 	m.retryConfig.Enabled = false
 }
 
+// IgnoreQuietHours makes new loops run through the configured quiet hours.
+func (m *Manager) IgnoreQuietHours() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ignoreQuiet = true
+}
+
 // SetCompletionCallback sets a callback that is called when any PRD completes.
 func (m *Manager) SetCompletionCallback(fn func(prdName string)) {
 	m.mu.Lock()
@@ -119,6 +143,14 @@ func (m *Manager) SetPostCompleteCallback(fn func(prdName, branch, workDir strin
 	m.onPostComplete = fn
 }
 
+// SetEventCallback sets a function that receives a copy of every event
+// forwarded from a loop. It is called from the loop's goroutine and must not block.
+func (m *Manager) SetEventCallback(fn func(event ManagerEvent)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onEvent = fn
+}
+
 // SetBaseDir sets the project root directory so Claude runs from there and picks up CLAUDE.md.
 func (m *Manager) SetBaseDir(baseDir string) {
 	m.mu.Lock()
@@ -221,29 +253,161 @@ func (m *Manager) Start(name string) error {
 		return fmt.Errorf("PRD %s not found", name)
 	}
 
+	m.mu.RLock()
+	baseDir := m.baseDir
+	m.mu.RUnlock()
+	if baseDir != "" {
+		if maintenance := ReadMaintenance(baseDir); maintenance != nil {
+			return errMaintenance(maintenance)
+		}
+	}
+
+	m.mu.RLock()
+	var quietHours schedule.QuietHours
+	var err error
+	if m.config != nil && !m.ignoreQuiet {
+		quietHours, err = schedule.Parse(m.config.Schedule.QuietHours)
+	}
+	var middlewareErr error
+	dirtyPolicy := DirtyAbort
+	remoteSync := ""
+	if m.config != nil {
+		middlewareErr = prompt.Validate(m.config.Prompt.Middlewares)
+		if m.config.Loop.DirtyWorktree != "" {
+			dirtyPolicy = m.config.Loop.DirtyWorktree
+		}
+		remoteSync = m.config.Loop.RemoteSync
+	}
+	m.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("schedule.quietHours: %w", err)
+	}
+	if middlewareErr != nil {
+		return fmt.Errorf("prompt.middlewares: %w", middlewareErr)
+	}
+	if err := ValidateDirtyPolicy(dirtyPolicy); err != nil {
+		return fmt.Errorf("loop.dirtyWorktree: %w", err)
+	}
+	if err := ValidateRemoteSync(remoteSync); err != nil {
+		return fmt.Errorf("loop.remoteSync: %w", err)
+	}
+
 	instance.mu.Lock()
 	if instance.State == LoopStateRunning {
 		instance.mu.Unlock()
 		return fmt.Errorf("PRD %s is already running", name)
 	}
 
-	// Create a new loop instance, using worktree-aware constructor if WorktreeDir is set.
-	// When no worktree is configured, run from the project root (baseDir) so that
-	// CLAUDE.md and other project-level files are visible to Claude.
+	// Decide what to do with uncommitted changes before anything else starts
 	workDir := instance.WorktreeDir
 	if workDir == "" {
 		m.mu.RLock()
 		workDir = m.baseDir
 		m.mu.RUnlock()
 	}
+	dirty := dirtyFiles(workDir)
+	if len(dirty) > 0 && dirtyPolicy == DirtyAbort {
+		instance.mu.Unlock()
+		return fmt.Errorf("the working tree has %d uncommitted change(s); commit them, or set loop.dirtyWorktree to %q or %q", len(dirty), DirtyStash, DirtyInclude)
+	}
+
+	// Refuse to start when another chief process already runs this PRD
+	runLock, err := acquireRunLock(filepath.Dir(instance.PRDPath))
+	if err != nil {
+		instance.mu.Unlock()
+		return fmt.Errorf("PRD %s: %w", name, err)
+	}
+	instance.runLock = runLock
+
+	// Create a new loop instance, using worktree-aware constructor if WorktreeDir is set.
+	// When no worktree is configured, run from the project root (baseDir) so that
+	// CLAUDE.md and other project-level files are visible to Claude.
 	instance.Loop = NewLoopWithWorkDir(instance.PRDPath, workDir, "", m.maxIter, m.provider)
-	instance.Loop.buildPrompt = promptBuilderForPRD(instance.PRDPath)
+	instance.Loop.buildPrompt = instance.Loop.promptBuilderForPRD()
+	instance.Loop.persistState = true
+	instance.Loop.SetDirtyWorktree(dirtyPolicy, dirty)
+	instance.Loop.SetRemoteSync(remoteSync)
+	if instance.Parallel > 1 {
+		laneRoot := baseDir
+		if laneRoot == "" {
+			laneRoot = workDir
+		}
+		setup := ""
+		if m.config != nil {
+			setup = m.config.Worktree.Setup
+		}
+		instance.Loop.SetParallel(instance.Parallel, LaneDir(laneRoot), setup)
+	}
 	m.mu.RLock()
 	instance.Loop.SetRetryConfig(m.retryConfig)
+	instance.Loop.SetQuietHours(quietHours)
+	instance.status = nil
+	if m.config != nil {
+		if m.config.Forge.CommitStatus {
+			instance.status = newStatusReporter(name, instance.PRDPath, workDir)
+		}
+		applyLoopConfig(instance.Loop, m.config.Loop)
+		instance.Loop.SetStackedPRs(m.config.Forge.StackedPRs)
+		instance.Loop.SetFileIssues(m.config.Forge.FileIssues)
+		if push := m.config.Push; push.EveryMinutes > 0 || push.PerStory {
+			instance.Loop.SetPushPolicy(&pushgate.Policy{
+				Every:     time.Duration(push.EveryMinutes) * time.Minute,
+				PerStory:  push.PerStory,
+				StatePath: filepath.Join(filepath.Dir(instance.PRDPath), pushgate.StateFile),
+				BinDir:    pushgate.BinDir(m.baseDir),
+			})
+		}
+		instance.Loop.SetLanguage(m.config.Language)
+		if len(m.config.Prompt.Middlewares) > 0 {
+			middlewares, _ := prompt.New(m.config.Prompt.Middlewares, prompt.Options{
+				BaseDir:  m.baseDir,
+				WorkDir:  workDir,
+				Language: m.config.Language,
+			})
+			instance.Loop.SetPromptMiddlewares(middlewares)
+		}
+		if command, gateFlaky := m.verifySettings(instance.PRDPath, workDir); command != "" {
+			instance.Loop.SetVerify(command, gateFlaky)
+			instance.Loop.SetVerifyIgnore(m.config.Verify.Ignore)
+		}
+		instance.Loop.SetDoneDefinition(m.config.Done)
+		instance.Loop.SetVerifyResources(m.verifyResources(instance.PRDPath), m.config.Resources)
+		if len(m.config.Cache.Commands) > 0 {
+			instance.Loop.SetToolCache(&toolcache.Cache{
+				Dir:      toolcache.Dir(m.baseDir),
+				Patterns: m.config.Cache.Commands,
+				MaxAge:   time.Duration(m.config.Cache.MaxAgeMinutes) * time.Minute,
+			})
+		}
+		if command := m.browserSettings(instance.PRDPath, workDir); command != "" {
+			instance.Loop.SetBrowserVerify(command)
+		}
+		if m.config.Coverage.Command != "" {
+			instance.Loop.SetCoverage(m.config.Coverage.Command, m.config.Coverage.MaxDrop)
+		}
+		if m.config.Commits.Validate {
+			instance.Loop.SetCommitRules(&git.CommitRules{
+				MaxSubjectLength: m.config.Commits.MaxSubjectLength,
+				Prefixes:         m.config.Commits.Prefixes,
+				RequireStoryID:   m.config.Commits.RequireStoryID,
+			})
+		}
+		instance.Loop.SetCommitTrailers(m.config.Commits.Trailers)
+		if m.config.Commits.Squash {
+			template := m.config.Commits.SquashMessage
+			if template == "" {
+				template = DefaultSquashTemplate
+			}
+			instance.Loop.SetSquashTemplate(template)
+		}
+	}
 	m.mu.RUnlock()
 	instance.ctx, instance.cancel = context.WithCancel(context.Background())
 	instance.State = LoopStateRunning
 	instance.StartTime = time.Now()
+	if state := ReadRunState(filepath.Dir(instance.PRDPath)); state != nil {
+		instance.StartTime = instance.StartTime.Add(-state.Elapsed())
+	}
 	instance.Error = nil
 	instance.mu.Unlock()
 
@@ -254,10 +418,97 @@ func (m *Manager) Start(name string) error {
 	return nil
 }
 
+// verifySettings returns the verification command for a PRD, taking the PRD's
+// config.yaml overrides into account, and whether flaky tests still gate
+// stories. Callers must hold m.mu.
+func (m *Manager) verifySettings(prdPath, workDir string) (string, bool) {
+	prdCfg, err := config.LoadPRD(filepath.Dir(prdPath))
+	if err != nil {
+		prdCfg = &config.PRDConfig{}
+	}
+	command, _ := verify.Resolve(prdCfg.Verify.Command, m.config.Verify.Command, workDir)
+	return command, m.config.Verify.GateFlaky || prdCfg.Verify.GateFlaky
+}
+
+// verifyResources returns the resource tags a PRD's verification uses,
+// taking the PRD's config.yaml overrides into account. Callers must hold m.mu.
+func (m *Manager) verifyResources(prdPath string) []string {
+	if prdCfg, err := config.LoadPRD(filepath.Dir(prdPath)); err == nil && prdCfg.Verify.Resources != nil {
+		return prdCfg.Verify.Resources
+	}
+	return m.config.Verify.Resources
+}
+
+// browserSettings returns the browser test command for a PRD, taking the
+// PRD's config.yaml overrides into account. Callers must hold m.mu.
+func (m *Manager) browserSettings(prdPath, workDir string) string {
+	configured := m.config.Verify.Browser.Command
+	if prdCfg, err := config.LoadPRD(filepath.Dir(prdPath)); err == nil && prdCfg.Verify.Browser.Command != "" {
+		configured = prdCfg.Verify.Browser.Command
+	}
+	return verify.ResolveBrowser(configured, workDir)
+}
+
+// applyLoopConfig applies the configured stall, watchdog, story, and stop timeouts, the
+// crash limit, and the story retries to a loop.
+// Zero values keep the defaults; negative values disable that stage.
+func applyLoopConfig(l *Loop, cfg config.LoopConfig) {
+	if cfg.StallMinutes != 0 {
+		l.SetStallTimeout(minutesOrOff(cfg.StallMinutes))
+	}
+	if cfg.WatchdogMinutes != 0 {
+		l.SetWatchdogTimeout(minutesOrOff(cfg.WatchdogMinutes))
+	}
+	if cfg.StoryTimeoutMinutes > 0 {
+		l.SetStoryTimeout(time.Duration(cfg.StoryTimeoutMinutes) * time.Minute)
+	}
+	if cfg.StopGraceSeconds > 0 {
+		l.SetStopGracePeriod(time.Duration(cfg.StopGraceSeconds) * time.Second)
+	} else if cfg.StopGraceSeconds < 0 {
+		l.SetStopGracePeriod(0)
+	}
+	if cfg.CrashLimit > 0 {
+		l.SetCrashLimit(cfg.CrashLimit)
+	} else if cfg.CrashLimit < 0 {
+		l.SetCrashLimit(0)
+	}
+	if cfg.StoryRetries > 0 {
+		l.SetStoryRetries(cfg.StoryRetries)
+	} else if cfg.StoryRetries < 0 {
+		l.SetStoryRetries(0)
+	}
+	if cfg.SummaryEvery > 0 {
+		l.SetSummaryEvery(cfg.SummaryEvery)
+	}
+	if cfg.ExploreTurns > 0 {
+		l.SetExploreTurns(cfg.ExploreTurns)
+	}
+}
+
+// minutesOrOff converts a positive minute count to a duration and anything else to 0.
+func minutesOrOff(minutes int) time.Duration {
+	if minutes < 0 {
+		return 0
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
 // runLoop runs a loop instance and forwards events.
 func (m *Manager) runLoop(instance *LoopInstance) {
 	defer m.wg.Done()
 
+	instance.mu.Lock()
+	status := instance.status
+	base := instance.Base
+	labels := instance.Labels
+	instance.mu.Unlock()
+	events := openEventLog(filepath.Dir(instance.PRDPath), base, labels)
+	usage := openUsage(filepath.Dir(instance.PRDPath))
+	m.mu.RLock()
+	baseDir := m.baseDir
+	m.mu.RUnlock()
+	appendFeed(baseDir, FeedRecord{Event: FeedRunStarted, PRD: instance.Name})
+
 	// Start event forwarding goroutine
 	done := make(chan struct{})
 	go func() {
@@ -269,19 +520,38 @@ func (m *Manager) runLoop(instance *LoopInstance) {
 					return
 				}
 
+				events.record(event)
+				if event.Type == EventUsage {
+					usage.add(event)
+				}
 				instance.mu.Lock()
 				instance.Iteration = event.Iteration
+				instance.Usage = usage.totals()
 				instance.mu.Unlock()
+				if event.Type == EventStoryPassed {
+					appendFeed(baseDir, FeedRecord{Event: FeedStoryPassed, PRD: instance.Name, StoryID: event.StoryID})
+					go m.resumeDependents(instance.Name, event.StoryID)
+				}
+				if status != nil {
+					status.report(event)
+				}
 
 				// Check if this is a completion event
 				completed := event.Type == EventComplete
 
 				// Forward event to manager channel
-				m.events <- ManagerEvent{
+				managerEvent := ManagerEvent{
 					PRDName:   instance.Name,
 					Event:     event,
 					Completed: completed,
 				}
+				m.mu.RLock()
+				onEvent := m.onEvent
+				m.mu.RUnlock()
+				if onEvent != nil {
+					onEvent(managerEvent)
+				}
+				m.events <- managerEvent
 
 				// If completed, trigger callbacks
 				if completed {
@@ -309,6 +579,7 @@ func (m *Manager) runLoop(instance *LoopInstance) {
 
 	// Run the loop
 	err := instance.Loop.Run(instance.ctx)
+	instance.runLock.release()
 
 	// Update state based on result
 	instance.mu.Lock()
@@ -329,9 +600,51 @@ func (m *Manager) runLoop(instance *LoopInstance) {
 			instance.State = LoopStatePaused
 		}
 	}
+	ended := FeedRecord{Event: FeedRunEnded, PRD: instance.Name, State: instance.State.String()}
+	if instance.State == LoopStateComplete {
+		ended = FeedRecord{Event: FeedRunComplete, PRD: instance.Name}
+	} else if instance.Error != nil {
+		ended.Error = instance.Error.Error()
+	}
 	instance.mu.Unlock()
 
 	<-done
+	if ended.Event == FeedRunComplete {
+		ended.Cost = usage.totals().CostUSD
+	}
+	appendFeed(baseDir, ended)
+	events.close()
+	if status != nil {
+		status.close()
+	}
+}
+
+// resumeDependents starts the paused PRDs that were waiting on storyID of
+// prdName, now that it passed. One still blocked by something else pauses
+// again straight away.
+func (m *Manager) resumeDependents(prdName, storyID string) {
+	ref := prdName + "/" + storyID
+	var names []string
+	m.mu.RLock()
+	for name, instance := range m.instances {
+		instance.mu.Lock()
+		paused := instance.State == LoopStatePaused && instance.Loop != nil
+		instance.mu.Unlock()
+		if !paused {
+			continue
+		}
+		for _, dep := range instance.Loop.WaitingOn() {
+			if strings.EqualFold(dep, ref) {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	m.mu.RUnlock()
+
+	for _, name := range names {
+		_ = m.Start(name)
+	}
 }
 
 // Pause pauses the loop for a specific PRD (stops after current iteration).
@@ -358,6 +671,48 @@ func (m *Manager) Pause(name string) error {
 	return nil
 }
 
+// EnterMaintenance puts the project in maintenance mode: until
+// LeaveMaintenance, no loop starts, in this process or in a chief started
+// later. Running loops are left to the caller to pause.
+func (m *Manager) EnterMaintenance(reason string) error {
+	m.mu.RLock()
+	baseDir := m.baseDir
+	m.mu.RUnlock()
+	if baseDir == "" {
+		return fmt.Errorf("manager base directory is not configured")
+	}
+	if err := EnterMaintenance(baseDir, reason); err != nil {
+		return fmt.Errorf("failed to enter maintenance mode: %w", err)
+	}
+	return nil
+}
+
+// Maintenance returns the maintenance window in effect, or nil.
+func (m *Manager) Maintenance() *Maintenance {
+	m.mu.RLock()
+	baseDir := m.baseDir
+	m.mu.RUnlock()
+	if baseDir == "" {
+		return nil
+	}
+	return ReadMaintenance(baseDir)
+}
+
+// LeaveMaintenance lifts maintenance mode so loops can start again. Paused
+// loops stay paused.
+func (m *Manager) LeaveMaintenance() error {
+	m.mu.RLock()
+	baseDir := m.baseDir
+	m.mu.RUnlock()
+	if baseDir == "" {
+		return nil
+	}
+	if err := LeaveMaintenance(baseDir); err != nil {
+		return fmt.Errorf("failed to lift maintenance mode: %w", err)
+	}
+	return nil
+}
+
 // Stop stops the loop for a specific PRD immediately.
 func (m *Manager) Stop(name string) error {
 	m.mu.RLock()
@@ -375,10 +730,11 @@ func (m *Manager) Stop(name string) error {
 		return nil // Already stopped
 	}
 
+	// The loop winds down on its own: the agent gets a grace period to exit,
+	// and the instance context stays alive so the final events are delivered.
 	if instance.Loop != nil {
 		instance.Loop.Stop()
-	}
-	if instance.cancel != nil {
+	} else if instance.cancel != nil {
 		instance.cancel()
 	}
 
@@ -406,6 +762,62 @@ func (m *Manager) UpdateWorktreeInfo(name, worktreeDir, branch string) error {
 	return nil
 }
 
+// SetBase records the commit a PRD's run was pinned to, for the event log.
+func (m *Manager) SetBase(name string, base RunBase) error {
+	m.mu.RLock()
+	instance, exists := m.instances[name]
+	m.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("PRD %s not found", name)
+	}
+
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	instance.Base = base
+
+	return nil
+}
+
+// SetLabels sets the labels recorded with each run of a PRD, so runs can be
+// told apart in `chief profile`.
+func (m *Manager) SetLabels(name string, labels []string) error {
+	m.mu.RLock()
+	instance, exists := m.instances[name]
+	m.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("PRD %s not found", name)
+	}
+
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	instance.Labels = labels
+
+	return nil
+}
+
+// SetParallel makes runs of a PRD work on up to n stories side by side, each
+// in a worktree of its own.
+func (m *Manager) SetParallel(name string, n int) error {
+	m.mu.RLock()
+	instance, exists := m.instances[name]
+	m.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("PRD %s not found", name)
+	}
+
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	instance.Parallel = n
+
+	return nil
+}
+
 // ClearWorktreeInfo clears the worktree directory and optionally the branch for a PRD instance.
 func (m *Manager) ClearWorktreeInfo(name string, clearBranch bool) error {
 	m.mu.RLock()
@@ -462,10 +874,13 @@ func (m *Manager) GetInstance(name string) *LoopInstance {
 		PRDPath:     instance.PRDPath,
 		WorktreeDir: instance.WorktreeDir,
 		Branch:      instance.Branch,
+		Base:        instance.Base,
+		Labels:      instance.Labels,
 		State:       instance.State,
 		Iteration:   instance.Iteration,
 		StartTime:   instance.StartTime,
 		Error:       instance.Error,
+		Usage:       instance.Usage,
 	}
 }
 
@@ -482,10 +897,16 @@ func (m *Manager) GetAllInstances() []*LoopInstance {
 			PRDPath:     instance.PRDPath,
 			WorktreeDir: instance.WorktreeDir,
 			Branch:      instance.Branch,
+			Base:        instance.Base,
+			Labels:      instance.Labels,
 			State:       instance.State,
 			Iteration:   instance.Iteration,
 			StartTime:   instance.StartTime,
 			Error:       instance.Error,
+			Usage:       instance.Usage,
+		}
+		if instance.Loop != nil {
+			copy.AgentPID = instance.Loop.AgentPID()
 		}
 		instance.mu.Unlock()
 		result = append(result, copy)
@@ -533,6 +954,11 @@ func (m *Manager) StopAll() {
 	m.wg.Wait()
 }
 
+// Wait blocks until every started loop has finished.
+func (m *Manager) Wait() {
+	m.wg.Wait()
+}
+
 // IsAnyRunning returns true if any loop is currently running.
 func (m *Manager) IsAnyRunning() bool {
 	return m.GetRunningCount() > 0
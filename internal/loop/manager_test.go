@@ -471,6 +471,30 @@ func TestManagerSetConfig(t *testing.T) {
 	}
 }
 
+func TestApplyLoopConfig(t *testing.T) {
+	l := NewLoop("/test/prd.md", "test", 5, testProvider)
+	applyLoopConfig(l, config.LoopConfig{})
+	if l.StallTimeout() != DefaultStallTimeout || l.WatchdogTimeout() != DefaultWatchdogTimeout {
+		t.Errorf("expected defaults for empty config, got stall=%v watchdog=%v", l.StallTimeout(), l.WatchdogTimeout())
+	}
+
+	applyLoopConfig(l, config.LoopConfig{StallMinutes: 1, WatchdogMinutes: -1})
+	if l.StallTimeout() != time.Minute {
+		t.Errorf("expected stall timeout 1m, got %v", l.StallTimeout())
+	}
+	if l.WatchdogTimeout() != 0 {
+		t.Errorf("expected watchdog disabled, got %v", l.WatchdogTimeout())
+	}
+	if l.StoryTimeout() != 0 {
+		t.Errorf("expected no story timeout by default, got %v", l.StoryTimeout())
+	}
+
+	applyLoopConfig(l, config.LoopConfig{StoryTimeoutMinutes: 30})
+	if l.StoryTimeout() != 30*time.Minute {
+		t.Errorf("expected story timeout 30m, got %v", l.StoryTimeout())
+	}
+}
+
 func TestManagerSetPostCompleteCallback(t *testing.T) {
 	m := NewManager(10, testProvider)
 
@@ -0,0 +1,34 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/minicodemonkey/chief/internal/prompt"
+)
+
+// errPromptMiddleware is returned by runIteration when a prompt middleware
+// fails. It is a configuration problem, so the iteration is not retried.
+var errPromptMiddleware = errors.New("prompt middleware failed")
+
+// SetPromptMiddlewares runs each iteration's prompt through chain before it is
+// sent to the agent. The chain replaces the built-in language note, so it
+// should include the "language" middleware when a language is set.
+func (l *Loop) SetPromptMiddlewares(chain prompt.Chain) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.middlewares = chain
+}
+
+// applyMiddlewares runs text through the configured middlewares.
+func (l *Loop) applyMiddlewares(ctx context.Context, chain prompt.Chain, text string) (string, error) {
+	if chain == nil {
+		return text, nil
+	}
+	text, err := chain.Apply(ctx, text)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errPromptMiddleware, err)
+	}
+	return text, nil
+}
@@ -0,0 +1,418 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/minicodemonkey/chief/embed"
+	"github.com/minicodemonkey/chief/internal/git"
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// LaneDir returns the directory holding the worktrees stories of a parallel
+// run are worked on in.
+func LaneDir(baseDir string) string {
+	return filepath.Join(baseDir, ".chief", "lanes")
+}
+
+// SetParallel makes the loop work on up to n stories at a time, each in its
+// own git worktree under laneDir, merging each story's branch back into the
+// working directory once it passes. setup is run with `sh -c` in each new
+// worktree ("" = none). n <= 1 works on one story at a time in the working
+// directory.
+func (l *Loop) SetParallel(n int, laneDir, setup string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.parallel = n
+	l.laneDir = laneDir
+	l.laneSetup = setup
+}
+
+// laneOutcome is how a lane of a parallel run ended.
+type laneOutcome struct {
+	finished bool // The story was done or needed review
+	maxed    bool // The lane ran out of iterations
+	err      error
+}
+
+// runParallel runs batches of stories that can be worked on side by side
+// until every story is done, the iterations run out, or the loop is paused
+// or stopped. Each story of a batch runs in a lane: a loop of its own, in a
+// worktree on a branch of its own started from the working directory's HEAD.
+func (l *Loop) runParallel(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.stopped {
+			l.mu.Unlock()
+			l.finishStop("")
+			return nil
+		}
+		if l.paused {
+			l.mu.Unlock()
+			return nil
+		}
+		iter, maxIter, n := l.iteration, l.maxIter, l.parallel
+		alone := make(map[string]bool, len(l.runAlone))
+		for id := range l.runAlone {
+			alone[id] = true
+		}
+		l.mu.Unlock()
+
+		// Wait out quiet hours, then check again whether to continue
+		if until, quiet := l.quietUntil(); quiet {
+			if err := l.waitQuietHours(ctx, until); err != nil && !l.IsStopped() {
+				return err
+			}
+			continue
+		}
+
+		if iter >= maxIter {
+			l.clearRunState()
+			l.events <- Event{Type: EventMaxIterationsReached, Iteration: iter}
+			return nil
+		}
+
+		p, err := prd.LoadPRD(l.prdPath)
+		if err != nil {
+			l.events <- Event{Type: EventError, Iteration: iter, Err: err}
+			return err
+		}
+		batch := planBatch(p, n, alone)
+		if len(batch) == 0 {
+			waiting, review, skipped, cycle, prds := p.WaitingOnHumans(), p.NeedingReview(), p.SkippedStories(), p.DependencyCycle(), p.ExternalBlockers()
+			if len(waiting) > 0 || len(review) > 0 || len(skipped) > 0 || len(cycle) > 0 || len(prds) > 0 {
+				l.events <- Event{
+					Type:      EventWaitingOnHuman,
+					Iteration: iter,
+					Text:      (&waitingOnHumansError{stories: waiting, review: review, skipped: skipped, cycle: cycle, prds: prds}).Error(),
+				}
+				l.mu.Lock()
+				l.waitingOn = prds
+				l.mu.Unlock()
+				l.Pause()
+				return nil
+			}
+			l.clearRunState()
+			l.events <- Event{Type: EventComplete, Iteration: iter, Text: l.coverageSummary()}
+			return nil
+		}
+
+		outcomes, err := l.runBatch(ctx, batch, maxIter-iter)
+		if err != nil {
+			return err
+		}
+		if l.IsStopped() {
+			continue // Reported at the top
+		}
+		for _, outcome := range outcomes {
+			if outcome.err != nil {
+				return outcome.err // The lane reported it
+			}
+		}
+		for _, outcome := range outcomes {
+			if outcome.maxed {
+				l.events <- Event{Type: EventMaxIterationsReached, Iteration: l.Iteration()}
+				return nil
+			}
+		}
+		for _, outcome := range outcomes {
+			if !outcome.finished {
+				// The lane was paused, e.g. by crashes or a story timeout
+				l.Pause()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+	}
+}
+
+// runBatch works on the stories of batch side by side, each lane allowed up
+// to budget iterations, and merges the stories that passed back into the
+// working directory in batch order. A story that conflicts with the ones
+// merged before it is put back to run again on its own.
+func (l *Loop) runBatch(ctx context.Context, batch []prd.UserStory, budget int) ([]laneOutcome, error) {
+	workDir := l.effectiveWorkDir()
+	base := git.HeadCommit(workDir)
+	if base == "" {
+		err := errors.New("running stories in parallel needs a git repository with at least one commit")
+		l.events <- Event{Type: EventError, Iteration: l.Iteration(), Err: err}
+		return nil, err
+	}
+
+	l.mu.Lock()
+	laneDir, setup := l.laneDir, l.laneSetup
+	l.mu.Unlock()
+	prdName := filepath.Base(filepath.Dir(l.prdPath))
+	lanes := make([]*Loop, len(batch))
+	paths := make([]string, len(batch))
+	branches := make([]string, len(batch))
+	for i, story := range batch {
+		name := prdName + "-" + strings.ToLower(story.ID)
+		paths[i] = filepath.Join(laneDir, name)
+		branches[i] = "chief/" + name
+		fresh := !git.IsWorktree(paths[i])
+		err := git.AddWorktreeAt(workDir, paths[i], branches[i], base)
+		if err == nil && fresh && setup != "" {
+			cmd := exec.CommandContext(ctx, "sh", "-c", setup)
+			cmd.Dir = paths[i]
+			if out, setupErr := cmd.CombinedOutput(); setupErr != nil {
+				err = fmt.Errorf("worktree.setup failed: %v\n%s", setupErr, strings.TrimSpace(string(out)))
+				_ = git.DiscardWorktree(workDir, paths[i], branches[i])
+			}
+		}
+		if err != nil {
+			err = fmt.Errorf("could not set up a worktree for %s: %w", story.ID, err)
+			l.events <- Event{Type: EventError, Iteration: l.Iteration(), StoryID: story.ID, Err: err}
+			return nil, err
+		}
+		lanes[i] = l.newLane(story.ID, paths[i], budget)
+	}
+
+	l.mu.Lock()
+	l.lanes = lanes
+	stopped, paused := l.stopped, l.paused
+	l.mu.Unlock()
+	for _, lane := range lanes {
+		if stopped {
+			lane.Stop()
+		} else if paused {
+			lane.Pause()
+		}
+	}
+	outcomes := make([]laneOutcome, len(batch))
+	var wg sync.WaitGroup
+	for i, lane := range lanes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			forwarded := make(chan struct{})
+			go func() {
+				outcomes[i].finished, outcomes[i].maxed = l.forwardLane(lane, batch[i].ID)
+				close(forwarded)
+			}()
+			outcomes[i].err = lane.Run(ctx)
+			<-forwarded
+		}()
+	}
+	wg.Wait()
+	l.mu.Lock()
+	l.lanes = nil
+	l.mu.Unlock()
+
+	merged := false
+	for i, story := range batch {
+		p, err := prd.LoadPRD(l.prdPath)
+		if err != nil {
+			return outcomes, err
+		}
+		passed := false
+		for _, s := range p.UserStories {
+			if s.ID == story.ID {
+				passed = s.Passes
+			}
+		}
+		if !passed {
+			continue // Left in its worktree to be resumed or reviewed
+		}
+		ok, err := l.mergeLane(story.ID, workDir, branches[i])
+		if err != nil {
+			// Keep the work; the story is merged when it is picked up again
+			_ = prd.SetStoryStatus(l.prdPath, story.ID, "todo")
+			l.emitWithStory(EventStoryMerged, story.ID, "", fmt.Errorf("could not merge %s, left on %s: %w", story.ID, branches[i], err))
+			l.Pause()
+			continue
+		}
+		merged = merged || ok
+		if err := git.DiscardWorktree(workDir, paths[i], branches[i]); err != nil {
+			l.emitWithStory(EventStoryMerged, story.ID, "", fmt.Errorf("could not remove the worktree of %s: %w", story.ID, err))
+		}
+	}
+	if merged {
+		l.updateSummary(ctx)
+	}
+	return outcomes, nil
+}
+
+// mergeLane merges the branch a story was done on into workDir and reports
+// whether it merged. On a conflict the merge is undone and the story is put
+// back to run again on its own, on top of the stories merged so far. An
+// error means the merge failed for another reason.
+func (l *Loop) mergeLane(storyID, workDir, branch string) (bool, error) {
+	conflicts, err := git.MergeBranch(workDir, branch)
+	if err != nil && len(conflicts) == 0 {
+		return false, err
+	}
+	if err == nil {
+		l.mu.Lock()
+		delete(l.runAlone, strings.ToUpper(storyID))
+		l.mu.Unlock()
+		l.emitWithStory(EventStoryMerged, storyID, fmt.Sprintf("Merged %s from %s", storyID, branch), nil)
+		return true, nil
+	}
+
+	_ = prd.SetStoryStatus(l.prdPath, storyID, "todo")
+	l.mu.Lock()
+	if l.runAlone == nil {
+		l.runAlone = make(map[string]bool)
+	}
+	l.runAlone[strings.ToUpper(storyID)] = true
+	l.mu.Unlock()
+	l.emitWithStory(EventStoryMerged, storyID, "", fmt.Errorf("%s conflicts with stories merged before it in %s; it runs again on its own", storyID, strings.Join(conflicts, ", ")))
+	return false, nil
+}
+
+// forwardLane passes the events of a lane working on storyID on as the
+// loop's own, numbering iterations across lanes, until the lane ends. It
+// returns whether the lane finished its story and whether it ran out of
+// iterations; those events, and the lane's stop, are left for the loop to
+// report once for the whole run.
+func (l *Loop) forwardLane(lane *Loop, storyID string) (finished, maxed bool) {
+	for event := range lane.Events() {
+		switch event.Type {
+		case EventComplete:
+			finished = true
+			continue
+		case EventMaxIterationsReached:
+			maxed = true
+			continue
+		case EventStopped, EventWaitingOnHuman:
+			continue
+		}
+		l.mu.Lock()
+		started := event.Type == EventIterationStart && event.StoryID != ""
+		if started {
+			l.iteration++
+		}
+		event.Iteration = l.iteration
+		l.mu.Unlock()
+		if started {
+			l.saveRunState()
+		}
+		if event.StoryID == "" {
+			event.StoryID = storyID
+		}
+		l.events <- event
+	}
+	return finished, maxed
+}
+
+// newLane returns a loop working on storyID alone in workDir, with the
+// loop's settings. Lanes don't open stacked PRs, push, or update the
+// summary; the loop does what is needed once their stories are merged.
+func (l *Loop) newLane(storyID, workDir string, maxIter int) *Loop {
+	lane := NewLoopWithWorkDir(l.prdPath, workDir, "", maxIter, l.provider)
+	lane.buildPrompt = lane.promptBuilderForStory(storyID)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lane.retryConfig = l.retryConfig
+	lane.watchdogTimeout = l.watchdogTimeout
+	lane.stallTimeout = l.stallTimeout
+	lane.stopGrace = l.stopGrace
+	lane.crashLimit = l.crashLimit
+	lane.storyRetries = l.storyRetries
+	lane.storyTimeout = l.storyTimeout
+	lane.commitRules = l.commitRules
+	lane.trailers = l.trailers
+	lane.runID = l.runID
+	lane.language = l.language
+	lane.quietHours = l.quietHours
+	lane.squashTemplate = l.squashTemplate
+	lane.coverage = coverageState{command: l.coverage.command, maxDrop: l.coverage.maxDrop}
+	lane.verify = l.verify
+	lane.toolCache = l.toolCache
+	lane.middlewares = l.middlewares
+	lane.fileIssues = l.fileIssues
+	lane.exploreTurns = l.exploreTurns
+	return lane
+}
+
+// promptBuilderForStory returns a prompt builder like promptBuilderForPRD's
+// that only ever works on storyID. It reports that no work is left once the
+// story is done or needs review, which ends the lane.
+func (l *Loop) promptBuilderForStory(storyID string) func() (string, string, error) {
+	prdPath := l.prdPath
+	return func() (string, string, error) {
+		p, err := prd.LoadPRD(prdPath)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to load PRD for prompt: %w", err)
+		}
+		for i := range p.UserStories {
+			story := &p.UserStories[i]
+			if story.ID != storyID {
+				continue
+			}
+			if story.Passes || story.NeedsReview || story.Skipped {
+				return "", "", fmt.Errorf("story %s is finished", storyID)
+			}
+			_ = prd.SetStoryStatus(prdPath, story.ID, "in-progress")
+
+			// The agent works in another worktree, where .chief isn't checked out
+			progressPath, err := filepath.Abs(prd.ProgressPath(prdPath))
+			if err != nil {
+				progressPath = prd.ProgressPath(prdPath)
+			}
+			return embed.GetPrompt(progressPath, prd.StoryContext(l.withDone(story)), story.ID, story.Title), story.ID, nil
+		}
+		return "", "", fmt.Errorf("story %s not found in PRD", storyID)
+	}
+}
+
+// planBatch picks up to n stories that can be worked on side by side: stories
+// ready to start, in the order NextStory would pick them, leaving out any that
+// names files a story picked before it names too. Those wait for a later
+// batch, so stories expected to touch the same files run one after another.
+// A story in alone (upper-case IDs) only runs in a batch of its own.
+func planBatch(p *prd.PRD, n int, alone map[string]bool) []prd.UserStory {
+	var ready []prd.UserStory
+	for i := range p.UserStories {
+		s := &p.UserStories[i]
+		if !s.Passes && !s.IsHuman() && !s.NeedsReview && !s.Skipped && len(p.BlockedBy(s)) == 0 {
+			ready = append(ready, *s)
+		}
+	}
+	sort.SliceStable(ready, func(i, j int) bool {
+		if ready[i].InProgress != ready[j].InProgress {
+			return ready[i].InProgress
+		}
+		return ready[i].Priority < ready[j].Priority
+	})
+
+	var batch []prd.UserStory
+	var files [][]string
+	for _, s := range ready {
+		if len(batch) >= max(n, 1) {
+			break
+		}
+		if alone[strings.ToUpper(s.ID)] {
+			if len(batch) == 0 {
+				return []prd.UserStory{s}
+			}
+			continue
+		}
+		storyFiles := prd.StoryFiles(&s)
+		overlaps := false
+		for _, other := range files {
+			if prd.FilesOverlap(storyFiles, other) {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			continue
+		}
+		batch = append(batch, s)
+		files = append(files, storyFiles)
+	}
+	return batch
+}
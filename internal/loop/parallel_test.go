@@ -0,0 +1,205 @@
+package loop
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+func TestPlanBatch(t *testing.T) {
+	p := &prd.PRD{UserStories: []prd.UserStory{
+		{ID: "US-001", Priority: 1, Passes: true},
+		{ID: "US-002", Priority: 2, Description: "Change internal/auth/login.go"},
+		{ID: "US-003", Priority: 3, Description: "Add a test to internal/auth/"},
+		{ID: "US-004", Priority: 4, Description: "Update `billing.go`"},
+		{ID: "US-005", Priority: 5, DependsOn: []string{"US-002"}},
+		{ID: "US-006", Priority: 6, Owner: prd.OwnerHuman},
+		{ID: "US-007", Priority: 7, NeedsReview: true},
+		{ID: "US-008", Priority: 8},
+		{ID: "US-009", Priority: 0.5, Description: "Rename internal/billing/billing.go"},
+	}}
+
+	ids := func(batch []prd.UserStory) string {
+		var out []string
+		for _, s := range batch {
+			out = append(out, s.ID)
+		}
+		return strings.Join(out, ",")
+	}
+
+	// US-003 overlaps US-002 and US-004 overlaps US-009, so they wait
+	if got := ids(planBatch(p, 4, nil)); got != "US-009,US-002,US-008" {
+		t.Errorf("planBatch(4) = %s, want US-009,US-002,US-008", got)
+	}
+	if got := ids(planBatch(p, 2, nil)); got != "US-009,US-002" {
+		t.Errorf("planBatch(2) = %s, want US-009,US-002", got)
+	}
+
+	// A story that has to run alone gets a batch of its own when it is next
+	if got := ids(planBatch(p, 4, map[string]bool{"US-009": true})); got != "US-009" {
+		t.Errorf("planBatch with US-009 alone = %s, want US-009", got)
+	}
+	if got := ids(planBatch(p, 4, map[string]bool{"US-008": true})); got != "US-009,US-002" {
+		t.Errorf("planBatch with US-008 alone = %s, want US-009,US-002", got)
+	}
+
+	// An interrupted story is picked up first
+	p.UserStories[7].InProgress = true
+	if got := ids(planBatch(p, 1, nil)); got != "US-008" {
+		t.Errorf("planBatch(1) = %s, want US-008", got)
+	}
+}
+
+// laneProvider runs an agent that writes one file for the story in its
+// prompt, commits it, and reports the story done.
+type laneProvider struct {
+	mockProvider
+	file func(storyID string) (name, content string)
+}
+
+var promptStoryRegex = regexp.MustCompile(`"id": "([A-Z]+-\d+)"`)
+
+func (p *laneProvider) LoopCommand(ctx context.Context, prompt, workDir string) *exec.Cmd {
+	id := promptStoryRegex.FindStringSubmatch(prompt)[1]
+	name, content := p.file(id)
+	script := fmt.Sprintf(`echo %s > %s && git add -A && git commit -qm %s && echo '{"type":"assistant","message":{"content":[{"type":"text","text":"<chief-done/>"}]}}'`, content, name, id)
+	cmd := exec.CommandContext(ctx, "sh", "-c", script)
+	cmd.Dir = workDir
+	return cmd
+}
+
+func (p *laneProvider) ReadOnlyCommand(ctx context.Context, prompt, workDir string, _ ...string) (*exec.Cmd, error) {
+	return p.LoopCommand(ctx, prompt, workDir), nil
+}
+
+// initParallelRepo creates a repository with .chief ignored and a PRD of two
+// stories, and returns the repository and the PRD's path.
+func initParallelRepo(t *testing.T) (string, string) {
+	t.Helper()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ".gitignore"), []byte(".chief/\n"), 0644)
+	for _, args := range [][]string{
+		{"git", "init", "-b", "main"},
+		{"git", "config", "user.name", "Test"},
+		{"git", "config", "user.email", "test@test.com"},
+		{"git", "add", "."},
+		{"git", "commit", "-m", "initial"},
+	} {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v failed: %s", args, out)
+		}
+	}
+
+	prdPath := filepath.Join(dir, ".chief", "prds", "auth", "prd.md")
+	os.MkdirAll(filepath.Dir(prdPath), 0755)
+	content := "# Auth\n\n### US-001: First\n**Priority:** 1\n- [ ] Works\n\n### US-002: Second\n**Priority:** 2\n- [ ] Works\n"
+	if err := os.WriteFile(prdPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir, prdPath
+}
+
+// runParallelLoop runs a loop working on two stories at a time and returns its events.
+func runParallelLoop(t *testing.T, dir, prdPath string, provider Provider) []Event {
+	t.Helper()
+	l := NewLoopWithWorkDir(prdPath, dir, "", 10, provider)
+	l.buildPrompt = l.promptBuilderForPRD()
+	l.SetParallel(2, LaneDir(dir), "")
+
+	var events []Event
+	done := make(chan struct{})
+	go func() {
+		for event := range l.Events() {
+			events = append(events, event)
+		}
+		close(done)
+	}()
+	if err := l.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	<-done
+	return events
+}
+
+func TestLoop_RunParallel(t *testing.T) {
+	dir, prdPath := initParallelRepo(t)
+	provider := &laneProvider{file: func(id string) (string, string) { return id + ".txt", id }}
+	events := runParallelLoop(t, dir, prdPath, provider)
+
+	p, err := prd.LoadPRD(prdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.AllComplete() {
+		t.Errorf("expected every story done, got %+v", p.UserStories)
+	}
+	for _, id := range []string{"US-001", "US-002"} {
+		if _, err := os.Stat(filepath.Join(dir, id+".txt")); err != nil {
+			t.Errorf("expected %s's work to be merged: %v", id, err)
+		}
+	}
+	if entries, _ := os.ReadDir(LaneDir(dir)); len(entries) != 0 {
+		t.Errorf("expected the lane worktrees to be removed, found %d", len(entries))
+	}
+
+	merged, starts := 0, 0
+	for _, e := range events {
+		switch e.Type {
+		case EventStoryMerged:
+			if e.Err != nil {
+				t.Errorf("unexpected merge failure: %v", e.Err)
+			}
+			merged++
+		case EventIterationStart:
+			starts++
+		}
+	}
+	if merged != 2 || starts != 2 {
+		t.Errorf("got %d merges and %d iterations, want 2 and 2", merged, starts)
+	}
+	if last := events[len(events)-1]; last.Type != EventComplete || last.Iteration != 2 {
+		t.Errorf("last event = %v at iteration %d, want Complete at 2", last.Type, last.Iteration)
+	}
+}
+
+func TestLoop_RunParallelConflict(t *testing.T) {
+	dir, prdPath := initParallelRepo(t)
+	provider := &laneProvider{file: func(id string) (string, string) { return "shared.txt", id }}
+	events := runParallelLoop(t, dir, prdPath, provider)
+
+	p, err := prd.LoadPRD(prdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.AllComplete() {
+		t.Errorf("expected every story done, got %+v", p.UserStories)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "shared.txt"))
+	if strings.TrimSpace(string(data)) != "US-002" {
+		t.Errorf("shared.txt = %q, want US-002's rerun on top of US-001", data)
+	}
+
+	conflicts := 0
+	for _, e := range events {
+		if e.Type == EventStoryMerged && e.Err != nil {
+			conflicts++
+			if e.StoryID != "US-002" || !strings.Contains(e.Text, "shared.txt") {
+				t.Errorf("unexpected conflict event: %+v", e)
+			}
+		}
+	}
+	if conflicts != 1 {
+		t.Errorf("got %d conflicts, want 1", conflicts)
+	}
+}
@@ -31,6 +31,66 @@ const (
 	EventRetrying
 	// EventWatchdogTimeout is emitted when the watchdog kills a hung process.
 	EventWatchdogTimeout
+	// EventStalled is emitted when the agent has produced no output for the stall timeout.
+	EventStalled
+	// EventStoryTimeout is emitted when a story exceeds its time budget and the agent is asked to wrap up.
+	EventStoryTimeout
+	// EventCommitRejected is emitted when agent commits fail the commit message rules.
+	EventCommitRejected
+	// EventStorySquashed is emitted after a finished story's commits are squashed (Err is set on failure).
+	EventStorySquashed
+	// EventCoverage is emitted with the coverage change after a story (Err is set when
+	// coverage couldn't be measured or dropped too far).
+	EventCoverage
+	// EventVerify is emitted after the verification command runs for a finished story
+	// (Err is set when it failed).
+	EventVerify
+	// EventFlakyTest is emitted when a test is found to be flaky.
+	EventFlakyTest
+	// EventStackedPR is emitted when a story's stacked PR is opened or updated, and
+	// when the run pauses for a stacked PR with changes requested (Err is set on failure).
+	EventStackedPR
+	// EventStopped is emitted when a stopped loop has wound down, with a summary of the run.
+	EventStopped
+	// EventCircuitOpen is emitted when repeated agent crashes pause the run.
+	EventCircuitOpen
+	// EventQuietHours is emitted when the loop waits for quiet hours to end
+	// before starting the next iteration.
+	EventQuietHours
+	// EventWaitingOnHuman is emitted when the only stories left are human
+	// tasks, or depend on them, and the loop pauses until they are done.
+	EventWaitingOnHuman
+	// EventDirtyWorktree is emitted when a run starts with uncommitted changes
+	// and stashes or includes them, and when stashed changes are restored
+	// (Err is set when they couldn't be).
+	EventDirtyWorktree
+	// EventSummary is emitted when the summary of completed stories is
+	// regenerated (Err is set when it couldn't be).
+	EventSummary
+	// EventExplore is emitted after a story's read-only exploration pass
+	// (Err is set when it produced no findings).
+	EventExplore
+	// EventPush is emitted when the loop pushes commits the push policy held
+	// back (Err is set when the push failed).
+	EventPush
+	// EventStoryBlocked is emitted when a story used up its retries and is
+	// marked as needing review, or when stories failing on the same test are
+	// paused behind a story to fix it (Err is set when that failed).
+	EventStoryBlocked
+	// EventStoryPassed is emitted when a finished story passed its checks
+	// and was marked done in the PRD.
+	EventStoryPassed
+	// EventStoryMerged is emitted when a parallel run merges the branch a
+	// story was done on back into the working directory (Err is set when the
+	// merge failed or conflicted).
+	EventStoryMerged
+	// EventUsage is emitted when the agent reports the tokens and cost of
+	// an iteration as it exits.
+	EventUsage
+	// EventRemoteSync is emitted when a run starts on a branch that is
+	// behind its remote: after rebasing onto it, or when the run pauses
+	// instead (Err is set then, and when the remote couldn't be fetched).
+	EventRemoteSync
 )
 
 // String returns the string representation of an EventType.
@@ -56,11 +116,63 @@ func (e EventType) String() string {
 		return "Retrying"
 	case EventWatchdogTimeout:
 		return "WatchdogTimeout"
+	case EventStalled:
+		return "Stalled"
+	case EventStoryTimeout:
+		return "StoryTimeout"
+	case EventCommitRejected:
+		return "CommitRejected"
+	case EventStorySquashed:
+		return "StorySquashed"
+	case EventCoverage:
+		return "Coverage"
+	case EventVerify:
+		return "Verify"
+	case EventFlakyTest:
+		return "FlakyTest"
+	case EventStackedPR:
+		return "StackedPR"
+	case EventStopped:
+		return "Stopped"
+	case EventCircuitOpen:
+		return "CircuitOpen"
+	case EventQuietHours:
+		return "QuietHours"
+	case EventWaitingOnHuman:
+		return "WaitingOnHuman"
+	case EventDirtyWorktree:
+		return "DirtyWorktree"
+	case EventSummary:
+		return "Summary"
+	case EventExplore:
+		return "Explore"
+	case EventPush:
+		return "Push"
+	case EventStoryBlocked:
+		return "StoryBlocked"
+	case EventStoryPassed:
+		return "StoryPassed"
+	case EventStoryMerged:
+		return "StoryMerged"
+	case EventUsage:
+		return "Usage"
+	case EventRemoteSync:
+		return "RemoteSync"
 	default:
 		return "Unknown"
 	}
 }
 
+// ParseEventType returns the EventType whose String() is name, or EventUnknown.
+func ParseEventType(name string) EventType {
+	for t := EventIterationStart; t.String() != "Unknown"; t++ {
+		if t.String() == name {
+			return t
+		}
+	}
+	return EventUnknown
+}
+
 // Event represents a parsed event from Claude's stream-json output.
 type Event struct {
 	Type       EventType
@@ -70,8 +182,25 @@ type Event struct {
 	ToolInput  map[string]interface{}
 	StoryID    string
 	Err        error
-	RetryCount int // Current retry attempt (1-based)
-	RetryMax   int // Maximum retries allowed
+	RetryCount int     // Current retry attempt (1-based)
+	RetryMax   int     // Maximum retries allowed
+	Stderr     string  // Last lines of the agent's stderr (failure events)
+	Cause      string  // Classified failure cause (auth, network, oom, rate-limit)
+	MessageID  string  // Assistant message the event came from, when the agent reports it
+	Usage      Usage   // Tokens of that message, when the agent reports them
+	Cost       float64 // Dollars the iteration cost (EventUsage only)
+}
+
+// Usage is the tokens an assistant message used. Input includes tokens read
+// from and written to the prompt cache.
+type Usage struct {
+	Input  int
+	Output int
+}
+
+// Total returns the input and output tokens together.
+func (u Usage) Total() int {
+	return u.Input + u.Output
 }
 
 // streamMessage represents the top-level structure of a stream-json line.
@@ -79,11 +208,25 @@ type streamMessage struct {
 	Type    string          `json:"type"`
 	Subtype string          `json:"subtype,omitempty"`
 	Message json.RawMessage `json:"message,omitempty"`
+
+	// Reported once, in the result message the agent ends with
+	Usage        *messageUsage `json:"usage,omitempty"`
+	TotalCostUSD float64       `json:"total_cost_usd,omitempty"`
 }
 
 // assistantMessage represents the structure of an assistant message.
 type assistantMessage struct {
+	ID      string         `json:"id,omitempty"`
 	Content []contentBlock `json:"content"`
+	Usage   *messageUsage  `json:"usage,omitempty"`
+}
+
+// messageUsage is the token usage reported with an assistant message.
+type messageUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
 }
 
 // contentBlock represents a block of content in an assistant message.
@@ -134,7 +277,7 @@ func ParseLine(line string) *Event {
 		return parseUserMessage(msg.Message)
 
 	case "result":
-		return nil
+		return parseResultMessage(msg)
 
 	default:
 		return nil
@@ -152,7 +295,42 @@ func parseAssistantMessage(raw json.RawMessage) *Event {
 		return nil
 	}
 
-	for _, block := range msg.Content {
+	event := parseContent(msg.Content)
+	if event != nil {
+		event.MessageID = msg.ID
+		if u := msg.Usage; u != nil {
+			event.Usage = u.usage()
+		}
+	}
+	return event
+}
+
+// usage converts the reported usage, counting cached input tokens as input.
+func (u *messageUsage) usage() Usage {
+	return Usage{
+		Input:  u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens,
+		Output: u.OutputTokens,
+	}
+}
+
+// parseResultMessage returns the usage event for the result message the agent
+// ends with, or nil when it reports no usage.
+func parseResultMessage(msg streamMessage) *Event {
+	if msg.Usage == nil && msg.TotalCostUSD == 0 {
+		return nil
+	}
+	event := &Event{Type: EventUsage, Cost: msg.TotalCostUSD}
+	if msg.Usage != nil {
+		event.Usage = msg.Usage.usage()
+	}
+	event.Text = "Used " + FormatUsage(event.Usage.Total(), event.Cost)
+	return event
+}
+
+// parseContent returns the event for the first text or tool call in an
+// assistant message's content.
+func parseContent(content []contentBlock) *Event {
+	for _, block := range content {
 		switch block.Type {
 		case "text":
 			text := block.Text
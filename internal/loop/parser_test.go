@@ -154,12 +154,30 @@ func TestParseLineResultMessage(t *testing.T) {
 	line := `{"type":"result","subtype":"success","is_error":false,"result":"Done"}`
 
 	event := ParseLine(line)
-	// Result messages are not emitted as events
+	// Result messages without usage are not emitted as events
 	if event != nil {
 		t.Errorf("ParseLine returned %v, want nil for result message", event)
 	}
 }
 
+func TestParseLineResultUsage(t *testing.T) {
+	line := `{"type":"result","subtype":"success","is_error":false,"result":"Done","total_cost_usd":0.4231,"usage":{"input_tokens":20,"cache_creation_input_tokens":1000,"cache_read_input_tokens":41000,"output_tokens":2500}}`
+
+	event := ParseLine(line)
+	if event == nil {
+		t.Fatal("ParseLine returned nil, want event")
+	}
+	if event.Type != EventUsage {
+		t.Errorf("event.Type = %v, want EventUsage", event.Type)
+	}
+	if event.Usage != (Usage{Input: 42020, Output: 2500}) || event.Cost != 0.4231 {
+		t.Errorf("event usage = %+v at $%v, want 42020 in, 2500 out at $0.4231", event.Usage, event.Cost)
+	}
+	if event.Text != "Used 44k tokens, $0.42" {
+		t.Errorf("event.Text = %q", event.Text)
+	}
+}
+
 func TestParseLineUnknownType(t *testing.T) {
 	line := `{"type":"unknown_type"}`
 
@@ -205,6 +223,21 @@ func TestParseLineRealWorldToolUse(t *testing.T) {
 	}
 }
 
+func TestParseLineUsage(t *testing.T) {
+	line := `{"type":"assistant","message":{"id":"msg_01","type":"message","role":"assistant","content":[{"type":"tool_use","id":"toolu_01","name":"Bash","input":{"command":"go test ./..."}}],"usage":{"input_tokens":12,"cache_creation_input_tokens":300,"cache_read_input_tokens":4000,"output_tokens":85}}}`
+
+	event := ParseLine(line)
+	if event == nil {
+		t.Fatal("ParseLine returned nil, want event")
+	}
+	if event.MessageID != "msg_01" {
+		t.Errorf("event.MessageID = %q, want %q", event.MessageID, "msg_01")
+	}
+	if event.Usage != (Usage{Input: 4312, Output: 85}) || event.Usage.Total() != 4397 {
+		t.Errorf("event.Usage = %+v, want 4312 in and 85 out", event.Usage)
+	}
+}
+
 func TestParseLineMultipleContentBlocks(t *testing.T) {
 	line := `{"type":"assistant","message":{"content":[{"type":"text","text":"First"},{"type":"tool_use","name":"Read","input":{}}]}}`
 
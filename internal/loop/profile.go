@@ -0,0 +1,267 @@
+package loop
+
+import (
+	"sort"
+	"time"
+)
+
+// Phase is what a stretch of a run's time went to.
+type Phase string
+
+const (
+	PhaseThinking Phase = "thinking"     // The agent reasoning and writing, between tool calls
+	PhaseTools    Phase = "tools"        // Tool calls running
+	PhaseVerify   Phase = "verification" // Verification, browser tests, and coverage after a story
+	PhaseOther    Phase = "other"        // Startup, retries, waits, and post-story steps
+)
+
+// Phases lists the phases in display order.
+var Phases = []Phase{PhaseThinking, PhaseTools, PhaseVerify, PhaseOther}
+
+// Category groups what the agent spent time and tokens on by the kind of
+// tool involved.
+type Category string
+
+const (
+	CategoryThinking Category = "thinking" // Reasoning and writing, and messages that call no tool
+	CategoryEdits    Category = "edits"    // Writing and editing files
+	CategoryShell    Category = "shell"    // Shell commands
+	CategoryReads    Category = "reads"    // Reading and searching files
+	CategoryWeb      Category = "web"      // Fetching pages and searching the web
+	CategoryOther    Category = "other"    // Any other tool, e.g. sub-agents and MCP tools
+)
+
+// Categories lists the categories in display order.
+var Categories = []Category{CategoryThinking, CategoryEdits, CategoryShell, CategoryReads, CategoryWeb, CategoryOther}
+
+// toolCategories maps the tool names of the supported agents to categories.
+var toolCategories = map[string]Category{
+	"Bash": CategoryShell, "BashOutput": CategoryShell, "KillShell": CategoryShell, "bash": CategoryShell, "shell": CategoryShell,
+	"Read": CategoryReads, "Grep": CategoryReads, "Glob": CategoryReads, "LS": CategoryReads, "NotebookRead": CategoryReads,
+	"read": CategoryReads, "grep": CategoryReads, "glob": CategoryReads, "list": CategoryReads,
+	"WebFetch": CategoryWeb, "WebSearch": CategoryWeb, "webfetch": CategoryWeb, "websearch": CategoryWeb,
+	"file_change": CategoryEdits,
+}
+
+// ToolCategory returns the category of a tool.
+func ToolCategory(tool string) Category {
+	if writeTools[tool] {
+		return CategoryEdits
+	}
+	if c, ok := toolCategories[tool]; ok {
+		return c
+	}
+	return CategoryOther
+}
+
+// CategoryUsage is the time and tokens spent on one category.
+type CategoryUsage struct {
+	Calls  int           // Tool calls (none for thinking)
+	Time   time.Duration // Tool calls running, or the agent thinking
+	Tokens int           // Tokens of the messages that made the calls
+}
+
+// ToolCall is one timed tool call.
+type ToolCall struct {
+	StoryID  string
+	Tool     string
+	Detail   string
+	Start    time.Time
+	Duration time.Duration
+}
+
+// StoryProfile is where the time spent on one story went.
+type StoryProfile struct {
+	StoryID    string
+	Iterations int
+	Phases     map[Phase]time.Duration
+	Categories map[Category]*CategoryUsage
+}
+
+// Total returns the time spent on the story.
+func (s *StoryProfile) Total() time.Duration {
+	var total time.Duration
+	for _, d := range s.Phases {
+		total += d
+	}
+	return total
+}
+
+// Profile is where the time in one or more runs went.
+type Profile struct {
+	Start      time.Time
+	Runs       int
+	Phases     map[Phase]time.Duration
+	Categories map[Category]*CategoryUsage
+	Stories    []*StoryProfile // In the order they were worked on
+	ToolCalls  []ToolCall      // Slowest first
+	Tokens     int             // Tokens the agent reported using (0 = not reported)
+}
+
+// category returns the usage of c, adding it to usage if needed.
+func category(usage map[Category]*CategoryUsage, c Category) *CategoryUsage {
+	u, ok := usage[c]
+	if !ok {
+		u = &CategoryUsage{}
+		usage[c] = u
+	}
+	return u
+}
+
+// Total returns the time covered by the profile.
+func (p *Profile) Total() time.Duration {
+	var total time.Duration
+	for _, d := range p.Phases {
+		total += d
+	}
+	return total
+}
+
+// LastRun returns the records of the most recent run in an event log.
+func LastRun(records []EventRecord) []EventRecord {
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].Type == RunStartRecord {
+			return records[i:]
+		}
+	}
+	return records
+}
+
+// phaseOf returns the phase of the time leading up to an event of the given type.
+func phaseOf(eventType string) Phase {
+	switch ParseEventType(eventType) {
+	case EventAssistantText, EventToolStart, EventStoryDone:
+		return PhaseThinking
+	case EventToolResult:
+		return PhaseTools
+	case EventVerify, EventCoverage, EventFlakyTest:
+		return PhaseVerify
+	default:
+		return PhaseOther
+	}
+}
+
+// BuildProfile works out where the time between the records went. The time
+// between two records is attributed by the later one: the wait before a tool
+// result was the tool running, the wait before text or a tool call was the
+// agent thinking, and so on. Time between runs isn't counted.
+//
+// Tool call time is broken down by category, and the agent thinking counts
+// as CategoryThinking. The tokens of an assistant message go to the category
+// of the tool it calls, or to thinking when it calls none.
+func BuildProfile(records []EventRecord) *Profile {
+	p := &Profile{Phases: make(map[Phase]time.Duration), Categories: make(map[Category]*CategoryUsage)}
+	stories := make(map[string]*StoryProfile)
+	story := func(id string) *StoryProfile {
+		s, ok := stories[id]
+		if !ok {
+			s = &StoryProfile{StoryID: id, Phases: make(map[Phase]time.Duration), Categories: make(map[Category]*CategoryUsage)}
+			stories[id] = s
+			p.Stories = append(p.Stories, s)
+		}
+		return s
+	}
+
+	// Messages are reported once per content block; keep each one's largest
+	// usage and the category of the tool it calls.
+	type message struct {
+		storyID  string
+		category Category
+		tokens   int
+	}
+	messages := make(map[string]*message)
+	var order []*message
+
+	var prev *EventRecord
+	var current string
+	var pending []ToolCall // Tool calls waiting for their result, in call order
+	for i := range records {
+		rec := &records[i]
+		if rec.Type == RunStartRecord {
+			p.Runs++
+			if p.Start.IsZero() {
+				p.Start = rec.Time
+			}
+			prev, current, pending = rec, "", nil
+			continue
+		}
+		if p.Start.IsZero() {
+			p.Start = rec.Time
+		}
+		if rec.StoryID != "" {
+			current = rec.StoryID
+		}
+
+		if prev != nil {
+			if gap := rec.Time.Sub(prev.Time); gap > 0 {
+				phase := phaseOf(rec.Type)
+				p.Phases[phase] += gap
+				if current != "" {
+					story(current).Phases[phase] += gap
+				}
+			}
+		}
+		prev = rec
+
+		if tokens := rec.Input + rec.Output; tokens > 0 || rec.MessageID != "" {
+			m, ok := messages[rec.MessageID]
+			if !ok || rec.MessageID == "" {
+				m = &message{storyID: current, category: CategoryThinking}
+				order = append(order, m)
+				if rec.MessageID != "" {
+					messages[rec.MessageID] = m
+				}
+			}
+			if tokens > m.tokens {
+				m.tokens = tokens
+			}
+			if ParseEventType(rec.Type) == EventToolStart {
+				m.category = ToolCategory(rec.Tool)
+			}
+		}
+
+		switch ParseEventType(rec.Type) {
+		case EventIterationStart:
+			pending = nil
+			if rec.StoryID != "" {
+				story(rec.StoryID).Iterations++
+			}
+		case EventToolStart:
+			pending = append(pending, ToolCall{StoryID: current, Tool: rec.Tool, Detail: rec.Detail, Start: rec.Time})
+		case EventToolResult:
+			if len(pending) > 0 {
+				call := pending[0]
+				pending = pending[1:]
+				call.Duration = rec.Time.Sub(call.Start)
+				p.ToolCalls = append(p.ToolCalls, call)
+				c := ToolCategory(call.Tool)
+				category(p.Categories, c).Calls++
+				category(p.Categories, c).Time += call.Duration
+				if call.StoryID != "" {
+					category(story(call.StoryID).Categories, c).Calls++
+					category(story(call.StoryID).Categories, c).Time += call.Duration
+				}
+			}
+		}
+	}
+	if p.Runs == 0 && len(records) > 0 {
+		p.Runs = 1
+	}
+
+	category(p.Categories, CategoryThinking).Time = p.Phases[PhaseThinking]
+	for _, s := range p.Stories {
+		category(s.Categories, CategoryThinking).Time = s.Phases[PhaseThinking]
+	}
+	for _, m := range order {
+		p.Tokens += m.tokens
+		category(p.Categories, m.category).Tokens += m.tokens
+		if m.storyID != "" {
+			category(story(m.storyID).Categories, m.category).Tokens += m.tokens
+		}
+	}
+
+	sort.SliceStable(p.ToolCalls, func(i, j int) bool {
+		return p.ToolCalls[i].Duration > p.ToolCalls[j].Duration
+	})
+	return p
+}
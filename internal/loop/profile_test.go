@@ -0,0 +1,130 @@
+package loop
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBuildProfile(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time { return start.Add(time.Duration(seconds) * time.Second) }
+	records := []EventRecord{
+		{Time: at(0), Type: RunStartRecord},
+		{Time: at(2), Type: "IterationStart", StoryID: "US-001"},
+		{Time: at(12), Type: "ToolStart", Tool: "Bash", Detail: "npm test"},
+		{Time: at(72), Type: "ToolResult"},
+		{Time: at(80), Type: "ToolStart", Tool: "Read", Detail: "src/app.ts"},
+		{Time: at(81), Type: "ToolResult"},
+		{Time: at(90), Type: "StoryDone"},
+		{Time: at(120), Type: "Verify", StoryID: "US-001"},
+		{Time: at(121), Type: "IterationStart", StoryID: "US-002"},
+		{Time: at(131), Type: "AssistantText"},
+	}
+
+	p := BuildProfile(records)
+	want := map[Phase]time.Duration{
+		PhaseThinking: 37 * time.Second, // 10 + 8 + 9 + 10
+		PhaseTools:    61 * time.Second,
+		PhaseVerify:   30 * time.Second,
+		PhaseOther:    3 * time.Second,
+	}
+	for phase, d := range want {
+		if p.Phases[phase] != d {
+			t.Errorf("Phases[%s] = %s, want %s", phase, p.Phases[phase], d)
+		}
+	}
+	if len(p.Stories) != 2 || p.Stories[0].StoryID != "US-001" || p.Stories[0].Total() != 120*time.Second {
+		t.Fatalf("unexpected stories: %+v", p.Stories)
+	}
+	if p.Stories[1].Phases[PhaseThinking] != 10*time.Second || p.Stories[1].Iterations != 1 {
+		t.Errorf("unexpected US-002 profile: %+v", p.Stories[1])
+	}
+	if len(p.ToolCalls) != 2 || p.ToolCalls[0].Detail != "npm test" || p.ToolCalls[0].Duration != time.Minute || p.ToolCalls[0].StoryID != "US-001" {
+		t.Errorf("expected npm test to be the slowest call, got %+v", p.ToolCalls)
+	}
+
+	// Only the latest run, and no time counted between runs
+	records = append(records, EventRecord{Time: at(3600), Type: RunStartRecord}, EventRecord{Time: at(3605), Type: "IterationStart", StoryID: "US-002"})
+	if last := LastRun(records); len(last) != 2 {
+		t.Errorf("LastRun() returned %d records, want 2", len(last))
+	}
+	if all := BuildProfile(records); all.Runs != 2 || all.Total() != 136*time.Second {
+		t.Errorf("expected 2 runs totalling 2m16s, got %d runs, %s", all.Runs, all.Total())
+	}
+}
+
+func TestBuildProfile_Categories(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time { return start.Add(time.Duration(seconds) * time.Second) }
+	records := []EventRecord{
+		{Time: at(0), Type: RunStartRecord},
+		{Time: at(1), Type: "IterationStart", StoryID: "US-001"},
+		// One message that thinks out loud, then edits a file
+		{Time: at(5), Type: "AssistantText", MessageID: "msg_1", Input: 1000, Output: 50},
+		{Time: at(6), Type: "ToolStart", Tool: "Edit", Detail: "main.go", MessageID: "msg_1", Input: 1000, Output: 200},
+		{Time: at(7), Type: "ToolResult"},
+		{Time: at(10), Type: "ToolStart", Tool: "Bash", Detail: "go test ./...", MessageID: "msg_2", Input: 2000, Output: 30},
+		{Time: at(40), Type: "ToolResult"},
+		{Time: at(45), Type: "StoryDone", MessageID: "msg_3", Input: 2500, Output: 20},
+		{Time: at(46), Type: "IterationStart", StoryID: "US-002"},
+		{Time: at(50), Type: "ToolStart", Tool: "WebFetch", Detail: "https://example.com", MessageID: "msg_4", Input: 500, Output: 10},
+		{Time: at(52), Type: "ToolResult"},
+	}
+
+	p := BuildProfile(records)
+	if p.Tokens != 1200+2030+2520+510 {
+		t.Errorf("Tokens = %d, want each message counted once", p.Tokens)
+	}
+	want := map[Category]CategoryUsage{
+		CategoryEdits:    {Calls: 1, Time: time.Second, Tokens: 1200},
+		CategoryShell:    {Calls: 1, Time: 30 * time.Second, Tokens: 2030},
+		CategoryWeb:      {Calls: 1, Time: 2 * time.Second, Tokens: 510},
+		CategoryThinking: {Time: p.Phases[PhaseThinking], Tokens: 2520},
+	}
+	for c, w := range want {
+		if got := p.Categories[c]; got == nil || *got != w {
+			t.Errorf("Categories[%s] = %+v, want %+v", c, got, w)
+		}
+	}
+	if u := p.Stories[1].Categories[CategoryWeb]; u == nil || u.Tokens != 510 || u.Calls != 1 {
+		t.Errorf("expected the web fetch under US-002, got %+v", u)
+	}
+	if ToolCategory("Read") != CategoryReads || ToolCategory("mcp/search") != CategoryOther {
+		t.Error("unexpected tool categories")
+	}
+}
+
+func TestEventLog(t *testing.T) {
+	dir := t.TempDir()
+	log := openEventLog(dir, RunBase{Ref: "v2.3.0", Commit: "0123abc"}, []string{"experiment", "model=opus"})
+	log.record(Event{Type: EventIterationStart, Iteration: 1, StoryID: "US-001"})
+	log.record(Event{Type: EventToolStart, Iteration: 1, Tool: "Bash", ToolInput: map[string]interface{}{"command": "go test ./...\necho done"}})
+	log.record(Event{Type: EventAssistantText, Iteration: 1, Text: "secret plans"})
+	log.close()
+
+	records, err := ReadEventLog(filepath.Join(dir, EventLogFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4 || records[0].Type != RunStartRecord {
+		t.Fatalf("expected a run start and 3 events, got %+v", records)
+	}
+	if records[0].Detail != "v2.3.0" || records[0].Base != "0123abc" {
+		t.Errorf("expected the run start to record the base, got %+v", records[0])
+	}
+	if len(records[0].Labels) != 2 || records[0].Labels[1] != "model=opus" {
+		t.Errorf("expected the run start to record the labels, got %+v", records[0])
+	}
+	if records[2].Tool != "Bash" || records[2].Detail != "go test ./..." {
+		t.Errorf("expected the tool's first command line as detail, got %+v", records[2])
+	}
+	if records[3].Detail != "" {
+		t.Errorf("expected assistant text not to be kept, got %q", records[3].Detail)
+	}
+
+	if _, err := ReadEventLog(filepath.Join(dir, "missing.jsonl")); !os.IsNotExist(err) {
+		t.Errorf("expected a not-exist error, got %v", err)
+	}
+}
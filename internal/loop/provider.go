@@ -2,15 +2,26 @@ package loop
 
 import (
 	"context"
+	"errors"
 	"os/exec"
 )
 
+// ErrReadOnlyUnsupported is returned by ReadOnlyCommand for CLIs that can't be
+// kept from changing the project.
+var ErrReadOnlyUnsupported = errors.New("can't be limited to reading the project")
+
 // Provider is the interface for an agent CLI (e.g. Claude, Codex).
 // Implementations live in internal/agent to avoid import cycles.
 type Provider interface {
 	Name() string
 	CLIPath() string
 	LoopCommand(ctx context.Context, prompt, workDir string) *exec.Cmd
+	// ReadOnlyCommand is LoopCommand for a pass that must not change the
+	// project: the agent may read, search, and inspect git history, and may
+	// only write the files in outputs. CLIs that can't be limited that far
+	// are limited as far as they allow, so callers still check the tree.
+	// CLIs that can't be limited at all return ErrReadOnlyUnsupported.
+	ReadOnlyCommand(ctx context.Context, prompt, workDir string, outputs ...string) (*exec.Cmd, error)
 	InteractiveCommand(workDir, prompt string) *exec.Cmd
 	// CleanOutput extracts JSON from the provider's output format (e.g., NDJSON).
 	// Returns the original output if no cleaning needed.
@@ -0,0 +1,41 @@
+package loop
+
+import (
+	"fmt"
+
+	"github.com/minicodemonkey/chief/internal/pushgate"
+)
+
+// SetPushPolicy limits how often the agent's pushes reach the remote and
+// refuses its force pushes. Pushes held back by the policy are made by the
+// loop once the policy allows them. nil lets the agent push freely.
+func (l *Loop) SetPushPolicy(policy *pushgate.Policy) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pushPolicy = policy
+}
+
+// flushPushes pushes the branch if the agent's push was held back and the
+// policy now allows it. final pushes regardless of the interval, once every
+// story is done.
+func (l *Loop) flushPushes(storyID string, storyDone, final bool) {
+	l.mu.Lock()
+	policy := l.pushPolicy
+	l.mu.Unlock()
+	if policy == nil {
+		return
+	}
+	p := *policy
+	if final {
+		p.Every = 0
+		storyDone = true
+	}
+	branch, err := p.Flush(l.effectiveWorkDir(), storyDone)
+	if err != nil {
+		l.emitWithStory(EventPush, storyID, "", fmt.Errorf("could not push the held commits: %w", err))
+		return
+	}
+	if branch != "" {
+		l.emitWithStory(EventPush, storyID, fmt.Sprintf("Pushed the held commits on %s", branch), nil)
+	}
+}
@@ -0,0 +1,60 @@
+package loop
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/schedule"
+	"github.com/minicodemonkey/chief/internal/timefmt"
+)
+
+// quietPollInterval is how often a loop waiting out quiet hours checks
+// whether it was stopped, paused, or told to ignore them.
+const quietPollInterval = time.Second
+
+// SetQuietHours sets the times during which the loop doesn't start new
+// iterations. An iteration already running when quiet hours begin finishes.
+// Setting nil lets the loop run at any time, including one that is waiting.
+func (l *Loop) SetQuietHours(q schedule.QuietHours) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.quietHours = q
+}
+
+// quietUntil reports whether it is quiet hours now and when they end.
+func (l *Loop) quietUntil() (time.Time, bool) {
+	l.mu.Lock()
+	q := l.quietHours
+	l.mu.Unlock()
+	return q.Until(time.Now())
+}
+
+// waitQuietHours emits EventQuietHours and waits until quiet hours end, or
+// the loop is stopped, paused, or its quiet hours are cleared.
+func (l *Loop) waitQuietHours(ctx context.Context, until time.Time) error {
+	l.mu.Lock()
+	iter := l.iteration
+	l.mu.Unlock()
+
+	text := fmt.Sprintf("Quiet hours: waiting until %s to start the next iteration", timefmt.Weekday(until))
+	l.logLine("[chief] " + text)
+	l.events <- Event{Type: EventQuietHours, Iteration: iter, Text: text}
+
+	ticker := time.NewTicker(quietPollInterval)
+	defer ticker.Stop()
+	for time.Now().Before(until) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		l.mu.Lock()
+		done := l.stopped || l.paused || l.quietHours == nil
+		l.mu.Unlock()
+		if done {
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package loop
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/schedule"
+)
+
+func TestLoop_WaitsOutQuietHours(t *testing.T) {
+	dir := t.TempDir()
+	prdPath := createTestPRD(t, dir, false)
+	agent := createLongRunningAgent(t, dir, "exit 0")
+
+	always, err := schedule.Parse([]string{"00:00-24:00 daily"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewLoopWithEmbeddedPrompt(prdPath, 5, &mockProvider{cliPath: agent})
+	l.SetQuietHours(always)
+	l.SetStopGracePeriod(0)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- l.Run(t.Context()) }()
+
+	var got []EventType
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case event, ok := <-l.Events():
+			if !ok {
+				if err := <-errCh; err != nil {
+					t.Fatalf("Run() error = %v", err)
+				}
+				want := []EventType{EventQuietHours, EventIterationStart, EventStopped}
+				for _, w := range want {
+					if !slices.Contains(got, w) {
+						t.Errorf("expected %v in events, got %v", w, got)
+					}
+				}
+				if got[0] != EventQuietHours {
+					t.Errorf("expected the loop to wait before the first iteration, got %v first", got[0])
+				}
+				return
+			}
+			got = append(got, event.Type)
+			switch {
+			case event.Type == EventQuietHours:
+				// Overriding quiet hours lets the waiting loop start
+				l.SetQuietHours(nil)
+			case event.Type == EventIterationStart && l.Iteration() == 1 && !l.IsStopped():
+				l.Stop()
+			}
+		case <-timeout:
+			l.Stop()
+			t.Fatal("loop did not finish")
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package loop
+
+import (
+	"fmt"
+
+	"github.com/minicodemonkey/chief/internal/git"
+)
+
+// Policies for a branch that is behind its remote when a run starts.
+const (
+	RemoteSyncOff    = "off"    // Don't fetch or compare (default)
+	RemoteSyncPause  = "pause"  // Pause the run until the branch is brought up to date
+	RemoteSyncRebase = "rebase" // Rebase the branch onto the remote and run
+)
+
+// ValidateRemoteSync reports an unknown loop.remoteSync value. "" is the default, off.
+func ValidateRemoteSync(policy string) error {
+	switch policy {
+	case "", RemoteSyncOff, RemoteSyncPause, RemoteSyncRebase:
+		return nil
+	}
+	return fmt.Errorf("unknown policy %q: expected %q, %q, or %q", policy, RemoteSyncOff, RemoteSyncPause, RemoteSyncRebase)
+}
+
+// SetRemoteSync sets what the run does when its branch is behind its remote
+// as it starts: RemoteSyncPause or RemoteSyncRebase.
+func (l *Loop) SetRemoteSync(policy string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.remoteSync = policy
+}
+
+// syncRemote fetches the branch the run works on and, when the remote has
+// commits it doesn't, rebases onto them or reports why the run can't go on,
+// so the agent doesn't build on a stale base. It returns false when the run
+// should pause. A remote that can't be reached is only warned about.
+func (l *Loop) syncRemote() bool {
+	l.mu.Lock()
+	policy := l.remoteSync
+	l.mu.Unlock()
+	if policy != RemoteSyncPause && policy != RemoteSyncRebase {
+		return true
+	}
+	workDir := l.effectiveWorkDir()
+	remote, branch := git.RemoteBranch(workDir)
+	if remote == "" {
+		return true
+	}
+
+	found, err := git.FetchBranch(workDir, remote, branch)
+	if err != nil {
+		l.emitRemoteSync(fmt.Errorf("%w; running on the local branch", err))
+		return true
+	}
+	if !found {
+		return true
+	}
+	ref := remote + "/" + branch
+	behind, err := git.CommitsBehind(workDir, ref)
+	if err != nil || behind == 0 {
+		return true
+	}
+
+	if policy == RemoteSyncRebase {
+		if err := git.Rebase(workDir, ref); err != nil {
+			l.emitRemoteSync(fmt.Errorf("%d commit(s) behind %s and %w; rebase by hand, then start again", behind, ref, err))
+			return false
+		}
+		text := fmt.Sprintf("Rebased onto %s, which had %d new commit(s)", ref, behind)
+		l.logLine("[chief] " + text)
+		l.events <- Event{Type: EventRemoteSync, Text: text}
+		return true
+	}
+	l.emitRemoteSync(fmt.Errorf("the branch is %d commit(s) behind %s; pull or rebase, then start again (or set loop.remoteSync to %q)", behind, ref, RemoteSyncRebase))
+	return false
+}
+
+// emitRemoteSync logs and emits a remote sync problem.
+func (l *Loop) emitRemoteSync(err error) {
+	l.logLine("[chief] " + err.Error())
+	l.events <- Event{Type: EventRemoteSync, Text: err.Error(), Err: err}
+}
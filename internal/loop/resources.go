@@ -0,0 +1,149 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// resourceDir holds one file per taken resource slot. It is shared by every
+// chief process on the machine, so PRDs run from different terminals or
+// projects count against the same limits.
+var resourceDir = filepath.Join(os.TempDir(), "chief-resources")
+
+// resourcePoll is how often a verification waiting for a resource checks for
+// a free slot.
+const resourcePoll = time.Second
+
+// resourceSlotGrace is how long a slot file may exist without a pid before
+// it is taken for abandoned; it covers the moment between creating and
+// writing it.
+const resourceSlotGrace = time.Minute
+
+// unsafeTagChars are replaced in resource tags to make file names.
+var unsafeTagChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// SetVerifyResources declares the resources, such as "gpu" or
+// "integration-db", that the verification command and browser tests use.
+// Before running them the loop takes a slot of each resource, waiting while
+// other runs on this machine hold them all. limits sets the number of slots
+// per resource; resources not listed have one.
+func (l *Loop) SetVerifyResources(tags []string, limits map[string]int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.verify.resources = tags
+	l.verify.limits = limits
+}
+
+// holdResources takes the verification resources for storyID and returns a
+// func that releases them. It fails only when ctx is cancelled while waiting.
+func (l *Loop) holdResources(ctx context.Context, storyID string) (func(), error) {
+	l.mu.Lock()
+	tags := l.verify.resources
+	limits := l.verify.limits
+	l.mu.Unlock()
+	if len(tags) == 0 {
+		return func() {}, nil
+	}
+	return acquireResources(ctx, tags, limits, func(tag string) {
+		l.emitVerify(storyID, fmt.Sprintf("Waiting for %s (in use by another run) before verifying %s", tag, storyID), nil)
+	})
+}
+
+// acquireResources takes a slot of each tag, calling waiting once for every
+// tag it has to wait for, and returns a func that releases them. Tags are
+// taken in sorted order so two runs needing the same tags can't deadlock.
+func acquireResources(ctx context.Context, tags []string, limits map[string]int, waiting func(tag string)) (func(), error) {
+	sorted := append([]string(nil), tags...)
+	sort.Strings(sorted)
+
+	var held []string
+	release := func() {
+		for _, path := range held {
+			os.Remove(path)
+		}
+	}
+	for i, tag := range sorted {
+		if i > 0 && tag == sorted[i-1] {
+			continue
+		}
+		limit := limits[tag]
+		if limit < 1 {
+			limit = 1
+		}
+		path, err := acquireSlot(ctx, tag, limit, waiting)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		held = append(held, path)
+	}
+	return release, nil
+}
+
+// acquireSlot waits for one of a tag's slots to be free and takes it.
+func acquireSlot(ctx context.Context, tag string, limit int, waiting func(tag string)) (string, error) {
+	if err := os.MkdirAll(resourceDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", resourceDir, err)
+	}
+	name := unsafeTagChars.ReplaceAllString(tag, "_")
+	notified := false
+	for {
+		for i := 0; i < limit; i++ {
+			path := filepath.Join(resourceDir, fmt.Sprintf("%s.%d.lock", name, i))
+			if takeSlot(path) {
+				return path, nil
+			}
+		}
+		if !notified && waiting != nil {
+			waiting(tag)
+			notified = true
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(resourcePoll):
+		}
+	}
+}
+
+// takeSlot creates a slot file holding this process's pid, replacing one
+// left behind by a process that is gone.
+func takeSlot(path string) bool {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if errors.Is(err, os.ErrExist) {
+		if !slotAbandoned(path) {
+			return false
+		}
+		os.Remove(path)
+		f, err = os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	}
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "%d\n", os.Getpid())
+	return true
+}
+
+// slotAbandoned reports whether the process that took a slot has exited.
+func slotAbandoned(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Is(err, os.ErrNotExist)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		// Just created and not written yet, unless it has been like this a while
+		info, statErr := os.Stat(path)
+		return statErr == nil && time.Since(info.ModTime()) > resourceSlotGrace
+	}
+	return !processAlive(pid)
+}
@@ -0,0 +1,65 @@
+package loop
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// useResourceDir points resource slots at a temporary directory for a test.
+func useResourceDir(t *testing.T) {
+	t.Helper()
+	old := resourceDir
+	resourceDir = t.TempDir()
+	t.Cleanup(func() { resourceDir = old })
+}
+
+func TestAcquireResources(t *testing.T) {
+	useResourceDir(t)
+
+	release, err := acquireResources(context.Background(), []string{"gpu", "integration-db"}, map[string]int{"integration-db": 2}, nil)
+	if err != nil {
+		t.Fatalf("acquireResources() error = %v", err)
+	}
+
+	// The second database slot is free, the only gpu slot is not
+	var waitedFor []string
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := acquireResources(ctx, []string{"integration-db", "gpu"}, map[string]int{"integration-db": 2}, func(tag string) {
+		waitedFor = append(waitedFor, tag)
+	}); err == nil {
+		t.Fatal("expected to time out waiting for the gpu")
+	}
+	if len(waitedFor) != 1 || waitedFor[0] != "gpu" {
+		t.Errorf("expected to wait for the gpu only, got %v", waitedFor)
+	}
+	if _, err := os.Stat(filepath.Join(resourceDir, "integration-db.1.lock")); !os.IsNotExist(err) {
+		t.Error("expected the database slot taken while waiting to be released")
+	}
+
+	release()
+	release2, err := acquireResources(context.Background(), []string{"gpu"}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected the gpu to be free after release: %v", err)
+	}
+	release2()
+}
+
+func TestAcquireResources_AbandonedSlot(t *testing.T) {
+	useResourceDir(t)
+	// A pid well above any real one stands in for a process that has exited
+	if err := os.WriteFile(filepath.Join(resourceDir, "gpu.0.lock"), []byte("999999999\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	release, err := acquireResources(ctx, []string{"gpu"}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected an abandoned slot to be taken over: %v", err)
+	}
+	release()
+}
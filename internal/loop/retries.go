@@ -0,0 +1,61 @@
+package loop
+
+import (
+	"fmt"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// DefaultStoryRetries is how many iterations may end without a story done
+// before it is marked as needing review and the run moves on.
+const DefaultStoryRetries = 3
+
+// SetStoryRetries sets how many failed iterations a story gets before it is
+// marked as needing review and skipped. Setting it to 0 never skips a story.
+func (l *Loop) SetStoryRetries(retries int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.storyRetries = retries
+}
+
+// StoryRetries returns how many failed iterations a story gets (0 = unlimited).
+func (l *Loop) StoryRetries() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.storyRetries
+}
+
+// recordStoryFailure counts an iteration that ended without storyID done.
+// Once the story has used up its retries it is marked as needing review in
+// the PRD, so this run and later ones skip it, and EventStoryBlocked is
+// emitted. It returns true when the story was given up on.
+func (l *Loop) recordStoryFailure(storyID string) bool {
+	if storyID == "" {
+		return false
+	}
+	l.mu.Lock()
+	if l.storyRetries <= 0 {
+		l.mu.Unlock()
+		return false
+	}
+	if l.storyFailures == nil {
+		l.storyFailures = make(map[string]int)
+	}
+	l.storyFailures[storyID]++
+	failures := l.storyFailures[storyID]
+	if failures < l.storyRetries {
+		l.mu.Unlock()
+		return false
+	}
+	delete(l.storyFailures, storyID)
+	l.feedback = ""
+	l.mu.Unlock()
+
+	if err := prd.SetStoryStatus(l.prdPath, storyID, "needs-review"); err != nil {
+		l.emitWithStory(EventStoryBlocked, storyID, "", fmt.Errorf("%s failed %d times but could not be marked for review: %w", storyID, failures, err))
+		return false
+	}
+	l.removeScratch()
+	l.emitWithStory(EventStoryBlocked, storyID, fmt.Sprintf("%s failed %d times; marked as needing review, moving on", storyID, failures), nil)
+	return true
+}
@@ -0,0 +1,66 @@
+package loop
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+func TestLoop_SkipsStoryAfterRetries(t *testing.T) {
+	dir := t.TempDir()
+	md := "# Test\n\n### US-001: Flaky story\n- [ ] It works\n\n### US-002: Needs US-001\n**Depends on:** US-001\n- [ ] It works\n\n### US-003: Independent\n- [ ] It works\n"
+	prdPath := filepath.Join(dir, "prd.md")
+	if err := os.WriteFile(prdPath, []byte(md), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The agent never finishes a story
+	script := createMockClaudeScript(t, dir, nil)
+	l := NewLoopWithEmbeddedPrompt(prdPath, 10, &mockProvider{cliPath: script})
+	l.SetStoryRetries(2)
+	done := make(chan error, 1)
+	go func() { done <- l.Run(t.Context()) }()
+	var blocked []string
+	var last Event
+	for event := range l.Events() {
+		if event.Type == EventStoryBlocked {
+			blocked = append(blocked, event.StoryID)
+		}
+		last = event
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if strings.Join(blocked, ",") != "US-001,US-003" {
+		t.Errorf("expected US-001 and US-003 to be given up on, got %v", blocked)
+	}
+	if l.Iteration() != 4 {
+		t.Errorf("expected 2 iterations per story, got %d", l.Iteration())
+	}
+	if last.Type != EventWaitingOnHuman || !strings.Contains(last.Text, "Needs review: US-001 (Flaky story), US-003 (Independent)") {
+		t.Errorf("expected the run to pause for review, got %v %q", last.Type, last.Text)
+	}
+
+	p, err := prd.LoadPRD(prdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if review := p.NeedingReview(); len(review) != 2 {
+		t.Errorf("expected 2 stories marked needs-review in prd.md, got %+v", review)
+	}
+}
+
+func TestLoop_StoryRetriesDisabled(t *testing.T) {
+	dir := t.TempDir()
+	l := NewLoop(createTestPRD(t, dir, false), "test", 5, testProvider)
+	l.SetStoryRetries(0)
+	for i := 0; i < 5; i++ {
+		if l.recordStoryFailure("US-001") {
+			t.Fatal("expected a story never to be skipped with retries disabled")
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package loop
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// failureClusterMin is how many stories must fail verification on the same
+// test before they are treated as one problem.
+const failureClusterMin = 2
+
+// storyNumberRegex extracts the number from a story ID like "US-007".
+var storyNumberRegex = regexp.MustCompile(`-(\d+)$`)
+
+// noteFailedTests records that storyID failed verification on tests in this run.
+func (l *Loop) noteFailedTests(storyID string, tests []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.testFailures == nil {
+		l.testFailures = make(map[string][]string)
+	}
+	for _, test := range tests {
+		if !containsFold(l.testFailures[test], storyID) {
+			l.testFailures[test] = append(l.testFailures[test], storyID)
+		}
+	}
+}
+
+// pauseFailureCluster checks whether storyID failed on a test that other
+// stories in this run failed on too. If so the stories share one root cause:
+// instead of each burning its retries, a story to fix that test is added to
+// the PRD and every unfinished story of the cluster is made to depend on it.
+// It returns true when storyID was paused this way.
+func (l *Loop) pauseFailureCluster(storyID string) bool {
+	if storyID == "" {
+		return false
+	}
+	l.mu.Lock()
+	if l.storyRetries <= 0 {
+		l.mu.Unlock()
+		return false
+	}
+	var test string
+	var cluster []string
+	for name, stories := range l.testFailures {
+		if len(stories) >= failureClusterMin && containsFold(stories, storyID) && (test == "" || name < test) {
+			test, cluster = name, stories
+		}
+	}
+	l.mu.Unlock()
+	if test == "" {
+		return false
+	}
+
+	p, err := prd.LoadPRD(l.prdPath)
+	if err != nil {
+		return false
+	}
+	var paused []string
+	for _, id := range cluster {
+		for _, s := range p.UserStories {
+			if strings.EqualFold(s.ID, id) && !s.Passes {
+				paused = append(paused, s.ID)
+			}
+		}
+	}
+	if len(paused) < failureClusterMin {
+		return false
+	}
+
+	fixID, err := addRootCauseStory(l.prdPath, test, paused)
+	if err != nil {
+		l.emitWithStory(EventStoryBlocked, storyID, "", fmt.Errorf("%s keep failing on %s but no root cause story could be added: %w", strings.Join(paused, ", "), test, err))
+		return false
+	}
+	for _, id := range paused {
+		if err := prd.AddStoryDependency(l.prdPath, id, fixID); err != nil {
+			l.emitWithStory(EventStoryBlocked, id, "", fmt.Errorf("could not make %s wait for %s: %w", id, fixID, err))
+			continue
+		}
+		for _, s := range p.UserStories {
+			if s.ID == id && s.InProgress {
+				_ = prd.SetStoryStatus(l.prdPath, id, "todo")
+			}
+		}
+	}
+
+	l.mu.Lock()
+	delete(l.testFailures, test)
+	for _, id := range paused {
+		delete(l.storyFailures, id)
+	}
+	l.feedback = ""
+	l.mu.Unlock()
+
+	l.emitWithStory(EventStoryBlocked, storyID, fmt.Sprintf("%s all fail on %s; paused behind %s to fix the root cause once", strings.Join(paused, ", "), test, fixID), nil)
+	return true
+}
+
+// rootCauseTitle returns the title of the story asking for test to be fixed
+// for a cluster of stories.
+func rootCauseTitle(test string) string {
+	return "Fix root cause of failing " + test
+}
+
+// addRootCauseStory appends a story to fix test to the PRD at prdPath, ahead
+// of every unfinished story, and returns its ID. An unfinished story for the
+// same test is reused.
+func addRootCauseStory(prdPath, test string, stories []string) (string, error) {
+	data, err := os.ReadFile(prdPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read PRD: %w", err)
+	}
+	content := string(data)
+	p, err := prd.ParseMarkdownPRDFromString(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse PRD: %w", err)
+	}
+
+	nextID, priority := 1, float64(0)
+	for _, s := range p.UserStories {
+		if s.Title == rootCauseTitle(test) && !s.Passes {
+			return s.ID, nil
+		}
+		if m := storyNumberRegex.FindStringSubmatch(s.ID); m != nil {
+			if n, _ := strconv.Atoi(m[1]); n >= nextID {
+				nextID = n + 1
+			}
+		}
+		if !s.Passes && (priority == 0 || s.Priority < priority) {
+			priority = s.Priority
+		}
+	}
+	if priority == 0 {
+		priority = 1
+	}
+	id := fmt.Sprintf("%s-%03d", p.ExtractIDPrefix(), nextID)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n### %s: %s\n", id, rootCauseTitle(test))
+	fmt.Fprintf(&b, "**Priority:** %g\n", priority/2)
+	fmt.Fprintf(&b, "**Description:** As a developer, I want %s to pass so the stories waiting on it can continue. It failed verification of %s, which points to one root cause rather than a problem in each story.\n\n",
+		test, strings.Join(stories, ", "))
+	b.WriteString("**Acceptance Criteria:**\n")
+	fmt.Fprintf(&b, "- [ ] The reason %s fails is found and fixed at its source\n", test)
+	fmt.Fprintf(&b, "- [ ] %s passes\n", test)
+	b.WriteString("- [ ] No test is skipped or weakened to make it pass\n")
+
+	content = strings.TrimRight(content, "\n") + "\n" + b.String()
+	if err := os.WriteFile(prdPath, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write PRD: %w", err)
+	}
+	return id, nil
+}
+
+// containsFold reports whether ids contains id, ignoring case.
+func containsFold(ids []string, id string) bool {
+	for _, other := range ids {
+		if strings.EqualFold(other, id) {
+			return true
+		}
+	}
+	return false
+}
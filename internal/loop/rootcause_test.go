@@ -0,0 +1,67 @@
+package loop
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+func TestLoop_PauseFailureCluster(t *testing.T) {
+	dir := t.TempDir()
+	md := "# Test\n\n### US-001: Login\n**Status:** needs-review\n- [ ] It works\n\n### US-002: Logout\n**Status:** in-progress\n- [ ] It works\n\n### US-003: Profile\n- [ ] It works\n"
+	prdPath := filepath.Join(dir, "prd.md")
+	if err := os.WriteFile(prdPath, []byte(md), 0644); err != nil {
+		t.Fatal(err)
+	}
+	l := NewLoop(prdPath, "test", 5, testProvider)
+
+	l.noteFailedTests("US-001", []string{"TestSession"})
+	if l.pauseFailureCluster("US-001") {
+		t.Fatal("expected one story failing on a test not to be a cluster")
+	}
+	l.noteFailedTests("US-002", []string{"TestSession", "TestLogout"})
+	if !l.pauseFailureCluster("US-002") {
+		t.Fatal("expected US-001 and US-002 failing on TestSession to be a cluster")
+	}
+
+	p, err := prd.LoadPRD(prdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fix := p.UserStories[3]
+	if fix.ID != "US-004" || fix.Title != "Fix root cause of failing TestSession" || fix.Priority != 0.5 {
+		t.Fatalf("unexpected root cause story: %+v", fix)
+	}
+	for _, s := range p.UserStories[:2] {
+		if strings.Join(s.DependsOn, ",") != "US-004" {
+			t.Errorf("expected %s to wait for US-004, got %v", s.ID, s.DependsOn)
+		}
+	}
+	if p.UserStories[1].InProgress || !p.UserStories[0].NeedsReview {
+		t.Error("expected US-002 back to todo and US-001 left for review")
+	}
+	if next := p.NextStory(); next == nil || next.ID != "US-004" {
+		t.Errorf("expected the root cause story next, got %+v", next)
+	}
+
+	// The same cluster failing again reuses the story
+	l.noteFailedTests("US-001", []string{"TestSession"})
+	l.noteFailedTests("US-002", []string{"TestSession"})
+	l.pauseFailureCluster("US-002")
+	if p, _ := prd.LoadPRD(prdPath); len(p.UserStories) != 4 {
+		t.Errorf("expected no second root cause story, got %d stories", len(p.UserStories))
+	}
+}
+
+func TestLoop_PauseFailureCluster_RetriesDisabled(t *testing.T) {
+	l := NewLoop(createTestPRD(t, t.TempDir(), false), "test", 5, testProvider)
+	l.SetStoryRetries(0)
+	l.noteFailedTests("US-001", []string{"TestX"})
+	l.noteFailedTests("US-002", []string{"TestX"})
+	if l.pauseFailureCluster("US-002") {
+		t.Error("expected no clustering with story retries off")
+	}
+}
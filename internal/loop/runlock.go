@@ -0,0 +1,158 @@
+package loop
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/timefmt"
+)
+
+// RunLockFile marks a PRD as running. It lives in the PRD directory so two
+// chief processes sharing the repository, on the same machine or through a
+// synced folder, don't run the same PRD at once.
+const RunLockFile = "run.lock"
+
+// runLockHeartbeat is how often a running PRD refreshes its lock.
+const runLockHeartbeat = 30 * time.Second
+
+// runLockStale is how long a lock from another machine is honored without a
+// heartbeat, in case the process holding it died without releasing it.
+const runLockStale = 2 * time.Minute
+
+// RunLock describes the process running a PRD.
+type RunLock struct {
+	Host      string    `json:"host"`
+	PID       int       `json:"pid"`
+	Started   time.Time `json:"started"`
+	Heartbeat time.Time `json:"heartbeat"`
+}
+
+// runLockHandle is a held lock, refreshed until it is released.
+type runLockHandle struct {
+	path string
+	lock RunLock
+	stop chan struct{}
+	once sync.Once
+}
+
+// ReadRunLock returns the lock in a PRD directory if another live process
+// holds it, or nil when the PRD is free to run.
+func ReadRunLock(prdDir string) *RunLock {
+	data, err := os.ReadFile(filepath.Join(prdDir, RunLockFile))
+	if err != nil {
+		return nil
+	}
+	var lock RunLock
+	if json.Unmarshal(data, &lock) != nil || !lock.held() {
+		return nil
+	}
+	return &lock
+}
+
+// String describes who holds the lock, for error messages.
+func (r *RunLock) String() string {
+	return fmt.Sprintf("%s (pid %d) since %s", r.Host, r.PID, timefmt.Stamp(r.Started))
+}
+
+// held reports whether the process that wrote the lock is still running it.
+func (r RunLock) held() bool {
+	host, _ := os.Hostname()
+	if r.Host == host {
+		return r.PID != os.Getpid() && processAlive(r.PID)
+	}
+	return time.Since(r.Heartbeat) < runLockStale
+}
+
+// acquireRunLock takes the run lock for a PRD directory and keeps it fresh
+// until release. It fails when another process already runs the PRD.
+func acquireRunLock(prdDir string) (*runLockHandle, error) {
+	path := filepath.Join(prdDir, RunLockFile)
+	host, _ := os.Hostname()
+	now := time.Now()
+	h := &runLockHandle{
+		path: path,
+		lock: RunLock{Host: host, PID: os.Getpid(), Started: now, Heartbeat: now},
+		stop: make(chan struct{}),
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := h.create()
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrExist) || attempt > 0 {
+			return nil, fmt.Errorf("failed to take run lock: %w", err)
+		}
+		if holder := ReadRunLock(prdDir); holder != nil {
+			return nil, fmt.Errorf("already running on %s; stop it there first or delete %s if that run is gone", holder, path)
+		}
+		// The lock is ours or abandoned; replace it
+		os.Remove(path)
+	}
+
+	go h.refresh()
+	return h, nil
+}
+
+// create writes the lock file, failing if one already exists.
+func (h *runLockHandle) create() error {
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(h.lock)
+}
+
+// refresh updates the heartbeat until the lock is released.
+func (h *runLockHandle) refresh() {
+	ticker := time.NewTicker(runLockHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+			h.lock.Heartbeat = time.Now()
+			if data, err := json.Marshal(h.lock); err == nil {
+				os.WriteFile(h.path, append(data, '\n'), 0644)
+			}
+		}
+	}
+}
+
+// release stops the heartbeat and removes the lock if it is still ours.
+func (h *runLockHandle) release() {
+	h.once.Do(func() {
+		close(h.stop)
+		data, err := os.ReadFile(h.path)
+		if err != nil {
+			return
+		}
+		var current RunLock
+		if json.Unmarshal(data, &current) == nil && current.Host == h.lock.Host && current.PID == h.lock.PID {
+			os.Remove(h.path)
+		}
+	})
+}
+
+// processAlive reports whether a process with the given pid is running on
+// this machine.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true // FindProcess only succeeds for running processes
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, os.ErrPermission)
+}
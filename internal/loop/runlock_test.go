@@ -0,0 +1,86 @@
+package loop
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeRunLock writes a lock file as another process would.
+func writeRunLock(t *testing.T, dir string, lock RunLock) {
+	t.Helper()
+	data, err := json.Marshal(lock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, RunLockFile), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAcquireRunLock(t *testing.T) {
+	dir := t.TempDir()
+	h, err := acquireRunLock(dir)
+	if err != nil {
+		t.Fatalf("acquireRunLock() error = %v", err)
+	}
+	if ReadRunLock(dir) != nil {
+		t.Error("expected our own lock not to count as held by another process")
+	}
+	h.release()
+	if _, err := os.Stat(filepath.Join(dir, RunLockFile)); !os.IsNotExist(err) {
+		t.Errorf("expected release to remove the lock, got %v", err)
+	}
+}
+
+func TestAcquireRunLock_OtherHolders(t *testing.T) {
+	host, _ := os.Hostname()
+	tests := []struct {
+		name    string
+		lock    RunLock
+		wantErr bool
+	}{
+		{"other machine, fresh", RunLock{Host: "laptop", PID: 1, Heartbeat: time.Now()}, true},
+		{"other machine, stale", RunLock{Host: "laptop", PID: 1, Heartbeat: time.Now().Add(-time.Hour)}, false},
+		{"this machine, live process", RunLock{Host: host, PID: os.Getppid(), Heartbeat: time.Now().Add(-time.Hour)}, true},
+		{"this machine, dead process", RunLock{Host: host, PID: 1 << 30, Heartbeat: time.Now()}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeRunLock(t, dir, tt.lock)
+			h, err := acquireRunLock(dir)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "already running on") {
+					t.Errorf("expected the lock to be refused, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected an abandoned lock to be replaced, got %v", err)
+			}
+			h.release()
+		})
+	}
+}
+
+func TestManagerStart_RefusesLockedPRD(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdPath := createTestPRDWithName(t, tmpDir, "test-prd")
+	writeRunLock(t, filepath.Dir(prdPath), RunLock{Host: "other-device", PID: 42, Started: time.Now(), Heartbeat: time.Now()})
+
+	m := NewManager(10, testProvider)
+	if err := m.Register("test-prd", prdPath); err != nil {
+		t.Fatal(err)
+	}
+	err := m.Start("test-prd")
+	if err == nil || !strings.Contains(err.Error(), "other-device (pid 42)") {
+		t.Fatalf("expected Start to refuse a PRD running elsewhere, got %v", err)
+	}
+	if state, _, _ := m.GetState("test-prd"); state == LoopStateRunning {
+		t.Error("expected the PRD not to be running")
+	}
+}
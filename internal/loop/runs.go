@@ -0,0 +1,114 @@
+package loop
+
+import (
+	"sort"
+	"time"
+)
+
+// RunSummary is the outcome of one run in an event log.
+type RunSummary struct {
+	Number   int // Position in the event log, from 1
+	Start    time.Time
+	Base     string   // Commit the run was pinned to, if any
+	Labels   []string // Labels the run was started with
+	Duration time.Duration
+	Tokens   int             // Tokens the agent reported using (0 = not reported)
+	Stories  []*StoryOutcome // In the order they were worked on
+	Files    []string        // Files the agent wrote, sorted
+}
+
+// StoryOutcome is how one story fared in a run.
+type StoryOutcome struct {
+	StoryID    string
+	Iterations int
+	Duration   time.Duration
+	Passed     bool // The agent finished the story and it wasn't attempted again
+}
+
+// Story returns the outcome of a story in the run, or nil if it wasn't worked on.
+func (r *RunSummary) Story(id string) *StoryOutcome {
+	for _, s := range r.Stories {
+		if s.StoryID == id {
+			return s
+		}
+	}
+	return nil
+}
+
+// Passed returns how many stories passed in the run.
+func (r *RunSummary) Passed() int {
+	n := 0
+	for _, s := range r.Stories {
+		if s.Passed {
+			n++
+		}
+	}
+	return n
+}
+
+// Iterations returns how many iterations the run took.
+func (r *RunSummary) Iterations() int {
+	n := 0
+	for _, s := range r.Stories {
+		n += s.Iterations
+	}
+	return n
+}
+
+// SplitRuns splits an event log into its runs. Records written before the
+// first run start belong to no run and are dropped.
+func SplitRuns(records []EventRecord) [][]EventRecord {
+	var runs [][]EventRecord
+	for i, rec := range records {
+		if rec.Type == RunStartRecord {
+			runs = append(runs, records[i:i+1])
+		} else if len(runs) > 0 {
+			runs[len(runs)-1] = append(runs[len(runs)-1], rec)
+		}
+	}
+	return runs
+}
+
+// SummarizeRun works out the outcome of a run from its records. A story
+// passed when the agent signalled it was done and the run moved on to
+// another story; one attempted again after that was rejected by
+// verification. The files are those the agent's write tools were called on.
+func SummarizeRun(number int, records []EventRecord) *RunSummary {
+	p := BuildProfile(records)
+	r := &RunSummary{Number: number, Start: p.Start, Duration: p.Total(), Tokens: p.Tokens}
+	if len(records) > 0 && records[0].Type == RunStartRecord {
+		r.Base = records[0].Base
+		r.Labels = records[0].Labels
+	}
+	for _, s := range p.Stories {
+		r.Stories = append(r.Stories, &StoryOutcome{StoryID: s.StoryID, Iterations: s.Iterations, Duration: s.Total()})
+	}
+
+	files := make(map[string]bool)
+	var current string
+	for _, rec := range records {
+		switch ParseEventType(rec.Type) {
+		case EventIterationStart:
+			if rec.StoryID == "" {
+				continue
+			}
+			current = rec.StoryID
+			if s := r.Story(current); s != nil {
+				s.Passed = false
+			}
+		case EventStoryDone:
+			if s := r.Story(current); s != nil {
+				s.Passed = true
+			}
+		case EventToolStart:
+			if writeTools[rec.Tool] && rec.Detail != "" {
+				files[rec.Detail] = true
+			}
+		}
+	}
+	for f := range files {
+		r.Files = append(r.Files, f)
+	}
+	sort.Strings(r.Files)
+	return r
+}
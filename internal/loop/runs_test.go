@@ -0,0 +1,54 @@
+package loop
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarizeRun(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time { return start.Add(time.Duration(seconds) * time.Second) }
+	records := []EventRecord{
+		{Time: at(0), Type: "IterationStart", StoryID: "US-000"}, // Before any run
+		{Time: at(10), Type: RunStartRecord, Base: "0123abc", Labels: []string{"model=opus"}},
+		{Time: at(11), Type: "IterationStart", StoryID: "US-001"},
+		{Time: at(20), Type: "ToolStart", Tool: "Write", Detail: "src/auth.go"},
+		{Time: at(25), Type: "ToolResult"},
+		{Time: at(30), Type: "StoryDone"},
+		{Time: at(31), Type: "IterationStart", StoryID: "US-002"},
+		{Time: at(40), Type: "ToolStart", Tool: "Read", Detail: "src/session.go"},
+		{Time: at(41), Type: "ToolResult"},
+		{Time: at(50), Type: "StoryDone"},
+		// Verification rejected US-002, so it was attempted again
+		{Time: at(60), Type: "IterationStart", StoryID: "US-002"},
+		{Time: at(70), Type: "ToolStart", Tool: "Edit", Detail: "src/session.go"},
+		{Time: at(71), Type: "ToolResult"},
+		{Time: at(100), Type: RunStartRecord},
+		{Time: at(101), Type: "IterationStart", StoryID: "US-002"},
+	}
+
+	runs := SplitRuns(records)
+	if len(runs) != 2 || len(runs[0]) != 12 || len(runs[1]) != 2 {
+		t.Fatalf("expected runs of 12 and 2 records, got %d runs", len(runs))
+	}
+
+	r := SummarizeRun(1, runs[0])
+	if r.Number != 1 || r.Base != "0123abc" || len(r.Labels) != 1 || !r.Start.Equal(at(10)) {
+		t.Errorf("unexpected run: %+v", r)
+	}
+	if r.Duration != 61*time.Second {
+		t.Errorf("Duration = %s, want 1m1s", r.Duration)
+	}
+	if s := r.Story("US-001"); s == nil || !s.Passed || s.Iterations != 1 {
+		t.Errorf("expected US-001 to pass in 1 iteration, got %+v", s)
+	}
+	if s := r.Story("US-002"); s == nil || s.Passed || s.Iterations != 2 {
+		t.Errorf("expected US-002 to fail after 2 iterations, got %+v", s)
+	}
+	if r.Passed() != 1 || r.Iterations() != 3 {
+		t.Errorf("Passed() = %d, Iterations() = %d, want 1 and 3", r.Passed(), r.Iterations())
+	}
+	if len(r.Files) != 2 || r.Files[0] != "src/auth.go" || r.Files[1] != "src/session.go" {
+		t.Errorf("Files = %v, want the written files", r.Files)
+	}
+}
@@ -0,0 +1,141 @@
+package loop
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RunStateFile holds the state of a PRD's unfinished run: the iteration it
+// got to, the story it was on, and how often each story failed. It lives in
+// the PRD directory, is written as each iteration starts and ends, and is
+// removed when the run finishes, so a run that was killed picks up where it
+// left off when it is started again.
+const RunStateFile = "run-state.json"
+
+// RunState is the contents of RunStateFile.
+type RunState struct {
+	Iteration           int            `json:"iteration"`
+	RunID               string         `json:"runId,omitempty"`               // Chief-Run trailer of the run's commits
+	StoryID             string         `json:"storyId,omitempty"`             // Story the last iteration worked on
+	StoryElapsedSeconds int64          `json:"storyElapsedSeconds,omitempty"` // Time spent on StoryID, against the story timeout
+	StoryBase           string         `json:"storyBase,omitempty"`           // HEAD when work on StoryID began
+	CoverageBase        *float64       `json:"coverageBase,omitempty"`        // Coverage when work on StoryID began
+	ElapsedSeconds      int64          `json:"elapsedSeconds"`                // Time the run has taken, over all its starts
+	Failures            map[string]int `json:"failures,omitempty"`            // Failed iterations by story
+	Updated             time.Time      `json:"updated"`
+}
+
+// Elapsed returns the time the run has taken.
+func (s *RunState) Elapsed() time.Duration {
+	return time.Duration(s.ElapsedSeconds) * time.Second
+}
+
+// ReadRunState returns the state of the unfinished run in a PRD directory,
+// or nil when there is none or it can't be read.
+func ReadRunState(prdDir string) *RunState {
+	data, err := os.ReadFile(filepath.Join(prdDir, RunStateFile))
+	if err != nil {
+		return nil
+	}
+	var state RunState
+	if json.Unmarshal(data, &state) != nil {
+		return nil
+	}
+	return &state
+}
+
+// resumeRunState restores the state of the PRD's unfinished run, if any, and
+// starts the clock for this one.
+func (l *Loop) resumeRunState() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.runStart = now
+	if l.runID == "" {
+		l.runID = filepath.Base(filepath.Dir(l.prdPath)) + "-" + now.UTC().Format("20060102-150405")
+	}
+	if !l.persistState {
+		return
+	}
+	state := ReadRunState(filepath.Dir(l.prdPath))
+	if state == nil {
+		return
+	}
+	l.runStart = now.Add(-state.Elapsed())
+	if state.RunID != "" {
+		l.runID = state.RunID
+	}
+	if state.Iteration > l.iteration {
+		l.iteration = state.Iteration
+	}
+	if len(state.Failures) > 0 {
+		l.storyFailures = state.Failures
+	}
+	if state.StoryID != "" && l.storyStartID == "" {
+		l.storyStartID = state.StoryID
+		l.storyStart = now.Add(-time.Duration(state.StoryElapsedSeconds) * time.Second)
+		l.storyBase = state.StoryBase
+		if state.CoverageBase != nil {
+			l.coverage.before = *state.CoverageBase
+			l.coverage.haveBase = true
+		}
+	}
+	l.logLine(fmt.Sprintf("[chief] Resuming the run at iteration %d", state.Iteration+1))
+}
+
+// saveRunState writes the run's state to the PRD directory. It is best
+// effort: a run goes on without it.
+func (l *Loop) saveRunState() {
+	l.mu.Lock()
+	if !l.persistState {
+		l.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	state := RunState{
+		Iteration:      l.iteration,
+		RunID:          l.runID,
+		StoryID:        l.storyStartID,
+		StoryBase:      l.storyBase,
+		ElapsedSeconds: int64(now.Sub(l.runStart) / time.Second),
+		Updated:        now,
+	}
+	if l.coverage.haveBase {
+		before := l.coverage.before
+		state.CoverageBase = &before
+	}
+	if !l.storyStart.IsZero() {
+		state.StoryElapsedSeconds = int64(now.Sub(l.storyStart) / time.Second)
+	}
+	if len(l.storyFailures) > 0 {
+		state.Failures = make(map[string]int, len(l.storyFailures))
+		for id, n := range l.storyFailures {
+			state.Failures[id] = n
+		}
+	}
+	l.mu.Unlock()
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return
+	}
+	path := filepath.Join(filepath.Dir(l.prdPath), RunStateFile)
+	if err := os.WriteFile(path+".tmp", append(data, '\n'), 0644); err == nil {
+		os.Rename(path+".tmp", path)
+	}
+}
+
+// clearRunState removes the run's state once it has finished, so the next
+// run starts afresh with a run ID of its own.
+func (l *Loop) clearRunState() {
+	l.mu.Lock()
+	persist := l.persistState
+	l.runID = ""
+	l.mu.Unlock()
+	if persist {
+		os.Remove(filepath.Join(filepath.Dir(l.prdPath), RunStateFile))
+	}
+}
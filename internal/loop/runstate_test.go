@@ -0,0 +1,130 @@
+package loop
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/git"
+)
+
+func TestRunState_SaveAndResume(t *testing.T) {
+	dir := t.TempDir()
+	prdPath := filepath.Join(dir, "prd.md")
+
+	l := NewLoopWithEmbeddedPrompt(prdPath, 10, testProvider)
+	l.resumeRunState()
+	l.iteration = 4
+	l.storyFailures = map[string]int{"US-002": 2}
+	l.storyStartID = "US-002"
+	l.storyStart = time.Now().Add(-90 * time.Second)
+	l.runStart = time.Now().Add(-10 * time.Minute)
+	l.saveRunState()
+
+	state := ReadRunState(dir)
+	if state == nil {
+		t.Fatal("ReadRunState() = nil after saveRunState")
+	}
+	if state.Iteration != 4 || state.StoryID != "US-002" || state.Failures["US-002"] != 2 {
+		t.Errorf("state = %+v", state)
+	}
+	if state.Elapsed() < 10*time.Minute {
+		t.Errorf("Elapsed() = %v, want at least 10m", state.Elapsed())
+	}
+
+	resumed := NewLoopWithEmbeddedPrompt(prdPath, 10, testProvider)
+	resumed.resumeRunState()
+	if resumed.iteration != 4 {
+		t.Errorf("iteration = %d, want 4", resumed.iteration)
+	}
+	if resumed.storyFailures["US-002"] != 2 {
+		t.Errorf("storyFailures = %v, want US-002: 2", resumed.storyFailures)
+	}
+	if resumed.storyStartID != "US-002" || time.Since(resumed.storyStart) < 90*time.Second {
+		t.Errorf("story clock = %s since %v, want US-002 for 90s", resumed.storyStartID, resumed.storyStart)
+	}
+	if time.Since(resumed.runStart) < 10*time.Minute {
+		t.Errorf("runStart = %v, want 10m ago", resumed.runStart)
+	}
+	if resumed.runID == "" || resumed.runID != l.runID {
+		t.Errorf("runID = %q, want the first run's %q", resumed.runID, l.runID)
+	}
+
+	resumed.clearRunState()
+	if _, err := os.Stat(filepath.Join(dir, RunStateFile)); !os.IsNotExist(err) {
+		t.Errorf("run state left after clearRunState: %v", err)
+	}
+}
+
+func TestRunState_LanesDontPersist(t *testing.T) {
+	dir := t.TempDir()
+	l := NewLoopWithWorkDir(filepath.Join(dir, "prd.md"), dir, "", 10, testProvider)
+	l.resumeRunState()
+	l.iteration = 3
+	l.saveRunState()
+	if ReadRunState(dir) != nil {
+		t.Error("a loop without persistState wrote run state")
+	}
+}
+
+func TestRunState_ResumedStorySquashesOnlyItsCommits(t *testing.T) {
+	l, project := newVerifyTestLoop(t)
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	commit := func(name string) {
+		t.Helper()
+		os.WriteFile(filepath.Join(project, name), []byte(name), 0644)
+		for _, args := range [][]string{{"add", name}, {"commit", "-m", "add " + name}} {
+			if out, err := exec.Command("git", append([]string{"-C", project}, args...)...).CombinedOutput(); err != nil {
+				t.Fatalf("git %v failed: %s", args, out)
+			}
+		}
+	}
+	for _, args := range [][]string{{"init"}, {"config", "user.name", "Test"}, {"config", "user.email", "test@test.com"}} {
+		if out, err := exec.Command("git", append([]string{"-C", project}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %s", args, out)
+		}
+	}
+	commit("a.txt")
+	commit("b.txt")
+
+	// The run starts US-001, commits once, and is killed
+	l.persistState = true
+	l.SetSquashTemplate(DefaultSquashTemplate)
+	l.resumeRunState()
+	l.storyStartID = "US-001"
+	l.storyStart = time.Now()
+	l.storyBase = git.HeadCommit(project)
+	commit("c.txt")
+	l.saveRunState()
+
+	resumed := NewLoopWithWorkDir(l.prdPath, project, "test", 5, testProvider)
+	resumed.persistState = true
+	resumed.SetSquashTemplate(DefaultSquashTemplate)
+	resumed.resumeRunState()
+	if resumed.storyBase != l.storyBase {
+		t.Fatalf("storyBase = %q after resuming, want %q", resumed.storyBase, l.storyBase)
+	}
+	commit("d.txt")
+	resumed.squashStory("US-001")
+
+	commits, err := git.CommitsSince(project, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 3 || commits[0].Subject != "add a.txt" || commits[1].Subject != "add b.txt" {
+		t.Errorf("history after squashing = %+v, want a.txt, b.txt, and the squashed story", commits)
+	}
+}
+
+func TestSquashStory_RefusesUnknownBase(t *testing.T) {
+	l, _ := newVerifyTestLoop(t)
+	l.SetSquashTemplate(DefaultSquashTemplate)
+	l.squashStory("US-001")
+	events := drainEvents(l)
+	if len(events) != 1 || events[0].Type != EventStorySquashed || events[0].Err == nil {
+		t.Errorf("events = %+v, want a failed squash", events)
+	}
+}
@@ -0,0 +1,60 @@
+package loop
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ScratchEnv is the environment variable the agent finds its story's scratch
+// directory in.
+const ScratchEnv = "CHIEF_SCRATCH"
+
+// scratchPrefix starts the name of every scratch directory in the system's
+// temporary directory.
+const scratchPrefix = "chief-scratch-"
+
+// scratchFor returns the scratch directory of storyID, creating it on first
+// use. Attempts at the same story share it; moving on to another story
+// removes the previous one. It returns "" when no directory can be created;
+// the agent then works without one.
+func (l *Loop) scratchFor(storyID string) string {
+	if storyID == "" {
+		return ""
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.scratchDir != "" && l.scratchStory == storyID {
+		if _, err := os.Stat(l.scratchDir); err == nil {
+			return l.scratchDir
+		}
+	}
+	if l.scratchDir != "" {
+		os.RemoveAll(l.scratchDir)
+	}
+	name := strings.Map(func(r rune) rune {
+		if r == filepath.Separator || r == '/' {
+			return '-'
+		}
+		return r
+	}, storyID)
+	dir, err := os.MkdirTemp("", scratchPrefix+name+"-")
+	if err != nil {
+		l.scratchDir, l.scratchStory = "", ""
+		return ""
+	}
+	l.scratchDir, l.scratchStory = dir, storyID
+	return dir
+}
+
+// removeScratch deletes the scratch directory, if any, along with everything
+// the agent left in it.
+func (l *Loop) removeScratch() {
+	l.mu.Lock()
+	dir := l.scratchDir
+	l.scratchDir, l.scratchStory = "", ""
+	l.mu.Unlock()
+	if dir != "" {
+		os.RemoveAll(dir)
+	}
+}
@@ -0,0 +1,73 @@
+package loop
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScratchFor(t *testing.T) {
+	l := NewLoop(filepath.Join(t.TempDir(), "prd.md"), "prompt", 1, testProvider)
+	if dir := l.scratchFor(""); dir != "" {
+		t.Errorf("expected no scratch directory without a story, got %q", dir)
+	}
+
+	first := l.scratchFor("US-001")
+	if info, err := os.Stat(first); err != nil || !info.IsDir() {
+		t.Fatalf("expected a scratch directory, got %q (%v)", first, err)
+	}
+	if !strings.Contains(filepath.Base(first), "US-001") {
+		t.Errorf("expected the story in the directory's name, got %q", first)
+	}
+	if again := l.scratchFor("US-001"); again != first {
+		t.Errorf("expected attempts at the same story to share %q, got %q", first, again)
+	}
+
+	second := l.scratchFor("US-002")
+	if second == first {
+		t.Fatal("expected a new directory for another story")
+	}
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Error("expected the previous story's scratch directory to be removed")
+	}
+	l.removeScratch()
+	if _, err := os.Stat(second); !os.IsNotExist(err) {
+		t.Error("expected removeScratch to delete the directory")
+	}
+}
+
+func TestRunIteration_Scratch(t *testing.T) {
+	dir := t.TempDir()
+	prdPath := createTestPRD(t, dir, false)
+	seen := filepath.Join(dir, "seen")
+	script := filepath.Join(dir, "mock-claude")
+	os.WriteFile(script, []byte("#!/bin/bash\necho \"$CHIEF_SCRATCH\" > "+seen+"\ntouch \"$CHIEF_SCRATCH/debug.log\"\n"), 0755)
+
+	l := NewLoop(prdPath, "prompt", 1, &mockProvider{cliPath: script})
+	l.currentStoryID = "US-001"
+	if err := l.runIteration(context.Background()); err != nil {
+		t.Fatalf("runIteration() error = %v", err)
+	}
+
+	data, err := os.ReadFile(seen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scratch := strings.TrimSpace(string(data))
+	if scratch == "" || scratch != l.scratchDir {
+		t.Fatalf("expected %s to be the scratch directory %q, got %q", ScratchEnv, l.scratchDir, scratch)
+	}
+	if _, err := os.Stat(filepath.Join(scratch, "debug.log")); err != nil {
+		t.Errorf("expected the agent's file in the scratch directory: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "debug.log")); !os.IsNotExist(err) {
+		t.Error("expected nothing written to the repository")
+	}
+
+	l.removeScratch()
+	if _, err := os.Stat(scratch); !os.IsNotExist(err) {
+		t.Error("expected the scratch directory to be removed")
+	}
+}
@@ -0,0 +1,150 @@
+package loop
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/minicodemonkey/chief/internal/forge"
+	"github.com/minicodemonkey/chief/internal/git"
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// Stacked PR operations (replaceable in tests).
+var (
+	pushStackBranch   = git.PushStackBranch
+	branchExists      = git.BranchExists
+	defaultBranch     = git.GetDefaultBranch
+	createPullRequest = forge.CreatePullRequest
+	findPullRequest   = forge.FindPullRequest
+)
+
+// SetStackedPRs enables opening a pull request for each finished story. Each
+// PR is based on the previous story's branch, so reviewers see one story at a
+// time, and the run pauses while any of them has changes requested.
+func (l *Loop) SetStackedPRs(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stackedPRs = enabled
+}
+
+// prdName returns the name of the PRD directory the loop works on.
+func (l *Loop) prdName() string {
+	return filepath.Base(filepath.Dir(l.prdPath))
+}
+
+// openStackedPR pushes a branch for the finished story and opens a PR for it
+// on top of the previous story's branch.
+func (l *Loop) openStackedPR(storyID string) {
+	p, err := prd.LoadPRD(l.prdPath)
+	if err != nil {
+		l.emitWithStory(EventStackedPR, storyID, "", fmt.Errorf("could not open a PR for %s: %w", storyID, err))
+		return
+	}
+	var story prd.UserStory
+	for _, s := range p.UserStories {
+		if s.ID == storyID {
+			story = s
+			break
+		}
+	}
+
+	workDir := l.effectiveWorkDir()
+	name := l.prdName()
+	base, err := l.stackBase(p, storyID)
+	if err != nil {
+		l.emitWithStory(EventStackedPR, storyID, "", fmt.Errorf("could not open a PR for %s: %w", storyID, err))
+		return
+	}
+	branch := git.StackBranch(name, storyID)
+	if err := pushStackBranch(workDir, branch); err != nil {
+		l.emitWithStory(EventStackedPR, storyID, "", fmt.Errorf("could not open a PR for %s: %w", storyID, err))
+		return
+	}
+
+	// A story redone after review already has a PR; pushing updated it.
+	if pr, err := findPullRequest(workDir, branch); err == nil && pr != nil && pr.IsOpen() {
+		l.emitWithStory(EventStackedPR, storyID, fmt.Sprintf("Updated PR for %s: %s", storyID, pr.URL), nil)
+		return
+	}
+
+	title := fmt.Sprintf("feat(%s): %s - %s", name, storyID, story.Title)
+	url, err := createPullRequest(workDir, branch, base, title, stackedPRBody(name, base, story))
+	if err != nil {
+		l.emitWithStory(EventStackedPR, storyID, "", fmt.Errorf("could not open a PR for %s: %w", storyID, err))
+		return
+	}
+	l.emitWithStory(EventStackedPR, storyID, fmt.Sprintf("Opened PR for %s on %s: %s", storyID, base, url), nil)
+}
+
+// stackBase returns the branch the PR for storyID is based on: the branch of
+// the closest earlier finished story that has one, or the default branch for
+// the first story in the stack.
+func (l *Loop) stackBase(p *prd.PRD, storyID string) (string, error) {
+	workDir := l.effectiveWorkDir()
+	name := l.prdName()
+	base := ""
+	for _, s := range p.UserStories {
+		if s.ID == storyID {
+			break
+		}
+		if !s.Passes {
+			continue
+		}
+		branch := git.StackBranch(name, s.ID)
+		if ok, _ := branchExists(workDir, branch); ok {
+			base = branch
+		}
+	}
+	if base != "" {
+		return base, nil
+	}
+	return defaultBranch(workDir)
+}
+
+// stackBlocked returns a description of the first stacked PR with changes
+// requested, or "" when the run may continue. PRs that can't be looked up
+// don't block the run.
+func (l *Loop) stackBlocked() string {
+	p, err := prd.LoadPRD(l.prdPath)
+	if err != nil {
+		return ""
+	}
+	workDir := l.effectiveWorkDir()
+	name := l.prdName()
+	for _, s := range p.UserStories {
+		if !s.Passes {
+			continue
+		}
+		branch := git.StackBranch(name, s.ID)
+		if ok, _ := branchExists(workDir, branch); !ok {
+			continue
+		}
+		pr, err := findPullRequest(workDir, branch)
+		if err != nil || pr == nil || !pr.IsOpen() {
+			continue
+		}
+		if pr.ReviewDecision == forge.ReviewChangesRequested {
+			return fmt.Sprintf("Paused: changes requested on the PR for %s (%s)", s.ID, pr.URL)
+		}
+	}
+	return ""
+}
+
+// stackedPRBody describes a single story for its stacked PR.
+func stackedPRBody(prdName, base string, story prd.UserStory) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Story **%s** of the `%s` PRD, stacked on `%s`.\n", story.ID, prdName, base)
+	if story.Description != "" {
+		b.WriteString("\n")
+		b.WriteString(story.Description)
+		b.WriteString("\n")
+	}
+	if len(story.AcceptanceCriteria) > 0 {
+		b.WriteString("\n## Acceptance Criteria\n\n")
+		for _, c := range story.AcceptanceCriteria {
+			fmt.Fprintf(&b, "- [x] %s\n", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,161 @@
+package loop
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/forge"
+)
+
+// createStackPRD writes an "auth" PRD whose first two stories are done.
+func createStackPRD(t *testing.T) string {
+	t.Helper()
+	md := `# Auth
+
+Login support
+
+### US-001: Sessions
+**Status:** done
+- [x] Sessions persist
+
+### US-002: Login form
+**Status:** done
+Users sign in with email and password.
+- [x] Form validates input
+
+### US-003: Logout
+- [ ] Session is cleared
+`
+	dir := filepath.Join(t.TempDir(), "auth")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	prdPath := filepath.Join(dir, "prd.md")
+	if err := os.WriteFile(prdPath, []byte(md), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return prdPath
+}
+
+// fakeStack replaces the git and forge calls used for stacked PRs. Branches
+// listed in existing exist locally; prs maps branch names to their PRs.
+func fakeStack(t *testing.T, existing []string, prs map[string]*forge.PullRequest) (pushed *[]string, created *[][]string) {
+	t.Helper()
+	origPush, origExists, origDefault := pushStackBranch, branchExists, defaultBranch
+	origCreate, origFind := createPullRequest, findPullRequest
+	t.Cleanup(func() {
+		pushStackBranch, branchExists, defaultBranch = origPush, origExists, origDefault
+		createPullRequest, findPullRequest = origCreate, origFind
+	})
+
+	pushed = &[]string{}
+	created = &[][]string{}
+	pushStackBranch = func(dir, branch string) error {
+		*pushed = append(*pushed, branch)
+		return nil
+	}
+	branchExists = func(dir, branch string) (bool, error) {
+		for _, b := range existing {
+			if b == branch {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	defaultBranch = func(string) (string, error) { return "main", nil }
+	createPullRequest = func(dir, head, base, title, body string) (string, error) {
+		*created = append(*created, []string{head, base, title, body})
+		return "https://github.com/o/r/pull/9", nil
+	}
+	findPullRequest = func(dir, head string) (*forge.PullRequest, error) {
+		return prs[head], nil
+	}
+	return pushed, created
+}
+
+func TestOpenStackedPR(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []string
+		wantBase string
+	}{
+		{name: "stacks on the previous story", existing: []string{"chief/auth-us-001"}, wantBase: "chief/auth-us-001"},
+		{name: "first story uses the default branch", wantBase: "main"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pushed, created := fakeStack(t, tt.existing, nil)
+			l := NewLoop(createStackPRD(t), "", 1, testProvider)
+			l.openStackedPR("US-002")
+
+			if len(*pushed) != 1 || (*pushed)[0] != "chief/auth-us-002" {
+				t.Fatalf("pushed = %v, want [chief/auth-us-002]", *pushed)
+			}
+			if len(*created) != 1 {
+				t.Fatalf("expected one PR, got %d", len(*created))
+			}
+			pr := (*created)[0]
+			if pr[0] != "chief/auth-us-002" || pr[1] != tt.wantBase {
+				t.Errorf("PR head/base = %s/%s, want chief/auth-us-002/%s", pr[0], pr[1], tt.wantBase)
+			}
+			if pr[2] != "feat(auth): US-002 - Login form" {
+				t.Errorf("title = %q", pr[2])
+			}
+			if !strings.Contains(pr[3], "- [x] Form validates input") {
+				t.Errorf("body missing acceptance criteria:\n%s", pr[3])
+			}
+
+			event := <-l.events
+			if event.Type != EventStackedPR || event.Err != nil || !strings.Contains(event.Text, "pull/9") {
+				t.Errorf("unexpected event %+v", event)
+			}
+		})
+	}
+}
+
+func TestOpenStackedPR_UpdatesExisting(t *testing.T) {
+	_, created := fakeStack(t, nil, map[string]*forge.PullRequest{
+		"chief/auth-us-002": {Number: 4, URL: "https://github.com/o/r/pull/4", State: "OPEN"},
+	})
+	l := NewLoop(createStackPRD(t), "", 1, testProvider)
+	l.openStackedPR("US-002")
+
+	if len(*created) != 0 {
+		t.Errorf("expected no new PR for a story that already has one, got %v", *created)
+	}
+	if event := <-l.events; !strings.Contains(event.Text, "Updated PR") {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestStackBlocked(t *testing.T) {
+	tests := []struct {
+		name     string
+		decision string
+		state    string
+		blocked  bool
+	}{
+		{name: "changes requested", decision: forge.ReviewChangesRequested, state: "OPEN", blocked: true},
+		{name: "approved", decision: forge.ReviewApproved, state: "OPEN"},
+		{name: "merged", decision: forge.ReviewChangesRequested, state: "MERGED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeStack(t, []string{"chief/auth-us-001"}, map[string]*forge.PullRequest{
+				"chief/auth-us-001": {Number: 3, URL: "https://github.com/o/r/pull/3", State: tt.state, ReviewDecision: tt.decision},
+			})
+			l := NewLoop(createStackPRD(t), "", 1, testProvider)
+			got := l.stackBlocked()
+			if (got != "") != tt.blocked {
+				t.Errorf("stackBlocked() = %q, want blocked=%v", got, tt.blocked)
+			}
+			if tt.blocked && !strings.Contains(got, "US-001") {
+				t.Errorf("expected the blocking story in %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package loop
+
+import (
+	"fmt"
+
+	"github.com/minicodemonkey/chief/internal/forge"
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// setCommitStatus posts a commit status (replaceable in tests).
+var setCommitStatus = forge.SetCommitStatus
+
+// pushedHead returns the commit a branch's upstream points to (replaceable in tests).
+var pushedHead = forge.PushedHead
+
+// statusReporter posts a PRD's progress as a commit status on the pushed head
+// of its branch, so reviewers can follow a run from the pull request. Events
+// are queued and posted in order on a separate goroutine so slow API calls
+// never hold up the loop.
+type statusReporter struct {
+	prdName string
+	prdPath string
+	workDir string
+	queue   chan Event
+	done    chan struct{}
+}
+
+// newStatusReporter starts a reporter for one run of a PRD.
+func newStatusReporter(prdName, prdPath, workDir string) *statusReporter {
+	r := &statusReporter{
+		prdName: prdName,
+		prdPath: prdPath,
+		workDir: workDir,
+		queue:   make(chan Event, 16),
+		done:    make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// report queues an event. Events that don't change the status are ignored,
+// and events are dropped rather than block when the queue is full.
+func (r *statusReporter) report(event Event) {
+	switch event.Type {
+	case EventIterationStart, EventComplete, EventMaxIterationsReached, EventError, EventStopped:
+	default:
+		return
+	}
+	select {
+	case r.queue <- event:
+	default:
+	}
+}
+
+// close stops the reporter after the queued events are posted.
+func (r *statusReporter) close() {
+	close(r.queue)
+	<-r.done
+}
+
+func (r *statusReporter) run() {
+	defer close(r.done)
+	for event := range r.queue {
+		sha := pushedHead(r.workDir)
+		if sha == "" {
+			continue // Nothing on the forge to attach a status to yet
+		}
+		state, description := r.describe(event)
+		err := setCommitStatus(r.workDir, forge.Status{
+			SHA:         sha,
+			State:       state,
+			Context:     "chief/" + r.prdName,
+			Description: description,
+		})
+		if err != nil {
+			// Most likely gh is missing or unauthenticated; don't retry every iteration
+			for range r.queue {
+			}
+			return
+		}
+	}
+}
+
+// describe returns the status state and description for an event.
+func (r *statusReporter) describe(event Event) (string, string) {
+	progress := "stories"
+	if p, err := prd.LoadPRD(r.prdPath); err == nil {
+		done := 0
+		for _, s := range p.UserStories {
+			if s.Passes {
+				done++
+			}
+		}
+		progress = fmt.Sprintf("%d/%d stories", done, len(p.UserStories))
+	}
+
+	switch event.Type {
+	case EventComplete:
+		return forge.StateSuccess, fmt.Sprintf("chief: %s, complete", progress)
+	case EventMaxIterationsReached:
+		return forge.StateFailure, fmt.Sprintf("chief: %s, stopped at max iterations (%d)", progress, event.Iteration)
+	case EventError:
+		return forge.StateError, fmt.Sprintf("chief: %s, run failed", progress)
+	case EventStopped:
+		return forge.StateError, fmt.Sprintf("chief: %s, stopped", progress)
+	default:
+		return forge.StatePending, fmt.Sprintf("chief: %s, iteration %d", progress, event.Iteration)
+	}
+}
@@ -0,0 +1,60 @@
+package loop
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/forge"
+)
+
+func TestStatusReporter(t *testing.T) {
+	dir := t.TempDir()
+	prdPath := createTestPRD(t, dir, false)
+
+	var mu sync.Mutex
+	var posted []forge.Status
+	origSet, origHead := setCommitStatus, pushedHead
+	t.Cleanup(func() { setCommitStatus, pushedHead = origSet, origHead })
+	setCommitStatus = func(dir string, s forge.Status) error {
+		mu.Lock()
+		defer mu.Unlock()
+		posted = append(posted, s)
+		return nil
+	}
+	pushedHead = func(string) string { return "abc123" }
+
+	r := newStatusReporter("auth", prdPath, dir)
+	r.report(Event{Type: EventIterationStart, Iteration: 3})
+	r.report(Event{Type: EventToolStart}) // ignored
+	r.report(Event{Type: EventComplete, Iteration: 4})
+	r.close()
+
+	if len(posted) != 2 {
+		t.Fatalf("expected 2 statuses, got %d: %+v", len(posted), posted)
+	}
+	if posted[0].State != forge.StatePending || posted[0].Context != "chief/auth" || posted[0].SHA != "abc123" {
+		t.Errorf("unexpected first status %+v", posted[0])
+	}
+	if !strings.Contains(posted[0].Description, "stories, iteration 3") {
+		t.Errorf("expected progress in description, got %q", posted[0].Description)
+	}
+	if posted[1].State != forge.StateSuccess {
+		t.Errorf("expected success on completion, got %+v", posted[1])
+	}
+}
+
+func TestStatusReporter_NotPushed(t *testing.T) {
+	origSet, origHead := setCommitStatus, pushedHead
+	t.Cleanup(func() { setCommitStatus, pushedHead = origSet, origHead })
+	called := false
+	setCommitStatus = func(string, forge.Status) error { called = true; return nil }
+	pushedHead = func(string) string { return "" }
+
+	r := newStatusReporter("auth", "", t.TempDir())
+	r.report(Event{Type: EventIterationStart, Iteration: 1})
+	r.close()
+	if called {
+		t.Error("expected no status for a branch that hasn't been pushed")
+	}
+}
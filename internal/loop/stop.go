@@ -0,0 +1,61 @@
+package loop
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// DefaultStopGracePeriod is how long a stopped agent may take to exit after
+// being interrupted before it is killed.
+const DefaultStopGracePeriod = 10 * time.Second
+
+// SetStopGracePeriod sets how long the agent gets to wrap up when the loop is
+// stopped. Setting it to 0 kills the agent immediately.
+func (l *Loop) SetStopGracePeriod(grace time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stopGrace = grace
+}
+
+// killLocked kills the agent and cancels the running Run's context. l.mu must be held.
+func (l *Loop) killLocked() {
+	if l.agentCmd != nil && l.agentCmd.Process != nil {
+		l.agentCmd.Process.Kill()
+	}
+	if l.cancelRun != nil {
+		l.cancelRun()
+	}
+}
+
+// finishStop leaves the story that was being worked on in progress, flushes
+// the log, and emits EventStopped with a summary of the run.
+func (l *Loop) finishStop(storyID string) {
+	l.mu.Lock()
+	iter := l.iteration
+	l.mu.Unlock()
+
+	summary := fmt.Sprintf("Stopped after %d iteration(s)", iter)
+	inProgress := false
+	if p, err := prd.LoadPRD(l.prdPath); err == nil {
+		done := 0
+		for _, s := range p.UserStories {
+			if s.Passes {
+				done++
+			} else if s.ID == storyID {
+				inProgress = prd.SetStoryStatus(l.prdPath, storyID, "in-progress") == nil
+			}
+		}
+		summary += fmt.Sprintf(", %d/%d stories done", done, len(p.UserStories))
+	}
+	if inProgress {
+		summary += fmt.Sprintf(", %s left in progress", storyID)
+	}
+
+	l.logLine("[chief] " + summary)
+	if l.logFile != nil {
+		l.logFile.Sync()
+	}
+	l.events <- Event{Type: EventStopped, Iteration: iter, StoryID: storyID, Text: summary}
+}
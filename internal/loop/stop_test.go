@@ -0,0 +1,111 @@
+package loop
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// createLongRunningAgent writes an agent script that keeps running until it
+// is killed. onInterrupt is the trap action for SIGINT ("" ignores it).
+func createLongRunningAgent(t *testing.T, dir, onInterrupt string) string {
+	t.Helper()
+	script := "#!/bin/bash\n" +
+		"trap '" + onInterrupt + "' INT\n" +
+		`echo '{"type":"system","subtype":"init"}'` + "\n" +
+		"while true; do sleep 0.1; done\n"
+	path := filepath.Join(dir, "agent")
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// runUntilStopped starts the loop, stops it once the first iteration has
+// started, and returns the events it emitted and how long stopping took.
+func runUntilStopped(t *testing.T, l *Loop) ([]Event, time.Duration) {
+	t.Helper()
+	errCh := make(chan error, 1)
+	go func() { errCh <- l.Run(t.Context()) }()
+
+	var events []Event
+	var stoppedAt time.Time
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case event, ok := <-l.Events():
+			if !ok {
+				if err := <-errCh; err != nil {
+					t.Fatalf("Run() error = %v", err)
+				}
+				return events, time.Since(stoppedAt)
+			}
+			events = append(events, event)
+			if event.Type == EventIterationStart && stoppedAt.IsZero() {
+				time.Sleep(200 * time.Millisecond) // let the agent start
+				stoppedAt = time.Now()
+				l.Stop()
+			}
+		case <-timeout:
+			l.Stop() // force
+			t.Fatal("loop did not stop")
+		}
+	}
+}
+
+func TestLoop_StopLetsAgentWrapUp(t *testing.T) {
+	dir := t.TempDir()
+	prdPath := createTestPRD(t, dir, false)
+	agent := createLongRunningAgent(t, dir, "exit 0")
+
+	l := NewLoopWithEmbeddedPrompt(prdPath, 5, &mockProvider{cliPath: agent})
+	events, took := runUntilStopped(t, l)
+	if took >= DefaultStopGracePeriod {
+		t.Errorf("expected the interrupted agent to exit before the grace period, took %s", took)
+	}
+
+	last := events[len(events)-1]
+	if last.Type != EventStopped {
+		t.Fatalf("expected EventStopped last, got %v", last.Type)
+	}
+	if !strings.Contains(last.Text, "0/1 stories done") || !strings.Contains(last.Text, "US-001 left in progress") {
+		t.Errorf("unexpected summary %q", last.Text)
+	}
+	for _, e := range events {
+		if e.Type == EventError {
+			t.Errorf("unexpected error event: %v", e.Err)
+		}
+	}
+
+	p, err := prd.LoadPRD(prdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.UserStories[0].InProgress {
+		t.Error("expected US-001 to stay in progress")
+	}
+	log, _ := os.ReadFile(filepath.Join(dir, "claude.log"))
+	if !strings.Contains(string(log), "[chief] Stopped after 1 iteration(s)") {
+		t.Errorf("expected the stop summary in the log, got:\n%s", log)
+	}
+}
+
+func TestLoop_StopKillsAgentAfterGracePeriod(t *testing.T) {
+	dir := t.TempDir()
+	prdPath := createTestPRD(t, dir, false)
+	agent := createLongRunningAgent(t, dir, "")
+
+	l := NewLoopWithEmbeddedPrompt(prdPath, 5, &mockProvider{cliPath: agent})
+	l.SetStopGracePeriod(300 * time.Millisecond)
+	events, took := runUntilStopped(t, l)
+	if took < 300*time.Millisecond {
+		t.Errorf("expected the agent to get the grace period, stopped after %s", took)
+	}
+	if last := events[len(events)-1]; last.Type != EventStopped {
+		t.Errorf("expected EventStopped last, got %v", last.Type)
+	}
+}
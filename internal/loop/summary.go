@@ -0,0 +1,151 @@
+package loop
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/minicodemonkey/chief/embed"
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// SummaryFile holds the rolling summary of a PRD's completed stories, inside
+// the PRD directory.
+const SummaryFile = "summary.md"
+
+// summaryDraftFile is where the agent writes a new summary before it
+// replaces the old one.
+const summaryDraftFile = "summary.next.md"
+
+// summaryHeaderRegex matches the first line of a summary, which records how
+// many completed stories it covers.
+var summaryHeaderRegex = regexp.MustCompile(`^<!-- chief: summary of (\d+) completed stories -->$`)
+
+// SetSummaryEvery keeps a summary of the completed stories, regenerated every
+// n completed stories, and puts it in the prompt in place of the full
+// history in progress.md. 0 turns it off.
+func (l *Loop) SetSummaryEvery(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.summaryEvery = n
+}
+
+// ReadSummary returns the summary in prdDir and the number of completed
+// stories it covers. It returns "" when there is none.
+func ReadSummary(prdDir string) (string, int) {
+	data, err := os.ReadFile(filepath.Join(prdDir, SummaryFile))
+	if err != nil {
+		return "", 0
+	}
+	header, body, _ := strings.Cut(string(data), "\n")
+	m := summaryHeaderRegex.FindStringSubmatch(strings.TrimSpace(header))
+	if m == nil {
+		return "", 0
+	}
+	count, _ := strconv.Atoi(m[1])
+	return strings.TrimSpace(body), count
+}
+
+// summaryNote returns the prompt section with the summary of completed
+// stories. Callers must hold l.mu.
+func (l *Loop) summaryNote() string {
+	if l.summaryEvery <= 0 {
+		return ""
+	}
+	summary, count := ReadSummary(filepath.Dir(l.prdPath))
+	return embed.GetSummaryPrompt(summary, count, prd.ProgressPath(l.prdPath))
+}
+
+// updateSummary regenerates the summary once summaryEvery more stories are
+// complete than it covers. A failed update keeps the old summary; the run
+// goes on without it.
+func (l *Loop) updateSummary(ctx context.Context) {
+	l.mu.Lock()
+	every := l.summaryEvery
+	iter := l.iteration
+	l.mu.Unlock()
+	if every <= 0 {
+		return
+	}
+
+	p, err := prd.LoadPRD(l.prdPath)
+	if err != nil {
+		return
+	}
+	var done []prd.UserStory
+	for _, s := range p.UserStories {
+		if s.Passes {
+			done = append(done, s)
+		}
+	}
+	prdDir := filepath.Dir(l.prdPath)
+	_, covered := ReadSummary(prdDir)
+	if len(done)-covered < every {
+		return
+	}
+
+	if err := l.writeSummary(ctx, done); err != nil {
+		l.events <- Event{Type: EventSummary, Iteration: iter, Text: err.Error(), Err: err}
+		return
+	}
+	l.events <- Event{Type: EventSummary, Iteration: iter, Text: fmt.Sprintf("Summarized %d completed stories for the next prompts", len(done))}
+}
+
+// writeSummary has the agent summarize the done stories and saves the result
+// with a header recording how many stories it covers.
+func (l *Loop) writeSummary(ctx context.Context, done []prd.UserStory) error {
+	prdDir := filepath.Dir(l.prdPath)
+	summaryPath := filepath.Join(prdDir, SummaryFile)
+	draftPath := filepath.Join(prdDir, summaryDraftFile)
+	os.Remove(draftPath)
+	defer os.Remove(draftPath)
+
+	var stories strings.Builder
+	for _, s := range done {
+		fmt.Fprintf(&stories, "- %s: %s\n", s.ID, s.Title)
+	}
+	previous := ""
+	if summary, _ := ReadSummary(prdDir); summary != "" {
+		previous = summaryPath
+	}
+	prompt := embed.GetSummarizePrompt(l.prdPath, prd.ProgressPath(l.prdPath), strings.TrimRight(stories.String(), "\n"), previous, draftPath)
+
+	l.logLine("[chief] Summarizing completed stories")
+	cmd, err := l.provider.ReadOnlyCommand(ctx, prompt, l.effectiveWorkDir(), draftPath)
+	if err != nil {
+		return fmt.Errorf("failed to summarize completed stories: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to summarize completed stories: %w", err)
+	}
+	if l.logFile != nil {
+		cmd.Stderr = l.logFile
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s to summarize completed stories: %w", l.provider.Name(), err)
+	}
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		l.logLine(scanner.Text())
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("summarizing completed stories failed: %w", err)
+	}
+
+	draft, err := os.ReadFile(draftPath)
+	if err != nil || strings.TrimSpace(string(draft)) == "" {
+		return fmt.Errorf("%s did not write a summary of the completed stories", l.provider.Name())
+	}
+	content := fmt.Sprintf("<!-- chief: summary of %d completed stories -->\n%s\n", len(done), strings.TrimSpace(string(draft)))
+	if err := os.WriteFile(summaryPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", SummaryFile, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package loop
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadSummary(t *testing.T) {
+	dir := t.TempDir()
+	if summary, count := ReadSummary(dir); summary != "" || count != 0 {
+		t.Fatalf("expected no summary, got %q (%d)", summary, count)
+	}
+
+	os.WriteFile(filepath.Join(dir, SummaryFile), []byte("<!-- chief: summary of 4 completed stories -->\n## Built So Far\n- US-001: login\n"), 0644)
+	summary, count := ReadSummary(dir)
+	if count != 4 || summary != "## Built So Far\n- US-001: login" {
+		t.Errorf("got %q (%d)", summary, count)
+	}
+
+	os.WriteFile(filepath.Join(dir, SummaryFile), []byte("## Built So Far\n"), 0644)
+	if summary, _ := ReadSummary(dir); summary != "" {
+		t.Errorf("expected a summary without a header to be ignored, got %q", summary)
+	}
+}
+
+func TestSummaryNote(t *testing.T) {
+	dir := t.TempDir()
+	prdPath := createTestPRD(t, dir, false)
+	l := NewLoop(prdPath, "prompt", 1, testProvider)
+	os.WriteFile(filepath.Join(dir, SummaryFile), []byte("<!-- chief: summary of 2 completed stories -->\nLogin is done.\n"), 0644)
+
+	if note := l.summaryNote(); note != "" {
+		t.Errorf("expected no note with summaries off, got %q", note)
+	}
+	l.SetSummaryEvery(2)
+	if note := l.summaryNote(); !strings.Contains(note, "Login is done.") {
+		t.Errorf("expected the summary in the note, got %q", note)
+	}
+}
+
+func TestUpdateSummary(t *testing.T) {
+	dir := t.TempDir()
+	prdPath := createTestPRD(t, dir, true)
+	script := filepath.Join(dir, "mock-claude")
+	os.WriteFile(script, []byte("#!/bin/bash\necho '## Built So Far' > "+filepath.Join(dir, summaryDraftFile)+"\n"), 0755)
+
+	l := NewLoop(prdPath, "prompt", 1, &mockProvider{cliPath: script})
+	l.SetSummaryEvery(1)
+	l.updateSummary(context.Background())
+
+	event := <-l.events
+	if event.Type != EventSummary || event.Err != nil {
+		t.Fatalf("expected a summary event, got %+v", event)
+	}
+	summary, count := ReadSummary(dir)
+	if count != 1 || summary != "## Built So Far" {
+		t.Errorf("got %q (%d)", summary, count)
+	}
+	if _, err := os.Stat(filepath.Join(dir, summaryDraftFile)); !os.IsNotExist(err) {
+		t.Error("expected the draft to be removed")
+	}
+
+	// The summary already covers every completed story.
+	l.updateSummary(context.Background())
+	select {
+	case event := <-l.events:
+		t.Errorf("expected no regeneration, got %+v", event)
+	default:
+	}
+}
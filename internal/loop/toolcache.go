@@ -0,0 +1,30 @@
+package loop
+
+import (
+	"os"
+
+	"github.com/minicodemonkey/chief/embed"
+	"github.com/minicodemonkey/chief/internal/toolcache"
+)
+
+// SetToolCache lets the agent and verification reuse the passing result of
+// commands matching the cache's patterns while no files have changed. The
+// agent is told to run those commands through `chief cache run`.
+func (l *Loop) SetToolCache(cache *toolcache.Cache) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.toolCache = cache
+}
+
+// cacheNote returns the prompt section about cached commands. Callers must
+// hold l.mu.
+func (l *Loop) cacheNote() string {
+	if l.toolCache == nil {
+		return ""
+	}
+	chief, err := os.Executable()
+	if err != nil {
+		chief = "chief"
+	}
+	return embed.GetCachePrompt(chief, l.toolCache.Patterns)
+}
@@ -0,0 +1,160 @@
+package loop
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// UsageFile records the tokens and cost the agent reported for a PRD, in
+// total, per story, and per run and iteration. It lives in the PRD directory
+// and is updated as each iteration ends.
+const UsageFile = "usage.json"
+
+// UsageTotals is the tokens and cost of some of the agent's work.
+type UsageTotals struct {
+	InputTokens  int     `json:"inputTokens"` // Cached input tokens included
+	OutputTokens int     `json:"outputTokens"`
+	CostUSD      float64 `json:"costUsd"`
+}
+
+// Tokens returns the input and output tokens together.
+func (t UsageTotals) Tokens() int {
+	return t.InputTokens + t.OutputTokens
+}
+
+// String describes the totals, e.g. "420k tokens, $1.23".
+func (t UsageTotals) String() string {
+	return FormatUsage(t.Tokens(), t.CostUSD)
+}
+
+// add adds the usage an event reports.
+func (t *UsageTotals) add(event Event) {
+	t.InputTokens += event.Usage.Input
+	t.OutputTokens += event.Usage.Output
+	t.CostUSD += event.Cost
+}
+
+// IterationUsage is what one iteration cost, retries included.
+type IterationUsage struct {
+	Iteration int    `json:"iteration"`
+	StoryID   string `json:"storyId,omitempty"`
+	UsageTotals
+}
+
+// RunUsage is what one run cost.
+type RunUsage struct {
+	Start time.Time `json:"start"`
+	UsageTotals
+	Iterations []IterationUsage `json:"iterations"`
+}
+
+// UsageReport is the contents of UsageFile.
+type UsageReport struct {
+	UsageTotals
+	Stories map[string]*UsageTotals `json:"stories"`
+	Runs    []*RunUsage             `json:"runs"`
+}
+
+// ReadUsage reads a usage file. A missing file is an empty report.
+func ReadUsage(path string) (*UsageReport, error) {
+	report := &UsageReport{Stories: make(map[string]*UsageTotals)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return report, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if report.Stories == nil {
+		report.Stories = make(map[string]*UsageTotals)
+	}
+	return report, nil
+}
+
+// FormatUsage describes tokens and cost compactly, e.g. "420k tokens, $1.23".
+// The cost is left out when the agent doesn't report one.
+func FormatUsage(tokens int, cost float64) string {
+	var s string
+	switch {
+	case tokens >= 1000000:
+		s = fmt.Sprintf("%.1fM tokens", float64(tokens)/1000000)
+	case tokens >= 1000:
+		s = fmt.Sprintf("%dk tokens", tokens/1000)
+	default:
+		s = fmt.Sprintf("%d tokens", tokens)
+	}
+	if cost > 0 {
+		s += fmt.Sprintf(", $%.2f", cost)
+	}
+	return s
+}
+
+// usageTracker adds up a run's usage events in a PRD's usage file.
+type usageTracker struct {
+	path   string
+	report *UsageReport
+	run    *RunUsage // Added to the report with the run's first usage event
+}
+
+// openUsage reads the usage file in prdDir for a run starting now. It returns
+// nil when the file can't be read; runs go on without it.
+func openUsage(prdDir string) *usageTracker {
+	path := filepath.Join(prdDir, UsageFile)
+	report, err := ReadUsage(path)
+	if err != nil {
+		return nil
+	}
+	return &usageTracker{path: path, report: report, run: &RunUsage{Start: time.Now()}}
+}
+
+// add adds a usage event to the run, its iteration and story, and the PRD's
+// total, and writes the usage file.
+func (u *usageTracker) add(event Event) {
+	if u == nil {
+		return
+	}
+	if len(u.run.Iterations) == 0 {
+		u.report.Runs = append(u.report.Runs, u.run)
+	}
+	u.report.add(event)
+	u.run.add(event)
+	u.iteration(event.Iteration, event.StoryID).add(event)
+
+	if event.StoryID != "" {
+		story, ok := u.report.Stories[event.StoryID]
+		if !ok {
+			story = &UsageTotals{}
+			u.report.Stories[event.StoryID] = story
+		}
+		story.add(event)
+	}
+
+	if data, err := json.MarshalIndent(u.report, "", "  "); err == nil {
+		os.WriteFile(u.path, append(data, '\n'), 0644)
+	}
+}
+
+// iteration returns the usage of an iteration of the run, adding it if
+// needed. Parallel stories report their iterations interleaved.
+func (u *usageTracker) iteration(n int, storyID string) *UsageTotals {
+	for i := len(u.run.Iterations) - 1; i >= 0; i-- {
+		if it := &u.run.Iterations[i]; it.Iteration == n && it.StoryID == storyID {
+			return &it.UsageTotals
+		}
+	}
+	u.run.Iterations = append(u.run.Iterations, IterationUsage{Iteration: n, StoryID: storyID})
+	return &u.run.Iterations[len(u.run.Iterations)-1].UsageTotals
+}
+
+// totals returns what the run has cost so far.
+func (u *usageTracker) totals() UsageTotals {
+	if u == nil {
+		return UsageTotals{}
+	}
+	return u.run.UsageTotals
+}
@@ -0,0 +1,74 @@
+package loop
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestUsageTracker(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, UsageFile)
+
+	u := openUsage(dir)
+	u.add(Event{Type: EventUsage, Iteration: 1, StoryID: "US-001", Usage: Usage{Input: 1000, Output: 100}, Cost: 0.25})
+	u.add(Event{Type: EventUsage, Iteration: 1, StoryID: "US-001", Usage: Usage{Input: 500, Output: 50}, Cost: 0.10})
+	u.add(Event{Type: EventUsage, Iteration: 2, StoryID: "US-002", Usage: Usage{Input: 2000, Output: 200}, Cost: 0.50})
+	if got := u.totals(); got.Tokens() != 3850 || math.Abs(got.CostUSD-0.85) > 1e-9 {
+		t.Errorf("run totals = %+v, want 3850 tokens and $0.85", got)
+	}
+
+	// A second run adds to the PRD's totals but starts its own
+	u = openUsage(dir)
+	if got := u.totals(); got.Tokens() != 0 {
+		t.Errorf("new run totals = %+v, want zero", got)
+	}
+	u.add(Event{Type: EventUsage, Iteration: 1, StoryID: "US-002", Usage: Usage{Input: 100, Output: 10}, Cost: 0.05})
+
+	report, err := ReadUsage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Tokens() != 3960 || math.Abs(report.CostUSD-0.90) > 1e-9 {
+		t.Errorf("total = %+v, want 3960 tokens and $0.90", report.UsageTotals)
+	}
+	if s := report.Stories["US-001"]; s == nil || s.InputTokens != 1500 || s.OutputTokens != 150 {
+		t.Errorf("US-001 = %+v, want 1500 in and 150 out", s)
+	}
+	if s := report.Stories["US-002"]; s == nil || math.Abs(s.CostUSD-0.55) > 1e-9 {
+		t.Errorf("US-002 = %+v, want $0.55", s)
+	}
+	if len(report.Runs) != 2 {
+		t.Fatalf("got %d runs, want 2", len(report.Runs))
+	}
+	first := report.Runs[0]
+	if len(first.Iterations) != 2 || first.Iterations[0].Tokens() != 1650 || first.Iterations[1].StoryID != "US-002" {
+		t.Errorf("first run iterations = %+v, want 1650 tokens for US-001 then US-002", first.Iterations)
+	}
+}
+
+func TestReadUsage_Missing(t *testing.T) {
+	report, err := ReadUsage(filepath.Join(t.TempDir(), UsageFile))
+	if err != nil {
+		t.Fatalf("ReadUsage() error = %v", err)
+	}
+	if report.Tokens() != 0 || len(report.Runs) != 0 || report.Stories == nil {
+		t.Errorf("expected an empty report, got %+v", report)
+	}
+}
+
+func TestFormatUsage(t *testing.T) {
+	for _, tt := range []struct {
+		tokens int
+		cost   float64
+		want   string
+	}{
+		{900, 0, "900 tokens"},
+		{420000, 1.234, "420k tokens, $1.23"},
+		{1500000, 12, "1.5M tokens, $12.00"},
+	} {
+		if got := FormatUsage(tt.tokens, tt.cost); got != tt.want {
+			t.Errorf("FormatUsage(%d, %v) = %q, want %q", tt.tokens, tt.cost, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package loop
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/git"
+	"github.com/minicodemonkey/chief/internal/timefmt"
+	"github.com/minicodemonkey/chief/internal/toolcache"
+	"github.com/minicodemonkey/chief/internal/verify"
+)
+
+// verifyOutputLines is how much of a failed verification's output the agent is shown.
+const verifyOutputLines = 40
+
+// FailuresDir holds what is kept about failing stories inside the PRD directory.
+const FailuresDir = "failures"
+
+// verifyLogLines is how much of a failed verification's output is kept for `chief why-failed`.
+const verifyLogLines = 300
+
+// VerifyLogPath returns where the output of a story's last failed verification is kept.
+func VerifyLogPath(prdPath, storyID string) string {
+	return filepath.Join(filepath.Dir(prdPath), FailuresDir, storyID+".verify.log")
+}
+
+// verifyState holds the verification settings for the running PRD.
+type verifyState struct {
+	command   string                 // Shell command that must pass before a story is marked done ("" = off)
+	gateFlaky bool                   // Keep failing stories on tests known to be flaky
+	browser   string                 // Browser test command for stories with a URL ("" = off)
+	resources []string               // Resource tags held while verifying, e.g. "gpu"
+	limits    map[string]int         // Slots per resource tag on this machine (default 1)
+	ignore    []string               // Paths whose changes never need verifying, e.g. "docs/", "*.md"
+	done      []config.DoneCriterion // Project's definition of done, added to every story
+}
+
+// SetVerify enables verification. command is run with `sh -c` in the working
+// directory after the agent finishes a story, and the story is only marked
+// done when it passes. A failing run is repeated once; tests that pass on the
+// second run are recorded as flaky, a draft story to fix each one is added to
+// the flaky-tests PRD, and unless gateFlaky is set they no longer fail stories.
+func (l *Loop) SetVerify(command string, gateFlaky bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.verify.command = command
+	l.verify.gateFlaky = gateFlaky
+}
+
+// SetVerifyIgnore sets the paths whose changes never need verifying. A story
+// that only changed such paths is marked done without running the
+// verification command.
+func (l *Loop) SetVerifyIgnore(patterns []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.verify.ignore = patterns
+}
+
+// checkVerification runs the verification command after the agent finished
+// storyID and reports whether the story may be marked done.
+func (l *Loop) checkVerification(ctx context.Context, storyID string) bool {
+	l.mu.Lock()
+	state := l.verify
+	cache := l.toolCache
+	l.mu.Unlock()
+	if state.command == "" {
+		return true
+	}
+
+	// Skip verifying stories that only touched ignored paths, such as docs
+	dir := l.effectiveWorkDir()
+	if len(state.ignore) > 0 {
+		if files := l.storyChanges(dir); verify.OnlyIgnored(state.ignore, files) {
+			l.emitVerify(storyID, fmt.Sprintf("Verification skipped for %s; only ignored paths changed (%s)", storyID, listFiles(files, 3)), nil)
+			return true
+		}
+	}
+
+	// Reuse a passing run on the same files, e.g. the agent's own last test run
+	var tree string
+	if cache != nil && cache.Matches(state.command) {
+		var entry *toolcache.Entry
+		if entry, tree, _ = cache.Lookup(dir, state.command); entry != nil {
+			l.emitVerify(storyID, fmt.Sprintf("Verification passed for %s (cached result from %s; no files changed)", storyID, timefmt.ClockSeconds(entry.Time)), nil)
+			return true
+		}
+	}
+
+	release, err := l.holdResources(ctx, storyID)
+	if err != nil {
+		return false
+	}
+	defer release()
+
+	first := verify.Run(ctx, dir, state.command)
+	if first.Passed {
+		if tree != "" {
+			cache.Store(dir, state.command, tree, first.Output)
+		}
+		l.emitVerify(storyID, fmt.Sprintf("Verification passed for %s", storyID), nil)
+		return true
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+
+	// Run again with no code changes in between. Tests that pass this time are flaky.
+	second := verify.Run(ctx, dir, state.command)
+	stillFailing := make(map[string]bool)
+	for _, name := range second.Failed {
+		stillFailing[name] = true
+	}
+	var flipped []string
+	for _, name := range first.Failed {
+		if second.Passed || !stillFailing[name] {
+			flipped = append(flipped, name)
+		}
+	}
+	registry := l.recordFlaky(storyID, flipped)
+
+	if second.Passed {
+		l.emitVerify(storyID, fmt.Sprintf("Verification passed for %s on the second run", storyID), nil)
+		return true
+	}
+
+	remaining := second.Failed
+	if !state.gateFlaky && registry != nil {
+		remaining = nil
+		for _, name := range second.Failed {
+			if !registry.IsFlaky(name) {
+				remaining = append(remaining, name)
+			}
+		}
+	}
+	if len(second.Failed) > 0 && len(remaining) == 0 {
+		l.emitVerify(storyID, fmt.Sprintf("Verification passed for %s; ignored quarantined flaky tests: %s", storyID, strings.Join(second.Failed, ", ")), nil)
+		return true
+	}
+
+	l.saveVerifyLog(storyID, second.Output)
+	l.noteFailedTests(storyID, remaining)
+	summary := "verification failed"
+	if len(remaining) > 0 {
+		summary = fmt.Sprintf("verification failed: %s", strings.Join(remaining, ", "))
+	}
+	l.emitVerify(storyID, "", fmt.Errorf("%s for %s, asking %s to fix it", summary, storyID, l.provider.Name()))
+	l.addFeedback(fmt.Sprintf("## Fix Failing Verification\n\n"+
+		"You marked %s as done, but the verification command `%s` failed. The end of its output:\n\n```\n%s\n```\n\n"+
+		"The story is not done yet. Fix the failures, commit, and then output <chief-done/> again.",
+		storyID, state.command, verify.Tail(second.Output, verifyOutputLines)))
+	return false
+}
+
+// saveVerifyLog keeps the end of a failed verification's output for later
+// analysis. It is best effort; a story fails the same without it.
+func (l *Loop) saveVerifyLog(storyID, output string) {
+	path := VerifyLogPath(l.prdPath, storyID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, []byte(verify.Tail(output, verifyLogLines)+"\n"), 0644)
+}
+
+// storyChanges returns the files changed since work on the current story
+// began, or nil when that isn't known.
+func (l *Loop) storyChanges(dir string) []string {
+	l.mu.Lock()
+	base := l.storyBase
+	l.mu.Unlock()
+	if base == "" {
+		return nil
+	}
+	files, err := git.ChangedFiles(dir, base)
+	if err != nil {
+		return nil
+	}
+	return files
+}
+
+// listFiles joins the first max files for a message.
+func listFiles(files []string, max int) string {
+	if len(files) <= max {
+		return strings.Join(files, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(files[:max], ", "), len(files)-max)
+}
+
+// recordFlaky adds tests that flipped from fail to pass to the project's flaky
+// test registry and drafts a story to fix each new one. It returns the updated
+// registry, or nil if it couldn't be read.
+func (l *Loop) recordFlaky(storyID string, flipped []string) *verify.Registry {
+	baseDir := l.projectDir()
+	registry, err := verify.LoadRegistry(verify.RegistryPath(baseDir))
+	if err != nil {
+		l.emitFlaky(storyID, "", err)
+		return nil
+	}
+	if len(flipped) == 0 {
+		return registry
+	}
+
+	prdName := filepath.Base(filepath.Dir(l.prdPath))
+	fixPRD := filepath.Join(baseDir, ".chief", "prds", verify.FlakyPRDName, "prd.md")
+	now := time.Now()
+	for _, name := range flipped {
+		test, isNew := registry.Record(name, prdName, storyID, now)
+		if !isNew {
+			l.emitFlaky(storyID, fmt.Sprintf("Flaky test %s flipped again (%d times)", name, test.Flips), nil)
+			continue
+		}
+		text := fmt.Sprintf("Flaky test %s quarantined", name)
+		if added, err := verify.AddFixStory(fixPRD, test); err != nil {
+			l.emitFlaky(storyID, "", fmt.Errorf("could not draft a story for flaky test %s: %w", name, err))
+		} else if added {
+			text += fmt.Sprintf(", fix story added to %s", verify.FlakyPRDName)
+		}
+		l.emitFlaky(storyID, text, nil)
+	}
+	if err := registry.Save(); err != nil {
+		l.emitFlaky(storyID, "", err)
+	}
+	return registry
+}
+
+// projectDir returns the project root, which holds .chief/prds/<name>/prd.md.
+func (l *Loop) projectDir() string {
+	return filepath.Dir(filepath.Dir(filepath.Dir(filepath.Dir(l.prdPath))))
+}
+
+// emitVerify sends an EventVerify. A non-nil err marks a failed verification.
+func (l *Loop) emitVerify(storyID, text string, err error) {
+	l.emitWithStory(EventVerify, storyID, text, err)
+}
+
+// emitFlaky sends an EventFlakyTest.
+func (l *Loop) emitFlaky(storyID, text string, err error) {
+	l.emitWithStory(EventFlakyTest, storyID, text, err)
+}
+
+func (l *Loop) emitWithStory(t EventType, storyID, text string, err error) {
+	l.mu.Lock()
+	iter := l.iteration
+	l.mu.Unlock()
+	if err != nil {
+		text = err.Error()
+	}
+	l.events <- Event{Type: t, Iteration: iter, StoryID: storyID, Text: text, Err: err}
+}
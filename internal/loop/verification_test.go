@@ -0,0 +1,148 @@
+package loop
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/git"
+	"github.com/minicodemonkey/chief/internal/verify"
+)
+
+// newVerifyTestLoop creates a loop for a PRD at <project>/.chief/prds/main/prd.md.
+func newVerifyTestLoop(t *testing.T) (*Loop, string) {
+	t.Helper()
+	project := t.TempDir()
+	prdDir := filepath.Join(project, ".chief", "prds", "main")
+	if err := os.MkdirAll(prdDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	prdPath := createTestPRD(t, prdDir, false)
+	return NewLoopWithWorkDir(prdPath, project, "test", 5, testProvider), project
+}
+
+// drainEvents returns the events queued so far.
+func drainEvents(l *Loop) []Event {
+	var events []Event
+	for {
+		select {
+		case e := <-l.events:
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+func TestCheckVerification_Flaky(t *testing.T) {
+	l, project := newVerifyTestLoop(t)
+	// Fails the first time it runs, passes after that
+	l.SetVerify(`if [ -f ran ]; then echo ok; else touch ran; echo '--- FAIL: TestFlaky (0.00s)'; exit 1; fi`, false)
+
+	if !l.checkVerification(context.Background(), "US-001") {
+		t.Fatal("expected verification to pass on the second run")
+	}
+	registry, err := verify.LoadRegistry(verify.RegistryPath(project))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !registry.IsFlaky("TestFlaky") {
+		t.Error("expected TestFlaky to be recorded as flaky")
+	}
+	if _, err := os.Stat(filepath.Join(project, ".chief", "prds", verify.FlakyPRDName, "prd.md")); err != nil {
+		t.Errorf("expected a fix story PRD to be created: %v", err)
+	}
+
+	var sawFlaky bool
+	for _, e := range drainEvents(l) {
+		if e.Type == EventFlakyTest && strings.Contains(e.Text, "TestFlaky") {
+			sawFlaky = true
+		}
+	}
+	if !sawFlaky {
+		t.Error("expected a FlakyTest event")
+	}
+}
+
+func TestCheckVerification_Quarantine(t *testing.T) {
+	l, project := newVerifyTestLoop(t)
+	registry, _ := verify.LoadRegistry(verify.RegistryPath(project))
+	registry.Record("TestFlaky", "main", "US-001", time.Now())
+	if err := registry.Save(); err != nil {
+		t.Fatal(err)
+	}
+	command := `echo '--- FAIL: TestFlaky (0.00s)'; exit 1`
+
+	l.SetVerify(command, false)
+	if !l.checkVerification(context.Background(), "US-002") {
+		t.Error("expected a failure in a quarantined test not to fail the story")
+	}
+
+	l.SetVerify(command, true)
+	if l.checkVerification(context.Background(), "US-002") {
+		t.Error("expected gateFlaky to keep quarantined tests failing the story")
+	}
+}
+
+func TestCheckVerification_Failure(t *testing.T) {
+	l, _ := newVerifyTestLoop(t)
+	l.SetVerify(`echo '--- FAIL: TestBroken (0.00s)'; exit 1`, false)
+
+	if l.checkVerification(context.Background(), "US-001") {
+		t.Fatal("expected a consistently failing test to fail the story")
+	}
+	if !strings.Contains(l.feedback, "Fix Failing Verification") || !strings.Contains(l.feedback, "TestBroken") {
+		t.Errorf("expected feedback with the failing output, got %q", l.feedback)
+	}
+
+	var failed bool
+	for _, e := range drainEvents(l) {
+		if e.Type == EventVerify && e.Err != nil && strings.Contains(e.Text, "TestBroken") {
+			failed = true
+		}
+	}
+	if !failed {
+		t.Error("expected a failed Verify event naming the test")
+	}
+	if data, err := os.ReadFile(VerifyLogPath(l.prdPath, "US-001")); err != nil || !strings.Contains(string(data), "TestBroken") {
+		t.Errorf("expected the failing output to be kept, got %q (%v)", data, err)
+	}
+}
+
+func TestCheckVerification_IgnoredPaths(t *testing.T) {
+	l, project := newVerifyTestLoop(t)
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.name", "Test"},
+		{"config", "user.email", "test@test.com"},
+		{"commit", "--allow-empty", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", project}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %s", args, out)
+		}
+	}
+	l.storyBase = git.HeadCommit(project)
+	l.SetVerify("exit 1", false)
+	l.SetVerifyIgnore([]string{"docs/", "*.md"})
+
+	os.MkdirAll(filepath.Join(project, "docs"), 0755)
+	os.WriteFile(filepath.Join(project, "docs", "guide.html"), []byte("guide"), 0644)
+	os.WriteFile(filepath.Join(project, "README.md"), []byte("# Readme"), 0644)
+	if !l.checkVerification(context.Background(), "US-001") {
+		t.Fatal("expected a doc-only story to skip verification")
+	}
+	if events := drainEvents(l); len(events) != 1 || !strings.Contains(events[0].Text, "skipped") {
+		t.Errorf("expected a skipped verification event, got %+v", events)
+	}
+
+	os.WriteFile(filepath.Join(project, "main.go"), []byte("package main"), 0644)
+	if l.checkVerification(context.Background(), "US-001") {
+		t.Error("expected a code change to be verified")
+	}
+}
@@ -0,0 +1,407 @@
+// Package network applies the project's network policy to the agent. Under
+// the offline and restricted policies, agent commands are pointed at a local
+// proxy through the standard proxy environment variables. The proxy only lets
+// through requests to allowed hosts and logs every request it sees, so a run
+// leaves a record of what the agent tried to reach. Nothing else is blocked:
+// a program that ignores the proxy variables, or resolves and connects on its
+// own, gets past the policy.
+//
+// The package also builds the HTTP client for Chief's own requests, which
+// honors the standard proxy variables, trusts a configured CA bundle, and
+// presents a configured client certificate.
+package network
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/loop"
+)
+
+// Policies for network.policy.
+const (
+	PolicyOpen       = "open"       // No restrictions (default)
+	PolicyRestricted = "restricted" // Only the agent's API and network.allow
+	PolicyOffline    = "offline"    // Only the local machine
+)
+
+// LogFile records the requests the proxy saw, relative to the project root.
+const LogFile = ".chief/network.log"
+
+// PolicyEnv tells agent commands which policy they run under.
+const PolicyEnv = "CHIEF_NETWORK_POLICY"
+
+// dialTimeout bounds connecting to a host or upstream proxy.
+const dialTimeout = 30 * time.Second
+
+// agentHosts are the hosts each agent CLI needs to reach its model, allowed
+// under the restricted policy.
+var agentHosts = map[string][]string{
+	"Claude":   {"api.anthropic.com"},
+	"Codex":    {"api.openai.com", "chatgpt.com"},
+	"Cursor":   {"*.cursor.sh"},
+	"OpenCode": nil, // Depends on the configured model provider
+}
+
+// Policy returns the configured policy, defaulting to open.
+func Policy(cfg config.NetworkConfig) string {
+	if cfg.Policy == "" {
+		return PolicyOpen
+	}
+	return cfg.Policy
+}
+
+// Validate checks the network settings.
+func Validate(cfg config.NetworkConfig) error {
+	switch Policy(cfg) {
+	case PolicyOpen, PolicyRestricted, PolicyOffline:
+	default:
+		return fmt.Errorf("unknown network.policy %q: expected \"open\", \"restricted\", or \"offline\"", cfg.Policy)
+	}
+	if (cfg.ClientCert == "") != (cfg.ClientKey == "") {
+		return fmt.Errorf("network.clientCert and network.clientKey must be set together")
+	}
+	for _, host := range cfg.Allow {
+		if host == "" || strings.ContainsAny(host, "/:") {
+			return fmt.Errorf("network.allow entry %q must be a host name, such as example.com or *.example.com", host)
+		}
+	}
+	return nil
+}
+
+// CABundlePath returns the configured CA bundle's path, resolved against the
+// project root. It returns "" when none is set.
+func CABundlePath(cfg config.NetworkConfig, baseDir string) string {
+	return cfg.CABundlePath(baseDir)
+}
+
+// CertPool returns the system's trusted roots plus the certificates in the
+// configured CA bundle, or nil when no bundle is set.
+func CertPool(cfg config.NetworkConfig, baseDir string) (*x509.CertPool, error) {
+	return cfg.CertPool(baseDir)
+}
+
+// ClientCertificate returns the configured client certificate for mutual
+// TLS, or nil when none is set.
+func ClientCertificate(cfg config.NetworkConfig, baseDir string) (*tls.Certificate, error) {
+	return cfg.ClientCertificate(baseDir)
+}
+
+// TLSConfig returns the TLS settings for Chief's own connections: the
+// configured CA bundle and client certificate. It returns nil when neither
+// is set, meaning the defaults.
+func TLSConfig(cfg config.NetworkConfig, baseDir string) (*tls.Config, error) {
+	return cfg.TLSConfig(baseDir)
+}
+
+// HTTPClient returns a client for Chief's own requests. It goes through the
+// proxy named by HTTPS_PROXY or HTTP_PROXY, except for hosts in NO_PROXY,
+// trusts the configured CA bundle, and presents the configured client
+// certificate to servers that ask for one. Organization settings are fetched
+// with the same client (see config.NetworkConfig.HTTPClient).
+func HTTPClient(cfg config.NetworkConfig, baseDir string) (*http.Client, error) {
+	return cfg.HTTPClient(baseDir)
+}
+
+// AllowedHosts returns the hosts the policy lets the named agent reach.
+// Offline allows none; open returns nil, meaning every host.
+func AllowedHosts(cfg config.NetworkConfig, agentName string) []string {
+	switch Policy(cfg) {
+	case PolicyRestricted:
+		return append(append([]string(nil), agentHosts[agentName]...), cfg.Allow...)
+	case PolicyOffline:
+		return []string{}
+	default:
+		return nil
+	}
+}
+
+// AgentHosts returns the hosts the named agent needs to reach its model, and
+// whether they are known.
+func AgentHosts(agentName string) ([]string, bool) {
+	hosts := agentHosts[agentName]
+	return hosts, len(hosts) > 0
+}
+
+// hostAllowed reports whether host matches one of the patterns. A pattern is
+// a host name or "*.domain", which matches any subdomain of domain.
+func hostAllowed(host string, patterns []string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, p := range patterns {
+		p = strings.ToLower(p)
+		if suffix, ok := strings.CutPrefix(p, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Proxy is a local HTTP proxy that only forwards requests to allowed hosts.
+type Proxy struct {
+	Addr     string // host:port the proxy listens on
+	Policy   string
+	allow    []string
+	listener net.Listener
+	server   *http.Server
+
+	mu  sync.Mutex
+	log io.WriteCloser
+}
+
+// Start starts a proxy applying the policy for the named agent, logging to
+// LogFile under baseDir. It returns nil under the open policy.
+func Start(cfg config.NetworkConfig, agentName, baseDir string) (*Proxy, error) {
+	if err := Validate(cfg); err != nil {
+		return nil, err
+	}
+	if Policy(cfg) == PolicyOpen {
+		return nil, nil
+	}
+
+	logPath := filepath.Join(baseDir, LogFile)
+	if err := os.MkdirAll(filepath.Dir(logPath), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(logPath), err)
+	}
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open network log: %w", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		logFile.Close()
+		return nil, fmt.Errorf("failed to start network proxy: %w", err)
+	}
+
+	p := &Proxy{
+		Addr:     listener.Addr().String(),
+		Policy:   Policy(cfg),
+		allow:    AllowedHosts(cfg, agentName),
+		listener: listener,
+		log:      logFile,
+	}
+	p.server = &http.Server{Handler: p, ReadHeaderTimeout: 30 * time.Second}
+	go func() { _ = p.server.Serve(listener) }()
+	return p, nil
+}
+
+// Close stops the proxy and closes its log.
+func (p *Proxy) Close() error {
+	err := p.server.Close()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if cerr := p.log.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Env returns the environment variables that send a command's traffic
+// through the proxy. Connections to the local machine bypass it, and so does
+// any program that doesn't read them.
+func (p *Proxy) Env() []string {
+	url := "http://" + p.Addr
+	var env []string
+	for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "ALL_PROXY"} {
+		env = append(env, name+"="+url, strings.ToLower(name)+"="+url)
+	}
+	noProxy := "localhost,127.0.0.1,::1"
+	return append(env, "NO_PROXY="+noProxy, "no_proxy="+noProxy, PolicyEnv+"="+p.Policy)
+}
+
+// record appends a request to the network log.
+func (p *Proxy) record(allowed bool, method, target string) {
+	verdict := "DENY"
+	if allowed {
+		verdict = "ALLOW"
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintf(p.log, "%s %s %s %s\n", time.Now().Format(time.RFC3339), verdict, method, target)
+}
+
+// ServeHTTP implements http.Handler. CONNECT requests are tunneled, and plain
+// HTTP requests are forwarded.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if r.Method != http.MethodConnect && r.URL.Host != "" {
+		host = r.URL.Host
+	}
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+
+	allowed := hostAllowed(hostname, p.allow)
+	p.record(allowed, r.Method, host)
+	if !allowed {
+		http.Error(w, fmt.Sprintf("chief: %s is blocked by network.policy %s", hostname, p.Policy), http.StatusForbidden)
+		return
+	}
+
+	if r.Method == http.MethodConnect {
+		p.tunnel(w, r)
+		return
+	}
+	p.forward(w, r)
+}
+
+// tunnel connects the client to the requested host:port and copies bytes
+// both ways.
+func (p *Proxy) tunnel(w http.ResponseWriter, r *http.Request) {
+	upstream, err := dialUpstream(r.Host)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		upstream.Close()
+		http.Error(w, "tunneling not supported", http.StatusInternalServerError)
+		return
+	}
+	client, _, err := hijacker.Hijack()
+	if err != nil {
+		upstream.Close()
+		return
+	}
+	_, _ = client.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
+
+	go func() {
+		_, _ = io.Copy(upstream, client)
+		upstream.Close()
+	}()
+	_, _ = io.Copy(client, upstream)
+	client.Close()
+}
+
+// dialUpstream connects to host:port, through the proxy HTTPS_PROXY names for
+// it when there is one, so the policy proxy also works behind a corporate
+// proxy.
+func dialUpstream(host string) (net.Conn, error) {
+	via, err := http.ProxyFromEnvironment(&http.Request{URL: &url.URL{Scheme: "https", Host: host}})
+	if err != nil {
+		return nil, err
+	}
+	if via == nil {
+		return net.DialTimeout("tcp", host, dialTimeout)
+	}
+
+	conn, err := net.DialTimeout("tcp", via.Host, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	connect := &http.Request{Method: http.MethodConnect, URL: &url.URL{Opaque: host}, Host: host, Header: make(http.Header)}
+	if user := via.User; user != nil {
+		password, _ := user.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		connect.Header.Set("Proxy-Authorization", "Basic "+auth)
+	}
+	if err := connect.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), connect)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s refused %s: %s", via.Host, host, resp.Status)
+	}
+	return conn, nil
+}
+
+// forward sends a plain HTTP request on and copies back the response.
+func (p *Proxy) forward(w http.ResponseWriter, r *http.Request) {
+	out := r.Clone(r.Context())
+	out.RequestURI = ""
+	out.Header.Del("Proxy-Connection")
+	out.Header.Del("Proxy-Authorization")
+	resp, err := (&http.Transport{Proxy: http.ProxyFromEnvironment}).RoundTrip(out)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	for k, values := range resp.Header {
+		for _, v := range values {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+// AgentEnv returns the environment variables agent commands need under the
+// network settings: the proxy's variables when proxy is set, and the CA bundle
+// for Node-based agents. It returns nil when nothing needs to change.
+func AgentEnv(cfg config.NetworkConfig, baseDir string, proxy *Proxy) []string {
+	var env []string
+	if proxy != nil {
+		env = append(env, proxy.Env()...)
+	}
+	if cfg.CABundle != "" {
+		env = append(env, "NODE_EXTRA_CA_CERTS="+CABundlePath(cfg, baseDir))
+	}
+	return env
+}
+
+// Provider wraps an agent provider so every command it builds runs with
+// extra environment variables, such as AgentEnv's.
+type Provider struct {
+	loop.Provider
+	Env []string
+}
+
+// LoopCommand implements loop.Provider.
+func (p *Provider) LoopCommand(ctx context.Context, prompt, workDir string) *exec.Cmd {
+	return p.withEnv(p.Provider.LoopCommand(ctx, prompt, workDir))
+}
+
+// ReadOnlyCommand implements loop.Provider.
+func (p *Provider) ReadOnlyCommand(ctx context.Context, prompt, workDir string, outputs ...string) (*exec.Cmd, error) {
+	cmd, err := p.Provider.ReadOnlyCommand(ctx, prompt, workDir, outputs...)
+	if err != nil {
+		return nil, err
+	}
+	return p.withEnv(cmd), nil
+}
+
+// InteractiveCommand implements loop.Provider.
+func (p *Provider) InteractiveCommand(workDir, prompt string) *exec.Cmd {
+	return p.withEnv(p.Provider.InteractiveCommand(workDir, prompt))
+}
+
+func (p *Provider) withEnv(cmd *exec.Cmd) *exec.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, p.Env...)
+	return cmd
+}
@@ -0,0 +1,262 @@
+package network
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/loop"
+)
+
+func TestValidate(t *testing.T) {
+	valid := []config.NetworkConfig{
+		{},
+		{Policy: "offline"},
+		{Policy: "restricted", Allow: []string{"registry.npmjs.org", "*.example.com"}},
+	}
+	for _, cfg := range valid {
+		if err := Validate(cfg); err != nil {
+			t.Errorf("Validate(%+v) = %v", cfg, err)
+		}
+	}
+	invalid := []config.NetworkConfig{
+		{Policy: "airgapped"},
+		{Policy: "restricted", Allow: []string{"https://example.com"}},
+	}
+	for _, cfg := range invalid {
+		if err := Validate(cfg); err == nil {
+			t.Errorf("expected Validate(%+v) to fail", cfg)
+		}
+	}
+}
+
+func TestAllowedHosts(t *testing.T) {
+	restricted := config.NetworkConfig{Policy: "restricted", Allow: []string{"*.example.com"}}
+	hosts := AllowedHosts(restricted, "Claude")
+	for _, tc := range []struct {
+		host string
+		want bool
+	}{
+		{"api.anthropic.com", true},
+		{"API.Anthropic.com.", true},
+		{"registry.example.com", true},
+		{"example.com", false},
+		{"evil-example.com", false},
+		{"github.com", false},
+	} {
+		if got := hostAllowed(tc.host, hosts); got != tc.want {
+			t.Errorf("hostAllowed(%q) = %v, want %v", tc.host, got, tc.want)
+		}
+	}
+
+	if hosts := AllowedHosts(config.NetworkConfig{Policy: "offline", Allow: []string{"example.com"}}, "Claude"); len(hosts) != 0 {
+		t.Errorf("expected offline to allow nothing, got %v", hosts)
+	}
+}
+
+func TestProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "hello")
+	}))
+	defer upstream.Close()
+
+	baseDir := t.TempDir()
+	proxy, err := Start(config.NetworkConfig{Policy: "restricted", Allow: []string{"127.0.0.1"}}, "Claude", baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse("http://" + proxy.Addr)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+	resp, err := client.Get(upstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "hello" {
+		t.Errorf("expected an allowed request to go through, got %d %q", resp.StatusCode, body)
+	}
+
+	blocked := strings.Replace(upstream.URL, "127.0.0.1", "localhost", 1)
+	resp, err = client.Get(blocked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected a request to another host to be blocked, got %d", resp.StatusCode)
+	}
+
+	log, err := os.ReadFile(filepath.Join(baseDir, LogFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(log), "ALLOW GET 127.0.0.1") || !strings.Contains(string(log), "DENY GET localhost") {
+		t.Errorf("expected both requests in the log, got:\n%s", log)
+	}
+}
+
+func TestStart_Open(t *testing.T) {
+	proxy, err := Start(config.NetworkConfig{}, "Claude", t.TempDir())
+	if err != nil || proxy != nil {
+		t.Errorf("expected no proxy under the open policy, got %v (%v)", proxy, err)
+	}
+}
+
+// stubProvider runs `true` in place of an agent CLI.
+type stubProvider struct{}
+
+func (stubProvider) Name() string    { return "Claude" }
+func (stubProvider) CLIPath() string { return "true" }
+func (stubProvider) LoopCommand(ctx context.Context, _, workDir string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "true")
+	cmd.Dir = workDir
+	return cmd
+}
+func (p stubProvider) ReadOnlyCommand(ctx context.Context, prompt, workDir string, _ ...string) (*exec.Cmd, error) {
+	return p.LoopCommand(ctx, prompt, workDir), nil
+}
+func (stubProvider) InteractiveCommand(workDir, _ string) *exec.Cmd { return exec.Command("true") }
+func (stubProvider) CleanOutput(output string) string               { return output }
+func (stubProvider) ParseLine(string) *loop.Event                   { return nil }
+func (stubProvider) LogFileName() string                            { return "stub.log" }
+
+func TestProvider_Env(t *testing.T) {
+	proxy, err := Start(config.NetworkConfig{Policy: "offline"}, "Claude", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer proxy.Close()
+
+	p := &Provider{Provider: stubProvider{}, Env: AgentEnv(config.NetworkConfig{CABundle: "ca.pem"}, "/project", proxy)}
+	cmd := p.LoopCommand(context.Background(), "prompt", t.TempDir())
+	env := strings.Join(cmd.Env, "\n")
+	for _, want := range []string{"HTTPS_PROXY=http://" + proxy.Addr, "NO_PROXY=localhost", PolicyEnv + "=offline", "NODE_EXTRA_CA_CERTS=/project/ca.pem"} {
+		if !strings.Contains(env, want) {
+			t.Errorf("expected %q in the agent's environment", want)
+		}
+	}
+}
+
+func TestHTTPClient_CABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "trusted")
+	}))
+	defer server.Close()
+
+	// Without the bundle the test server's certificate isn't trusted
+	client, err := HTTPClient(config.NetworkConfig{}, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Get(server.URL); err == nil {
+		t.Fatal("expected an unknown CA to be rejected")
+	}
+
+	baseDir := t.TempDir()
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(filepath.Join(baseDir, "ca.pem"), cert, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	client, err = HTTPClient(config.NetworkConfig{CABundle: "ca.pem"}, baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected the bundle's CA to be trusted, got %v", err)
+	}
+	resp.Body.Close()
+
+	if err := os.WriteFile(filepath.Join(baseDir, "bad.pem"), []byte("not a certificate"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := HTTPClient(config.NetworkConfig{CABundle: "bad.pem"}, baseDir); err == nil {
+		t.Error("expected a bundle without certificates to be rejected")
+	}
+}
+
+func TestHTTPClient_ClientCert(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "hello "+r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	baseDir := t.TempDir()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	os.WriteFile(filepath.Join(baseDir, "ca.pem"), ca, 0o644)
+
+	// Without a client certificate the gateway refuses the connection
+	client, err := HTTPClient(config.NetworkConfig{CABundle: "ca.pem"}, baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Get(server.URL); err == nil {
+		t.Fatal("expected the server to require a client certificate")
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "chief"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(baseDir, "client.pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644)
+	os.WriteFile(filepath.Join(baseDir, "client.key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+
+	cfg := config.NetworkConfig{CABundle: "ca.pem", ClientCert: "client.pem", ClientKey: "client.key"}
+	client, err = HTTPClient(cfg, baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected the client certificate to be presented, got %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "hello chief" {
+		t.Errorf("got %q", body)
+	}
+
+	if err := Validate(config.NetworkConfig{ClientCert: "client.pem"}); err == nil {
+		t.Error("expected a certificate without a key to be rejected")
+	}
+	if _, err := HTTPClient(config.NetworkConfig{ClientCert: "client.pem", ClientKey: "missing.key"}, baseDir); err == nil {
+		t.Error("expected a missing key to be rejected")
+	}
+}
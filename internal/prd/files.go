@@ -0,0 +1,78 @@
+package prd
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// codeSpanRegex matches `code spans` in story text.
+var codeSpanRegex = regexp.MustCompile("`([^`\n]+)`")
+
+// fileNameRegex matches a file name with an extension, like "login.go".
+var fileNameRegex = regexp.MustCompile(`^[\w.-]*\w\.[A-Za-z][A-Za-z0-9]{0,5}$`)
+
+// StoryFiles returns the files and directories a story names in its
+// description or acceptance criteria, sorted and without duplicates. A path
+// counts when it contains a slash, like internal/auth/ or src/login.ts, or
+// when it is a code span holding a file name, like `README.md`.
+func StoryFiles(s *UserStory) []string {
+	text := s.Title + "\n" + s.Description + "\n" + strings.Join(s.AcceptanceCriteria, "\n")
+
+	seen := make(map[string]bool)
+	var files []string
+	add := func(word string, named bool) {
+		word = strings.Trim(word, "\"'()[]{}<>,;:!?")
+		word = strings.TrimSuffix(word, ".")
+		if word == "" || strings.Contains(word, "://") || strings.ContainsAny(word, " *") {
+			return
+		}
+		if !strings.Contains(word, "/") && !(named && fileNameRegex.MatchString(word)) {
+			return
+		}
+		dir := strings.HasSuffix(word, "/")
+		word = path.Clean(strings.TrimPrefix(word, "./"))
+		if word == "." || word == "/" || strings.HasPrefix(word, "../") {
+			return
+		}
+		if dir {
+			word += "/"
+		}
+		if !seen[word] {
+			seen[word] = true
+			files = append(files, word)
+		}
+	}
+	for _, m := range codeSpanRegex.FindAllStringSubmatch(text, -1) {
+		add(m[1], true)
+	}
+	for _, word := range strings.Fields(codeSpanRegex.ReplaceAllString(text, " ")) {
+		add(word, false)
+	}
+
+	sort.Strings(files)
+	return files
+}
+
+// FilesOverlap reports whether two lists from StoryFiles share a file, or
+// one names a directory holding a file of the other.
+func FilesOverlap(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y || within(x, y) || within(y, x) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// within reports whether file is inside dir. A file name without a directory
+// matches that name in any directory.
+func within(file, dir string) bool {
+	if !strings.Contains(strings.TrimSuffix(dir, "/"), "/") && !strings.HasSuffix(dir, "/") {
+		return path.Base(file) == dir
+	}
+	return strings.HasPrefix(file, strings.TrimSuffix(dir, "/")+"/")
+}
@@ -0,0 +1,51 @@
+package prd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStoryFiles(t *testing.T) {
+	s := &UserStory{
+		Title:       "Add login",
+		Description: "As a user, I want to log in. Touches internal/auth/login.go and the handlers in `cmd/server/`.",
+		AcceptanceCriteria: []string{
+			"Document it in `README.md`",
+			"Works for read/write tokens, e.g. from https://example.com/docs",
+			"`go test ./...` passes",
+			"See ./internal/auth/login.go.",
+		},
+	}
+	got := StoryFiles(s)
+	want := []string{"README.md", "cmd/server/", "internal/auth/login.go", "read/write"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("StoryFiles() = %v, want %v", got, want)
+	}
+
+	if files := StoryFiles(&UserStory{Description: "Make the button blue, i.e. friendlier."}); len(files) != 0 {
+		t.Errorf("expected no files, got %v", files)
+	}
+}
+
+func TestFilesOverlap(t *testing.T) {
+	tests := []struct {
+		a, b []string
+		want bool
+	}{
+		{[]string{"internal/auth/login.go"}, []string{"internal/auth/login.go"}, true},
+		{[]string{"internal/auth/"}, []string{"internal/auth/login.go"}, true},
+		{[]string{"internal/auth"}, []string{"internal/auth/login.go"}, true},
+		{[]string{"README.md"}, []string{"docs/README.md"}, true},
+		{[]string{"internal/auth/login.go"}, []string{"internal/billing/invoice.go"}, false},
+		{[]string{"internal/auth"}, []string{"internal/authz/policy.go"}, false},
+		{nil, []string{"internal/auth/login.go"}, false},
+	}
+	for _, tt := range tests {
+		if got := FilesOverlap(tt.a, tt.b); got != tt.want {
+			t.Errorf("FilesOverlap(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if got := FilesOverlap(tt.b, tt.a); got != tt.want {
+			t.Errorf("FilesOverlap(%v, %v) = %v, want %v", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
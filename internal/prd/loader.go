@@ -1,6 +1,51 @@
 package prd
 
-// LoadPRD reads and parses a PRD markdown file from the given path.
+import (
+	"path/filepath"
+	"strings"
+)
+
+// LoadPRD reads and parses a PRD markdown file from the given path, and
+// looks up the stories of other PRDs in the same .chief/prds directory that
+// its stories depend on.
 func LoadPRD(path string) (*PRD, error) {
-	return ParseMarkdownPRD(path)
+	p, err := ParseMarkdownPRD(path)
+	if err != nil {
+		return nil, err
+	}
+	p.resolveExternal(path)
+	return p, nil
+}
+
+// resolveExternal fills in External for the PRD at path. A dependency that
+// names the PRD itself is turned into a plain story ID.
+func (p *PRD) resolveExternal(path string) {
+	own := filepath.Base(filepath.Dir(path))
+	prdsDir := filepath.Dir(filepath.Dir(path))
+	p.External = make(map[string]bool)
+	loaded := make(map[string]bool)
+	for i := range p.UserStories {
+		deps := p.UserStories[i].DependsOn
+		for j, dep := range deps {
+			prdName, storyID := SplitDependency(dep)
+			if prdName == "" {
+				continue
+			}
+			if prdName == own {
+				deps[j] = storyID
+				continue
+			}
+			if loaded[prdName] || prdName == "." || prdName == ".." || strings.ContainsAny(prdName, `/\`) {
+				continue
+			}
+			loaded[prdName] = true
+			other, err := ParseMarkdownPRD(filepath.Join(prdsDir, prdName, "prd.md"))
+			if err != nil {
+				continue
+			}
+			for _, story := range other.UserStories {
+				p.External[externalKey(prdName, story.ID)] = story.Passes
+			}
+		}
+	}
 }
@@ -19,6 +19,15 @@ var priorityLineRegex = regexp.MustCompile(`^\*\*Priority:\*\*\s*(.+)$`)
 // descriptionLineRegex matches "**Description:** value"
 var descriptionLineRegex = regexp.MustCompile(`^\*\*Description:\*\*\s*(.+)$`)
 
+// ownerLineRegex matches "**Owner:** value"
+var ownerLineRegex = regexp.MustCompile(`^\*\*Owner:\*\*\s*(.+)$`)
+
+// dependsOnLineRegex matches "**Depends on:** US-001, US-002"
+var dependsOnLineRegex = regexp.MustCompile(`(?i)^\*\*Depends on:\*\*\s*(.+)$`)
+
+// urlLineRegex matches "**URL:** http://localhost:3000/checkout"
+var urlLineRegex = regexp.MustCompile(`(?i)^\*\*URL:\*\*\s*(.+)$`)
+
 // checkboxRegex matches "- [ ] text" or "- [x] text"
 var checkboxRegex = regexp.MustCompile(`^-\s+\[([ xX])\]\s+(.+)$`)
 
@@ -120,6 +129,14 @@ func ParseMarkdownPRDFromString(content string) (*PRD, error) {
 				case "in-progress", "in progress", "started":
 					current.story.InProgress = true
 					current.story.Passes = false
+				case "needs-review", "needs review":
+					current.story.NeedsReview = true
+					current.story.Passes = false
+					current.story.InProgress = false
+				case "skipped", "skip":
+					current.story.Skipped = true
+					current.story.Passes = false
+					current.story.InProgress = false
 				default:
 					current.story.Passes = false
 					current.story.InProgress = false
@@ -137,6 +154,28 @@ func ParseMarkdownPRDFromString(content string) (*PRD, error) {
 				continue
 			}
 
+			// **Owner:** line
+			if m := ownerLineRegex.FindStringSubmatch(trimmed); m != nil {
+				if strings.EqualFold(strings.TrimSpace(m[1]), OwnerHuman) {
+					current.story.Owner = OwnerHuman
+				}
+				continue
+			}
+
+			// **Depends on:** line
+			if m := dependsOnLineRegex.FindStringSubmatch(trimmed); m != nil {
+				current.story.DependsOn = strings.FieldsFunc(m[1], func(r rune) bool {
+					return r == ',' || r == ' '
+				})
+				continue
+			}
+
+			// **URL:** line
+			if m := urlLineRegex.FindStringSubmatch(trimmed); m != nil {
+				current.story.URL = strings.Trim(strings.TrimSpace(m[1]), "<>")
+				continue
+			}
+
 			// **Description:** line
 			if m := descriptionLineRegex.FindStringSubmatch(trimmed); m != nil {
 				current.story.Description = strings.TrimSpace(m[1])
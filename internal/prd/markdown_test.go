@@ -242,6 +242,7 @@ func TestParseMarkdownPRDFromString_StatusMapping(t *testing.T) {
 		{"in-progress", false, true},
 		{"in progress", false, true},
 		{"started", false, true},
+		{"needs-review", false, false},
 		{"todo", false, false},
 		{"pending", false, false},
 		{"", false, false},
@@ -412,3 +413,41 @@ func TestParseMarkdownPRDFromString_FloatPriority(t *testing.T) {
 		t.Errorf("s3.Priority = %g, want 2", p.UserStories[2].Priority)
 	}
 }
+
+func TestParseMarkdownPRDFromString_OwnerAndDependsOn(t *testing.T) {
+	md := `# P
+
+### US-001: Create Stripe account
+**Owner:** Human
+- [ ] Keys in .env
+
+### US-002: Charge cards
+**Depends on:** US-001, US-003
+- [ ] Checkout charges the card
+`
+	p, err := ParseMarkdownPRDFromString(md)
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	if !p.UserStories[0].IsHuman() {
+		t.Errorf("s1.Owner = %q, want human", p.UserStories[0].Owner)
+	}
+	if p.UserStories[1].IsHuman() {
+		t.Errorf("s2.Owner = %q, want the agent", p.UserStories[1].Owner)
+	}
+	deps := p.UserStories[1].DependsOn
+	if len(deps) != 2 || deps[0] != "US-001" || deps[1] != "US-003" {
+		t.Errorf("s2.DependsOn = %v, want [US-001 US-003]", deps)
+	}
+}
+
+func TestParseMarkdownPRDFromString_URL(t *testing.T) {
+	md := "# P\n\n### US-001: Checkout page\n**URL:** <http://localhost:3000/checkout>\n- [ ] Pays\n"
+	p, err := ParseMarkdownPRDFromString(md)
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	if got := p.UserStories[0].URL; got != "http://localhost:3000/checkout" {
+		t.Errorf("URL = %q, want http://localhost:3000/checkout", got)
+	}
+}
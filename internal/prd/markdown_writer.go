@@ -5,12 +5,19 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 )
 
+// writeMu serializes the read-modify-write updates of prd.md files, so loops
+// running stories of one PRD side by side don't undo each other's changes.
+var writeMu sync.Mutex
+
 // SetStoryStatus performs a surgical update of a story's status in a prd.md file.
 // It finds the story block by its heading, updates or inserts the **Status:** line,
 // and when status is "done", flips all unchecked checkboxes to checked.
 func SetStoryStatus(path, storyID, status string) error {
+	writeMu.Lock()
+	defer writeMu.Unlock()
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("failed to read PRD file: %w", err)
@@ -87,3 +94,117 @@ func setStoryStatusInString(content, storyID, status string) (string, error) {
 
 	return strings.Join(lines, "\n"), nil
 }
+
+// AddStoryDependency adds dependsOn to the **Depends on:** line of a story in
+// a prd.md file, inserting the line after the heading if the story has none.
+// A dependency the story already has is left alone.
+func AddStoryDependency(path, storyID, dependsOn string) error {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read PRD file: %w", err)
+	}
+	lines := strings.Split(string(data), "\n")
+
+	headingPattern := regexp.MustCompile(`^#{3,4}\s+` + regexp.QuoteMeta(storyID) + `:\s+`)
+	storyStart := -1
+	for i, line := range lines {
+		if headingPattern.MatchString(strings.TrimSpace(line)) {
+			storyStart = i
+			break
+		}
+	}
+	if storyStart == -1 {
+		return fmt.Errorf("story %s not found in PRD", storyID)
+	}
+
+	for i := storyStart + 1; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, "## ") || strings.HasPrefix(trimmed, "### ") || strings.HasPrefix(trimmed, "#### ") {
+			break
+		}
+		m := dependsOnLineRegex.FindStringSubmatch(trimmed)
+		if m == nil {
+			continue
+		}
+		for _, id := range strings.FieldsFunc(m[1], func(r rune) bool { return r == ',' || r == ' ' }) {
+			if strings.EqualFold(id, dependsOn) {
+				return nil
+			}
+		}
+		lines[i] = trimmed + ", " + dependsOn
+		return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+	}
+
+	lines = append(lines[:storyStart+1], append([]string{"**Depends on:** " + dependsOn}, lines[storyStart+1:]...)...)
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+}
+
+// SetStoryPriorities sets the **Priority:** line of each story in a prd.md
+// file to its position in ids, from 1, so the agent picks them in that order.
+// A story without the line gets one after its heading and status.
+func SetStoryPriorities(path string, ids []string) error {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read PRD file: %w", err)
+	}
+
+	result, err := setStoryPrioritiesInString(string(data), ids)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, []byte(result), 0644)
+}
+
+// setStoryPrioritiesInString performs the priority update on a string and returns the modified string.
+func setStoryPrioritiesInString(content string, ids []string) (string, error) {
+	lines := strings.Split(content, "\n")
+	for n, storyID := range ids {
+		start, end := storyBlock(lines, storyID)
+		if start == -1 {
+			return "", fmt.Errorf("story %s not found in PRD", storyID)
+		}
+
+		priorityLine := fmt.Sprintf("**Priority:** %d", n+1)
+		insertAt := start + 1
+		replaced := false
+		for i := start + 1; i < end; i++ {
+			trimmed := strings.TrimSpace(lines[i])
+			if priorityLineRegex.MatchString(trimmed) {
+				lines[i] = priorityLine
+				replaced = true
+				break
+			}
+			if statusLineRegex.MatchString(trimmed) {
+				insertAt = i + 1
+			}
+		}
+		if !replaced {
+			lines = append(lines[:insertAt], append([]string{priorityLine}, lines[insertAt:]...)...)
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// storyBlock returns the line index of a story's heading and of the first line
+// after its block, or -1 and 0 when the story isn't in lines.
+func storyBlock(lines []string, storyID string) (start, end int) {
+	headingPattern := regexp.MustCompile(`^#{3,4}\s+` + regexp.QuoteMeta(storyID) + `:\s+`)
+	for i, line := range lines {
+		if headingPattern.MatchString(strings.TrimSpace(line)) {
+			start = i
+			for j := i + 1; j < len(lines); j++ {
+				trimmed := strings.TrimSpace(lines[j])
+				if strings.HasPrefix(trimmed, "## ") || strings.HasPrefix(trimmed, "### ") || strings.HasPrefix(trimmed, "#### ") {
+					return start, j
+				}
+			}
+			return start, len(lines)
+		}
+	}
+	return -1, 0
+}
@@ -268,3 +268,75 @@ A description.
 		t.Error("US-001 should not be in-progress")
 	}
 }
+
+func TestAddStoryDependency(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prd.md")
+	md := "# P\n\n### US-001: First\n**Status:** in-progress\n- [ ] A\n\n### US-002: Second\n**Depends on:** US-001\n- [ ] B\n"
+	if err := os.WriteFile(path, []byte(md), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, step := range []struct{ story, dep string }{{"US-001", "US-009"}, {"US-002", "US-009"}, {"US-002", "us-009"}} {
+		if err := AddStoryDependency(path, step.story, step.dep); err != nil {
+			t.Fatalf("AddStoryDependency(%s, %s) error = %v", step.story, step.dep, err)
+		}
+	}
+	p, err := LoadPRD(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(p.UserStories[0].DependsOn, ","); got != "US-009" {
+		t.Errorf("US-001 DependsOn = %q, want US-009", got)
+	}
+	if got := strings.Join(p.UserStories[1].DependsOn, ","); got != "US-001,US-009" {
+		t.Errorf("US-002 DependsOn = %q, want US-001,US-009", got)
+	}
+	if err := AddStoryDependency(path, "US-404", "US-009"); err == nil {
+		t.Error("expected an error for a missing story")
+	}
+}
+
+func TestSetStoryPrioritiesInString(t *testing.T) {
+	md := `# P
+
+### US-001: First
+**Status:** done
+**Priority:** 1
+- [x] A
+
+### US-002: Second
+**Status:** skipped
+- [ ] B
+
+### US-003: Third
+- [ ] C
+`
+	result, err := setStoryPrioritiesInString(md, []string{"US-003", "US-001", "US-002"})
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	p, err := ParseMarkdownPRDFromString(result)
+	if err != nil {
+		t.Fatalf("parse error = %v", err)
+	}
+	want := map[string]float64{"US-003": 1, "US-001": 2, "US-002": 3}
+	for _, s := range p.UserStories {
+		if s.Priority != want[s.ID] {
+			t.Errorf("%s priority = %g, want %g", s.ID, s.Priority, want[s.ID])
+		}
+	}
+	if !p.UserStories[1].Skipped {
+		t.Error("US-002 lost its skipped status")
+	}
+	if !strings.Contains(result, "**Status:** skipped\n**Priority:** 3\n") {
+		t.Errorf("priority not inserted after the status line:\n%s", result)
+	}
+	if next := p.NextStory(); next == nil || next.ID != "US-003" {
+		t.Errorf("NextStory() = %v, want US-003", next)
+	}
+
+	if _, err := setStoryPrioritiesInString(md, []string{"US-999"}); err == nil {
+		t.Error("expected error for a missing story")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -54,6 +55,62 @@ func TestLoadPRD_FileNotFound(t *testing.T) {
 	}
 }
 
+func TestLoadPRD_CrossPRDDependencies(t *testing.T) {
+	prds := t.TempDir()
+	write := func(name, md string) string {
+		t.Helper()
+		path := filepath.Join(prds, name, "prd.md")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(md), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	authPath := write("auth", "# Auth\n\n### US-004: Sessions\n- [ ] Works\n")
+	billingPath := write("billing", `# Billing
+
+### US-009: Plans
+- [ ] Works
+
+### US-010: Invoices
+**Depends on:** auth/US-004, billing/US-009
+- [ ] Works
+
+### US-011: Refunds
+**Depends on:** auth/US-099
+- [ ] Works
+`)
+
+	p, err := LoadPRD(billingPath)
+	if err != nil {
+		t.Fatalf("LoadPRD failed: %v", err)
+	}
+	invoices := &p.UserStories[1]
+	if got := strings.Join(invoices.DependsOn, ","); got != "auth/US-004,US-009" {
+		t.Errorf("DependsOn = %s, want the own-PRD dependency unqualified", got)
+	}
+	if got := p.BlockedBy(invoices); strings.Join(got, ",") != "auth/US-004,US-009" {
+		t.Errorf("BlockedBy() = %v, want auth/US-004 and US-009", got)
+	}
+	if got := p.ExternalBlockers(); strings.Join(got, ",") != "auth/US-004,auth/US-099" {
+		t.Errorf("ExternalBlockers() = %v", got)
+	}
+	err = p.Validate()
+	if err == nil || !strings.Contains(err.Error(), `auth/US-099, which isn't a story in PRD "auth"`) {
+		t.Errorf("Validate() = %v, want the missing auth/US-099 reported", err)
+	}
+
+	if err := SetStoryStatus(authPath, "US-004", "done"); err != nil {
+		t.Fatal(err)
+	}
+	p, _ = LoadPRD(billingPath)
+	if got := p.BlockedBy(&p.UserStories[1]); strings.Join(got, ",") != "US-009" {
+		t.Errorf("BlockedBy() after auth/US-004 passed = %v, want US-009", got)
+	}
+}
+
 func TestPRD_AllComplete_EmptyPRD(t *testing.T) {
 	p := &PRD{
 		Project:     "Empty",
@@ -197,6 +254,83 @@ func TestPRD_NextStory_InterruptedTakesPrecedence(t *testing.T) {
 	}
 }
 
+func TestPRD_NextStory_SkipsHumanAndBlocked(t *testing.T) {
+	p := &PRD{
+		Project: "Test",
+		UserStories: []UserStory{
+			{ID: "US-001", Priority: 1, Owner: OwnerHuman},
+			{ID: "US-002", Priority: 2, DependsOn: []string{"US-001"}},
+			{ID: "US-003", Priority: 3},
+		},
+	}
+
+	if next := p.NextStory(); next == nil || next.ID != "US-003" {
+		t.Fatalf("expected US-003, got %+v", next)
+	}
+	if blocked := p.BlockedBy(&p.UserStories[1]); len(blocked) != 1 || blocked[0] != "US-001" {
+		t.Errorf("BlockedBy(US-002) = %v, want [US-001]", blocked)
+	}
+
+	p.UserStories[2].Passes = true
+	if next := p.NextStory(); next != nil {
+		t.Errorf("expected no story while waiting on a human, got %s", next.ID)
+	}
+	if waiting := p.WaitingOnHumans(); len(waiting) != 1 || waiting[0].ID != "US-001" {
+		t.Errorf("WaitingOnHumans() = %v, want [US-001]", waiting)
+	}
+
+	p.UserStories[0].Passes = true
+	if next := p.NextStory(); next == nil || next.ID != "US-002" {
+		t.Errorf("expected US-002 once US-001 is done, got %+v", next)
+	}
+}
+
+func TestPRD_NextStory_SkipsNeedsReview(t *testing.T) {
+	p, err := ParseMarkdownPRDFromString("# P\n\n### US-001: Gave up\n**Status:** needs-review\n\n### US-002: Next\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.UserStories[0].NeedsReview {
+		t.Fatal("expected needs-review to be parsed")
+	}
+	if next := p.NextStory(); next == nil || next.ID != "US-002" {
+		t.Fatalf("expected US-002, got %+v", next)
+	}
+	p.UserStories[1].Passes = true
+	if next := p.NextStory(); next != nil {
+		t.Errorf("expected no story left for the agent, got %s", next.ID)
+	}
+	if review := p.NeedingReview(); len(review) != 1 || review[0].ID != "US-001" {
+		t.Errorf("NeedingReview() = %v, want [US-001]", review)
+	}
+	if p.AllComplete() {
+		t.Error("expected a story needing review to keep the PRD incomplete")
+	}
+}
+
+func TestPRD_DependencyCycle(t *testing.T) {
+	p := &PRD{
+		UserStories: []UserStory{
+			{ID: "US-001", Priority: 1},
+			{ID: "US-002", Priority: 2, DependsOn: []string{"US-001", "us-004"}},
+			{ID: "US-003", Priority: 3, DependsOn: []string{"US-002"}},
+			{ID: "US-004", Priority: 4, DependsOn: []string{"US-003"}},
+		},
+	}
+	want := "US-002 US-004 US-003 US-002"
+	if got := strings.Join(p.DependencyCycle(), " "); got != want {
+		t.Errorf("DependencyCycle() = %q, want %q", got, want)
+	}
+	if next := p.NextStory(); next == nil || next.ID != "US-001" {
+		t.Errorf("expected US-001 outside the cycle to still run, got %+v", next)
+	}
+
+	p.UserStories[2].Passes = true
+	if cycle := p.DependencyCycle(); cycle != nil {
+		t.Errorf("expected a finished story to break the cycle, got %v", cycle)
+	}
+}
+
 func TestUserStory_Fields(t *testing.T) {
 	story := UserStory{
 		ID:                 "US-TEST",
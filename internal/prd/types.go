@@ -18,6 +18,19 @@ type UserStory struct {
 	Priority           float64  `json:"priority"`
 	Passes             bool     `json:"passes"`
 	InProgress         bool     `json:"inProgress,omitempty"`
+	NeedsReview        bool     `json:"needsReview,omitempty"` // The agent used up its retries; skipped until a person looks at it
+	Skipped            bool     `json:"skipped,omitempty"`     // Set aside by a person; not worked on until its status is todo again
+	Owner              string   `json:"owner,omitempty"`       // OwnerHuman for tasks people do; "" for the agent
+	DependsOn          []string `json:"dependsOn,omitempty"`   // Stories that must be done before this one starts
+	URL                string   `json:"url,omitempty"`         // Page the story changes; enables browser tests during verification
+}
+
+// OwnerHuman marks a story as a task for a person rather than the agent.
+const OwnerHuman = "human"
+
+// IsHuman reports whether the story is a task for a person rather than the agent.
+func (s *UserStory) IsHuman() bool {
+	return s.Owner == OwnerHuman
 }
 
 // PRD represents a Product Requirements Document.
@@ -25,6 +38,26 @@ type PRD struct {
 	Project     string      `json:"project"`
 	Description string      `json:"description"`
 	UserStories []UserStory `json:"userStories"`
+
+	// External holds whether the stories of other PRDs that stories here
+	// depend on are done, keyed like "auth/US-004" with the ID upper-cased.
+	// LoadPRD fills it in; a story it couldn't find has no entry.
+	External map[string]bool `json:"-"`
+}
+
+// SplitDependency splits a **Depends on:** entry into the PRD it names and the
+// story ID. The PRD is "" for a story of the same PRD ("US-004"), and the
+// name before the slash for a story of another one ("auth/US-004").
+func SplitDependency(dep string) (prdName, storyID string) {
+	if i := strings.LastIndex(dep, "/"); i >= 0 {
+		return dep[:i], dep[i+1:]
+	}
+	return "", dep
+}
+
+// externalKey returns the External key of a story in another PRD.
+func externalKey(prdName, storyID string) string {
+	return prdName + "/" + strings.ToUpper(storyID)
 }
 
 // ExtractIDPrefix returns the ID prefix used by the stories in this PRD.
@@ -52,15 +85,147 @@ func (p *PRD) AllComplete() bool {
 	return true
 }
 
-// NextStory returns the next story to work on.
+// WaitingOnHumans returns the human stories that aren't done yet, in PRD order.
+func (p *PRD) WaitingOnHumans() []UserStory {
+	var waiting []UserStory
+	for _, story := range p.UserStories {
+		if story.IsHuman() && !story.Passes {
+			waiting = append(waiting, story)
+		}
+	}
+	return waiting
+}
+
+// NeedingReview returns the stories the agent gave up on, in PRD order.
+func (p *PRD) NeedingReview() []UserStory {
+	var stories []UserStory
+	for _, story := range p.UserStories {
+		if story.NeedsReview && !story.Passes {
+			stories = append(stories, story)
+		}
+	}
+	return stories
+}
+
+// SkippedStories returns the unfinished stories a person set aside, in PRD order.
+func (p *PRD) SkippedStories() []UserStory {
+	var stories []UserStory
+	for _, story := range p.UserStories {
+		if story.Skipped && !story.Passes {
+			stories = append(stories, story)
+		}
+	}
+	return stories
+}
+
+// BlockedBy returns the IDs of the stories s depends on that aren't done.
+// Unknown IDs don't block, except in another PRD: a story there blocks
+// until it is known to be done.
+func (p *PRD) BlockedBy(s *UserStory) []string {
+	var blocking []string
+	for _, id := range s.DependsOn {
+		if prdName, storyID := SplitDependency(id); prdName != "" {
+			if !p.External[externalKey(prdName, storyID)] {
+				blocking = append(blocking, id)
+			}
+			continue
+		}
+		for _, other := range p.UserStories {
+			if strings.EqualFold(other.ID, id) && !other.Passes {
+				blocking = append(blocking, other.ID)
+				break
+			}
+		}
+	}
+	return blocking
+}
+
+// ExternalBlockers returns the stories of other PRDs that unfinished agent
+// stories wait on, in PRD order without repeats.
+func (p *PRD) ExternalBlockers() []string {
+	seen := make(map[string]bool)
+	var blockers []string
+	for i := range p.UserStories {
+		s := &p.UserStories[i]
+		if s.Passes || s.IsHuman() {
+			continue
+		}
+		for _, id := range p.BlockedBy(s) {
+			if prdName, _ := SplitDependency(id); prdName != "" && !seen[strings.ToUpper(id)] {
+				seen[strings.ToUpper(id)] = true
+				blockers = append(blockers, id)
+			}
+		}
+	}
+	return blockers
+}
+
+// DependencyCycle returns the IDs of unfinished stories that depend on each
+// other in a loop, in dependency order with the first ID repeated at the end,
+// or nil when there is none. Stories in a cycle can never start.
+func (p *PRD) DependencyCycle() []string {
+	byID := make(map[string]*UserStory, len(p.UserStories))
+	for i := range p.UserStories {
+		byID[strings.ToUpper(p.UserStories[i].ID)] = &p.UserStories[i]
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(byID))
+	var path []string
+	var visit func(s *UserStory) []string
+	visit = func(s *UserStory) []string {
+		key := strings.ToUpper(s.ID)
+		switch state[key] {
+		case visited:
+			return nil
+		case visiting:
+			for i, id := range path {
+				if strings.EqualFold(id, s.ID) {
+					return append(append([]string{}, path[i:]...), s.ID)
+				}
+			}
+			return nil
+		}
+		state[key] = visiting
+		path = append(path, s.ID)
+		for _, id := range s.DependsOn {
+			if dep, ok := byID[strings.ToUpper(id)]; ok && !dep.Passes {
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[key] = visited
+		return nil
+	}
+
+	for i := range p.UserStories {
+		if s := &p.UserStories[i]; !s.Passes {
+			if cycle := visit(s); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// NextStory returns the next story for the agent to work on.
 // It returns:
-//   - First story with inProgress: true (interrupted story), or
-//   - Lowest priority story with passes: false, or
-//   - nil if all stories are complete
+//   - First agent story with inProgress: true (interrupted story), or
+//   - Lowest priority agent story with passes: false whose dependencies are done, or
+//   - nil if no agent story is left to work on
+//
+// Human stories are never returned; see WaitingOnHumans. Neither are stories
+// that need review or were skipped; see NeedingReview and SkippedStories.
 func (p *PRD) NextStory() *UserStory {
 	// First, check for any in-progress story (interrupted)
 	for i := range p.UserStories {
-		if p.UserStories[i].InProgress {
+		if p.UserStories[i].InProgress && !p.UserStories[i].IsHuman() {
 			return &p.UserStories[i]
 		}
 	}
@@ -69,7 +234,7 @@ func (p *PRD) NextStory() *UserStory {
 	var next *UserStory
 	for i := range p.UserStories {
 		story := &p.UserStories[i]
-		if !story.Passes {
+		if !story.Passes && !story.IsHuman() && !story.NeedsReview && !story.Skipped && len(p.BlockedBy(story)) == 0 {
 			if next == nil || story.Priority < next.Priority {
 				next = story
 			}
@@ -86,7 +251,12 @@ func (p *PRD) NextStoryContext() *string {
 	if story == nil {
 		return nil
 	}
+	result := StoryContext(story)
+	return &result
+}
 
+// StoryContext formats story for inlining into the agent prompt.
+func StoryContext(story *UserStory) string {
 	data, err := json.MarshalIndent(story, "", "  ")
 	if err != nil {
 		// Fallback to a simple text format
@@ -96,10 +266,7 @@ func (p *PRD) NextStoryContext() *string {
 		for _, ac := range story.AcceptanceCriteria {
 			fmt.Fprintf(&b, "- %s\n", ac)
 		}
-		result := b.String()
-		return &result
+		return b.String()
 	}
-
-	result := string(data)
-	return &result
+	return string(data)
 }
@@ -0,0 +1,110 @@
+package prd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// storyIDRegex is the format of a story ID: a prefix of letters, a hyphen,
+// and a number, e.g. "US-001".
+var storyIDRegex = regexp.MustCompile(`^[A-Za-z]+-\d+$`)
+
+// Problem is one way a PRD doesn't match the format Chief expects.
+type Problem struct {
+	StoryID string // The story it concerns ("" = the PRD as a whole)
+	Message string
+}
+
+// String describes the problem, prefixed with its story.
+func (p Problem) String() string {
+	if p.StoryID == "" {
+		return p.Message
+	}
+	return p.StoryID + ": " + p.Message
+}
+
+// ValidationError is returned by Validate for a PRD with problems.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Problems) == 1 {
+		return "invalid PRD: " + e.Problems[0].String()
+	}
+	return fmt.Sprintf("invalid PRD: %d problems, the first: %s", len(e.Problems), e.Problems[0])
+}
+
+// Validate checks the PRD against the format Chief expects: every story has
+// an ID like "US-001" that no other story uses, a title, and acceptance
+// criteria, and depends only on stories that exist, without a cycle. Stories
+// of other PRDs ("auth/US-004") are only checked in a PRD read with LoadPRD.
+// It returns a *ValidationError listing the problems, or nil.
+func (p *PRD) Validate() error {
+	var problems []Problem
+	add := func(storyID, format string, args ...any) {
+		problems = append(problems, Problem{StoryID: storyID, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(p.Project) == "" {
+		add("", "missing the project name (a \"# PRD: Name\" heading)")
+	}
+
+	ids := make(map[string]int, len(p.UserStories))
+	for _, s := range p.UserStories {
+		ids[strings.ToUpper(s.ID)]++
+	}
+	reported := make(map[string]bool)
+	for _, s := range p.UserStories {
+		key := strings.ToUpper(s.ID)
+		switch {
+		case s.ID == "":
+			add("", "a story has no ID")
+		case !storyIDRegex.MatchString(s.ID):
+			add(s.ID, "ID doesn't look like \"US-001\"")
+		case ids[key] > 1 && !reported[key]:
+			add(s.ID, "ID is used by %d stories", ids[key])
+			reported[key] = true
+		}
+		if strings.TrimSpace(s.Title) == "" {
+			add(s.ID, "missing a title")
+		}
+		if len(s.AcceptanceCriteria) == 0 {
+			add(s.ID, "no acceptance criteria")
+		}
+		for _, dep := range s.DependsOn {
+			prdName, depID := SplitDependency(dep)
+			switch {
+			case prdName != "":
+				if _, ok := p.External[externalKey(prdName, depID)]; !ok && p.External != nil {
+					add(s.ID, "depends on %s, which isn't a story in PRD %q", dep, prdName)
+				}
+			case strings.EqualFold(dep, s.ID):
+				add(s.ID, "depends on itself")
+			case ids[strings.ToUpper(dep)] == 0:
+				add(s.ID, "depends on %s, which isn't a story in this PRD", dep)
+			}
+		}
+	}
+
+	// Stories that depend on themselves are reported above, so look past them
+	others := &PRD{UserStories: make([]UserStory, len(p.UserStories))}
+	for i, s := range p.UserStories {
+		s.DependsOn = nil
+		for _, dep := range p.UserStories[i].DependsOn {
+			if !strings.EqualFold(dep, s.ID) {
+				s.DependsOn = append(s.DependsOn, dep)
+			}
+		}
+		others.UserStories[i] = s
+	}
+	if cycle := others.DependencyCycle(); len(cycle) > 0 {
+		add("", "stories depend on each other in a cycle: %s", strings.Join(cycle, " → "))
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: problems}
+}
@@ -0,0 +1,55 @@
+package prd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidate_Valid(t *testing.T) {
+	p := &PRD{
+		Project: "Test",
+		UserStories: []UserStory{
+			{ID: "US-001", Title: "First", AcceptanceCriteria: []string{"Works"}},
+			{ID: "US-002", Title: "Second", AcceptanceCriteria: []string{"Works"}, DependsOn: []string{"us-001"}},
+		},
+	}
+	if err := p.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func TestValidate_Problems(t *testing.T) {
+	p := &PRD{
+		UserStories: []UserStory{
+			{ID: "US-001", Title: "First", AcceptanceCriteria: []string{"Works"}, DependsOn: []string{"US-009"}},
+			{ID: "US-001", Title: "Again", AcceptanceCriteria: []string{"Works"}},
+			{ID: "Story1", Title: "Bad ID", AcceptanceCriteria: []string{"Works"}},
+			{ID: "US-003", Title: "", DependsOn: []string{"US-003"}},
+			{ID: "US-004", Title: "Loop", AcceptanceCriteria: []string{"Works"}, DependsOn: []string{"US-005"}},
+			{ID: "US-005", Title: "Loop", AcceptanceCriteria: []string{"Works"}, DependsOn: []string{"US-004"}},
+		},
+	}
+	err := p.Validate()
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Validate() = %v, want a *ValidationError", err)
+	}
+	var got []string
+	for _, problem := range invalid.Problems {
+		got = append(got, problem.String())
+	}
+	want := []string{
+		`missing the project name (a "# PRD: Name" heading)`,
+		"US-001: ID is used by 2 stories",
+		"US-001: depends on US-009, which isn't a story in this PRD",
+		`Story1: ID doesn't look like "US-001"`,
+		"US-003: missing a title",
+		"US-003: no acceptance criteria",
+		"US-003: depends on itself",
+		"stories depend on each other in a cycle: US-004 → US-005 → US-004",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("problems =\n%q\nwant\n%q", got, want)
+	}
+}
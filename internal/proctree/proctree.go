@@ -0,0 +1,141 @@
+// Package proctree lists processes with their CPU and memory use, and ends
+// them cleanly, so Chief can show and manage the agents and commands it
+// started. It reads the process table with ps, which behaves the same on
+// Linux and macOS.
+package proctree
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Process is one entry of the process table.
+type Process struct {
+	PID     int
+	PPID    int
+	CPU     float64 // Percent of one core
+	RSS     int64   // Resident memory in bytes
+	Elapsed string  // Running time as ps reports it, e.g. "01:02:03"
+	Command string  // Command line
+}
+
+// Node is a process in a tree, Depth levels below its root.
+type Node struct {
+	Process
+	Depth int
+}
+
+// List returns the processes running on this machine.
+func List() ([]Process, error) {
+	out, err := exec.Command("ps", "-A", "-o", "pid=", "-o", "ppid=", "-o", "pcpu=", "-o", "rss=", "-o", "etime=", "-o", "args=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+	return parsePS(string(out)), nil
+}
+
+// parsePS parses ps output with the columns List asks for. Lines that don't
+// parse are skipped.
+func parsePS(out string) []Process {
+	var procs []Process
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		cpu, err3 := strconv.ParseFloat(fields[2], 64)
+		rss, err4 := strconv.ParseInt(fields[3], 10, 64)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+			continue
+		}
+		procs = append(procs, Process{
+			PID:     pid,
+			PPID:    ppid,
+			CPU:     cpu,
+			RSS:     rss * 1024,
+			Elapsed: fields[4],
+			Command: strings.Join(fields[5:], " "),
+		})
+	}
+	return procs
+}
+
+// Tree returns root and its descendants, depth first with children in PID
+// order. It returns nil when root isn't running.
+func Tree(procs []Process, root int) []Node {
+	children := make(map[int][]Process)
+	var rootProc *Process
+	for i := range procs {
+		if procs[i].PID == root {
+			rootProc = &procs[i]
+		} else {
+			children[procs[i].PPID] = append(children[procs[i].PPID], procs[i])
+		}
+	}
+	if rootProc == nil {
+		return nil
+	}
+
+	var nodes []Node
+	var walk func(p Process, depth int)
+	walk = func(p Process, depth int) {
+		nodes = append(nodes, Node{Process: p, Depth: depth})
+		kids := children[p.PID]
+		sort.Slice(kids, func(i, j int) bool { return kids[i].PID < kids[j].PID })
+		for _, kid := range kids {
+			walk(kid, depth+1)
+		}
+	}
+	walk(*rootProc, 0)
+	return nodes
+}
+
+// Descends reports whether pid is a descendant of ancestor, not counting
+// ancestor itself.
+func Descends(procs []Process, pid, ancestor int) bool {
+	parent := make(map[int]int, len(procs))
+	for _, p := range procs {
+		parent[p.PID] = p.PPID
+	}
+	for seen := 0; seen < len(procs); seen++ {
+		ppid, ok := parent[pid]
+		if !ok || ppid == pid {
+			return false
+		}
+		if ppid == ancestor {
+			return true
+		}
+		pid = ppid
+	}
+	return false
+}
+
+// Terminate asks pid to exit with SIGTERM and kills it if it is still
+// running after grace.
+func Terminate(pid int, grace time.Duration) error {
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+		if errors.Is(err, syscall.ESRCH) {
+			return nil
+		}
+		return fmt.Errorf("failed to stop process %d: %w", pid, err)
+	}
+	deadline := time.Now().Add(grace)
+	for time.Now().Before(deadline) {
+		if syscall.Kill(pid, 0) != nil {
+			return nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if err := syscall.Kill(pid, syscall.SIGKILL); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return fmt.Errorf("failed to kill process %d: %w", pid, err)
+	}
+	return nil
+}
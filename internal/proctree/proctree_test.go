@@ -0,0 +1,77 @@
+package proctree
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestParsePS(t *testing.T) {
+	out := "    1     0  0.0  1024 10-01:02:03 /sbin/init\n" +
+		"  200     1 12.5 20480    05:06 claude -p do the thing\n" +
+		"  bad line\n"
+	procs := parsePS(out)
+	if len(procs) != 2 {
+		t.Fatalf("expected 2 processes, got %+v", procs)
+	}
+	p := procs[1]
+	if p.PID != 200 || p.PPID != 1 || p.CPU != 12.5 || p.RSS != 20480*1024 || p.Elapsed != "05:06" || p.Command != "claude -p do the thing" {
+		t.Errorf("unexpected process: %+v", p)
+	}
+}
+
+func TestTreeAndDescends(t *testing.T) {
+	procs := []Process{
+		{PID: 10, PPID: 1, Command: "chief"},
+		{PID: 30, PPID: 10, Command: "sh -c go test ./..."},
+		{PID: 20, PPID: 10, Command: "claude"},
+		{PID: 21, PPID: 20, Command: "npm test"},
+		{PID: 99, PPID: 1, Command: "other"},
+	}
+
+	var got []int
+	for _, n := range Tree(procs, 10) {
+		got = append(got, n.PID*10+n.Depth)
+	}
+	want := []int{100, 201, 212, 301}
+	if len(got) != len(want) {
+		t.Fatalf("Tree() = %v, want %v (pid*10+depth)", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Tree() = %v, want %v (pid*10+depth)", got, want)
+		}
+	}
+	if Tree(procs, 42) != nil {
+		t.Error("expected nil for a process that isn't running")
+	}
+
+	if !Descends(procs, 21, 10) || !Descends(procs, 21, 20) {
+		t.Error("expected 21 to descend from 20 and 10")
+	}
+	if Descends(procs, 10, 10) || Descends(procs, 99, 10) {
+		t.Error("expected neither chief itself nor an unrelated process to descend from chief")
+	}
+}
+
+func TestTerminate(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skip("sleep not available")
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	if err := Terminate(cmd.Process.Pid, time.Second); err != nil {
+		t.Fatalf("Terminate() error = %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the process to exit")
+	}
+	if err := Terminate(os.Getpid()+1_000_000, 0); err != nil {
+		t.Errorf("expected no error for a process that is gone, got %v", err)
+	}
+}
@@ -0,0 +1,216 @@
+// Package prompt runs the agent prompt through a chain of middlewares before
+// each loop iteration. Middlewares add or rewrite parts of the prompt, such as
+// the project's conventions or a map of the repository, so prompting can be
+// customized from config without changing the embedded templates.
+package prompt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/minicodemonkey/chief/embed"
+	"github.com/minicodemonkey/chief/internal/git"
+)
+
+// ConventionsFile holds the project's conventions, relative to the project root.
+const ConventionsFile = ".chief/conventions.md"
+
+// maxRepoMapFiles caps the files listed by the repomap middleware, so a large
+// repository doesn't crowd out the rest of the prompt.
+const maxRepoMapFiles = 300
+
+// DefaultMiddlewares is the chain used when none is configured.
+var DefaultMiddlewares = []string{"language"}
+
+// Middleware transforms the prompt.
+type Middleware interface {
+	Name() string
+	Apply(ctx context.Context, prompt string) (string, error)
+}
+
+// Options holds what the built-in middlewares need to know about the run.
+type Options struct {
+	BaseDir  string // Project root, where .chief/ lives
+	WorkDir  string // Directory the agent works in
+	Language string // Language the agent writes prose in ("" = English)
+}
+
+// Chain is a list of middlewares applied in order.
+type Chain []Middleware
+
+// Apply runs the prompt through every middleware in order.
+func (c Chain) Apply(ctx context.Context, prompt string) (string, error) {
+	for _, m := range c {
+		var err error
+		prompt, err = m.Apply(ctx, prompt)
+		if err != nil {
+			return "", fmt.Errorf("prompt middleware %s: %w", m.Name(), err)
+		}
+	}
+	return prompt, nil
+}
+
+// Validate reports the first middleware name New would reject.
+func Validate(names []string) error {
+	_, err := New(names, Options{})
+	return err
+}
+
+// New returns the chain for the configured middleware names. An empty list
+// returns the DefaultMiddlewares chain.
+func New(names []string, opts Options) (Chain, error) {
+	if len(names) == 0 {
+		names = DefaultMiddlewares
+	}
+	chain := make(Chain, 0, len(names))
+	for _, name := range names {
+		switch {
+		case name == "language":
+			chain = append(chain, Language{Language: opts.Language})
+		case name == "conventions":
+			chain = append(chain, Conventions{Path: filepath.Join(opts.BaseDir, ConventionsFile)})
+		case name == "redact":
+			chain = append(chain, Redact{})
+		case name == "repomap":
+			chain = append(chain, RepoMap{Dir: opts.WorkDir})
+		case strings.HasPrefix(name, "command:"):
+			command := strings.TrimSpace(strings.TrimPrefix(name, "command:"))
+			if command == "" {
+				return nil, fmt.Errorf("middleware %q has no command", name)
+			}
+			chain = append(chain, Command{Command: command, Dir: opts.WorkDir})
+		default:
+			return nil, fmt.Errorf("unknown middleware %q: expected \"language\", \"conventions\", \"redact\", \"repomap\", or \"command:<command>\"", name)
+		}
+	}
+	return chain, nil
+}
+
+// appendSection adds a section to the end of the prompt.
+func appendSection(prompt, section string) string {
+	if section == "" {
+		return prompt
+	}
+	return prompt + "\n\n" + section
+}
+
+// Language asks the agent to write prose in another language.
+type Language struct {
+	Language string
+}
+
+// Name implements Middleware.
+func (Language) Name() string { return "language" }
+
+// Apply implements Middleware.
+func (m Language) Apply(_ context.Context, prompt string) (string, error) {
+	return appendSection(prompt, embed.GetLanguagePrompt(m.Language)), nil
+}
+
+// Conventions adds the project's conventions file. A missing file adds nothing.
+type Conventions struct {
+	Path string
+}
+
+// Name implements Middleware.
+func (Conventions) Name() string { return "conventions" }
+
+// Apply implements Middleware.
+func (m Conventions) Apply(_ context.Context, prompt string) (string, error) {
+	data, err := os.ReadFile(m.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return prompt, nil
+		}
+		return "", err
+	}
+	return appendSection(prompt, embed.GetConventionsPrompt(string(data))), nil
+}
+
+// RepoMap adds a list of the files tracked in the repository. Outside a git
+// repository it adds nothing.
+type RepoMap struct {
+	Dir string
+}
+
+// Name implements Middleware.
+func (RepoMap) Name() string { return "repomap" }
+
+// Apply implements Middleware.
+func (m RepoMap) Apply(_ context.Context, prompt string) (string, error) {
+	files, err := git.TrackedFiles(m.Dir)
+	if err != nil {
+		return prompt, nil
+	}
+	if len(files) > maxRepoMapFiles {
+		more := len(files) - maxRepoMapFiles
+		files = append(files[:maxRepoMapFiles:maxRepoMapFiles], fmt.Sprintf("... and %d more", more))
+	}
+	return appendSection(prompt, embed.GetRepoMapPrompt(files)), nil
+}
+
+// secretPatterns match credentials that may end up in the prompt, for
+// example in test output fed back after a failed verification.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`),
+	regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,}\b`),
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`),
+	regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`),
+}
+
+// secretAssignment matches values assigned to secret-sounding names, such as
+// PASSWORD=hunter22 or "api_key": "abc123". The name is kept.
+var secretAssignment = regexp.MustCompile(`(?i)\b((?:[a-z0-9_]*_)?(?:password|passwd|secret|token|api[_-]?key))("?\s*[:=]\s*"?)([^\s"',]{6,})`)
+
+// Redacted replaces each secret removed by the redact middleware.
+const Redacted = "[REDACTED]"
+
+// Redact removes secrets from the prompt.
+type Redact struct{}
+
+// Name implements Middleware.
+func (Redact) Name() string { return "redact" }
+
+// Apply implements Middleware.
+func (Redact) Apply(_ context.Context, prompt string) (string, error) {
+	for _, re := range secretPatterns {
+		prompt = re.ReplaceAllString(prompt, Redacted)
+	}
+	return secretAssignment.ReplaceAllString(prompt, "${1}${2}"+Redacted), nil
+}
+
+// Command is a middleware backed by an external program, so any rewriting can
+// be plugged in. The program is run with `sh -c` in the agent's working
+// directory, receives the prompt on stdin, and must print the new prompt.
+type Command struct {
+	Command string
+	Dir     string
+}
+
+// Name implements Middleware.
+func (m Command) Name() string { return "command:" + m.Command }
+
+// Apply implements Middleware.
+func (m Command) Apply(ctx context.Context, prompt string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", m.Command)
+	cmd.Dir = m.Dir
+	cmd.Stdin = strings.NewReader(prompt)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if strings.TrimSpace(stdout.String()) == "" {
+		return "", fmt.Errorf("command printed an empty prompt")
+	}
+	return stdout.String(), nil
+}
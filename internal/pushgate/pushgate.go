@@ -0,0 +1,285 @@
+// Package pushgate limits how often the agent's pushes reach the remote, so
+// a long run doesn't trigger CI dozens of times an hour. Agent commands get a
+// git wrapper first on PATH that sends every `git push` through `chief
+// push-gate`. Force pushes are refused. A push that comes too soon after the
+// last one is held back, and Chief pushes the branch itself once the policy
+// allows it.
+package pushgate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/git"
+	"github.com/minicodemonkey/chief/internal/timefmt"
+)
+
+// Environment variables that pass the policy to `chief push-gate` when the
+// agent runs git.
+const (
+	StateEnv    = "CHIEF_PUSH_STATE"     // Path of the state file
+	EveryEnv    = "CHIEF_PUSH_EVERY"     // Minimum time between pushes, as a Go duration
+	PerStoryEnv = "CHIEF_PUSH_PER_STORY" // "1" holds every push until the story is done
+	GitEnv      = "CHIEF_GIT"            // The real git binary
+)
+
+// Marker starts every line Chief prints about a push.
+const Marker = "[chief push]"
+
+// StateFile holds when the agent's branch was last pushed, inside the PRD
+// directory.
+const StateFile = "push.json"
+
+// Policy limits how often the agent's pushes reach the remote.
+type Policy struct {
+	Every     time.Duration // Minimum time between pushes (0 = no limit)
+	PerStory  bool          // Hold every push until the story is done, then push once
+	StatePath string        // Where the time of the last push is kept
+	BinDir    string        // Where the git wrapper is written
+}
+
+// State records the pushes of a PRD's run.
+type State struct {
+	Last    time.Time `json:"last,omitempty"`    // When the branch was last pushed
+	Pending bool      `json:"pending,omitempty"` // A push was held back and not made yet
+}
+
+// BinDir returns the directory of a project's git wrapper.
+func BinDir(baseDir string) string {
+	return filepath.Join(baseDir, ".chief", "bin")
+}
+
+// LoadState reads the state at path. A missing file is an empty state.
+func LoadState(path string) State {
+	var s State
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &s)
+	}
+	return s
+}
+
+// Save writes the state to path.
+func (s State) Save(path string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Due returns true if a push may be made now. storyDone says whether the
+// agent's story has just been marked done.
+func (p Policy) Due(s State, now time.Time, storyDone bool) bool {
+	if p.PerStory && !storyDone {
+		return false
+	}
+	return p.Every <= 0 || s.Last.IsZero() || now.Sub(s.Last) >= p.Every
+}
+
+// Apply routes the git commands cmd runs through the gate by writing the
+// wrapper and putting it first on cmd's PATH.
+func (p Policy) Apply(cmd *exec.Cmd) error {
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	path := ""
+	for _, kv := range cmd.Env {
+		if v, ok := strings.CutPrefix(kv, "PATH="); ok {
+			path = v
+		}
+	}
+	realGit, err := lookPath("git", path, p.BinDir)
+	if err != nil {
+		return fmt.Errorf("failed to find git: %w", err)
+	}
+	chief, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if err := writeWrapper(p.BinDir, chief); err != nil {
+		return fmt.Errorf("failed to write the git wrapper: %w", err)
+	}
+	cmd.Env = append(cmd.Env,
+		"PATH="+p.BinDir+string(os.PathListSeparator)+path,
+		GitEnv+"="+realGit,
+		StateEnv+"="+p.StatePath,
+		EveryEnv+"="+p.Every.String(),
+	)
+	if p.PerStory {
+		cmd.Env = append(cmd.Env, PerStoryEnv+"=1")
+	}
+	return nil
+}
+
+// Flush pushes the current branch of dir if a push was held back and the
+// policy now allows one. It returns the branch pushed, or "" when nothing was
+// pushed.
+func (p Policy) Flush(dir string, storyDone bool) (string, error) {
+	s := LoadState(p.StatePath)
+	if !s.Pending || !p.Due(s, time.Now(), storyDone) {
+		return "", nil
+	}
+	branch, err := git.GetCurrentBranch(dir)
+	if err != nil {
+		return "", err
+	}
+	if err := pushBranch(dir, branch); err != nil {
+		return "", err
+	}
+	s.Last = time.Now()
+	s.Pending = false
+	return branch, s.Save(p.StatePath)
+}
+
+// pushBranch pushes a branch to origin (replaceable in tests).
+var pushBranch = git.PushBranch
+
+// Run is `chief push-gate`: it runs git with args, applying the policy from
+// the environment to pushes, and returns git's exit code.
+func Run(args []string) int {
+	realGit := os.Getenv(GitEnv)
+	if realGit == "" {
+		fmt.Fprintf(os.Stderr, "%s %s is not set; push-gate only runs as Chief's git wrapper\n", Marker, GitEnv)
+		return 1
+	}
+	push, force := IsPush(args)
+	if !push {
+		return runGit(realGit, args)
+	}
+	if force {
+		fmt.Fprintf(os.Stderr, "%s Force pushes and deleting remote branches are not allowed during a Chief run. Push without --force, or leave pushing to Chief.\n", Marker)
+		return 1
+	}
+
+	statePath := os.Getenv(StateEnv)
+	every, _ := time.ParseDuration(os.Getenv(EveryEnv))
+	p := Policy{Every: every, PerStory: os.Getenv(PerStoryEnv) == "1", StatePath: statePath}
+	s := LoadState(statePath)
+	if !p.Due(s, time.Now(), false) {
+		s.Pending = true
+		if err := s.Save(statePath); err != nil {
+			fmt.Fprintf(os.Stderr, "%s Failed to record the held push: %v\n", Marker, err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "%s Push held back: %s. Keep committing; Chief pushes the branch %s.\n", Marker, p.describe(), p.when(s))
+		return 0
+	}
+
+	code := runGit(realGit, args)
+	if code == 0 {
+		s.Last = time.Now()
+		s.Pending = false
+		if err := s.Save(statePath); err != nil {
+			fmt.Fprintf(os.Stderr, "%s Failed to record the push: %v\n", Marker, err)
+		}
+	}
+	return code
+}
+
+// describe explains the policy to the agent.
+func (p Policy) describe() string {
+	if p.PerStory {
+		return "the branch is pushed once per story"
+	}
+	return fmt.Sprintf("pushes are limited to one every %d minutes", int(p.Every.Minutes()))
+}
+
+// when says when a held push will be made.
+func (p Policy) when(s State) string {
+	if p.PerStory {
+		return "when the story is done"
+	}
+	return "after " + timefmt.Clock(s.Last.Add(p.Every))
+}
+
+// globalArgOptions are git options before the subcommand that take a
+// separate value.
+var globalArgOptions = map[string]bool{
+	"-C": true, "-c": true, "--git-dir": true, "--work-tree": true,
+	"--namespace": true, "--exec-path": true, "--config-env": true,
+}
+
+// IsPush returns true if git args run `git push`, and whether the push
+// forces an update of the remote. Deleting remote refs, with --delete,
+// --prune, or a refspec like :branch, counts as forcing.
+func IsPush(args []string) (push, force bool) {
+	i := 0
+	for i < len(args) && strings.HasPrefix(args[i], "-") {
+		if globalArgOptions[args[i]] {
+			i++
+		}
+		i++
+	}
+	if i >= len(args) || args[i] != "push" {
+		return false, false
+	}
+	for _, arg := range args[i+1:] {
+		switch {
+		case arg == "--":
+		case arg == "--force" || arg == "--mirror" || strings.HasPrefix(arg, "--force-with-lease"):
+			return true, true
+		case arg == "--delete" || arg == "--prune":
+			return true, true
+		case strings.HasPrefix(arg, "--"):
+		case strings.HasPrefix(arg, "-"):
+			if strings.ContainsAny(arg, "fd") {
+				return true, true
+			}
+		case strings.HasPrefix(arg, "+"):
+			return true, true // A forced refspec
+		case strings.HasPrefix(arg, ":") && arg != ":":
+			return true, true // A refspec deleting the remote ref
+		}
+	}
+	return true, false
+}
+
+// runGit runs the real git with args and returns its exit code.
+func runGit(realGit string, args []string) int {
+	cmd := exec.Command(realGit, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintf(os.Stderr, "%s %v\n", Marker, err)
+		return 1
+	}
+	return 0
+}
+
+// writeWrapper writes the git wrapper script to dir, calling chief.
+func writeWrapper(dir, chief string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	script := fmt.Sprintf("#!/bin/sh\nexec '%s' push-gate \"$@\"\n", strings.ReplaceAll(chief, "'", `'\''`))
+	return os.WriteFile(filepath.Join(dir, "git"), []byte(script), 0755)
+}
+
+// lookPath finds file in the directories of path, which may differ from the
+// current process's PATH, skipping the wrapper's own directory.
+func lookPath(file, path, skip string) (string, error) {
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" || dir == skip {
+			continue
+		}
+		candidate := filepath.Join(dir, file)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() && info.Mode()&0111 != 0 {
+			return candidate, nil
+		}
+	}
+	return exec.LookPath(file)
+}
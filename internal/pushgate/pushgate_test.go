@@ -0,0 +1,165 @@
+package pushgate
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsPush(t *testing.T) {
+	tests := []struct {
+		args        []string
+		push, force bool
+	}{
+		{[]string{"status"}, false, false},
+		{[]string{"push"}, true, false},
+		{[]string{"push", "-u", "origin", "chief/auth"}, true, false},
+		{[]string{"-C", "sub", "push", "origin"}, true, false},
+		{[]string{"-c", "push.default=current", "push"}, true, false},
+		{[]string{"push", "--force"}, true, true},
+		{[]string{"push", "-f", "origin"}, true, true},
+		{[]string{"push", "-uf", "origin", "main"}, true, true},
+		{[]string{"push", "--force-with-lease=main"}, true, true},
+		{[]string{"push", "origin", "+main"}, true, true},
+		{[]string{"push", "--mirror"}, true, true},
+		{[]string{"push", "origin", "--delete", "main"}, true, true},
+		{[]string{"push", "-d", "origin", "main"}, true, true},
+		{[]string{"push", "-ud", "origin", "main"}, true, true},
+		{[]string{"push", "origin", ":main"}, true, true},
+		{[]string{"push", "origin", "chief/auth", ":main"}, true, true},
+		{[]string{"push", "--prune", "origin", "refs/heads/*:refs/heads/*"}, true, true},
+		{[]string{"push", "origin", ":"}, true, false},
+		{[]string{"push", "origin", "main:main"}, true, false},
+		{[]string{"log", "--", "push"}, false, false},
+	}
+	for _, tt := range tests {
+		push, force := IsPush(tt.args)
+		if push != tt.push || force != tt.force {
+			t.Errorf("IsPush(%q) = %v, %v; want %v, %v", tt.args, push, force, tt.push, tt.force)
+		}
+	}
+}
+
+func TestDue(t *testing.T) {
+	now := time.Now()
+	every := Policy{Every: 15 * time.Minute}
+	if !every.Due(State{}, now, false) {
+		t.Error("expected the first push to be due")
+	}
+	if every.Due(State{Last: now.Add(-5 * time.Minute)}, now, false) {
+		t.Error("expected a push 5 minutes after the last to be held")
+	}
+	if !every.Due(State{Last: now.Add(-20 * time.Minute)}, now, false) {
+		t.Error("expected a push 20 minutes after the last to be due")
+	}
+
+	perStory := Policy{PerStory: true}
+	if perStory.Due(State{}, now, false) {
+		t.Error("expected a push during a story to be held")
+	}
+	if !perStory.Due(State{}, now, true) {
+		t.Error("expected a push once the story is done to be due")
+	}
+}
+
+// initRepo creates a repository with a commit and a bare origin.
+func initRepo(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	origin := filepath.Join(root, "origin.git")
+	dir := filepath.Join(root, "repo")
+	for _, args := range [][]string{
+		{"init", "--bare", origin},
+		{"init", "-b", "main", dir},
+		{"-C", dir, "config", "user.email", "test@example.com"},
+		{"-C", dir, "config", "user.name", "Test"},
+		{"-C", dir, "remote", "add", "origin", origin},
+		{"-C", dir, "commit", "--allow-empty", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestRun(t *testing.T) {
+	dir := initRepo(t)
+	realGit, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not found")
+	}
+	statePath := filepath.Join(t.TempDir(), StateFile)
+	t.Setenv(GitEnv, realGit)
+	t.Setenv(StateEnv, statePath)
+	t.Setenv(EveryEnv, (15 * time.Minute).String())
+
+	if code := Run([]string{"-C", dir, "push", "--force", "origin", "main"}); code == 0 {
+		t.Error("expected a force push to be refused")
+	}
+	if !LoadState(statePath).Last.IsZero() {
+		t.Error("expected the refused push not to be recorded")
+	}
+
+	if code := Run([]string{"-C", dir, "push", "origin", "main"}); code != 0 {
+		t.Fatalf("expected the first push to go through, got exit code %d", code)
+	}
+	s := LoadState(statePath)
+	if s.Last.IsZero() || s.Pending {
+		t.Errorf("expected the push to be recorded, got %+v", s)
+	}
+
+	exec.Command("git", "-C", dir, "commit", "--allow-empty", "-m", "second").Run()
+	if code := Run([]string{"-C", dir, "push", "origin", "main"}); code != 0 {
+		t.Fatalf("expected a held push to exit 0, got %d", code)
+	}
+	if !LoadState(statePath).Pending {
+		t.Error("expected the second push to be held back")
+	}
+	head, _ := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	remote, _ := exec.Command("git", "-C", dir, "rev-parse", "origin/main").Output()
+	if strings.TrimSpace(string(head)) == strings.TrimSpace(string(remote)) {
+		t.Error("expected the second commit not to be pushed")
+	}
+}
+
+func TestFlush(t *testing.T) {
+	var pushed []string
+	orig := pushBranch
+	pushBranch = func(dir, branch string) error {
+		pushed = append(pushed, branch)
+		return nil
+	}
+	defer func() { pushBranch = orig }()
+
+	dir := initRepo(t)
+	statePath := filepath.Join(t.TempDir(), StateFile)
+	p := Policy{PerStory: true, StatePath: statePath}
+
+	if branch, err := p.Flush(dir, true); err != nil || branch != "" {
+		t.Errorf("expected nothing to push without a held push, got %q, %v", branch, err)
+	}
+
+	if err := (State{Pending: true}).Save(statePath); err != nil {
+		t.Fatal(err)
+	}
+	if branch, _ := p.Flush(dir, false); branch != "" {
+		t.Errorf("expected the push to wait for the story, got %q", branch)
+	}
+	branch, err := p.Flush(dir, true)
+	if err != nil || branch != "main" {
+		t.Fatalf("expected main to be pushed, got %q, %v", branch, err)
+	}
+	if len(pushed) != 1 {
+		t.Errorf("expected one push, got %v", pushed)
+	}
+	if s := LoadState(statePath); s.Pending || s.Last.IsZero() {
+		t.Errorf("expected the push to be recorded, got %+v", s)
+	}
+	if _, err := os.Stat(statePath); err != nil {
+		t.Error(err)
+	}
+}
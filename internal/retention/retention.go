@@ -0,0 +1,356 @@
+// Package retention removes run data older than the project's retention
+// settings, so long-lived workspaces don't grow without bound. Each kind of
+// data is counted in items: log files, undo entries, or runs recorded in a
+// PRD's event log or usage file.
+package retention
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/undo"
+)
+
+// Kinds of run data, in the order they are reported.
+const (
+	KindLogs        = "logs"
+	KindHistory     = "history"
+	KindTranscripts = "transcripts"
+	KindUsage       = "usage"
+)
+
+// Status is how much of one kind of run data a project holds.
+type Status struct {
+	Kind    string
+	Days    int       // How long it is kept (0 = forever)
+	Items   int       // Log files, undo entries, or runs
+	Bytes   int64     // Size on disk
+	Oldest  time.Time // Time of the oldest item (zero = none)
+	Expired int       // Items older than Days, removed by the next cleanup
+}
+
+// kind is one kind of run data.
+type kind struct {
+	name string
+	days func(cfg config.RetentionConfig) int
+	// scan returns the time of each item and the size of them all
+	scan func(baseDir string) ([]time.Time, int64)
+	// clean removes the items older than cutoff and returns how many it removed
+	clean func(baseDir string, cutoff time.Time) (int, error)
+}
+
+var kinds = []kind{
+	{KindLogs, func(c config.RetentionConfig) int { return c.LogDays }, scanLogs, cleanLogs},
+	{KindHistory, func(c config.RetentionConfig) int { return c.HistoryDays }, scanHistory, cleanHistory},
+	{KindTranscripts, func(c config.RetentionConfig) int { return c.TranscriptDays }, scanTranscripts, cleanTranscripts},
+	{KindUsage, func(c config.RetentionConfig) int { return c.UsageDays }, scanUsage, cleanUsage},
+}
+
+// Check reports how much of each kind of run data the project at baseDir
+// holds, and how much of it the next cleanup would remove.
+func Check(baseDir string, cfg config.RetentionConfig, now time.Time) []Status {
+	var statuses []Status
+	for _, k := range kinds {
+		times, size := k.scan(baseDir)
+		s := Status{Kind: k.name, Days: max(k.days(cfg), 0), Items: len(times), Bytes: size}
+		cutoff := now.AddDate(0, 0, -s.Days)
+		for _, t := range times {
+			if s.Oldest.IsZero() || t.Before(s.Oldest) {
+				s.Oldest = t
+			}
+			if s.Days > 0 && t.Before(cutoff) {
+				s.Expired++
+			}
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
+
+// Clean removes the run data of the project at baseDir that is older than
+// the retention settings allow, and returns how many items it removed. The
+// event log and usage file of a PRD that is running are left alone.
+func Clean(baseDir string, cfg config.RetentionConfig, now time.Time) (int, error) {
+	removed := 0
+	var errs []string
+	for _, k := range kinds {
+		days := k.days(cfg)
+		if days <= 0 {
+			continue
+		}
+		n, err := k.clean(baseDir, now.AddDate(0, 0, -days))
+		removed += n
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", k.name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return removed, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return removed, nil
+}
+
+// prdDirs returns the directories of the project's PRDs.
+func prdDirs(baseDir string) []string {
+	entries, err := os.ReadDir(filepath.Join(baseDir, ".chief", "prds"))
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, e := range entries {
+		if e.IsDir() {
+			dirs = append(dirs, filepath.Join(baseDir, ".chief", "prds", e.Name()))
+		}
+	}
+	return dirs
+}
+
+// logFiles returns the project's log files: the agent logs, crash reports
+// and verification output of each PRD, and the rotated events feed.
+func logFiles(baseDir string) []string {
+	files := []string{filepath.Join(baseDir, loop.FeedFile+".1")}
+	for _, dir := range prdDirs(baseDir) {
+		logs, _ := filepath.Glob(filepath.Join(dir, "*.log"))
+		verify, _ := filepath.Glob(filepath.Join(dir, loop.FailuresDir, "*.verify.log"))
+		files = append(files, logs...)
+		files = append(files, verify...)
+		files = append(files, filepath.Join(dir, loop.FailureFile))
+	}
+	return files
+}
+
+func scanLogs(baseDir string) ([]time.Time, int64) {
+	var times []time.Time
+	var size int64
+	for _, path := range logFiles(baseDir) {
+		if info, err := os.Stat(path); err == nil {
+			times = append(times, info.ModTime())
+			size += info.Size()
+		}
+	}
+	return times, size
+}
+
+// cleanLogs removes the log files not written to since cutoff.
+func cleanLogs(baseDir string, cutoff time.Time) (int, error) {
+	removed := 0
+	for _, path := range logFiles(baseDir) {
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func scanHistory(baseDir string) ([]time.Time, int64) {
+	entries, _ := undo.List(baseDir)
+	var times []time.Time
+	for _, e := range entries {
+		times = append(times, e.Time)
+	}
+	return times, dirSize(undo.Dir(baseDir))
+}
+
+// cleanHistory removes the undo entries taken before cutoff.
+func cleanHistory(baseDir string, cutoff time.Time) (int, error) {
+	entries, err := undo.List(baseDir)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, e := range entries {
+		if !e.Time.Before(cutoff) {
+			continue
+		}
+		if err := undo.Remove(baseDir, e.ID); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// dirSize returns the size of the files under dir.
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// logRun is one run in an event log: its start and its lines as written.
+type logRun struct {
+	start time.Time
+	lines [][]byte
+}
+
+// readLogRuns splits an event log into runs. Lines written before the first
+// run start count as part of the first run.
+func readLogRuns(path string) ([]*logRun, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var runs []*logRun
+	var leading [][]byte
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.Clone(scanner.Bytes())
+		var rec loop.EventRecord
+		if json.Unmarshal(line, &rec) == nil && rec.Type == loop.RunStartRecord {
+			runs = append(runs, &logRun{start: rec.Time, lines: append(leading, line)})
+			leading = nil
+		} else if len(runs) > 0 {
+			runs[len(runs)-1].lines = append(runs[len(runs)-1].lines, line)
+		} else {
+			leading = append(leading, line)
+		}
+	}
+	return runs, scanner.Err()
+}
+
+func scanTranscripts(baseDir string) ([]time.Time, int64) {
+	var times []time.Time
+	var size int64
+	for _, dir := range prdDirs(baseDir) {
+		path := filepath.Join(dir, loop.EventLogFile)
+		runs, err := readLogRuns(path)
+		if err != nil {
+			continue
+		}
+		for _, r := range runs {
+			times = append(times, r.start)
+		}
+		if info, err := os.Stat(path); err == nil {
+			size += info.Size()
+		}
+	}
+	return times, size
+}
+
+// cleanTranscripts drops the runs started before cutoff from each PRD's
+// event log. The log is removed once no run is left.
+func cleanTranscripts(baseDir string, cutoff time.Time) (int, error) {
+	removed := 0
+	for _, dir := range prdDirs(baseDir) {
+		if loop.ReadRunLock(dir) != nil {
+			continue
+		}
+		path := filepath.Join(dir, loop.EventLogFile)
+		runs, err := readLogRuns(path)
+		if err != nil {
+			continue
+		}
+		var kept bytes.Buffer
+		dropped := 0
+		for _, r := range runs {
+			if r.start.Before(cutoff) {
+				dropped++
+				continue
+			}
+			for _, line := range r.lines {
+				kept.Write(line)
+				kept.WriteByte('\n')
+			}
+		}
+		if dropped == 0 {
+			continue
+		}
+		if err := rewrite(path, kept.Bytes()); err != nil {
+			return removed, err
+		}
+		removed += dropped
+	}
+	return removed, nil
+}
+
+func scanUsage(baseDir string) ([]time.Time, int64) {
+	var times []time.Time
+	var size int64
+	for _, dir := range prdDirs(baseDir) {
+		path := filepath.Join(dir, loop.UsageFile)
+		report, err := loop.ReadUsage(path)
+		if err != nil {
+			continue
+		}
+		for _, r := range report.Runs {
+			times = append(times, r.Start)
+		}
+		if info, err := os.Stat(path); err == nil {
+			size += info.Size()
+		}
+	}
+	return times, size
+}
+
+// cleanUsage drops the runs started before cutoff from each PRD's usage
+// file. The totals per story and for the PRD are kept.
+func cleanUsage(baseDir string, cutoff time.Time) (int, error) {
+	removed := 0
+	for _, dir := range prdDirs(baseDir) {
+		if loop.ReadRunLock(dir) != nil {
+			continue
+		}
+		path := filepath.Join(dir, loop.UsageFile)
+		report, err := loop.ReadUsage(path)
+		if err != nil || len(report.Runs) == 0 {
+			continue
+		}
+		before := len(report.Runs)
+		kept := report.Runs[:0]
+		for _, r := range report.Runs {
+			if !r.Start.Before(cutoff) {
+				kept = append(kept, r)
+			}
+		}
+		if len(kept) == before {
+			continue
+		}
+		report.Runs = kept
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return removed, err
+		}
+		if err := rewrite(path, append(data, '\n')); err != nil {
+			return removed, err
+		}
+		removed += before - len(kept)
+	}
+	return removed, nil
+}
+
+// rewrite replaces the file at path with data, or removes it when data is
+// empty.
+func rewrite(path string, data []byte) error {
+	if len(data) == 0 {
+		return os.Remove(path)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
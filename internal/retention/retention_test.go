@@ -0,0 +1,161 @@
+package retention
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/config"
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/undo"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func jsonLine(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data) + "\n"
+}
+
+// setupProject creates a project with one old and one recent item of each
+// kind of run data.
+func setupProject(t *testing.T, now time.Time) string {
+	t.Helper()
+	dir := t.TempDir()
+	old, recent := now.AddDate(0, 0, -40), now.AddDate(0, 0, -1)
+	prdDir := filepath.Join(dir, ".chief", "prds", "auth")
+	writeFile(t, filepath.Join(prdDir, "prd.md"), "# Auth\n")
+
+	writeFile(t, filepath.Join(prdDir, "claude.log"), "old output\n")
+	os.Chtimes(filepath.Join(prdDir, "claude.log"), old, old)
+	writeFile(t, filepath.Join(prdDir, loop.FailuresDir, "US-001.verify.log"), "FAIL\n")
+
+	writeFile(t, filepath.Join(prdDir, loop.EventLogFile),
+		jsonLine(t, loop.EventRecord{Time: old, Type: loop.RunStartRecord})+
+			jsonLine(t, loop.EventRecord{Time: old, Type: "IterationStart", StoryID: "US-001"})+
+			jsonLine(t, loop.EventRecord{Time: recent, Type: loop.RunStartRecord})+
+			jsonLine(t, loop.EventRecord{Time: recent, Type: "IterationStart", StoryID: "US-002"}))
+
+	run := func(start time.Time, cost float64) *loop.RunUsage {
+		return &loop.RunUsage{Start: start, UsageTotals: loop.UsageTotals{CostUSD: cost}}
+	}
+	writeFile(t, filepath.Join(prdDir, loop.UsageFile), jsonLine(t, loop.UsageReport{
+		UsageTotals: loop.UsageTotals{CostUSD: 3},
+		Runs:        []*loop.RunUsage{run(old, 1), run(recent, 2)},
+	}))
+
+	for _, when := range []time.Time{old, recent} {
+		if err := undo.Snapshot(dir, "do --rm", "Removed PRD auth", ".chief/prds/auth/prd.md"); err != nil {
+			t.Fatal(err)
+		}
+		entries, _ := undo.List(dir)
+		path := filepath.Join(undo.Dir(dir), entries[0].ID, "entry.json")
+		data, _ := os.ReadFile(path)
+		var e map[string]any
+		json.Unmarshal(data, &e)
+		e["time"] = when
+		writeFile(t, path, jsonLine(t, e))
+	}
+	return dir
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Now()
+	dir := setupProject(t, now)
+
+	statuses := Check(dir, config.RetentionConfig{LogDays: 30, TranscriptDays: 30, UsageDays: 90}, now)
+	want := map[string][3]int{ // Days, items, expired
+		KindLogs:        {30, 2, 1},
+		KindHistory:     {0, 2, 0},
+		KindTranscripts: {30, 2, 1},
+		KindUsage:       {90, 2, 0},
+	}
+	if len(statuses) != len(want) {
+		t.Fatalf("got %d statuses, want %d", len(statuses), len(want))
+	}
+	for _, s := range statuses {
+		if got := [3]int{s.Days, s.Items, s.Expired}; got != want[s.Kind] {
+			t.Errorf("%s: days, items, expired = %v, want %v", s.Kind, got, want[s.Kind])
+		}
+		if s.Bytes == 0 || s.Oldest.After(now.AddDate(0, 0, -39)) {
+			t.Errorf("%s: expected a size and the old item as oldest, got %+v", s.Kind, s)
+		}
+	}
+}
+
+func TestClean(t *testing.T) {
+	now := time.Now()
+	dir := setupProject(t, now)
+	prdDir := filepath.Join(dir, ".chief", "prds", "auth")
+
+	cfg := config.RetentionConfig{LogDays: 30, HistoryDays: 30, TranscriptDays: 30, UsageDays: 30}
+	removed, err := Clean(dir, cfg, now)
+	if err != nil {
+		t.Fatalf("Clean() error = %v", err)
+	}
+	if removed != 4 {
+		t.Errorf("Clean() removed %d items, want 4", removed)
+	}
+
+	if _, err := os.Stat(filepath.Join(prdDir, "claude.log")); !os.IsNotExist(err) {
+		t.Error("expected the old agent log to be removed")
+	}
+	if _, err := os.Stat(filepath.Join(prdDir, loop.FailuresDir, "US-001.verify.log")); err != nil {
+		t.Errorf("expected the recent verification output to be kept: %v", err)
+	}
+	if entries, _ := undo.List(dir); len(entries) != 1 {
+		t.Errorf("expected one undo entry left, got %d", len(entries))
+	}
+
+	records, err := loop.ReadEventLog(filepath.Join(prdDir, loop.EventLogFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[1].StoryID != "US-002" {
+		t.Errorf("expected only the recent run in the event log, got %+v", records)
+	}
+
+	report, err := loop.ReadUsage(filepath.Join(prdDir, loop.UsageFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Runs) != 1 || report.Runs[0].CostUSD != 2 || report.CostUSD != 3 {
+		t.Errorf("expected the recent run and the PRD's total kept, got %+v", report)
+	}
+
+	// Nothing is left to remove
+	if removed, _ := Clean(dir, cfg, now); removed != 0 {
+		t.Errorf("second Clean() removed %d items, want 0", removed)
+	}
+}
+
+func TestClean_SkipsRunningPRD(t *testing.T) {
+	now := time.Now()
+	dir := setupProject(t, now)
+	prdDir := filepath.Join(dir, ".chief", "prds", "auth")
+	// Held by a chief process on another machine sharing the repository
+	writeFile(t, filepath.Join(prdDir, loop.RunLockFile), jsonLine(t, loop.RunLock{Host: "elsewhere", PID: 1, Started: now, Heartbeat: now}))
+
+	if _, err := Clean(dir, config.RetentionConfig{TranscriptDays: 30, UsageDays: 30}, now); err != nil {
+		t.Fatalf("Clean() error = %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(prdDir, loop.EventLogFile))
+	if strings.Count(string(data), loop.RunStartRecord) != 2 {
+		t.Errorf("expected the running PRD's event log untouched, got %s", data)
+	}
+}
@@ -0,0 +1,191 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/minicodemonkey/chief/embed"
+	"github.com/minicodemonkey/chief/internal/git"
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/prompt"
+)
+
+// ScorecardFile is the conventions scorecard's file name inside the PRD directory.
+const ScorecardFile = "scorecard.md"
+
+// ScorecardJSONFile holds the parsed scorecard, for CI checks.
+const ScorecardJSONFile = "scorecard.json"
+
+// scorecardLogFile receives the raw agent output of a conventions check.
+const scorecardLogFile = "scorecard.log"
+
+// Verdicts a convention can get.
+const (
+	VerdictPass = "PASS"
+	VerdictFail = "FAIL"
+	VerdictNA   = "N/A"
+)
+
+// Check is one convention's verdict.
+type Check struct {
+	Convention string   `json:"convention"`
+	Verdict    string   `json:"verdict"`
+	Notes      []string `json:"notes,omitempty"` // Where a failed convention is broken
+}
+
+// Scorecard is a parsed conventions scorecard.
+type Scorecard struct {
+	Path   string  `json:"-"`
+	Checks []Check `json:"checks"`
+	Score  int     `json:"score"` // Percentage of applicable conventions followed
+}
+
+// checklistItemRegex matches a top-level "- item", "* item" or "1. item" line.
+var checklistItemRegex = regexp.MustCompile(`^(?:[-*]|\d+[.)])\s+(.+)$`)
+
+// ParseChecklist returns the conventions listed in a conventions file: every
+// top-level list item. Headings and prose around them are ignored.
+func ParseChecklist(content string) []string {
+	var items []string
+	for _, line := range strings.Split(content, "\n") {
+		if m := checklistItemRegex.FindStringSubmatch(strings.TrimRight(line, " \t\r")); m != nil {
+			items = append(items, strings.TrimSpace(m[1]))
+		}
+	}
+	return items
+}
+
+// checkLineRegex matches "- [PASS] Convention".
+var checkLineRegex = regexp.MustCompile(`^[-*]\s+\[(PASS|FAIL|N/A)\]\s+(.+)$`)
+
+// ParseScorecard extracts the checks from a scorecard's markdown and scores it.
+func ParseScorecard(content string) *Scorecard {
+	s := &Scorecard{}
+	for _, line := range strings.Split(content, "\n") {
+		if m := checkLineRegex.FindStringSubmatch(strings.TrimSpace(line)); m != nil && !strings.HasPrefix(line, " ") {
+			s.Checks = append(s.Checks, Check{Convention: strings.TrimSpace(m[2]), Verdict: m[1]})
+			continue
+		}
+		note := strings.TrimSpace(line)
+		if note != "" && len(s.Checks) > 0 && strings.HasPrefix(line, " ") {
+			last := &s.Checks[len(s.Checks)-1]
+			last.Notes = append(last.Notes, note)
+		}
+	}
+
+	passed, applicable := 0, 0
+	for _, c := range s.Checks {
+		switch c.Verdict {
+		case VerdictPass:
+			passed++
+			applicable++
+		case VerdictFail:
+			applicable++
+		}
+	}
+	s.Score = 100
+	if applicable > 0 {
+		s.Score = passed * 100 / applicable
+	}
+	return s
+}
+
+// Failed returns the conventions the changes break.
+func (s *Scorecard) Failed() []Check {
+	var failed []Check
+	for _, c := range s.Checks {
+		if c.Verdict == VerdictFail {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+// ConventionsOptions configures a conventions check.
+type ConventionsOptions struct {
+	Provider loop.Provider
+	BaseDir  string // Project root holding .chief/conventions.md
+	PRDName  string
+	PRDPath  string    // Path to prd.md; the scorecard is written next to it
+	WorkDir  string    // Repository or worktree holding the PRD's changes
+	Output   io.Writer // Receives one line per agent tool call (optional)
+}
+
+// RunConventions runs a read-only agent pass that checks the changes on the
+// PRD's branch against the project's conventions, and returns the scorecard
+// it wrote. The parsed scorecard is also saved as JSON next to it.
+func RunConventions(ctx context.Context, opts ConventionsOptions) (*Scorecard, error) {
+	if opts.Provider == nil {
+		return nil, fmt.Errorf("conventions check requires Provider to be set")
+	}
+
+	conventionsPath := filepath.Join(opts.BaseDir, prompt.ConventionsFile)
+	data, err := os.ReadFile(conventionsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no conventions to check: create %s with one convention per list item", prompt.ConventionsFile)
+		}
+		return nil, err
+	}
+	checklist := ParseChecklist(string(data))
+	if len(checklist) == 0 {
+		return nil, fmt.Errorf("%s has no list items to check", prompt.ConventionsFile)
+	}
+
+	base, err := git.DiffBase(opts.WorkDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the changes to check: %w", err)
+	}
+	before, err := treeState(opts.WorkDir)
+	if err != nil {
+		return nil, err
+	}
+
+	prdDir := filepath.Dir(opts.PRDPath)
+	scorecardPath := filepath.Join(prdDir, ScorecardFile)
+	if err := os.Remove(scorecardPath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove old scorecard: %w", err)
+	}
+
+	lines := make([]string, len(checklist))
+	for i, item := range checklist {
+		lines[i] = "- " + item
+	}
+	evalPrompt := embed.GetConventionsEvalPrompt(opts.PRDName, opts.PRDPath, base, strings.Join(lines, "\n"), scorecardPath)
+	if err := runAgent(ctx, opts.Provider, opts.WorkDir, opts.Output, evalPrompt, scorecardPath, filepath.Join(prdDir, scorecardLogFile)); err != nil {
+		return nil, err
+	}
+
+	after, err := treeState(opts.WorkDir)
+	if err != nil {
+		return nil, err
+	}
+	if after != before {
+		return nil, fmt.Errorf("the agent modified the repository while checking conventions; inspect `git status` before trusting its scorecard")
+	}
+
+	content, err := os.ReadFile(scorecardPath)
+	if err != nil {
+		return nil, fmt.Errorf("%s did not write a scorecard to %s", opts.Provider.Name(), scorecardPath)
+	}
+	scorecard := ParseScorecard(string(content))
+	scorecard.Path = scorecardPath
+	if len(scorecard.Checks) != len(checklist) {
+		return nil, fmt.Errorf("the scorecard at %s covers %d of %d conventions", scorecardPath, len(scorecard.Checks), len(checklist))
+	}
+
+	out, err := json.MarshalIndent(scorecard, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(prdDir, ScorecardJSONFile), append(out, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", ScorecardJSONFile, err)
+	}
+	return scorecard, nil
+}
@@ -0,0 +1,101 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleConventions = `# Conventions
+
+Rules for every change:
+
+- Wrap errors with %w
+* Handlers don't query the database directly
+  (use the repository layer)
+1. Exported functions have doc comments
+`
+
+const sampleScorecard = `# Conventions Scorecard: auth
+
+- [PASS] Wrap errors with %w
+- [FAIL] Handlers don't query the database directly
+  api/login.go:12: calls db.Query in the handler.
+- [N/A] Exported functions have doc comments
+`
+
+func TestParseChecklist(t *testing.T) {
+	items := ParseChecklist(sampleConventions)
+	want := []string{"Wrap errors with %w", "Handlers don't query the database directly", "Exported functions have doc comments"}
+	if strings.Join(items, "|") != strings.Join(want, "|") {
+		t.Errorf("ParseChecklist() = %q, want %q", items, want)
+	}
+}
+
+func TestParseScorecard(t *testing.T) {
+	s := ParseScorecard(sampleScorecard)
+	if len(s.Checks) != 3 {
+		t.Fatalf("expected 3 checks, got %+v", s.Checks)
+	}
+	if s.Score != 50 {
+		t.Errorf("expected N/A to be left out of the score, got %d", s.Score)
+	}
+	failed := s.Failed()
+	if len(failed) != 1 || len(failed[0].Notes) != 1 || !strings.Contains(failed[0].Notes[0], "api/login.go:12") {
+		t.Errorf("expected the failure with its location, got %+v", failed)
+	}
+
+	if s := ParseScorecard("- [N/A] Anything\n"); s.Score != 100 {
+		t.Errorf("expected 100 with nothing applicable, got %d", s.Score)
+	}
+}
+
+func TestRunConventions(t *testing.T) {
+	dir := initReviewRepo(t)
+	prdPath := filepath.Join(dir, ".chief", "prds", "auth", "prd.md")
+	scorecardPath := filepath.Join(dir, ".chief", "prds", "auth", ScorecardFile)
+	opts := ConventionsOptions{
+		Provider: &scriptProvider{script: "cp .chief/scorecard.tmpl " + scorecardPath},
+		BaseDir:  dir,
+		PRDName:  "auth",
+		PRDPath:  prdPath,
+		WorkDir:  dir,
+	}
+
+	if _, err := RunConventions(context.Background(), opts); err == nil || !strings.Contains(err.Error(), "no conventions") {
+		t.Fatalf("expected an error without a conventions file, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ".chief", "conventions.md"), []byte(sampleConventions), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".chief", "scorecard.tmpl"), []byte("- [PASS] Wrap errors with %w\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RunConventions(context.Background(), opts); err == nil || !strings.Contains(err.Error(), "covers 1 of 3") {
+		t.Fatalf("expected an incomplete scorecard to be rejected, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ".chief", "scorecard.tmpl"), []byte(sampleScorecard), 0644); err != nil {
+		t.Fatal(err)
+	}
+	scorecard, err := RunConventions(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("RunConventions() error = %v", err)
+	}
+	if scorecard.Path != scorecardPath || scorecard.Score != 50 {
+		t.Errorf("unexpected scorecard: %+v", scorecard)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ".chief", "prds", "auth", ScorecardJSONFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved Scorecard
+	if err := json.Unmarshal(data, &saved); err != nil || saved.Score != 50 || len(saved.Checks) != 3 {
+		t.Errorf("expected the scorecard saved as JSON, got %s (%v)", data, err)
+	}
+}
@@ -0,0 +1,141 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/minicodemonkey/chief/embed"
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// EstimateFile is the story estimate's file name inside the PRD directory.
+const EstimateFile = "estimate.md"
+
+// StoryEstimate is the agent's forecast of the work a story will take.
+type StoryEstimate struct {
+	StoryID    string
+	Complexity string // LOW, MEDIUM, or HIGH
+	Minutes    int    // Agent time across attempts
+	Iterations int    // Attempts until the story passes
+	Tokens     int    // Input and output tokens across attempts
+	Risks      string
+}
+
+// estimateHeadingRegex matches "### US-001 [MEDIUM]".
+var estimateHeadingRegex = regexp.MustCompile(`^#{3,4}\s+(\S+)\s+\[([A-Za-z]+)\]`)
+
+// estimateFieldRegex matches "**Minutes:** 25".
+var estimateFieldRegex = regexp.MustCompile(`^\*\*([A-Za-z]+):\*\*\s*(.*)$`)
+
+// ParseEstimate extracts the story estimates from an estimate's markdown.
+// Numbers may use thousands separators or a "k" suffix, e.g. "400k".
+func ParseEstimate(content string) []StoryEstimate {
+	var estimates []StoryEstimate
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if m := estimateHeadingRegex.FindStringSubmatch(line); m != nil {
+			estimates = append(estimates, StoryEstimate{StoryID: m[1], Complexity: strings.ToUpper(m[2])})
+			continue
+		}
+		m := estimateFieldRegex.FindStringSubmatch(line)
+		if m == nil || len(estimates) == 0 {
+			continue
+		}
+		e := &estimates[len(estimates)-1]
+		switch strings.ToLower(m[1]) {
+		case "minutes":
+			e.Minutes = parseCount(m[2])
+		case "iterations":
+			e.Iterations = parseCount(m[2])
+		case "tokens":
+			e.Tokens = parseCount(m[2])
+		case "risks":
+			e.Risks = strings.TrimSpace(m[2])
+		}
+	}
+	return estimates
+}
+
+// parseCount parses a whole number such as "25", "400,000", or "400k",
+// ignoring any words after it. It returns 0 when there is no number.
+func parseCount(s string) int {
+	field, _, _ := strings.Cut(strings.TrimSpace(s), " ")
+	field = strings.NewReplacer(",", "", "_", "", "~", "").Replace(strings.ToLower(field))
+	scale := 1
+	if strings.HasSuffix(field, "k") {
+		field, scale = strings.TrimSuffix(field, "k"), 1000
+	} else if strings.HasSuffix(field, "m") {
+		field, scale = strings.TrimSuffix(field, "m"), 1000000
+	}
+	n, err := strconv.ParseFloat(field, 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return int(n * float64(scale))
+}
+
+// EstimateOptions configures a story estimate.
+type EstimateOptions struct {
+	Provider loop.Provider
+	PRDPath  string          // Path to prd.md; the estimate is written next to it
+	Stories  []prd.UserStory // Stories to estimate, in the order they will run
+	WorkDir  string          // Repository the stories will be implemented in
+	Output   io.Writer       // Receives one line per agent tool call (optional)
+}
+
+// Estimate runs a read-only agent pass that forecasts the minutes,
+// iterations, and tokens each story will take, and returns the estimates it
+// wrote.
+func Estimate(ctx context.Context, opts EstimateOptions) ([]StoryEstimate, error) {
+	if opts.Provider == nil {
+		return nil, fmt.Errorf("estimate requires Provider to be set")
+	}
+	if len(opts.Stories) == 0 {
+		return nil, fmt.Errorf("no stories to estimate")
+	}
+
+	before, err := treeState(opts.WorkDir)
+	if err != nil {
+		return nil, err
+	}
+
+	prdDir := filepath.Dir(opts.PRDPath)
+	path := filepath.Join(prdDir, EstimateFile)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove old estimate: %w", err)
+	}
+
+	stories := make([]string, len(opts.Stories))
+	for i, s := range opts.Stories {
+		stories[i] = formatStory(s)
+	}
+	prompt := embed.GetEstimatePrompt(opts.PRDPath, strings.Join(stories, "\n\n"), path)
+	if err := runAgent(ctx, opts.Provider, opts.WorkDir, opts.Output, prompt, path, filepath.Join(prdDir, "estimate.log")); err != nil {
+		return nil, err
+	}
+
+	after, err := treeState(opts.WorkDir)
+	if err != nil {
+		return nil, err
+	}
+	if after != before {
+		return nil, fmt.Errorf("the agent modified the repository while estimating; inspect `git status`")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s did not write an estimate to %s", opts.Provider.Name(), path)
+	}
+	estimates := ParseEstimate(string(data))
+	if len(estimates) == 0 {
+		return nil, fmt.Errorf("the estimate at %s has no stories in the expected format", path)
+	}
+	return estimates, nil
+}
@@ -0,0 +1,44 @@
+package review
+
+import "testing"
+
+const sampleEstimate = `# Estimate: auth
+
+### US-001 [LOW]
+**Minutes:** 8
+**Iterations:** 1
+**Tokens:** 120,000
+**Risks:** None
+
+### US-002 [high]
+**Minutes:** 45 (two attempts)
+**Iterations:** 3
+**Tokens:** 1.5M
+**Risks:** Touches the session middleware used by every route.
+`
+
+func TestParseEstimate(t *testing.T) {
+	estimates := ParseEstimate(sampleEstimate)
+	if len(estimates) != 2 {
+		t.Fatalf("expected 2 estimates, got %d", len(estimates))
+	}
+	first := estimates[0]
+	if first.StoryID != "US-001" || first.Complexity != "LOW" || first.Minutes != 8 || first.Iterations != 1 || first.Tokens != 120000 {
+		t.Errorf("unexpected first estimate: %+v", first)
+	}
+	second := estimates[1]
+	if second.Complexity != "HIGH" || second.Minutes != 45 || second.Iterations != 3 || second.Tokens != 1500000 {
+		t.Errorf("unexpected second estimate: %+v", second)
+	}
+	if second.Risks != "Touches the session middleware used by every route." {
+		t.Errorf("unexpected risks: %q", second.Risks)
+	}
+}
+
+func TestParseCount(t *testing.T) {
+	for in, want := range map[string]int{"25": 25, "400k": 400000, "~30 minutes": 30, "1_000": 1000, "unknown": 0} {
+		if got := parseCount(in); got != want {
+			t.Errorf("parseCount(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
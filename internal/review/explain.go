@@ -0,0 +1,136 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/minicodemonkey/chief/embed"
+	"github.com/minicodemonkey/chief/internal/git"
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// ExplanationDir holds story explanations inside the PRD directory.
+const ExplanationDir = "explanations"
+
+// ExplainOptions configures a story explanation.
+type ExplainOptions struct {
+	Provider loop.Provider
+	PRDPath  string // Path to prd.md; the explanation is written next to it
+	Story    prd.UserStory
+	WorkDir  string    // Repository or worktree holding the story's commits
+	Output   io.Writer // Receives one line per agent tool call (optional)
+}
+
+// ExplanationPath returns where the explanation of a story is written.
+func ExplanationPath(prdPath, storyID string) string {
+	return filepath.Join(filepath.Dir(prdPath), ExplanationDir, storyID+".md")
+}
+
+// Explain runs a read-only agent pass that explains how a story was
+// implemented, from the commits that mention it and its progress notes, and
+// returns the explanation it wrote.
+func Explain(ctx context.Context, opts ExplainOptions) (string, error) {
+	if opts.Provider == nil {
+		return "", fmt.Errorf("explain requires Provider to be set")
+	}
+	story := opts.Story
+
+	commits, err := git.StoryCommits(opts.WorkDir, story.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to find commits for %s: %w", story.ID, err)
+	}
+	var notes []prd.ProgressEntry
+	if progress, err := prd.ParseProgress(prd.ProgressPath(opts.PRDPath)); err == nil {
+		notes = progress[story.ID]
+	}
+	if len(commits) == 0 && len(notes) == 0 {
+		return "", fmt.Errorf("%s has no commits or progress notes yet", story.ID)
+	}
+
+	before, err := treeState(opts.WorkDir)
+	if err != nil {
+		return "", err
+	}
+
+	path := ExplanationPath(opts.PRDPath, story.ID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", ExplanationDir, err)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to remove old explanation: %w", err)
+	}
+
+	prompt := embed.GetExplainPrompt(opts.PRDPath, story.ID, story.Title,
+		formatStory(story), formatCommits(commits), formatNotes(notes), path)
+	logPath := filepath.Join(filepath.Dir(path), story.ID+".log")
+	if err := runAgent(ctx, opts.Provider, opts.WorkDir, opts.Output, prompt, path, logPath); err != nil {
+		return "", err
+	}
+
+	after, err := treeState(opts.WorkDir)
+	if err != nil {
+		return "", err
+	}
+	if after != before {
+		return "", fmt.Errorf("the agent modified the repository while explaining %s; inspect `git status`", story.ID)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%s did not write an explanation to %s", opts.Provider.Name(), path)
+	}
+	return string(data), nil
+}
+
+// formatStory renders a story as markdown for the explain prompt.
+func formatStory(s prd.UserStory) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s: %s**\n", s.ID, s.Title)
+	if s.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", s.Description)
+	}
+	if len(s.AcceptanceCriteria) > 0 {
+		b.WriteString("\nAcceptance criteria:\n")
+		for _, c := range s.AcceptanceCriteria {
+			fmt.Fprintf(&b, "- %s\n", c)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// formatCommits lists commits as "- `hash` subject" lines.
+func formatCommits(commits []git.Commit) string {
+	if len(commits) == 0 {
+		return "(none: the story's work may not be committed yet; inspect `git status` and `git diff`)"
+	}
+	lines := make([]string, len(commits))
+	for i, c := range commits {
+		lines[i] = fmt.Sprintf("- `%s` %s", shortHash(c.Hash), c.Subject)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatNotes renders progress entries under their session dates.
+func formatNotes(notes []prd.ProgressEntry) string {
+	if len(notes) == 0 {
+		return "(none)"
+	}
+	var parts []string
+	for _, n := range notes {
+		parts = append(parts, fmt.Sprintf("Session %s:\n%s", n.Date, strings.TrimSpace(n.Content)))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// shortHash abbreviates a commit hash for display.
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
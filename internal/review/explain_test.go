@@ -0,0 +1,53 @@
+package review
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+func TestExplain(t *testing.T) {
+	dir := initReviewRepo(t)
+	prdPath := filepath.Join(dir, ".chief", "prds", "auth", "prd.md")
+	path := ExplanationPath(prdPath, "US-001")
+	if err := os.WriteFile(filepath.Join(dir, ".chief", "explanation.tmpl"), []byte("# US-001: Login\n\n## Summary\nAdds login.go.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := ExplainOptions{
+		Provider: &scriptProvider{script: "cp .chief/explanation.tmpl " + path},
+		PRDPath:  prdPath,
+		Story:    prd.UserStory{ID: "US-001", Title: "Login"},
+		WorkDir:  dir,
+	}
+	explanation, err := Explain(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if !strings.Contains(explanation, "Adds login.go.") {
+		t.Errorf("unexpected explanation: %q", explanation)
+	}
+
+	opts.Story = prd.UserStory{ID: "US-002", Title: "Logout"}
+	if _, err := Explain(context.Background(), opts); err == nil || !strings.Contains(err.Error(), "no commits or progress notes") {
+		t.Errorf("expected an error for a story without history, got %v", err)
+	}
+}
+
+func TestExplainPromptParts(t *testing.T) {
+	story := formatStory(prd.UserStory{ID: "US-001", Title: "Login", Description: "As a user...", AcceptanceCriteria: []string{"Form validates email"}})
+	if !strings.Contains(story, "**US-001: Login**") || !strings.Contains(story, "- Form validates email") {
+		t.Errorf("unexpected story block:\n%s", story)
+	}
+	notes := formatNotes([]prd.ProgressEntry{{StoryID: "US-001", Date: "2026-03-01", Content: "- Used bcrypt\n"}})
+	if notes != "Session 2026-03-01:\n- Used bcrypt" {
+		t.Errorf("unexpected notes: %q", notes)
+	}
+	if got := formatNotes(nil); got != "(none)" {
+		t.Errorf("formatNotes(nil) = %q", got)
+	}
+}
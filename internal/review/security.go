@@ -0,0 +1,247 @@
+// Package review runs read-only agent passes over the changes a PRD run
+// produced. The security review writes a findings report next to the PRD and
+// can gate pull request creation on its severity. The conventions check
+// scores the changes against the project's conventions.
+package review
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/minicodemonkey/chief/embed"
+	"github.com/minicodemonkey/chief/internal/git"
+	"github.com/minicodemonkey/chief/internal/loop"
+)
+
+// ReportFile is the security report's file name inside the PRD directory.
+const ReportFile = "security-review.md"
+
+// logFile receives the raw agent output of a security review.
+const logFile = "security-review.log"
+
+// Severity ranks a finding.
+type Severity int
+
+const (
+	SeverityNone Severity = iota
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+// String returns the severity as written in reports.
+func (s Severity) String() string {
+	switch s {
+	case SeverityLow:
+		return "LOW"
+	case SeverityMedium:
+		return "MEDIUM"
+	case SeverityHigh:
+		return "HIGH"
+	case SeverityCritical:
+		return "CRITICAL"
+	default:
+		return "NONE"
+	}
+}
+
+// ParseSeverity parses a severity name, case-insensitively. Unknown names
+// return SeverityNone.
+func ParseSeverity(name string) Severity {
+	for s := SeverityLow; s <= SeverityCritical; s++ {
+		if strings.EqualFold(name, s.String()) {
+			return s
+		}
+	}
+	return SeverityNone
+}
+
+// Finding is a single problem reported by the review.
+type Finding struct {
+	Severity Severity
+	Title    string
+}
+
+// Report is a parsed security review report.
+type Report struct {
+	Path     string
+	Findings []Finding
+}
+
+// findingHeadingRegex matches "### [HIGH] Title".
+var findingHeadingRegex = regexp.MustCompile(`^#{3,4}\s+\[([A-Za-z]+)\]\s+(.+)$`)
+
+// ParseReport extracts the findings from a report's markdown.
+func ParseReport(content string) *Report {
+	r := &Report{}
+	for _, line := range strings.Split(content, "\n") {
+		m := findingHeadingRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		r.Findings = append(r.Findings, Finding{Severity: ParseSeverity(m[1]), Title: strings.TrimSpace(m[2])})
+	}
+	return r
+}
+
+// LoadReport reads and parses the report at path.
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := ParseReport(string(data))
+	r.Path = path
+	return r, nil
+}
+
+// Highest returns the most severe finding's severity.
+func (r *Report) Highest() Severity {
+	highest := SeverityNone
+	for _, f := range r.Findings {
+		if f.Severity > highest {
+			highest = f.Severity
+		}
+	}
+	return highest
+}
+
+// Blocking returns the findings severe enough to hold back a pull request.
+func (r *Report) Blocking() []Finding {
+	var blocking []Finding
+	for _, f := range r.Findings {
+		if f.Severity >= SeverityHigh {
+			blocking = append(blocking, f)
+		}
+	}
+	return blocking
+}
+
+// SecurityOptions configures a security review.
+type SecurityOptions struct {
+	Provider loop.Provider
+	PRDName  string
+	PRDPath  string    // Path to prd.md; the report is written next to it
+	WorkDir  string    // Repository or worktree holding the PRD's changes
+	Output   io.Writer // Receives one line per agent tool call (optional)
+}
+
+// RunSecurity runs a read-only agent pass over the changes on the PRD's branch
+// and returns the report it wrote. The review fails if the agent changes the
+// repository, since its findings would then describe code that doesn't exist.
+func RunSecurity(ctx context.Context, opts SecurityOptions) (*Report, error) {
+	if opts.Provider == nil {
+		return nil, fmt.Errorf("security review requires Provider to be set")
+	}
+
+	base, err := git.DiffBase(opts.WorkDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the changes to review: %w", err)
+	}
+	before, err := treeState(opts.WorkDir)
+	if err != nil {
+		return nil, err
+	}
+
+	prdDir := filepath.Dir(opts.PRDPath)
+	reportPath := filepath.Join(prdDir, ReportFile)
+	if err := os.Remove(reportPath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove old report: %w", err)
+	}
+
+	prompt := embed.GetSecurityReviewPrompt(opts.PRDName, opts.PRDPath, base, reportPath)
+	if err := runAgent(ctx, opts.Provider, opts.WorkDir, opts.Output, prompt, reportPath, filepath.Join(prdDir, logFile)); err != nil {
+		return nil, err
+	}
+
+	after, err := treeState(opts.WorkDir)
+	if err != nil {
+		return nil, err
+	}
+	if after != before {
+		return nil, fmt.Errorf("the review agent modified the repository; inspect `git status` before trusting its report")
+	}
+
+	report, err := LoadReport(reportPath)
+	if err != nil {
+		return nil, fmt.Errorf("%s did not write a report to %s", opts.Provider.Name(), reportPath)
+	}
+	return report, nil
+}
+
+// runAgent runs the provider read-only with prompt in workDir, allowed to write
+// only resultPath. It logs the agent's raw output to logPath and writes one
+// line per tool call to output (optional).
+func runAgent(ctx context.Context, provider loop.Provider, workDir string, output io.Writer, prompt, resultPath, logPath string) error {
+	logOut, err := os.Create(logPath)
+	if err != nil {
+		return fmt.Errorf("failed to create review log: %w", err)
+	}
+	defer logOut.Close()
+
+	cmd, err := provider.ReadOnlyCommand(ctx, prompt, workDir, resultPath)
+	if err != nil {
+		return err
+	}
+	cmd.Stderr = logOut
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", provider.Name(), err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fmt.Fprintln(logOut, line)
+		if output == nil {
+			continue
+		}
+		if event := provider.ParseLine(line); event != nil && event.Type == loop.EventToolStart {
+			fmt.Fprintf(output, "  %s\n", event.Tool)
+		}
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("%s review failed (see %s): %w", provider.Name(), logPath, err)
+	}
+	return nil
+}
+
+// treeState summarizes the repository's HEAD and working tree, ignoring .chief/.
+func treeState(dir string) (string, error) {
+	head, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read HEAD: %w", err)
+	}
+	status, err := exec.Command("git", "-C", dir, "status", "--porcelain", "--", ".", ":(exclude).chief").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read git status: %w", err)
+	}
+	return string(head) + string(status), nil
+}
+
+// Gate runs a security review and returns an error when it can't complete or
+// reports findings of high severity or worse. It is used to hold back pull
+// requests.
+func Gate(ctx context.Context, opts SecurityOptions) error {
+	report, err := RunSecurity(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("security review failed: %w", err)
+	}
+	if blocking := report.Blocking(); len(blocking) > 0 {
+		return fmt.Errorf("security review found %d high-severity finding(s); see %s", len(blocking), report.Path)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package review
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/loop"
+)
+
+// scriptProvider runs a shell script in place of an agent CLI.
+type scriptProvider struct {
+	script  string
+	outputs []string // Files the last read-only command may write
+}
+
+func (p *scriptProvider) Name() string    { return "Test" }
+func (p *scriptProvider) CLIPath() string { return "sh" }
+func (p *scriptProvider) LoopCommand(ctx context.Context, prompt, workDir string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "sh", "-c", p.script)
+	cmd.Dir = workDir
+	return cmd
+}
+func (p *scriptProvider) ReadOnlyCommand(ctx context.Context, prompt, workDir string, outputs ...string) (*exec.Cmd, error) {
+	p.outputs = outputs
+	return p.LoopCommand(ctx, prompt, workDir), nil
+}
+func (p *scriptProvider) InteractiveCommand(workDir, prompt string) *exec.Cmd { return nil }
+func (p *scriptProvider) CleanOutput(output string) string                    { return output }
+func (p *scriptProvider) ParseLine(line string) *loop.Event                   { return nil }
+func (p *scriptProvider) LogFileName() string                                 { return "test.log" }
+
+const sampleReport = `# Security Review: auth
+
+## Findings
+
+### [HIGH] SQL injection in login
+**Location:** db/users.go:42
+**Issue:** The email is concatenated into the query.
+**Fix:** Use a bound parameter.
+
+### [low] Verbose error message
+**Location:** api/login.go:10
+
+#### [CRITICAL] Hardcoded admin token
+`
+
+func TestParseReport(t *testing.T) {
+	r := ParseReport(sampleReport)
+	if len(r.Findings) != 3 {
+		t.Fatalf("expected 3 findings, got %+v", r.Findings)
+	}
+	if r.Findings[0].Severity != SeverityHigh || r.Findings[0].Title != "SQL injection in login" {
+		t.Errorf("unexpected first finding: %+v", r.Findings[0])
+	}
+	if r.Findings[1].Severity != SeverityLow {
+		t.Errorf("expected severity to be case-insensitive, got %v", r.Findings[1].Severity)
+	}
+	if r.Highest() != SeverityCritical {
+		t.Errorf("Highest() = %v, want CRITICAL", r.Highest())
+	}
+	if len(r.Blocking()) != 2 {
+		t.Errorf("expected HIGH and CRITICAL findings to block, got %+v", r.Blocking())
+	}
+
+	empty := ParseReport("# Security Review: auth\n\n## Findings\n\nNo findings.\n")
+	if len(empty.Findings) != 0 || empty.Highest() != SeverityNone || len(empty.Blocking()) != 0 {
+		t.Errorf("expected an empty report, got %+v", empty)
+	}
+}
+
+// initReviewRepo creates a repository on a feature branch with one change to review.
+func initReviewRepo(t *testing.T) string {
+	t.Helper()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %s", args, out)
+		}
+	}
+	run("init", "-b", "main")
+	run("config", "user.email", "test@test.com")
+	run("config", "user.name", "Test")
+	if err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte(".chief/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("add", ".")
+	run("commit", "-m", "initial commit")
+	run("checkout", "-b", "chief/auth")
+	if err := os.WriteFile(filepath.Join(dir, "login.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("add", ".")
+	run("commit", "-m", "feat: US-001 - Login")
+
+	if err := os.MkdirAll(filepath.Join(dir, ".chief", "prds", "auth"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".chief", "prds", "auth", "prd.md"), []byte("# Auth\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestRunSecurity(t *testing.T) {
+	dir := initReviewRepo(t)
+	prdPath := filepath.Join(dir, ".chief", "prds", "auth", "prd.md")
+	reportPath := filepath.Join(dir, ".chief", "prds", "auth", ReportFile)
+	if err := os.WriteFile(filepath.Join(dir, "report.tmpl"), []byte(sampleReport), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := SecurityOptions{
+		Provider: &scriptProvider{script: "cp report.tmpl " + reportPath + " && rm report.tmpl"},
+		PRDName:  "auth",
+		PRDPath:  prdPath,
+		WorkDir:  dir,
+	}
+
+	// The template file is untracked, so removing it changes the working tree
+	if _, err := RunSecurity(context.Background(), opts); err == nil || !strings.Contains(err.Error(), "modified the repository") {
+		t.Fatalf("expected a repository change to fail the review, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ".chief", "report.tmpl"), []byte(sampleReport), 0644); err != nil {
+		t.Fatal(err)
+	}
+	provider := &scriptProvider{script: "cp .chief/report.tmpl " + reportPath}
+	opts.Provider = provider
+	report, err := RunSecurity(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("RunSecurity() error = %v", err)
+	}
+	if len(provider.outputs) != 1 || provider.outputs[0] != reportPath {
+		t.Errorf("agent ran with outputs %v, want it read-only except for %s", provider.outputs, reportPath)
+	}
+	if report.Path != reportPath || len(report.Findings) != 3 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	if err := Gate(context.Background(), opts); err == nil || !strings.Contains(err.Error(), "2 high-severity") {
+		t.Errorf("expected Gate to block on high findings, got %v", err)
+	}
+
+	opts.Provider = &scriptProvider{script: "true"}
+	if _, err := RunSecurity(context.Background(), opts); err == nil || !strings.Contains(err.Error(), "did not write a report") {
+		t.Errorf("expected an error when no report is written, got %v", err)
+	}
+}
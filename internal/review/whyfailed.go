@@ -0,0 +1,187 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/minicodemonkey/chief/embed"
+	"github.com/minicodemonkey/chief/internal/git"
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/prd"
+	"github.com/minicodemonkey/chief/internal/timefmt"
+)
+
+// WhyFailedOptions configures a failure analysis.
+type WhyFailedOptions struct {
+	Provider loop.Provider
+	PRDPath  string // Path to prd.md; the analysis is written next to it
+	Story    prd.UserStory
+	WorkDir  string    // Repository or worktree the story was attempted in
+	Output   io.Writer // Receives one line per agent tool call (optional)
+}
+
+// AnalysisPath returns where the failure analysis of a story is written.
+func AnalysisPath(prdPath, storyID string) string {
+	return filepath.Join(filepath.Dir(prdPath), loop.FailuresDir, storyID+".md")
+}
+
+// Attempt is one iteration spent on a story, taken from the event log.
+type Attempt struct {
+	Run       int // 1-based run the iteration belongs to
+	Iteration int
+	Started   string   // Start time, "2006-01-02 15:04"
+	ToolCalls int      // Tools the agent called
+	Outcomes  []string // How the iteration ended: verification, crashes, rejected commits
+}
+
+// outcomeTypes are the event log records that say how an attempt went.
+var outcomeTypes = map[string]bool{
+	"StoryDone":       true,
+	"Error":           true,
+	"Retrying":        true,
+	"WatchdogTimeout": true,
+	"Stalled":         true,
+	"StoryTimeout":    true,
+	"CommitRejected":  true,
+	"Coverage":        true,
+	"Verify":          true,
+	"Stopped":         true,
+	"CircuitOpen":     true,
+}
+
+// StoryAttempts returns the iterations spent on storyID, oldest first.
+func StoryAttempts(records []loop.EventRecord, storyID string) []Attempt {
+	var attempts []Attempt
+	var current *Attempt
+	run := 0
+	for _, rec := range records {
+		switch {
+		case rec.Type == loop.RunStartRecord:
+			run++
+			current = nil
+		case rec.Type == "IterationStart":
+			current = nil
+			if strings.EqualFold(rec.StoryID, storyID) {
+				attempts = append(attempts, Attempt{Run: max(run, 1), Iteration: rec.Iteration, Started: timefmt.DateTime(rec.Time)})
+				current = &attempts[len(attempts)-1]
+			}
+		case current == nil:
+		case rec.StoryID != "" && !strings.EqualFold(rec.StoryID, storyID):
+		case rec.Type == "ToolStart":
+			current.ToolCalls++
+		case outcomeTypes[rec.Type]:
+			outcome := rec.Type
+			if rec.Detail != "" {
+				outcome += ": " + rec.Detail
+			}
+			current.Outcomes = append(current.Outcomes, outcome)
+		}
+	}
+	return attempts
+}
+
+// WhyFailed runs a read-only agent pass over everything recorded about a
+// story's attempts, and returns the root-cause analysis and story rewrite it
+// wrote.
+func WhyFailed(ctx context.Context, opts WhyFailedOptions) (string, error) {
+	if opts.Provider == nil {
+		return "", fmt.Errorf("why-failed requires Provider to be set")
+	}
+	story := opts.Story
+	if story.Passes {
+		return "", fmt.Errorf("%s passed; there is no failure to analyze", story.ID)
+	}
+
+	prdDir := filepath.Dir(opts.PRDPath)
+	records, err := loop.ReadEventLog(filepath.Join(prdDir, loop.EventLogFile))
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	attempts := StoryAttempts(records, story.ID)
+	if len(attempts) == 0 {
+		return "", fmt.Errorf("%s has no recorded attempts yet", story.ID)
+	}
+
+	commits, err := git.StoryCommits(opts.WorkDir, story.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to find commits for %s: %w", story.ID, err)
+	}
+	var notes []prd.ProgressEntry
+	if progress, err := prd.ParseProgress(prd.ProgressPath(opts.PRDPath)); err == nil {
+		notes = progress[story.ID]
+	}
+
+	before, err := treeState(opts.WorkDir)
+	if err != nil {
+		return "", err
+	}
+
+	path := AnalysisPath(opts.PRDPath, story.ID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", loop.FailuresDir, err)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to remove old analysis: %w", err)
+	}
+
+	prompt := embed.GetWhyFailedPrompt(opts.PRDPath, story.ID, story.Title, formatStory(story),
+		formatAttempts(attempts), formatCommits(commits), formatNotes(notes),
+		formatEvidence(opts.PRDPath, story.ID, opts.Provider.LogFileName()), path)
+	logPath := filepath.Join(filepath.Dir(path), story.ID+".log")
+	if err := runAgent(ctx, opts.Provider, opts.WorkDir, opts.Output, prompt, path, logPath); err != nil {
+		return "", err
+	}
+
+	after, err := treeState(opts.WorkDir)
+	if err != nil {
+		return "", err
+	}
+	if after != before {
+		return "", fmt.Errorf("the agent modified the repository while analyzing %s; inspect `git status`", story.ID)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%s did not write an analysis to %s", opts.Provider.Name(), path)
+	}
+	return string(data), nil
+}
+
+// formatAttempts renders attempts as a markdown list for the prompt.
+func formatAttempts(attempts []Attempt) string {
+	var b strings.Builder
+	for _, a := range attempts {
+		fmt.Fprintf(&b, "- Run %d, iteration %d (%s, %d tool calls)\n", a.Run, a.Iteration, a.Started, a.ToolCalls)
+		if len(a.Outcomes) == 0 {
+			b.WriteString("  - Ended without a recorded outcome (the agent never reported the story done)\n")
+		}
+		for _, o := range a.Outcomes {
+			fmt.Fprintf(&b, "  - %s\n", o)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// formatEvidence lists the files kept about a story's failures that exist.
+func formatEvidence(prdPath, storyID, agentLog string) string {
+	prdDir := filepath.Dir(prdPath)
+	candidates := []struct{ path, what string }{
+		{loop.VerifyLogPath(prdPath, storyID), "output of the last failed verification"},
+		{filepath.Join(prdDir, loop.FailureFile), "crash dossier, written when repeated agent crashes paused the run"},
+		{filepath.Join(prdDir, agentLog), "raw agent log of every run"},
+	}
+	var lines []string
+	for _, c := range candidates {
+		if _, err := os.Stat(c.path); err == nil {
+			lines = append(lines, fmt.Sprintf("- `%s`: %s", c.path, c.what))
+		}
+	}
+	if len(lines) == 0 {
+		return "(none)"
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,90 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// writeEventLog writes records to the PRD's event log.
+func writeEventLog(t *testing.T, prdPath string, records []loop.EventRecord) {
+	t.Helper()
+	var b strings.Builder
+	for _, rec := range records {
+		line, _ := json.Marshal(rec)
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	if err := os.WriteFile(filepath.Join(filepath.Dir(prdPath), loop.EventLogFile), []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStoryAttempts(t *testing.T) {
+	now := time.Now()
+	records := []loop.EventRecord{
+		{Time: now, Type: loop.RunStartRecord},
+		{Time: now, Type: "IterationStart", Iteration: 1, StoryID: "US-001"},
+		{Time: now, Type: "ToolStart", Iteration: 1, Tool: "Bash"},
+		{Time: now, Type: "ToolStart", Iteration: 1, Tool: "Edit"},
+		{Time: now, Type: "Verify", Iteration: 1, StoryID: "US-001", Detail: "verification failed: TestLogin"},
+		{Time: now, Type: "IterationStart", Iteration: 2, StoryID: "US-002"},
+		{Time: now, Type: "ToolStart", Iteration: 2, Tool: "Bash"},
+		{Time: now, Type: loop.RunStartRecord},
+		{Time: now, Type: "IterationStart", Iteration: 1, StoryID: "us-001"},
+		{Time: now, Type: "Error", Iteration: 1, Detail: "exit status 1"},
+	}
+	attempts := StoryAttempts(records, "US-001")
+	if len(attempts) != 2 {
+		t.Fatalf("expected 2 attempts, got %+v", attempts)
+	}
+	if attempts[0].Run != 1 || attempts[0].ToolCalls != 2 || len(attempts[0].Outcomes) != 1 || !strings.Contains(attempts[0].Outcomes[0], "TestLogin") {
+		t.Errorf("unexpected first attempt: %+v", attempts[0])
+	}
+	if attempts[1].Run != 2 || attempts[1].ToolCalls != 0 || attempts[1].Outcomes[0] != "Error: exit status 1" {
+		t.Errorf("unexpected second attempt: %+v", attempts[1])
+	}
+}
+
+func TestWhyFailed(t *testing.T) {
+	dir := initReviewRepo(t)
+	prdPath := filepath.Join(dir, ".chief", "prds", "auth", "prd.md")
+	path := AnalysisPath(prdPath, "US-001")
+	if err := os.WriteFile(filepath.Join(dir, ".chief", "analysis.tmpl"), []byte("# Why US-001 failed: Login\n\n## Root Cause\nThe story is too large.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := WhyFailedOptions{
+		Provider: &scriptProvider{script: "cp .chief/analysis.tmpl " + path},
+		PRDPath:  prdPath,
+		Story:    prd.UserStory{ID: "US-001", Title: "Login"},
+		WorkDir:  dir,
+	}
+
+	if _, err := WhyFailed(context.Background(), opts); err == nil || !strings.Contains(err.Error(), "no recorded attempts") {
+		t.Fatalf("expected an error for a story never attempted, got %v", err)
+	}
+
+	writeEventLog(t, prdPath, []loop.EventRecord{
+		{Time: time.Now(), Type: loop.RunStartRecord},
+		{Time: time.Now(), Type: "IterationStart", Iteration: 1, StoryID: "US-001"},
+	})
+	analysis, err := WhyFailed(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("WhyFailed() error = %v", err)
+	}
+	if !strings.Contains(analysis, "too large") {
+		t.Errorf("unexpected analysis: %q", analysis)
+	}
+
+	opts.Story.Passes = true
+	if _, err := WhyFailed(context.Background(), opts); err == nil {
+		t.Error("expected an error for a passed story")
+	}
+}
@@ -0,0 +1,158 @@
+// Package schedule parses quiet hours: recurring times of the week during
+// which Chief doesn't start new iterations.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Window is one recurring quiet period, e.g. "09:00-18:00 weekdays".
+type Window struct {
+	Start int     // Minutes after midnight
+	End   int     // Minutes after midnight; End <= Start runs past midnight
+	Days  [7]bool // Days the window starts on, indexed by time.Weekday
+}
+
+// QuietHours is a set of quiet windows. A nil QuietHours is never quiet.
+type QuietHours []Window
+
+var dayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Parse parses quiet hour specs. Each spec is "HH:MM-HH:MM" followed by
+// optional days: "daily" (the default), "weekdays", "weekends", or a
+// comma-separated list of days and day ranges such as "mon-wed,fri".
+func Parse(specs []string) (QuietHours, error) {
+	var q QuietHours
+	for _, spec := range specs {
+		w, err := ParseWindow(spec)
+		if err != nil {
+			return nil, err
+		}
+		q = append(q, w)
+	}
+	return q, nil
+}
+
+// ParseWindow parses a single quiet hour spec, see Parse.
+func ParseWindow(spec string) (Window, error) {
+	fields := strings.Fields(strings.ReplaceAll(spec, "–", "-"))
+	if len(fields) == 0 || len(fields) > 2 {
+		return Window{}, fmt.Errorf("invalid quiet hours %q: want \"HH:MM-HH:MM [days]\"", spec)
+	}
+
+	var w Window
+	from, to, ok := strings.Cut(fields[0], "-")
+	if !ok {
+		return Window{}, fmt.Errorf("invalid quiet hours %q: want \"HH:MM-HH:MM [days]\"", spec)
+	}
+	var err error
+	if w.Start, err = parseClock(from); err != nil {
+		return Window{}, fmt.Errorf("invalid quiet hours %q: %w", spec, err)
+	}
+	if w.End, err = parseClock(to); err != nil {
+		return Window{}, fmt.Errorf("invalid quiet hours %q: %w", spec, err)
+	}
+
+	days := "daily"
+	if len(fields) == 2 {
+		days = fields[1]
+	}
+	if w.Days, err = parseDays(days); err != nil {
+		return Window{}, fmt.Errorf("invalid quiet hours %q: %w", spec, err)
+	}
+	return w, nil
+}
+
+// parseClock parses "HH:MM" into minutes after midnight. "24:00" is midnight
+// at the end of the day.
+func parseClock(s string) (int, error) {
+	hh, mm, ok := strings.Cut(s, ":")
+	h, herr := strconv.Atoi(hh)
+	m, merr := strconv.Atoi(mm)
+	if !ok || herr != nil || merr != nil || h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("%q is not a time of day (HH:MM)", s)
+	}
+	return h*60 + m, nil
+}
+
+// parseDays parses a day list into the days it includes.
+func parseDays(s string) ([7]bool, error) {
+	var days [7]bool
+	switch strings.ToLower(s) {
+	case "daily":
+		return [7]bool{true, true, true, true, true, true, true}, nil
+	case "weekdays":
+		return [7]bool{false, true, true, true, true, true, false}, nil
+	case "weekends":
+		return [7]bool{true, false, false, false, false, false, true}, nil
+	}
+
+	for _, part := range strings.Split(strings.ToLower(s), ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		first, ok := dayNames[from]
+		if !ok {
+			return days, fmt.Errorf("unknown day %q", from)
+		}
+		last := first
+		if isRange {
+			if last, ok = dayNames[to]; !ok {
+				return days, fmt.Errorf("unknown day %q", to)
+			}
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// Until reports whether t falls in quiet hours and, if so, when they end.
+// Back-to-back windows are joined, so the end is when work may start again.
+func (q QuietHours) Until(t time.Time) (time.Time, bool) {
+	end, quiet := q.windowEnd(t)
+	if !quiet {
+		return time.Time{}, false
+	}
+	// A week of chained windows means it is always quiet; stop looking
+	for i := 0; i < 7*len(q); i++ {
+		next, ok := q.windowEnd(end)
+		if !ok {
+			break
+		}
+		end = next
+	}
+	return end, true
+}
+
+// windowEnd returns the latest end of the windows that contain t.
+func (q QuietHours) windowEnd(t time.Time) (time.Time, bool) {
+	var end time.Time
+	for _, w := range q {
+		// A window containing t started today or, past midnight, yesterday
+		for _, offset := range []int{0, -1} {
+			y, m, d := t.AddDate(0, 0, offset).Date()
+			day := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+			if !w.Days[day.Weekday()] {
+				continue
+			}
+			start := time.Date(y, m, d, 0, w.Start, 0, 0, t.Location())
+			stop := time.Date(y, m, d, 0, w.End, 0, 0, t.Location())
+			if w.End <= w.Start {
+				stop = time.Date(y, m, d+1, 0, w.End, 0, 0, t.Location())
+			}
+			if !t.Before(start) && t.Before(stop) && stop.After(end) {
+				end = stop
+			}
+		}
+	}
+	return end, !end.IsZero()
+}
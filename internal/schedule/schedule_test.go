@@ -0,0 +1,89 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		spec    string
+		start   int
+		end     int
+		days    string // Days included, Sunday first
+		wantErr string
+	}{
+		{spec: "09:00-18:00 weekdays", start: 540, end: 1080, days: "0111110"},
+		{spec: "09:00–18:00", start: 540, end: 1080, days: "1111111"},
+		{spec: "22:30-06:00 fri-mon", start: 1350, end: 360, days: "1100011"},
+		{spec: "12:00-13:00 mon,wed", start: 720, end: 780, days: "0101000"},
+		{spec: "00:00-24:00 weekends", start: 0, end: 1440, days: "1000001"},
+		{spec: "9-18", wantErr: "not a time of day"},
+		{spec: "09:00-18:00 someday", wantErr: `unknown day "someday"`},
+		{spec: "09:00", wantErr: "want"},
+	}
+	for _, tt := range tests {
+		w, err := ParseWindow(tt.spec)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseWindow(%q) error = %v, want %q", tt.spec, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseWindow(%q) error = %v", tt.spec, err)
+			continue
+		}
+		days := ""
+		for _, on := range w.Days {
+			if on {
+				days += "1"
+			} else {
+				days += "0"
+			}
+		}
+		if w.Start != tt.start || w.End != tt.end || days != tt.days {
+			t.Errorf("ParseWindow(%q) = %d-%d %s, want %d-%d %s", tt.spec, w.Start, w.End, days, tt.start, tt.end, tt.days)
+		}
+	}
+}
+
+func TestQuietHours_Until(t *testing.T) {
+	q, err := Parse([]string{"09:00-18:00 weekdays", "18:00-19:00 fri", "23:00-01:00 sat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(day, clock string) time.Time {
+		ts, err := time.ParseInLocation("2006-01-02 15:04", day+" "+clock, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+
+	// 2026-03-02 is a Monday
+	tests := []struct {
+		name  string
+		t     time.Time
+		quiet bool
+		until time.Time
+	}{
+		{"before work", at("2026-03-02", "08:59"), false, time.Time{}},
+		{"during work", at("2026-03-02", "12:00"), true, at("2026-03-02", "18:00")},
+		{"end is exclusive", at("2026-03-02", "18:00"), false, time.Time{}},
+		{"back-to-back windows join", at("2026-03-06", "10:00"), true, at("2026-03-06", "19:00")},
+		{"weekend", at("2026-03-07", "12:00"), false, time.Time{}},
+		{"past midnight", at("2026-03-08", "00:30"), true, at("2026-03-08", "01:00")},
+	}
+	for _, tt := range tests {
+		until, quiet := q.Until(tt.t)
+		if quiet != tt.quiet || !until.Equal(tt.until) {
+			t.Errorf("%s: Until() = %v, %v, want %v, %v", tt.name, until, quiet, tt.until, tt.quiet)
+		}
+	}
+
+	if _, quiet := QuietHours(nil).Until(time.Now()); quiet {
+		t.Error("expected no quiet hours to never be quiet")
+	}
+}
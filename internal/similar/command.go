@@ -0,0 +1,138 @@
+package similar
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/minicodemonkey/chief/internal/config"
+)
+
+// cacheFile holds cached embeddings, relative to the project root.
+const cacheFile = ".chief/embeddings.json"
+
+// Command is an embedder backed by an external program, so any embedding
+// model can be plugged in. The program is run with `sh -c`, receives
+// {"texts": [...]} on stdin, and must print {"embeddings": [[...], ...]}
+// with one vector per text.
+type Command struct {
+	Command string
+}
+
+// Name implements Embedder.
+func (c Command) Name() string { return "command:" + c.Command }
+
+// Embed implements Embedder.
+func (c Command) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	input, err := json.Marshal(map[string][]string{"texts": texts})
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", c.Command)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("embedding command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var out struct {
+		Embeddings [][]float64 `json:"embeddings"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return nil, fmt.Errorf("embedding command printed invalid JSON: %w", err)
+	}
+	if len(out.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("embedding command returned %d vectors for %d texts", len(out.Embeddings), len(texts))
+	}
+	return out.Embeddings, nil
+}
+
+// Cached wraps an embedder with a vector cache on disk, so only new or
+// changed texts are sent to it.
+type Cached struct {
+	Embedder Embedder
+	Path     string
+}
+
+// Name implements Embedder.
+func (c Cached) Name() string { return c.Embedder.Name() }
+
+// Embed implements Embedder.
+func (c Cached) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	cache := make(map[string][]float64)
+	if data, err := os.ReadFile(c.Path); err == nil {
+		// A corrupt cache is rebuilt rather than reported
+		_ = json.Unmarshal(data, &cache)
+	}
+
+	vectors := make([][]float64, len(texts))
+	var missing []string
+	var missingIdx []int
+	for i, text := range texts {
+		if v, ok := cache[c.key(text)]; ok {
+			vectors[i] = v
+			continue
+		}
+		missing = append(missing, text)
+		missingIdx = append(missingIdx, i)
+	}
+	if len(missing) == 0 {
+		return vectors, nil
+	}
+
+	fresh, err := c.Embedder.Embed(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range missingIdx {
+		vectors[i] = fresh[j]
+		cache[c.key(missing[j])] = fresh[j]
+	}
+
+	if data, err := json.Marshal(cache); err == nil {
+		if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err == nil {
+			_ = os.WriteFile(c.Path, data, 0644)
+		}
+	}
+	return vectors, nil
+}
+
+func (c Cached) key(text string) string {
+	sum := sha256.Sum256([]byte(c.Embedder.Name() + "\x00" + text))
+	return hex.EncodeToString(sum[:16])
+}
+
+// New returns the embedder configured for the project at baseDir, or nil when
+// similarity checks are turned off.
+func New(cfg config.SimilarConfig, baseDir string) (Embedder, error) {
+	switch cfg.Provider {
+	case "", "local":
+		return Local{}, nil
+	case "command":
+		if cfg.Command == "" {
+			return nil, fmt.Errorf("similar.provider is \"command\" but similar.command is empty")
+		}
+		return Cached{Embedder: Command{Command: cfg.Command}, Path: filepath.Join(baseDir, cacheFile)}, nil
+	case "off":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown similar.provider %q: expected \"local\", \"command\", or \"off\"", cfg.Provider)
+	}
+}
+
+// Threshold returns the configured duplicate threshold, or DefaultThreshold.
+func Threshold(cfg config.SimilarConfig) float64 {
+	if cfg.Threshold > 0 {
+		return cfg.Threshold
+	}
+	return DefaultThreshold
+}
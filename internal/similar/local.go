@@ -0,0 +1,87 @@
+package similar
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+)
+
+// localDims is the size of vectors produced by Local.
+const localDims = 512
+
+// stopWords are skipped when embedding; they carry little meaning in stories.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "as": true, "at": true, "be": true, "by": true,
+	"can": true, "for": true, "from": true, "i": true, "in": true, "is": true, "it": true,
+	"my": true, "of": true, "on": true, "or": true, "so": true, "that": true, "the": true,
+	"to": true, "want": true, "we": true, "when": true, "with": true, "user": true,
+}
+
+// Local is an embedder that needs no model or network. It hashes word
+// stems and word pairs into a fixed-size vector, so stories that share
+// vocabulary score as similar. It catches restated stories well but not
+// paraphrases; use a command embedder backed by a real model for those.
+type Local struct{}
+
+// Name implements Embedder.
+func (Local) Name() string { return "local-v1" }
+
+// Embed implements Embedder.
+func (Local) Embed(_ context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = localVector(text)
+	}
+	return vectors, nil
+}
+
+func localVector(text string) []float64 {
+	v := make([]float64, localDims)
+	var prev string
+	for _, word := range normalize(text) {
+		if stopWords[word] {
+			prev = ""
+			continue
+		}
+		word = stem(word)
+		add(v, word, 1)
+		if prev != "" {
+			add(v, prev+" "+word, 0.5)
+		}
+		prev = word
+	}
+
+	// Sublinear term frequency keeps repeated words from dominating
+	var norm float64
+	for i, x := range v {
+		if x > 0 {
+			v[i] = 1 + math.Log(x)
+		}
+		norm += v[i] * v[i]
+	}
+	if norm > 0 {
+		norm = math.Sqrt(norm)
+		for i := range v {
+			v[i] /= norm
+		}
+	}
+	return v
+}
+
+func add(v []float64, feature string, weight float64) {
+	h := fnv.New32a()
+	h.Write([]byte(feature))
+	v[h.Sum32()%localDims] += weight
+}
+
+// stem strips common English suffixes so "limits", "limiting", and "limited"
+// count as the same word.
+func stem(word string) string {
+	for _, suffix := range []string{"ing", "ed", "es", "s"} {
+		if len(word) > len(suffix)+2 && strings.HasSuffix(word, suffix) {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
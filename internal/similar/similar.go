@@ -0,0 +1,191 @@
+// Package similar finds stories that resemble each other across a project's
+// PRDs. Stories are turned into embedding vectors by a pluggable Embedder and
+// compared by cosine similarity, which answers "has this been done before?"
+// and flags likely duplicates when a PRD is created or edited.
+package similar
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// DefaultThreshold is the similarity above which two stories are reported as
+// likely duplicates.
+const DefaultThreshold = 0.75
+
+// Embedder turns texts into vectors whose cosine similarity reflects how
+// alike the texts are.
+type Embedder interface {
+	// Name identifies the embedder. It keys cached vectors, so it should
+	// change when the vectors would.
+	Name() string
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// Story is a story from one of the project's PRDs.
+type Story struct {
+	PRD         string
+	ID          string
+	Title       string
+	Description string
+	Passes      bool
+}
+
+// Text returns what is embedded for the story.
+func (s Story) Text() string {
+	if s.Description == "" {
+		return s.Title
+	}
+	return s.Title + ". " + s.Description
+}
+
+// Match is a story with its similarity to a query.
+type Match struct {
+	Story
+	Score float64
+}
+
+// Duplicate pairs an incoming story with the existing story it closely matches.
+type Duplicate struct {
+	Story    Story
+	Existing Story
+	Score    float64
+}
+
+// LoadStories reads the stories of every PRD under baseDir/.chief/prds.
+// PRDs that can't be parsed are skipped.
+func LoadStories(baseDir string) ([]Story, error) {
+	prdsDir := filepath.Join(baseDir, ".chief", "prds")
+	entries, err := os.ReadDir(prdsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read PRDs: %w", err)
+	}
+
+	var stories []Story
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		p, err := prd.LoadPRD(filepath.Join(prdsDir, entry.Name(), "prd.md"))
+		if err != nil {
+			continue
+		}
+		stories = append(stories, FromPRD(entry.Name(), p)...)
+	}
+	return stories, nil
+}
+
+// FromPRD returns the stories of a parsed PRD.
+func FromPRD(name string, p *prd.PRD) []Story {
+	stories := make([]Story, 0, len(p.UserStories))
+	for _, s := range p.UserStories {
+		stories = append(stories, Story{PRD: name, ID: s.ID, Title: s.Title, Description: s.Description, Passes: s.Passes})
+	}
+	return stories
+}
+
+// Search returns up to limit stories ordered by similarity to query.
+func Search(ctx context.Context, e Embedder, query string, stories []Story, limit int) ([]Match, error) {
+	if len(stories) == 0 {
+		return nil, nil
+	}
+	texts := make([]string, 0, len(stories)+1)
+	texts = append(texts, query)
+	for _, s := range stories {
+		texts = append(texts, s.Text())
+	}
+	vectors, err := e.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]Match, len(stories))
+	for i, s := range stories {
+		matches[i] = Match{Story: s, Score: Cosine(vectors[0], vectors[i+1])}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// FindDuplicates compares each incoming story against the existing ones and
+// returns the best match for every incoming story scoring at least threshold.
+// Stories from the same PRD as the incoming story aren't compared.
+func FindDuplicates(ctx context.Context, e Embedder, incoming, existing []Story, threshold float64) ([]Duplicate, error) {
+	if len(incoming) == 0 || len(existing) == 0 {
+		return nil, nil
+	}
+	texts := make([]string, 0, len(incoming)+len(existing))
+	for _, s := range incoming {
+		texts = append(texts, s.Text())
+	}
+	for _, s := range existing {
+		texts = append(texts, s.Text())
+	}
+	vectors, err := e.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	var dups []Duplicate
+	for i, s := range incoming {
+		best := Duplicate{Score: -1}
+		for j, other := range existing {
+			if other.PRD == s.PRD {
+				continue
+			}
+			if score := Cosine(vectors[i], vectors[len(incoming)+j]); score > best.Score {
+				best = Duplicate{Story: s, Existing: other, Score: score}
+			}
+		}
+		if best.Score >= threshold {
+			dups = append(dups, best)
+		}
+	}
+	return dups, nil
+}
+
+// Cosine returns the cosine similarity of two vectors, or 0 if either is zero
+// or their lengths differ.
+func Cosine(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// FormatStatus returns a short label for a story's state.
+func FormatStatus(s Story) string {
+	if s.Passes {
+		return "done"
+	}
+	return "open"
+}
+
+// normalize lowercases text and splits it into words.
+func normalize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r > 127)
+	})
+}
@@ -0,0 +1,139 @@
+package similar
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/config"
+)
+
+func writePRD(t *testing.T, baseDir, name, content string) {
+	t.Helper()
+	dir := filepath.Join(baseDir, ".chief", "prds", name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "prd.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLocalSimilarity(t *testing.T) {
+	ctx := context.Background()
+	vectors, _ := Local{}.Embed(ctx, []string{
+		"Rate limit login attempts. As a user, I want repeated failed logins to be rate limited.",
+		"Add rate limiting to login. Failed login attempts are limited per IP.",
+		"Export invoices as CSV. As an accountant, I want to download invoices.",
+	})
+	dup, unrelated := Cosine(vectors[0], vectors[1]), Cosine(vectors[0], vectors[2])
+	if dup <= unrelated {
+		t.Errorf("expected restated stories to score higher than unrelated ones: %.2f vs %.2f", dup, unrelated)
+	}
+	if unrelated > 0.3 {
+		t.Errorf("expected unrelated stories to score low, got %.2f", unrelated)
+	}
+	if same := Cosine(vectors[0], vectors[0]); same < 0.999 {
+		t.Errorf("expected identical text to score 1, got %.2f", same)
+	}
+}
+
+func TestSearchAndDuplicates(t *testing.T) {
+	base := t.TempDir()
+	writePRD(t, base, "auth", `# Auth
+
+### US-001: Rate limit login attempts
+**Status:** done
+**Description:** Limit failed login attempts per IP address.
+
+### US-002: Password reset email
+**Description:** Send a reset link by email.
+`)
+	writePRD(t, base, "billing", `# Billing
+
+### US-001: Export invoices as CSV
+**Description:** Download all invoices as a CSV file.
+`)
+
+	stories, err := LoadStories(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stories) != 3 {
+		t.Fatalf("expected 3 stories, got %d", len(stories))
+	}
+
+	matches, err := Search(context.Background(), Local{}, "rate limiting failed login attempts", stories, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 || matches[0].PRD != "auth" || matches[0].ID != "US-001" {
+		t.Fatalf("expected auth/US-001 first, got %+v", matches)
+	}
+
+	incoming := []Story{
+		{PRD: "api", ID: "US-001", Title: "Rate limit login attempts", Description: "Limit failed login attempts per IP address."},
+		{PRD: "api", ID: "US-002", Title: "Paginate the orders endpoint"},
+	}
+	dups, err := FindDuplicates(context.Background(), Local{}, incoming, stories, DefaultThreshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dups) != 1 || dups[0].Story.ID != "US-001" || dups[0].Existing.PRD != "auth" {
+		t.Errorf("expected one duplicate of auth/US-001, got %+v", dups)
+	}
+
+	// Stories in the same PRD are not duplicates of themselves
+	dups, _ = FindDuplicates(context.Background(), Local{}, stories[:1], stories, DefaultThreshold)
+	if len(dups) != 0 {
+		t.Errorf("expected no duplicates within the same PRD, got %+v", dups)
+	}
+}
+
+func TestCommandEmbedderWithCache(t *testing.T) {
+	base := t.TempDir()
+	counter := filepath.Join(base, "calls")
+	// Returns a vector per text from its length and counts invocations
+	script := `echo x >> ` + counter + `; python3 -c 'import json,sys; t=json.load(sys.stdin)["texts"]; print(json.dumps({"embeddings": [[len(s), 1] for s in t]}))'`
+	if _, err := os.Stat("/usr/bin/python3"); err != nil {
+		t.Skip("python3 not available")
+	}
+
+	e, err := New(config.SimilarConfig{Provider: "command", Command: script}, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		vectors, err := e.Embed(context.Background(), []string{"ab", "abcd"})
+		if err != nil {
+			t.Fatalf("Embed() error = %v", err)
+		}
+		if vectors[0][0] != 2 || vectors[1][0] != 4 {
+			t.Errorf("unexpected vectors %v", vectors)
+		}
+	}
+	calls, _ := os.ReadFile(counter)
+	if n := strings.Count(string(calls), "x"); n != 1 {
+		t.Errorf("expected the second call to be served from the cache, command ran %d times", n)
+	}
+	if _, err := os.Stat(filepath.Join(base, cacheFile)); err != nil {
+		t.Errorf("expected cache file: %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if e, err := New(config.SimilarConfig{}, ""); err != nil || e == nil || e.Name() != (Local{}).Name() {
+		t.Errorf("expected local embedder by default, got %v, %v", e, err)
+	}
+	if e, err := New(config.SimilarConfig{Provider: "off"}, ""); err != nil || e != nil {
+		t.Errorf("expected nil embedder when off, got %v, %v", e, err)
+	}
+	if _, err := New(config.SimilarConfig{Provider: "command"}, ""); err == nil {
+		t.Error("expected error for command provider without a command")
+	}
+	if _, err := New(config.SimilarConfig{Provider: "bogus"}, ""); err == nil {
+		t.Error("expected error for unknown provider")
+	}
+}
@@ -0,0 +1,119 @@
+// Package timefmt formats timestamps and durations for display. Every
+// command, report and the TUI go through it, so times appear in the same
+// timezone and clock style everywhere: display.timezone and display.clock in
+// .chief/config.yaml, falling back to the TZ environment variable and a
+// 24-hour clock.
+package timefmt
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Clock styles.
+const (
+	Clock24 = "24h"
+	Clock12 = "12h"
+)
+
+var (
+	mu       sync.RWMutex
+	location = time.Local // Timezone times are shown in
+	clock12  bool         // Show times on a 12-hour clock
+)
+
+// Configure sets the timezone (an IANA name such as "Europe/Berlin"; "" keeps
+// TZ or the system's) and clock style ("24h" or "12h"; "" = 24h) times are
+// shown in.
+func Configure(timezone, clock string) error {
+	loc := time.Local
+	if timezone != "" {
+		var err error
+		loc, err = time.LoadLocation(timezone)
+		if err != nil {
+			return fmt.Errorf("display.timezone: unknown timezone %q", timezone)
+		}
+	}
+	if clock != "" && clock != Clock24 && clock != Clock12 {
+		return fmt.Errorf("display.clock must be %q or %q, got %q", Clock24, Clock12, clock)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	location, clock12 = loc, clock == Clock12
+	return nil
+}
+
+// layout returns the clock layout, with seconds when asked for.
+func layout(seconds bool) (string, *time.Location) {
+	mu.RLock()
+	defer mu.RUnlock()
+	switch {
+	case clock12 && seconds:
+		return "3:04:05 PM", location
+	case clock12:
+		return "3:04 PM", location
+	case seconds:
+		return "15:04:05", location
+	default:
+		return "15:04", location
+	}
+}
+
+// Clock formats the time of day, e.g. "14:05" or "2:05 PM".
+func Clock(t time.Time) string {
+	l, loc := layout(false)
+	return t.In(loc).Format(l)
+}
+
+// ClockSeconds formats the time of day to the second, e.g. "14:05:09".
+func ClockSeconds(t time.Time) string {
+	l, loc := layout(true)
+	return t.In(loc).Format(l)
+}
+
+// Weekday formats the day of the week and time, e.g. "Mon 14:05".
+func Weekday(t time.Time) string {
+	l, loc := layout(false)
+	return t.In(loc).Format("Mon " + l)
+}
+
+// Stamp formats a recent moment, e.g. "Mar 1 14:05".
+func Stamp(t time.Time) string {
+	l, loc := layout(false)
+	return t.In(loc).Format("Jan 2 " + l)
+}
+
+// DateTime formats a moment unambiguously, e.g. "2025-03-01 14:05".
+func DateTime(t time.Time) string {
+	l, loc := layout(false)
+	return t.In(loc).Format("2006-01-02 " + l)
+}
+
+// Date formats the day, e.g. "2025-03-01".
+func Date(t time.Time) string {
+	_, loc := layout(false)
+	return t.In(loc).Format("2006-01-02")
+}
+
+// Duration formats a duration compactly, e.g. "1h02m03s", "4m05s", "12s",
+// or "2.4s" for short durations with a fraction of a second.
+func Duration(d time.Duration) string {
+	if d <= 0 {
+		return "0s"
+	}
+	if d < 10*time.Second && d%time.Second != 0 {
+		return fmt.Sprintf("%.1fs", d.Round(100*time.Millisecond).Seconds())
+	}
+	d = d.Round(time.Second)
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	s := int(d.Seconds()) % 60
+	if h > 0 {
+		return fmt.Sprintf("%dh%02dm%02ds", h, m, s)
+	}
+	if m > 0 {
+		return fmt.Sprintf("%dm%02ds", m, s)
+	}
+	return fmt.Sprintf("%ds", s)
+}
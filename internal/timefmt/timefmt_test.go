@@ -0,0 +1,65 @@
+package timefmt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfigure(t *testing.T) {
+	t.Cleanup(func() { Configure("", "") })
+	at := time.Date(2025, 3, 1, 13, 5, 9, 0, time.UTC)
+
+	if err := Configure("Asia/Tokyo", Clock12); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"Clock", Clock(at), "10:05 PM"},
+		{"ClockSeconds", ClockSeconds(at), "10:05:09 PM"},
+		{"Weekday", Weekday(at), "Sat 10:05 PM"},
+		{"Stamp", Stamp(at), "Mar 1 10:05 PM"},
+		{"DateTime", DateTime(at), "2025-03-01 10:05 PM"},
+		{"Date", Date(at.Add(2 * time.Hour)), "2025-03-02"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if err := Configure("UTC", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := Stamp(at); got != "Mar 1 13:05" {
+		t.Errorf("Stamp = %q, want the 24-hour default", got)
+	}
+
+	if err := Configure("Mars/Olympus", ""); err == nil {
+		t.Error("expected an unknown timezone to be rejected")
+	}
+	if err := Configure("", "24-hour"); err == nil {
+		t.Error("expected an unknown clock style to be rejected")
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{2400 * time.Millisecond, "2.4s"},
+		{5 * time.Second, "5s"},
+		{12*time.Second + 300*time.Millisecond, "12s"},
+		{4*time.Minute + 5*time.Second, "4m05s"},
+		{time.Hour + 2*time.Minute + 3*time.Second, "1h02m03s"},
+	}
+	for _, tt := range tests {
+		if got := Duration(tt.d); got != tt.want {
+			t.Errorf("Duration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
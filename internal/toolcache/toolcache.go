@@ -0,0 +1,120 @@
+// Package toolcache remembers the output of expensive commands, such as a
+// full test suite, so running one again on unchanged files can reuse the
+// last result instead of waiting for it.
+package toolcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/git"
+	"github.com/minicodemonkey/chief/internal/timefmt"
+)
+
+// DirEnv passes the cache directory to `chief cache run` when it is started
+// by the agent.
+const DirEnv = "CHIEF_CACHE_DIR"
+
+// Marker starts every line Chief prints about a cached result.
+const Marker = "[chief cache]"
+
+// Entry is a remembered successful run of a command.
+type Entry struct {
+	Command string    `json:"command"`
+	Dir     string    `json:"dir"`  // Directory the command ran in
+	Tree    string    `json:"tree"` // WorkTreeHash of the files the command ran on
+	Output  string    `json:"output"`
+	Time    time.Time `json:"time"`
+}
+
+// Cache stores entries for commands matching its patterns.
+type Cache struct {
+	Dir      string
+	Patterns []string      // Glob patterns of cacheable commands, e.g. "go test ./..."
+	MaxAge   time.Duration // Entries older than this are ignored (0 = no limit)
+}
+
+// Dir returns the cache directory of a project.
+func Dir(baseDir string) string {
+	return filepath.Join(baseDir, ".chief", "cache")
+}
+
+// Matches reports whether command is cacheable. A "*" in a pattern matches
+// any text, and whitespace is compared loosely.
+func (c *Cache) Matches(command string) bool {
+	command = normalize(command)
+	for _, pattern := range c.Patterns {
+		expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(normalize(pattern)), `\*`, ".*") + "$"
+		if ok, _ := regexp.MatchString(expr, command); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup returns the entry for command run in workDir if no file in the
+// repository changed since it was stored. The tree hash is returned for Store.
+func (c *Cache) Lookup(workDir, command string) (*Entry, string, error) {
+	tree, err := git.WorkTreeHash(workDir)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := os.ReadFile(c.entryPath(workDir, command, tree))
+	if err != nil {
+		return nil, tree, nil
+	}
+	var e Entry
+	if json.Unmarshal(data, &e) != nil || e.Tree != tree || normalize(e.Command) != normalize(command) {
+		return nil, tree, nil
+	}
+	if c.MaxAge > 0 && time.Since(e.Time) > c.MaxAge {
+		return nil, tree, nil
+	}
+	return &e, tree, nil
+}
+
+// Store remembers a successful run of command in workDir on the tree from
+// Lookup.
+func (c *Cache) Store(workDir, command, tree, output string) error {
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(Entry{Command: command, Dir: workDir, Tree: tree, Output: output, Time: time.Now()})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.entryPath(workDir, command, tree), data, 0644)
+}
+
+// Clear removes every entry.
+func (c *Cache) Clear() error {
+	return os.RemoveAll(c.Dir)
+}
+
+// Banner describes a cached result, for the first line of its output.
+func (e *Entry) Banner() string {
+	return fmt.Sprintf("%s Reusing the result of `%s` from %s: no files changed since it passed. Run the command directly to force a fresh run.",
+		Marker, e.Command, timefmt.ClockSeconds(e.Time))
+}
+
+// entryPath returns where the entry for a command, directory, and tree is
+// stored.
+func (c *Cache) entryPath(workDir, command, tree string) string {
+	if abs, err := filepath.Abs(workDir); err == nil {
+		workDir = abs
+	}
+	sum := sha256.Sum256([]byte(normalize(command) + "\x00" + workDir + "\x00" + tree))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:12])+".json")
+}
+
+// normalize collapses runs of whitespace in a command.
+func normalize(command string) string {
+	return strings.Join(strings.Fields(command), " ")
+}
@@ -0,0 +1,72 @@
+package toolcache
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatches(t *testing.T) {
+	c := &Cache{Patterns: []string{"go test ./...", "npm test*", "pytest * -x"}}
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"go test ./...", true},
+		{"go  test   ./...", true},
+		{"go test ./pkg/...", false},
+		{"npm test -- --runInBand src/app.test.ts", true},
+		{"pytest tests/unit -x", true},
+		{"npm run build", false},
+	}
+	for _, tt := range tests {
+		if got := c.Matches(tt.command); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestLookupAndStore(t *testing.T) {
+	dir := t.TempDir()
+	for _, args := range [][]string{{"init"}, {"config", "user.email", "t@t.com"}, {"config", "user.name", "T"}} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Cache{Dir: Dir(dir), Patterns: []string{"go test ./..."}}
+	entry, tree, err := c.Lookup(dir, "go test ./...")
+	if err != nil || entry != nil || tree == "" {
+		t.Fatalf("Lookup() on an empty cache = %v, %q, %v", entry, tree, err)
+	}
+	if err := c.Store(dir, "go test ./...", tree, "ok  \tmain\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, _, err = c.Lookup(dir, "go  test ./...")
+	if err != nil || entry == nil || entry.Output != "ok  \tmain\n" {
+		t.Fatalf("expected a hit, got %+v, %v", entry, err)
+	}
+	if !strings.HasPrefix(entry.Banner(), Marker) {
+		t.Errorf("expected the banner to start with %s, got %q", Marker, entry.Banner())
+	}
+
+	c.MaxAge = time.Nanosecond
+	if entry, _, _ := c.Lookup(dir, "go test ./..."); entry != nil {
+		t.Error("expected an entry older than MaxAge to be ignored")
+	}
+	c.MaxAge = 0
+
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if entry, _, _ := c.Lookup(dir, "go test ./..."); entry != nil {
+		t.Error("expected a changed file to miss the cache")
+	}
+}
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -13,6 +14,7 @@ import (
 	"github.com/minicodemonkey/chief/internal/git"
 	"github.com/minicodemonkey/chief/internal/loop"
 	"github.com/minicodemonkey/chief/internal/prd"
+	"github.com/minicodemonkey/chief/internal/review"
 )
 
 // PRDUpdateMsg is sent when the PRD file changes.
@@ -147,6 +149,7 @@ const (
 	ViewCompletion
 	ViewSettings
 	ViewQuitConfirm
+	ViewStoryOrder
 )
 
 // App is the main Bubble Tea model for the Chief TUI.
@@ -219,6 +222,9 @@ type App struct {
 	// Quit confirmation dialog
 	quitConfirm *QuitConfirmation
 
+	// Story order screen
+	storyOrder *StoryOrder
+
 	// Completion notification callback
 	onCompletion func(prdName string)
 
@@ -342,6 +348,7 @@ func NewAppWithOptions(prdPath string, maxIter int, provider loop.Provider) (*Ap
 		completionScreen: NewCompletionScreen(),
 		settingsOverlay:  NewSettingsOverlay(),
 		quitConfirm:      NewQuitConfirmation(),
+		storyOrder:       NewStoryOrder(),
 	}, nil
 }
 
@@ -365,6 +372,36 @@ func (a *App) DisableRetry() {
 	}
 }
 
+// IgnoreQuietHours lets loops run through the configured quiet hours.
+func (a *App) IgnoreQuietHours() {
+	if a.manager != nil {
+		a.manager.IgnoreQuietHours()
+	}
+}
+
+// SetBase records that the current PRD's run is pinned to base on branch.
+func (a *App) SetBase(base loop.RunBase, branch string) {
+	if a.manager != nil {
+		a.manager.UpdateWorktreeInfo(a.prdName, "", branch)
+		a.manager.SetBase(a.prdName, base)
+	}
+}
+
+// SetLabels records labels with each run of the current PRD.
+func (a *App) SetLabels(labels []string) {
+	if a.manager != nil {
+		a.manager.SetLabels(a.prdName, labels)
+	}
+}
+
+// SetParallel makes runs of the current PRD work on up to n stories side by
+// side, each in a worktree of its own.
+func (a *App) SetParallel(n int) {
+	if a.manager != nil {
+		a.manager.SetParallel(a.prdName, n)
+	}
+}
+
 // Init initializes the App.
 func (a App) Init() tea.Cmd {
 	// Start the file watcher
@@ -419,6 +456,9 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case LoopFinishedMsg:
 		return a.handleLoopFinished(msg.PRDName, msg.Err)
 
+	case RemoteCommandMsg:
+		return a.handleRemoteCommand(msg)
+
 	case PRDCompletedMsg:
 		// A PRD completed - trigger completion notification
 		if a.onCompletion != nil {
@@ -564,6 +604,11 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a.handleQuitConfirmKeys(msg)
 		}
 
+		// Handle story order screen
+		if a.viewMode == ViewStoryOrder {
+			return a.handleStoryOrderKeys(msg)
+		}
+
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return a.tryQuit()
@@ -630,6 +675,16 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return a, nil
 
+		// Reorder or skip the current PRD's stories
+		case "o":
+			if a.viewMode == ViewDashboard || a.viewMode == ViewLog {
+				a.previousViewMode = a.viewMode
+				a.storyOrder.Load(a.prd)
+				a.storyOrder.SetSize(a.width, a.height)
+				a.viewMode = ViewStoryOrder
+			}
+			return a, nil
+
 		// Number keys 1-9 to switch PRDs
 		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
 			if a.viewMode == ViewDashboard || a.viewMode == ViewLog || a.viewMode == ViewDiff {
@@ -904,6 +959,45 @@ func (a App) handleQuitConfirmKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return a, nil
 }
 
+// handleStoryOrderKeys handles keyboard input for the story order screen.
+func (a App) handleStoryOrderKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		a.viewMode = a.previousViewMode
+	case "up", "k":
+		a.storyOrder.MoveUp()
+	case "down", "j":
+		a.storyOrder.MoveDown()
+	case "K", "shift+up":
+		a.storyOrder.ShiftUp()
+	case "J", "shift+down":
+		a.storyOrder.ShiftDown()
+	case "n":
+		a.storyOrder.RunNext()
+	case " ", "space":
+		a.storyOrder.ToggleSkip()
+	case "enter":
+		if a.storyOrder.HasChanges() {
+			if err := a.storyOrder.Save(a.prdPath); err != nil {
+				a.lastActivity = "Error saving story order: " + err.Error()
+			} else {
+				a.lastActivity = "Story order saved"
+			}
+			if p, err := prd.LoadPRD(a.prdPath); err == nil {
+				a.prd = p
+			}
+		}
+		a.viewMode = a.previousViewMode
+	}
+	return a, nil
+}
+
+// renderStoryOrderView renders the story order screen.
+func (a *App) renderStoryOrderView() string {
+	a.storyOrder.SetSize(a.width, a.height)
+	return a.storyOrder.Render()
+}
+
 // renderQuitConfirmView renders the quit confirmation dialog.
 func (a *App) renderQuitConfirmView() string {
 	a.quitConfirm.SetSize(a.width, a.height)
@@ -964,6 +1058,7 @@ func (a App) handleLoopEvent(prdName string, event loop.Event) (tea.Model, tea.C
 			// Finalize the last story's timing
 			a.finalizeStoryTiming()
 			autoActionCmd = a.showCompletionScreen(prdName)
+			a.completionScreen.SetCoverage(event.Text)
 		} else {
 			// For background PRDs, trigger auto-push/PR without showing completion screen
 			autoActionCmd = a.runBackgroundAutoActions(prdName)
@@ -989,7 +1084,9 @@ func (a App) handleLoopEvent(prdName string, event loop.Event) (tea.Model, tea.C
 		if isCurrentPRD {
 			a.lastActivity = event.Text
 		}
-	case loop.EventWatchdogTimeout:
+	case loop.EventWatchdogTimeout, loop.EventStalled, loop.EventStoryTimeout, loop.EventCommitRejected, loop.EventCoverage,
+		loop.EventVerify, loop.EventFlakyTest, loop.EventStackedPR, loop.EventStopped,
+		loop.EventCircuitOpen, loop.EventQuietHours, loop.EventWaitingOnHuman, loop.EventDirtyWorktree, loop.EventSummary, loop.EventExplore, loop.EventPush, loop.EventStoryBlocked, loop.EventStoryMerged, loop.EventRemoteSync:
 		if isCurrentPRD {
 			a.lastActivity = event.Text
 		}
@@ -998,7 +1095,7 @@ func (a App) handleLoopEvent(prdName string, event loop.Event) (tea.Model, tea.C
 	// Reload PRD from disk only on meaningful state changes (not every event)
 	if isCurrentPRD {
 		switch event.Type {
-		case loop.EventStoryDone, loop.EventComplete, loop.EventError, loop.EventMaxIterationsReached:
+		case loop.EventStoryDone, loop.EventStoryPassed, loop.EventStoryMerged, loop.EventComplete, loop.EventError, loop.EventMaxIterationsReached:
 			if p, err := prd.LoadPRD(a.prdPath); err == nil {
 				a.prd = p
 			}
@@ -1077,6 +1174,8 @@ func (a App) View() string {
 		return a.renderSettingsView()
 	case ViewQuitConfirm:
 		return a.renderQuitConfirmView()
+	case ViewStoryOrder:
+		return a.renderStoryOrderView()
 	default:
 		return a.renderDashboard()
 	}
@@ -1377,13 +1476,25 @@ func (a App) handleBackgroundAutoAction(msg backgroundAutoActionResultMsg) (tea.
 			branch := instance.Branch
 			dir := a.baseDir
 			prdPath := filepath.Join(a.baseDir, ".chief", "prds", prdName, "prd.json")
+			workDir := dir
+			if instance.WorktreeDir != "" {
+				workDir = instance.WorktreeDir
+			}
+			gate := a.securityGate(prdName, workDir)
+			prBody := a.prBody(prdName)
 			return a, func() tea.Msg {
 				p, err := prd.LoadPRD(prdPath)
 				if err != nil {
 					return backgroundAutoActionResultMsg{prdName: prdName, action: "pr", err: err}
 				}
+				if err := gate(); err != nil {
+					return backgroundAutoActionResultMsg{prdName: prdName, action: "pr", err: err}
+				}
 				title := git.PRTitleFromPRD(prdName, p)
-				body := git.PRBodyFromPRD(p)
+				body, err := prBody(p)
+				if err != nil {
+					return backgroundAutoActionResultMsg{prdName: prdName, action: "pr", err: err}
+				}
 				_, err = git.CreatePR(dir, branch, title, body)
 				return backgroundAutoActionResultMsg{prdName: prdName, action: "pr", err: err}
 			}
@@ -1413,6 +1524,13 @@ func (a *App) runAutoCreatePR() tea.Cmd {
 	branch := a.completionScreen.Branch()
 	dir := a.baseDir
 
+	workDir := dir
+	if instance := a.manager.GetInstance(prdName); instance != nil && instance.WorktreeDir != "" {
+		workDir = instance.WorktreeDir
+	}
+	gate := a.securityGate(prdName, workDir)
+	prBody := a.prBody(prdName)
+
 	// Load the PRD to generate PR content
 	prdPath := filepath.Join(a.baseDir, ".chief", "prds", prdName, "prd.json")
 	return func() tea.Msg {
@@ -1420,8 +1538,14 @@ func (a *App) runAutoCreatePR() tea.Cmd {
 		if err != nil {
 			return autoActionResultMsg{action: "pr", err: fmt.Errorf("failed to load PRD: %s", err.Error())}
 		}
+		if err := gate(); err != nil {
+			return autoActionResultMsg{action: "pr", err: err}
+		}
 		title := git.PRTitleFromPRD(prdName, p)
-		body := git.PRBodyFromPRD(p)
+		body, err := prBody(p)
+		if err != nil {
+			return autoActionResultMsg{action: "pr", err: err}
+		}
 		url, err := git.CreatePR(dir, branch, title, body)
 		if err != nil {
 			return autoActionResultMsg{action: "pr", err: err}
@@ -1430,6 +1554,54 @@ func (a *App) runAutoCreatePR() tea.Cmd {
 	}
 }
 
+// prBody returns a function rendering the PR body for prdName from its PRD,
+// using onComplete.prTemplate when set. Run stats are captured now, while the
+// instance still describes the finished run.
+func (a *App) prBody(prdName string) func(p *prd.PRD) (string, error) {
+	var iterations int
+	var duration time.Duration
+	if instance := a.manager.GetInstance(prdName); instance != nil {
+		iterations = instance.Iteration
+		if !instance.StartTime.IsZero() {
+			duration = time.Since(instance.StartTime)
+		}
+	}
+	var templatePath string
+	if a.config != nil {
+		templatePath = a.config.OnComplete.PRTemplate
+	}
+	baseDir := a.baseDir
+	prdDir := filepath.Join(baseDir, ".chief", "prds", prdName)
+
+	return func(p *prd.PRD) (string, error) {
+		tmpl, err := git.LoadPRTemplate(baseDir, templatePath)
+		if err != nil {
+			return "", err
+		}
+		data := git.NewPRBodyData(baseDir, prdName, prdDir, p)
+		data.SetRunStats(iterations, duration)
+		return git.RenderPRBody(tmpl, data)
+	}
+}
+
+// securityGate returns a check to run before creating a PR for prdName. When
+// review.blockPR is set it runs the security review over workDir and fails on
+// high-severity findings; otherwise it always passes.
+func (a *App) securityGate(prdName, workDir string) func() error {
+	if a.config == nil || !a.config.Review.BlockPR {
+		return func() error { return nil }
+	}
+	opts := review.SecurityOptions{
+		Provider: a.provider,
+		PRDName:  prdName,
+		PRDPath:  filepath.Join(a.baseDir, ".chief", "prds", prdName, "prd.md"),
+		WorkDir:  workDir,
+	}
+	return func() error {
+		return review.Gate(context.Background(), opts)
+	}
+}
+
 // renderCompletionView renders the completion screen.
 func (a *App) renderCompletionView() string {
 	a.completionScreen.SetSize(a.width, a.height)
@@ -2130,11 +2302,15 @@ func (a *App) storiesListHeight() int {
 		fh = 0
 	}
 	contentHeight := a.height - a.effectiveHeaderHeight() - fh - 2
+	waiting := 0
+	if a.prd != nil {
+		waiting = waitingSectionHeight(len(a.prd.WaitingOnHumans()))
+	}
 	if a.isNarrowMode() {
 		storiesHeight := max((contentHeight*40)/100, 5)
-		return storiesHeight - 5
+		return storiesHeight - 5 - waiting
 	}
-	return contentHeight - 5
+	return contentHeight - 5 - waiting
 }
 
 // adjustStoriesScroll ensures the selected index is visible in the scroll window.
@@ -2210,6 +2386,16 @@ func (a *App) selectInProgressStory() {
 	}
 }
 
+// GetManager returns the loop manager running this app's PRDs.
+func (a *App) GetManager() *loop.Manager {
+	return a.manager
+}
+
+// GetBaseDir returns the project root directory.
+func (a *App) GetBaseDir() string {
+	return a.baseDir
+}
+
 // GetState returns the current app state.
 func (a *App) GetState() AppState {
 	return a.state
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/minicodemonkey/chief/internal/timefmt"
 )
 
 // AutoActionState represents the progress of an auto-action (push or PR).
@@ -40,6 +41,7 @@ type CompletionScreen struct {
 	// Duration data
 	totalDuration time.Duration
 	storyTimings  []StoryTiming
+	coverage      string // Coverage change over the run ("" = not tracked)
 
 	// Confetti animation
 	confetti *Confetti
@@ -69,6 +71,7 @@ func (c *CompletionScreen) Configure(prdName string, completed, total int, branc
 	c.hasAutoActions = hasAutoActions
 	c.totalDuration = totalDuration
 	c.storyTimings = storyTimings
+	c.coverage = ""
 	// Reset auto-action state
 	c.pushState = AutoActionIdle
 	c.pushError = ""
@@ -85,6 +88,11 @@ func (c *CompletionScreen) Configure(prdName string, completed, total int, branc
 	}
 }
 
+// SetCoverage sets the coverage change over the run, e.g. "Coverage 70.0% → 72.5% (+2.5)".
+func (c *CompletionScreen) SetCoverage(summary string) {
+	c.coverage = summary
+}
+
 // SetSize sets the screen dimensions.
 func (c *CompletionScreen) SetSize(width, height int) {
 	c.width = width
@@ -199,7 +207,13 @@ func (c *CompletionScreen) Render() string {
 	if c.totalDuration > 0 {
 		content.WriteString("\n")
 		durationStyle := lipgloss.NewStyle().Foreground(SuccessColor)
-		content.WriteString(durationStyle.Render(fmt.Sprintf("Completed in %s", formatDuration(c.totalDuration))))
+		content.WriteString(durationStyle.Render(fmt.Sprintf("Completed in %s", timefmt.Duration(c.totalDuration))))
+		content.WriteString("\n")
+	}
+
+	// Coverage change over the run
+	if c.coverage != "" {
+		content.WriteString(lipgloss.NewStyle().Foreground(TextColor).Render(c.coverage))
 		content.WriteString("\n")
 	}
 
@@ -369,7 +383,7 @@ func (c *CompletionScreen) renderStoryTimings(innerWidth int) string {
 		}
 
 		// Duration string (right-aligned in 8 chars)
-		durStr := formatDuration(st.Duration)
+		durStr := timefmt.Duration(st.Duration)
 		if len(durStr) > 8 {
 			durStr = durStr[:8]
 		}
@@ -2,11 +2,14 @@ package tui
 
 import (
 	"fmt"
+	"os"
 	"strings"
-	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/minicodemonkey/chief/internal/loop"
 	"github.com/minicodemonkey/chief/internal/prd"
+	"github.com/minicodemonkey/chief/internal/review"
+	"github.com/minicodemonkey/chief/internal/timefmt"
 )
 
 const (
@@ -168,7 +171,7 @@ func (a *App) renderHeader() string {
 
 	// Elapsed time
 	elapsed := a.GetElapsedTime()
-	elapsedStr := SubtitleStyle.Render(fmt.Sprintf("Time: %s", formatDuration(elapsed)))
+	elapsedStr := SubtitleStyle.Render(fmt.Sprintf("Time: %s", timefmt.Duration(elapsed)))
 
 	// Combine elements
 	leftPart := lipgloss.JoinHorizontal(lipgloss.Center, brand, "  ", state)
@@ -216,7 +219,7 @@ func (a *App) renderNarrowHeader() string {
 
 	// Condensed iteration and time
 	elapsed := a.GetElapsedTime()
-	iterTime := SubtitleStyle.Render(fmt.Sprintf("#%d %s", a.iteration, formatDuration(elapsed)))
+	iterTime := SubtitleStyle.Render(fmt.Sprintf("#%d %s", a.iteration, timefmt.Duration(elapsed)))
 
 	// Combine elements
 	leftPart := lipgloss.JoinHorizontal(lipgloss.Center, brand, " ", state)
@@ -267,8 +270,12 @@ func (a *App) renderFooter() string {
 	}
 	shortcutsStr := footerStyle.Render(strings.Join(shortcuts, "  │  "))
 
-	// PRD name
-	prdInfo := footerStyle.Render(fmt.Sprintf("PRD: %s", a.prdName))
+	// PRD name, with what the run has cost so far
+	prdText := fmt.Sprintf("PRD: %s", a.prdName)
+	if usage := a.runUsage(); usage.Tokens() > 0 {
+		prdText = usage.String() + "  │  " + prdText
+	}
+	prdInfo := footerStyle.Render(prdText)
 
 	// Create footer line with proper spacing
 	spacing := strings.Repeat(" ", max(0, a.width-lipgloss.Width(shortcutsStr)-lipgloss.Width(prdInfo)-2))
@@ -283,6 +290,17 @@ func (a *App) renderFooter() string {
 	return lipgloss.JoinVertical(lipgloss.Left, border, activityLine, footerLine)
 }
 
+// runUsage returns the tokens and cost of the current PRD's run so far.
+func (a *App) runUsage() loop.UsageTotals {
+	if a.manager == nil {
+		return loop.UsageTotals{}
+	}
+	if instance := a.manager.GetInstance(a.prdName); instance != nil {
+		return instance.Usage
+	}
+	return loop.UsageTotals{}
+}
+
 // renderNarrowFooter renders a condensed footer for narrow terminals.
 func (a *App) renderNarrowFooter() string {
 	// Condensed keyboard shortcuts for narrow mode
@@ -370,7 +388,8 @@ func (a *App) renderStoriesPanel(width, height int) string {
 	var content strings.Builder
 
 	// Panel title — append scroll percentage when list is scrollable
-	listHeight := height - 5 // Account for title, border, and progress bar
+	waiting := a.prd.WaitingOnHumans()
+	listHeight := height - 5 - waitingSectionHeight(len(waiting)) // Account for title, border, progress bar, and human tasks
 	totalStories := len(a.prd.UserStories)
 	titleText := "Stories"
 	if totalStories > listHeight && listHeight > 0 {
@@ -406,7 +425,7 @@ func (a *App) renderStoriesPanel(width, height int) string {
 	visibleCount := 0
 	for i := a.storiesScrollOffset; i < endIdx; i++ {
 		story := a.prd.UserStories[i]
-		icon := GetStatusIcon(story.Passes, story.InProgress)
+		icon := storyIcon(a.prd, story)
 
 		// Truncate title to fit
 		maxTitleLen := width - 12 // Account for icon, ID, and spacing
@@ -434,10 +453,13 @@ func (a *App) renderStoriesPanel(width, height int) string {
 
 	// Pad remaining space
 	linesWritten := visibleCount + 2 // +2 for title and divider
-	for i := linesWritten; i < height-3; i++ {
+	for i := linesWritten; i < height-3-waitingSectionHeight(len(waiting)); i++ {
 		content.WriteString("\n")
 	}
 
+	// Human tasks the run is waiting on
+	content.WriteString(renderWaitingSection(waiting, width))
+
 	// Progress bar
 	content.WriteString(DividerStyle.Render(strings.Repeat("─", width-2)))
 	content.WriteString("\n")
@@ -447,6 +469,60 @@ func (a *App) renderStoriesPanel(width, height int) string {
 	return panelStyle.Width(width).Height(height).Render(content.String())
 }
 
+// maxWaitingLines is how many human tasks the stories panel lists.
+const maxWaitingLines = 3
+
+// waitingSectionHeight returns the lines the "Waiting for you" section takes
+// in the stories panel: a divider, a title, and the tasks.
+func waitingSectionHeight(waiting int) int {
+	if waiting == 0 {
+		return 0
+	}
+	return 2 + min(waiting, maxWaitingLines)
+}
+
+// renderWaitingSection lists the human tasks that aren't done yet.
+func renderWaitingSection(waiting []prd.UserStory, width int) string {
+	if len(waiting) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(DividerStyle.Render(strings.Repeat("─", width-2)))
+	b.WriteString("\n")
+	b.WriteString(labelStyle.Render(fmt.Sprintf("Waiting for you (%d)", len(waiting))))
+	b.WriteString("\n")
+	for i, story := range waiting {
+		if i == maxWaitingLines-1 && len(waiting) > maxWaitingLines {
+			b.WriteString(statusPendingStyle.Render(fmt.Sprintf("…and %d more", len(waiting)-i)))
+			b.WriteString("\n")
+			break
+		}
+		title := truncateWithEllipsis(story.Title, width-12) // Account for icon, ID, and spacing
+		b.WriteString(fmt.Sprintf("%s %s %s", storyIcon(nil, story), story.ID, title))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// storyIcon returns the status icon for a story, marking pending human tasks,
+// stories that need review or were skipped, and stories waiting on their
+// dependencies in p (nil when unknown).
+func storyIcon(p *prd.PRD, story prd.UserStory) string {
+	if story.IsHuman() && !story.Passes {
+		return statusPendingStyle.Render(IconHuman)
+	}
+	if story.NeedsReview && !story.Passes {
+		return statusFailedStyle.Render(IconFailed)
+	}
+	if story.Skipped && !story.Passes {
+		return statusPendingStyle.Render(IconSkipped)
+	}
+	if p != nil && !story.Passes && !story.InProgress && len(p.BlockedBy(&story)) > 0 {
+		return statusPausedStyle.Render(IconBlocked)
+	}
+	return GetStatusIcon(story.Passes, story.InProgress)
+}
+
 // renderDetailsPanel renders the details panel for the selected story.
 func (a *App) renderDetailsPanel(width, height int) string {
 	// Check for empty PRD state first
@@ -477,20 +553,48 @@ func (a *App) renderDetailsPanel(width, height int) string {
 	content.WriteString("\n\n")
 
 	// Status and Priority with proper styling
-	statusIcon := GetStatusIcon(story.Passes, story.InProgress)
+	statusIcon := storyIcon(a.prd, *story)
 	var statusText string
 	var statusStyle lipgloss.Style
 	if story.Passes {
 		statusText = "Passed"
 		statusStyle = statusPassedStyle
+	} else if story.IsHuman() {
+		statusText = "Waiting for you"
+		statusStyle = statusPendingStyle
+	} else if story.NeedsReview {
+		statusText = "Needs Review"
+		statusStyle = statusFailedStyle
+	} else if story.Skipped {
+		statusText = "Skipped"
+		statusStyle = statusPendingStyle
 	} else if story.InProgress {
 		statusText = "In Progress"
 		statusStyle = statusInProgressStyle
+	} else if len(a.prd.BlockedBy(story)) > 0 {
+		statusText = "Blocked"
+		statusStyle = statusPausedStyle
 	} else {
 		statusText = "Pending"
 		statusStyle = statusPendingStyle
 	}
 	content.WriteString(fmt.Sprintf("%s %s  │  Priority: %g\n", statusIcon, statusStyle.Render(statusText), story.Priority))
+	if story.IsHuman() && !story.Passes {
+		content.WriteString(wrapText(fmt.Sprintf("A task for you, not the agent. Mark it done with: chief tasks pass %s", story.ID), width-4))
+		content.WriteString("\n")
+	}
+	if story.NeedsReview && !story.Passes {
+		content.WriteString(wrapText("The agent used up its retries on this story and moved on. Fix it, or set its status back to todo in prd.md to retry.", width-4))
+		content.WriteString("\n")
+	}
+	if story.Skipped && !story.Passes {
+		content.WriteString(wrapText("Skipped. The agent won't work on it until you unskip it in the story order screen (o).", width-4))
+		content.WriteString("\n")
+	}
+	if blocking := a.prd.BlockedBy(story); len(blocking) > 0 && !story.Passes {
+		content.WriteString(wrapText("Blocked by: "+strings.Join(blocking, ", "), width-4))
+		content.WriteString("\n")
+	}
 	content.WriteString(DividerStyle.Render(strings.Repeat("─", width-4)))
 	content.WriteString("\n\n")
 
@@ -523,6 +627,17 @@ func (a *App) renderDetailsPanel(width, height int) string {
 		}
 	}
 
+	// Root cause from `chief why-failed`, if the story was analyzed
+	if !story.Passes {
+		if rootCause := failureRootCause(a.prdPath, story.ID); rootCause != "" {
+			content.WriteString("\n")
+			content.WriteString(labelStyle.Render("Why It Failed"))
+			content.WriteString("\n")
+			content.WriteString(renderGlamour(rootCause, width-4))
+			content.WriteString("\n")
+		}
+	}
+
 	// Truncate content to fit panel height (lipgloss Height only sets minimum, not maximum)
 	contentStr := content.String()
 	contentLines := strings.Split(contentStr, "\n")
@@ -534,6 +649,23 @@ func (a *App) renderDetailsPanel(width, height int) string {
 	return panelStyle.Width(width).Height(height).Render(contentStr)
 }
 
+// failureRootCause returns the Root Cause section of a story's failure
+// analysis, or "" when it has none.
+func failureRootCause(prdPath, storyID string) string {
+	data, err := os.ReadFile(review.AnalysisPath(prdPath, storyID))
+	if err != nil {
+		return ""
+	}
+	_, section, found := strings.Cut(string(data), "## Root Cause")
+	if !found {
+		return ""
+	}
+	if end := strings.Index(section, "\n## "); end >= 0 {
+		section = section[:end]
+	}
+	return strings.TrimSpace(section)
+}
+
 // renderErrorPanel renders the error details panel when in error state.
 func (a *App) renderErrorPanel(width, height int) string {
 	var content strings.Builder
@@ -688,25 +820,6 @@ func (a *App) renderProgressBar(width int) string {
 	return fmt.Sprintf("%s %3.0f%% %d/%d", bar, percentage, completedStories, totalStories)
 }
 
-// formatDuration formats a duration in a human-readable way.
-func formatDuration(d time.Duration) string {
-	if d == 0 {
-		return "0s"
-	}
-
-	h := int(d.Hours())
-	m := int(d.Minutes()) % 60
-	s := int(d.Seconds()) % 60
-
-	if h > 0 {
-		return fmt.Sprintf("%dh%02dm%02ds", h, m, s)
-	}
-	if m > 0 {
-		return fmt.Sprintf("%dm%02ds", m, s)
-	}
-	return fmt.Sprintf("%ds", s)
-}
-
 // wrapText wraps text to fit within a given width.
 func wrapText(text string, width int) string {
 	if width <= 0 {
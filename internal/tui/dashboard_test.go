@@ -2,6 +2,8 @@ package tui
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -194,3 +196,18 @@ func TestAndNMore_Removed(t *testing.T) {
 		t.Error("expected '... and N more' to be removed from stories panel")
 	}
 }
+
+func TestFailureRootCause(t *testing.T) {
+	dir := t.TempDir()
+	prdPath := filepath.Join(dir, "prd.md")
+	if got := failureRootCause(prdPath, "US-031"); got != "" {
+		t.Errorf("expected no root cause without an analysis, got %q", got)
+	}
+
+	os.MkdirAll(filepath.Join(dir, "failures"), 0755)
+	analysis := "# Why US-031 failed: Export\n\n## Attempts\n- Iteration 1\n\n## Root Cause\nThe fixture is missing.\n\n## Suggested Story\n### US-031: Export\n"
+	os.WriteFile(filepath.Join(dir, "failures", "US-031.md"), []byte(analysis), 0644)
+	if got := failureRootCause(prdPath, "US-031"); got != "The fixture is missing." {
+		t.Errorf("failureRootCause() = %q", got)
+	}
+}
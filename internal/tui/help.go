@@ -68,6 +68,7 @@ func (h *HelpOverlay) GetCategories() []ShortcutCategory {
 		Shortcuts: []Shortcut{
 			{Key: "1-9", Description: "Switch to PRD"},
 			{Key: "e", Description: "Edit current PRD"},
+			{Key: "o", Description: "Reorder/skip stories"},
 			{Key: "n", Description: "Create new PRD"},
 			{Key: "l", Description: "List/manage PRDs"},
 		},
@@ -22,6 +22,8 @@ type LogEntry struct {
 	ToolInput map[string]interface{}
 	StoryID   string
 	FilePath  string // For Read tool results, stores the file path for syntax highlighting
+	Failed    bool   // The event carried an error
+	Stderr    string // Last lines of the agent's stderr, for failures
 
 	highlightedCode string   // Pre-computed syntax highlighted code (computed once on add)
 	cachedLines     []string // Pre-rendered output lines (invalidated on width change)
@@ -55,6 +57,11 @@ func (l *LogViewer) AddEvent(event loop.Event) {
 		Tool:      event.Tool,
 		ToolInput: event.ToolInput,
 		StoryID:   event.StoryID,
+		Failed:    event.Err != nil,
+		Stderr:    event.Stderr,
+	}
+	if entry.Type == loop.EventError && entry.Text == "" && event.Err != nil {
+		entry.Text = event.Err.Error()
 	}
 
 	// Track Read tool file paths for syntax highlighting
@@ -77,7 +84,10 @@ func (l *LogViewer) AddEvent(event loop.Event) {
 	switch event.Type {
 	case loop.EventAssistantText, loop.EventToolStart, loop.EventToolResult,
 		loop.EventStoryDone, loop.EventComplete, loop.EventError, loop.EventRetrying,
-		loop.EventWatchdogTimeout:
+		loop.EventWatchdogTimeout, loop.EventStalled, loop.EventStoryTimeout,
+		loop.EventCommitRejected, loop.EventStorySquashed, loop.EventCoverage,
+		loop.EventVerify, loop.EventFlakyTest, loop.EventStackedPR, loop.EventStopped,
+		loop.EventCircuitOpen, loop.EventQuietHours, loop.EventWaitingOnHuman, loop.EventDirtyWorktree, loop.EventSummary, loop.EventExplore, loop.EventPush, loop.EventStoryBlocked, loop.EventStoryMerged, loop.EventRemoteSync:
 		// Pre-render and cache lines
 		if l.width > 0 {
 			entry.cachedLines = l.renderEntry(entry)
@@ -362,8 +372,44 @@ func (l *LogViewer) renderEntry(entry LogEntry) []string {
 		return l.renderError(entry)
 	case loop.EventRetrying:
 		return l.renderRetrying(entry)
-	case loop.EventWatchdogTimeout:
+	case loop.EventWatchdogTimeout, loop.EventStoryTimeout:
 		return l.renderWatchdogTimeout(entry)
+	case loop.EventStalled:
+		return l.renderStalled(entry)
+	case loop.EventCommitRejected:
+		return l.renderCommitRejected(entry)
+	case loop.EventStorySquashed:
+		return l.renderStorySquashed(entry)
+	case loop.EventCoverage:
+		return l.renderCoverage(entry)
+	case loop.EventVerify:
+		return l.renderVerify(entry)
+	case loop.EventFlakyTest:
+		return l.renderFlakyTest(entry)
+	case loop.EventStackedPR:
+		return l.renderStackedPR(entry)
+	case loop.EventStopped:
+		return l.renderStopped(entry)
+	case loop.EventCircuitOpen:
+		return l.renderCircuitOpen(entry)
+	case loop.EventQuietHours:
+		return l.renderQuietHours(entry)
+	case loop.EventWaitingOnHuman:
+		return l.renderWaitingOnHuman(entry)
+	case loop.EventDirtyWorktree:
+		return l.renderDirtyWorktree(entry)
+	case loop.EventSummary:
+		return l.renderSummary(entry)
+	case loop.EventExplore:
+		return l.renderExplore(entry)
+	case loop.EventPush:
+		return l.renderPush(entry)
+	case loop.EventStoryBlocked:
+		return l.renderStoryBlocked(entry)
+	case loop.EventStoryMerged:
+		return l.renderStoryMerged(entry)
+	case loop.EventRemoteSync:
+		return l.renderRemoteSync(entry)
 	default:
 		return l.renderText(entry)
 	}
@@ -583,12 +629,15 @@ func (l *LogViewer) renderComplete(entry LogEntry) []string {
 	dividerStyle := lipgloss.NewStyle().Foreground(SuccessColor)
 	divider := dividerStyle.Render(strings.Repeat("═", l.width-4))
 
-	return []string{
+	lines := []string{
 		"",
 		divider,
 		completeStyle.Render("✓ All stories complete!"),
-		divider,
 	}
+	if entry.Text != "" {
+		lines = append(lines, lipgloss.NewStyle().Foreground(MutedColor).Padding(0, 1).Render(entry.Text))
+	}
+	return append(lines, divider)
 }
 
 // renderError renders an error message.
@@ -602,7 +651,7 @@ func (l *LogViewer) renderError(entry LogEntry) []string {
 		text = "An error occurred"
 	}
 
-	return []string{errorStyle.Render("✗ Error: " + text)}
+	return append([]string{errorStyle.Render("✗ Error: " + text)}, l.renderStderr(entry)...)
 }
 
 // renderRetrying renders a retry message.
@@ -616,7 +665,34 @@ func (l *LogViewer) renderRetrying(entry LogEntry) []string {
 		text = "Retrying..."
 	}
 
-	return []string{retryStyle.Render("🔄 " + text)}
+	return append([]string{retryStyle.Render("🔄 " + text)}, l.renderStderr(entry)...)
+}
+
+// stderrExcerptLines is how many lines of stderr are shown under a failure.
+const stderrExcerptLines = 3
+
+// renderStderr renders the last lines of the agent's stderr under a failure.
+func (l *LogViewer) renderStderr(entry LogEntry) []string {
+	var lines []string
+	for _, line := range strings.Split(entry.Stderr, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > stderrExcerptLines {
+		lines = lines[len(lines)-stderrExcerptLines:]
+	}
+
+	style := lipgloss.NewStyle().Foreground(MutedColor)
+	maxLen := max(l.width-8, 20)
+	result := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if len(line) > maxLen {
+			line = line[:maxLen-3] + "..."
+		}
+		result = append(result, style.Render("  │ "+line))
+	}
+	return result
 }
 
 // renderWatchdogTimeout renders a watchdog timeout message.
@@ -632,3 +708,206 @@ func (l *LogViewer) renderWatchdogTimeout(entry LogEntry) []string {
 
 	return []string{style.Render("⏱ " + text)}
 }
+
+// renderCommitRejected renders a commit message rule failure.
+func (l *LogViewer) renderCommitRejected(entry LogEntry) []string {
+	style := lipgloss.NewStyle().
+		Foreground(WarningColor)
+
+	text := entry.Text
+	if text == "" {
+		text = "Commit messages need rewording"
+	}
+
+	return []string{style.Render("✎ " + text)}
+}
+
+// renderStorySquashed renders the result of squashing a finished story.
+func (l *LogViewer) renderStorySquashed(entry LogEntry) []string {
+	color := MutedColor
+	if entry.Failed {
+		color = WarningColor
+	}
+	style := lipgloss.NewStyle().
+		Foreground(color)
+
+	return []string{style.Render("⇣ " + entry.Text)}
+}
+
+// renderCoverage renders a story's coverage change, or a failed coverage check.
+func (l *LogViewer) renderCoverage(entry LogEntry) []string {
+	color := MutedColor
+	if entry.Failed {
+		color = WarningColor
+	}
+	style := lipgloss.NewStyle().
+		Foreground(color)
+
+	return []string{style.Render("◔ " + entry.Text)}
+}
+
+// renderVerify renders the outcome of a story's verification run.
+func (l *LogViewer) renderVerify(entry LogEntry) []string {
+	icon, color := "✓ ", SuccessColor
+	if entry.Failed {
+		icon, color = "✗ ", WarningColor
+	}
+	style := lipgloss.NewStyle().
+		Foreground(color)
+
+	return []string{style.Render(icon + entry.Text)}
+}
+
+// renderFlakyTest renders a flaky test being quarantined.
+func (l *LogViewer) renderFlakyTest(entry LogEntry) []string {
+	style := lipgloss.NewStyle().
+		Foreground(WarningColor)
+
+	return []string{style.Render("≈ " + entry.Text)}
+}
+
+// renderStackedPR renders a stacked PR being opened, or the run pausing for one.
+func (l *LogViewer) renderStackedPR(entry LogEntry) []string {
+	color := MutedColor
+	if entry.Failed {
+		color = WarningColor
+	}
+	style := lipgloss.NewStyle().
+		Foreground(color)
+
+	return []string{style.Render("⎇ " + entry.Text)}
+}
+
+// renderStopped renders the summary of a stopped run.
+func (l *LogViewer) renderStopped(entry LogEntry) []string {
+	style := lipgloss.NewStyle().
+		Foreground(WarningColor).
+		Bold(true)
+
+	return []string{style.Render("■ " + entry.Text)}
+}
+
+// renderCircuitOpen renders the run pausing after repeated agent crashes.
+func (l *LogViewer) renderCircuitOpen(entry LogEntry) []string {
+	style := lipgloss.NewStyle().
+		Foreground(ErrorColor).
+		Bold(true)
+
+	return append([]string{style.Render("⚡ " + entry.Text)}, l.renderStderr(entry)...)
+}
+
+// renderQuietHours renders a wait for quiet hours to end.
+func (l *LogViewer) renderQuietHours(entry LogEntry) []string {
+	style := lipgloss.NewStyle().
+		Foreground(MutedColor)
+
+	return []string{style.Render("☾ " + entry.Text)}
+}
+
+// renderWaitingOnHuman renders a pause for human tasks.
+func (l *LogViewer) renderWaitingOnHuman(entry LogEntry) []string {
+	style := lipgloss.NewStyle().
+		Foreground(WarningColor).
+		Bold(true)
+
+	return []string{style.Render(IconHuman + " " + entry.Text)}
+}
+
+// renderStoryBlocked renders a story given up on after its retries.
+func (l *LogViewer) renderStoryBlocked(entry LogEntry) []string {
+	color := WarningColor
+	if entry.Failed {
+		color = ErrorColor
+	}
+	style := lipgloss.NewStyle().
+		Foreground(color).
+		Bold(true)
+
+	return []string{style.Render(IconFailed + " " + entry.Text)}
+}
+
+// renderStoryMerged renders the merge of a story done in its own worktree.
+func (l *LogViewer) renderStoryMerged(entry LogEntry) []string {
+	color := SuccessColor
+	if entry.Failed {
+		color = WarningColor
+	}
+	style := lipgloss.NewStyle().
+		Foreground(color)
+
+	return []string{style.Render("⇢ " + entry.Text)}
+}
+
+// renderRemoteSync renders a run catching up with its remote branch, or
+// pausing because it is behind.
+func (l *LogViewer) renderRemoteSync(entry LogEntry) []string {
+	color := MutedColor
+	if entry.Failed {
+		color = WarningColor
+	}
+	style := lipgloss.NewStyle().
+		Foreground(color)
+
+	return []string{style.Render("⇣ " + entry.Text)}
+}
+
+// renderDirtyWorktree renders what a run did with uncommitted changes.
+func (l *LogViewer) renderDirtyWorktree(entry LogEntry) []string {
+	color := MutedColor
+	if entry.Failed {
+		color = ErrorColor
+	}
+	style := lipgloss.NewStyle().
+		Foreground(color)
+
+	return []string{style.Render("± " + entry.Text)}
+}
+
+// renderSummary renders an update of the summary of completed stories.
+func (l *LogViewer) renderSummary(entry LogEntry) []string {
+	color := MutedColor
+	if entry.Failed {
+		color = WarningColor
+	}
+	style := lipgloss.NewStyle().
+		Foreground(color)
+
+	return []string{style.Render("≡ " + entry.Text)}
+}
+
+// renderExplore renders the end of a story's exploration pass.
+func (l *LogViewer) renderExplore(entry LogEntry) []string {
+	color := MutedColor
+	if entry.Failed {
+		color = WarningColor
+	}
+	style := lipgloss.NewStyle().
+		Foreground(color)
+
+	return []string{style.Render("⌕ " + entry.Text)}
+}
+
+// renderPush renders a push of commits the push policy held back.
+func (l *LogViewer) renderPush(entry LogEntry) []string {
+	color := MutedColor
+	if entry.Failed {
+		color = WarningColor
+	}
+	style := lipgloss.NewStyle().
+		Foreground(color)
+
+	return []string{style.Render("↑ " + entry.Text)}
+}
+
+// renderStalled renders a stalled-run warning.
+func (l *LogViewer) renderStalled(entry LogEntry) []string {
+	style := lipgloss.NewStyle().
+		Foreground(WarningColor)
+
+	text := entry.Text
+	if text == "" {
+		text = "No output recently, agent may be stalled"
+	}
+
+	return []string{style.Render("⏳ " + text)}
+}
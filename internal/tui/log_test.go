@@ -1,6 +1,12 @@
 package tui
 
-import "testing"
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/loop"
+)
 
 func TestGetToolIcon(t *testing.T) {
 	tests := []struct {
@@ -172,6 +178,27 @@ func TestLogViewer_IsAutoScrolling(t *testing.T) {
 	}
 }
 
+func TestLogViewer_ErrorShowsStderrExcerpt(t *testing.T) {
+	lv := NewLogViewer()
+	lv.SetSize(100, 50)
+	lv.AddEvent(loop.Event{
+		Type:   loop.EventError,
+		Err:    errors.New("max retries (3) exceeded"),
+		Stderr: "starting\nconnecting\n\nretrying\nECONNRESET",
+	})
+
+	lines := lv.entries[0].cachedLines
+	if len(lines) != 4 {
+		t.Fatalf("expected the error and 3 stderr lines, got %d: %q", len(lines), lines)
+	}
+	if !strings.Contains(lines[0], "max retries (3) exceeded") {
+		t.Errorf("expected the error message, got %q", lines[0])
+	}
+	if strings.Contains(strings.Join(lines, "\n"), "starting") || !strings.Contains(lines[3], "ECONNRESET") {
+		t.Errorf("expected only the last stderr lines, got %q", lines)
+	}
+}
+
 func TestStripLineNumbers(t *testing.T) {
 	tests := []struct {
 		name     string
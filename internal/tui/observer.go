@@ -0,0 +1,304 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/minicodemonkey/chief/internal/control"
+	"github.com/minicodemonkey/chief/internal/loop"
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// observerMsg carries a message received from the control socket.
+type observerMsg struct {
+	msg control.Message
+}
+
+// observerCommandMsg carries the outcome of a command sent by the observer.
+type observerCommandMsg struct {
+	text string
+	err  error
+}
+
+// observerDisconnectedMsg is sent when the control socket connection ends.
+type observerDisconnectedMsg struct {
+	err error
+}
+
+// Observer is a view of a PRD loop running in another Chief instance. It
+// follows events over the control socket. In read-only mode it offers no
+// controls, so a teammate can watch a run without being able to change it;
+// otherwise the loop can be started, paused, and stopped from here.
+type Observer struct {
+	client    *control.Client
+	baseDir   string
+	prdName   string
+	readOnly  bool
+	prd       *prd.PRD
+	state     AppState
+	iteration int
+	storyID   string
+	activity  string
+	logViewer *LogViewer
+	width     int
+	height    int
+	err       error
+}
+
+// NewObserver creates a view of prdName. The client must already be
+// subscribed; the observer reads messages from it until the connection closes.
+func NewObserver(baseDir, prdName string, client *control.Client, readOnly bool) *Observer {
+	o := &Observer{
+		client:    client,
+		baseDir:   baseDir,
+		prdName:   prdName,
+		readOnly:  readOnly,
+		logViewer: NewLogViewer(),
+	}
+	o.reloadPRD()
+	return o
+}
+
+// Init starts reading from the control socket.
+func (o *Observer) Init() tea.Cmd {
+	return tea.Batch(tea.EnterAltScreen, o.receive())
+}
+
+// receive waits for the next control socket message.
+func (o *Observer) receive() tea.Cmd {
+	return func() tea.Msg {
+		msg, err := o.client.Receive()
+		if err != nil {
+			return observerDisconnectedMsg{err: err}
+		}
+		return observerMsg{msg: msg}
+	}
+}
+
+// command sends a start, pause, or stop command on a separate connection,
+// since the subscribed one only carries the event stream.
+func (o *Observer) command(cmd string) tea.Cmd {
+	return func() tea.Msg {
+		client, err := control.Dial(o.baseDir)
+		if err != nil {
+			return observerCommandMsg{err: err}
+		}
+		defer client.Close()
+		text, err := client.Command(cmd, o.prdName)
+		return observerCommandMsg{text: text, err: err}
+	}
+}
+
+// Update handles messages. Besides quitting and scrolling, s/p/x control the
+// loop unless the observer is read-only.
+func (o *Observer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		o.width = msg.Width
+		o.height = msg.Height
+		return o, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return o, tea.Quit
+		case "j", "down":
+			o.logViewer.ScrollDown()
+		case "k", "up":
+			o.logViewer.ScrollUp()
+		case "ctrl+d", "pgdown":
+			o.logViewer.PageDown()
+		case "ctrl+u", "pgup":
+			o.logViewer.PageUp()
+		case "g":
+			o.logViewer.ScrollToTop()
+		case "G":
+			o.logViewer.ScrollToBottom()
+		case "s":
+			if !o.readOnly {
+				return o, o.command(control.CmdStart)
+			}
+		case "p":
+			if !o.readOnly {
+				return o, o.command(control.CmdPause)
+			}
+		case "x":
+			if !o.readOnly {
+				return o, o.command(control.CmdStop)
+			}
+		}
+		return o, nil
+
+	case observerCommandMsg:
+		if msg.err != nil {
+			o.activity = "Error: " + msg.err.Error()
+		} else {
+			o.activity = msg.text
+		}
+		return o, nil
+
+	case observerMsg:
+		o.handleMessage(msg.msg)
+		return o, o.receive()
+
+	case observerDisconnectedMsg:
+		o.err = msg.err
+		o.activity = "Disconnected from chief (press q to quit)"
+		return o, nil
+	}
+	return o, nil
+}
+
+// handleMessage applies a control socket message to the view.
+func (o *Observer) handleMessage(msg control.Message) {
+	switch msg.Type {
+	case control.MsgState:
+		for _, status := range msg.PRDs {
+			if status.Name != o.prdName {
+				continue
+			}
+			o.state = parseAppState(status.State)
+			o.iteration = status.Iteration
+			if status.Error != "" {
+				o.activity = "Error: " + status.Error
+			}
+		}
+	case control.MsgHistory:
+		// Catch up on the run so far, as if the events had just arrived
+		for _, event := range msg.Events {
+			o.handleMessage(control.Message{Type: control.MsgEvent, PRD: msg.PRD, Event: event})
+		}
+	case control.MsgEvent:
+		if msg.PRD != o.prdName || msg.Event == nil {
+			return
+		}
+		event := msg.Event.LoopEvent()
+		o.logViewer.AddEvent(event)
+		if event.Iteration > 0 {
+			o.iteration = event.Iteration
+		}
+		switch event.Type {
+		case loop.EventIterationStart:
+			o.state = StateRunning
+			o.storyID = event.StoryID
+			o.activity = fmt.Sprintf("Working on %s", event.StoryID)
+			o.reloadPRD()
+		case loop.EventToolStart:
+			o.activity = fmt.Sprintf("%s %s", getToolIcon(event.Tool), event.Tool)
+		case loop.EventStoryDone, loop.EventComplete:
+			o.reloadPRD()
+			if event.Type == loop.EventComplete {
+				o.state = StateComplete
+				o.activity = "All stories complete"
+			}
+		case loop.EventError:
+			o.state = StateError
+			if event.Err != nil {
+				o.activity = "Error: " + event.Err.Error()
+			}
+		case loop.EventRetrying, loop.EventWatchdogTimeout, loop.EventStalled,
+			loop.EventStoryTimeout, loop.EventCommitRejected, loop.EventCoverage,
+			loop.EventVerify, loop.EventFlakyTest, loop.EventStackedPR, loop.EventStopped,
+			loop.EventCircuitOpen, loop.EventQuietHours, loop.EventWaitingOnHuman, loop.EventDirtyWorktree, loop.EventSummary, loop.EventExplore, loop.EventPush, loop.EventStoryBlocked, loop.EventStoryMerged, loop.EventRemoteSync:
+			o.activity = event.Text
+		}
+	case control.MsgError:
+		o.activity = "Error: " + msg.Error
+	}
+}
+
+// reloadPRD re-reads the observed PRD from disk so story statuses stay current.
+func (o *Observer) reloadPRD() {
+	path := filepath.Join(o.baseDir, ".chief", "prds", o.prdName, "prd.md")
+	if p, err := prd.LoadPRD(path); err == nil {
+		o.prd = p
+	}
+}
+
+// View renders the read-only dashboard: header, story list, and live log.
+func (o *Observer) View() string {
+	if o.width == 0 || o.height == 0 {
+		return "Loading..."
+	}
+
+	// Header
+	brand := headerStyle.Render("chief")
+	mode := lipgloss.NewStyle().Foreground(PrimaryColor).Bold(true).Render("[Attached]")
+	if o.readOnly {
+		mode = lipgloss.NewStyle().Foreground(WarningColor).Bold(true).Render("[Read-only]")
+	}
+	state := GetStateStyle(o.state).Render(fmt.Sprintf("[%s]", o.state.String()))
+	left := lipgloss.JoinHorizontal(lipgloss.Center, brand, "  ", mode, "  ", state, "  ", titleStyle.Render(o.prdName))
+	right := SubtitleStyle.Render(fmt.Sprintf("Iteration: %d", o.iteration))
+	spacing := strings.Repeat(" ", max(0, o.width-lipgloss.Width(left)-lipgloss.Width(right)-2))
+	header := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.JoinHorizontal(lipgloss.Center, left, spacing, right),
+		DividerStyle.Render(strings.Repeat("─", o.width)),
+	)
+
+	// Stories, capped at a third of the screen
+	storyLines := o.renderStories(max(1, o.height/3))
+
+	// Footer
+	activity := GetActivityStyle(o.state).Render(truncateWithEllipsis(o.activity, max(0, o.width-2)))
+	keyHelp := "q quit  j/k scroll  g/G top/bottom"
+	if !o.readOnly {
+		keyHelp = "s start  p pause  x stop  " + keyHelp
+	}
+	keys := footerStyle.Render(keyHelp)
+	footer := lipgloss.JoinVertical(lipgloss.Left, DividerStyle.Render(strings.Repeat("─", o.width)), activity, keys)
+
+	// Log fills the remaining space
+	logHeight := o.height - lipgloss.Height(header) - len(storyLines) - lipgloss.Height(footer) - 2
+	if logHeight < 1 {
+		logHeight = 1
+	}
+	o.logViewer.SetSize(o.width-4, logHeight)
+	logPanel := panelStyle.Width(o.width - 2).Height(logHeight).Render(o.logViewer.Render())
+
+	parts := []string{header}
+	parts = append(parts, storyLines...)
+	parts = append(parts, logPanel, footer)
+	return lipgloss.JoinVertical(lipgloss.Left, parts...)
+}
+
+// renderStories renders up to limit story lines, keeping the current story visible.
+func (o *Observer) renderStories(limit int) []string {
+	if o.prd == nil || len(o.prd.UserStories) == 0 {
+		return []string{SubtitleStyle.Render(" No stories")}
+	}
+
+	stories := o.prd.UserStories
+	start := 0
+	for i, story := range stories {
+		if story.ID == o.storyID && i >= limit {
+			start = i - limit + 1
+		}
+	}
+
+	var lines []string
+	for i := start; i < len(stories) && len(lines) < limit; i++ {
+		story := stories[i]
+		icon := storyIcon(o.prd, story)
+		text := truncateWithEllipsis(fmt.Sprintf("%s: %s", story.ID, story.Title), max(0, o.width-5))
+		line := " " + icon + " " + text
+		if story.ID == o.storyID {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseAppState converts a loop state name to the matching AppState.
+func parseAppState(name string) AppState {
+	for _, state := range []AppState{StateReady, StateRunning, StatePaused, StateStopped, StateComplete, StateError} {
+		if state.String() == name {
+			return state
+		}
+	}
+	return StateReady
+}
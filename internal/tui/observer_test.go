@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/control"
+	"github.com/minicodemonkey/chief/internal/loop"
+)
+
+func TestObserver_HandleMessage(t *testing.T) {
+	baseDir := t.TempDir()
+	prdDir := filepath.Join(baseDir, ".chief", "prds", "auth")
+	if err := os.MkdirAll(prdDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(prdDir, "prd.md"), []byte("# Auth\n\n### US-001: Login\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	o := NewObserver(baseDir, "auth", nil, true)
+	if o.prd == nil || len(o.prd.UserStories) != 1 {
+		t.Fatalf("expected PRD to be loaded, got %+v", o.prd)
+	}
+
+	o.handleMessage(control.Message{Type: control.MsgState, PRDs: []control.PRDStatus{
+		{Name: "other", State: "Error"},
+		{Name: "auth", State: "Paused", Iteration: 3},
+	}})
+	if o.state != StatePaused || o.iteration != 3 {
+		t.Errorf("expected Paused at iteration 3, got %v at %d", o.state, o.iteration)
+	}
+
+	start := control.NewEvent(loop.Event{Type: loop.EventIterationStart, Iteration: 4, StoryID: "US-001"})
+	o.handleMessage(control.Message{Type: control.MsgEvent, PRD: "auth", Event: start})
+	if o.state != StateRunning || o.storyID != "US-001" || o.iteration != 4 {
+		t.Errorf("expected running US-001 at iteration 4, got %v %q %d", o.state, o.storyID, o.iteration)
+	}
+
+	// Events for other PRDs are ignored
+	done := control.NewEvent(loop.Event{Type: loop.EventComplete})
+	o.handleMessage(control.Message{Type: control.MsgEvent, PRD: "other", Event: done})
+	if o.state != StateRunning {
+		t.Errorf("expected events for other PRDs to be ignored, got %v", o.state)
+	}
+
+	// History replays the run so far
+	o.handleMessage(control.Message{Type: control.MsgHistory, PRD: "auth", Events: []*control.Event{
+		control.NewEvent(loop.Event{Type: loop.EventIterationStart, Iteration: 5, StoryID: "US-001"}),
+		control.NewEvent(loop.Event{Type: loop.EventToolStart, Iteration: 5, Tool: "Bash"}),
+	}})
+	if o.iteration != 5 || o.activity == "" || o.activity == "Working on US-001" {
+		t.Errorf("expected history to be applied in order, got iteration %d, activity %q", o.iteration, o.activity)
+	}
+}
+
+func TestParseAppState(t *testing.T) {
+	for _, state := range []AppState{StateReady, StateRunning, StatePaused, StateStopped, StateComplete, StateError} {
+		if got := parseAppState(state.String()); got != state {
+			t.Errorf("parseAppState(%q) = %v", state.String(), got)
+		}
+	}
+	if got := parseAppState("bogus"); got != StateReady {
+		t.Errorf("expected unknown states to map to Ready, got %v", got)
+	}
+}
@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/minicodemonkey/chief/internal/control"
+	"github.com/minicodemonkey/chief/internal/loop"
+)
+
+// remoteCommandTimeout bounds how long a control socket client waits for the
+// TUI to act on a command.
+const remoteCommandTimeout = 10 * time.Second
+
+// RemoteCommandMsg asks the app to run a command received over the control
+// socket. The outcome is sent on Reply.
+type RemoteCommandMsg struct {
+	Cmd   string
+	PRD   string
+	Reply chan<- RemoteCommandResult
+}
+
+// RemoteCommandResult is the outcome of a RemoteCommandMsg.
+type RemoteCommandResult struct {
+	Text string
+	Err  error
+}
+
+// RemoteHandler returns a control socket handler that runs commands inside the
+// program's update loop, so `chief pause` and a key press in the TUI do exactly
+// the same thing.
+func RemoteHandler(p *tea.Program) control.Handler {
+	return func(req control.Request) (string, error) {
+		reply := make(chan RemoteCommandResult, 1)
+		p.Send(RemoteCommandMsg{Cmd: req.Cmd, PRD: req.PRD, Reply: reply})
+		select {
+		case result := <-reply:
+			return result.Text, result.Err
+		case <-time.After(remoteCommandTimeout):
+			return "", fmt.Errorf("chief did not respond")
+		}
+	}
+}
+
+// handleRemoteCommand runs a start, pause, or stop command from the control socket.
+func (a App) handleRemoteCommand(msg RemoteCommandMsg) (tea.Model, tea.Cmd) {
+	if err := a.checkRemoteCommand(msg.Cmd, msg.PRD); err != nil {
+		msg.Reply <- RemoteCommandResult{Err: err}
+		return a, nil
+	}
+
+	var model tea.Model
+	var cmd tea.Cmd
+	switch msg.Cmd {
+	case control.CmdStart:
+		model, cmd = a.startLoopForPRD(msg.PRD)
+	case control.CmdPause:
+		model, cmd = a.pauseLoopForPRD(msg.PRD)
+	case control.CmdStop:
+		model, cmd = a.stopLoopAndUpdateForPRD(msg.PRD)
+	}
+
+	result := RemoteCommandResult{}
+	if app, ok := model.(App); ok {
+		result.Text = app.lastActivity
+		if app.viewMode == ViewBranchWarning {
+			result.Text = "Waiting for branch confirmation in the chief TUI"
+		}
+	}
+	msg.Reply <- result
+	return model, cmd
+}
+
+// checkRemoteCommand reports why a command can't run against prdName.
+func (a App) checkRemoteCommand(cmd, prdName string) error {
+	if a.manager == nil {
+		return fmt.Errorf("no loop manager")
+	}
+	instance := a.manager.GetInstance(prdName)
+
+	switch cmd {
+	case control.CmdStart:
+		if _, err := os.Stat(filepath.Join(a.baseDir, ".chief", "prds", prdName, "prd.md")); err != nil {
+			return fmt.Errorf("PRD %s not found", prdName)
+		}
+		if instance != nil && instance.State == loop.LoopStateRunning {
+			return fmt.Errorf("PRD %s is already running", prdName)
+		}
+		if m := a.manager.Maintenance(); m != nil {
+			return fmt.Errorf("chief is in maintenance mode (%s)", m)
+		}
+		if a.viewMode == ViewBranchWarning {
+			return fmt.Errorf("chief is waiting for a branch confirmation")
+		}
+	case control.CmdPause:
+		if instance == nil || instance.State != loop.LoopStateRunning {
+			return fmt.Errorf("PRD %s is not running", prdName)
+		}
+	case control.CmdStop:
+		if instance == nil || (instance.State != loop.LoopStateRunning && instance.State != loop.LoopStatePaused) {
+			return fmt.Errorf("PRD %s is not running", prdName)
+		}
+	default:
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/control"
+	"github.com/minicodemonkey/chief/internal/loop"
+)
+
+func TestCheckRemoteCommand(t *testing.T) {
+	baseDir := t.TempDir()
+	prdDir := filepath.Join(baseDir, ".chief", "prds", "auth")
+	if err := os.MkdirAll(prdDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(prdDir, "prd.md"), []byte("# Auth\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := loop.NewManager(5, nil)
+	if err := manager.Register("auth", filepath.Join(prdDir, "prd.md")); err != nil {
+		t.Fatal(err)
+	}
+	app := App{baseDir: baseDir, manager: manager}
+
+	tests := []struct {
+		cmd     string
+		prd     string
+		wantErr string
+	}{
+		{control.CmdStart, "auth", ""},
+		{control.CmdStart, "missing", "not found"},
+		{control.CmdPause, "auth", "not running"},
+		{control.CmdStop, "auth", "not running"},
+		{"bogus", "auth", "unknown command"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cmd+"/"+tt.prd, func(t *testing.T) {
+			err := app.checkRemoteCommand(tt.cmd, tt.prd)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestHandleRemoteCommand_RepliesWithError(t *testing.T) {
+	app := App{baseDir: t.TempDir(), manager: loop.NewManager(5, nil)}
+	reply := make(chan RemoteCommandResult, 1)
+
+	app.handleRemoteCommand(RemoteCommandMsg{Cmd: control.CmdPause, PRD: "auth", Reply: reply})
+
+	result := <-reply
+	if result.Err == nil {
+		t.Error("expected pausing an unknown PRD to fail")
+	}
+}
@@ -0,0 +1,253 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// StoryOrder manages the story order screen: every story of the PRD in the
+// order the agent picks them, where stories can be moved, skipped, or sent
+// to the front. Nothing is written until the changes are saved.
+type StoryOrder struct {
+	width  int
+	height int
+
+	prd           *prd.PRD
+	stories       []prd.UserStory // In pick order, with the pending changes
+	selectedIdx   int
+	scrollOffset  int
+	reordered     bool
+	statusChanged map[string]bool // Story IDs whose status is to be written
+	originalOrder []string
+}
+
+// NewStoryOrder creates a new story order screen.
+func NewStoryOrder() *StoryOrder {
+	return &StoryOrder{}
+}
+
+// SetSize sets the screen dimensions.
+func (s *StoryOrder) SetSize(width, height int) {
+	s.width = width
+	s.height = height
+}
+
+// Load shows the stories of p, lowest priority first, and forgets any
+// unsaved changes.
+func (s *StoryOrder) Load(p *prd.PRD) {
+	s.prd = p
+	s.stories = append([]prd.UserStory(nil), p.UserStories...)
+	sort.SliceStable(s.stories, func(i, j int) bool {
+		return s.stories[i].Priority < s.stories[j].Priority
+	})
+	s.originalOrder = s.Order()
+	s.selectedIdx = 0
+	s.scrollOffset = 0
+	s.reordered = false
+	s.statusChanged = make(map[string]bool)
+}
+
+// MoveUp moves the selection up.
+func (s *StoryOrder) MoveUp() {
+	if s.selectedIdx > 0 {
+		s.selectedIdx--
+	}
+}
+
+// MoveDown moves the selection down.
+func (s *StoryOrder) MoveDown() {
+	if s.selectedIdx < len(s.stories)-1 {
+		s.selectedIdx++
+	}
+}
+
+// ShiftUp moves the selected story one place earlier in the order.
+func (s *StoryOrder) ShiftUp() {
+	if s.selectedIdx > 0 {
+		s.swap(s.selectedIdx, s.selectedIdx-1)
+		s.selectedIdx--
+	}
+}
+
+// ShiftDown moves the selected story one place later in the order.
+func (s *StoryOrder) ShiftDown() {
+	if s.selectedIdx < len(s.stories)-1 {
+		s.swap(s.selectedIdx, s.selectedIdx+1)
+		s.selectedIdx++
+	}
+}
+
+// RunNext moves the selected story to the front of the order, taking it off
+// the skipped or needs-review list, so the agent picks it next once its
+// dependencies are done.
+func (s *StoryOrder) RunNext() {
+	if s.selectedIdx >= len(s.stories) {
+		return
+	}
+	story := s.stories[s.selectedIdx]
+	if !story.Passes && (story.Skipped || story.NeedsReview) {
+		story.Skipped, story.NeedsReview = false, false
+		s.statusChanged[story.ID] = true
+	}
+	copy(s.stories[1:s.selectedIdx+1], s.stories[:s.selectedIdx])
+	s.stories[0] = story
+	s.selectedIdx = 0
+	s.reordered = !slices.Equal(s.Order(), s.originalOrder)
+}
+
+// ToggleSkip skips the selected story, or unskips it. Finished stories and
+// human tasks can't be skipped.
+func (s *StoryOrder) ToggleSkip() {
+	if s.selectedIdx >= len(s.stories) {
+		return
+	}
+	story := &s.stories[s.selectedIdx]
+	if story.Passes || story.IsHuman() {
+		return
+	}
+	story.Skipped = !story.Skipped
+	story.NeedsReview = false
+	s.statusChanged[story.ID] = true
+}
+
+// swap exchanges two stories in the order.
+func (s *StoryOrder) swap(i, j int) {
+	s.stories[i], s.stories[j] = s.stories[j], s.stories[i]
+	s.reordered = !slices.Equal(s.Order(), s.originalOrder)
+}
+
+// HasChanges reports whether there are unsaved changes.
+func (s *StoryOrder) HasChanges() bool {
+	return s.reordered || len(s.statusChanged) > 0
+}
+
+// Order returns the story IDs in the order shown.
+func (s *StoryOrder) Order() []string {
+	ids := make([]string, len(s.stories))
+	for i, story := range s.stories {
+		ids[i] = story.ID
+	}
+	return ids
+}
+
+// Save writes the changes to the prd.md file at path: the order as
+// **Priority:** lines, and each skipped or unskipped story's status.
+func (s *StoryOrder) Save(path string) error {
+	for _, story := range s.stories {
+		if !s.statusChanged[story.ID] {
+			continue
+		}
+		status := "todo"
+		if story.Skipped {
+			status = "skipped"
+		}
+		if err := prd.SetStoryStatus(path, story.ID, status); err != nil {
+			return err
+		}
+	}
+	if s.reordered {
+		if err := prd.SetStoryPriorities(path, s.Order()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Render renders the story order screen.
+func (s *StoryOrder) Render() string {
+	modalWidth := min(80, s.width-10)
+	if modalWidth < 50 {
+		modalWidth = 50
+	}
+	listHeight := max(s.height-16, 3)
+	if s.selectedIdx < s.scrollOffset {
+		s.scrollOffset = s.selectedIdx
+	}
+	if s.selectedIdx >= s.scrollOffset+listHeight {
+		s.scrollOffset = s.selectedIdx - listHeight + 1
+	}
+
+	var content strings.Builder
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(PrimaryColor)
+	mutedStyle := lipgloss.NewStyle().Foreground(MutedColor)
+	title := titleStyle.Render("Story Order")
+	hint := mutedStyle.Render("the agent picks from the top")
+	padding := max(modalWidth-4-lipgloss.Width(title)-lipgloss.Width(hint), 1)
+	content.WriteString(title)
+	content.WriteString(strings.Repeat(" ", padding))
+	content.WriteString(hint)
+	content.WriteString("\n")
+	content.WriteString(DividerStyle.Render(strings.Repeat("─", modalWidth-4)))
+	content.WriteString("\n\n")
+
+	selectedStyle := lipgloss.NewStyle().Foreground(PrimaryColor).Bold(true)
+	textStyle := lipgloss.NewStyle().Foreground(TextColor)
+	numWidth := len(fmt.Sprint(len(s.stories)))
+	end := min(s.scrollOffset+listHeight, len(s.stories))
+	for i := s.scrollOffset; i < end; i++ {
+		story := s.stories[i]
+		note := ""
+		switch {
+		case story.Passes:
+			note = "done"
+		case story.Skipped:
+			note = "skipped"
+		case story.IsHuman():
+			note = "human task"
+		case story.NeedsReview:
+			note = "needs review"
+		case story.InProgress:
+			note = "in progress"
+		case len(s.prd.BlockedBy(&story)) > 0:
+			note = "blocked by " + strings.Join(s.prd.BlockedBy(&story), ", ")
+		}
+		label := fmt.Sprintf("%*d. %s", numWidth, i+1, story.ID)
+		titleWidth := max(modalWidth-8-lipgloss.Width(label)-len(note)-4, 10)
+		line := label + "  " + truncateWithEllipsis(story.Title, titleWidth)
+
+		cursor := "  "
+		style := textStyle
+		if i == s.selectedIdx {
+			cursor = "▶ "
+			style = selectedStyle
+		} else if story.Passes || story.Skipped {
+			style = mutedStyle
+		}
+		content.WriteString(cursor)
+		content.WriteString(storyIcon(s.prd, story))
+		content.WriteString(" ")
+		content.WriteString(style.Render(line))
+		if note != "" {
+			content.WriteString("  ")
+			content.WriteString(mutedStyle.Render(note))
+		}
+		content.WriteString("\n")
+	}
+	if len(s.stories) == 0 {
+		content.WriteString(mutedStyle.Render("No stories in this PRD"))
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	content.WriteString(DividerStyle.Render(strings.Repeat("─", modalWidth-4)))
+	content.WriteString("\n")
+	footer := "↑/↓: Select  K/J: Move  n: Run next  space: Skip  Enter: Save  Esc: Cancel"
+	if s.HasChanges() {
+		footer = "Unsaved changes · " + footer
+	}
+	content.WriteString(mutedStyle.Render(footer))
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(PrimaryColor).
+		Padding(1, 2).
+		Width(modalWidth)
+
+	return centerModal(modalStyle.Render(content.String()), s.width, s.height)
+}
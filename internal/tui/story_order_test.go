@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+const storyOrderPRD = `# PRD: Shop
+
+### US-001: Cart
+**Status:** done
+- [x] A
+
+### US-002: Checkout
+- [ ] B
+
+### US-003: Receipts
+**Status:** needs-review
+- [ ] C
+
+### US-004: Refunds
+- [ ] D
+`
+
+func loadStoryOrder(t *testing.T) (*StoryOrder, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "prd.md")
+	if err := os.WriteFile(path, []byte(storyOrderPRD), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := prd.LoadPRD(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewStoryOrder()
+	s.SetSize(100, 40)
+	s.Load(p)
+	return s, path
+}
+
+func TestStoryOrder_MoveAndRunNext(t *testing.T) {
+	s, _ := loadStoryOrder(t)
+	s.MoveDown()
+	s.ShiftDown() // US-002 below US-003
+	if got := s.Order(); !slices.Equal(got, []string{"US-001", "US-003", "US-002", "US-004"}) {
+		t.Errorf("Order() after ShiftDown = %v", got)
+	}
+	s.ShiftUp()
+	if s.HasChanges() {
+		t.Error("HasChanges() = true after moving a story back")
+	}
+
+	s.MoveDown()
+	s.MoveDown() // US-004
+	s.RunNext()
+	if got := s.Order(); !slices.Equal(got, []string{"US-004", "US-001", "US-002", "US-003"}) {
+		t.Errorf("Order() after RunNext = %v", got)
+	}
+	if !s.HasChanges() {
+		t.Error("HasChanges() = false after RunNext")
+	}
+}
+
+func TestStoryOrder_Save(t *testing.T) {
+	s, path := loadStoryOrder(t)
+	s.ToggleSkip() // US-001 is done and can't be skipped
+	s.MoveDown()
+	s.ToggleSkip() // Skip US-002
+	s.MoveDown()
+	s.RunNext() // US-003 needs review; running it next makes it todo again
+	if err := s.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	p, err := prd.LoadPRD(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := map[string]prd.UserStory{}
+	for _, story := range p.UserStories {
+		byID[story.ID] = story
+	}
+	if byID["US-001"].Skipped || !byID["US-001"].Passes {
+		t.Errorf("US-001 = %+v, want it left done", byID["US-001"])
+	}
+	if !byID["US-002"].Skipped {
+		t.Error("US-002 not saved as skipped")
+	}
+	if byID["US-003"].NeedsReview || byID["US-003"].Priority != 1 {
+		t.Errorf("US-003 = %+v, want todo with priority 1", byID["US-003"])
+	}
+	if next := p.NextStory(); next == nil || next.ID != "US-003" {
+		t.Errorf("NextStory() = %v, want US-003", next)
+	}
+	if got := p.SkippedStories(); len(got) != 1 || got[0].ID != "US-002" {
+		t.Errorf("SkippedStories() = %v, want US-002", got)
+	}
+
+	if out := s.Render(); !strings.Contains(out, "Story Order") {
+		t.Error("Render() missing the title")
+	}
+}
@@ -204,6 +204,9 @@ const (
 	IconPending    = "○"
 	IconFailed     = "✗"
 	IconPaused     = "◐"
+	IconHuman      = "◇"
+	IconBlocked    = "⊘"
+	IconSkipped    = "⊖"
 )
 
 // Backward compatibility aliases
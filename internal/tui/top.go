@@ -0,0 +1,223 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// topRefreshInterval is how often the process list is refreshed.
+const topRefreshInterval = 2 * time.Second
+
+// TopRow is a process in the chief top view.
+type TopRow struct {
+	PID     int
+	Depth   int    // Levels below the chief process running it (0 = chief itself)
+	PRD     string // PRD the process works for ("" = unknown)
+	CPU     float64
+	RSS     int64 // Resident memory in bytes
+	Elapsed string
+	Command string
+}
+
+// topRowsMsg carries a refreshed process list.
+type topRowsMsg struct {
+	rows []TopRow
+	err  error
+}
+
+// topTickMsg triggers a refresh.
+type topTickMsg struct{}
+
+// topKilledMsg carries the outcome of killing a process.
+type topKilledMsg struct {
+	pid int
+	err error
+}
+
+// Top is a live view of the processes chief runs: the chief processes, their
+// agents, and the commands those start. A runaway child can be killed from
+// it; chief itself can't.
+type Top struct {
+	load     func() ([]TopRow, error)
+	kill     func(pid int) error
+	rows     []TopRow
+	selected int
+	confirm  int // PID waiting for the kill to be confirmed (0 = none)
+	status   string
+	err      error
+	width    int
+	height   int
+}
+
+// NewTop creates the view. load lists the processes on every refresh; kill
+// ends one of them.
+func NewTop(load func() ([]TopRow, error), kill func(pid int) error) *Top {
+	return &Top{load: load, kill: kill}
+}
+
+// Init loads the process list.
+func (t *Top) Init() tea.Cmd {
+	return tea.Batch(tea.EnterAltScreen, t.refresh())
+}
+
+// refresh lists the processes.
+func (t *Top) refresh() tea.Cmd {
+	return func() tea.Msg {
+		rows, err := t.load()
+		return topRowsMsg{rows: rows, err: err}
+	}
+}
+
+// killSelected kills the process waiting for confirmation.
+func (t *Top) killSelected(pid int) tea.Cmd {
+	return func() tea.Msg {
+		return topKilledMsg{pid: pid, err: t.kill(pid)}
+	}
+}
+
+// Update handles messages: j/k select a process, x kills it after y confirms.
+func (t *Top) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		t.width = msg.Width
+		t.height = msg.Height
+		return t, nil
+
+	case tea.KeyMsg:
+		if t.confirm != 0 {
+			pid := t.confirm
+			t.confirm = 0
+			if msg.String() == "y" {
+				t.status = fmt.Sprintf("Stopping %d...", pid)
+				return t, t.killSelected(pid)
+			}
+			t.status = ""
+			return t, nil
+		}
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return t, tea.Quit
+		case "j", "down":
+			if t.selected < len(t.rows)-1 {
+				t.selected++
+			}
+		case "k", "up":
+			if t.selected > 0 {
+				t.selected--
+			}
+		case "r":
+			return t, t.refresh()
+		case "x":
+			if t.selected < len(t.rows) {
+				row := t.rows[t.selected]
+				if row.Depth == 0 {
+					t.status = "chief itself can't be killed from here; use chief pause or chief stop"
+				} else {
+					t.confirm = row.PID
+					t.status = fmt.Sprintf("Kill %d (%s)? y to confirm", row.PID, truncateWithEllipsis(row.Command, 40))
+				}
+			}
+		}
+		return t, nil
+
+	case topRowsMsg:
+		t.err = msg.err
+		if msg.err == nil {
+			t.keepSelection(msg.rows)
+		}
+		return t, tea.Tick(topRefreshInterval, func(time.Time) tea.Msg { return topTickMsg{} })
+
+	case topTickMsg:
+		return t, t.refresh()
+
+	case topKilledMsg:
+		if msg.err != nil {
+			t.status = "Error: " + msg.err.Error()
+		} else {
+			t.status = fmt.Sprintf("Stopped %d", msg.pid)
+		}
+		return t, nil
+	}
+	return t, nil
+}
+
+// keepSelection replaces the rows, keeping the selected process selected
+// when it is still running.
+func (t *Top) keepSelection(rows []TopRow) {
+	pid := 0
+	if t.selected < len(t.rows) {
+		pid = t.rows[t.selected].PID
+	}
+	t.rows = rows
+	t.selected = min(t.selected, max(0, len(rows)-1))
+	for i, row := range rows {
+		if row.PID == pid {
+			t.selected = i
+			break
+		}
+	}
+}
+
+// View renders the process table.
+func (t *Top) View() string {
+	if t.width == 0 || t.height == 0 {
+		return "Loading..."
+	}
+
+	header := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.JoinHorizontal(lipgloss.Center, headerStyle.Render("chief"), "  ", titleStyle.Render("top")),
+		DividerStyle.Render(strings.Repeat("─", t.width)),
+	)
+
+	var body strings.Builder
+	body.WriteString(labelStyle.Render(formatTopLine("PID", "PRD", "CPU%", "MEM", "TIME", "COMMAND", t.width)))
+	body.WriteString("\n")
+	switch {
+	case t.err != nil:
+		body.WriteString(statusFailedStyle.Render("Error: " + t.err.Error()))
+	case len(t.rows) == 0:
+		body.WriteString(SubtitleStyle.Render("No chief runs on this machine"))
+	}
+	listHeight := max(1, t.height-lipgloss.Height(header)-5)
+	start := max(0, t.selected-listHeight+1)
+	for i := start; i < len(t.rows) && i < start+listHeight; i++ {
+		row := t.rows[i]
+		command := strings.Repeat("  ", row.Depth) + row.Command
+		line := formatTopLine(fmt.Sprint(row.PID), row.PRD, fmt.Sprintf("%.1f", row.CPU), formatMemory(row.RSS), row.Elapsed, command, t.width)
+		if i == t.selected {
+			line = selectedStyle.Render(line)
+		}
+		body.WriteString(line)
+		body.WriteString("\n")
+	}
+
+	status := SubtitleStyle.Render(truncateWithEllipsis(t.status, max(0, t.width-2)))
+	keys := footerStyle.Render("q quit  j/k select  x kill  r refresh")
+	footer := lipgloss.JoinVertical(lipgloss.Left, DividerStyle.Render(strings.Repeat("─", t.width)), status, keys)
+
+	bodyHeight := max(1, t.height-lipgloss.Height(header)-lipgloss.Height(footer))
+	return lipgloss.JoinVertical(lipgloss.Left, header, lipgloss.NewStyle().Height(bodyHeight).Render(body.String()), footer)
+}
+
+// formatTopLine lays out one line of the process table, cutting the command
+// to the width left.
+func formatTopLine(pid, prdName, cpu, mem, elapsed, command string, width int) string {
+	line := fmt.Sprintf("%7s  %-14s %5s %7s %11s  ", pid, truncateWithEllipsis(prdName, 14), cpu, mem, elapsed)
+	return line + truncateWithEllipsis(command, max(0, width-lipgloss.Width(line)-1))
+}
+
+// formatMemory shows a byte count in the largest unit that keeps it above 1.
+func formatMemory(bytes int64) string {
+	switch {
+	case bytes >= 1<<30:
+		return fmt.Sprintf("%.1fG", float64(bytes)/(1<<30))
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%dM", bytes>>20)
+	default:
+		return fmt.Sprintf("%dK", bytes>>10)
+	}
+}
@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTop_KillNeedsConfirmation(t *testing.T) {
+	var killed []int
+	top := NewTop(nil, func(pid int) error {
+		killed = append(killed, pid)
+		return nil
+	})
+	top.Update(topRowsMsg{rows: []TopRow{{PID: 10, Command: "chief"}, {PID: 20, Depth: 1, Command: "claude"}}})
+
+	key := func(s string) tea.Cmd {
+		_, cmd := top.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)})
+		return cmd
+	}
+	key("x")
+	if top.confirm != 0 {
+		t.Fatal("expected chief itself not to be killable")
+	}
+	key("j")
+	key("x")
+	if cmd := key("n"); cmd != nil || len(killed) != 0 {
+		t.Fatal("expected n to cancel the kill")
+	}
+	key("x")
+	if cmd := key("y"); cmd != nil {
+		cmd()
+	}
+	if len(killed) != 1 || killed[0] != 20 {
+		t.Errorf("expected 20 to be killed, got %v", killed)
+	}
+
+	// The selection follows the process across refreshes
+	top.Update(topRowsMsg{rows: []TopRow{{PID: 10}, {PID: 15, Depth: 1}, {PID: 20, Depth: 1}}})
+	if top.rows[top.selected].PID != 20 {
+		t.Errorf("expected 20 to stay selected, got %d", top.rows[top.selected].PID)
+	}
+}
+
+func TestFormatMemory(t *testing.T) {
+	tests := map[int64]string{512 << 10: "512K", 300 << 20: "300M", 3 << 29: "1.5G"}
+	for bytes, want := range tests {
+		if got := formatMemory(bytes); got != want {
+			t.Errorf("formatMemory(%d) = %q, want %q", bytes, got, want)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+// Package undo keeps a journal of snapshots taken before destructive
+// commands, such as deleting a PRD or overwriting one on import, so `chief
+// undo` can put the files back the way they were. Each entry lives in its own
+// directory under .chief/undo/ with a copy of every path it saved.
+package undo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxEntries is how many entries the journal keeps; older ones are dropped.
+const MaxEntries = 20
+
+// entryFile describes an entry inside its directory. An entry directory
+// without one was never committed and is ignored.
+const entryFile = "entry.json"
+
+// filesDir holds the saved copies inside an entry's directory.
+const filesDir = "files"
+
+// Entry is one destructive action that can be undone.
+type Entry struct {
+	ID          string    `json:"-"`           // Name of the entry's directory
+	Action      string    `json:"action"`      // Command that was run, e.g. "do --rm"
+	Description string    `json:"description"` // What the command changed
+	Time        time.Time `json:"time"`
+	Paths       []Path    `json:"paths"`
+}
+
+// Path is a file or directory saved by an entry.
+type Path struct {
+	Path    string `json:"path"`    // Relative to the project root, slash-separated
+	Existed bool   `json:"existed"` // false = undo removes it
+}
+
+// Dir returns the journal directory of a project.
+func Dir(baseDir string) string {
+	return filepath.Join(baseDir, ".chief", "undo")
+}
+
+// Journal records the paths a destructive action is about to change.
+type Journal struct {
+	baseDir string
+	dir     string
+	entry   Entry
+}
+
+// Begin starts an entry for an action in baseDir's journal. Save the paths
+// the action changes before changing them, then Commit.
+func Begin(baseDir, action, description string) (*Journal, error) {
+	now := time.Now()
+	id := strconv.FormatInt(now.UnixNano(), 10)
+	dir := filepath.Join(Dir(baseDir), id)
+	if err := os.MkdirAll(filepath.Join(dir, filesDir), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create undo entry: %w", err)
+	}
+	return &Journal{
+		baseDir: baseDir,
+		dir:     dir,
+		entry:   Entry{ID: id, Action: action, Description: description, Time: now},
+	}, nil
+}
+
+// Save copies the file or directory at rel, relative to the project root,
+// into the entry. A path that doesn't exist is recorded so undo removes it.
+// Saving a path twice keeps the first copy.
+func (j *Journal) Save(rel string) error {
+	rel = filepath.ToSlash(filepath.Clean(rel))
+	if rel == "." || strings.HasPrefix(rel, "../") || filepath.IsAbs(rel) {
+		return fmt.Errorf("cannot save %q: not inside the project", rel)
+	}
+	for _, p := range j.entry.Paths {
+		if p.Path == rel {
+			return nil
+		}
+	}
+	src := filepath.Join(j.baseDir, filepath.FromSlash(rel))
+	_, err := os.Lstat(src)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	existed := err == nil
+	if existed {
+		if err := copyTree(src, filepath.Join(j.dir, filesDir, filepath.FromSlash(rel))); err != nil {
+			return fmt.Errorf("failed to save %s for undo: %w", rel, err)
+		}
+	}
+	j.entry.Paths = append(j.entry.Paths, Path{Path: rel, Existed: existed})
+	return nil
+}
+
+// Commit writes the entry to the journal and drops the oldest entries past
+// MaxEntries. An entry with nothing saved is discarded.
+func (j *Journal) Commit() error {
+	if len(j.entry.Paths) == 0 {
+		j.Discard()
+		return nil
+	}
+	data, err := json.MarshalIndent(j.entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(j.dir, entryFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write undo entry: %w", err)
+	}
+	return prune(j.baseDir)
+}
+
+// Discard drops the entry without committing it.
+func (j *Journal) Discard() {
+	os.RemoveAll(j.dir)
+}
+
+// Snapshot saves paths, relative to baseDir, as one committed entry.
+func Snapshot(baseDir, action, description string, paths ...string) error {
+	j, err := Begin(baseDir, action, description)
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		if err := j.Save(p); err != nil {
+			j.Discard()
+			return err
+		}
+	}
+	return j.Commit()
+}
+
+// List returns the journal's entries, newest first.
+func List(baseDir string) ([]Entry, error) {
+	dirs, err := os.ReadDir(Dir(baseDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(Dir(baseDir), d.Name(), entryFile))
+		if err != nil {
+			continue
+		}
+		var e Entry
+		if json.Unmarshal(data, &e) != nil {
+			continue
+		}
+		e.ID = d.Name()
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, k int) bool { return entries[i].Time.After(entries[k].Time) })
+	return entries, nil
+}
+
+// Undo puts back the paths of the newest entry and removes the entry from
+// the journal. It returns the entry undone, or nil when the journal is empty.
+func Undo(baseDir string) (*Entry, error) {
+	entries, err := List(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	e := entries[0]
+	dir := filepath.Join(Dir(baseDir), e.ID)
+	for _, p := range e.Paths {
+		target := filepath.Join(baseDir, filepath.FromSlash(p.Path))
+		if err := os.RemoveAll(target); err != nil {
+			return nil, fmt.Errorf("failed to remove %s: %w", p.Path, err)
+		}
+		if !p.Existed {
+			continue
+		}
+		if err := copyTree(filepath.Join(dir, filesDir, filepath.FromSlash(p.Path)), target); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", p.Path, err)
+		}
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return &e, fmt.Errorf("restored, but failed to remove the undo entry: %w", err)
+	}
+	return &e, nil
+}
+
+// Remove drops an entry from the journal without undoing it.
+func Remove(baseDir, id string) error {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return fmt.Errorf("invalid undo entry %q", id)
+	}
+	return os.RemoveAll(filepath.Join(Dir(baseDir), id))
+}
+
+// prune drops entries past MaxEntries and entry directories that were never
+// committed.
+func prune(baseDir string) error {
+	entries, err := List(baseDir)
+	if err != nil {
+		return err
+	}
+	keep := make(map[string]bool)
+	for i, e := range entries {
+		if i < MaxEntries {
+			keep[e.ID] = true
+		}
+	}
+	dirs, err := os.ReadDir(Dir(baseDir))
+	if err != nil {
+		return err
+	}
+	for _, d := range dirs {
+		if keep[d.Name()] || inProgress(baseDir, d) {
+			continue
+		}
+		os.RemoveAll(filepath.Join(Dir(baseDir), d.Name()))
+	}
+	return nil
+}
+
+// inProgress reports whether d is an entry another command may still be
+// writing: not committed yet, and started within the last hour.
+func inProgress(baseDir string, d fs.DirEntry) bool {
+	if _, err := os.Stat(filepath.Join(Dir(baseDir), d.Name(), entryFile)); err == nil {
+		return false
+	}
+	info, err := d.Info()
+	return err == nil && time.Since(info.ModTime()) < time.Hour
+}
+
+// copyTree copies a file or directory from src to dst, keeping permissions.
+// Symlinks are copied as links.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		}
+		return nil
+	})
+}
+
+// copyFile copies a regular file, creating the parent directory.
+func copyFile(src, dst string, mode fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
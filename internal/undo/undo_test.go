@@ -0,0 +1,131 @@
+package undo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSnapshotAndUndo(t *testing.T) {
+	dir := t.TempDir()
+	prdDir := filepath.Join(dir, ".chief", "prds", "auth")
+	writeFile(t, filepath.Join(prdDir, "prd.md"), "# Auth\n")
+	writeFile(t, filepath.Join(prdDir, "progress.md"), "notes\n")
+
+	if err := Snapshot(dir, "do --rm", "Removed PRD auth", ".chief/prds/auth", ".chief/conventions.md"); err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	os.RemoveAll(prdDir)
+	writeFile(t, filepath.Join(dir, ".chief", "conventions.md"), "- new\n")
+
+	entries, err := List(dir)
+	if err != nil || len(entries) != 1 || entries[0].Action != "do --rm" {
+		t.Fatalf("List() = %+v, %v", entries, err)
+	}
+
+	e, err := Undo(dir)
+	if err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if e == nil || e.Description != "Removed PRD auth" {
+		t.Errorf("Undo() = %+v, want the snapshot entry", e)
+	}
+	if got := readFile(t, filepath.Join(prdDir, "prd.md")); got != "# Auth\n" {
+		t.Errorf("expected prd.md restored, got %q", got)
+	}
+	if got := readFile(t, filepath.Join(prdDir, "progress.md")); got != "notes\n" {
+		t.Errorf("expected progress.md restored, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".chief", "conventions.md")); !os.IsNotExist(err) {
+		t.Error("expected a file that didn't exist before to be removed")
+	}
+
+	if e, err := Undo(dir); e != nil || err != nil {
+		t.Errorf("expected nothing left to undo, got %+v, %v", e, err)
+	}
+}
+
+func TestUndo_NewestFirst(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".chief", "conventions.md")
+	writeFile(t, path, "one\n")
+	if err := Snapshot(dir, "first", "", ".chief/conventions.md"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, path, "two\n")
+	if err := Snapshot(dir, "second", "", ".chief/conventions.md"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, path, "three\n")
+
+	if e, _ := Undo(dir); e == nil || e.Action != "second" || readFile(t, path) != "two\n" {
+		t.Errorf("expected the second action undone first, got %+v and %q", e, readFile(t, path))
+	}
+	if e, _ := Undo(dir); e == nil || e.Action != "first" || readFile(t, path) != "one\n" {
+		t.Errorf("expected the first action undone next, got %+v and %q", e, readFile(t, path))
+	}
+}
+
+func TestJournal(t *testing.T) {
+	dir := t.TempDir()
+	if err := Snapshot(dir, "nothing", ""); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := List(dir); len(entries) != 0 {
+		t.Errorf("expected an empty entry to be discarded, got %+v", entries)
+	}
+
+	j, err := Begin(dir, "restore --force", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Save("../outside"); err == nil {
+		t.Error("expected a path outside the project to be refused")
+	}
+	if err := j.Save(".chief/a.md"); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Save(".chief/a.md"); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := List(dir); len(entries) != 0 {
+		t.Error("expected an uncommitted entry to be ignored")
+	}
+	if err := j.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := List(dir); len(entries) != 1 || len(entries[0].Paths) != 1 {
+		t.Errorf("expected one entry with one path, got %+v", entries)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < MaxEntries+3; i++ {
+		if err := Snapshot(dir, "action", "", ".chief/missing"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if entries, _ := List(dir); len(entries) != MaxEntries {
+		t.Errorf("expected %d entries kept, got %d", MaxEntries, len(entries))
+	}
+}
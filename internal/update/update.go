@@ -44,7 +44,18 @@ type CheckResult struct {
 
 // Options configures the update checker.
 type Options struct {
-	ReleasesURL string // Override GitHub API URL (for testing)
+	ReleasesURL string       // Override GitHub API URL (for testing)
+	Client      *http.Client // Client for all requests, e.g. with a proxy or custom CA (nil = default)
+}
+
+// httpClient returns the client to use with the given timeout.
+func (o Options) httpClient(timeout time.Duration) *http.Client {
+	if o.Client == nil {
+		return &http.Client{Timeout: timeout}
+	}
+	client := *o.Client
+	client.Timeout = timeout
+	return &client
 }
 
 func (o Options) releasesURL() string {
@@ -58,7 +69,7 @@ func (o Options) releasesURL() string {
 func CheckForUpdate(currentVersion string, opts Options) (*CheckResult, error) {
 	current := normalizeVersion(currentVersion)
 
-	client := &http.Client{Timeout: checkTimeout}
+	client := opts.httpClient(checkTimeout)
 	resp, err := client.Get(opts.releasesURL())
 	if err != nil {
 		return nil, fmt.Errorf("fetching latest release: %w", err)
@@ -93,7 +104,7 @@ func CheckForUpdate(currentVersion string, opts Options) (*CheckResult, error) {
 
 // PerformUpdate downloads and installs the latest version.
 func PerformUpdate(currentVersion string, opts Options) (*CheckResult, error) {
-	client := &http.Client{Timeout: checkTimeout}
+	client := opts.httpClient(checkTimeout)
 	resp, err := client.Get(opts.releasesURL())
 	if err != nil {
 		return nil, fmt.Errorf("fetching latest release: %w", err)
@@ -146,7 +157,7 @@ func PerformUpdate(currentVersion string, opts Options) (*CheckResult, error) {
 	}
 
 	// Download binary to temp file
-	tmpFile, err := downloadToTemp(binaryAsset.BrowserDownloadURL, dir)
+	tmpFile, err := downloadToTemp(opts.httpClient(downloadTimeout), binaryAsset.BrowserDownloadURL, dir)
 	if err != nil {
 		return nil, fmt.Errorf("downloading update: %w", err)
 	}
@@ -154,7 +165,7 @@ func PerformUpdate(currentVersion string, opts Options) (*CheckResult, error) {
 
 	// Verify checksum if available
 	if checksumAsset != nil {
-		if err := verifyChecksum(tmpFile, checksumAsset.BrowserDownloadURL); err != nil {
+		if err := verifyChecksum(opts.httpClient(checkTimeout), tmpFile, checksumAsset.BrowserDownloadURL); err != nil {
 			return nil, fmt.Errorf("checksum verification failed: %w", err)
 		}
 	}
@@ -238,8 +249,7 @@ func checkWritePermission(dir string) error {
 }
 
 // downloadToTemp downloads a URL to a temporary file in the specified directory.
-func downloadToTemp(url, dir string) (string, error) {
-	client := &http.Client{Timeout: downloadTimeout}
+func downloadToTemp(client *http.Client, url, dir string) (string, error) {
 	resp, err := client.Get(url)
 	if err != nil {
 		return "", fmt.Errorf("downloading %s: %w", url, err)
@@ -266,9 +276,8 @@ func downloadToTemp(url, dir string) (string, error) {
 }
 
 // verifyChecksum downloads the expected SHA256 checksum and verifies the file.
-func verifyChecksum(filePath, checksumURL string) error {
+func verifyChecksum(client *http.Client, filePath, checksumURL string) error {
 	// Download checksum file
-	client := &http.Client{Timeout: checkTimeout}
 	resp, err := client.Get(checksumURL)
 	if err != nil {
 		return fmt.Errorf("downloading checksum: %w", err)
@@ -282,7 +282,7 @@ func TestDownloadToTemp(t *testing.T) {
 	defer srv.Close()
 
 	dir := t.TempDir()
-	tmpFile, err := downloadToTemp(srv.URL, dir)
+	tmpFile, err := downloadToTemp(http.DefaultClient, srv.URL, dir)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -309,7 +309,7 @@ func TestDownloadToTemp_ServerError(t *testing.T) {
 	defer srv.Close()
 
 	dir := t.TempDir()
-	_, err := downloadToTemp(srv.URL, dir)
+	_, err := downloadToTemp(http.DefaultClient, srv.URL, dir)
 	if err == nil {
 		t.Error("expected error for server error")
 	}
@@ -332,7 +332,7 @@ func TestVerifyChecksum(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	if err := verifyChecksum(filePath, srv.URL); err != nil {
+	if err := verifyChecksum(http.DefaultClient, filePath, srv.URL); err != nil {
 		t.Errorf("expected checksum verification to pass: %v", err)
 	}
 }
@@ -347,7 +347,7 @@ func TestVerifyChecksum_Mismatch(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	if err := verifyChecksum(filePath, srv.URL); err == nil {
+	if err := verifyChecksum(http.DefaultClient, filePath, srv.URL); err == nil {
 		t.Error("expected checksum verification to fail")
 	}
 }
@@ -408,13 +408,13 @@ func TestPerformUpdate_FullFlow(t *testing.T) {
 	// (CheckForUpdate, findAssets, downloadToTemp, verifyChecksum are all tested above)
 
 	// Test the download + checksum flow manually
-	tmpFile, err := downloadToTemp(downloadSrv.URL+"/binary", dir)
+	tmpFile, err := downloadToTemp(http.DefaultClient, downloadSrv.URL+"/binary", dir)
 	if err != nil {
 		t.Fatalf("download failed: %v", err)
 	}
 	defer os.Remove(tmpFile)
 
-	if err := verifyChecksum(tmpFile, downloadSrv.URL+"/checksum"); err != nil {
+	if err := verifyChecksum(http.DefaultClient, tmpFile, downloadSrv.URL+"/checksum"); err != nil {
 		t.Fatalf("checksum failed: %v", err)
 	}
 
@@ -0,0 +1,174 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// playwrightConfigs are the file names Playwright loads its config from.
+var playwrightConfigs = []string{"playwright.config.ts", "playwright.config.js", "playwright.config.mjs", "playwright.config.cjs"}
+
+// testDirRegex picks testDir out of a Playwright config.
+var testDirRegex = regexp.MustCompile(`testDir:\s*['"]([^'"]+)['"]`)
+
+// DefaultBrowserTestDir is where recorded tests go when the Playwright config
+// doesn't set testDir.
+const DefaultBrowserTestDir = "e2e"
+
+// ResolveBrowser returns the browser test command for the project in dir:
+// the configured command, "npx playwright test" when the project has a
+// Playwright config, or "" when browser tests are off.
+func ResolveBrowser(configured, dir string) string {
+	switch configured {
+	case Disabled:
+		return ""
+	case "":
+		if playwrightConfig(dir) == "" {
+			return ""
+		}
+		return "npx playwright test"
+	}
+	return configured
+}
+
+// playwrightConfig returns the path of the project's Playwright config, or "".
+func playwrightConfig(dir string) string {
+	for _, name := range playwrightConfigs {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// BrowserTestDir returns the directory, relative to dir, that recorded tests
+// are written to: the Playwright config's testDir, or DefaultBrowserTestDir.
+func BrowserTestDir(dir string) string {
+	if path := playwrightConfig(dir); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			if m := testDirRegex.FindSubmatch(data); m != nil {
+				return filepath.Clean(string(m[1]))
+			}
+		}
+	}
+	return DefaultBrowserTestDir
+}
+
+// BrowserTestPath returns where the recorded test for a story is written,
+// relative to dir.
+func BrowserTestPath(dir, storyID string) string {
+	return filepath.Join(BrowserTestDir(dir), strings.ToLower(storyID)+".spec.ts")
+}
+
+// BrowserTests returns the test files in dir, relative to it, whose name
+// mentions the story ID, such as e2e/us-012.spec.ts.
+func BrowserTests(dir, storyID string) []string {
+	id := strings.ToLower(storyID)
+	var tests []string
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := strings.ToLower(d.Name())
+		if d.IsDir() {
+			if path != dir && (name == "node_modules" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.Contains(name, id) && (strings.Contains(name, ".spec.") || strings.Contains(name, ".test.")) {
+			if rel, err := filepath.Rel(dir, path); err == nil {
+				tests = append(tests, filepath.ToSlash(rel))
+			}
+		}
+		return nil
+	})
+	return tests
+}
+
+// RunBrowser runs the browser test command on the given test files with `sh
+// -c` in dir. The story's ID and URL are passed to the tests as
+// CHIEF_STORY_ID and CHIEF_STORY_URL.
+func RunBrowser(ctx context.Context, dir, command string, tests []string, storyID, url string) Result {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	args := make([]string, len(tests))
+	for i, t := range tests {
+		args[i] = shellQuote(t)
+	}
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command+" "+strings.Join(args, " "))
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "CHIEF_STORY_ID="+storyID, "CHIEF_STORY_URL="+url)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+
+	output := out.String()
+	if err == nil {
+		return Result{Passed: true, Output: output}
+	}
+	return Result{Output: output, Failed: FailedTests(output)}
+}
+
+// CollectScreenshots copies the screenshots written to Playwright's
+// test-results directory since the given time into destDir, and returns the
+// copied paths.
+func CollectScreenshots(dir string, since time.Time, destDir string) []string {
+	var copied []string
+	results := filepath.Join(dir, "test-results")
+	filepath.WalkDir(results, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".png") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.ModTime().Before(since) {
+			return nil
+		}
+		// Playwright names each result directory after the test, so keep it
+		rel, _ := filepath.Rel(results, path)
+		dest := filepath.Join(destDir, strings.ReplaceAll(filepath.ToSlash(rel), "/", "--"))
+		if copyFile(path, dest) == nil {
+			copied = append(copied, dest)
+		}
+		return nil
+	})
+	return copied
+}
+
+// copyFile copies src to dest, creating dest's directory.
+func copyFile(src, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// shellQuote quotes s for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
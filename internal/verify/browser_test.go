@@ -0,0 +1,77 @@
+package verify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveBrowser(t *testing.T) {
+	dir := t.TempDir()
+	if got := ResolveBrowser("", dir); got != "" {
+		t.Errorf("ResolveBrowser() without a Playwright config = %q, want off", got)
+	}
+	writeFile(t, filepath.Join(dir, "playwright.config.ts"), "export default defineConfig({ testDir: './tests/e2e' })\n")
+	if got := ResolveBrowser("", dir); got != "npx playwright test" {
+		t.Errorf("ResolveBrowser() = %q, want npx playwright test", got)
+	}
+	if got := ResolveBrowser(Disabled, dir); got != "" {
+		t.Errorf("ResolveBrowser(none) = %q, want off", got)
+	}
+	if got := BrowserTestPath(dir, "US-012"); got != filepath.Join("tests", "e2e", "us-012.spec.ts") {
+		t.Errorf("BrowserTestPath() = %q, want the config's testDir", got)
+	}
+}
+
+func TestBrowserTests(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "e2e", "us-012.spec.ts"), "")
+	writeFile(t, filepath.Join(dir, "e2e", "US-012-checkout.test.js"), "")
+	writeFile(t, filepath.Join(dir, "e2e", "us-013.spec.ts"), "")
+	writeFile(t, filepath.Join(dir, "node_modules", "x", "us-012.spec.ts"), "")
+
+	want := []string{"e2e/US-012-checkout.test.js", "e2e/us-012.spec.ts"}
+	if got := BrowserTests(dir, "US-012"); !reflect.DeepEqual(got, want) {
+		t.Errorf("BrowserTests() = %v, want %v", got, want)
+	}
+}
+
+func TestRunBrowser(t *testing.T) {
+	dir := t.TempDir()
+	result := RunBrowser(context.Background(), dir, `echo "$CHIEF_STORY_ID $CHIEF_STORY_URL"; echo`, []string{"e2e/it's.spec.ts"}, "US-001", "http://localhost:3000")
+	if !result.Passed || !strings.Contains(result.Output, "US-001 http://localhost:3000\ne2e/it's.spec.ts") {
+		t.Errorf("RunBrowser() = %+v", result)
+	}
+}
+
+func TestCollectScreenshots(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "test-results", "old", "test-failed-1.png")
+	writeFile(t, old, "old")
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(old, past, past)
+	start := time.Now().Add(-time.Minute)
+	writeFile(t, filepath.Join(dir, "test-results", "us-012-checkout-chromium", "test-failed-1.png"), "png")
+	writeFile(t, filepath.Join(dir, "test-results", "us-012-checkout-chromium", "trace.zip"), "zip")
+
+	dest := filepath.Join(dir, "artifacts")
+	got := CollectScreenshots(dir, start, dest)
+	want := []string{filepath.Join(dest, "us-012-checkout-chromium--test-failed-1.png")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CollectScreenshots() = %v, want %v", got, want)
+	}
+}
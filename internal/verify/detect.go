@@ -0,0 +1,95 @@
+package verify
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Disabled is the verify.command value that turns verification off, including
+// the detected default.
+const Disabled = "none"
+
+// npmDefaultTestScript is the placeholder "test" script written by `npm init`.
+const npmDefaultTestScript = `echo "Error: no test specified" && exit 1`
+
+// Detect returns the idiomatic test command for the project in dir, and the
+// file it was inferred from. Both are empty when the project type isn't
+// recognized.
+func Detect(dir string) (command, marker string) {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, name))
+		return err == nil
+	}
+
+	switch {
+	case exists("go.mod"):
+		return "go test ./...", "go.mod"
+	case exists("Cargo.toml"):
+		return "cargo test", "Cargo.toml"
+	case exists("package.json"):
+		if !hasTestScript(filepath.Join(dir, "package.json")) {
+			return "", ""
+		}
+		switch {
+		case exists("pnpm-lock.yaml"):
+			return "pnpm test", "package.json"
+		case exists("yarn.lock"):
+			return "yarn test", "package.json"
+		case exists("bun.lockb"), exists("bun.lock"):
+			return "bun run test", "package.json"
+		default:
+			return "npm test", "package.json"
+		}
+	case exists("pyproject.toml"):
+		switch {
+		case exists("uv.lock"):
+			return "uv run pytest", "pyproject.toml"
+		case exists("poetry.lock"):
+			return "poetry run pytest", "pyproject.toml"
+		default:
+			return "python -m pytest", "pyproject.toml"
+		}
+	}
+	return "", ""
+}
+
+// hasTestScript reports whether package.json defines a real "test" script.
+func hasTestScript(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return false
+	}
+	script := strings.TrimSpace(pkg.Scripts["test"])
+	return script != "" && script != npmDefaultTestScript
+}
+
+// Resolve picks the verification command for a PRD: the PRD's own override,
+// then the project config, then the command detected from the project files
+// in dir. It returns the command ("" = don't verify) and where it came from.
+func Resolve(prdCommand, projectCommand, dir string) (command, source string) {
+	switch {
+	case prdCommand != "":
+		command, source = prdCommand, "PRD config"
+	case projectCommand != "":
+		command, source = projectCommand, ".chief/config.yaml"
+	default:
+		var marker string
+		command, marker = Detect(dir)
+		if command == "" {
+			return "", "no project type detected"
+		}
+		return command, "detected from " + marker
+	}
+	if command == Disabled {
+		return "", source
+	}
+	return command, source
+}
@@ -0,0 +1,69 @@
+package verify
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      map[string]string
+		want       string
+		wantMarker string
+	}{
+		{"go", map[string]string{"go.mod": "module x"}, "go test ./...", "go.mod"},
+		{"rust", map[string]string{"Cargo.toml": ""}, "cargo test", "Cargo.toml"},
+		{"npm", map[string]string{"package.json": `{"scripts":{"test":"jest"}}`}, "npm test", "package.json"},
+		{"pnpm", map[string]string{"package.json": `{"scripts":{"test":"vitest"}}`, "pnpm-lock.yaml": ""}, "pnpm test", "package.json"},
+		{"yarn", map[string]string{"package.json": `{"scripts":{"test":"jest"}}`, "yarn.lock": ""}, "yarn test", "package.json"},
+		{"npm placeholder script", map[string]string{"package.json": `{"scripts":{"test":"echo \"Error: no test specified\" && exit 1"}}`}, "", ""},
+		{"npm without test script", map[string]string{"package.json": `{"name":"x"}`}, "", ""},
+		{"python", map[string]string{"pyproject.toml": ""}, "python -m pytest", "pyproject.toml"},
+		{"uv", map[string]string{"pyproject.toml": "", "uv.lock": ""}, "uv run pytest", "pyproject.toml"},
+		{"go wins over package.json", map[string]string{"go.mod": "", "package.json": `{"scripts":{"test":"jest"}}`}, "go test ./...", "go.mod"},
+		{"unknown", map[string]string{"README.md": ""}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, marker := Detect(dir)
+			if got != tt.want || marker != tt.wantMarker {
+				t.Errorf("Detect() = %q, %q; want %q, %q", got, marker, tt.want, tt.wantMarker)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		prd, project   string
+		want, wantFrom string
+	}{
+		{"detected", "", "", "go test ./...", "detected from go.mod"},
+		{"project config", "", "make test", "make test", ".chief/config.yaml"},
+		{"PRD override", "go test ./api/...", "make test", "go test ./api/...", "PRD config"},
+		{"disabled in project", "", Disabled, "", ".chief/config.yaml"},
+		{"disabled for PRD", Disabled, "make test", "", "PRD config"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, from := Resolve(tt.prd, tt.project, dir)
+			if got != tt.want || from != tt.wantFrom {
+				t.Errorf("Resolve() = %q, %q; want %q, %q", got, from, tt.want, tt.wantFrom)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// FlakyPRDName is the PRD that collects draft stories for fixing flaky tests.
+const FlakyPRDName = "flaky-tests"
+
+// registryFile holds the flaky test registry, relative to the project root.
+const registryFile = ".chief/flaky.json"
+
+// FlakyTest is a test that failed and then passed with no code changes in between.
+type FlakyTest struct {
+	Name      string    `json:"name"`
+	PRD       string    `json:"prd"`   // PRD being verified when the test first flipped
+	Story     string    `json:"story"` // Story being verified when the test first flipped
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+	Flips     int       `json:"flips"`
+}
+
+// Registry is the project's list of known flaky tests.
+type Registry struct {
+	path  string
+	Tests []FlakyTest `json:"tests"`
+}
+
+// RegistryPath returns the path of the flaky test registry for a project.
+func RegistryPath(baseDir string) string {
+	return filepath.Join(baseDir, registryFile)
+}
+
+// LoadRegistry reads the registry at path. A missing file yields an empty registry.
+func LoadRegistry(path string) (*Registry, error) {
+	r := &Registry{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return r, nil
+		}
+		return nil, fmt.Errorf("failed to read flaky test registry: %w", err)
+	}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Save writes the registry back to disk.
+func (r *Registry) Save() error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("failed to create registry directory: %w", err)
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0644)
+}
+
+// IsFlaky reports whether the named test is in the registry.
+func (r *Registry) IsFlaky(name string) bool {
+	return r.find(name) != nil
+}
+
+// Record notes that the named test flipped from fail to pass while verifying
+// storyID. It returns the registry entry and whether the test is newly flaky.
+func (r *Registry) Record(name, prdName, storyID string, now time.Time) (FlakyTest, bool) {
+	if t := r.find(name); t != nil {
+		t.LastSeen = now
+		t.Flips++
+		return *t, false
+	}
+	t := FlakyTest{Name: name, PRD: prdName, Story: storyID, FirstSeen: now, LastSeen: now, Flips: 1}
+	r.Tests = append(r.Tests, t)
+	return t, true
+}
+
+func (r *Registry) find(name string) *FlakyTest {
+	for i := range r.Tests {
+		if r.Tests[i].Name == name {
+			return &r.Tests[i]
+		}
+	}
+	return nil
+}
+
+// flakyPRDHeader starts a new flaky test PRD.
+const flakyPRDHeader = `# PRD: Flaky Tests
+
+## Introduction
+
+Tests that failed and then passed with no code changes in between while Chief was verifying stories. They are excluded from story verification until fixed. Each story makes one test deterministic.
+
+## User Stories
+`
+
+// storyIDNumberRegex extracts the number from a story ID like "FLAKY-007".
+var storyIDNumberRegex = regexp.MustCompile(`-(\d+)$`)
+
+// FixStoryTitle returns the title of the story asking for test to be fixed.
+func FixStoryTitle(test string) string {
+	return "Fix flaky test " + test
+}
+
+// AddFixStory appends a draft story for fixing t to the PRD at prdPath,
+// creating the PRD if needed. It returns false if an unfinished story for the
+// same test already exists.
+func AddFixStory(prdPath string, t FlakyTest) (bool, error) {
+	content := flakyPRDHeader
+	prefix, nextID, priority := "FLAKY", 1, 0
+	if data, err := os.ReadFile(prdPath); err == nil {
+		content = string(data)
+		existing, err := prd.ParseMarkdownPRDFromString(content)
+		if err != nil {
+			return false, fmt.Errorf("failed to parse %s: %w", prdPath, err)
+		}
+		if len(existing.UserStories) > 0 {
+			prefix = existing.ExtractIDPrefix()
+		}
+		for _, story := range existing.UserStories {
+			if story.Title == FixStoryTitle(t.Name) && !story.Passes {
+				return false, nil
+			}
+			if m := storyIDNumberRegex.FindStringSubmatch(story.ID); m != nil {
+				if n, _ := strconv.Atoi(m[1]); n >= nextID {
+					nextID = n + 1
+				}
+			}
+			if int(story.Priority) > priority {
+				priority = int(story.Priority)
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read PRD: %w", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n### %s-%03d: %s\n", prefix, nextID, FixStoryTitle(t.Name))
+	fmt.Fprintf(&b, "**Priority:** %d\n", priority+1)
+	fmt.Fprintf(&b, "**Description:** As a developer, I want %s to pass or fail deterministically so verification results can be trusted. It failed and then passed with no code changes while verifying %s/%s on %s.\n\n",
+		t.Name, t.PRD, t.Story, t.FirstSeen.Format("2006-01-02"))
+	b.WriteString("**Acceptance Criteria:**\n")
+	b.WriteString("- [ ] The source of nondeterminism (timing, ordering, shared state, network) is identified\n")
+	fmt.Fprintf(&b, "- [ ] %s passes reliably when run repeatedly\n", t.Name)
+	fmt.Fprintf(&b, "- [ ] %s is removed from %s\n", t.Name, registryFile)
+
+	if err := os.MkdirAll(filepath.Dir(prdPath), 0755); err != nil {
+		return false, fmt.Errorf("failed to create PRD directory: %w", err)
+	}
+	content = strings.TrimRight(content, "\n") + "\n" + b.String()
+	if err := os.WriteFile(prdPath, []byte(content), 0644); err != nil {
+		return false, fmt.Errorf("failed to write PRD: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,73 @@
+package verify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+func TestRegistry(t *testing.T) {
+	path := RegistryPath(t.TempDir())
+
+	r, err := LoadRegistry(path)
+	if err != nil {
+		t.Fatalf("LoadRegistry() on a missing file: %v", err)
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if _, isNew := r.Record("TestX", "auth", "US-002", now); !isNew {
+		t.Error("expected first record to be new")
+	}
+	if test, isNew := r.Record("TestX", "auth", "US-003", now); isNew || test.Flips != 2 || test.Story != "US-002" {
+		t.Errorf("expected second record to bump flips on the original entry, got %+v (new=%v)", test, isNew)
+	}
+	if err := r.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	loaded, err := LoadRegistry(path)
+	if err != nil {
+		t.Fatalf("LoadRegistry() failed: %v", err)
+	}
+	if !loaded.IsFlaky("TestX") || loaded.IsFlaky("TestY") {
+		t.Errorf("unexpected registry contents: %+v", loaded.Tests)
+	}
+}
+
+func TestAddFixStory(t *testing.T) {
+	prdPath := filepath.Join(t.TempDir(), ".chief", "prds", FlakyPRDName, "prd.md")
+	test := FlakyTest{Name: "TestX", PRD: "auth", Story: "US-002", FirstSeen: time.Now()}
+
+	added, err := AddFixStory(prdPath, test)
+	if err != nil || !added {
+		t.Fatalf("AddFixStory() = %v, %v; want true, nil", added, err)
+	}
+	added, err = AddFixStory(prdPath, test)
+	if err != nil || added {
+		t.Errorf("expected a pending story for the same test to be kept, got %v, %v", added, err)
+	}
+	if _, err := AddFixStory(prdPath, FlakyTest{Name: "TestY", FirstSeen: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := prd.LoadPRD(prdPath)
+	if err != nil {
+		t.Fatalf("generated PRD does not parse: %v", err)
+	}
+	if len(p.UserStories) != 2 {
+		t.Fatalf("expected 2 stories, got %d", len(p.UserStories))
+	}
+	if p.UserStories[0].ID != "FLAKY-001" || p.UserStories[1].ID != "FLAKY-002" {
+		t.Errorf("unexpected story IDs: %s, %s", p.UserStories[0].ID, p.UserStories[1].ID)
+	}
+	if p.UserStories[0].Title != "Fix flaky test TestX" {
+		t.Errorf("unexpected title %q", p.UserStories[0].Title)
+	}
+	data, _ := os.ReadFile(prdPath)
+	if !strings.Contains(string(data), "auth/US-002") {
+		t.Error("expected the story to say where the flake was seen")
+	}
+}
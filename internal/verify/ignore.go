@@ -0,0 +1,46 @@
+package verify
+
+import (
+	"path"
+	"strings"
+)
+
+// MatchPath reports whether the repository-relative path matches pattern. A
+// pattern ending in "/" matches everything inside a directory of that name,
+// e.g. "docs/". A pattern without "/" is matched against the file name, so
+// "*.md" matches README.md and docs/guide/setup.md. Any other pattern is
+// matched against the whole path, e.g. "site/*.html".
+func MatchPath(pattern, filePath string) bool {
+	if dir, ok := strings.CutSuffix(pattern, "/"); ok {
+		dir = strings.TrimPrefix(dir, "/")
+		return strings.HasPrefix(filePath, dir+"/") || (!strings.HasPrefix(pattern, "/") && strings.Contains(filePath, "/"+dir+"/"))
+	}
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(filePath))
+		return ok
+	}
+	ok, _ := path.Match(strings.TrimPrefix(pattern, "/"), filePath)
+	return ok
+}
+
+// OnlyIgnored reports whether every file matches one of patterns, so changing
+// them can't affect the verification result. No files means nothing is known
+// about the changes, which never counts as ignored.
+func OnlyIgnored(patterns, files []string) bool {
+	if len(patterns) == 0 || len(files) == 0 {
+		return false
+	}
+	for _, f := range files {
+		matched := false
+		for _, p := range patterns {
+			if MatchPath(p, f) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
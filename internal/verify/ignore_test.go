@@ -0,0 +1,43 @@
+package verify
+
+import "testing"
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"docs/", "docs/guide.md", true},
+		{"docs/", "docs/api/index.html", true},
+		{"docs/", "web/docs/intro.md", true},
+		{"/docs/", "web/docs/intro.md", false},
+		{"docs/", "docs.go", false},
+		{"*.md", "README.md", true},
+		{"*.md", "docs/guide/setup.md", true},
+		{"*.md", "main.go", false},
+		{"site/*.html", "site/index.html", true},
+		{"site/*.html", "site/blog/post.html", false},
+		{"CHANGELOG.md", "CHANGELOG.md", true},
+	}
+	for _, tt := range tests {
+		if got := MatchPath(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("MatchPath(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestOnlyIgnored(t *testing.T) {
+	patterns := []string{"docs/", "*.md"}
+	if !OnlyIgnored(patterns, []string{"README.md", "docs/api.html"}) {
+		t.Error("expected doc-only changes to be ignored")
+	}
+	if OnlyIgnored(patterns, []string{"README.md", "main.go"}) {
+		t.Error("expected a code change to need verification")
+	}
+	if OnlyIgnored(patterns, nil) {
+		t.Error("expected no known changes to need verification")
+	}
+	if OnlyIgnored(nil, []string{"README.md"}) {
+		t.Error("expected no patterns to ignore nothing")
+	}
+}
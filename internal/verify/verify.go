@@ -0,0 +1,83 @@
+// Package verify runs a project's verification command (usually its test
+// suite) and keeps track of tests that fail intermittently, so a flaky test
+// doesn't hold back stories it has nothing to do with.
+package verify
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Timeout bounds a single run of the verification command.
+const Timeout = 30 * time.Minute
+
+// Result is the outcome of one run of the verification command.
+type Result struct {
+	Passed bool
+	Output string   // Combined stdout and stderr
+	Failed []string // Names of the failing tests that could be identified
+}
+
+// failedTestRegexes pick failing test names out of common test runners' output.
+var failedTestRegexes = []*regexp.Regexp{
+	regexp.MustCompile(`^\s*--- FAIL: (\S+)`),                 // go test
+	regexp.MustCompile(`^FAILED (\S+)`),                       // pytest
+	regexp.MustCompile(`^test (\S+) \.\.\. FAILED$`),          // cargo test
+	regexp.MustCompile(`^\s*● (.+ › .+)$`),                    // jest
+	regexp.MustCompile(`^\s*\d+\) \[[^\]]+\] › (.+?)[\s─]*$`), // playwright
+}
+
+// Run runs command with `sh -c` in dir.
+func Run(ctx context.Context, dir, command string) Result {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+
+	output := out.String()
+	if err == nil {
+		return Result{Passed: true, Output: output}
+	}
+	return Result{Output: output, Failed: FailedTests(output)}
+}
+
+// FailedTests returns the names of the failing tests reported in a test
+// runner's output, in order of appearance and without duplicates.
+func FailedTests(output string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		for _, re := range failedTestRegexes {
+			m := re.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			name := strings.TrimSpace(m[1])
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+			break
+		}
+	}
+	return names
+}
+
+// Tail returns the last n lines of output, for showing a failure to the agent.
+func Tail(output string, n int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,78 @@
+package verify
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestFailedTests(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{
+			name:   "go test",
+			output: "=== RUN   TestLogin\n--- FAIL: TestLogin (0.01s)\n    --- FAIL: TestLogin/bad_password (0.00s)\nFAIL\n--- FAIL: TestLogin (0.01s)\n",
+			want:   []string{"TestLogin", "TestLogin/bad_password"},
+		},
+		{
+			name:   "pytest",
+			output: "=== short test summary info ===\nFAILED tests/test_api.py::test_timeout - AssertionError\n",
+			want:   []string{"tests/test_api.py::test_timeout"},
+		},
+		{
+			name:   "cargo test",
+			output: "test parser::tests::empty ... ok\ntest parser::tests::nested ... FAILED\n",
+			want:   []string{"parser::tests::nested"},
+		},
+		{
+			name:   "jest",
+			output: "  ● Cart › adds an item\n\n    expect(received).toBe(expected)\n",
+			want:   []string{"Cart › adds an item"},
+		},
+		{
+			name:   "playwright",
+			output: "  1) [chromium] › e2e/us-012.spec.ts:3:5 › checkout › pays ───────────\n\n    Error: expect(locator).toBeVisible() failed\n",
+			want:   []string{"e2e/us-012.spec.ts:3:5 › checkout › pays"},
+		},
+		{
+			name:   "nothing recognizable",
+			output: "make: *** [test] Error 1\n",
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FailedTests(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FailedTests() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+
+	if res := Run(context.Background(), dir, "echo ok"); !res.Passed || res.Output != "ok\n" {
+		t.Errorf("expected passing run with output, got %+v", res)
+	}
+
+	res := Run(context.Background(), dir, "echo '--- FAIL: TestX (0.00s)' >&2; exit 1")
+	if res.Passed {
+		t.Fatal("expected failing run")
+	}
+	if !reflect.DeepEqual(res.Failed, []string{"TestX"}) {
+		t.Errorf("expected TestX to be reported from stderr, got %q", res.Failed)
+	}
+}
+
+func TestTail(t *testing.T) {
+	if got := Tail("a\nb\nc\n", 2); got != "b\nc" {
+		t.Errorf("Tail() = %q, want %q", got, "b\nc")
+	}
+	if got := Tail("a\n", 5); got != "a" {
+		t.Errorf("Tail() = %q, want %q", got, "a")
+	}
+}
@@ -0,0 +1,48 @@
+package workspace
+
+import (
+	"path/filepath"
+)
+
+// Arrange applies pin and hide glob patterns to a project list.
+// Projects matching a hide pattern are removed unless they are also pinned.
+// Pinned projects come first, ordered by the first pattern they match, and
+// keep their relative order within a pattern. Remaining projects follow in
+// their original order. The input slice is not modified.
+func Arrange(projects []Project, pin, hide []string) []Project {
+	var pinned [][]Project
+	if len(pin) > 0 {
+		pinned = make([][]Project, len(pin))
+	}
+	var rest []Project
+
+	for _, p := range projects {
+		if idx := matchIndex(p.Name, pin); idx >= 0 {
+			p.Pinned = true
+			pinned[idx] = append(pinned[idx], p)
+			continue
+		}
+		if matchIndex(p.Name, hide) >= 0 {
+			continue
+		}
+		p.Pinned = false
+		rest = append(rest, p)
+	}
+
+	result := make([]Project, 0, len(projects))
+	for _, group := range pinned {
+		result = append(result, group...)
+	}
+	return append(result, rest...)
+}
+
+// matchIndex returns the index of the first pattern matching name, or -1.
+// Invalid patterns never match.
+func matchIndex(name string, patterns []string) int {
+	for i, pattern := range patterns {
+		if ok, err := filepath.Match(pattern, name); err == nil && ok {
+			return i
+		}
+	}
+	return -1
+}
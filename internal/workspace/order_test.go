@@ -0,0 +1,51 @@
+package workspace
+
+import (
+	"reflect"
+	"testing"
+)
+
+func projectNames(projects []Project) []string {
+	names := make([]string, len(projects))
+	for i, p := range projects {
+		names[i] = p.Name
+	}
+	return names
+}
+
+func TestArrange(t *testing.T) {
+	projects := []Project{
+		{Name: "api"}, {Name: "archive-2019"}, {Name: "archive-2020"},
+		{Name: "docs"}, {Name: "web"}, {Name: "worker"},
+	}
+
+	tests := []struct {
+		name string
+		pin  []string
+		hide []string
+		want []string
+	}{
+		{"no config", nil, nil, []string{"api", "archive-2019", "archive-2020", "docs", "web", "worker"}},
+		{"pin in pattern order", []string{"web", "api"}, nil, []string{"web", "api", "archive-2019", "archive-2020", "docs", "worker"}},
+		{"pin glob keeps relative order", []string{"w*"}, nil, []string{"web", "worker", "api", "archive-2019", "archive-2020", "docs"}},
+		{"hide glob", nil, []string{"archive-*"}, []string{"api", "docs", "web", "worker"}},
+		{"pin wins over hide", []string{"archive-2020"}, []string{"archive-*"}, []string{"archive-2020", "api", "docs", "web", "worker"}},
+		{"invalid pattern ignored", []string{"["}, []string{"["}, []string{"api", "archive-2019", "archive-2020", "docs", "web", "worker"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := projectNames(Arrange(projects, tt.pin, tt.hide))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Arrange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArrange_MarksPinned(t *testing.T) {
+	result := Arrange([]Project{{Name: "api"}, {Name: "web"}}, []string{"web"}, nil)
+	if !result[0].Pinned || result[1].Pinned {
+		t.Errorf("expected only web to be pinned, got %+v", result)
+	}
+}
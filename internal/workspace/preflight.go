@@ -0,0 +1,120 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/minicodemonkey/chief/internal/git"
+)
+
+// DefaultMaxRepoSize is the repository size above which preflight warns (1 GiB).
+const DefaultMaxRepoSize int64 = 1 << 30
+
+// Severity indicates how serious a preflight issue is.
+type Severity int
+
+const (
+	// SeverityWarning marks issues that degrade the experience but don't block runs.
+	SeverityWarning Severity = iota
+	// SeverityError marks issues that will cause runs to fail.
+	SeverityError
+)
+
+// Issue is a problem found while validating a project.
+type Issue struct {
+	Severity Severity
+	Message  string
+}
+
+// PreflightOptions configures the preflight checks.
+type PreflightOptions struct {
+	MaxRepoSize int64 // Warn when the git object database is larger than this (default: DefaultMaxRepoSize)
+}
+
+// Preflight validates a project and returns the problems found.
+// It checks for write access, a configured git identity, repository size,
+// and PRDs that fail to parse.
+func Preflight(p Project, opts PreflightOptions) []Issue {
+	if opts.MaxRepoSize <= 0 {
+		opts.MaxRepoSize = DefaultMaxRepoSize
+	}
+
+	var issues []Issue
+
+	// Chief writes PRD state, logs, and worktrees inside the project
+	writeDir := p.Path
+	if p.HasChief {
+		writeDir = filepath.Join(p.Path, ".chief")
+	}
+	if err := checkWritable(writeDir); err != nil {
+		issues = append(issues, Issue{SeverityError, fmt.Sprintf("%s is not writable: %v", writeDir, err)})
+	}
+
+	if !p.IsGitRepo {
+		issues = append(issues, Issue{SeverityWarning, "not a git repository; the agent will not be able to commit"})
+	} else {
+		name, email := git.GetUserIdentity(p.Path)
+		if name == "" {
+			issues = append(issues, Issue{SeverityError, "git user.name is not set; agent commits will fail"})
+		}
+		if email == "" {
+			issues = append(issues, Issue{SeverityError, "git user.email is not set; agent commits will fail"})
+		}
+
+		// The object database excludes gitignored files (node_modules, build output),
+		// so it reflects what worktrees and clones actually have to copy.
+		if size, err := git.RepoSize(p.Path); err == nil && size > opts.MaxRepoSize {
+			issues = append(issues, Issue{SeverityWarning, fmt.Sprintf("repository is %s (limit %s); scans and worktrees will be slow",
+				FormatBytes(size), FormatBytes(opts.MaxRepoSize))})
+		}
+	}
+
+	for _, summary := range p.PRDs {
+		switch {
+		case summary.Err != nil:
+			issues = append(issues, Issue{SeverityError, fmt.Sprintf("PRD %s could not be parsed: %v", summary.Name, summary.Err)})
+		case summary.Total == 0:
+			issues = append(issues, Issue{SeverityWarning, fmt.Sprintf("PRD %s has no stories", summary.Name)})
+		}
+	}
+
+	return issues
+}
+
+// PreflightAll runs Preflight for every project in parallel and returns the
+// issues in the same order as projects. Git is only invoked here, never during
+// scanning, so a workspace can be listed quickly and validated on demand.
+func PreflightAll(projects []Project, opts PreflightOptions) [][]Issue {
+	results := make([][]Issue, len(projects))
+	forEach(len(projects), runtime.NumCPU(), func(i int) {
+		results[i] = Preflight(projects[i], opts)
+	})
+	return results
+}
+
+// checkWritable verifies that a file can be created in dir.
+func checkWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".chief-preflight-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
+
+// FormatBytes renders a byte count using binary units (e.g. "1.5 GiB").
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,273 @@
+// Package workspace discovers Chief projects inside a workspace directory.
+// A workspace is a directory whose immediate children are project
+// repositories, which is the layout a device exposes to the web app.
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// loadPRD is the PRD loader used by the scanner (replaceable in tests).
+var loadPRD = prd.LoadPRD
+
+// PRDSummary describes a PRD found inside a project.
+type PRDSummary struct {
+	Name      string
+	Path      string
+	Total     int
+	Completed int
+	Err       error // Non-nil when prd.md could not be parsed
+}
+
+// Project describes a single project discovered in a workspace.
+type Project struct {
+	Name      string
+	Path      string
+	IsGitRepo bool
+	HasChief  bool
+	Pinned    bool
+	PRDs      []PRDSummary
+}
+
+// Scanner discovers projects in a workspace. It is safe for concurrent use and
+// is meant to be long-lived: parsed PRD summaries are cached and only re-read
+// when a prd.md file's modification time or size changes. Scanning never spawns
+// git; repository details are only queried on demand (see Preflight).
+type Scanner struct {
+	root    string
+	workers int
+
+	mu    sync.Mutex
+	cache map[string]cachedPRD // prd.md path -> summary
+}
+
+// cachedPRD is a parsed PRD summary along with the file stat it was read from.
+type cachedPRD struct {
+	modTime time.Time
+	size    int64
+	summary PRDSummary
+}
+
+// NewScanner creates a Scanner for the workspace at root.
+func NewScanner(root string) *Scanner {
+	workers := runtime.NumCPU()
+	if workers < 4 {
+		workers = 4
+	}
+	return &Scanner{
+		root:    root,
+		workers: workers,
+		cache:   make(map[string]cachedPRD),
+	}
+}
+
+// Scan returns the projects found in the immediate subdirectories of root,
+// sorted by name. It is shorthand for NewScanner(root).Scan().
+func Scan(root string) ([]Project, error) {
+	return NewScanner(root).Scan()
+}
+
+// Scan returns the projects found in the immediate subdirectories of the
+// workspace root, sorted by name. Hidden directories and directories the
+// root's .gitignore ignores are skipped. A directory counts as a project when
+// it is a git repository or contains a .chief directory. If no child projects
+// exist but the root itself is a project, the root is returned. Projects are
+// scanned in parallel by a worker pool.
+func (s *Scanner) Scan() ([]Project, error) {
+	entries, err := os.ReadDir(s.root)
+	if err != nil {
+		return nil, err
+	}
+
+	ignored := readIgnore(s.root)
+	var dirs []string
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || ignored(entry.Name()) {
+			continue
+		}
+		dir := filepath.Join(s.root, entry.Name())
+		if isProjectDir(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	if len(dirs) == 0 && isProjectDir(s.root) {
+		dirs = append(dirs, s.root)
+	}
+
+	projects := make([]Project, len(dirs))
+	forEach(len(dirs), s.workers, func(i int) {
+		projects[i] = s.scanProject(dirs[i])
+	})
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].Name < projects[j].Name
+	})
+	s.prune(projects)
+	return projects, nil
+}
+
+// scanProject collects metadata for a single project directory.
+func (s *Scanner) scanProject(dir string) Project {
+	return Project{
+		Name:      filepath.Base(dir),
+		Path:      dir,
+		IsGitRepo: exists(filepath.Join(dir, ".git")),
+		HasChief:  exists(filepath.Join(dir, ".chief")),
+		PRDs:      s.listPRDs(dir),
+	}
+}
+
+// listPRDs returns a summary of every PRD in the project's .chief/prds/ directory.
+func (s *Scanner) listPRDs(projectDir string) []PRDSummary {
+	prdsDir := filepath.Join(projectDir, ".chief", "prds")
+	entries, err := os.ReadDir(prdsDir)
+	if err != nil {
+		return nil
+	}
+
+	var prds []PRDSummary
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		prdPath := filepath.Join(prdsDir, entry.Name(), "prd.md")
+		info, err := os.Stat(prdPath)
+		if err != nil {
+			continue
+		}
+		prds = append(prds, s.summarize(entry.Name(), prdPath, info))
+	}
+	return prds
+}
+
+// summarize returns the summary for a prd.md file, parsing it only when the
+// cached copy is missing or stale.
+func (s *Scanner) summarize(name, prdPath string, info os.FileInfo) PRDSummary {
+	s.mu.Lock()
+	cached, ok := s.cache[prdPath]
+	s.mu.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.summary
+	}
+
+	summary := PRDSummary{Name: name, Path: prdPath}
+	p, err := loadPRD(prdPath)
+	if err != nil {
+		summary.Err = err
+	} else {
+		summary.Total = len(p.UserStories)
+		for _, story := range p.UserStories {
+			if story.Passes {
+				summary.Completed++
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.cache[prdPath] = cachedPRD{modTime: info.ModTime(), size: info.Size(), summary: summary}
+	s.mu.Unlock()
+	return summary
+}
+
+// prune drops cache entries for PRDs that no longer exist.
+func (s *Scanner) prune(projects []Project) {
+	seen := make(map[string]bool)
+	for _, p := range projects {
+		for _, summary := range p.PRDs {
+			seen[summary.Path] = true
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for path := range s.cache {
+		if !seen[path] {
+			delete(s.cache, path)
+		}
+	}
+}
+
+// forEach calls fn for every index in [0, n) using up to workers goroutines.
+func forEach(n, workers int, fn func(i int)) {
+	if workers > n {
+		workers = n
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// readIgnore returns a function reporting whether the .gitignore in dir
+// ignores a directory directly inside it. Only patterns that can match such a
+// directory are considered: names and globs, optionally anchored with a
+// leading slash or limited to directories with a trailing one, and their
+// negations. A missing .gitignore ignores nothing. Like the rest of scanning,
+// this doesn't spawn git.
+func readIgnore(dir string) func(name string) bool {
+	data, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return func(string) bool { return false }
+	}
+	type rule struct {
+		pattern string
+		negate  bool
+	}
+	var rules []rule
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r := rule{}
+		if rest, ok := strings.CutPrefix(line, "!"); ok {
+			r.negate, line = true, rest
+		}
+		line = strings.TrimPrefix(strings.TrimSuffix(line, "/"), "/")
+		if line == "" || strings.Contains(line, "/") {
+			continue // Matches only deeper paths
+		}
+		r.pattern = line
+		rules = append(rules, r)
+	}
+	return func(name string) bool {
+		ignored := false
+		for _, r := range rules {
+			if ok, _ := filepath.Match(r.pattern, name); ok {
+				ignored = !r.negate
+			}
+		}
+		return ignored
+	}
+}
+
+// isProjectDir reports whether dir looks like a project (git repo or Chief project).
+func isProjectDir(dir string) bool {
+	return exists(filepath.Join(dir, ".git")) || exists(filepath.Join(dir, ".chief"))
+}
+
+// exists reports whether a path exists.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,327 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minicodemonkey/chief/internal/prd"
+)
+
+// initRepo creates a git repository at dir with the given identity (empty values are left unset).
+func initRepo(t *testing.T, dir, name, email string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	cmds := [][]string{{"git", "init"}}
+	if name != "" {
+		cmds = append(cmds, []string{"git", "config", "user.name", name})
+	}
+	if email != "" {
+		cmds = append(cmds, []string{"git", "config", "user.email", email})
+	}
+	for _, args := range cmds {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("setup command %v failed: %s", args, string(out))
+		}
+	}
+}
+
+// writePRD writes a prd.md for the named PRD inside a project.
+func writePRD(t *testing.T, projectDir, name, content string) {
+	t.Helper()
+	dir := filepath.Join(projectDir, ".chief", "prds", name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "prd.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// scanProject collects metadata for the single project at dir.
+func scanProject(dir string) Project {
+	return NewScanner(filepath.Dir(dir)).scanProject(dir)
+}
+
+// isolateGitConfig prevents the user's global git config from leaking into tests.
+func isolateGitConfig(t *testing.T) {
+	t.Helper()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+}
+
+func TestScan(t *testing.T) {
+	isolateGitConfig(t)
+	root := t.TempDir()
+
+	initRepo(t, filepath.Join(root, "api"), "Test", "test@test.com")
+	writePRD(t, filepath.Join(root, "api"), "auth", "# Auth\n\n### US-001: Login\n**Status:** done\n\n### US-002: Logout\n")
+	initRepo(t, filepath.Join(root, "web"), "Test", "test@test.com")
+	if err := os.MkdirAll(filepath.Join(root, "notes"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	initRepo(t, filepath.Join(root, ".hidden"), "Test", "test@test.com")
+
+	projects, err := Scan(root)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(projects) != 2 {
+		t.Fatalf("expected 2 projects, got %d: %+v", len(projects), projects)
+	}
+	if projects[0].Name != "api" || projects[1].Name != "web" {
+		t.Errorf("expected [api web], got [%s %s]", projects[0].Name, projects[1].Name)
+	}
+
+	api := projects[0]
+	if !api.IsGitRepo || !api.HasChief {
+		t.Errorf("expected api to be a git repo with .chief, got %+v", api)
+	}
+	if len(api.PRDs) != 1 {
+		t.Fatalf("expected 1 PRD, got %d", len(api.PRDs))
+	}
+	if api.PRDs[0].Name != "auth" || api.PRDs[0].Total != 2 || api.PRDs[0].Completed != 1 {
+		t.Errorf("unexpected PRD summary: %+v", api.PRDs[0])
+	}
+}
+
+func TestScan_RootIsProject(t *testing.T) {
+	isolateGitConfig(t)
+	root := t.TempDir()
+	initRepo(t, root, "Test", "test@test.com")
+
+	projects, err := Scan(root)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(projects) != 1 || projects[0].Path != root {
+		t.Errorf("expected root to be scanned as the only project, got %+v", projects)
+	}
+}
+
+func TestScan_MissingRoot(t *testing.T) {
+	if _, err := Scan(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected error for missing workspace")
+	}
+}
+
+func TestPreflight_MissingIdentity(t *testing.T) {
+	isolateGitConfig(t)
+	dir := filepath.Join(t.TempDir(), "api")
+	initRepo(t, dir, "", "")
+
+	issues := Preflight(scanProject(dir), PreflightOptions{})
+
+	var messages []string
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			messages = append(messages, issue.Message)
+		}
+	}
+	joined := strings.Join(messages, "\n")
+	if !strings.Contains(joined, "user.name") || !strings.Contains(joined, "user.email") {
+		t.Errorf("expected missing identity errors, got %q", joined)
+	}
+}
+
+func TestPreflight_OversizedRepo(t *testing.T) {
+	isolateGitConfig(t)
+	dir := filepath.Join(t.TempDir(), "api")
+	initRepo(t, dir, "Test", "test@test.com")
+	if err := os.WriteFile(filepath.Join(dir, "big.txt"), []byte(strings.Repeat("x", 4096)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("git", "add", ".")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git add failed: %s", out)
+	}
+
+	issues := Preflight(scanProject(dir), PreflightOptions{MaxRepoSize: 1})
+
+	found := false
+	for _, issue := range issues {
+		if issue.Severity == SeverityWarning && strings.Contains(issue.Message, "repository is") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected oversized repo warning, got %+v", issues)
+	}
+}
+
+func TestPreflight_PRDProblems(t *testing.T) {
+	isolateGitConfig(t)
+	dir := filepath.Join(t.TempDir(), "api")
+	initRepo(t, dir, "Test", "test@test.com")
+	writePRD(t, dir, "empty", "# Empty\n")
+
+	issues := Preflight(scanProject(dir), PreflightOptions{})
+	if len(issues) != 1 || !strings.Contains(issues[0].Message, "PRD empty has no stories") {
+		t.Errorf("expected a single empty-PRD warning, got %+v", issues)
+	}
+}
+
+func TestPreflight_NotGitRepo(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".chief"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := Preflight(scanProject(dir), PreflightOptions{})
+	if len(issues) != 1 || issues[0].Severity != SeverityWarning {
+		t.Errorf("expected a single not-a-repo warning, got %+v", issues)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		in   int64
+		want string
+	}{
+		{512, "512 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{1 << 30, "1.0 GiB"},
+	}
+	for _, tt := range tests {
+		if got := FormatBytes(tt.in); got != tt.want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// countLoads replaces the PRD loader with one that counts calls.
+func countLoads(t *testing.T) *int {
+	t.Helper()
+	calls := 0
+	var mu sync.Mutex
+	original := loadPRD
+	loadPRD = func(path string) (*prd.PRD, error) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		return original(path)
+	}
+	t.Cleanup(func() { loadPRD = original })
+	return &calls
+}
+
+func TestScanner_CachesUnchangedPRDs(t *testing.T) {
+	root := t.TempDir()
+	projectDir := filepath.Join(root, "api")
+	writePRD(t, projectDir, "auth", "# Auth\n\n### US-001: Login\n")
+	calls := countLoads(t)
+
+	scanner := NewScanner(root)
+	if _, err := scanner.Scan(); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if _, err := scanner.Scan(); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if *calls != 1 {
+		t.Errorf("expected PRD to be parsed once, got %d", *calls)
+	}
+
+	// Changing the file invalidates the cache
+	prdPath := filepath.Join(projectDir, ".chief", "prds", "auth", "prd.md")
+	if err := os.WriteFile(prdPath, []byte("# Auth\n\n### US-001: Login\n**Status:** done\n\n### US-002: Logout\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(prdPath, future, future); err != nil {
+		t.Fatal(err)
+	}
+
+	projects, err := scanner.Scan()
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if *calls != 2 {
+		t.Errorf("expected PRD to be re-parsed after change, got %d parses", *calls)
+	}
+	if got := projects[0].PRDs[0]; got.Total != 2 || got.Completed != 1 {
+		t.Errorf("expected refreshed summary, got %+v", got)
+	}
+}
+
+func TestScan_SkipsGitignoredDirectories(t *testing.T) {
+	isolateGitConfig(t)
+	root := t.TempDir()
+	initRepo(t, filepath.Join(root, "api"), "Test", "test@test.com")
+	initRepo(t, filepath.Join(root, "vendor"), "Test", "test@test.com")
+	initRepo(t, filepath.Join(root, "build-1"), "Test", "test@test.com")
+	initRepo(t, filepath.Join(root, "build-keep"), "Test", "test@test.com")
+	ignore := "# Generated\n/vendor/\nbuild-*\n!build-keep\nsrc/api\n"
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte(ignore), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	projects, err := Scan(root)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	var names []string
+	for _, p := range projects {
+		names = append(names, p.Name)
+	}
+	if strings.Join(names, " ") != "api build-keep" {
+		t.Errorf("expected [api build-keep], got %v", names)
+	}
+}
+
+func TestScanner_PrunesDeletedPRDs(t *testing.T) {
+	root := t.TempDir()
+	projectDir := filepath.Join(root, "api")
+	writePRD(t, projectDir, "auth", "# Auth\n")
+
+	scanner := NewScanner(root)
+	if _, err := scanner.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(projectDir, ".chief", "prds", "auth")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := scanner.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	if len(scanner.cache) != 0 {
+		t.Errorf("expected cache to be pruned, got %d entries", len(scanner.cache))
+	}
+}
+
+func BenchmarkScanner_100Projects(b *testing.B) {
+	root := b.TempDir()
+	for i := 0; i < 100; i++ {
+		projectDir := filepath.Join(root, fmt.Sprintf("project-%03d", i))
+		if err := os.MkdirAll(filepath.Join(projectDir, ".git"), 0755); err != nil {
+			b.Fatal(err)
+		}
+		prdDir := filepath.Join(projectDir, ".chief", "prds", "main")
+		if err := os.MkdirAll(prdDir, 0755); err != nil {
+			b.Fatal(err)
+		}
+		content := "# Project\n\n### US-001: One\n**Status:** done\n\n### US-002: Two\n"
+		if err := os.WriteFile(filepath.Join(prdDir, "prd.md"), []byte(content), 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	scanner := NewScanner(root)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := scanner.Scan(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}